@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -21,6 +20,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/tendant/simple-content/pkg/simplecontent"
 	"github.com/tendant/simple-content/pkg/simplecontent/admin"
+	"github.com/tendant/simple-content/pkg/simplecontent/api"
 	"github.com/tendant/simple-content/pkg/simplecontent/config"
 	"github.com/tendant/simple-content/pkg/simplecontent/presigned"
 	"github.com/tendant/simple-content/pkg/simplecontent/repo/memory"
@@ -30,10 +30,17 @@ import (
 	s3storage "github.com/tendant/simple-content/pkg/simplecontent/storage/s3"
 )
 
-// loadServerConfigFromEnv constructs a ServerConfig by reading process environment variables.
+// loadServerConfigFromEnv constructs a ServerConfig by reading process environment variables,
+// on top of the config file named by CONFIG_FILE when set.
 // This keeps environment-specific logic within the executable instead of the library.
 func loadServerConfigFromEnv() (*config.ServerConfig, error) {
-	cfg, err := config.Load(config.WithEnv(""))
+	var opts []config.Option
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		opts = append(opts, config.WithFile(path))
+	}
+	opts = append(opts, config.WithEnv(""))
+
+	cfg, err := config.Load(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
@@ -61,8 +68,16 @@ func main() {
 		log.Fatalf("Failed to build service: %v", err)
 	}
 
+	// Signer for presigned requests on backends without their own; config.Load has
+	// already validated the presigned settings
+	signer, err := serverConfig.Presigned.NewSigner()
+	if err != nil {
+		log.Fatalf("Invalid presigned configuration: %v", err)
+	}
+
 	// Create HTTP server
 	server := NewHTTPServer(svc, serverConfig)
+	server.requestSigner = signer
 
 	// Create HTTP server instance
 	httpServer := &http.Server{
@@ -112,6 +127,7 @@ type HTTPServer struct {
 	repository     simplecontent.Repository     // For direct repository access (presigned uploads)
 	blobStores     map[string]simplecontent.BlobStore // For direct blob storage access
 	config         *config.ServerConfig
+	requestSigner  *presigned.Signer // Validates presigned requests on backends without their own signer; nil skips
 }
 
 // NewHTTPServer creates a new HTTP server wrapper
@@ -154,27 +170,24 @@ func (s *HTTPServer) Routes() http.Handler {
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Timeout(60 * time.Second))
+	// Caller identity for the access audit log, only from a header a fronting auth
+	// gateway is trusted to set
+	if s.config.TrustedActorHeader != "" {
+		r.Use(api.ActorHeaderMiddleware(s.config.TrustedActorHeader))
+	}
 
-	// CORS for development
-	if s.config.Environment == "development" {
-		r.Use(func(next http.Handler) http.Handler {
-			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.Header().Set("Access-Control-Allow-Origin", "*")
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-				if r.Method == "OPTIONS" {
-					w.WriteHeader(http.StatusOK)
-					return
-				}
-
-				next.ServeHTTP(w, r)
-			})
-		})
+	// CORS: explicit allowlist when configured, wildcard only in development
+	if len(s.config.CORSAllowedOrigins) > 0 || s.config.Environment == "development" {
+		r.Use(api.CORSMiddlewareWithOptions(api.CORSOptions{
+			AllowedOrigins:   s.config.CORSAllowedOrigins,
+			AllowedHeaders:   []string{"Content-Type", "Authorization"},
+			AllowCredentials: s.config.CORSAllowCredentials,
+		}))
 	}
 
 	// Health check
 	r.Get("/health", s.handleHealth)
+	r.Method(http.MethodGet, "/health/storage", api.NewStorageHealthHandler(s.blobStores))
 
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
@@ -192,25 +205,33 @@ func (s *HTTPServer) Routes() http.Handler {
 
 		// Content data access
 		r.Get("/contents/{contentID}/download", s.handleContentDownload)
+		r.Get("/contents/{contentID}/bundle", s.handleContentBundle)
 		r.Get("/contents/{contentID}/preview", s.handleContentPreview)
 		r.Post("/contents/{contentID}/upload", s.handleContentUpload)
 
-		// Object management
-		r.Post("/contents/{contentID}/objects", s.handleCreateObject)
-		r.Get("/objects/{objectID}", s.handleGetObject)
-		r.Delete("/objects/{objectID}", s.handleDeleteObject)
-		r.Get("/contents/{contentID}/objects", s.handleListObjects)
-
-		// Object upload/download
-		r.Post("/objects/{objectID}/upload", s.handleUploadObject)
-		r.Get("/objects/{objectID}/download", s.handleDownloadObject)
-		r.Get("/objects/{objectID}/upload-url", s.handleGetUploadURL)
-		r.Get("/objects/{objectID}/download-url", s.handleGetDownloadURL)
-		r.Get("/objects/{objectID}/preview-url", s.handleGetPreviewURL)
+		// Object-level API (unless disabled)
+		if !s.config.DisableObjectAPI {
+			// Object management
+			r.Post("/contents/{contentID}/objects", s.handleCreateObject)
+			r.Get("/objects/{objectID}", s.handleGetObject)
+			r.Delete("/objects/{objectID}", s.handleDeleteObject)
+			r.Post("/objects/{objectID}/move", s.handleMoveObject)
+			r.Get("/contents/{contentID}/objects", s.handleListObjects)
+
+			// Object upload/download
+			r.Post("/objects/{objectID}/upload", s.handleUploadObject)
+			r.Get("/objects/{objectID}/download", s.handleDownloadObject)
+			r.Get("/objects/{objectID}/upload-progress", s.handleGetUploadProgress)
+			r.Get("/objects/{objectID}/upload-offset", s.handleGetUploadOffset)
+			r.Get("/objects/{objectID}/upload-url", s.handleGetUploadURL)
+			r.Get("/objects/{objectID}/download-url", s.handleGetDownloadURL)
+			r.Get("/objects/{objectID}/preview-url", s.handleGetPreviewURL)
+		}
 
 		// Presigned-style endpoints for filesystem storage (mimics S3 presigned URLs)
 		// Handles PUT /upload/{objectKey...} and GET /download|preview/{objectKey...}
-		presignedHandlers := presigned.NewHandlers(s.blobStores, s.config.DefaultStorageBackend)
+		// Signatures use the server's request signer on backends without their own signer
+		presignedHandlers := presigned.NewHandlers(s.blobStores, s.config.DefaultStorageBackend, presigned.WithRequestSigner(s.requestSigner))
 		presignedHandlers.Mount(r)
 
 		// Demo endpoint
@@ -226,6 +247,7 @@ func (s *HTTPServer) Routes() http.Handler {
 				r.Get("/contents", s.handleAdminListContents)
 				r.Get("/contents/count", s.handleAdminCountContents)
 				r.Get("/contents/stats", s.handleAdminGetStatistics)
+				r.Get("/contents/{contentID}/access-events", s.handleAdminListAccessEvents)
 			})
 		}
 	})
@@ -311,6 +333,25 @@ func (s *HTTPServer) handleGetConfig(w http.ResponseWriter, r *http.Request) {
 
 // Placeholder handlers - in a real implementation these would be fully implemented
 
+// parseOwnerIDs parses the owner_id and tenant_id of a request. An empty ID is left as
+// uuid.Nil when the config sets a default for it, which the service then fills in.
+func (s *HTTPServer) parseOwnerIDs(w http.ResponseWriter, ownerStr, tenantStr string) (ownerID, tenantID uuid.UUID, ok bool) {
+	var err error
+	if ownerStr != "" || s.config.DefaultOwnerID == "" {
+		if ownerID, err = uuid.Parse(ownerStr); err != nil {
+			api.WriteError(w, http.StatusBadRequest, "invalid_owner_id", "owner_id must be a UUID", nil)
+			return uuid.Nil, uuid.Nil, false
+		}
+	}
+	if tenantStr != "" || s.config.DefaultTenantID == "" {
+		if tenantID, err = uuid.Parse(tenantStr); err != nil {
+			api.WriteError(w, http.StatusBadRequest, "invalid_tenant_id", "tenant_id must be a UUID", nil)
+			return uuid.Nil, uuid.Nil, false
+		}
+	}
+	return ownerID, tenantID, true
+}
+
 func (s *HTTPServer) handleCreateContent(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		OwnerID        string                 `json:"owner_id"`
@@ -322,17 +363,11 @@ func (s *HTTPServer) handleCreateContent(w http.ResponseWriter, r *http.Request)
 		Metadata       map[string]interface{} `json:"metadata"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
-		return
-	}
-	ownerID, err := uuid.Parse(req.OwnerID)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_owner_id", "owner_id must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
 		return
 	}
-	tenantID, err := uuid.Parse(req.TenantID)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_tenant_id", "tenant_id must be a UUID", nil)
+	ownerID, tenantID, ok := s.parseOwnerIDs(w, req.OwnerID, req.TenantID)
+	if !ok {
 		return
 	}
 
@@ -345,7 +380,7 @@ func (s *HTTPServer) handleCreateContent(w http.ResponseWriter, r *http.Request)
 		DerivationType: req.DerivationType,
 	})
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, contentResponse(content, ""))
@@ -355,12 +390,16 @@ func (s *HTTPServer) handleGetContent(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "contentID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		return
+	}
+	if r.URL.Query().Get("full") == "true" {
+		s.handleGetContentFull(w, r, id)
 		return
 	}
 	content, err := s.service.GetContent(r.Context(), id)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	variant := ""
@@ -370,13 +409,33 @@ func (s *HTTPServer) handleGetContent(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, contentResponse(content, variant))
 }
 
+// handleGetContentFull serves GET /contents/{contentID}?full=true: the content response
+// with the content's objects and metadata added under "objects" and "metadata"
+func (s *HTTPServer) handleGetContentFull(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	full, err := s.service.GetContentFull(r.Context(), id)
+	if err != nil {
+		api.WriteServiceError(w, err)
+		return
+	}
+	variant := ""
+	if rel, err := s.service.GetDerivedRelationship(r.Context(), id); err == nil && rel != nil {
+		variant = rel.DerivationType
+	}
+	out := contentResponse(full.Content, variant)
+	out["objects"] = full.Objects
+	if full.Metadata != nil {
+		out["metadata"] = full.Metadata
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
 // handleCreateDerivedContent creates a derived Content linked to a parent content.
 // Request body: { owner_id, tenant_id, derivation_type, variant, metadata }
 func (s *HTTPServer) handleCreateDerivedContent(w http.ResponseWriter, r *http.Request) {
 	parentStr := chi.URLParam(r, "parentID")
 	parentID, err := uuid.Parse(parentStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_parent_id", "parentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_parent_id", "parentID must be a UUID", nil)
 		return
 	}
 	var req struct {
@@ -387,17 +446,11 @@ func (s *HTTPServer) handleCreateDerivedContent(w http.ResponseWriter, r *http.R
 		Metadata       map[string]interface{} `json:"metadata"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
 		return
 	}
-	ownerID, err := uuid.Parse(req.OwnerID)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_owner_id", "owner_id must be a UUID", nil)
-		return
-	}
-	tenantID, err := uuid.Parse(req.TenantID)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_tenant_id", "tenant_id must be a UUID", nil)
+	ownerID, tenantID, ok := s.parseOwnerIDs(w, req.OwnerID, req.TenantID)
+	if !ok {
 		return
 	}
 	derived, err := s.service.CreateDerivedContent(r.Context(), simplecontent.CreateDerivedContentRequest{
@@ -409,7 +462,7 @@ func (s *HTTPServer) handleCreateDerivedContent(w http.ResponseWriter, r *http.R
 		Metadata:       req.Metadata,
 	})
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	variant := ""
@@ -423,12 +476,12 @@ func (s *HTTPServer) handleUpdateContent(w http.ResponseWriter, r *http.Request)
 	idStr := chi.URLParam(r, "contentID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
 		return
 	}
 	existing, err := s.service.GetContent(r.Context(), id)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	var req struct {
@@ -437,7 +490,7 @@ func (s *HTTPServer) handleUpdateContent(w http.ResponseWriter, r *http.Request)
 		DocumentType *string `json:"document_type"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
 		return
 	}
 	if req.Name != nil {
@@ -450,7 +503,7 @@ func (s *HTTPServer) handleUpdateContent(w http.ResponseWriter, r *http.Request)
 		existing.DocumentType = *req.DocumentType
 	}
 	if err := s.service.UpdateContent(r.Context(), simplecontent.UpdateContentRequest{Content: existing}); err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, contentResponse(existing, ""))
@@ -460,11 +513,12 @@ func (s *HTTPServer) handleDeleteContent(w http.ResponseWriter, r *http.Request)
 	idStr := chi.URLParam(r, "contentID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
 		return
 	}
-	if err := s.service.DeleteContent(r.Context(), id); err != nil {
-		writeServiceError(w, err)
+	opts := simplecontent.DeleteContentOptions{Cascade: r.URL.Query().Get("cascade") == "true"}
+	if err := s.service.DeleteContentWithOptions(r.Context(), id, opts); err != nil {
+		api.WriteServiceError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -473,23 +527,24 @@ func (s *HTTPServer) handleDeleteContent(w http.ResponseWriter, r *http.Request)
 func (s *HTTPServer) handleListContents(w http.ResponseWriter, r *http.Request) {
 	ownerStr := r.URL.Query().Get("owner_id")
 	tenantStr := r.URL.Query().Get("tenant_id")
-	if ownerStr == "" || tenantStr == "" {
-		writeError(w, http.StatusBadRequest, "missing_params", "owner_id and tenant_id are required", nil)
+	if (ownerStr == "" && s.config.DefaultOwnerID == "") || (tenantStr == "" && s.config.DefaultTenantID == "") {
+		api.WriteError(w, http.StatusBadRequest, "missing_params", "owner_id and tenant_id are required", nil)
 		return
 	}
-	ownerID, err := uuid.Parse(ownerStr)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_owner_id", "owner_id must be a UUID", nil)
+	ownerID, tenantID, ok := s.parseOwnerIDs(w, ownerStr, tenantStr)
+	if !ok {
 		return
 	}
-	tenantID, err := uuid.Parse(tenantStr)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_tenant_id", "tenant_id must be a UUID", nil)
-		return
+	req := simplecontent.ListContentRequest{OwnerID: ownerID, TenantID: tenantID}
+	if r.URL.Query().Get("originals_only") == "true" {
+		req = req.WithOriginalsOnly()
 	}
-	contents, err := s.service.ListContent(r.Context(), simplecontent.ListContentRequest{OwnerID: ownerID, TenantID: tenantID})
+	if r.URL.Query().Get("include_primary_object") == "true" {
+		req = req.WithPrimaryObject()
+	}
+	contents, err := s.service.ListContent(r.Context(), req)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	out := make([]map[string]interface{}, 0, len(contents))
@@ -509,27 +564,21 @@ func (s *HTTPServer) handleListDerivedForParent(w http.ResponseWriter, r *http.R
 	parentStr := chi.URLParam(r, "contentID")
 	parentID, err := uuid.Parse(parentStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
 		return
 	}
 
-	// List derived relationships filtered by parent via service
-	rels, err := s.service.ListDerivedContent(r.Context(), simplecontent.WithParentID(parentID))
+	// List derived relationships together with their child content in one repository call
+	items, err := s.service.ListDerivedContentWithDetails(r.Context(), simplecontent.WithParentID(parentID))
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 
-	// For each relationship, fetch the child content and build the response
-	out := make([]map[string]interface{}, 0, len(rels))
-	for _, rel := range rels {
-		child, err := s.service.GetContent(r.Context(), rel.ContentID)
-		if err != nil {
-			// Skip if child content not found
-			continue
-		}
-		// rel.DerivationType is the specific variant (COALESCE handled in repo)
-		out = append(out, contentResponse(child, rel.DerivationType))
+	out := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		// item.DerivationType is the specific variant (COALESCE handled in repo)
+		out = append(out, contentResponse(item.Content, item.DerivationType))
 	}
 
 	writeJSON(w, http.StatusOK, out)
@@ -540,7 +589,7 @@ func (s *HTTPServer) handleGetContentDetails(w http.ResponseWriter, r *http.Requ
 	idStr := chi.URLParam(r, "contentID")
 	contentID, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
 		return
 	}
 
@@ -563,7 +612,7 @@ func (s *HTTPServer) handleGetContentDetails(w http.ResponseWriter, r *http.Requ
 
 	details, err := s.service.GetContentDetails(r.Context(), contentID, options...)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 
@@ -580,7 +629,7 @@ func (s *HTTPServer) handleCreateObject(w http.ResponseWriter, r *http.Request)
 		ObjectKey          string `json:"object_key"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
-		writeError(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
 		return
 	}
 	cid := req.ContentID
@@ -589,7 +638,7 @@ func (s *HTTPServer) handleCreateObject(w http.ResponseWriter, r *http.Request)
 	}
 	contentID, err := uuid.Parse(cid)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "content_id must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "content_id must be a UUID", nil)
 		return
 	}
 	backend := req.StorageBackendName
@@ -603,7 +652,7 @@ func (s *HTTPServer) handleCreateObject(w http.ResponseWriter, r *http.Request)
 		ObjectKey:          req.ObjectKey,
 	})
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, obj)
@@ -613,26 +662,87 @@ func (s *HTTPServer) handleGetObject(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "objectID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
 		return
 	}
 	obj, err := s.storageService.GetObject(r.Context(), id)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, obj)
+}
+
+// handleMoveObject moves an object's data to a new key on its backend.
+// Request body: { object_key }
+func (s *HTTPServer) handleMoveObject(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "objectID")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		return
+	}
+	var req struct {
+		ObjectKey string `json:"object_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", err.Error(), nil)
+		return
+	}
+	obj, err := s.storageService.MoveObject(r.Context(), id, req.ObjectKey)
+	if err != nil {
+		api.WriteServiceError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, obj)
 }
 
+// handleGetUploadProgress reports bytes written for an object uploaded through the server
+func (s *HTTPServer) handleGetUploadProgress(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "objectID")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		return
+	}
+	progress, err := s.storageService.GetUploadProgress(r.Context(), id)
+	if err != nil {
+		api.WriteServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, progress)
+}
+
+// handleGetUploadOffset reports how many bytes of the object are already stored so a
+// client can resume an interrupted upload from that offset
+func (s *HTTPServer) handleGetUploadOffset(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "objectID")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		return
+	}
+	offset, err := s.storageService.GetUploadOffset(r.Context(), id)
+	if err != nil {
+		api.WriteServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"object_id": id,
+		"offset":    offset,
+	})
+}
+
 func (s *HTTPServer) handleDeleteObject(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "objectID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
 		return
 	}
-	if err := s.storageService.DeleteObject(r.Context(), id); err != nil {
-		writeServiceError(w, err)
+	opts := simplecontent.DeleteObjectOptions{KeepBlob: r.URL.Query().Get("keep_blob") == "true"}
+	if err := s.storageService.DeleteObjectWithOptions(r.Context(), id, opts); err != nil {
+		api.WriteServiceError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -642,12 +752,12 @@ func (s *HTTPServer) handleListObjects(w http.ResponseWriter, r *http.Request) {
 	contentStr := chi.URLParam(r, "contentID")
 	contentID, err := uuid.Parse(contentStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
 		return
 	}
 	objs, err := s.storageService.GetObjectsByContentID(r.Context(), contentID)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, objs)
@@ -657,7 +767,7 @@ func (s *HTTPServer) handleUploadObject(w http.ResponseWriter, r *http.Request)
 	idStr := chi.URLParam(r, "objectID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
 		return
 	}
 	mimeType := r.Header.Get("Content-Type")
@@ -669,9 +779,13 @@ func (s *HTTPServer) handleUploadObject(w http.ResponseWriter, r *http.Request)
 		ObjectID: id,
 		Reader:   r.Body,
 		MimeType: mimeType,
+		IfMatch:  r.Header.Get("If-Match"),
+	}
+	if r.ContentLength > 0 {
+		req.SizeBytes = r.ContentLength
 	}
 	if err := s.storageService.UploadObject(r.Context(), req); err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -681,24 +795,40 @@ func (s *HTTPServer) handleDownloadObject(w http.ResponseWriter, r *http.Request
 	idStr := chi.URLParam(r, "objectID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		return
+	}
+	disposition, err := dispositionOptions(r)
+	if err != nil {
+		api.WriteServiceError(w, err)
+		return
+	}
+	object, err := s.storageService.GetObject(r.Context(), id)
+	if err != nil {
+		api.WriteServiceError(w, err)
 		return
 	}
-	rc, err := s.storageService.DownloadObject(r.Context(), id)
+	rc, meta, err := s.storageService.DownloadObjectWithMeta(r.Context(), id)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	defer rc.Close()
+	w.Header().Set("Content-Length", strconv.FormatInt(meta.Size, 10))
 	if md, mdErr := s.storageService.GetObjectMetadata(r.Context(), id); mdErr == nil {
 		if mt, ok := md["mime_type"].(string); ok && mt != "" {
 			w.Header().Set("Content-Type", mt)
 		}
-		if fn, ok := md["file_name"].(string); ok && fn != "" {
-			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fn))
+		fn, _ := md["file_name"].(string)
+		if cd := disposition.Header(simplecontent.DispositionAttachment, fn); cd != "" {
+			w.Header().Set("Content-Disposition", cd)
+		}
+		if writeContentETag(w, r, md) {
+			return
 		}
 	}
-	if _, err := io.Copy(w, rc); err != nil {
+	access := &simplecontent.AccessEvent{ContentID: object.ContentID, ObjectID: id, Action: simplecontent.AccessActionDownload}
+	if err := s.streamWithAccessEvent(w, r, rc, access); err != nil {
 		log.Printf("download copy error: %v", err)
 	}
 }
@@ -707,12 +837,12 @@ func (s *HTTPServer) handleGetUploadURL(w http.ResponseWriter, r *http.Request)
 	idStr := chi.URLParam(r, "objectID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
 		return
 	}
 	url, err := s.storageService.GetUploadURL(r.Context(), id)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"url": url})
@@ -722,12 +852,12 @@ func (s *HTTPServer) handleGetDownloadURL(w http.ResponseWriter, r *http.Request
 	idStr := chi.URLParam(r, "objectID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
 		return
 	}
 	url, err := s.storageService.GetDownloadURL(r.Context(), id)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"url": url})
@@ -737,12 +867,12 @@ func (s *HTTPServer) handleGetPreviewURL(w http.ResponseWriter, r *http.Request)
 	idStr := chi.URLParam(r, "objectID")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_object_id", "objectID must be a UUID", nil)
 		return
 	}
 	url, err := s.storageService.GetPreviewURL(r.Context(), id)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"url": url})
@@ -753,53 +883,90 @@ func (s *HTTPServer) handleGetPreviewURL(w http.ResponseWriter, r *http.Request)
 
 // --- Helpers ---
 
-type errorBody struct {
-	Error struct {
-		Code    string      `json:"code"`
-		Message string      `json:"message"`
-		Details interface{} `json:"details,omitempty"`
-	} `json:"error"`
-}
-
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(v)
 }
 
-func writeError(w http.ResponseWriter, status int, code, message string, details interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	var eb errorBody
-	eb.Error.Code = code
-	eb.Error.Message = message
-	eb.Error.Details = details
-	_ = json.NewEncoder(w).Encode(eb)
+// writeContentETag sets the ETag header from the content ETag the service stored for the
+// object, if any, and answers with 304 Not Modified when If-None-Match already matches it.
+// It reports whether the response has been written.
+func writeContentETag(w http.ResponseWriter, r *http.Request, md map[string]interface{}) bool {
+	etag, ok := md[simplecontent.MetaContentETag].(string)
+	if !ok || etag == "" {
+		return false
+	}
+	w.Header().Set("ETag", etag)
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
 }
 
-func writeServiceError(w http.ResponseWriter, err error) {
-	status := http.StatusInternalServerError
-	code := "internal_error"
-	msg := err.Error()
-
-	if errors.Is(err, simplecontent.ErrContentNotFound) || errors.Is(err, simplecontent.ErrObjectNotFound) {
-		status = http.StatusNotFound
-		code = "not_found"
+// dispositionOptions reads the disposition and filename query parameters with which a client
+// overrides how a download or preview is presented
+func dispositionOptions(r *http.Request) (simplecontent.DispositionOptions, error) {
+	disposition, err := simplecontent.ParseDisposition(r.URL.Query().Get("disposition"))
+	if err != nil {
+		return simplecontent.DispositionOptions{}, err
 	}
-	if errors.Is(err, simplecontent.ErrInvalidContentStatus) || errors.Is(err, simplecontent.ErrInvalidObjectStatus) {
-		status = http.StatusBadRequest
-		code = "invalid_status"
+	return simplecontent.DispositionOptions{Disposition: disposition, FileName: r.URL.Query().Get("filename")}, nil
+}
+
+// etagMatches applies the weak comparison If-None-Match uses to a header value listing ETags
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
 	}
-	if errors.Is(err, simplecontent.ErrUploadFailed) || errors.Is(err, simplecontent.ErrDownloadFailed) {
-		status = http.StatusBadGateway
-		code = "storage_error"
+	return false
+}
+
+// streamWithAccessEvent copies rc to the response and records the access event once the
+// first bytes have been written, so requests that fail before streaming starts are not
+// audited. An empty body is recorded after the copy succeeds.
+func (s *HTTPServer) streamWithAccessEvent(w http.ResponseWriter, r *http.Request, rc io.Reader, event *simplecontent.AccessEvent) error {
+	aw := &accessRecordingWriter{ResponseWriter: w, record: func() {
+		if err := s.service.RecordContentAccess(r.Context(), event); err != nil {
+			log.Printf("record content access error: %v", err)
+		}
+	}}
+	n, err := io.Copy(aw, rc)
+	if err == nil {
+		if n == 0 {
+			// Send the status of a zero-byte download explicitly, as nothing was written
+			w.WriteHeader(http.StatusOK)
+		}
+		aw.recordOnce()
 	}
-	if errors.Is(err, simplecontent.ErrStorageBackendNotFound) {
-		status = http.StatusBadRequest
-		code = "storage_backend_not_found"
+	return err
+}
+
+// accessRecordingWriter calls record after the first successful write
+type accessRecordingWriter struct {
+	http.ResponseWriter
+	record   func()
+	recorded bool
+}
+
+func (w *accessRecordingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	if n > 0 {
+		w.recordOnce()
 	}
+	return n, err
+}
 
-	writeError(w, status, code, msg, nil)
+func (w *accessRecordingWriter) recordOnce() {
+	if !w.recorded {
+		w.recorded = true
+		w.record()
+	}
 }
 
 // contentResponse augments a Content with explicit variant for clients.
@@ -822,6 +989,9 @@ func contentResponse(c *simplecontent.Content, variant string) map[string]interf
 	if variant != "" {
 		m["variant"] = variant
 	}
+	if c.PrimaryObject != nil {
+		m["primary_object"] = c.PrimaryObject
+	}
 	return m
 }
 
@@ -830,19 +1000,24 @@ func (s *HTTPServer) handleContentDownload(w http.ResponseWriter, r *http.Reques
 	idStr := chi.URLParam(r, "contentID")
 	contentID, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		return
+	}
+	disposition, err := dispositionOptions(r)
+	if err != nil {
+		api.WriteServiceError(w, err)
 		return
 	}
 
 	// Get the primary object for this content
 	objects, err := s.storageService.GetObjectsByContentID(r.Context(), contentID)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 
 	if len(objects) == 0 {
-		writeError(w, http.StatusNotFound, "no_objects", "No objects found for this content", nil)
+		api.WriteError(w, http.StatusNotFound, "no_objects", "No objects found for this content", nil)
 		return
 	}
 
@@ -850,73 +1025,126 @@ func (s *HTTPServer) handleContentDownload(w http.ResponseWriter, r *http.Reques
 	primaryObject := objects[0]
 
 	// Download the object data
-	rc, err := s.storageService.DownloadObject(r.Context(), primaryObject.ID)
+	rc, meta, err := s.storageService.DownloadObjectWithMeta(r.Context(), primaryObject.ID)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	defer rc.Close()
+	w.Header().Set("Content-Length", strconv.FormatInt(meta.Size, 10))
 
 	// Set appropriate headers
 	if md, mdErr := s.storageService.GetObjectMetadata(r.Context(), primaryObject.ID); mdErr == nil {
 		if mt, ok := md["mime_type"].(string); ok && mt != "" {
 			w.Header().Set("Content-Type", mt)
 		}
-		if fn, ok := md["file_name"].(string); ok && fn != "" {
-			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fn))
+		fn, _ := md["file_name"].(string)
+		if cd := disposition.Header(simplecontent.DispositionAttachment, fn); cd != "" {
+			w.Header().Set("Content-Disposition", cd)
+		}
+		if writeContentETag(w, r, md) {
+			return
 		}
 	}
 
 	// Stream the content
-	if _, err := io.Copy(w, rc); err != nil {
+	access := &simplecontent.AccessEvent{ContentID: contentID, ObjectID: primaryObject.ID, Action: simplecontent.AccessActionDownload}
+	if err := s.streamWithAccessEvent(w, r, rc, access); err != nil {
 		log.Printf("content download copy error: %v", err)
 	}
 }
 
+// handleContentBundle streams a tar of the content and all its derived variants
+func (s *HTTPServer) handleContentBundle(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "contentID")
+	contentID, err := uuid.Parse(idStr)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.tar\"", contentID))
+
+	access := &simplecontent.AccessEvent{ContentID: contentID, Action: simplecontent.AccessActionDownload}
+	aw := &accessRecordingWriter{ResponseWriter: w, record: func() {
+		if err := s.service.RecordContentAccess(r.Context(), access); err != nil {
+			log.Printf("record content access error: %v", err)
+		}
+	}}
+	if err := s.storageService.DownloadContentBundle(r.Context(), contentID, aw); err != nil {
+		if !aw.recorded {
+			// Nothing streamed yet, so the error can still be reported
+			w.Header().Del("Content-Disposition")
+			api.WriteServiceError(w, err)
+			return
+		}
+		log.Printf("content bundle copy error: %v", err)
+	}
+}
+
 // handleContentPreview provides preview access to content using content ID
 func (s *HTTPServer) handleContentPreview(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "contentID")
 	contentID, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		return
+	}
+	disposition, err := dispositionOptions(r)
+	if err != nil {
+		api.WriteServiceError(w, err)
 		return
 	}
 
 	// Get the primary object for this content
 	objects, err := s.storageService.GetObjectsByContentID(r.Context(), contentID)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 
 	if len(objects) == 0 {
-		writeError(w, http.StatusNotFound, "no_objects", "No objects found for this content", nil)
+		api.WriteError(w, http.StatusNotFound, "no_objects", "No objects found for this content", nil)
 		return
 	}
 
 	// Use the first object as primary
 	primaryObject := objects[0]
 
-	// Download the object data for preview
-	rc, err := s.storageService.DownloadObject(r.Context(), primaryObject.ID)
+	// Download the object data for preview, transformed for its MIME type when configured
+	rc, meta, err := s.storageService.DownloadObjectPreview(r.Context(), primaryObject.ID)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 	defer rc.Close()
+	transformed := meta.Size < 0
+	if !transformed {
+		w.Header().Set("Content-Length", strconv.FormatInt(meta.Size, 10))
+	}
 
 	// Set appropriate headers for preview (inline content disposition)
 	if md, mdErr := s.storageService.GetObjectMetadata(r.Context(), primaryObject.ID); mdErr == nil {
 		if mt, ok := md["mime_type"].(string); ok && mt != "" {
 			w.Header().Set("Content-Type", mt)
 		}
-		if fn, ok := md["file_name"].(string); ok && fn != "" {
-			w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", fn))
+		fn, _ := md["file_name"].(string)
+		if cd := disposition.Header(simplecontent.DispositionInline, fn); cd != "" {
+			w.Header().Set("Content-Disposition", cd)
+		}
+		// The content ETag identifies the original data, not a transformed preview
+		if !transformed && writeContentETag(w, r, md) {
+			return
 		}
 	}
+	if transformed && meta.ContentType != "" {
+		w.Header().Set("Content-Type", meta.ContentType)
+	}
 
 	// Stream the content
-	if _, err := io.Copy(w, rc); err != nil {
+	access := &simplecontent.AccessEvent{ContentID: contentID, ObjectID: primaryObject.ID, Action: simplecontent.AccessActionPreview}
+	if err := s.streamWithAccessEvent(w, r, rc, access); err != nil {
 		log.Printf("content preview copy error: %v", err)
 	}
 }
@@ -926,21 +1154,25 @@ func (s *HTTPServer) handleContentUpload(w http.ResponseWriter, r *http.Request)
 	idStr := chi.URLParam(r, "contentID")
 	contentID, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
 		return
 	}
 
 	// Verify the content exists
-	_, err = s.service.GetContent(r.Context(), contentID)
+	exists, err := s.service.ContentExists(r.Context(), contentID)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
+		return
+	}
+	if !exists {
+		api.WriteServiceError(w, &simplecontent.ContentError{ContentID: contentID, Op: "upload", Err: simplecontent.ErrContentNotFound})
 		return
 	}
 
 	// Get or create an object for this content
 	objects, err := s.storageService.GetObjectsByContentID(r.Context(), contentID)
 	if err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 
@@ -956,7 +1188,7 @@ func (s *HTTPServer) handleContentUpload(w http.ResponseWriter, r *http.Request)
 			Version:            1,
 		})
 		if err != nil {
-			writeServiceError(w, err)
+			api.WriteServiceError(w, err)
 			return
 		}
 		primaryObject = obj
@@ -973,9 +1205,13 @@ func (s *HTTPServer) handleContentUpload(w http.ResponseWriter, r *http.Request)
 		ObjectID: primaryObject.ID,
 		Reader:   r.Body,
 		MimeType: mimeType,
+		IfMatch:  r.Header.Get("If-Match"),
+	}
+	if r.ContentLength > 0 {
+		req.SizeBytes = r.ContentLength
 	}
 	if err := s.storageService.UploadObject(r.Context(), req); err != nil {
-		writeServiceError(w, err)
+		api.WriteServiceError(w, err)
 		return
 	}
 
@@ -989,17 +1225,12 @@ func buildRepository(serverConfig *config.ServerConfig) simplecontent.Repository
 
 	switch serverConfig.DatabaseType {
 	case "postgres":
-		// Connect to postgres
-		poolConfig, err := pgxpool.ParseConfig(serverConfig.DatabaseURL)
+		// Connect to postgres with the configured pool sizing and search_path
+		poolConfig, err := serverConfig.PostgresPoolConfig()
 		if err != nil {
 			log.Fatalf("Failed to parse postgres URL: %v", err)
 		}
 
-		// Set search_path if schema is specified
-		if serverConfig.DBSchema != "" {
-			poolConfig.ConnConfig.RuntimeParams["search_path"] = serverConfig.DBSchema
-		}
-
 		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 		if err != nil {
 			log.Fatalf("Failed to connect to postgres: %v", err)
@@ -1112,7 +1343,7 @@ func buildAdminService(serverConfig *config.ServerConfig) admin.AdminService {
 
 func (s *HTTPServer) handleAdminListContents(w http.ResponseWriter, r *http.Request) {
 	if s.adminService == nil {
-		writeError(w, http.StatusForbidden, "admin_disabled", "Admin API is not enabled", nil)
+		api.WriteError(w, http.StatusForbidden, "admin_disabled", "Admin API is not enabled", nil)
 		return
 	}
 
@@ -1123,7 +1354,7 @@ func (s *HTTPServer) handleAdminListContents(w http.ResponseWriter, r *http.Requ
 	if tenantIDStr := r.URL.Query().Get("tenant_id"); tenantIDStr != "" {
 		tenantID, err := uuid.Parse(tenantIDStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_tenant_id", "Invalid tenant_id format", nil)
+			api.WriteError(w, http.StatusBadRequest, "invalid_tenant_id", "Invalid tenant_id format", nil)
 			return
 		}
 		filters.TenantID = &tenantID
@@ -1133,7 +1364,7 @@ func (s *HTTPServer) handleAdminListContents(w http.ResponseWriter, r *http.Requ
 	if ownerIDStr := r.URL.Query().Get("owner_id"); ownerIDStr != "" {
 		ownerID, err := uuid.Parse(ownerIDStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_owner_id", "Invalid owner_id format", nil)
+			api.WriteError(w, http.StatusBadRequest, "invalid_owner_id", "Invalid owner_id format", nil)
 			return
 		}
 		filters.OwnerID = &ownerID
@@ -1184,7 +1415,7 @@ func (s *HTTPServer) handleAdminListContents(w http.ResponseWriter, r *http.Requ
 		Filters: filters,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "list_failed", err.Error(), nil)
+		api.WriteError(w, http.StatusInternalServerError, "list_failed", err.Error(), nil)
 		return
 	}
 
@@ -1193,7 +1424,7 @@ func (s *HTTPServer) handleAdminListContents(w http.ResponseWriter, r *http.Requ
 
 func (s *HTTPServer) handleAdminCountContents(w http.ResponseWriter, r *http.Request) {
 	if s.adminService == nil {
-		writeError(w, http.StatusForbidden, "admin_disabled", "Admin API is not enabled", nil)
+		api.WriteError(w, http.StatusForbidden, "admin_disabled", "Admin API is not enabled", nil)
 		return
 	}
 
@@ -1203,7 +1434,7 @@ func (s *HTTPServer) handleAdminCountContents(w http.ResponseWriter, r *http.Req
 	if tenantIDStr := r.URL.Query().Get("tenant_id"); tenantIDStr != "" {
 		tenantID, err := uuid.Parse(tenantIDStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_tenant_id", "Invalid tenant_id format", nil)
+			api.WriteError(w, http.StatusBadRequest, "invalid_tenant_id", "Invalid tenant_id format", nil)
 			return
 		}
 		filters.TenantID = &tenantID
@@ -1212,7 +1443,7 @@ func (s *HTTPServer) handleAdminCountContents(w http.ResponseWriter, r *http.Req
 	if ownerIDStr := r.URL.Query().Get("owner_id"); ownerIDStr != "" {
 		ownerID, err := uuid.Parse(ownerIDStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_owner_id", "Invalid owner_id format", nil)
+			api.WriteError(w, http.StatusBadRequest, "invalid_owner_id", "Invalid owner_id format", nil)
 			return
 		}
 		filters.OwnerID = &ownerID
@@ -1239,7 +1470,7 @@ func (s *HTTPServer) handleAdminCountContents(w http.ResponseWriter, r *http.Req
 		Filters: filters,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "count_failed", err.Error(), nil)
+		api.WriteError(w, http.StatusInternalServerError, "count_failed", err.Error(), nil)
 		return
 	}
 
@@ -1248,7 +1479,7 @@ func (s *HTTPServer) handleAdminCountContents(w http.ResponseWriter, r *http.Req
 
 func (s *HTTPServer) handleAdminGetStatistics(w http.ResponseWriter, r *http.Request) {
 	if s.adminService == nil {
-		writeError(w, http.StatusForbidden, "admin_disabled", "Admin API is not enabled", nil)
+		api.WriteError(w, http.StatusForbidden, "admin_disabled", "Admin API is not enabled", nil)
 		return
 	}
 
@@ -1258,7 +1489,7 @@ func (s *HTTPServer) handleAdminGetStatistics(w http.ResponseWriter, r *http.Req
 	if tenantIDStr := r.URL.Query().Get("tenant_id"); tenantIDStr != "" {
 		tenantID, err := uuid.Parse(tenantIDStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_tenant_id", "Invalid tenant_id format", nil)
+			api.WriteError(w, http.StatusBadRequest, "invalid_tenant_id", "Invalid tenant_id format", nil)
 			return
 		}
 		filters.TenantID = &tenantID
@@ -1282,6 +1513,9 @@ func (s *HTTPServer) handleAdminGetStatistics(w http.ResponseWriter, r *http.Req
 	if includeTime := r.URL.Query().Get("include_time_range"); includeTime == "false" {
 		options.IncludeTimeRange = false
 	}
+	if includeDedup := r.URL.Query().Get("include_dedup"); includeDedup == "false" {
+		options.IncludeDedupBreakdown = false
+	}
 
 	// Call admin service
 	resp, err := s.adminService.GetStatistics(r.Context(), admin.StatisticsRequest{
@@ -1289,9 +1523,34 @@ func (s *HTTPServer) handleAdminGetStatistics(w http.ResponseWriter, r *http.Req
 		Options: options,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "stats_failed", err.Error(), nil)
+		api.WriteError(w, http.StatusInternalServerError, "stats_failed", err.Error(), nil)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
+
+// handleAdminListAccessEvents returns the download/preview audit log for a content
+func (s *HTTPServer) handleAdminListAccessEvents(w http.ResponseWriter, r *http.Request) {
+	if s.adminService == nil {
+		api.WriteError(w, http.StatusForbidden, "admin_disabled", "Admin API is not enabled", nil)
+		return
+	}
+
+	contentID, err := uuid.Parse(chi.URLParam(r, "contentID"))
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
+		return
+	}
+
+	events, err := s.adminService.ListAccessEvents(r.Context(), contentID)
+	if err != nil {
+		api.WriteServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"content_id": contentID,
+		"events":     events,
+	})
+}
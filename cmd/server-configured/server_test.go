@@ -1,18 +1,26 @@
 package main
 
 import (
+    "archive/tar"
     "bytes"
+    "context"
     "encoding/json"
+    "errors"
     "io"
     "net/http"
     "net/http/httptest"
+    "strings"
     "testing"
+    "testing/iotest"
 
     "github.com/google/uuid"
     "github.com/tendant/simple-content/pkg/simplecontent"
+    "github.com/tendant/simple-content/pkg/simplecontent/admin"
     memoryrepo "github.com/tendant/simple-content/pkg/simplecontent/repo/memory"
+    fsstorage "github.com/tendant/simple-content/pkg/simplecontent/storage/fs"
     memorystorage "github.com/tendant/simple-content/pkg/simplecontent/storage/memory"
     "github.com/tendant/simple-content/pkg/simplecontent/config"
+    "github.com/tendant/simple-content/pkg/simplecontent/presigned"
 )
 
 func newTestServer(t *testing.T) (simplecontent.Service, *HTTPServer) {
@@ -123,6 +131,22 @@ func TestObjectUploadDownload(t *testing.T) {
     if rec.Body.String() != "hello" {
         t.Fatalf("unexpected download body: %q", rec.Body.String())
     }
+    if got := rec.Header().Get("Content-Length"); got != "5" {
+        t.Fatalf("expected Content-Length 5, got %q", got)
+    }
+
+    // Upload progress reports the completed upload
+    rr = doJSON(t, ts, http.MethodGet, "/api/v1/objects/"+obj.ID+"/upload-progress", nil)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var progress simplecontent.UploadProgress
+    if err := json.Unmarshal(rr.Body.Bytes(), &progress); err != nil {
+        t.Fatalf("invalid progress response: %v", err)
+    }
+    if !progress.Completed || progress.BytesWritten != 5 {
+        t.Fatalf("unexpected upload progress: %+v", progress)
+    }
 }
 
 func TestCreateDerivedContentEndpoint(t *testing.T) {
@@ -190,3 +214,748 @@ func doRaw(t *testing.T, ts *HTTPServer, method, path, contentType string, body
     ts.Routes().ServeHTTP(rr, req)
     return rr
 }
+
+func TestCORSAllowedOrigins(t *testing.T) {
+    _, ts := newTestServer(t)
+    ts.config.Environment = "production"
+    ts.config.CORSAllowedOrigins = []string{"https://app.example.com"}
+    handler := ts.Routes()
+
+    req := httptest.NewRequest(http.MethodGet, "/health", nil)
+    req.Header.Set("Origin", "https://app.example.com")
+    rr := httptest.NewRecorder()
+    handler.ServeHTTP(rr, req)
+    if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+        t.Fatalf("expected allowed origin to be echoed, got %q", got)
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/health", nil)
+    req.Header.Set("Origin", "https://evil.example.com")
+    rr = httptest.NewRecorder()
+    handler.ServeHTTP(rr, req)
+    if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+        t.Fatalf("expected no CORS header for disallowed origin, got %q", got)
+    }
+}
+
+func TestUploadOffset(t *testing.T) {
+    baseDir := t.TempDir()
+    fsStore, err := fsstorage.New(fsstorage.Config{BaseDir: baseDir})
+    if err != nil {
+        t.Fatalf("fs storage: %v", err)
+    }
+    svc, err := simplecontent.New(
+        simplecontent.WithRepository(memoryrepo.New()),
+        simplecontent.WithBlobStore("fs", fsStore),
+    )
+    if err != nil {
+        t.Fatalf("service create error: %v", err)
+    }
+    ts := NewHTTPServer(svc, &config.ServerConfig{
+        ServiceConfig: config.ServiceConfig{DatabaseType: "memory", DefaultStorageBackend: "fs"},
+        Environment:   "testing",
+    })
+
+    rr := doJSON(t, ts, http.MethodPost, "/api/v1/contents", map[string]any{
+        "owner_id": uuid.New().String(),
+        "tenant_id": uuid.New().String(),
+        "name": "resumable",
+    })
+    if rr.Code != http.StatusCreated {
+        t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var created struct{ ID string `json:"id"` }
+    _ = json.Unmarshal(rr.Body.Bytes(), &created)
+
+    rr = doJSON(t, ts, http.MethodPost, "/api/v1/contents/"+created.ID+"/objects", map[string]any{
+        "version": 1,
+        "storage_backend_name": "fs",
+    })
+    if rr.Code != http.StatusCreated {
+        t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var obj struct {
+        ID        string `json:"id"`
+        ObjectKey string `json:"object_key"`
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &obj); err != nil || obj.ID == "" || obj.ObjectKey == "" {
+        t.Fatalf("invalid create object response: %v, body=%s", err, rr.Body.String())
+    }
+
+    offset := func() int64 {
+        t.Helper()
+        rr := doJSON(t, ts, http.MethodGet, "/api/v1/objects/"+obj.ID+"/upload-offset", nil)
+        if rr.Code != http.StatusOK {
+            t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+        }
+        var resp struct{ Offset int64 `json:"offset"` }
+        if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+            t.Fatalf("invalid offset response: %v", err)
+        }
+        return resp.Offset
+    }
+
+    // Fresh object: nothing stored yet
+    if got := offset(); got != 0 {
+        t.Fatalf("expected offset 0 for fresh object, got %d", got)
+    }
+
+    // An interrupted upload leaves its bytes behind
+    err = fsStore.Upload(context.Background(), obj.ObjectKey, io.MultiReader(
+        strings.NewReader("hello"), iotest.ErrReader(errors.New("connection reset")),
+    ))
+    if err == nil {
+        t.Fatal("expected interrupted upload to fail")
+    }
+    if got := offset(); got != 5 {
+        t.Fatalf("expected offset 5 after partial upload, got %d", got)
+    }
+
+    rr = doRaw(t, ts, http.MethodPost, "/api/v1/objects/"+obj.ID+"/upload", "text/plain", bytes.NewBufferString("hello world"))
+    if rr.Code != http.StatusNoContent {
+        t.Fatalf("expected 204, got %d: %s", rr.Code, rr.Body.String())
+    }
+    if got := offset(); got != 11 {
+        t.Fatalf("expected offset 11 after full upload, got %d", got)
+    }
+
+    rr = doJSON(t, ts, http.MethodGet, "/api/v1/objects/"+uuid.New().String()+"/upload-offset", nil)
+    if rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404 for unknown object, got %d", rr.Code)
+    }
+}
+
+// accessCountingSink counts the access events fired by the service
+type accessCountingSink struct {
+    simplecontent.NoopEventSink
+    downloads []*simplecontent.AccessEvent
+    previews  []*simplecontent.AccessEvent
+}
+
+func (s *accessCountingSink) ContentDownloaded(ctx context.Context, event *simplecontent.AccessEvent) error {
+    s.downloads = append(s.downloads, event)
+    return nil
+}
+
+func (s *accessCountingSink) ContentPreviewed(ctx context.Context, event *simplecontent.AccessEvent) error {
+    s.previews = append(s.previews, event)
+    return nil
+}
+
+func TestContentDownloadRecordsAccessEvent(t *testing.T) {
+    repo := memoryrepo.New()
+    sink := &accessCountingSink{}
+    svc, err := simplecontent.New(
+        simplecontent.WithRepository(repo),
+        simplecontent.WithBlobStore("memory", memorystorage.New()),
+        simplecontent.WithEventSink(sink),
+    )
+    if err != nil {
+        t.Fatalf("service create error: %v", err)
+    }
+    ts := NewHTTPServer(svc, &config.ServerConfig{
+        ServiceConfig:  config.ServiceConfig{DatabaseType: "memory", DefaultStorageBackend: "memory"},
+        Environment:        "testing",
+        EnableAdminAPI:     true,
+        TrustedActorHeader: "X-Actor-ID",
+    })
+    // The admin service must read the same repository the service writes to
+    ts.adminService = admin.New(repo)
+
+    content, err := svc.UploadContent(context.Background(), simplecontent.UploadContentRequest{
+        OwnerID:            uuid.New(),
+        TenantID:           uuid.New(),
+        Name:               "report",
+        DocumentType:       "text/plain",
+        StorageBackendName: "memory",
+        Reader:             strings.NewReader("audited"),
+        FileName:           "report.txt",
+    })
+    if err != nil {
+        t.Fatalf("upload content: %v", err)
+    }
+
+    get := func(path string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodGet, path, nil)
+        req.Header.Set("X-Actor-ID", "user-42")
+        rec := httptest.NewRecorder()
+        ts.Routes().ServeHTTP(rec, req)
+        return rec
+    }
+
+    rec := get("/api/v1/contents/" + content.ID.String() + "/download")
+    if rec.Code != http.StatusOK || rec.Body.String() != "audited" {
+        t.Fatalf("unexpected download: %d %q", rec.Code, rec.Body.String())
+    }
+
+    if len(sink.downloads) != 1 || len(sink.previews) != 0 {
+        t.Fatalf("expected exactly one download event, got %d downloads and %d previews", len(sink.downloads), len(sink.previews))
+    }
+    if sink.downloads[0].Actor != "user-42" || sink.downloads[0].ContentID != content.ID {
+        t.Fatalf("unexpected download event: %+v", sink.downloads[0])
+    }
+
+    // A failed download does not count as an access
+    if rec := get("/api/v1/contents/" + uuid.New().String() + "/download"); rec.Code == http.StatusOK {
+        t.Fatalf("expected download of unknown content to fail")
+    }
+    if len(sink.downloads) != 1 {
+        t.Fatalf("failed download emitted an access event")
+    }
+
+    rec = get("/api/v1/admin/contents/" + content.ID.String() + "/access-events")
+    if rec.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+    }
+    var listed struct {
+        Events []simplecontent.AccessEvent `json:"events"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+        t.Fatalf("invalid access events response: %v", err)
+    }
+    if len(listed.Events) != 1 {
+        t.Fatalf("expected one stored access event, got %d", len(listed.Events))
+    }
+    if ev := listed.Events[0]; ev.Actor != "user-42" || ev.Action != simplecontent.AccessActionDownload || ev.ObjectID == uuid.Nil {
+        t.Fatalf("unexpected stored access event: %+v", ev)
+    }
+}
+
+func TestActorHeaderIgnoredByDefault(t *testing.T) {
+    sink := &accessCountingSink{}
+    svc, err := simplecontent.New(
+        simplecontent.WithRepository(memoryrepo.New()),
+        simplecontent.WithBlobStore("memory", memorystorage.New()),
+        simplecontent.WithEventSink(sink),
+    )
+    if err != nil {
+        t.Fatalf("service create error: %v", err)
+    }
+    ts := NewHTTPServer(svc, &config.ServerConfig{
+        ServiceConfig: config.ServiceConfig{DatabaseType: "memory", DefaultStorageBackend: "memory"},
+        Environment:   "testing",
+    })
+
+    content, err := svc.UploadContent(context.Background(), simplecontent.UploadContentRequest{
+        OwnerID:            uuid.New(),
+        TenantID:           uuid.New(),
+        Name:               "report",
+        DocumentType:       "text/plain",
+        StorageBackendName: "memory",
+        Reader:             strings.NewReader("audited"),
+        FileName:           "report.txt",
+    })
+    if err != nil {
+        t.Fatalf("upload content: %v", err)
+    }
+
+    // Without a trusted actor header a client cannot name the actor itself
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/contents/"+content.ID.String()+"/download", nil)
+    req.Header.Set("X-Actor-ID", "forged")
+    rec := httptest.NewRecorder()
+    ts.Routes().ServeHTTP(rec, req)
+    if rec.Code != http.StatusOK {
+        t.Fatalf("unexpected download: %d %q", rec.Code, rec.Body.String())
+    }
+    if len(sink.downloads) != 1 || sink.downloads[0].Actor != "" {
+        t.Fatalf("expected one download event without actor, got %+v", sink.downloads)
+    }
+}
+
+func TestContentBundleDownload(t *testing.T) {
+    svc, ts := newTestServer(t)
+    ctx := context.Background()
+    ownerID, tenantID := uuid.New(), uuid.New()
+
+    parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+        OwnerID:            ownerID,
+        TenantID:           tenantID,
+        Name:               "photo",
+        DocumentType:       "image/jpeg",
+        StorageBackendName: "memory",
+        Reader:             strings.NewReader("original image"),
+        FileName:           "photo.jpg",
+    })
+    if err != nil {
+        t.Fatalf("upload content: %v", err)
+    }
+    for _, variant := range []string{"thumbnail_128", "thumbnail_256"} {
+        _, err := svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+            ParentID:           parent.ID,
+            OwnerID:            ownerID,
+            TenantID:           tenantID,
+            DerivationType:     "thumbnail",
+            Variant:            variant,
+            StorageBackendName: "memory",
+            Reader:             strings.NewReader(variant + " image"),
+            FileName:           "thumb.jpg",
+        })
+        if err != nil {
+            t.Fatalf("upload derived %s: %v", variant, err)
+        }
+    }
+
+    // A variant that looks like a path must not escape the archive root
+    _, err = svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+        ParentID:           parent.ID,
+        OwnerID:            ownerID,
+        TenantID:           tenantID,
+        DerivationType:     "preview",
+        Variant:            "../../preview_evil",
+        StorageBackendName: "memory",
+        Reader:             strings.NewReader("preview image"),
+        FileName:           "../thumb.jpg",
+    })
+    if err != nil {
+        t.Fatalf("upload derived preview: %v", err)
+    }
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/contents/"+parent.ID.String()+"/bundle", nil)
+    rec := httptest.NewRecorder()
+    ts.Routes().ServeHTTP(rec, req)
+    if rec.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+    }
+    if ct := rec.Header().Get("Content-Type"); ct != "application/x-tar" {
+        t.Fatalf("unexpected content type %q", ct)
+    }
+    if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, parent.ID.String()+".tar") {
+        t.Fatalf("unexpected content disposition %q", cd)
+    }
+
+    files := map[string]string{}
+    tr := tar.NewReader(rec.Body)
+    for {
+        hdr, err := tr.Next()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            t.Fatalf("read tar: %v", err)
+        }
+        data, err := io.ReadAll(tr)
+        if err != nil {
+            t.Fatalf("read tar entry %s: %v", hdr.Name, err)
+        }
+        files[hdr.Name] = string(data)
+    }
+    expected := map[string]string{
+        "photo.jpg":         "original image",
+        "thumbnail_128.jpg": "thumbnail_128 image",
+        "thumbnail_256.jpg": "thumbnail_256 image",
+        "preview_evil.jpg":  "preview image",
+    }
+    if len(files) != len(expected) {
+        t.Fatalf("unexpected bundle entries: %v", files)
+    }
+    for name, data := range expected {
+        if files[name] != data {
+            t.Fatalf("entry %s: expected %q, got %q", name, data, files[name])
+        }
+    }
+
+    // Unknown content is reported before anything is streamed
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/contents/"+uuid.New().String()+"/bundle", nil)
+    rec = httptest.NewRecorder()
+    ts.Routes().ServeHTTP(rec, req)
+    if rec.Code != http.StatusNotFound {
+        t.Fatalf("expected 404 for unknown content, got %d", rec.Code)
+    }
+
+    // Content that is not ready for download is rejected like a plain download
+    pending, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+        OwnerID:      ownerID,
+        TenantID:     tenantID,
+        Name:         "pending",
+        DocumentType: "text/plain",
+    })
+    if err != nil {
+        t.Fatalf("create content: %v", err)
+    }
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/contents/"+pending.ID.String()+"/bundle", nil)
+    rec = httptest.NewRecorder()
+    ts.Routes().ServeHTTP(rec, req)
+    if rec.Code != http.StatusConflict {
+        t.Fatalf("expected 409 for content that is not ready, got %d", rec.Code)
+    }
+}
+
+func TestGetContentFullEndpoint(t *testing.T) {
+    svc, ts := newTestServer(t)
+    ctx := context.Background()
+
+    content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+        OwnerID:            uuid.New(),
+        TenantID:           uuid.New(),
+        Name:               "report",
+        DocumentType:       "text/plain",
+        StorageBackendName: "memory",
+        Reader:             strings.NewReader("report body"),
+        FileName:           "report.txt",
+    })
+    if err != nil {
+        t.Fatalf("upload content: %v", err)
+    }
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/contents/"+content.ID.String()+"?full=true", nil)
+    rec := httptest.NewRecorder()
+    ts.Routes().ServeHTTP(rec, req)
+    if rec.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+    }
+
+    var body struct {
+        ID       string                         `json:"id"`
+        Objects  []simplecontent.Object         `json:"objects"`
+        Metadata *simplecontent.ContentMetadata `json:"metadata"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+        t.Fatalf("decode response: %v", err)
+    }
+    if body.ID != content.ID.String() {
+        t.Fatalf("expected content %s, got %s", content.ID, body.ID)
+    }
+    if len(body.Objects) != 1 || body.Objects[0].Status != string(simplecontent.ObjectStatusUploaded) {
+        t.Fatalf("expected one uploaded object, got %+v", body.Objects)
+    }
+    if body.Metadata == nil || body.Metadata.FileName != "report.txt" {
+        t.Fatalf("expected metadata with file name, got %+v", body.Metadata)
+    }
+
+    // Without the flag the plain content response is returned
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/contents/"+content.ID.String(), nil)
+    rec = httptest.NewRecorder()
+    ts.Routes().ServeHTTP(rec, req)
+    if strings.Contains(rec.Body.String(), `"objects"`) {
+        t.Fatalf("plain response should not include objects: %s", rec.Body.String())
+    }
+}
+
+func TestObjectAPIDisabled(t *testing.T) {
+    svc, err := simplecontent.New(
+        simplecontent.WithRepository(memoryrepo.New()),
+        simplecontent.WithBlobStore("memory", memorystorage.New()),
+    )
+    if err != nil {
+        t.Fatalf("service create error: %v", err)
+    }
+    ts := NewHTTPServer(svc, &config.ServerConfig{
+        ServiceConfig:    config.ServiceConfig{DatabaseType: "memory", DefaultStorageBackend: "memory"},
+        Environment:      "testing",
+        DisableObjectAPI: true,
+    })
+
+    rr := doJSON(t, ts, http.MethodPost, "/api/v1/contents", map[string]any{
+        "owner_id": uuid.New().String(),
+        "tenant_id": uuid.New().String(),
+        "name": "no objects",
+    })
+    if rr.Code != http.StatusCreated {
+        t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var created struct{ ID string `json:"id"` }
+    if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || created.ID == "" {
+        t.Fatalf("invalid create content response: %v, body=%s", err, rr.Body.String())
+    }
+
+    // Content-level routes stay registered
+    rr = doJSON(t, ts, http.MethodGet, "/api/v1/contents/"+created.ID, nil)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200 for content, got %d: %s", rr.Code, rr.Body.String())
+    }
+
+    objectID := uuid.New().String()
+    for _, route := range []struct{ method, path string }{
+        {http.MethodPost, "/api/v1/contents/" + created.ID + "/objects"},
+        {http.MethodGet, "/api/v1/contents/" + created.ID + "/objects"},
+        {http.MethodGet, "/api/v1/objects/" + objectID},
+        {http.MethodDelete, "/api/v1/objects/" + objectID},
+        {http.MethodPost, "/api/v1/objects/" + objectID + "/upload"},
+        {http.MethodGet, "/api/v1/objects/" + objectID + "/download"},
+        {http.MethodGet, "/api/v1/objects/" + objectID + "/upload-url"},
+    } {
+        rr = doJSON(t, ts, route.method, route.path, nil)
+        if rr.Code != http.StatusNotFound {
+            t.Errorf("%s %s: expected 404, got %d", route.method, route.path, rr.Code)
+        }
+    }
+}
+
+func TestContentDownloadETag(t *testing.T) {
+    svc, err := simplecontent.New(
+        simplecontent.WithRepository(memoryrepo.New()),
+        simplecontent.WithBlobStore("memory", memorystorage.New()),
+        simplecontent.WithContentETags(),
+    )
+    if err != nil {
+        t.Fatalf("service create error: %v", err)
+    }
+    ts := NewHTTPServer(svc, &config.ServerConfig{
+        ServiceConfig: config.ServiceConfig{DatabaseType: "memory", DefaultStorageBackend: "memory"},
+        Environment:   "testing",
+    })
+
+    content, err := svc.UploadContent(context.Background(), simplecontent.UploadContentRequest{
+        OwnerID:      uuid.New(),
+        TenantID:     uuid.New(),
+        Name:         "cached",
+        DocumentType: "text/plain",
+        Reader:       strings.NewReader("cacheable"),
+        FileName:     "cached.txt",
+    })
+    if err != nil {
+        t.Fatalf("upload content: %v", err)
+    }
+    path := "/api/v1/contents/" + content.ID.String() + "/download"
+
+    rr := doJSON(t, ts, http.MethodGet, path, nil)
+    etag := rr.Header().Get("ETag")
+    if rr.Code != http.StatusOK || rr.Body.String() != "cacheable" || etag == "" {
+        t.Fatalf("unexpected download: %d %q etag=%q", rr.Code, rr.Body.String(), etag)
+    }
+
+    for header, want := range map[string]int{
+        etag:                     http.StatusNotModified,
+        "W/" + etag:              http.StatusNotModified,
+        `"other", ` + etag:       http.StatusNotModified,
+        `"other"`:                http.StatusOK,
+    } {
+        req := httptest.NewRequest(http.MethodGet, path, nil)
+        req.Header.Set("If-None-Match", header)
+        rec := httptest.NewRecorder()
+        ts.Routes().ServeHTTP(rec, req)
+        if rec.Code != want {
+            t.Errorf("If-None-Match %s: expected %d, got %d", header, want, rec.Code)
+        }
+        if want == http.StatusNotModified && rec.Body.Len() != 0 {
+            t.Errorf("If-None-Match %s: expected empty body, got %q", header, rec.Body.String())
+        }
+    }
+}
+
+func TestUploadObjectIfMatch(t *testing.T) {
+    svc, err := simplecontent.New(
+        simplecontent.WithRepository(memoryrepo.New()),
+        simplecontent.WithBlobStore("memory", memorystorage.New()),
+        simplecontent.WithContentETags(),
+    )
+    if err != nil {
+        t.Fatalf("service create error: %v", err)
+    }
+    ts := NewHTTPServer(svc, &config.ServerConfig{
+        ServiceConfig: config.ServiceConfig{DatabaseType: "memory", DefaultStorageBackend: "memory"},
+        Environment:   "testing",
+    })
+
+    content, err := svc.UploadContent(context.Background(), simplecontent.UploadContentRequest{
+        OwnerID:      uuid.New(),
+        TenantID:     uuid.New(),
+        Name:         "versioned",
+        DocumentType: "text/plain",
+        Reader:       strings.NewReader("v1"),
+        FileName:     "versioned.txt",
+    })
+    if err != nil {
+        t.Fatalf("upload content: %v", err)
+    }
+    etag := doJSON(t, ts, http.MethodGet, "/api/v1/contents/"+content.ID.String()+"/download", nil).Header().Get("ETag")
+    objects, err := svc.GetObjectsByContentID(context.Background(), content.ID)
+    if err != nil || len(objects) != 1 {
+        t.Fatalf("objects: %v %d", err, len(objects))
+    }
+    path := "/api/v1/objects/" + objects[0].ID.String() + "/upload"
+
+    upload := func(ifMatch, body string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+        req.Header.Set("If-Match", ifMatch)
+        rec := httptest.NewRecorder()
+        ts.Routes().ServeHTTP(rec, req)
+        return rec
+    }
+    if rec := upload(etag, "v2"); rec.Code != http.StatusNoContent {
+        t.Fatalf("fresh writer: expected 204, got %d: %s", rec.Code, rec.Body.String())
+    }
+    rec := upload(etag, "stale")
+    if rec.Code != http.StatusPreconditionFailed || !strings.Contains(rec.Body.String(), "precondition_failed") {
+        t.Fatalf("stale writer: expected 412 precondition_failed, got %d: %s", rec.Code, rec.Body.String())
+    }
+}
+
+func TestContentUploadExistence(t *testing.T) {
+    svc, ts := newTestServer(t)
+    content, err := svc.CreateContent(context.Background(), simplecontent.CreateContentRequest{
+        OwnerID:      uuid.New(),
+        TenantID:     uuid.New(),
+        Name:         "upload target",
+        DocumentType: "text/plain",
+    })
+    if err != nil {
+        t.Fatalf("create content: %v", err)
+    }
+
+    rr := doRaw(t, ts, http.MethodPost, "/api/v1/contents/"+content.ID.String()+"/upload", "text/plain", bytes.NewBufferString("data"))
+    if rr.Code != http.StatusNoContent {
+        t.Fatalf("existing content: expected 204, got %d: %s", rr.Code, rr.Body.String())
+    }
+
+    rr = doRaw(t, ts, http.MethodPost, "/api/v1/contents/"+uuid.New().String()+"/upload", "text/plain", bytes.NewBufferString("data"))
+    if rr.Code != http.StatusNotFound || !strings.Contains(rr.Body.String(), "content_not_found") {
+        t.Fatalf("missing content: expected 404 content_not_found, got %d: %s", rr.Code, rr.Body.String())
+    }
+}
+
+func TestDownloadDispositionOverride(t *testing.T) {
+    svc, ts := newTestServer(t)
+    content, err := svc.UploadContent(context.Background(), simplecontent.UploadContentRequest{
+        OwnerID:      uuid.New(),
+        TenantID:     uuid.New(),
+        Name:         "report",
+        DocumentType: "application/pdf",
+        Reader:       strings.NewReader("%PDF"),
+        FileName:     "report.pdf",
+    })
+    if err != nil {
+        t.Fatalf("upload content: %v", err)
+    }
+    base := "/api/v1/contents/" + content.ID.String()
+
+    cases := []struct {
+        path string
+        want string
+    }{
+        {base + "/download", `attachment; filename="report.pdf"`},
+        {base + "/download?disposition=inline", `inline; filename="report.pdf"`},
+        {base + "/download?filename=Q3%20report.pdf", `attachment; filename="Q3 report.pdf"`},
+        {base + "/preview", `inline; filename="report.pdf"`},
+        {base + "/preview?disposition=attachment", `attachment; filename="report.pdf"`},
+    }
+    for _, tc := range cases {
+        rec := doJSON(t, ts, http.MethodGet, tc.path, nil)
+        if rec.Code != http.StatusOK {
+            t.Fatalf("%s: expected 200, got %d: %s", tc.path, rec.Code, rec.Body.String())
+        }
+        if got := rec.Header().Get("Content-Disposition"); got != tc.want {
+            t.Fatalf("%s: expected Content-Disposition %q, got %q", tc.path, tc.want, got)
+        }
+    }
+
+    rec := doJSON(t, ts, http.MethodGet, base+"/download?disposition=embed", nil)
+    if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_disposition") {
+        t.Fatalf("expected 400 invalid_disposition, got %d: %s", rec.Code, rec.Body.String())
+    }
+}
+
+func TestZeroByteContentUpload(t *testing.T) {
+    svc, ts := newTestServer(t)
+    content, err := svc.CreateContent(context.Background(), simplecontent.CreateContentRequest{
+        OwnerID:      uuid.New(),
+        TenantID:     uuid.New(),
+        Name:         "empty",
+        DocumentType: "text/plain",
+    })
+    if err != nil {
+        t.Fatalf("create content: %v", err)
+    }
+    base := "/api/v1/contents/" + content.ID.String()
+
+    req := httptest.NewRequest(http.MethodPost, base+"/upload", strings.NewReader(""))
+    rec := httptest.NewRecorder()
+    ts.Routes().ServeHTTP(rec, req)
+    if rec.Code >= 300 {
+        t.Fatalf("upload: expected success, got %d: %s", rec.Code, rec.Body.String())
+    }
+
+    rec = doJSON(t, ts, http.MethodGet, base+"/download", nil)
+    if rec.Code != http.StatusOK {
+        t.Fatalf("download: expected 200, got %d: %s", rec.Code, rec.Body.String())
+    }
+    if rec.Body.Len() != 0 || rec.Header().Get("Content-Length") != "0" {
+        t.Fatalf("expected an empty body with Content-Length 0, got %d bytes, Content-Length %q", rec.Body.Len(), rec.Header().Get("Content-Length"))
+    }
+}
+
+func TestDefaultTenantAndOwnerEndpoints(t *testing.T) {
+    tenantID, ownerID := uuid.New(), uuid.New()
+    svc, err := simplecontent.New(
+        simplecontent.WithRepository(memoryrepo.New()),
+        simplecontent.WithBlobStore("memory", memorystorage.New()),
+        simplecontent.WithDefaultTenant(tenantID),
+        simplecontent.WithDefaultOwner(ownerID),
+    )
+    if err != nil {
+        t.Fatalf("service create error: %v", err)
+    }
+    ts := NewHTTPServer(svc, &config.ServerConfig{
+        ServiceConfig: config.ServiceConfig{
+            DatabaseType:          "memory",
+            DefaultStorageBackend: "memory",
+            DefaultTenantID:       tenantID.String(),
+            DefaultOwnerID:        ownerID.String(),
+        },
+        Environment: "testing",
+    })
+
+    // owner_id and tenant_id may be omitted
+    rr := doJSON(t, ts, http.MethodPost, "/api/v1/contents", map[string]any{"name": "notes"})
+    if rr.Code != http.StatusCreated {
+        t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var created struct {
+        OwnerID  string `json:"owner_id"`
+        TenantID string `json:"tenant_id"`
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+        t.Fatalf("invalid create content response: %v, body=%s", err, rr.Body.String())
+    }
+    if created.OwnerID != ownerID.String() || created.TenantID != tenantID.String() {
+        t.Errorf("expected default owner and tenant, got %s and %s", created.OwnerID, created.TenantID)
+    }
+
+    rr = doJSON(t, ts, http.MethodGet, "/api/v1/contents", nil)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200 for list, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var listed []map[string]any
+    if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil || len(listed) != 1 {
+        t.Fatalf("expected one listed content, got %v, body=%s", err, rr.Body.String())
+    }
+
+    // A malformed ID is still rejected
+    rr = doJSON(t, ts, http.MethodPost, "/api/v1/contents", map[string]any{"owner_id": "bob", "name": "notes"})
+    if rr.Code != http.StatusBadRequest {
+        t.Errorf("expected 400 for invalid owner_id, got %d", rr.Code)
+    }
+}
+
+func TestRoutesWithPresignedSigner(t *testing.T) {
+    svc, err := simplecontent.New(
+        simplecontent.WithRepository(memoryrepo.New()),
+        simplecontent.WithBlobStore("memory", memorystorage.New()),
+    )
+    if err != nil {
+        t.Fatalf("service create error: %v", err)
+    }
+    // A hand-built config is not validated; building the routes must not exit the process
+    ts := NewHTTPServer(svc, &config.ServerConfig{
+        ServiceConfig: config.ServiceConfig{
+            DatabaseType:          "memory",
+            DefaultStorageBackend: "memory",
+            StorageBackends:       []config.StorageBackendConfig{{Name: "memory", Type: "memory"}},
+        },
+        Environment: "testing",
+        Presigned:   config.PresignedConfig{SecretKey: "too-short"},
+    })
+    rr := doJSON(t, ts, http.MethodGet, "/health", nil)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200 from health, got %d", rr.Code)
+    }
+
+    // The signer passed in validates presigned requests on backends without their own
+    ts.requestSigner = presigned.New(presigned.WithSecretKey("0123456789abcdef0123456789abcdef"))
+    req := httptest.NewRequest(http.MethodPut, "/api/v1/upload/unsigned.txt", strings.NewReader("data"))
+    rec := httptest.NewRecorder()
+    ts.Routes().ServeHTTP(rec, req)
+    if rec.Code != http.StatusUnauthorized {
+        t.Fatalf("expected 401 for an unsigned upload, got %d: %s", rec.Code, rec.Body.String())
+    }
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -68,6 +69,14 @@ func main() {
 
 	// Create HTTP server
 	server := NewHTTPServer(svc, port, storageDir)
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		for _, origin := range strings.Split(origins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				server.corsAllowedOrigins = append(server.corsAllowedOrigins, origin)
+			}
+		}
+		server.corsAllowCredentials = os.Getenv("CORS_ALLOW_CREDENTIALS") == "true"
+	}
 
 	// Create HTTP server instance
 	httpServer := &http.Server{
@@ -120,6 +129,9 @@ type HTTPServer struct {
 	service    simplecontent.Service
 	port       string
 	storageDir string
+
+	corsAllowedOrigins   []string
+	corsAllowCredentials bool
 }
 
 // NewHTTPServer creates a new HTTP server wrapper
@@ -142,21 +154,12 @@ func (s *HTTPServer) Routes() http.Handler {
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Timeout(60 * time.Second))
 
-	// CORS for development
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-
-			next.ServeHTTP(w, r)
-		})
-	})
+	// CORS: any origin unless CORS_ALLOWED_ORIGINS restricts it
+	r.Use(api.CORSMiddlewareWithOptions(api.CORSOptions{
+		AllowedOrigins:   s.corsAllowedOrigins,
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: s.corsAllowCredentials,
+	}))
 
 	// Health check
 	r.Get("/health", s.handleHealth)
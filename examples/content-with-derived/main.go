@@ -13,6 +13,7 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/api"
 	"github.com/tendant/simple-content/pkg/simplecontent/config"
 )
 
@@ -310,7 +311,7 @@ func (ecs *ExtendedContentService) handleGetContentWithDerived(w http.ResponseWr
 	contentIDStr := chi.URLParam(r, "contentID")
 	contentID, err := uuid.Parse(contentIDStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID")
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
 		return
 	}
 
@@ -333,7 +334,7 @@ func (ecs *ExtendedContentService) handleGetContentWithDerived(w http.ResponseWr
 
 	result, err := ecs.GetContentWithDerived(r.Context(), contentID, opts)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "fetch_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "fetch_failed", err.Error(), nil)
 		return
 	}
 
@@ -347,12 +348,12 @@ func (ecs *ExtendedContentService) handleGetMultipleContentWithDerived(w http.Re
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body")
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body", nil)
 		return
 	}
 
 	if len(req.ContentIDs) == 0 {
-		writeError(w, http.StatusBadRequest, "missing_content_ids", "content_ids is required")
+		api.WriteError(w, http.StatusBadRequest, "missing_content_ids", "content_ids is required", nil)
 		return
 	}
 
@@ -361,7 +362,7 @@ func (ecs *ExtendedContentService) handleGetMultipleContentWithDerived(w http.Re
 	for _, idStr := range req.ContentIDs {
 		id, err := uuid.Parse(idStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_content_id", fmt.Sprintf("Invalid content ID: %s", idStr))
+			api.WriteError(w, http.StatusBadRequest, "invalid_content_id", fmt.Sprintf("Invalid content ID: %s", idStr), nil)
 			return
 		}
 		contentIDs = append(contentIDs, id)
@@ -377,7 +378,7 @@ func (ecs *ExtendedContentService) handleGetMultipleContentWithDerived(w http.Re
 
 	results, err := ecs.GetMultipleContentWithDerived(r.Context(), contentIDs, req.Options)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "fetch_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "fetch_failed", err.Error(), nil)
 		return
 	}
 
@@ -391,7 +392,7 @@ func (ecs *ExtendedContentService) handleGetContentHierarchy(w http.ResponseWrit
 	contentIDStr := chi.URLParam(r, "contentID")
 	contentID, err := uuid.Parse(contentIDStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID")
+		api.WriteError(w, http.StatusBadRequest, "invalid_content_id", "contentID must be a UUID", nil)
 		return
 	}
 
@@ -414,7 +415,7 @@ func (ecs *ExtendedContentService) handleGetContentHierarchy(w http.ResponseWrit
 
 	result, err := ecs.GetContentHierarchy(r.Context(), contentID, opts)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "fetch_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "fetch_failed", err.Error(), nil)
 		return
 	}
 
@@ -431,7 +432,7 @@ func (ecs *ExtendedContentService) handleCreateContent(w http.ResponseWriter, r
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON")
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON", nil)
 		return
 	}
 
@@ -447,7 +448,7 @@ func (ecs *ExtendedContentService) handleCreateContent(w http.ResponseWriter, r
 		DocumentType: req.DocumentType,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "create_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "create_failed", err.Error(), nil)
 		return
 	}
 
@@ -458,7 +459,7 @@ func (ecs *ExtendedContentService) handleCreateDerivedContent(w http.ResponseWri
 	parentIDStr := chi.URLParam(r, "parentID")
 	parentID, err := uuid.Parse(parentIDStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_parent_id", "parentID must be a UUID")
+		api.WriteError(w, http.StatusBadRequest, "invalid_parent_id", "parentID must be a UUID", nil)
 		return
 	}
 
@@ -472,14 +473,14 @@ func (ecs *ExtendedContentService) handleCreateDerivedContent(w http.ResponseWri
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON")
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON", nil)
 		return
 	}
 
 	// Get parent content for owner/tenant info
 	parentContent, err := ecs.svc.GetContent(r.Context(), parentID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "parent_not_found", "Parent content not found")
+		api.WriteError(w, http.StatusNotFound, "parent_not_found", "Parent content not found", nil)
 		return
 	}
 
@@ -492,7 +493,7 @@ func (ecs *ExtendedContentService) handleCreateDerivedContent(w http.ResponseWri
 		Metadata:       req.Metadata,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "create_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "create_failed", err.Error(), nil)
 		return
 	}
 
@@ -503,7 +504,7 @@ func (ecs *ExtendedContentService) handleCreateDerivedContent(w http.ResponseWri
 
 	err = ecs.svc.UpdateContent(r.Context(), simplecontent.UpdateContentRequest{Content: content})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "update_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "update_failed", err.Error(), nil)
 		return
 	}
 
@@ -520,7 +521,7 @@ func (ecs *ExtendedContentService) handleListContents(w http.ResponseWriter, r *
 		TenantID: tenantID,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "list_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "list_failed", err.Error(), nil)
 		return
 	}
 
@@ -542,7 +543,7 @@ func (ecs *ExtendedContentService) handleDemoSetup(w http.ResponseWriter, r *htt
 		DocumentType: "image/jpeg",
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "setup_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "setup_failed", err.Error(), nil)
 		return
 	}
 
@@ -561,7 +562,7 @@ func (ecs *ExtendedContentService) handleDemoSetup(w http.ResponseWriter, r *htt
 			},
 		})
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "setup_failed", err.Error())
+			api.WriteError(w, http.StatusInternalServerError, "setup_failed", err.Error(), nil)
 			return
 		}
 	}
@@ -579,7 +580,7 @@ func (ecs *ExtendedContentService) handleDemoSetup(w http.ResponseWriter, r *htt
 		},
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "setup_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "setup_failed", err.Error(), nil)
 		return
 	}
 
@@ -600,17 +601,6 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func writeError(w http.ResponseWriter, status int, code, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": map[string]string{
-			"code":    code,
-			"message": message,
-		},
-	})
-}
-
 func (ecs *ExtendedContentService) serveDemoPage(w http.ResponseWriter, r *http.Request) {
 	html := `<!DOCTYPE html>
 <html lang="en">
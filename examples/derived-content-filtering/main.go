@@ -14,6 +14,7 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/api"
 	"github.com/tendant/simple-content/pkg/simplecontent/config"
 )
 
@@ -404,7 +405,7 @@ func (eds *EnhancedDerivedContentService) handleFilterDerivedContent(w http.Resp
 
 	results, err := eds.ListDerivedContentWithFilters(r.Context(), params)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "filter_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "filter_failed", err.Error(), nil)
 		return
 	}
 
@@ -418,13 +419,13 @@ func (eds *EnhancedDerivedContentService) handleFilterDerivedContent(w http.Resp
 func (eds *EnhancedDerivedContentService) handleFilterThumbnails(w http.ResponseWriter, r *http.Request) {
 	parentIDStr := r.URL.Query().Get("parent_id")
 	if parentIDStr == "" {
-		writeError(w, http.StatusBadRequest, "missing_parent_id", "parent_id is required")
+		api.WriteError(w, http.StatusBadRequest, "missing_parent_id", "parent_id is required", nil)
 		return
 	}
 
 	parentID, err := uuid.Parse(parentIDStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_parent_id", "Invalid parent_id format")
+		api.WriteError(w, http.StatusBadRequest, "invalid_parent_id", "Invalid parent_id format", nil)
 		return
 	}
 
@@ -440,7 +441,7 @@ func (eds *EnhancedDerivedContentService) handleFilterThumbnails(w http.Response
 
 	results, err := eds.GetThumbnailsBySize(r.Context(), parentID, sizes)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "filter_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "filter_failed", err.Error(), nil)
 		return
 	}
 
@@ -455,13 +456,13 @@ func (eds *EnhancedDerivedContentService) handleFilterThumbnails(w http.Response
 func (eds *EnhancedDerivedContentService) handleRecentDerived(w http.ResponseWriter, r *http.Request) {
 	parentIDStr := r.URL.Query().Get("parent_id")
 	if parentIDStr == "" {
-		writeError(w, http.StatusBadRequest, "missing_parent_id", "parent_id is required")
+		api.WriteError(w, http.StatusBadRequest, "missing_parent_id", "parent_id is required", nil)
 		return
 	}
 
 	parentID, err := uuid.Parse(parentIDStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_parent_id", "Invalid parent_id format")
+		api.WriteError(w, http.StatusBadRequest, "invalid_parent_id", "Invalid parent_id format", nil)
 		return
 	}
 
@@ -470,7 +471,7 @@ func (eds *EnhancedDerivedContentService) handleRecentDerived(w http.ResponseWri
 	if sinceStr != "" {
 		since, err = time.Parse(time.RFC3339, sinceStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_since", "Invalid since format, use RFC3339")
+			api.WriteError(w, http.StatusBadRequest, "invalid_since", "Invalid since format, use RFC3339", nil)
 			return
 		}
 	} else {
@@ -479,7 +480,7 @@ func (eds *EnhancedDerivedContentService) handleRecentDerived(w http.ResponseWri
 
 	results, err := eds.GetRecentDerived(r.Context(), parentID, since)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "filter_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "filter_failed", err.Error(), nil)
 		return
 	}
 
@@ -501,7 +502,7 @@ func (eds *EnhancedDerivedContentService) handleCreateContent(w http.ResponseWri
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON")
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON", nil)
 		return
 	}
 
@@ -516,7 +517,7 @@ func (eds *EnhancedDerivedContentService) handleCreateContent(w http.ResponseWri
 		DocumentType: req.DocumentType,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "create_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "create_failed", err.Error(), nil)
 		return
 	}
 
@@ -527,7 +528,7 @@ func (eds *EnhancedDerivedContentService) handleCreateDerivedContent(w http.Resp
 	parentIDStr := chi.URLParam(r, "parentID")
 	parentID, err := uuid.Parse(parentIDStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_parent_id", "parentID must be a UUID")
+		api.WriteError(w, http.StatusBadRequest, "invalid_parent_id", "parentID must be a UUID", nil)
 		return
 	}
 
@@ -538,13 +539,13 @@ func (eds *EnhancedDerivedContentService) handleCreateDerivedContent(w http.Resp
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON")
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON", nil)
 		return
 	}
 
 	parentContent, err := eds.svc.GetContent(r.Context(), parentID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "parent_not_found", "Parent content not found")
+		api.WriteError(w, http.StatusNotFound, "parent_not_found", "Parent content not found", nil)
 		return
 	}
 
@@ -563,7 +564,7 @@ func (eds *EnhancedDerivedContentService) handleCreateDerivedContent(w http.Resp
 		Metadata:       req.Metadata,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "create_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "create_failed", err.Error(), nil)
 		return
 	}
 
@@ -579,7 +580,7 @@ func (eds *EnhancedDerivedContentService) handleListContents(w http.ResponseWrit
 		TenantID: tenantID,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "list_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "list_failed", err.Error(), nil)
 		return
 	}
 
@@ -601,7 +602,7 @@ func (eds *EnhancedDerivedContentService) handleDemoSetup(w http.ResponseWriter,
 		DocumentType: "image/jpeg",
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "setup_failed", err.Error())
+		api.WriteError(w, http.StatusInternalServerError, "setup_failed", err.Error(), nil)
 		return
 	}
 
@@ -750,17 +751,6 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func writeError(w http.ResponseWriter, status int, code, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": map[string]string{
-			"code":    code,
-			"message": message,
-		},
-	})
-}
-
 func stringPtr(s string) *string {
 	return &s
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/api"
 	"github.com/tendant/simple-content/pkg/simplecontent/config"
 )
 
@@ -170,24 +171,9 @@ func (dus *PresignedUploadService) ConfirmUpload(ctx context.Context, req Confir
 		return fmt.Errorf("invalid object_id: %w", err)
 	}
 
-	// Get the object to update its status
-	object, err := dus.storageSvc.GetObject(ctx, objectID)
-	if err != nil {
-		return fmt.Errorf("failed to get object: %w", err)
-	}
-
-	// Update object status to indicate upload completion
-	object.Status = string(simplecontent.ObjectStatusUploaded)
-	err = dus.storageSvc.UpdateObject(ctx, object)
-	if err != nil {
-		return fmt.Errorf("failed to update object status: %w", err)
-	}
-
-	// Sync metadata from storage backend
-	_, err = dus.storageSvc.UpdateObjectMetaFromStorage(ctx, objectID)
-	if err != nil {
-		log.Printf("Warning: failed to sync object metadata from storage: %v", err)
-		// Don't fail the confirmation for metadata sync issues
+	// Verify the blob exists, sync metadata and mark the object uploaded
+	if _, err := dus.storageSvc.ConfirmUpload(ctx, objectID); err != nil {
+		return fmt.Errorf("failed to confirm upload: %w", err)
 	}
 
 	log.Printf("Upload confirmed for object %s", objectID)
@@ -242,20 +228,20 @@ func (dus *PresignedUploadService) GetUploadStatus(ctx context.Context, objectID
 func (dus *PresignedUploadService) handlePrepareUpload(w http.ResponseWriter, r *http.Request) {
 	var req PrepareUploadRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body")
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body", nil)
 		return
 	}
 
 	// Validate required fields
 	if req.OwnerID == "" || req.TenantID == "" || req.FileName == "" || req.Name == "" {
-		writeError(w, http.StatusBadRequest, "missing_required_fields", "owner_id, tenant_id, file_name, and name are required")
+		api.WriteError(w, http.StatusBadRequest, "missing_required_fields", "owner_id, tenant_id, file_name, and name are required", nil)
 		return
 	}
 
 	response, err := dus.PreparePresignedUpload(r.Context(), req)
 	if err != nil {
 		log.Printf("Failed to prepare upload: %v", err)
-		writeError(w, http.StatusBadRequest, "prepare_failed", err.Error())
+		api.WriteError(w, http.StatusBadRequest, "prepare_failed", err.Error(), nil)
 		return
 	}
 
@@ -265,19 +251,19 @@ func (dus *PresignedUploadService) handlePrepareUpload(w http.ResponseWriter, r
 func (dus *PresignedUploadService) handleConfirmUpload(w http.ResponseWriter, r *http.Request) {
 	var req ConfirmUploadRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body")
+		api.WriteError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON in request body", nil)
 		return
 	}
 
 	if req.ObjectID == "" {
-		writeError(w, http.StatusBadRequest, "missing_object_id", "object_id is required")
+		api.WriteError(w, http.StatusBadRequest, "missing_object_id", "object_id is required", nil)
 		return
 	}
 
 	err := dus.ConfirmUpload(r.Context(), req)
 	if err != nil {
 		log.Printf("Failed to confirm upload: %v", err)
-		writeError(w, http.StatusBadRequest, "confirm_failed", err.Error())
+		api.WriteError(w, http.StatusBadRequest, "confirm_failed", err.Error(), nil)
 		return
 	}
 
@@ -290,14 +276,14 @@ func (dus *PresignedUploadService) handleConfirmUpload(w http.ResponseWriter, r
 func (dus *PresignedUploadService) handleGetUploadStatus(w http.ResponseWriter, r *http.Request) {
 	objectID := chi.URLParam(r, "objectID")
 	if objectID == "" {
-		writeError(w, http.StatusBadRequest, "missing_object_id", "objectID parameter is required")
+		api.WriteError(w, http.StatusBadRequest, "missing_object_id", "objectID parameter is required", nil)
 		return
 	}
 
 	status, err := dus.GetUploadStatus(r.Context(), objectID)
 	if err != nil {
 		log.Printf("Failed to get upload status: %v", err)
-		writeError(w, http.StatusNotFound, "status_not_found", err.Error())
+		api.WriteError(w, http.StatusNotFound, "status_not_found", err.Error(), nil)
 		return
 	}
 
@@ -309,19 +295,19 @@ func (dus *PresignedUploadService) handleListContent(w http.ResponseWriter, r *h
 	tenantIDStr := r.URL.Query().Get("tenant_id")
 
 	if ownerIDStr == "" || tenantIDStr == "" {
-		writeError(w, http.StatusBadRequest, "missing_parameters", "owner_id and tenant_id query parameters are required")
+		api.WriteError(w, http.StatusBadRequest, "missing_parameters", "owner_id and tenant_id query parameters are required", nil)
 		return
 	}
 
 	ownerID, err := uuid.Parse(ownerIDStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_owner_id", "Invalid owner_id format")
+		api.WriteError(w, http.StatusBadRequest, "invalid_owner_id", "Invalid owner_id format", nil)
 		return
 	}
 
 	tenantID, err := uuid.Parse(tenantIDStr)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_tenant_id", "Invalid tenant_id format")
+		api.WriteError(w, http.StatusBadRequest, "invalid_tenant_id", "Invalid tenant_id format", nil)
 		return
 	}
 
@@ -331,7 +317,7 @@ func (dus *PresignedUploadService) handleListContent(w http.ResponseWriter, r *h
 	})
 	if err != nil {
 		log.Printf("Failed to list content: %v", err)
-		writeError(w, http.StatusInternalServerError, "list_failed", "Failed to list content")
+		api.WriteError(w, http.StatusInternalServerError, "list_failed", "Failed to list content", nil)
 		return
 	}
 
@@ -349,17 +335,6 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func writeError(w http.ResponseWriter, status int, code, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": map[string]string{
-			"code":    code,
-			"message": message,
-		},
-	})
-}
-
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
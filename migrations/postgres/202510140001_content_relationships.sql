@@ -0,0 +1,17 @@
+-- +goose Up
+-- Typed relationships between contents (attachments, references, related).
+-- Parent -> derived relationships remain in content_derived.
+CREATE TABLE IF NOT EXISTS content_relationship (
+    from_content_id UUID NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    to_content_id UUID NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    relation_type VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    PRIMARY KEY (from_content_id, to_content_id, relation_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_relationship_to ON content_relationship(to_content_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_relationship_to;
+DROP TABLE IF EXISTS content_relationship;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Audit log of content downloads and previews. content_id has no foreign key so the trail
+-- outlives the content row when it is purged.
+CREATE TABLE IF NOT EXISTS content_access_event (
+    id UUID PRIMARY KEY,
+    content_id UUID NOT NULL,
+    object_id UUID,
+    action VARCHAR(32) NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc')
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_access_event_content ON content_access_event(content_id, occurred_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_access_event_content;
+DROP TABLE IF EXISTS content_access_event;
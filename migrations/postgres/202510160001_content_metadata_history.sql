@@ -0,0 +1,22 @@
+-- +goose Up
+-- Versions of content metadata, one row per write.
+CREATE TABLE IF NOT EXISTS content_metadata_history (
+    id BIGSERIAL PRIMARY KEY,
+    content_id UUID NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    tags TEXT[],
+    file_size BIGINT,
+    file_name VARCHAR(500),
+    mime_type VARCHAR(100),
+    checksum VARCHAR(100),
+    checksum_algorithm VARCHAR(50),
+    metadata JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    actor TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_metadata_history_content ON content_metadata_history(content_id, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_metadata_history_content;
+DROP TABLE IF EXISTS content_metadata_history;
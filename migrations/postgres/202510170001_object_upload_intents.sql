@@ -0,0 +1,15 @@
+-- +goose Up
+-- Write-ahead records of uploads in progress, removed once the upload is committed.
+CREATE TABLE IF NOT EXISTS object_upload_intent (
+    object_id UUID PRIMARY KEY,
+    content_id UUID NOT NULL,
+    storage_backend_name VARCHAR(64) NOT NULL,
+    object_key VARCHAR(1024) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc')
+);
+
+CREATE INDEX IF NOT EXISTS idx_object_upload_intent_created ON object_upload_intent(created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_object_upload_intent_created;
+DROP TABLE IF EXISTS object_upload_intent;
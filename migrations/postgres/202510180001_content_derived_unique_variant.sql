@@ -0,0 +1,22 @@
+-- +goose Up
+-- One live derived content per parent and variant. Relationships without a variant are
+-- left alone; the service stores the derivation type as the variant when none is given.
+-- Relationships of deleted derived content no longer hold their variant.
+UPDATE content_derived cd SET deleted_at = c.deleted_at
+FROM content c
+WHERE c.id = cd.content_id AND c.deleted_at IS NOT NULL AND cd.deleted_at IS NULL;
+
+-- Earlier duplicates keep the oldest derived content of each variant; later ones are detached.
+UPDATE content_derived SET deleted_at = NOW()
+WHERE deleted_at IS NULL AND variant <> '' AND (parent_id, content_id) NOT IN (
+    SELECT DISTINCT ON (parent_id, variant) parent_id, content_id
+    FROM content_derived
+    WHERE deleted_at IS NULL AND variant <> ''
+    ORDER BY parent_id, variant, created_at, content_id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_content_derived_parent_variant_unique
+    ON content_derived(parent_id, variant) WHERE deleted_at IS NULL AND variant <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_derived_parent_variant_unique;
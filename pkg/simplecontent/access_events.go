@@ -0,0 +1,77 @@
+package simplecontent
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessAction identifies how content was accessed
+type AccessAction string
+
+const (
+	AccessActionDownload AccessAction = "download"
+	AccessActionPreview  AccessAction = "preview"
+)
+
+// AccessEvent records that content data was served to a client
+type AccessEvent struct {
+	ID         uuid.UUID    `json:"id"`
+	ContentID  uuid.UUID    `json:"content_id"`
+	ObjectID   uuid.UUID    `json:"object_id"`
+	Action     AccessAction `json:"action"`
+	Actor      string       `json:"actor,omitempty"` // empty when the request was anonymous
+	OccurredAt time.Time    `json:"occurred_at"`
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a context carrying the identity of the caller. RecordContentAccess
+// stores it on access events so the audit log shows who accessed the content.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or "" if none was set
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return actor
+}
+
+// RecordContentAccess stores an access event and fires ContentDownloaded or
+// ContentPreviewed on the event sink. ID and OccurredAt are filled in when zero, and
+// Actor defaults to the actor from the context. Callers serving content should record
+// the access only once data has started streaming to the client.
+func (s *service) RecordContentAccess(ctx context.Context, event *AccessEvent) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	if event.Actor == "" {
+		event.Actor = ActorFromContext(ctx)
+	}
+
+	if err := s.repository.CreateAccessEvent(ctx, event); err != nil {
+		return &ContentError{ContentID: event.ContentID, Op: "record_access", Err: err}
+	}
+
+	if s.eventSink != nil {
+		if event.Action == AccessActionPreview {
+			if err := s.eventSink.ContentPreviewed(ctx, event); err != nil {
+				// Log error but don't fail the operation
+				slog.Error("Failed to emit ContentPreviewed event", "content_id", event.ContentID, "error", err)
+			}
+		} else if err := s.eventSink.ContentDownloaded(ctx, event); err != nil {
+			// Log error but don't fail the operation
+			slog.Error("Failed to emit ContentDownloaded event", "content_id", event.ContentID, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"github.com/google/uuid"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// Filter is a composable condition on content columns, e.g.
+//
+//	admin.Where("document_type").In("a", "b").And("status").Eq("uploaded")
+//
+// Set it as ContentFilters.Where to filter on fields, or OR groups, that ContentFilters has
+// no field for.
+type Filter = simplecontent.ContentFilter
+
+// Where starts a Filter on the named content column, such as "status" or "owner_type"
+func Where(field string) *simplecontent.FilterCondition {
+	return simplecontent.Where(field)
+}
+
+// AllOf matches content that every filter matches
+func AllOf(filters ...*Filter) *Filter {
+	return simplecontent.AllOf(filters...)
+}
+
+// AnyOf matches content that at least one of the filters matches
+func AnyOf(filters ...*Filter) *Filter {
+	return simplecontent.AnyOf(filters...)
+}
+
+// Filter compiles the column conditions of f, including Where, into a single Filter. It
+// returns nil when f has none. Pagination, sorting and the special flags are not part of it.
+func (f ContentFilters) Filter() *Filter {
+	var conditions []*Filter
+	addUUIDs := func(field string, one *uuid.UUID, many []uuid.UUID) {
+		if one != nil {
+			conditions = append(conditions, Where(field).Eq(*one))
+		}
+		if len(many) > 0 {
+			values := make([]interface{}, len(many))
+			for i, id := range many {
+				values[i] = id
+			}
+			conditions = append(conditions, Where(field).In(values...))
+		}
+	}
+	addStrings := func(field string, one *string, many []string) {
+		if one != nil {
+			conditions = append(conditions, Where(field).Eq(*one))
+		}
+		if len(many) > 0 {
+			values := make([]interface{}, len(many))
+			for i, s := range many {
+				values[i] = s
+			}
+			conditions = append(conditions, Where(field).In(values...))
+		}
+	}
+
+	addUUIDs("tenant_id", f.TenantID, f.TenantIDs)
+	addUUIDs("owner_id", f.OwnerID, f.OwnerIDs)
+	addStrings("status", f.Status, f.Statuses)
+	addStrings("derivation_type", f.DerivationType, f.DerivationTypes)
+	addStrings("document_type", f.DocumentType, f.DocumentTypes)
+	if f.CreatedAfter != nil {
+		conditions = append(conditions, Where("created_at").Gte(*f.CreatedAfter))
+	}
+	if f.CreatedBefore != nil {
+		conditions = append(conditions, Where("created_at").Lte(*f.CreatedBefore))
+	}
+	if f.UpdatedAfter != nil {
+		conditions = append(conditions, Where("updated_at").Gte(*f.UpdatedAfter))
+	}
+	if f.UpdatedBefore != nil {
+		conditions = append(conditions, Where("updated_at").Lte(*f.UpdatedBefore))
+	}
+	if f.Where != nil {
+		conditions = append(conditions, f.Where)
+	}
+
+	switch len(conditions) {
+	case 0:
+		return nil
+	case 1:
+		return conditions[0]
+	}
+	return AllOf(conditions...)
+}
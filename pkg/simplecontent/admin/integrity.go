@@ -0,0 +1,260 @@
+package admin
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// IntegrityIssueKind names a discrepancy found by CheckContentIntegrity
+type IntegrityIssueKind string
+
+const (
+	IntegrityNoObjects          IntegrityIssueKind = "no_objects"          // The content has no objects
+	IntegrityNoUploadedObject   IntegrityIssueKind = "no_uploaded_object"  // None of the content's objects holds uploaded data
+	IntegrityBackendUnavailable IntegrityIssueKind = "backend_unavailable" // The primary object's backend is not registered
+	IntegrityBlobMissing        IntegrityIssueKind = "blob_missing"        // The primary object's data is not in storage
+	IntegrityChecksumMismatch   IntegrityIssueKind = "checksum_mismatch"   // The data does not hash to the stored checksum
+	IntegrityMetadataMissing    IntegrityIssueKind = "metadata_missing"    // The primary object has no object metadata
+	IntegritySizeMismatch       IntegrityIssueKind = "size_mismatch"       // A stored size differs from the data's size
+	IntegrityETagMismatch       IntegrityIssueKind = "etag_mismatch"       // The stored ETag differs from the backend's
+)
+
+// IntegrityIssue is one discrepancy between a content's records and its stored data
+type IntegrityIssue struct {
+	Kind     IntegrityIssueKind `json:"kind"`
+	ObjectID *uuid.UUID         `json:"object_id,omitempty"`
+	Field    string             `json:"field,omitempty"`    // Record field that is wrong, e.g. object_metadata.size_bytes
+	Expected string             `json:"expected,omitempty"` // Value recorded in the repository
+	Actual   string             `json:"actual,omitempty"`   // Value found in storage
+	Message  string             `json:"message"`
+	Repaired bool               `json:"repaired,omitempty"` // Set by RepairContentIntegrity once the field is rewritten
+}
+
+// IntegrityReport lists the discrepancies found for a content. ObjectID is the primary
+// object checked, the newest uploaded version, when there is one.
+type IntegrityReport struct {
+	ContentID uuid.UUID        `json:"content_id"`
+	ObjectID  *uuid.UUID       `json:"object_id,omitempty"`
+	Issues    []IntegrityIssue `json:"issues"`
+	CheckedAt time.Time        `json:"checked_at"`
+}
+
+// OK reports whether the check found no discrepancies, or all of them were repaired
+func (r *IntegrityReport) OK() bool {
+	for _, issue := range r.Issues {
+		if !issue.Repaired {
+			return false
+		}
+	}
+	return true
+}
+
+// Has reports whether the report lists an issue of the given kind
+func (r *IntegrityReport) Has(kind IntegrityIssueKind) bool {
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckContentIntegrity verifies a content against its stored data
+func (s *adminService) CheckContentIntegrity(ctx context.Context, contentID uuid.UUID) (*IntegrityReport, error) {
+	return s.checkIntegrity(ctx, contentID, false)
+}
+
+// RepairContentIntegrity verifies a content and rewrites the fields derived from storage
+func (s *adminService) RepairContentIntegrity(ctx context.Context, contentID uuid.UUID) (*IntegrityReport, error) {
+	return s.checkIntegrity(ctx, contentID, true)
+}
+
+func (s *adminService) checkIntegrity(ctx context.Context, contentID uuid.UUID, repair bool) (*IntegrityReport, error) {
+	if _, err := s.repo.GetContent(ctx, contentID); err != nil {
+		return nil, err
+	}
+	report := &IntegrityReport{ContentID: contentID, Issues: []IntegrityIssue{}, CheckedAt: time.Now().UTC()}
+
+	objects, err := s.repo.GetObjectsByContentID(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		report.Issues = append(report.Issues, IntegrityIssue{Kind: IntegrityNoObjects, Message: "content has no objects"})
+		return report, nil
+	}
+	// Objects come newest version first; the first uploaded or processed one is what
+	// downloads serve
+	var primary *simplecontent.Object
+	for _, object := range objects {
+		if status := simplecontent.ObjectStatus(object.Status); status == simplecontent.ObjectStatusUploaded || status == simplecontent.ObjectStatusProcessed {
+			primary = object
+			break
+		}
+	}
+	if primary == nil {
+		report.Issues = append(report.Issues, IntegrityIssue{Kind: IntegrityNoUploadedObject, Message: fmt.Sprintf("none of %d objects is uploaded", len(objects))})
+		return report, nil
+	}
+	objectID := primary.ID
+	report.ObjectID = &objectID
+	issue := func(kind IntegrityIssueKind, message string) IntegrityIssue {
+		return IntegrityIssue{Kind: kind, ObjectID: &objectID, Message: message}
+	}
+
+	backend, ok := s.blobStores[primary.StorageBackendName]
+	if !ok {
+		report.Issues = append(report.Issues, issue(IntegrityBackendUnavailable, fmt.Sprintf("storage backend %q is not registered", primary.StorageBackendName)))
+		return report, nil
+	}
+	blob, err := backend.GetObjectMeta(ctx, primary.ObjectKey)
+	if errors.Is(err, simplecontent.ErrObjectNotFound) || errors.Is(err, simplecontent.ErrBlobNotFound) {
+		report.Issues = append(report.Issues, issue(IntegrityBlobMissing, fmt.Sprintf("no data at key %s on backend %s", primary.ObjectKey, primary.StorageBackendName)))
+		return report, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get object meta of object %s: %w", primary.ID, err)
+	}
+
+	objectMetadata, err := s.repo.GetObjectMetadataByObjectIDs(ctx, []uuid.UUID{primary.ID})
+	if err != nil {
+		return nil, err
+	}
+	var contentMetadata *simplecontent.ContentMetadata
+	if metadata, err := s.repo.GetContentMetadata(ctx, contentID); err == nil {
+		contentMetadata = metadata
+	}
+
+	metadata := objectMetadata[primary.ID]
+	mismatches, err := checkChecksums(ctx, backend, primary, metadata, contentMetadata)
+	if err != nil {
+		return nil, err
+	}
+	report.Issues = append(report.Issues, mismatches...)
+
+	// Sizes and the ETag are derived from storage, so they are the fields that can be repaired
+	objectChanged := false
+	if metadata == nil {
+		missing := issue(IntegrityMetadataMissing, "object has no metadata")
+		if repair {
+			metadata = &simplecontent.ObjectMetadata{
+				ObjectID:  primary.ID,
+				SizeBytes: blob.Size,
+				MimeType:  blob.ContentType,
+				ETag:      blob.ETag,
+				Metadata:  map[string]interface{}{},
+				CreatedAt: time.Now().UTC(),
+			}
+			objectChanged, missing.Repaired = true, true
+		}
+		report.Issues = append(report.Issues, missing)
+	} else {
+		if metadata.SizeBytes != blob.Size {
+			mismatch := issue(IntegritySizeMismatch, "object metadata size differs from the stored data")
+			mismatch.Field = "object_metadata.size_bytes"
+			mismatch.Expected, mismatch.Actual = strconv.FormatInt(metadata.SizeBytes, 10), strconv.FormatInt(blob.Size, 10)
+			if repair {
+				metadata.SizeBytes = blob.Size
+				objectChanged, mismatch.Repaired = true, true
+			}
+			report.Issues = append(report.Issues, mismatch)
+		}
+		if blob.ETag != "" && metadata.ETag != blob.ETag {
+			mismatch := issue(IntegrityETagMismatch, "object metadata etag differs from the backend's")
+			mismatch.Field = "object_metadata.etag"
+			mismatch.Expected, mismatch.Actual = metadata.ETag, blob.ETag
+			if repair {
+				metadata.ETag = blob.ETag
+				objectChanged, mismatch.Repaired = true, true
+			}
+			report.Issues = append(report.Issues, mismatch)
+		}
+	}
+	if objectChanged {
+		metadata.UpdatedAt = time.Now().UTC()
+		if err := s.repo.SetObjectMetadata(ctx, metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	if contentMetadata != nil && contentMetadata.FileSize > 0 && contentMetadata.FileSize != blob.Size {
+		mismatch := issue(IntegritySizeMismatch, "content metadata file size differs from the stored data")
+		mismatch.Field = "content_metadata.file_size"
+		mismatch.Expected, mismatch.Actual = strconv.FormatInt(contentMetadata.FileSize, 10), strconv.FormatInt(blob.Size, 10)
+		if repair {
+			contentMetadata.FileSize = blob.Size
+			contentMetadata.UpdatedAt = time.Now().UTC()
+			if err := s.repo.SetContentMetadata(ctx, contentMetadata); err != nil {
+				return nil, err
+			}
+			mismatch.Repaired = true
+		}
+		report.Issues = append(report.Issues, mismatch)
+	}
+	return report, nil
+}
+
+// storedChecksum is a checksum recorded for an object's data and where it is recorded
+type storedChecksum struct {
+	field     string
+	algorithm string
+	value     string
+}
+
+// checkChecksums hashes the primary object's data when a checksum is recorded for it, the
+// content ETag stored by WithContentETags or a sha256 or md5 content metadata checksum
+func checkChecksums(ctx context.Context, backend simplecontent.BlobStore, object *simplecontent.Object, objectMetadata *simplecontent.ObjectMetadata, contentMetadata *simplecontent.ContentMetadata) ([]IntegrityIssue, error) {
+	var checksums []storedChecksum
+	if objectMetadata != nil {
+		if etag, ok := objectMetadata.Metadata[simplecontent.MetaContentETag].(string); ok && etag != "" {
+			checksums = append(checksums, storedChecksum{field: "object_metadata." + simplecontent.MetaContentETag, algorithm: "sha256", value: strings.Trim(etag, `"`)})
+		}
+	}
+	if contentMetadata != nil && contentMetadata.Checksum != "" {
+		switch algorithm := strings.ToLower(strings.ReplaceAll(contentMetadata.ChecksumAlgorithm, "-", "")); algorithm {
+		case "sha256", "md5":
+			checksums = append(checksums, storedChecksum{field: "content_metadata.checksum", algorithm: algorithm, value: strings.ToLower(contentMetadata.Checksum)})
+		}
+	}
+	if len(checksums) == 0 {
+		return nil, nil
+	}
+
+	reader, err := backend.Download(ctx, object.ObjectKey)
+	if err != nil {
+		return nil, fmt.Errorf("download object %s: %w", object.ID, err)
+	}
+	defer reader.Close()
+	hashes := map[string]hash.Hash{"sha256": sha256.New(), "md5": md5.New()}
+	if _, err := io.Copy(io.MultiWriter(hashes["sha256"], hashes["md5"]), reader); err != nil {
+		return nil, fmt.Errorf("read object %s: %w", object.ID, err)
+	}
+
+	var mismatches []IntegrityIssue
+	for _, checksum := range checksums {
+		actual := hex.EncodeToString(hashes[checksum.algorithm].Sum(nil))
+		if actual != checksum.value {
+			mismatches = append(mismatches, IntegrityIssue{
+				Kind:     IntegrityChecksumMismatch,
+				ObjectID: &object.ID,
+				Field:    checksum.field,
+				Expected: checksum.value,
+				Actual:   actual,
+				Message:  fmt.Sprintf("stored data does not match the recorded %s checksum", checksum.algorithm),
+			})
+		}
+	}
+	return mismatches, nil
+}
@@ -43,6 +43,20 @@ type StatisticsResponse struct {
 	ComputedAt time.Time         `json:"computed_at"`
 }
 
+// CleanupAbandonedRequest selects the abandoned content to delete. The filters are
+// narrowed to content without uploaded objects.
+type CleanupAbandonedRequest struct {
+	Filters   ContentFilters `json:"filters"`
+	OlderThan time.Duration  `json:"older_than"` // Skip content created more recently, whose upload may still be running
+	DryRun    bool           `json:"dry_run"`    // List the content without deleting it
+}
+
+// CleanupAbandonedResponse lists the content deleted, or that would be deleted on a dry run
+type CleanupAbandonedResponse struct {
+	ContentIDs []uuid.UUID `json:"content_ids"`
+	DryRun     bool        `json:"dry_run"`
+}
+
 // ListContentsOption provides functional options for listing contents
 type ListContentsOption func(*ContentFilters)
 
@@ -3,6 +3,7 @@ package admin
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/tendant/simple-content/pkg/simplecontent"
 )
 
@@ -25,11 +26,56 @@ type AdminService interface {
 	// GetStatistics returns aggregated statistics about contents.
 	// This provides breakdown by status, tenant, derivation type, etc.
 	GetStatistics(ctx context.Context, req StatisticsRequest) (*StatisticsResponse, error)
+
+	// ListAccessEvents returns who downloaded or previewed a content and when, oldest first.
+	// Deleted contents keep their access history.
+	ListAccessEvents(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.AccessEvent, error)
+
+	// CleanupAbandonedContents soft-deletes content that never received uploaded data,
+	// together with its objects. Use DryRun to list the candidates first.
+	CleanupAbandonedContents(ctx context.Context, req CleanupAbandonedRequest) (*CleanupAbandonedResponse, error)
+
+	// AddTags adds the tags to every content matching the filters in one batch and returns
+	// how many contents changed. Contents that already carry all the tags are not counted.
+	AddTags(ctx context.Context, filters ContentFilters, tags []string) (int64, error)
+
+	// RemoveTags removes the tags from every content matching the filters in one batch and
+	// returns how many contents changed.
+	RemoveTags(ctx context.Context, filters ContentFilters, tags []string) (int64, error)
+
+	// CheckContentIntegrity verifies that a content has objects, that the data of its primary
+	// object exists with the stored checksum, and that the stored sizes and ETag match the
+	// data, reporting each discrepancy. The data is read through the backends given with
+	// WithBlobStore.
+	CheckContentIntegrity(ctx context.Context, contentID uuid.UUID) (*IntegrityReport, error)
+
+	// RepairContentIntegrity checks a content like CheckContentIntegrity and rewrites the
+	// sizes and ETag derived from storage where they differ. Missing data and checksum
+	// mismatches are reported but cannot be repaired.
+	RepairContentIntegrity(ctx context.Context, contentID uuid.UUID) (*IntegrityReport, error)
+}
+
+// Option configures an AdminService
+type Option func(*adminService)
+
+// WithBlobStore registers a storage backend under the name objects refer to it by, for
+// the operations that read content data
+func WithBlobStore(name string, store simplecontent.BlobStore) Option {
+	return func(s *adminService) {
+		if s.blobStores == nil {
+			s.blobStores = make(map[string]simplecontent.BlobStore)
+		}
+		s.blobStores[name] = store
+	}
 }
 
 // New creates a new AdminService instance that uses the provided repository.
-func New(repo simplecontent.Repository) AdminService {
-	return &adminService{
+func New(repo simplecontent.Repository, opts ...Option) AdminService {
+	s := &adminService{
 		repo: repo,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
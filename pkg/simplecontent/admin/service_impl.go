@@ -2,14 +2,17 @@ package admin
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/tendant/simple-content/pkg/simplecontent"
 )
 
 // adminService implements the AdminService interface
 type adminService struct {
-	repo simplecontent.Repository
+	repo       simplecontent.Repository
+	blobStores map[string]simplecontent.BlobStore // Backends by name, for reading content data
 }
 
 // Ensure adminService implements AdminService
@@ -79,6 +82,7 @@ func (s *adminService) GetStatistics(ctx context.Context, req StatisticsRequest)
 		IncludeDerivationBreakdown:   req.Options.IncludeDerivationBreakdown,
 		IncludeDocumentTypeBreakdown: req.Options.IncludeDocumentTypeBreakdown,
 		IncludeTimeRange:             req.Options.IncludeTimeRange,
+		IncludeDedupBreakdown:        req.Options.IncludeDedupBreakdown,
 	}
 
 	// Get statistics from repository
@@ -96,6 +100,9 @@ func (s *adminService) GetStatistics(ctx context.Context, req StatisticsRequest)
 		ByDocumentType:   repoStats.ByDocumentType,
 		OldestContent:    repoStats.OldestContent,
 		NewestContent:    repoStats.NewestContent,
+		UniqueBlobs:      repoStats.UniqueBlobs,
+		TotalReferences:  repoStats.TotalReferences,
+		BytesSaved:       repoStats.BytesSaved,
 	}
 
 	response := &StatisticsResponse{
@@ -106,48 +113,110 @@ func (s *adminService) GetStatistics(ctx context.Context, req StatisticsRequest)
 	return response, nil
 }
 
+// ListAccessEvents returns the recorded downloads and previews of a content
+func (s *adminService) ListAccessEvents(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.AccessEvent, error) {
+	return s.repo.ListAccessEvents(ctx, contentID)
+}
+
+// CleanupAbandonedContents soft-deletes content with no uploaded object
+func (s *adminService) CleanupAbandonedContents(ctx context.Context, req CleanupAbandonedRequest) (*CleanupAbandonedResponse, error) {
+	repoFilters := s.convertToRepoListFilters(req.Filters)
+	repoFilters.WithoutUploadedObjects = true
+	if req.OlderThan > 0 {
+		cutoff := time.Now().UTC().Add(-req.OlderThan)
+		if repoFilters.CreatedBefore == nil || repoFilters.CreatedBefore.After(cutoff) {
+			repoFilters.CreatedBefore = &cutoff
+		}
+	}
+
+	contents, err := s.repo.ListContentWithFilters(ctx, repoFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CleanupAbandonedResponse{
+		ContentIDs: make([]uuid.UUID, 0, len(contents)),
+		DryRun:     req.DryRun,
+	}
+	for _, content := range contents {
+		response.ContentIDs = append(response.ContentIDs, content.ID)
+	}
+	if req.DryRun || len(response.ContentIDs) == 0 {
+		return response, nil
+	}
+
+	if err := s.repo.DeleteContentTree(ctx, response.ContentIDs); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AddTags adds tags to all content matching the filters
+func (s *adminService) AddTags(ctx context.Context, filters ContentFilters, tags []string) (int64, error) {
+	return s.updateTags(ctx, filters, normalizeTags(tags), nil)
+}
+
+// RemoveTags removes tags from all content matching the filters
+func (s *adminService) RemoveTags(ctx context.Context, filters ContentFilters, tags []string) (int64, error) {
+	return s.updateTags(ctx, filters, nil, normalizeTags(tags))
+}
+
+func (s *adminService) updateTags(ctx context.Context, filters ContentFilters, add, remove []string) (int64, error) {
+	if len(add) == 0 && len(remove) == 0 {
+		return 0, nil
+	}
+	contents, err := s.repo.ListContentWithFilters(ctx, s.convertToRepoListFilters(filters))
+	if err != nil {
+		return 0, err
+	}
+	if len(contents) == 0 {
+		return 0, nil
+	}
+
+	contentIDs := make([]uuid.UUID, 0, len(contents))
+	for _, content := range contents {
+		contentIDs = append(contentIDs, content.ID)
+	}
+	return s.repo.UpdateContentTags(ctx, contentIDs, add, remove)
+}
+
+// normalizeTags trims tags and drops empty and repeated ones
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
 // convertToRepoListFilters converts admin ContentFilters to repository ContentListFilters
 func (s *adminService) convertToRepoListFilters(filters ContentFilters) simplecontent.ContentListFilters {
 	return simplecontent.ContentListFilters{
-		TenantID:        filters.TenantID,
-		TenantIDs:       filters.TenantIDs,
-		OwnerID:         filters.OwnerID,
-		OwnerIDs:        filters.OwnerIDs,
-		Status:          filters.Status,
-		Statuses:        filters.Statuses,
-		DerivationType:  filters.DerivationType,
-		DerivationTypes: filters.DerivationTypes,
-		DocumentType:    filters.DocumentType,
-		DocumentTypes:   filters.DocumentTypes,
-		CreatedAfter:    filters.CreatedAfter,
-		CreatedBefore:   filters.CreatedBefore,
-		UpdatedAfter:    filters.UpdatedAfter,
-		UpdatedBefore:   filters.UpdatedBefore,
-		Limit:           filters.Limit,
-		Offset:          filters.Offset,
-		SortBy:          filters.SortBy,
-		SortOrder:       filters.SortOrder,
-		IncludeDeleted:  filters.IncludeDeleted,
+		Limit:          filters.Limit,
+		Offset:         filters.Offset,
+		SortBy:         filters.SortBy,
+		SortOrder:      filters.SortOrder,
+		IncludeDeleted: filters.IncludeDeleted,
+		Where:          filters.Filter(),
+
+		WithoutUploadedObjects: filters.WithoutUploadedObjects,
+		OriginalsOnly:          filters.OriginalsOnly,
 	}
 }
 
 // convertToRepoCountFilters converts admin ContentFilters to repository ContentCountFilters
 func (s *adminService) convertToRepoCountFilters(filters ContentFilters) simplecontent.ContentCountFilters {
 	return simplecontent.ContentCountFilters{
-		TenantID:        filters.TenantID,
-		TenantIDs:       filters.TenantIDs,
-		OwnerID:         filters.OwnerID,
-		OwnerIDs:        filters.OwnerIDs,
-		Status:          filters.Status,
-		Statuses:        filters.Statuses,
-		DerivationType:  filters.DerivationType,
-		DerivationTypes: filters.DerivationTypes,
-		DocumentType:    filters.DocumentType,
-		DocumentTypes:   filters.DocumentTypes,
-		CreatedAfter:    filters.CreatedAfter,
-		CreatedBefore:   filters.CreatedBefore,
-		UpdatedAfter:    filters.UpdatedAfter,
-		UpdatedBefore:   filters.UpdatedBefore,
-		IncludeDeleted:  filters.IncludeDeleted,
+		IncludeDeleted: filters.IncludeDeleted,
+		Where:          filters.Filter(),
+
+		WithoutUploadedObjects: filters.WithoutUploadedObjects,
+		OriginalsOnly:          filters.OriginalsOnly,
 	}
 }
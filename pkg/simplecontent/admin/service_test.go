@@ -0,0 +1,407 @@
+package admin_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/admin"
+	"github.com/tendant/simple-content/pkg/simplecontent/repo/memory"
+	memorystorage "github.com/tendant/simple-content/pkg/simplecontent/storage/memory"
+)
+
+func TestCleanupAbandonedContents(t *testing.T) {
+	repo := memory.New()
+	adminSvc := admin.New(repo)
+	ctx := context.Background()
+	old := time.Now().UTC().Add(-48 * time.Hour)
+
+	newContent := func(createdAt time.Time, objectStatus simplecontent.ObjectStatus) uuid.UUID {
+		content := &simplecontent.Content{
+			ID:        uuid.New(),
+			TenantID:  uuid.New(),
+			OwnerID:   uuid.New(),
+			Name:      "content",
+			Status:    string(simplecontent.ContentStatusCreated),
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+		require.NoError(t, repo.CreateContent(ctx, content))
+		if objectStatus != "" {
+			require.NoError(t, repo.CreateObject(ctx, &simplecontent.Object{
+				ID:                 uuid.New(),
+				ContentID:          content.ID,
+				StorageBackendName: "memory",
+				ObjectKey:          content.ID.String(),
+				Version:            1,
+				Status:             string(objectStatus),
+			}))
+		}
+		return content.ID
+	}
+
+	abandoned := newContent(old, "")
+	abandonedPending := newContent(old, simplecontent.ObjectStatusCreated)
+	uploaded := newContent(old, simplecontent.ObjectStatusUploaded)
+	recent := newContent(time.Now().UTC(), "")
+
+	req := admin.CleanupAbandonedRequest{OlderThan: 24 * time.Hour, DryRun: true}
+	resp, err := adminSvc.CleanupAbandonedContents(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, resp.DryRun)
+	assert.ElementsMatch(t, []uuid.UUID{abandoned, abandonedPending}, resp.ContentIDs)
+
+	// A dry run deletes nothing
+	_, err = repo.GetContent(ctx, abandoned)
+	require.NoError(t, err)
+
+	req.DryRun = false
+	resp, err = adminSvc.CleanupAbandonedContents(ctx, req)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{abandoned, abandonedPending}, resp.ContentIDs)
+
+	for _, id := range []uuid.UUID{uploaded, recent} {
+		_, err := repo.GetContent(ctx, id)
+		assert.NoError(t, err)
+	}
+	for _, id := range resp.ContentIDs {
+		content, err := repo.GetContent(ctx, id)
+		if err == nil {
+			assert.NotNil(t, content.DeletedAt)
+		}
+	}
+
+	// Nothing is left to clean up
+	resp, err = adminSvc.CleanupAbandonedContents(ctx, req)
+	require.NoError(t, err)
+	assert.Empty(t, resp.ContentIDs)
+}
+
+func TestBulkTags(t *testing.T) {
+	repo := memory.New()
+	adminSvc := admin.New(repo)
+	ctx := context.Background()
+
+	newContent := func(documentType string, tags ...string) uuid.UUID {
+		content := &simplecontent.Content{
+			ID:           uuid.New(),
+			TenantID:     uuid.New(),
+			OwnerID:      uuid.New(),
+			Name:         "content",
+			DocumentType: documentType,
+			Status:       string(simplecontent.ContentStatusUploaded),
+			CreatedAt:    time.Now().UTC(),
+			UpdatedAt:    time.Now().UTC(),
+		}
+		require.NoError(t, repo.CreateContent(ctx, content))
+		if tags != nil {
+			require.NoError(t, repo.SetContentMetadata(ctx, &simplecontent.ContentMetadata{
+				ContentID: content.ID,
+				MimeType:  documentType,
+				FileName:  "file",
+				Tags:      tags,
+			}))
+		}
+		return content.ID
+	}
+	tagsOf := func(id uuid.UUID) []string {
+		metadata, err := repo.GetContentMetadata(ctx, id)
+		if err != nil {
+			return nil
+		}
+		return metadata.Tags
+	}
+
+	pdfTagged := newContent("application/pdf", "invoice")
+	pdfBare := newContent("application/pdf")
+	pdfDone := newContent("application/pdf", "archive")
+	image := newContent("image/png", "photo")
+
+	pdf := "application/pdf"
+	filters := admin.ContentFilters{DocumentType: &pdf}
+
+	t.Run("AddTags", func(t *testing.T) {
+		count, err := adminSvc.AddTags(ctx, filters, []string{" archive ", "archive", "2024", ""})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		assert.Equal(t, []string{"invoice", "archive", "2024"}, tagsOf(pdfTagged))
+		assert.Equal(t, []string{"archive", "2024"}, tagsOf(pdfBare))
+		assert.Equal(t, []string{"archive", "2024"}, tagsOf(pdfDone))
+		assert.Equal(t, []string{"photo"}, tagsOf(image))
+
+		// Other metadata is kept, and the change is recorded as a new version
+		metadata, err := repo.GetContentMetadata(ctx, pdfTagged)
+		require.NoError(t, err)
+		assert.Equal(t, "file", metadata.FileName)
+		history, err := repo.ListContentMetadataHistory(ctx, pdfTagged)
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+
+		// Adding them again changes nothing
+		count, err = adminSvc.AddTags(ctx, filters, []string{"archive", "2024"})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("RemoveTags", func(t *testing.T) {
+		count, err := adminSvc.RemoveTags(ctx, filters, []string{"invoice", "photo"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		assert.Equal(t, []string{"archive", "2024"}, tagsOf(pdfTagged))
+		assert.Equal(t, []string{"photo"}, tagsOf(image))
+	})
+
+	t.Run("NoTags", func(t *testing.T) {
+		count, err := adminSvc.AddTags(ctx, admin.ContentFilters{}, []string{" "})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
+
+func TestContentFiltersWhere(t *testing.T) {
+	repo := memory.New()
+	adminSvc := admin.New(repo)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	newContent := func(documentType, status string) uuid.UUID {
+		content := &simplecontent.Content{
+			ID:           uuid.New(),
+			TenantID:     tenantID,
+			OwnerID:      uuid.New(),
+			DocumentType: documentType,
+			Status:       status,
+			CreatedAt:    time.Now().UTC(),
+			UpdatedAt:    time.Now().UTC(),
+		}
+		require.NoError(t, repo.CreateContent(ctx, content))
+		return content.ID
+	}
+
+	pdf := newContent("application/pdf", "uploaded")
+	png := newContent("image/png", "uploaded")
+	newContent("image/png", "created")
+	failed := newContent("text/plain", "failed")
+
+	ids := func(filters admin.ContentFilters) []uuid.UUID {
+		resp, err := adminSvc.ListAllContents(ctx, admin.ListContentsRequest{Filters: filters})
+		require.NoError(t, err)
+		var out []uuid.UUID
+		for _, content := range resp.Contents {
+			out = append(out, content.ID)
+		}
+		return out
+	}
+
+	// The struct fields and the Where filter are ANDed
+	assert.ElementsMatch(t, []uuid.UUID{pdf, png, failed}, ids(admin.ContentFilters{
+		TenantID: &tenantID,
+		Where:    admin.Where("document_type").In("application/pdf", "image/png").And("status").Eq("uploaded").Or("status").Eq("failed"),
+	}))
+
+	// The struct compiles to the equivalent Filter
+	statuses := admin.ContentFilters{TenantID: &tenantID, Statuses: []string{"uploaded", "failed"}}
+	assert.ElementsMatch(t, []uuid.UUID{pdf, png, failed}, ids(statuses))
+	assert.Equal(t, admin.AllOf(
+		admin.Where("tenant_id").Eq(tenantID),
+		admin.Where("status").In("uploaded", "failed"),
+	), statuses.Filter())
+	assert.Nil(t, admin.ContentFilters{}.Filter())
+
+	count, err := adminSvc.CountContents(ctx, admin.CountRequest{Filters: admin.ContentFilters{
+		Where: admin.AllOf(admin.Where("tenant_id").Eq(tenantID), admin.Where("status").Ne("uploaded")),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count.Count)
+
+	_, err = adminSvc.CountContents(ctx, admin.CountRequest{Filters: admin.ContentFilters{Where: admin.Where("created_at").Gt("yesterday")}})
+	assert.ErrorIs(t, err, simplecontent.ErrInvalidField)
+}
+
+// etagBlobStore reports a fixed backend ETag, which memory storage does not
+type etagBlobStore struct {
+	simplecontent.BlobStore
+	etag string
+}
+
+func (b *etagBlobStore) GetObjectMeta(ctx context.Context, objectKey string) (*simplecontent.ObjectMeta, error) {
+	meta, err := b.BlobStore.GetObjectMeta(ctx, objectKey)
+	if err == nil {
+		meta.ETag = b.etag
+	}
+	return meta, err
+}
+
+func TestCheckContentIntegrity(t *testing.T) {
+	ctx := context.Background()
+
+	type fixture struct {
+		repo    *memory.Repository
+		store   simplecontent.BlobStore
+		svc     simplecontent.Service
+		storage simplecontent.StorageService
+		admin   admin.AdminService
+	}
+	setup := func(t *testing.T) *fixture {
+		f := &fixture{repo: memory.New().(*memory.Repository), store: &etagBlobStore{BlobStore: memorystorage.New(), etag: `"backend-etag"`}}
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(f.repo),
+			simplecontent.WithBlobStore("memory", f.store),
+			simplecontent.WithContentETags(),
+		)
+		require.NoError(t, err)
+		f.svc, f.storage = svc, svc.(simplecontent.StorageService)
+		f.admin = admin.New(f.repo, admin.WithBlobStore("memory", f.store))
+		return f
+	}
+	upload := func(t *testing.T, f *fixture) (*simplecontent.Content, *simplecontent.Object) {
+		content, err := f.svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "report.txt",
+			FileName: "report.txt",
+			Reader:   strings.NewReader("quarterly numbers"),
+		})
+		require.NoError(t, err)
+		objects, err := f.repo.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return content, objects[0]
+	}
+	check := func(t *testing.T, f *fixture, contentID uuid.UUID) *admin.IntegrityReport {
+		report, err := f.admin.CheckContentIntegrity(ctx, contentID)
+		require.NoError(t, err)
+		return report
+	}
+
+	t.Run("Consistent", func(t *testing.T) {
+		f := setup(t)
+		content, object := upload(t, f)
+		report := check(t, f, content.ID)
+		assert.True(t, report.OK(), "%+v", report.Issues)
+		assert.Equal(t, &object.ID, report.ObjectID)
+	})
+
+	t.Run("Processed", func(t *testing.T) {
+		f := setup(t)
+		content, object := upload(t, f)
+		require.NoError(t, f.svc.UpdateObjectStatus(ctx, object.ID, simplecontent.ObjectStatusProcessed))
+		report := check(t, f, content.ID)
+		assert.True(t, report.OK(), "%+v", report.Issues)
+		assert.Equal(t, &object.ID, report.ObjectID)
+
+		require.NoError(t, f.store.Delete(ctx, object.ObjectKey))
+		assert.True(t, check(t, f, content.ID).Has(admin.IntegrityBlobMissing), "the blob of a processed object is checked")
+	})
+
+	t.Run("NoObjects", func(t *testing.T) {
+		f := setup(t)
+		content, err := f.svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: uuid.New(), TenantID: uuid.New(), Name: "empty"})
+		require.NoError(t, err)
+		report := check(t, f, content.ID)
+		assert.True(t, report.Has(admin.IntegrityNoObjects))
+		assert.Nil(t, report.ObjectID)
+	})
+
+	t.Run("NoUploadedObject", func(t *testing.T) {
+		f := setup(t)
+		content, err := f.svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: uuid.New(), TenantID: uuid.New(), Name: "pending"})
+		require.NoError(t, err)
+		_, err = f.storage.CreateObject(ctx, simplecontent.CreateObjectRequest{ContentID: content.ID, StorageBackendName: "memory", Version: 1})
+		require.NoError(t, err)
+		assert.True(t, check(t, f, content.ID).Has(admin.IntegrityNoUploadedObject))
+	})
+
+	t.Run("BackendUnavailable", func(t *testing.T) {
+		f := setup(t)
+		content, _ := upload(t, f)
+		report, err := admin.New(f.repo).CheckContentIntegrity(ctx, content.ID)
+		require.NoError(t, err)
+		assert.True(t, report.Has(admin.IntegrityBackendUnavailable))
+	})
+
+	t.Run("BlobMissing", func(t *testing.T) {
+		f := setup(t)
+		content, object := upload(t, f)
+		require.NoError(t, f.store.Delete(ctx, object.ObjectKey))
+		assert.True(t, check(t, f, content.ID).Has(admin.IntegrityBlobMissing))
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		f := setup(t)
+		content, object := upload(t, f)
+		require.NoError(t, f.store.Upload(ctx, object.ObjectKey, strings.NewReader("quarterly NUMBERS")))
+		report := check(t, f, content.ID)
+		require.True(t, report.Has(admin.IntegrityChecksumMismatch))
+		assert.Equal(t, "object_metadata."+simplecontent.MetaContentETag, report.Issues[0].Field)
+
+		// Corrupt data cannot be repaired
+		report, err := f.admin.RepairContentIntegrity(ctx, content.ID)
+		require.NoError(t, err)
+		assert.False(t, report.OK())
+	})
+
+	t.Run("SizeAndETagMismatch", func(t *testing.T) {
+		f := setup(t)
+		content, object := upload(t, f)
+		objectMetadata, err := f.repo.GetObjectMetadata(ctx, object.ID)
+		require.NoError(t, err)
+		objectMetadata.SizeBytes = 5
+		objectMetadata.ETag = `"stale"`
+		require.NoError(t, f.repo.SetObjectMetadata(ctx, objectMetadata))
+		contentMetadata, err := f.repo.GetContentMetadata(ctx, content.ID)
+		require.NoError(t, err)
+		contentMetadata.FileSize = 99
+		require.NoError(t, f.repo.SetContentMetadata(ctx, contentMetadata))
+
+		report := check(t, f, content.ID)
+		var fields []string
+		for _, issue := range report.Issues {
+			fields = append(fields, issue.Field)
+			assert.False(t, issue.Repaired)
+		}
+		assert.ElementsMatch(t, []string{"object_metadata.size_bytes", "object_metadata.etag", "content_metadata.file_size"}, fields)
+		assert.True(t, report.Has(admin.IntegritySizeMismatch))
+		assert.True(t, report.Has(admin.IntegrityETagMismatch))
+
+		report, err = f.admin.RepairContentIntegrity(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Len(t, report.Issues, 3)
+		assert.True(t, report.OK())
+
+		assert.Empty(t, check(t, f, content.ID).Issues)
+		objectMetadata, err = f.repo.GetObjectMetadata(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len("quarterly numbers")), objectMetadata.SizeBytes)
+		assert.Equal(t, `"backend-etag"`, objectMetadata.ETag)
+	})
+
+	t.Run("MetadataMissing", func(t *testing.T) {
+		f := setup(t)
+		content, err := f.svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: uuid.New(), TenantID: uuid.New(), Name: "imported"})
+		require.NoError(t, err)
+		object := &simplecontent.Object{
+			ID:                 uuid.New(),
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			ObjectKey:          "imported/" + content.ID.String(),
+			Version:            1,
+			Status:             string(simplecontent.ObjectStatusUploaded),
+		}
+		require.NoError(t, f.repo.CreateObject(ctx, object))
+		require.NoError(t, f.store.Upload(ctx, object.ObjectKey, strings.NewReader("data")))
+
+		assert.True(t, check(t, f, content.ID).Has(admin.IntegrityMetadataMissing))
+		report, err := f.admin.RepairContentIntegrity(ctx, content.ID)
+		require.NoError(t, err)
+		assert.True(t, report.OK())
+		assert.Empty(t, check(t, f, content.ID).Issues)
+	})
+}
@@ -15,6 +15,9 @@ type ContentStatistics struct {
 	ByDocumentType     map[string]int64       `json:"by_document_type,omitempty"`
 	OldestContent      *time.Time             `json:"oldest_content,omitempty"`
 	NewestContent      *time.Time             `json:"newest_content,omitempty"`
+	UniqueBlobs        int64                  `json:"unique_blobs,omitempty"`     // Distinct content checksums
+	TotalReferences    int64                  `json:"total_references,omitempty"` // Content with a checksum
+	BytesSaved         int64                  `json:"bytes_saved,omitempty"`      // Storage saved by sharing one blob per checksum
 }
 
 // ContentFilters defines flexible filtering options for admin operations
@@ -51,6 +54,16 @@ type ContentFilters struct {
 
 	// Special flags
 	IncludeDeleted bool `json:"include_deleted,omitempty"`
+
+	// WithoutUploadedObjects keeps only content with no uploaded data, e.g. created but
+	// never uploaded
+	WithoutUploadedObjects bool `json:"without_uploaded_objects,omitempty"`
+
+	// OriginalsOnly leaves out derived content such as thumbnails and previews
+	OriginalsOnly bool `json:"originals_only,omitempty"`
+
+	// Where is an additional condition built with admin.Where, ANDed with the fields above
+	Where *Filter `json:"where,omitempty"`
 }
 
 // StatisticsOptions defines what statistics to compute
@@ -60,6 +73,7 @@ type StatisticsOptions struct {
 	IncludeDerivationBreakdown   bool `json:"include_derivation_breakdown"`
 	IncludeDocumentTypeBreakdown bool `json:"include_document_type_breakdown"`
 	IncludeTimeRange             bool `json:"include_time_range"`
+	IncludeDedupBreakdown        bool `json:"include_dedup_breakdown"`
 }
 
 // DefaultStatisticsOptions returns statistics options with all breakdowns enabled
@@ -70,5 +84,6 @@ func DefaultStatisticsOptions() StatisticsOptions {
 		IncludeDerivationBreakdown:   true,
 		IncludeDocumentTypeBreakdown: true,
 		IncludeTimeRange:             true,
+		IncludeDedupBreakdown:        true,
 	}
 }
@@ -0,0 +1,81 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MetadataValidator checks annotations before AnnotateObject stores them on object. A
+// returned error rejects the whole set; it is reported wrapped in ErrInvalidMetadata.
+type MetadataValidator func(ctx context.Context, object *Object, annotations map[string]interface{}) error
+
+// WithMetadataValidator sets the validator AnnotateObject runs on every set of annotations,
+// e.g. to restrict keys to a known schema or cap the size of OCR text
+func WithMetadataValidator(validator MetadataValidator) Option {
+	return func(s *service) {
+		s.metadataValidator = validator
+	}
+}
+
+// AnnotateObject merges annotations into the object's metadata without touching its data,
+// for results such as labels or OCR text computed after the upload. Existing keys are
+// overwritten, others are kept; the ETag, size and MIME type stay as synced from storage.
+// Annotations of one object are applied one at a time, and each applied set is reported to
+// the event sink's ObjectAnnotated.
+func (s *service) AnnotateObject(ctx context.Context, objectID uuid.UUID, annotations map[string]interface{}) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	object, err := s.repository.GetObject(ctx, objectID)
+	if err != nil {
+		return &ObjectError{ObjectID: objectID, Op: "annotate", Err: ErrObjectNotFound}
+	}
+	if s.metadataValidator != nil {
+		if err := s.metadataValidator(ctx, object, annotations); err != nil {
+			if !errors.Is(err, ErrInvalidMetadata) {
+				err = fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+			}
+			return &ObjectError{ObjectID: objectID, Op: "annotate", Err: err}
+		}
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	unlock := s.objectLocks.lock(objectID)
+	defer unlock()
+
+	now := time.Now().UTC()
+	// The batch lookup leaves missing metadata out instead of failing, so a lookup error is
+	// never mistaken for an object without metadata and the synced fields are not overwritten
+	stored, err := s.repository.GetObjectMetadataByObjectIDs(ctx, []uuid.UUID{objectID})
+	if err != nil {
+		return &ObjectError{ObjectID: objectID, Op: "annotate", Err: err}
+	}
+	objectMetadata := stored[objectID]
+	if objectMetadata == nil {
+		objectMetadata = &ObjectMetadata{ObjectID: objectID, CreatedAt: now}
+	}
+	if objectMetadata.Metadata == nil {
+		objectMetadata.Metadata = make(map[string]interface{}, len(annotations))
+	}
+	maps.Copy(objectMetadata.Metadata, annotations)
+	objectMetadata.UpdatedAt = now
+	if err := s.repository.SetObjectMetadata(ctx, objectMetadata); err != nil {
+		return &ObjectError{ObjectID: objectID, Op: "annotate", Err: err}
+	}
+
+	if s.eventSink != nil {
+		if err := s.eventSink.ObjectAnnotated(ctx, object, maps.Clone(annotations)); err != nil {
+			// Log error but don't fail the operation
+			slog.Error("Failed to emit ObjectAnnotated event", "object_id", objectID, "error", err)
+		}
+	}
+	return nil
+}
@@ -109,21 +109,21 @@ const maxContentsPerRequest = 50
 func (h *ContentHandler) CreateContent(w http.ResponseWriter, r *http.Request) {
 	var req CreateContentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, err.Error())
 		return
 	}
 
 	ownerID, err := uuid.Parse(req.OwnerID)
 	if err != nil {
 		slog.Error("Invalid owner ID", "owner_id", req.OwnerID, "error", err)
-		http.Error(w, "Invalid owner ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid owner ID")
 		return
 	}
 
 	tenantID, err := uuid.Parse(req.TenantID)
 	if err != nil {
 		slog.Error("Invalid tenant ID", "tenant_id", req.TenantID, "error", err)
-		http.Error(w, "Invalid tenant ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid tenant ID")
 		return
 	}
 
@@ -139,7 +139,7 @@ func (h *ContentHandler) CreateContent(w http.ResponseWriter, r *http.Request) {
 	content, err := h.service.CreateContent(r.Context(), createReq)
 	if err != nil {
 		slog.Error("Failed to create content", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -148,12 +148,12 @@ func (h *ContentHandler) CreateContent(w http.ResponseWriter, r *http.Request) {
 		statusEnum := simplecontent.ContentStatus(req.Status)
 		if !statusEnum.IsValid() {
 			slog.Error("Invalid status", "status", req.Status)
-			http.Error(w, "Invalid status", http.StatusBadRequest)
+			writeBadRequest(w, "Invalid status")
 			return
 		}
 		if err := h.service.UpdateContentStatus(r.Context(), content.ID, statusEnum); err != nil {
 			slog.Error("Failed to update content status", "error", err)
-			http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+			WriteServiceError(w, err)
 			return
 		}
 		// Update the content object with the new status
@@ -169,7 +169,7 @@ func (h *ContentHandler) CreateContent(w http.ResponseWriter, r *http.Request) {
 		CreatedBy:   ownerID.String(),
 	}); err != nil {
 		slog.Error("Failed to set content metadata", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -207,7 +207,7 @@ func (h *ContentHandler) GetContent(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		slog.Error("Invalid content ID", "content_id", idStr, "error", err)
-		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid content ID")
 		return
 	}
 
@@ -215,7 +215,7 @@ func (h *ContentHandler) GetContent(w http.ResponseWriter, r *http.Request) {
 	content, err := h.service.GetContent(r.Context(), id)
 	if err != nil {
 		slog.Error("Failed to get content", "content_id", idStr, "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusNotFound)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -248,11 +248,16 @@ func (h *ContentHandler) GetContentsByIDs(w http.ResponseWriter, r *http.Request
 	// Get the id parameters from the query string
 	idStrings := r.URL.Query()["id"]
 	if len(idStrings) == 0 {
-		http.Error(w, "Missing required 'id' parameter", http.StatusBadRequest)
+		writeBadRequest(w, "Missing required 'id' parameter")
 		return
 	}
 	if len(idStrings) > maxContentsPerRequest {
-		http.Error(w, "Too many IDs requested", http.StatusBadRequest)
+		writeBadRequest(w, "Too many IDs requested")
+		return
+	}
+	page, err := parsePageParams(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
 		return
 	}
 
@@ -299,23 +304,25 @@ func (h *ContentHandler) GetContentsByIDs(w http.ResponseWriter, r *http.Request
 		contents = append(contents, resp)
 	}
 
-	// Return all found contents
-	render.JSON(w, r, contents)
+	// Return the requested page of the found contents
+	render.JSON(w, r, newPagedResponse(contents, page))
 }
 
-// DeleteContent deletes a content by ID
+// DeleteContent deletes a content by ID. With ?cascade=true its derived content is
+// deleted as well; otherwise content with derived children is rejected with 409.
 func (h *ContentHandler) DeleteContent(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		slog.Error("Invalid content ID", "content_id", idStr, "error", err)
-		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid content ID")
 		return
 	}
 
-	if err := h.service.DeleteContent(r.Context(), id); err != nil {
+	opts := simplecontent.DeleteContentOptions{Cascade: r.URL.Query().Get("cascade") == "true"}
+	if err := h.service.DeleteContentWithOptions(r.Context(), id, opts); err != nil {
 		slog.Error("Failed to delete content", "content_id", idStr, "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -329,7 +336,7 @@ func (h *ContentHandler) GetContentDetails(w http.ResponseWriter, r *http.Reques
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		slog.Error("Invalid content ID", "content_id", idStr, "error", err)
-		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid content ID")
 		return
 	}
 
@@ -337,7 +344,7 @@ func (h *ContentHandler) GetContentDetails(w http.ResponseWriter, r *http.Reques
 
 	if err != nil {
 		slog.Error("Failed to get content details", "content_id", idStr, "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusNotFound)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -351,14 +358,14 @@ func (h *ContentHandler) CreateObject(w http.ResponseWriter, r *http.Request) {
 	contentID, err := uuid.Parse(contentIDStr)
 	if err != nil {
 		slog.Error("Invalid content ID", "content_id", contentIDStr, "error", err)
-		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid content ID")
 		return
 	}
 
 	var req CreateObjectRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		slog.Error("Invalid request body", "error", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, err.Error())
 		return
 	}
 
@@ -376,7 +383,7 @@ func (h *ContentHandler) CreateObject(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		slog.Error("Fail to create object", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -394,7 +401,7 @@ func (h *ContentHandler) CreateObject(w http.ResponseWriter, r *http.Request) {
 	uploadURL, err := h.storage.GetUploadURL(r.Context(), object.ID)
 	if err != nil {
 		slog.Error("Failed to get upload URL", "err", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -423,19 +430,24 @@ func (h *ContentHandler) ListObjects(w http.ResponseWriter, r *http.Request) {
 	contentIDStr := chi.URLParam(r, "id")
 	contentID, err := uuid.Parse(contentIDStr)
 	if err != nil {
-		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid content ID")
+		return
+	}
+	page, err := parsePageParams(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
 		return
 	}
 
 	objects, err := h.service.GetObjectsByContentID(r.Context(), contentID)
 	if err != nil {
 		slog.Error("Fail to get objects by content ID", "content_id", contentIDStr, "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 	if len(objects) == 0 {
 		slog.Warn("No objects found for content", "content_id", contentIDStr)
-		http.Error(w, "No objects found for content "+contentIDStr, http.StatusNotFound)
+		WriteError(w, http.StatusNotFound, CodeNoObjects, "No objects found for content "+contentIDStr, nil)
 		return
 	}
 	// Check if we should only return the latest version
@@ -464,7 +476,7 @@ func (h *ContentHandler) ListObjects(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	render.JSON(w, r, resp)
+	render.JSON(w, r, newPagedResponse(resp, page))
 }
 
 // GetLatestVersionObject returns the object with the highest version number from a slice of objects.
@@ -492,7 +504,8 @@ type CreateDerivedContentRequest struct {
 	ProcessingMetadata map[string]interface{} `json:"processing_metadata"`
 	OwnerID            string                 `json:"owner_id"`
 	TenantID           string                 `json:"tenant_id"`
-	Status             string                 `json:"status,omitempty"` // Optional initial status (defaults to "created")
+	Status             string                 `json:"status,omitempty"`    // Optional initial status (defaults to "created")
+	Overwrite          bool                   `json:"overwrite,omitempty"` // Update an existing derivation of the same type instead of returning 409
 }
 
 // CreateDerivedContentResponse is the response body for derived content creation
@@ -507,13 +520,13 @@ func (h *ContentHandler) CreateDerivedContent(w http.ResponseWriter, r *http.Req
 	parentIDStr := chi.URLParam(r, "id")
 	parentID, err := uuid.Parse(parentIDStr)
 	if err != nil {
-		http.Error(w, "Invalid parent content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid parent content ID")
 		return
 	}
 
 	var req CreateDerivedContentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, err.Error())
 		return
 	}
 
@@ -524,7 +537,7 @@ func (h *ContentHandler) CreateDerivedContent(w http.ResponseWriter, r *http.Req
 		ownerID, err = uuid.Parse(req.OwnerID)
 		if err != nil {
 			slog.Error("Invalid owner ID", "owner_id", req.OwnerID, "error", err)
-			http.Error(w, "Invalid owner ID", http.StatusBadRequest)
+			writeBadRequest(w, "Invalid owner ID")
 			return
 		}
 	}
@@ -532,7 +545,7 @@ func (h *ContentHandler) CreateDerivedContent(w http.ResponseWriter, r *http.Req
 		tenantID, err = uuid.Parse(req.TenantID)
 		if err != nil {
 			slog.Error("Invalid tenant ID", "tenant_id", req.TenantID, "error", err)
-			http.Error(w, "Invalid tenant ID", http.StatusBadRequest)
+			writeBadRequest(w, "Invalid tenant ID")
 			return
 		}
 	}
@@ -552,7 +565,7 @@ func (h *ContentHandler) CreateDerivedContent(w http.ResponseWriter, r *http.Req
 		initialStatus = simplecontent.ContentStatus(req.Status)
 		if !initialStatus.IsValid() {
 			slog.Error("Invalid status", "status", req.Status)
-			http.Error(w, "Invalid status", http.StatusBadRequest)
+			writeBadRequest(w, "Invalid status")
 			return
 		}
 	}
@@ -565,10 +578,11 @@ func (h *ContentHandler) CreateDerivedContent(w http.ResponseWriter, r *http.Req
 		DerivationType: req.DerivationType,
 		Metadata:       metadata,
 		InitialStatus:  initialStatus,
+		Overwrite:      req.Overwrite,
 	})
 	if err != nil {
 		slog.Error("Failed to create derived content", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -605,14 +619,14 @@ func (h *ContentHandler) SetContentMetadata(w http.ResponseWriter, r *http.Reque
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		slog.Error("Invalid content ID", "content_id", idStr, "error", err)
-		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid content ID")
 		return
 	}
 
 	var metadataReq map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&metadataReq); err != nil {
 		slog.Error("Invalid request body", "error", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, err.Error())
 		return
 	}
 
@@ -675,7 +689,7 @@ func (h *ContentHandler) SetContentMetadata(w http.ResponseWriter, r *http.Reque
 	// Set content metadata
 	if err := h.service.SetContentMetadata(r.Context(), req); err != nil {
 		slog.Error("Failed to set content metadata", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -689,14 +703,14 @@ func (h *ContentHandler) GetContentMetadataHandler(w http.ResponseWriter, r *htt
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		slog.Error("Invalid content ID", "content_id", idStr, "error", err)
-		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid content ID")
 		return
 	}
 
 	metadata, err := h.service.GetContentMetadata(r.Context(), id)
 	if err != nil {
 		slog.Error("Failed to get content metadata", "content_id", idStr, "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusNotFound)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -740,7 +754,12 @@ func (h *ContentHandler) GetDerivedContent(w http.ResponseWriter, r *http.Reques
 	parentID, err := uuid.Parse(parentIDStr)
 	if err != nil {
 		slog.Error("Invalid parent content ID", "parent_id", parentIDStr, "error", err)
-		http.Error(w, "Invalid parent content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid parent content ID")
+		return
+	}
+	page, err := parsePageParams(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
 		return
 	}
 
@@ -748,7 +767,7 @@ func (h *ContentHandler) GetDerivedContent(w http.ResponseWriter, r *http.Reques
 	derivedList, err := h.service.ListDerivedContent(r.Context(), simplecontent.WithParentID(parentID))
 	if err != nil {
 		slog.Error("Failed to get derived content", "parent_id", parentIDStr, "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -782,7 +801,7 @@ func (h *ContentHandler) GetDerivedContent(w http.ResponseWriter, r *http.Reques
 	}
 
 	slog.Info("Derived content retrieved", "parent_id", parentIDStr, "count", len(resp))
-	render.JSON(w, r, resp)
+	render.JSON(w, r, newPagedResponse(resp, page))
 }
 
 // GetDerivedContentTree retrieves the entire derived content tree (recursive)
@@ -791,7 +810,12 @@ func (h *ContentHandler) GetDerivedContentTree(w http.ResponseWriter, r *http.Re
 	rootID, err := uuid.Parse(rootIDStr)
 	if err != nil {
 		slog.Error("Invalid root content ID", "root_id", rootIDStr, "error", err)
-		http.Error(w, "Invalid root content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid root content ID")
+		return
+	}
+	page, err := parsePageParams(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
 		return
 	}
 
@@ -799,7 +823,7 @@ func (h *ContentHandler) GetDerivedContentTree(w http.ResponseWriter, r *http.Re
 	rootContent, err := h.service.GetContent(r.Context(), rootID)
 	if err != nil {
 		slog.Error("Failed to get root content", "root_id", rootIDStr, "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusNotFound)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -831,7 +855,7 @@ func (h *ContentHandler) GetDerivedContentTree(w http.ResponseWriter, r *http.Re
 	h.addDescendants(r.Context(), rootID, &resp)
 
 	slog.Info("Derived content tree retrieved", "root_id", rootIDStr, "count", len(resp))
-	render.JSON(w, r, resp)
+	render.JSON(w, r, newPagedResponse(resp, page))
 }
 
 // addDescendants recursively adds all descendant content to the response
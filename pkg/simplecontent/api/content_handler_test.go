@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -188,6 +190,47 @@ func TestContentHandler_DeleteContent_Success(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestContentHandler_DeleteContent_Cascade(t *testing.T) {
+	handler, service, _ := setupContentHandlerTest(t)
+	router := chi.NewRouter()
+	router.Delete("/{id}", handler.DeleteContent)
+	ctx := context.Background()
+
+	parent, err := service.UploadContent(ctx, simplecontent.UploadContentRequest{
+		TenantID: uuid.New(),
+		OwnerID:  uuid.New(),
+		Name:     "photo.jpg",
+		Reader:   strings.NewReader("original"),
+	})
+	require.NoError(t, err)
+	thumbnail, err := service.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+		ParentID:       parent.ID,
+		OwnerID:        parent.OwnerID,
+		TenantID:       parent.TenantID,
+		DerivationType: "thumbnail",
+		Variant:        "thumbnail_128",
+		Reader:         strings.NewReader("thumb"),
+	})
+	require.NoError(t, err)
+
+	// Without cascade the parent is protected by its thumbnail
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/"+parent.ID.String(), nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, CodeHasDerivedContent, resp.Error.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/"+parent.ID.String()+"?cascade=true", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	_, err = service.GetContent(ctx, parent.ID)
+	assert.Error(t, err)
+	_, err = service.GetContent(ctx, thumbnail.ID)
+	assert.Error(t, err)
+}
+
 func TestContentHandler_GetContentsByIDs_Success(t *testing.T) {
 	handler, service, _ := setupContentHandlerTest(t)
 	router := chi.NewRouter()
@@ -219,11 +262,12 @@ func TestContentHandler_GetContentsByIDs_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var resp []ContentResponse
+	var resp PagedResponse[ContentResponse]
 	err = json.Unmarshal(w.Body.Bytes(), &resp)
 	require.NoError(t, err)
 
-	assert.Len(t, resp, 2)
+	assert.Len(t, resp.Items, 2)
+	assert.Equal(t, 2, resp.Total)
 }
 
 func TestContentHandler_GetContentsByIDs_MissingIDParameter(t *testing.T) {
@@ -273,12 +317,12 @@ func TestContentHandler_ListObjects_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var resp []ObjectResponse
+	var resp PagedResponse[ObjectResponse]
 	err = json.Unmarshal(w.Body.Bytes(), &resp)
 	require.NoError(t, err)
 
-	assert.Len(t, resp, 1)
-	assert.Equal(t, object.ID.String(), resp[0].ID)
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, object.ID.String(), resp.Items[0].ID)
 }
 
 func TestContentHandler_CreateDerivedContent_Success(t *testing.T) {
@@ -376,3 +420,94 @@ func TestGetLatestVersionObject(t *testing.T) {
 		})
 	}
 }
+
+func TestContentHandler_ListPagination(t *testing.T) {
+	handler, service, storageService := setupContentHandlerTest(t)
+	router := handler.Routes()
+	ctx := context.Background()
+
+	parent, err := service.CreateContent(ctx, simplecontent.CreateContentRequest{
+		TenantID:     uuid.New(),
+		OwnerID:      uuid.New(),
+		OwnerType:    "user",
+		Name:         "photo.jpg",
+		DocumentType: "image/jpeg",
+	})
+	require.NoError(t, err)
+	parent.Status = string(simplecontent.ContentStatusUploaded)
+	require.NoError(t, service.UpdateContent(ctx, simplecontent.UpdateContentRequest{Content: parent}))
+	for version := 1; version <= 3; version++ {
+		_, err := storageService.CreateObject(ctx, simplecontent.CreateObjectRequest{ContentID: parent.ID, StorageBackendName: "memory", Version: version})
+		require.NoError(t, err)
+	}
+	ids := []string{parent.ID.String()}
+	for _, variant := range []string{"thumbnail_128", "thumbnail_256"} {
+		derived, err := service.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+			ParentID:       parent.ID,
+			OwnerID:        parent.OwnerID,
+			TenantID:       parent.TenantID,
+			DerivationType: "thumbnail",
+			Variant:        variant,
+		})
+		require.NoError(t, err)
+		ids = append(ids, derived.ID.String())
+	}
+
+	get := func(t *testing.T, target string) (int, PagedResponse[json.RawMessage]) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		var resp PagedResponse[json.RawMessage]
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+		}
+		return w.Code, resp
+	}
+
+	base := "/" + parent.ID.String()
+	cases := []struct {
+		name  string
+		path  string
+		total int
+	}{
+		{"Bulk", "/bulk?id=" + strings.Join(ids, "&id="), 3},
+		{"Objects", base + "/objects?latest=false", 3},
+		{"Derived", base + "/derived", 2},
+		{"DerivedTree", base + "/derived-tree", 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sep := "?"
+			if strings.Contains(tc.path, "?") {
+				sep = "&"
+			}
+
+			code, all := get(t, tc.path)
+			require.Equal(t, http.StatusOK, code)
+			assert.Len(t, all.Items, tc.total)
+			assert.Equal(t, tc.total, all.Total)
+			assert.Equal(t, 100, all.Limit)
+			assert.Equal(t, 0, all.Offset)
+			assert.Empty(t, all.NextCursor)
+
+			code, first := get(t, tc.path+sep+"limit=1")
+			require.Equal(t, http.StatusOK, code)
+			assert.Len(t, first.Items, 1)
+			assert.Equal(t, tc.total, first.Total)
+			assert.Equal(t, 1, first.Limit)
+			assert.Equal(t, "1", first.NextCursor)
+
+			code, next := get(t, tc.path+sep+"limit=1&cursor="+first.NextCursor)
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, 1, next.Offset)
+			assert.Equal(t, all.Items[1], next.Items[0])
+
+			code, last := get(t, tc.path+sep+"offset="+strconv.Itoa(tc.total-1))
+			require.Equal(t, http.StatusOK, code)
+			assert.Len(t, last.Items, 1)
+			assert.Empty(t, last.NextCursor)
+
+			code, _ = get(t, tc.path+sep+"limit=0")
+			assert.Equal(t, http.StatusBadRequest, code)
+		})
+	}
+}
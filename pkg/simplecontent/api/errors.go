@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// Machine-readable error codes returned in ErrorResponse. Codes are part of the
+// API contract: clients may switch on them, so existing values must not change.
+const (
+	CodeInvalidRequest          = "invalid_request"
+	CodeInternalError           = "internal_error"
+	CodeContentNotFound         = "content_not_found"
+	CodeObjectNotFound          = "object_not_found"
+	CodeStorageBackendNotFound  = "storage_backend_not_found"
+	CodeInvalidContentStatus    = "invalid_content_status"
+	CodeInvalidObjectStatus     = "invalid_object_status"
+	CodeUploadFailed            = "upload_failed"
+	CodeDownloadFailed          = "download_failed"
+	CodeContentNotReady         = "content_not_ready"
+	CodeObjectNotReady          = "object_not_ready"
+	CodeInvalidUploadState      = "invalid_upload_state"
+	CodeParentNotReady          = "parent_not_ready"
+	CodeContentBeingProcessed   = "content_being_processed"
+	CodeMaxDerivationDepth      = "max_derivation_depth_exceeded"
+	CodeNoStorageBackend        = "no_storage_backend"
+	CodeNoObjects               = "no_objects"
+	CodeNoUploadedObjects       = "no_uploaded_objects"
+	CodeBlobNotFound            = "blob_not_found"
+	CodeInvalidRelationType     = "invalid_relation_type"
+	CodeRelationshipNotFound    = "relationship_not_found"
+	CodeStorageTimeout          = "storage_timeout"
+	CodeHasDerivedContent       = "has_derived_content"
+	CodeObjectKeyExists         = "object_key_exists"
+	CodeInvalidObjectKey        = "invalid_object_key"
+	CodeContentTypeMismatch     = "content_type_mismatch"
+	CodePresignNotSupported     = "presign_not_supported"
+	CodeUnknownDerivationType   = "unknown_derivation_type"
+	CodeUnknownVariant          = "unknown_variant"
+	CodeReadOnly                = "read_only"
+	CodeInvalidStatusTransition = "invalid_status_transition"
+	CodeURLImportDenied         = "url_import_denied"
+	CodeURLImportTooLarge       = "url_import_too_large"
+	CodeURLImportFailed         = "url_import_failed"
+	CodePreconditionFailed      = "precondition_failed"
+	CodeInvalidDisposition      = "invalid_disposition"
+	CodeInvalidMetadata         = "invalid_metadata"
+	CodeInvalidField            = "invalid_field"
+	CodeAppendNotSupported      = "append_not_supported"
+	CodeVariantExists           = "variant_exists"
+)
+
+// ErrorResponse is the JSON body written for every API error.
+//
+//	{"error": {"code": "content_not_found", "message": "...", "details": {...}}}
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody carries the machine-readable code and a human-readable message.
+type ErrorBody struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// errorMapping associates a service sentinel error with its HTTP status and code.
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings is checked in order with errors.Is; the first match wins.
+var errorMappings = []errorMapping{
+	{simplecontent.ErrContentNotFound, http.StatusNotFound, CodeContentNotFound},
+	{simplecontent.ErrObjectNotFound, http.StatusNotFound, CodeObjectNotFound},
+	{simplecontent.ErrStorageBackendNotFound, http.StatusBadRequest, CodeStorageBackendNotFound},
+	{simplecontent.ErrInvalidContentStatus, http.StatusBadRequest, CodeInvalidContentStatus},
+	{simplecontent.ErrInvalidObjectStatus, http.StatusBadRequest, CodeInvalidObjectStatus},
+	{simplecontent.ErrUploadFailed, http.StatusBadGateway, CodeUploadFailed},
+	{simplecontent.ErrDownloadFailed, http.StatusBadGateway, CodeDownloadFailed},
+	{simplecontent.ErrContentNotReady, http.StatusConflict, CodeContentNotReady},
+	{simplecontent.ErrObjectNotReady, http.StatusConflict, CodeObjectNotReady},
+	{simplecontent.ErrInvalidUploadState, http.StatusConflict, CodeInvalidUploadState},
+	{simplecontent.ErrParentNotReady, http.StatusConflict, CodeParentNotReady},
+	{simplecontent.ErrContentBeingProcessed, http.StatusConflict, CodeContentBeingProcessed},
+	{simplecontent.ErrMaxDerivationDepth, http.StatusBadRequest, CodeMaxDerivationDepth},
+	{simplecontent.ErrNoStorageBackend, http.StatusInternalServerError, CodeNoStorageBackend},
+	{simplecontent.ErrNoObjectsFound, http.StatusNotFound, CodeNoObjects},
+	{simplecontent.ErrNoUploadedObjects, http.StatusNotFound, CodeNoUploadedObjects},
+	{simplecontent.ErrBlobNotFound, http.StatusConflict, CodeBlobNotFound},
+	{simplecontent.ErrInvalidRelationType, http.StatusBadRequest, CodeInvalidRelationType},
+	{simplecontent.ErrRelationshipNotFound, http.StatusNotFound, CodeRelationshipNotFound},
+	{simplecontent.ErrStorageTimeout, http.StatusGatewayTimeout, CodeStorageTimeout},
+	{simplecontent.ErrHasDerivedContent, http.StatusConflict, CodeHasDerivedContent},
+	{simplecontent.ErrObjectKeyExists, http.StatusConflict, CodeObjectKeyExists},
+	{simplecontent.ErrInvalidObjectKey, http.StatusBadRequest, CodeInvalidObjectKey},
+	{simplecontent.ErrContentTypeMismatch, http.StatusUnsupportedMediaType, CodeContentTypeMismatch},
+	{simplecontent.ErrPresignNotSupported, http.StatusNotImplemented, CodePresignNotSupported},
+	{simplecontent.ErrUnknownDerivationType, http.StatusBadRequest, CodeUnknownDerivationType},
+	{simplecontent.ErrUnknownVariant, http.StatusBadRequest, CodeUnknownVariant},
+	{simplecontent.ErrReadOnly, http.StatusServiceUnavailable, CodeReadOnly},
+	{simplecontent.ErrInvalidStatusTransition, http.StatusConflict, CodeInvalidStatusTransition},
+	{simplecontent.ErrURLImportDenied, http.StatusForbidden, CodeURLImportDenied},
+	{simplecontent.ErrURLImportTooLarge, http.StatusRequestEntityTooLarge, CodeURLImportTooLarge},
+	{simplecontent.ErrURLImportFailed, http.StatusBadGateway, CodeURLImportFailed},
+	{simplecontent.ErrPreconditionFailed, http.StatusPreconditionFailed, CodePreconditionFailed},
+	{simplecontent.ErrInvalidDisposition, http.StatusBadRequest, CodeInvalidDisposition},
+	{simplecontent.ErrInvalidMetadata, http.StatusBadRequest, CodeInvalidMetadata},
+	{simplecontent.ErrInvalidField, http.StatusBadRequest, CodeInvalidField},
+	{simplecontent.ErrAppendNotSupported, http.StatusNotImplemented, CodeAppendNotSupported},
+	{simplecontent.ErrVariantExists, http.StatusConflict, CodeVariantExists},
+}
+
+// ErrorStatusAndCode maps a service error to its HTTP status and error code.
+// Unknown errors map to 500 internal_error.
+func ErrorStatusAndCode(err error) (int, string) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.status, m.code
+		}
+	}
+	return http.StatusInternalServerError, CodeInternalError
+}
+
+// WriteError writes an ErrorResponse with the given status, code and message.
+func WriteError(w http.ResponseWriter, status int, code, message string, details interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{
+		Error: ErrorBody{Code: code, Message: message, Details: details},
+	})
+}
+
+// WriteServiceError writes err as an ErrorResponse, deriving the status and code
+// from the service sentinel it wraps.
+func WriteServiceError(w http.ResponseWriter, err error) {
+	status, code := ErrorStatusAndCode(err)
+	WriteError(w, status, code, simplecontent.ToErrorMessage(err), serviceErrorDetails(err))
+}
+
+// serviceErrorDetails returns the structured details carried by typed service errors.
+func serviceErrorDetails(err error) interface{} {
+	var backendErr *simplecontent.BackendNotFoundError
+	if errors.As(err, &backendErr) {
+		return map[string]interface{}{
+			"backend":            backendErr.Name,
+			"available_backends": backendErr.Available,
+		}
+	}
+	var fieldErr *simplecontent.FieldError
+	if errors.As(err, &fieldErr) {
+		return map[string]interface{}{"field": fieldErr.Field}
+	}
+	return nil
+}
+
+// writeBadRequest writes a 400 invalid_request error for malformed input.
+func writeBadRequest(w http.ResponseWriter, message string) {
+	WriteError(w, http.StatusBadRequest, CodeInvalidRequest, message, nil)
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+func TestErrorStatusAndCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{simplecontent.ErrContentNotFound, http.StatusNotFound, CodeContentNotFound},
+		{simplecontent.ErrObjectNotFound, http.StatusNotFound, CodeObjectNotFound},
+		{simplecontent.ErrStorageBackendNotFound, http.StatusBadRequest, CodeStorageBackendNotFound},
+		{simplecontent.ErrInvalidContentStatus, http.StatusBadRequest, CodeInvalidContentStatus},
+		{simplecontent.ErrInvalidObjectStatus, http.StatusBadRequest, CodeInvalidObjectStatus},
+		{simplecontent.ErrUploadFailed, http.StatusBadGateway, CodeUploadFailed},
+		{simplecontent.ErrDownloadFailed, http.StatusBadGateway, CodeDownloadFailed},
+		{simplecontent.ErrContentNotReady, http.StatusConflict, CodeContentNotReady},
+		{simplecontent.ErrObjectNotReady, http.StatusConflict, CodeObjectNotReady},
+		{simplecontent.ErrInvalidUploadState, http.StatusConflict, CodeInvalidUploadState},
+		{simplecontent.ErrParentNotReady, http.StatusConflict, CodeParentNotReady},
+		{simplecontent.ErrContentBeingProcessed, http.StatusConflict, CodeContentBeingProcessed},
+		{simplecontent.ErrMaxDerivationDepth, http.StatusBadRequest, CodeMaxDerivationDepth},
+		{simplecontent.ErrNoStorageBackend, http.StatusInternalServerError, CodeNoStorageBackend},
+		{simplecontent.ErrNoObjectsFound, http.StatusNotFound, CodeNoObjects},
+		{simplecontent.ErrNoUploadedObjects, http.StatusNotFound, CodeNoUploadedObjects},
+		{simplecontent.ErrBlobNotFound, http.StatusConflict, CodeBlobNotFound},
+		{simplecontent.ErrInvalidRelationType, http.StatusBadRequest, CodeInvalidRelationType},
+		{simplecontent.ErrRelationshipNotFound, http.StatusNotFound, CodeRelationshipNotFound},
+		{simplecontent.ErrStorageTimeout, http.StatusGatewayTimeout, CodeStorageTimeout},
+		{simplecontent.ErrHasDerivedContent, http.StatusConflict, CodeHasDerivedContent},
+		{simplecontent.ErrObjectKeyExists, http.StatusConflict, CodeObjectKeyExists},
+		{simplecontent.ErrInvalidObjectKey, http.StatusBadRequest, CodeInvalidObjectKey},
+		{simplecontent.ErrContentTypeMismatch, http.StatusUnsupportedMediaType, CodeContentTypeMismatch},
+		{simplecontent.ErrPresignNotSupported, http.StatusNotImplemented, CodePresignNotSupported},
+		{simplecontent.ErrUnknownDerivationType, http.StatusBadRequest, CodeUnknownDerivationType},
+		{simplecontent.ErrUnknownVariant, http.StatusBadRequest, CodeUnknownVariant},
+		{simplecontent.ErrReadOnly, http.StatusServiceUnavailable, CodeReadOnly},
+		{simplecontent.ErrInvalidStatusTransition, http.StatusConflict, CodeInvalidStatusTransition},
+		{simplecontent.ErrURLImportDenied, http.StatusForbidden, CodeURLImportDenied},
+		{simplecontent.ErrURLImportTooLarge, http.StatusRequestEntityTooLarge, CodeURLImportTooLarge},
+		{simplecontent.ErrURLImportFailed, http.StatusBadGateway, CodeURLImportFailed},
+		{simplecontent.ErrPreconditionFailed, http.StatusPreconditionFailed, CodePreconditionFailed},
+		{simplecontent.ErrInvalidDisposition, http.StatusBadRequest, CodeInvalidDisposition},
+		{simplecontent.ErrInvalidMetadata, http.StatusBadRequest, CodeInvalidMetadata},
+		{simplecontent.ErrInvalidField, http.StatusBadRequest, CodeInvalidField},
+		{simplecontent.ErrAppendNotSupported, http.StatusNotImplemented, CodeAppendNotSupported},
+		{simplecontent.ErrVariantExists, http.StatusConflict, CodeVariantExists},
+		{errors.New("boom"), http.StatusInternalServerError, CodeInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			status, code := ErrorStatusAndCode(tt.err)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, code)
+
+			// Wrapped errors map the same way
+			status, code = ErrorStatusAndCode(fmt.Errorf("wrapped: %w", tt.err))
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, code)
+		})
+	}
+}
+
+func TestWriteServiceError(t *testing.T) {
+	t.Run("ContentError", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		WriteServiceError(w, &simplecontent.ContentError{
+			ContentID: uuid.New(),
+			Op:        "get",
+			Err:       simplecontent.ErrContentNotFound,
+		})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, CodeContentNotFound, resp.Error.Code)
+		assert.Equal(t, "get: content not found", resp.Error.Message)
+	})
+
+	t.Run("ObjectError", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		WriteServiceError(w, &simplecontent.ObjectError{
+			ObjectID: uuid.New(),
+			Op:       "confirm_upload",
+			Err:      simplecontent.ErrBlobNotFound,
+		})
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, CodeBlobNotFound, resp.Error.Code)
+		assert.Nil(t, resp.Error.Details)
+	})
+
+	t.Run("BackendNotFound", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		WriteServiceError(w, &simplecontent.ContentError{
+			ContentID: uuid.New(),
+			Op:        "create_object",
+			Err:       &simplecontent.BackendNotFoundError{Name: "gcs", Available: []string{"fs", "s3"}},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, CodeStorageBackendNotFound, resp.Error.Code)
+		assert.Equal(t, "create_object: storage backend not found: gcs (available: fs, s3)", resp.Error.Message)
+		assert.Equal(t, map[string]interface{}{
+			"backend":            "gcs",
+			"available_backends": []interface{}{"fs", "s3"},
+		}, resp.Error.Details)
+	})
+
+	t.Run("InvalidField", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		WriteServiceError(w, &simplecontent.ContentError{
+			Op:  "create",
+			Err: &simplecontent.FieldError{Field: "name", Reason: "not valid UTF-8"},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, CodeInvalidField, resp.Error.Code)
+		assert.Equal(t, "create: invalid field name: not valid UTF-8", resp.Error.Message)
+		assert.Equal(t, map[string]interface{}{"field": "name"}, resp.Error.Details)
+	})
+}
+
+func TestWriteError_Details(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteError(w, http.StatusBadRequest, CodeInvalidRequest, "bad field", map[string]string{"field": "owner_id"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":{"code":"invalid_request","message":"bad field","details":{"field":"owner_id"}}}`, w.Body.String())
+}
@@ -16,14 +16,16 @@ const DEFAULT_STORAGE_BACKEND = "s3-default"
 
 // FilesHandler handles file upload and management API endpoints using pkg/simplecontent
 type FilesHandler struct {
-	service        simplecontent.Service
-	storageService simplecontent.StorageService
+	service          simplecontent.Service
+	storageService   simplecontent.StorageService
+	progressInterval time.Duration // How often StreamUpload reports progress
 }
 
 func NewFilesHandler(service simplecontent.Service, storageService simplecontent.StorageService) *FilesHandler {
 	return &FilesHandler{
-		service:        service,
-		storageService: storageService,
+		service:          service,
+		storageService:   storageService,
+		progressInterval: defaultStreamProgressInterval,
 	}
 }
 
@@ -31,6 +33,7 @@ func NewFilesHandler(service simplecontent.Service, storageService simplecontent
 func (h *FilesHandler) Routes() chi.Router {
 	r := chi.NewRouter()
 	r.Post("/", h.CreateFile)
+	r.Post("/stream", h.StreamUpload)
 	r.Post("/{content_id}/complete", h.CompleteUpload)
 	r.Get("/{content_id}", h.GetFileInfo)
 	r.Get("/bulk", h.GetFilesByContentIDs)
@@ -88,7 +91,7 @@ func (h *FilesHandler) CreateFile(w http.ResponseWriter, r *http.Request) {
 	var req CreateFileRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		slog.Error("Failed to decode request", "error", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, err.Error())
 		return
 	}
 
@@ -96,26 +99,26 @@ func (h *FilesHandler) CreateFile(w http.ResponseWriter, r *http.Request) {
 	ownerID, err := uuid.Parse(req.OwnerID)
 	if err != nil {
 		slog.Error("Invalid owner ID", "owner_id", req.OwnerID, "error", err)
-		http.Error(w, "Invalid owner ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid owner ID")
 		return
 	}
 
 	if req.OwnerType == "" {
 		slog.Error("Owner type is required", "owner_type", req.OwnerType)
-		http.Error(w, "Owner type is required", http.StatusBadRequest)
+		writeBadRequest(w, "Owner type is required")
 		return
 	}
 
 	if req.DocumentType == "" {
 		slog.Error("Document type is required", "document_type", req.DocumentType)
-		http.Error(w, "Document type is required", http.StatusBadRequest)
+		writeBadRequest(w, "Document type is required")
 		return
 	}
 
 	tenantID, err := uuid.Parse(req.TenantID)
 	if err != nil {
 		slog.Error("Invalid tenant ID", "tenant_id", req.TenantID, "error", err)
-		http.Error(w, "Invalid tenant ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid tenant ID")
 		return
 	}
 
@@ -129,7 +132,7 @@ func (h *FilesHandler) CreateFile(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		slog.Error("Failed to create content", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -142,7 +145,7 @@ func (h *FilesHandler) CreateFile(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := h.service.SetContentMetadata(r.Context(), metadataParams); err != nil {
 		slog.Error("Failed to set content metadata", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -158,7 +161,7 @@ func (h *FilesHandler) CreateFile(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		slog.Error("Failed to create object", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -169,7 +172,7 @@ func (h *FilesHandler) CreateFile(w http.ResponseWriter, r *http.Request) {
 		"file_name":  req.FileName,
 	}); err != nil {
 		slog.Error("Failed to set object metadata", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -177,7 +180,7 @@ func (h *FilesHandler) CreateFile(w http.ResponseWriter, r *http.Request) {
 	uploadURL, err := h.storageService.GetUploadURL(r.Context(), object.ID)
 	if err != nil {
 		slog.Error("Failed to generate upload URL", "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -199,14 +202,23 @@ func (h *FilesHandler) CompleteUpload(w http.ResponseWriter, r *http.Request) {
 	contentID, err := uuid.Parse(contentIDStr)
 	if err != nil {
 		slog.Error("Invalid content ID", "content_id", contentIDStr, "error", err)
-		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid content ID")
+		return
+	}
+
+	// Content already processed has completed its upload; completing it again succeeds
+	// without moving it back to uploaded, which the status registry does not allow
+	content, err := h.service.GetContent(r.Context(), contentID)
+	if err == nil && content.Status == string(simplecontent.ContentStatusProcessed) {
+		w.WriteHeader(http.StatusOK)
+		render.JSON(w, r, map[string]string{"status": "completed"})
 		return
 	}
 
 	// Complete the upload using the unified API
 	if err := h.service.UpdateContentStatus(r.Context(), contentID, simplecontent.ContentStatusUploaded); err != nil {
 		slog.Error("Failed to complete upload", "content_id", contentID.String(), "error", err)
-		http.Error(w, simplecontent.ToErrorMessage(err), http.StatusInternalServerError)
+		WriteServiceError(w, err)
 		return
 	}
 
@@ -221,7 +233,7 @@ func (h *FilesHandler) GetFileInfo(w http.ResponseWriter, r *http.Request) {
 	contentID, err := uuid.Parse(contentIDStr)
 	if err != nil {
 		slog.Error("Invalid content ID", "content_id", contentIDStr, "error", err)
-		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid content ID")
 		return
 	}
 
@@ -229,7 +241,7 @@ func (h *FilesHandler) GetFileInfo(w http.ResponseWriter, r *http.Request) {
 	details, err := h.service.GetContentDetails(r.Context(), contentID)
 	if err != nil {
 		slog.Error("Failed to get content details", "content_id", contentID.String(), "error", err)
-		http.Error(w, "Content not found", http.StatusNotFound)
+		WriteError(w, http.StatusNotFound, CodeContentNotFound, "Content not found", nil)
 		return
 	}
 
@@ -237,7 +249,7 @@ func (h *FilesHandler) GetFileInfo(w http.ResponseWriter, r *http.Request) {
 	content, err := h.service.GetContent(r.Context(), contentID)
 	if err != nil {
 		slog.Error("Failed to get content", "content_id", contentID.String(), "error", err)
-		http.Error(w, "Content not found", http.StatusNotFound)
+		WriteError(w, http.StatusNotFound, CodeContentNotFound, "Content not found", nil)
 		return
 	}
 
@@ -265,13 +277,18 @@ func (h *FilesHandler) GetFilesByContentIDs(w http.ResponseWriter, r *http.Reque
 	// Get the id parameters from the query string
 	idStrings := r.URL.Query()["id"]
 	if len(idStrings) == 0 {
-		http.Error(w, "Missing required 'id' parameter", http.StatusBadRequest)
+		writeBadRequest(w, "Missing required 'id' parameter")
 		return
 	}
 
 	const maxContentsPerRequest = 50
 	if len(idStrings) > maxContentsPerRequest {
-		http.Error(w, "Too many IDs requested", http.StatusBadRequest)
+		writeBadRequest(w, "Too many IDs requested")
+		return
+	}
+	page, err := parsePageParams(r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
 		return
 	}
 
@@ -319,5 +336,5 @@ func (h *FilesHandler) GetFilesByContentIDs(w http.ResponseWriter, r *http.Reque
 		files = append(files, resp)
 	}
 
-	render.JSON(w, r, files)
+	render.JSON(w, r, newPagedResponse(files, page))
 }
@@ -69,9 +69,10 @@ func TestFilesHandler_CreateFile_Success(t *testing.T) {
 
 	router.ServeHTTP(w, req)
 
-	// Memory backend doesn't support upload URLs, so we expect an error
+	// The default s3 backend is not registered in the test service, so we expect an error
 	// But we can verify the request was processed
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"storage_backend_not_found"`)
 	t.Logf("CreateFile_Success body: %s", w.Body.String())
 }
 
@@ -187,6 +188,30 @@ func TestFilesHandler_CompleteUpload_Success(t *testing.T) {
 	assert.Equal(t, "completed", resp["status"])
 }
 
+func TestFilesHandler_CompleteUpload_AlreadyProcessed(t *testing.T) {
+	handler, service, _ := setupFilesHandlerTest(t)
+	router := chi.NewRouter()
+	router.Post("/{content_id}/complete", handler.CompleteUpload)
+
+	content, err := service.CreateContent(context.Background(), simplecontent.CreateContentRequest{
+		TenantID:     uuid.New(),
+		OwnerID:      uuid.New(),
+		Name:         "test.pdf",
+		DocumentType: "document",
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.UpdateContentStatus(context.Background(), content.ID, simplecontent.ContentStatusProcessed))
+
+	req := httptest.NewRequest(http.MethodPost, "/"+content.ID.String()+"/complete", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "completing a processed upload again is not a conflict")
+	stored, err := service.GetContent(context.Background(), content.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(simplecontent.ContentStatusProcessed), stored.Status)
+}
+
 func TestFilesHandler_CompleteUpload_InvalidContentID(t *testing.T) {
 	handler, _, _ := setupFilesHandlerTest(t)
 	router := chi.NewRouter()
@@ -314,13 +339,13 @@ func TestFilesHandler_GetFilesByContentIDs_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var resp []FileInfoResponse
+	var resp PagedResponse[FileInfoResponse]
 	err = json.Unmarshal(w.Body.Bytes(), &resp)
 	require.NoError(t, err)
 
-	assert.Len(t, resp, 2)
-	assert.Equal(t, "test1.pdf", resp[0].FileName)
-	assert.Equal(t, "test2.pdf", resp[1].FileName)
+	assert.Len(t, resp.Items, 2)
+	assert.Equal(t, "test1.pdf", resp.Items[0].FileName)
+	assert.Equal(t, "test2.pdf", resp.Items[1].FileName)
 }
 
 func TestFilesHandler_GetFilesByContentIDs_MissingIDParameter(t *testing.T) {
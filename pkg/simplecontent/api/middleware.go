@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/tendant/simple-content/pkg/simplecontent"
 )
 
 // Middleware is a function that wraps an http.Handler
@@ -190,39 +191,80 @@ func RecoveryMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// CORSMiddleware handles CORS headers
+// CORSOptions configures CORSMiddlewareWithOptions
+type CORSOptions struct {
+	// AllowedOrigins lists origins allowed to make cross-origin requests.
+	// "*" allows any origin. Defaults to "*" when empty.
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// AllowCredentials sets Access-Control-Allow-Credentials. Browsers reject credentialed
+	// responses with a literal "*", so the request origin is echoed instead.
+	AllowCredentials bool
+}
+
+// CORSMiddleware handles CORS headers, allowing credentials for matched origins
 func CORSMiddleware(allowedOrigins []string, allowedMethods []string, allowedHeaders []string) Middleware {
+	return CORSMiddlewareWithOptions(CORSOptions{
+		AllowedOrigins:   allowedOrigins,
+		AllowedMethods:   allowedMethods,
+		AllowedHeaders:   allowedHeaders,
+		AllowCredentials: true,
+	})
+}
+
+// CORSMiddlewareWithOptions handles CORS headers. The request origin is reflected only
+// when it is in the allowlist; other origins get no CORS headers.
+func CORSMiddlewareWithOptions(opts CORSOptions) Middleware {
+	allowedOrigins := opts.AllowedOrigins
 	if len(allowedOrigins) == 0 {
 		allowedOrigins = []string{"*"}
 	}
+	allowedMethods := opts.AllowedMethods
 	if len(allowedMethods) == 0 {
 		allowedMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
 	}
+	allowedHeaders := opts.AllowedHeaders
 	if len(allowedHeaders) == 0 {
 		allowedHeaders = []string{"Content-Type", "Authorization", "X-Request-ID"}
 	}
 
+	wildcard := false
+	for _, allowedOrigin := range allowedOrigins {
+		if allowedOrigin == "*" {
+			wildcard = true
+			break
+		}
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
 
-			// Check if origin is allowed
-			allowed := false
-			for _, allowedOrigin := range allowedOrigins {
-				if allowedOrigin == "*" || allowedOrigin == origin {
-					allowed = true
-					break
+			// Resolve the Access-Control-Allow-Origin value, if any
+			allowOrigin := ""
+			switch {
+			case wildcard && (origin == "" || !opts.AllowCredentials):
+				allowOrigin = "*"
+			case origin != "":
+				for _, allowedOrigin := range allowedOrigins {
+					if allowedOrigin == "*" || allowedOrigin == origin {
+						allowOrigin = origin
+						break
+					}
 				}
 			}
 
-			if allowed {
-				if origin == "" {
-					origin = allowedOrigins[0]
+			if allowOrigin != "" {
+				if allowOrigin != "*" {
+					w.Header().Add("Vary", "Origin")
 				}
-				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
 				w.Header().Set("Access-Control-Allow-Methods", joinStrings(allowedMethods, ", "))
 				w.Header().Set("Access-Control-Allow-Headers", joinStrings(allowedHeaders, ", "))
-				w.Header().Set("Access-Control-Allow-Credentials", "true")
+				if opts.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
 			}
 
 			// Handle preflight requests
@@ -330,15 +372,30 @@ func AuthenticationMiddleware(authFunc AuthenticationFunc) Middleware {
 				return
 			}
 
-			// Add user and tenant to context
+			// Add user and tenant to context; the user is also the actor on access events
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
 			ctx = context.WithValue(ctx, TenantIDKey, tenantID)
+			ctx = simplecontent.WithActor(ctx, userID.String())
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// ActorHeaderMiddleware records the value of the given request header as the actor on
+// content access events. The header is trusted as-is, so only use this behind a gateway
+// that authenticates callers and sets the header itself.
+func ActorHeaderMiddleware(header string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if actor := r.Header.Get(header); actor != "" {
+				r = r.WithContext(simplecontent.WithActor(r.Context(), actor))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // CompressionMiddleware adds gzip compression for responses
 func CompressionMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// ObjectsHandler handles HTTP requests for objects using pkg/simplecontent
+type ObjectsHandler struct {
+	storage simplecontent.StorageService
+}
+
+// NewObjectsHandler creates a new objects handler
+func NewObjectsHandler(storageService simplecontent.StorageService) *ObjectsHandler {
+	return &ObjectsHandler{
+		storage: storageService,
+	}
+}
+
+// Routes returns the routes for objects
+func (h *ObjectsHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Post("/{id}/confirm", h.ConfirmUpload)
+	return r
+}
+
+// ConfirmUpload completes a presigned upload after the client has PUT the data to storage
+func (h *ObjectsHandler) ConfirmUpload(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		slog.Error("Invalid object ID", "object_id", idStr, "error", err)
+		writeBadRequest(w, "Invalid object ID")
+		return
+	}
+
+	object, err := h.storage.ConfirmUpload(r.Context(), id)
+	if err != nil {
+		slog.Error("Failed to confirm upload", "object_id", idStr, "error", err)
+		WriteServiceError(w, err)
+		return
+	}
+
+	resp := ObjectResponse{
+		ID:                 object.ID.String(),
+		ContentID:          object.ContentID.String(),
+		StorageBackendName: object.StorageBackendName,
+		Version:            object.Version,
+		ObjectKey:          object.ObjectKey,
+		Status:             object.Status,
+		CreatedAt:          object.CreatedAt,
+		UpdatedAt:          object.UpdatedAt,
+	}
+
+	slog.Info("Upload confirmed", "object_id", idStr)
+	render.JSON(w, r, resp)
+}
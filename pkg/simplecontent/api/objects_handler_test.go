@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/repo/memory"
+	memorystorage "github.com/tendant/simple-content/pkg/simplecontent/storage/memory"
+)
+
+// setupObjectsHandlerTest creates an ObjectsHandler and a created (not yet uploaded) object
+func setupObjectsHandlerTest(t *testing.T) (*ObjectsHandler, simplecontent.BlobStore, *simplecontent.Object) {
+	repo := memory.New()
+	blobStore := memorystorage.New()
+
+	storageService, err := simplecontent.NewStorageService(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", blobStore),
+		simplecontent.WithEventSink(simplecontent.NewNoopEventSink()),
+	)
+	require.NoError(t, err)
+
+	service, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", blobStore),
+	)
+	require.NoError(t, err)
+
+	content, err := service.CreateContent(context.Background(), simplecontent.CreateContentRequest{
+		TenantID:     uuid.New(),
+		OwnerID:      uuid.New(),
+		Name:         "presigned.txt",
+		DocumentType: "text/plain",
+	})
+	require.NoError(t, err)
+
+	object, err := storageService.CreateObject(context.Background(), simplecontent.CreateObjectRequest{
+		ContentID:          content.ID,
+		StorageBackendName: "memory",
+		Version:            1,
+	})
+	require.NoError(t, err)
+
+	return NewObjectsHandler(storageService), blobStore, object
+}
+
+func TestObjectsHandler_ConfirmUpload_Success(t *testing.T) {
+	handler, blobStore, object := setupObjectsHandlerTest(t)
+
+	// Simulate the client uploading directly to storage via the presigned URL
+	data := []byte("uploaded through presigned url")
+	err := blobStore.UploadWithParams(context.Background(), bytes.NewReader(data), simplecontent.UploadParams{
+		ObjectKey: object.ObjectKey,
+		MimeType:  "text/plain",
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/"+object.ID.String()+"/confirm", nil)
+	w := httptest.NewRecorder()
+	handler.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp ObjectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, object.ID.String(), resp.ID)
+	assert.Equal(t, string(simplecontent.ObjectStatusUploaded), resp.Status)
+
+	metadata, err := handler.storage.GetObjectMetadata(context.Background(), object.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", metadata["mime_type"])
+}
+
+func TestObjectsHandler_ConfirmUpload_BlobMissing(t *testing.T) {
+	handler, _, object := setupObjectsHandlerTest(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/"+object.ID.String()+"/confirm", nil)
+	w := httptest.NewRecorder()
+	handler.Routes().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), simplecontent.ErrBlobNotFound.Error())
+
+	unchanged, err := handler.storage.GetObject(context.Background(), object.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(simplecontent.ObjectStatusCreated), unchanged.Status)
+}
+
+func TestObjectsHandler_ConfirmUpload_InvalidID(t *testing.T) {
+	handler, _, _ := setupObjectsHandlerTest(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/not-a-uuid/confirm", nil)
+	w := httptest.NewRecorder()
+	handler.Routes().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultPageLimit is the page size of list responses when the request sets no limit
+	defaultPageLimit = 100
+	// maxPageLimit caps the limit a request may ask for
+	maxPageLimit = 1000
+)
+
+// PagedResponse is the envelope of every list response: one page of Items, the Total
+// number of items across all pages, and the Limit and Offset the page was cut with.
+// NextCursor is passed back as ?cursor= to fetch the next page and is empty on the last one.
+type PagedResponse[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pageParams is the page a list request asks for
+type pageParams struct {
+	limit  int
+	offset int
+}
+
+// parsePageParams reads the limit and offset query parameters. A cursor from a previous
+// response takes the place of offset.
+func parsePageParams(r *http.Request) (pageParams, error) {
+	query := r.URL.Query()
+	page := pageParams{limit: defaultPageLimit}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return pageParams{}, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		page.limit = limit
+	}
+	offset := query.Get("offset")
+	if cursor := query.Get("cursor"); cursor != "" {
+		offset = cursor
+	}
+	if offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return pageParams{}, fmt.Errorf("offset and cursor must be non-negative integers")
+		}
+		page.offset = n
+	}
+	return page, nil
+}
+
+// newPagedResponse cuts the requested page out of all items
+func newPagedResponse[T any](items []T, page pageParams) PagedResponse[T] {
+	resp := PagedResponse[T]{Items: []T{}, Total: len(items), Limit: page.limit, Offset: page.offset}
+	if page.offset >= len(items) {
+		return resp
+	}
+	end := min(page.offset+page.limit, len(items))
+	resp.Items = items[page.offset:end]
+	if end < len(items) {
+		resp.NextCursor = strconv.Itoa(end)
+	}
+	return resp
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// defaultStorageHealthTimeout bounds each backend probe of the storage health check
+const defaultStorageHealthTimeout = 5 * time.Second
+
+// StorageHealthHandler reports the reachability and latency of each storage backend.
+// Every backend is critical unless marked optional: a critical backend that is down makes
+// the check answer 503, an optional one only marks the report degraded.
+type StorageHealthHandler struct {
+	backends map[string]simplecontent.BlobStore
+	optional map[string]bool
+	timeout  time.Duration
+}
+
+// BackendHealth is the probe result of one backend
+type BackendHealth struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"` // "up" or "down"
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// StorageHealthResponse is the body of GET /health/storage
+type StorageHealthResponse struct {
+	Status   string          `json:"status"` // "healthy", "degraded" or "unhealthy"
+	Backends []BackendHealth `json:"backends"`
+}
+
+// NewStorageHealthHandler creates a storage health check over the given backends. The
+// backends named in optional do not fail the check when they are down.
+func NewStorageHealthHandler(backends map[string]simplecontent.BlobStore, optional ...string) *StorageHealthHandler {
+	h := &StorageHealthHandler{
+		backends: backends,
+		optional: make(map[string]bool, len(optional)),
+		timeout:  defaultStorageHealthTimeout,
+	}
+	for _, name := range optional {
+		h.optional[name] = true
+	}
+	return h
+}
+
+// ServeHTTP probes every backend concurrently with simplecontent.PingBackend
+func (h *StorageHealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	results := make([]BackendHealth, 0, len(h.backends))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, backend := range h.backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := h.probe(r.Context(), name, backend)
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	resp := StorageHealthResponse{Status: "healthy", Backends: results}
+	for _, result := range results {
+		if result.Status == "up" {
+			continue
+		}
+		if result.Critical {
+			resp.Status = "unhealthy"
+			break
+		}
+		resp.Status = "degraded"
+	}
+	if resp.Status == "unhealthy" {
+		render.Status(r, http.StatusServiceUnavailable)
+	}
+	render.JSON(w, r, resp)
+}
+
+func (h *StorageHealthHandler) probe(ctx context.Context, name string, backend simplecontent.BlobStore) BackendHealth {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := simplecontent.PingBackend(ctx, backend)
+	result := BackendHealth{
+		Name:      name,
+		Status:    "up",
+		Critical:  !h.optional[name],
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "down"
+		result.Error = err.Error()
+	}
+	return result
+}
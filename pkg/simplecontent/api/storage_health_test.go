@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+	memorystorage "github.com/tendant/simple-content/pkg/simplecontent/storage/memory"
+)
+
+// pingStub is a backend whose Ping fails with err
+type pingStub struct {
+	simplecontent.BlobStore
+	err error
+}
+
+func (p *pingStub) Ping(ctx context.Context) error {
+	return p.err
+}
+
+// unreachableStore is a backend without Ping whose metadata lookups fail
+type unreachableStore struct {
+	simplecontent.BlobStore
+}
+
+func (u *unreachableStore) GetObjectMeta(ctx context.Context, objectKey string) (*simplecontent.ObjectMeta, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestStorageHealthHandler(t *testing.T) {
+	serve := func(t *testing.T, h *StorageHealthHandler) (int, StorageHealthResponse) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health/storage", nil))
+		var resp StorageHealthResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp), rr.Body.String())
+		return rr.Code, resp
+	}
+
+	t.Run("AllHealthy", func(t *testing.T) {
+		code, resp := serve(t, NewStorageHealthHandler(map[string]simplecontent.BlobStore{
+			"memory": memorystorage.New(),
+			"s3":     &pingStub{BlobStore: memorystorage.New()},
+		}))
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "healthy", resp.Status)
+		require.Len(t, resp.Backends, 2)
+		for _, backend := range resp.Backends {
+			assert.Equal(t, "up", backend.Status, backend.Name)
+			assert.True(t, backend.Critical)
+			assert.Empty(t, backend.Error)
+		}
+	})
+
+	t.Run("CriticalDown", func(t *testing.T) {
+		code, resp := serve(t, NewStorageHealthHandler(map[string]simplecontent.BlobStore{
+			"memory":  memorystorage.New(),
+			"s3":      &pingStub{BlobStore: memorystorage.New(), err: errors.New("bucket unreachable")},
+			"archive": &unreachableStore{BlobStore: memorystorage.New()},
+		}))
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "unhealthy", resp.Status)
+		require.Len(t, resp.Backends, 3)
+
+		// Backends are reported by name
+		assert.Equal(t, "archive", resp.Backends[0].Name)
+		assert.Equal(t, "down", resp.Backends[0].Status)
+		assert.Contains(t, resp.Backends[0].Error, "connection refused")
+		assert.Equal(t, "memory", resp.Backends[1].Name)
+		assert.Equal(t, "up", resp.Backends[1].Status)
+		assert.Equal(t, "s3", resp.Backends[2].Name)
+		assert.Equal(t, "down", resp.Backends[2].Status)
+		assert.Equal(t, "bucket unreachable", resp.Backends[2].Error)
+	})
+
+	t.Run("OptionalDown", func(t *testing.T) {
+		code, resp := serve(t, NewStorageHealthHandler(map[string]simplecontent.BlobStore{
+			"memory": memorystorage.New(),
+			"mirror": &pingStub{BlobStore: memorystorage.New(), err: errors.New("bucket unreachable")},
+		}, "mirror"))
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "degraded", resp.Status)
+		require.Len(t, resp.Backends, 2)
+		assert.Equal(t, "mirror", resp.Backends[1].Name)
+		assert.Equal(t, "down", resp.Backends[1].Status)
+		assert.False(t, resp.Backends[1].Critical)
+	})
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// defaultStreamProgressInterval is how often StreamUpload reports progress
+const defaultStreamProgressInterval = time.Second
+
+// StreamProgressEvent is the data of a "progress" event sent by StreamUpload
+type StreamProgressEvent struct {
+	BytesReceived int64 `json:"bytes_received"`
+	TotalBytes    int64 `json:"total_bytes,omitempty"`
+}
+
+// StreamCompleteEvent is the data of the final "complete" event sent by StreamUpload
+type StreamCompleteEvent struct {
+	ContentID     string `json:"content_id"`
+	Status        string `json:"status"`
+	BytesReceived int64  `json:"bytes_received"`
+}
+
+// SetProgressInterval sets how often StreamUpload sends progress events. A zero or
+// negative interval keeps the default of one second.
+func (h *FilesHandler) SetProgressInterval(interval time.Duration) {
+	if interval > 0 {
+		h.progressInterval = interval
+	}
+}
+
+// StreamUpload stores the request body as a new content and reports progress over the same
+// connection as server-sent events, so proxies that time out silent connections keep long
+// uploads open. The file is described by query parameters: owner_id, tenant_id, file_name,
+// document_type and storage_backend_name. The response is a text/event-stream of
+// "progress" events carrying StreamProgressEvent, ended by one "complete" event carrying
+// StreamCompleteEvent or one "error" event carrying an ErrorBody.
+//
+// Errors found before the upload starts are returned as plain JSON errors.
+func (h *FilesHandler) StreamUpload(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	ownerID, err := uuid.Parse(query.Get("owner_id"))
+	if err != nil {
+		slog.Error("Invalid owner ID", "owner_id", query.Get("owner_id"), "error", err)
+		writeBadRequest(w, "Invalid owner ID")
+		return
+	}
+	tenantID, err := uuid.Parse(query.Get("tenant_id"))
+	if err != nil {
+		slog.Error("Invalid tenant ID", "tenant_id", query.Get("tenant_id"), "error", err)
+		writeBadRequest(w, "Invalid tenant ID")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, http.StatusInternalServerError, CodeInternalError, "Streaming not supported", nil)
+		return
+	}
+
+	storageBackendName := query.Get("storage_backend_name")
+	if storageBackendName == "" {
+		storageBackendName = DEFAULT_STORAGE_BACKEND
+	}
+	fileName := query.Get("file_name")
+
+	// Progress events are written while the body is still being read
+	if err := http.NewResponseController(w).EnableFullDuplex(); err != nil {
+		slog.Debug("Full duplex not enabled", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	body := &countingBody{reader: r.Body}
+	type result struct {
+		content *simplecontent.Content
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		content, err := h.service.UploadContent(r.Context(), simplecontent.UploadContentRequest{
+			OwnerID:            ownerID,
+			TenantID:           tenantID,
+			Name:               fileName,
+			DocumentType:       query.Get("document_type"),
+			StorageBackendName: storageBackendName,
+			Reader:             body,
+			FileName:           fileName,
+			FileSize:           r.ContentLength,
+		})
+		done <- result{content: content, err: err}
+	}()
+
+	ticker := time.NewTicker(h.progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			progress := StreamProgressEvent{BytesReceived: body.count.Load()}
+			if r.ContentLength > 0 {
+				progress.TotalBytes = r.ContentLength
+			}
+			writeEvent(w, flusher, "progress", progress)
+
+		case res := <-done:
+			if res.err != nil {
+				slog.Error("Streamed upload failed", "error", res.err)
+				_, code := ErrorStatusAndCode(res.err)
+				writeEvent(w, flusher, "error", ErrorBody{
+					Code:    code,
+					Message: simplecontent.ToErrorMessage(res.err),
+					Details: serviceErrorDetails(res.err),
+				})
+				return
+			}
+			slog.Info("Streamed upload complete", "content_id", res.content.ID, "bytes", body.count.Load())
+			writeEvent(w, flusher, "complete", StreamCompleteEvent{
+				ContentID:     res.content.ID.String(),
+				Status:        res.content.Status,
+				BytesReceived: body.count.Load(),
+			})
+			return
+		}
+	}
+}
+
+// writeEvent writes one server-sent event with a JSON data line and flushes it
+func writeEvent(w io.Writer, flusher http.Flusher, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		slog.Error("Failed to encode event", "event", event, "error", err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	flusher.Flush()
+}
+
+// countingBody counts the bytes read from a request body. The count is read by the
+// handler goroutine while the upload goroutine reads the body.
+type countingBody struct {
+	reader io.Reader
+	count  atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	b.count.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowBody yields its chunks with a pause before each one
+type slowBody struct {
+	chunks []string
+	pause  time.Duration
+}
+
+func (b *slowBody) Read(p []byte) (int, error) {
+	if len(b.chunks) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(b.pause)
+	n := copy(p, b.chunks[0])
+	b.chunks[0] = b.chunks[0][n:]
+	if b.chunks[0] == "" {
+		b.chunks = b.chunks[1:]
+	}
+	return n, nil
+}
+
+type streamEvent struct {
+	name string
+	data string
+}
+
+// readEvents parses a text/event-stream body
+func readEvents(t *testing.T, body io.Reader) []streamEvent {
+	t.Helper()
+	var events []streamEvent
+	var current streamEvent
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, current)
+			current = streamEvent{}
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestFilesHandler_StreamUpload(t *testing.T) {
+	handler, service, _ := setupFilesHandlerTest(t)
+	handler.SetProgressInterval(5 * time.Millisecond)
+	router := chi.NewRouter()
+	router.Post("/stream", handler.StreamUpload)
+
+	chunks := []string{"first chunk,", "second chunk,", "third chunk,", "last chunk"}
+	data := strings.Join(chunks, "")
+	target := "/stream?owner_id=" + uuid.NewString() + "&tenant_id=" + uuid.NewString() +
+		"&file_name=large.txt&storage_backend_name=memory"
+	req := httptest.NewRequest(http.MethodPost, target, &slowBody{chunks: chunks, pause: 20 * time.Millisecond})
+	req.ContentLength = int64(len(data))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	events := readEvents(t, w.Body)
+	require.GreaterOrEqual(t, len(events), 2)
+
+	var progress []StreamProgressEvent
+	for _, event := range events[:len(events)-1] {
+		require.Equal(t, "progress", event.name)
+		var p StreamProgressEvent
+		require.NoError(t, json.Unmarshal([]byte(event.data), &p))
+		assert.Equal(t, int64(len(data)), p.TotalBytes)
+		progress = append(progress, p)
+	}
+	require.NotEmpty(t, progress, "expected progress events during the upload")
+	for i := 1; i < len(progress); i++ {
+		assert.GreaterOrEqual(t, progress[i].BytesReceived, progress[i-1].BytesReceived)
+	}
+
+	last := events[len(events)-1]
+	require.Equal(t, "complete", last.name)
+	var complete StreamCompleteEvent
+	require.NoError(t, json.Unmarshal([]byte(last.data), &complete))
+	assert.Equal(t, int64(len(data)), complete.BytesReceived)
+
+	contentID, err := uuid.Parse(complete.ContentID)
+	require.NoError(t, err)
+	reader, err := service.DownloadContent(context.Background(), contentID)
+	require.NoError(t, err)
+	defer reader.Close()
+	stored, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, data, string(stored))
+}
+
+func TestFilesHandler_StreamUpload_Errors(t *testing.T) {
+	handler, _, _ := setupFilesHandlerTest(t)
+	router := chi.NewRouter()
+	router.Post("/stream", handler.StreamUpload)
+
+	t.Run("InvalidOwnerID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/stream?owner_id=bad&tenant_id="+uuid.NewString(), strings.NewReader("data"))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("UnknownBackend", func(t *testing.T) {
+		target := "/stream?owner_id=" + uuid.NewString() + "&tenant_id=" + uuid.NewString() + "&storage_backend_name=gcs"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader("data")))
+
+		events := readEvents(t, w.Body)
+		require.Len(t, events, 1)
+		assert.Equal(t, "error", events[0].name)
+		var body ErrorBody
+		require.NoError(t, json.Unmarshal([]byte(events[0].data), &body))
+		assert.Equal(t, CodeStorageBackendNotFound, body.Code)
+	})
+}
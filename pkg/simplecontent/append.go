@@ -0,0 +1,154 @@
+package simplecontent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MetaAppendHashState is the object metadata key holding the SHA-256 state of the data
+// appended so far, "<size>:<base64 state>", so the next append only hashes its own bytes
+const MetaAppendHashState = "append_hash_state"
+
+// blobAppend appends reader to the data of objectKey when the backend implements
+// ObjectAppender and fails with ErrAppendNotSupported otherwise
+func blobAppend(ctx context.Context, backend BlobStore, objectKey string, reader io.Reader) error {
+	if appender, ok := backend.(ObjectAppender); ok {
+		return appender.AppendObject(ctx, objectKey, reader)
+	}
+	return fmt.Errorf("%w: %T", ErrAppendNotSupported, backend)
+}
+
+// AppendToObject appends the data of r to the object's stored data, e.g. for logs or
+// uploads that arrive in chunks, and returns the updated object metadata. The object may
+// be new or already uploaded; it is marked uploaded afterwards. Size, ETag and, with
+// WithContentETags, the content ETag are updated, the latter by extending the hash of the
+// previous data rather than reading it again. With content-addressed keys the data is
+// appended to a copy of the shared blob, which then moves to the key of its new content.
+// Backends that do not implement ObjectAppender fail with ErrAppendNotSupported.
+func (s *service) AppendToObject(ctx context.Context, objectID uuid.UUID, r io.Reader) (*ObjectMetadata, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	object, err := s.repository.GetObject(ctx, objectID)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "append", Err: err}
+	}
+	switch object.Status {
+	case string(ObjectStatusCreated), string(ObjectStatusUploaded):
+	default:
+		return nil, &ObjectError{ObjectID: objectID, Op: "append", Err: fmt.Errorf("%w: cannot append to %s object", ErrInvalidObjectStatus, object.Status)}
+	}
+	backend, err := s.GetBackend(object.StorageBackendName)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "append", Err: err}
+	}
+
+	// Concurrent appends would interleave their data and lose each other's hash state
+	unlock, err := s.repository.AcquireLock(ctx, "append:"+objectID.String())
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "append", Err: err}
+	}
+	defer unlock()
+
+	// Data stored by content may be shared, so it is appended to a copy at the staging key
+	previousKey := s.stageUpload(object)
+	if previousKey != "" {
+		if err := s.stageAppend(ctx, backend, object, previousKey); err != nil {
+			return nil, &ObjectError{ObjectID: objectID, Op: "append", Err: err}
+		}
+	}
+
+	var h hash.Hash
+	if s.contentETags {
+		h = s.appendHash(ctx, backend, object)
+		if h != nil {
+			r = io.TeeReader(r, h)
+		}
+	}
+	if err := blobAppend(ctx, backend, object.ObjectKey, r); err != nil {
+		s.stats.backendError(object.StorageBackendName)
+		return nil, &ObjectError{ObjectID: objectID, Op: "append", Err: err}
+	}
+
+	objectMetadata, err := s.updateObjectFromStorage(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if h != nil {
+		if objectMetadata.Metadata == nil {
+			objectMetadata.Metadata = make(map[string]interface{})
+		}
+		if state, err := h.(encoding.BinaryMarshaler).MarshalBinary(); err == nil {
+			objectMetadata.Metadata[MetaAppendHashState] = strconv.FormatInt(objectMetadata.SizeBytes, 10) + ":" + base64.StdEncoding.EncodeToString(state)
+		}
+		s.setContentETag(ctx, objectMetadata, ContentETag(h.Sum(nil)))
+	}
+	s.addressByContent(ctx, object, previousKey)
+	if err := s.updateContentMetadata(ctx, object.ContentID, objectMetadata); err != nil {
+		slog.Warn("Failed to update content metadata after append", "content_id", object.ContentID, "object_id", objectID, "error", err)
+	}
+	return objectMetadata, nil
+}
+
+// stageAppend copies the data at previousKey to the staging key object now holds and points
+// the object there, so the append leaves the blob other objects share unchanged. The copy is
+// removed again if the record cannot be updated.
+func (s *service) stageAppend(ctx context.Context, backend BlobStore, object *Object, previousKey string) error {
+	if _, ok := backend.(ObjectAppender); !ok {
+		return fmt.Errorf("%w: %T", ErrAppendNotSupported, backend)
+	}
+	if err := s.copyObjectData(ctx, backend, object.StorageBackendName, previousKey, object.ObjectKey); err != nil {
+		return err
+	}
+	if err := s.repository.UpdateObject(ctx, object); err != nil {
+		if undoErr := backend.Delete(context.WithoutCancel(ctx), object.ObjectKey); undoErr != nil {
+			slog.Warn("Failed to delete staged copy after record update failed", "object_id", object.ID, "key", object.ObjectKey, "error", undoErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// appendHash returns a SHA-256 hash of the object's current data, restored from the state
+// stored by the previous append when it still matches the stored size, or computed by
+// reading the data otherwise. Missing data hashes as empty; nil is returned when the data
+// cannot be read.
+func (s *service) appendHash(ctx context.Context, backend BlobStore, object *Object) hash.Hash {
+	h := sha256.New()
+	existing, _ := s.repository.GetObjectMetadata(ctx, object.ID)
+	if existing != nil {
+		if state, ok := existing.Metadata[MetaAppendHashState].(string); ok {
+			size, encoded, _ := strings.Cut(state, ":")
+			raw, err := base64.StdEncoding.DecodeString(encoded)
+			if err == nil && size == strconv.FormatInt(existing.SizeBytes, 10) &&
+				h.(encoding.BinaryUnmarshaler).UnmarshalBinary(raw) == nil {
+				return h
+			}
+			h.Reset()
+		}
+	}
+	rc, err := backend.Download(ctx, object.ObjectKey)
+	if err != nil {
+		if isBlobMissing(err) {
+			return h
+		}
+		slog.Warn("Failed to read object for append hash", "object_id", object.ID, "error", err)
+		return nil
+	}
+	defer rc.Close()
+	if _, err := io.Copy(h, rc); err != nil {
+		slog.Warn("Failed to read object for append hash", "object_id", object.ID, "error", err)
+		return nil
+	}
+	return h
+}
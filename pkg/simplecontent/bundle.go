@@ -0,0 +1,158 @@
+package simplecontent
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// bundleEntry is one file of a content bundle
+type bundleEntry struct {
+	name   string
+	object *Object
+}
+
+// DownloadContentBundle streams a tar archive holding the content's data and the data of
+// each derived content to w. The original is named after its file name (default
+// "original"); each derived variant is named by its variant key plus the extension of its
+// file. Entries are copied straight from storage one at a time, so the bundle is never
+// held in memory. Derived content that is not ready for download, or has no uploaded
+// data, is left out. Entry names are reduced to a single path element.
+//
+// Nothing is written to w when the content or its data cannot be found; an error after the
+// first entry leaves w with a truncated archive.
+func (s *service) DownloadContentBundle(ctx context.Context, contentID uuid.UUID, w io.Writer) error {
+	if err := s.requireContent(ctx, contentID); err != nil {
+		return &ContentError{ContentID: contentID, Op: "download_bundle", Err: err}
+	}
+	content, err := s.repository.GetContent(ctx, contentID)
+	if err != nil {
+		return &ContentError{ContentID: contentID, Op: "download_bundle", Err: err}
+	}
+	if ok, statusErr := canDownloadContent(ContentStatus(content.Status)); !ok {
+		return &ContentError{ContentID: contentID, Op: "download_bundle", Err: statusErr}
+	}
+
+	original, err := s.latestUploadedObject(ctx, contentID)
+	if err != nil {
+		return &ContentError{ContentID: contentID, Op: "download_bundle", Err: err}
+	}
+	if original == nil {
+		return &ContentError{ContentID: contentID, Op: "download_bundle", Err: ErrNoUploadedObjects}
+	}
+	entries := []bundleEntry{{name: s.bundleFileName(ctx, contentID, "original", original), object: original}}
+
+	derived, err := s.ListDerivedContent(ctx, WithParentID(contentID))
+	if err != nil {
+		return &ContentError{ContentID: contentID, Op: "download_bundle", Err: err}
+	}
+	for _, d := range derived {
+		if ok, _ := canDownloadContent(ContentStatus(d.Status)); !ok {
+			continue
+		}
+		object, err := s.latestUploadedObject(ctx, d.ContentID)
+		if err != nil {
+			return &ContentError{ContentID: d.ContentID, Op: "download_bundle", Err: err}
+		}
+		if object == nil {
+			continue
+		}
+		variant := d.Variant
+		if variant == "" {
+			variant = d.DerivationType
+		}
+		name := bundleEntryName(variant, "derived") + path.Ext(s.bundleFileName(ctx, d.ContentID, variant, object))
+		entries = append(entries, bundleEntry{name: name, object: object})
+	}
+
+	tw := tar.NewWriter(w)
+	seen := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		name := entry.name
+		if n := seen[name]; n > 0 {
+			ext := path.Ext(name)
+			name = name[:len(name)-len(ext)] + "-" + strconv.Itoa(n+1) + ext
+		}
+		seen[entry.name]++
+
+		if err := s.writeBundleEntry(ctx, tw, name, entry.object); err != nil {
+			return &ObjectError{ObjectID: entry.object.ID, Op: "download_bundle", Err: err}
+		}
+	}
+	return tw.Close()
+}
+
+// latestUploadedObject returns the newest uploaded object of the content, or nil if the
+// content has none
+func (s *service) latestUploadedObject(ctx context.Context, contentID uuid.UUID) (*Object, error) {
+	objects, err := s.repository.GetObjectsByContentID(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		if obj.Status == string(ObjectStatusUploaded) {
+			return obj, nil
+		}
+	}
+	return nil, nil
+}
+
+// bundleFileName returns the file name recorded for the content, falling back to
+// fallback plus the extension of the object key
+func (s *service) bundleFileName(ctx context.Context, contentID uuid.UUID, fallback string, object *Object) string {
+	if metadata, err := s.repository.GetContentMetadata(ctx, contentID); err == nil && metadata != nil && metadata.FileName != "" {
+		return bundleEntryName(metadata.FileName, fallback)
+	}
+	return bundleEntryName(fallback, "original") + path.Ext(object.ObjectKey)
+}
+
+// bundleEntryName reduces name to its last path element so an entry cannot point outside
+// the archive root, falling back to fallback when nothing usable is left
+func bundleEntryName(name, fallback string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == ".." || name == "/" {
+		return fallback
+	}
+	return name
+}
+
+// writeBundleEntry copies one object into the archive
+func (s *service) writeBundleEntry(ctx context.Context, tw *tar.Writer, name string, object *Object) error {
+	backend, err := s.GetBackend(object.StorageBackendName)
+	if err != nil {
+		return err
+	}
+	meta, err := s.objectMeta(ctx, backend, object)
+	if err != nil {
+		s.stats.backendError(object.StorageBackendName)
+		return fmt.Errorf("failed to get object meta: %w", err)
+	}
+
+	reader, err := backend.Download(ctx, object.ObjectKey)
+	reader = s.trackDownload(object.StorageBackendName, reader, err)
+	if err != nil {
+		return fmt.Errorf("failed to download object: %w", err)
+	}
+	defer reader.Close()
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    meta.Size,
+		ModTime: object.UpdatedAt,
+		Format:  tar.FormatPAX,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tw, reader); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
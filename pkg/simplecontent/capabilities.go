@@ -0,0 +1,66 @@
+package simplecontent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tendant/simple-content/pkg/simplecontent/urlstrategy"
+)
+
+// GetBackendCapabilities returns the capabilities reported by a storage backend. Backends
+// that do not implement PresignCapable are assumed to support presigned URLs.
+func GetBackendCapabilities(backend BlobStore) BackendCapabilities {
+	if capable, ok := backend.(PresignCapable); ok {
+		return capable.Capabilities()
+	}
+	return BackendCapabilities{PresignedUpload: true, PresignedDownload: true}
+}
+
+// pingProbeKey is looked up on backends that do not implement Pinger; it is never written
+const pingProbeKey = ".simplecontent-ping"
+
+// PingBackend checks that a storage backend is reachable. Backends implementing Pinger
+// check themselves; others are probed with an existence check of a key that does not
+// exist, which only fails when the backend cannot be reached.
+func PingBackend(ctx context.Context, backend BlobStore) error {
+	if pinger, ok := backend.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	_, err := blobExists(ctx, backend, pingProbeKey)
+	return err
+}
+
+// blobDownloadURLWithDisposition returns a download URL with the disposition signed in when
+// the backend implements urlstrategy.DispositionURLSigner, and its plain download URL otherwise
+func blobDownloadURLWithDisposition(ctx context.Context, backend BlobStore, objectKey, disposition, downloadFilename string) (string, error) {
+	if signer, ok := backend.(urlstrategy.DispositionURLSigner); ok {
+		return signer.GetDownloadURLWithDisposition(ctx, objectKey, disposition, downloadFilename)
+	}
+	return backend.GetDownloadURL(ctx, objectKey, downloadFilename)
+}
+
+// presignUnsupported returns the error for a presigned URL the backend cannot issue
+func presignUnsupported(backendName string) error {
+	return fmt.Errorf("%w: backend %s", ErrPresignNotSupported, backendName)
+}
+
+// strategyNeedsUploadPresign reports whether the URL strategy hands out backend upload
+// URLs. Content-based and CDN upload URLs point at the application, which accepts the
+// upload for any backend.
+func (s *service) strategyNeedsUploadPresign() bool {
+	_, delegated := s.urlStrategy.(*urlstrategy.StorageDelegatedStrategy)
+	return delegated
+}
+
+// canIssueUploadURL reports whether GetContentDetails can hand out an upload URL for
+// objects on the named backend
+func (s *service) canIssueUploadURL(backendName string) bool {
+	if !s.strategyNeedsUploadPresign() {
+		return true
+	}
+	backend, err := s.GetBackend(backendName)
+	if err != nil {
+		return false
+	}
+	return GetBackendCapabilities(backend).PresignedUpload
+}
@@ -0,0 +1,123 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// CollisionPolicy decides what CreateObject does when an explicit object key is already
+// in use, either by another object or by data stored on the backend
+type CollisionPolicy string
+
+const (
+	// CollisionPolicyError fails CreateObject with ErrObjectKeyExists
+	CollisionPolicyError CollisionPolicy = "error"
+	// CollisionPolicyOverwrite reuses the key; the next upload replaces the stored data and
+	// the object previously holding the key is soft deleted
+	CollisionPolicyOverwrite CollisionPolicy = "overwrite"
+	// CollisionPolicyAutoRename appends a short unique suffix to the key, before the extension
+	CollisionPolicyAutoRename CollisionPolicy = "auto_rename"
+)
+
+// maxRenameAttempts bounds the suffixes tried by CollisionPolicyAutoRename
+const maxRenameAttempts = 5
+
+// WithCollisionPolicy sets the default policy for CreateObject requests with an explicit
+// ObjectKey. Without a policy the key is used as given and no existence check is made.
+func WithCollisionPolicy(policy CollisionPolicy) Option {
+	return func(s *service) {
+		s.collisionPolicy = policy
+	}
+}
+
+// blobExists reports whether objectKey is stored on the backend
+func blobExists(ctx context.Context, backend BlobStore, objectKey string) (bool, error) {
+	if checker, ok := backend.(ObjectExistsChecker); ok {
+		return checker.ObjectExists(ctx, objectKey)
+	}
+	if _, err := backend.GetObjectMeta(ctx, objectKey); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// objectKeyInUse reports whether another object record or stored data uses the key.
+// The object holding the key is returned when there is one.
+func (s *service) objectKeyInUse(ctx context.Context, backend BlobStore, backendName, objectKey string) (bool, *Object, error) {
+	existing, err := s.repository.GetObjectByObjectKeyAndStorageBackendName(ctx, objectKey, backendName)
+	if err == nil && existing != nil {
+		return true, existing, nil
+	}
+	if err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return false, nil, err
+	}
+
+	exists, err := blobExists(ctx, backend, objectKey)
+	return exists, nil, err
+}
+
+// resolveObjectKeyCollision applies the collision policy to an explicit object key and
+// returns the key to use
+func (s *service) resolveObjectKeyCollision(ctx context.Context, backend BlobStore, backendName, objectKey string, policy CollisionPolicy) (string, error) {
+	if policy == "" {
+		policy = s.collisionPolicy
+	}
+	if policy == "" {
+		return objectKey, nil
+	}
+
+	inUse, existing, err := s.objectKeyInUse(ctx, backend, backendName, objectKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to check object key: %w", err)
+	}
+	if !inUse {
+		return objectKey, nil
+	}
+
+	switch policy {
+	case CollisionPolicyError:
+		return "", fmt.Errorf("%w: %s on backend %s", ErrObjectKeyExists, objectKey, backendName)
+
+	case CollisionPolicyOverwrite:
+		if existing != nil {
+			if err := s.repository.DeleteObject(ctx, existing.ID); err != nil {
+				return "", fmt.Errorf("failed to release object key: %w", err)
+			}
+			slog.Info("Object key reassigned", "key", objectKey, "backend", backendName, "previous_object_id", existing.ID)
+		}
+		return objectKey, nil
+
+	case CollisionPolicyAutoRename:
+		for i := 0; i < maxRenameAttempts; i++ {
+			candidate := renameObjectKey(objectKey)
+			inUse, _, err := s.objectKeyInUse(ctx, backend, backendName, candidate)
+			if err != nil {
+				return "", fmt.Errorf("failed to check object key: %w", err)
+			}
+			if !inUse {
+				return candidate, nil
+			}
+		}
+		return "", fmt.Errorf("%w: no free name for %s after %d attempts", ErrObjectKeyExists, objectKey, maxRenameAttempts)
+
+	default:
+		return "", fmt.Errorf("invalid collision policy %q", policy)
+	}
+}
+
+// renameObjectKey inserts a short random suffix before the extension of the last path
+// segment, e.g. "docs/report.pdf" becomes "docs/report-1a2b3c4d.pdf"
+func renameObjectKey(objectKey string) string {
+	suffix := strings.ReplaceAll(uuid.NewString(), "-", "")[:8]
+	ext := path.Ext(objectKey)
+	return strings.TrimSuffix(objectKey, ext) + "-" + suffix + ext
+}
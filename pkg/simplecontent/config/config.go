@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/tendant/simple-content/pkg/simplecontent"
@@ -34,6 +36,7 @@ func Load(opts ...Option) (*ServerConfig, error) {
 			return nil, err
 		}
 	}
+	cfg.applyPresignedDefaults()
 
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -69,6 +72,10 @@ func defaults() ServerConfig {
 		},
 		Port:        "8080",
 		Environment: "development",
+		Presigned: PresignedConfig{
+			DefaultExpiry: time.Hour,
+			URLPattern:    "/api/v1/upload/{key}",
+		},
 	}
 }
 
@@ -76,44 +83,82 @@ func defaults() ServerConfig {
 // This is the core configuration used by applications embedding the library
 type ServiceConfig struct {
 	// Database configuration
-	DatabaseURL  string
-	DatabaseType string // "memory", "postgres"
-	DBSchema     string // Postgres schema to use (default: content)
+	DatabaseURL  string `yaml:"database_url"`
+	DatabaseType string `yaml:"database_type"` // "memory", "postgres"
+	DBSchema     string `yaml:"db_schema"`     // Postgres schema to use (default: content)
+
+	// Postgres connection pool sizing. Zero values keep the pgx default or the pool_*
+	// parameter from DatabaseURL; non-zero values override them.
+	DBMaxConns        int32         `yaml:"db_max_conns"`          // pgx default: max(4, NumCPU)
+	DBMinConns        int32         `yaml:"db_min_conns"`          // pgx default: 0
+	DBMaxConnLifetime time.Duration `yaml:"db_max_conn_lifetime"`  // pgx default: 1h
+	DBMaxConnIdleTime time.Duration `yaml:"db_max_conn_idle_time"` // pgx default: 30m
 
 	// Storage configuration
-	DefaultStorageBackend string
-	StorageBackends       []StorageBackendConfig
+	DefaultStorageBackend string                 `yaml:"default_storage_backend"`
+	StorageBackends       []StorageBackendConfig `yaml:"storage_backends"`
+	// TenantBackends maps tenant IDs to the backend holding their new objects, for tenants
+	// with their own bucket. Other tenants use DefaultStorageBackend.
+	TenantBackends map[string]string `yaml:"tenant_backends"`
+	// DefaultTenantID and DefaultOwnerID fill requests that name no tenant or owner, for
+	// single-tenant deployments. Empty leaves the IDs required.
+	DefaultTenantID string `yaml:"default_tenant_id"`
+	DefaultOwnerID  string `yaml:"default_owner_id"`
 
 	// Service options
-	EnableEventLogging bool
-	EnablePreviews     bool
+	EnableEventLogging bool `yaml:"enable_event_logging"`
+	EnablePreviews     bool `yaml:"enable_previews"`
 
 	// URL generation
-	URLStrategy     string // "cdn", "content-based", "storage-delegated"
-	CDNBaseURL      string // Base URL for CDN strategy downloads (e.g., "https://cdn.example.com")
-	UploadBaseURL   string // Base URL for CDN strategy uploads (e.g., "https://api.example.com" or "/api/v1")
-	APIBaseURL      string // Base URL for content-based strategy (e.g., "/api/v1")
+	URLStrategy   string `yaml:"url_strategy"`    // "cdn", "content-based", "storage-delegated"
+	CDNBaseURL    string `yaml:"cdn_base_url"`    // Base URL for CDN strategy downloads (e.g., "https://cdn.example.com")
+	UploadBaseURL string `yaml:"upload_base_url"` // Base URL for CDN strategy uploads (e.g., "https://api.example.com" or "/api/v1")
+	APIBaseURL    string `yaml:"api_base_url"`    // Base URL for content-based strategy (e.g., "/api/v1")
 
 	// Object key generation
-	ObjectKeyGenerator string // "default", "git-like", "tenant-aware", "legacy"
+	ObjectKeyGenerator string `yaml:"object_key_generator"` // "default", "git-like", "tenant-aware", "legacy"
+	SanitizeObjectKeys bool   `yaml:"sanitize_object_keys"` // Strip traversal and percent-encode unsafe characters in object keys
+	NormalizeKeyCase   bool   `yaml:"normalize_key_case"`   // Lowercase object keys so keys differing only in case name one object
 }
 
 // ServerConfig represents server configuration for the simple-content HTTP server (cmd/server-configured)
 // This extends ServiceConfig with server-specific settings
 type ServerConfig struct {
-	ServiceConfig
+	ServiceConfig `yaml:",inline"`
 
 	// Server-specific configuration
-	Port           string
-	Environment    string // development, production, testing
-	EnableAdminAPI bool   // Enable admin API endpoints (requires authentication in production)
+	Port           string `yaml:"port"`
+	Environment    string `yaml:"environment"`      // development, production, testing
+	EnableAdminAPI bool   `yaml:"enable_admin_api"` // Enable admin API endpoints (requires authentication in production)
+
+	// DisableObjectAPI leaves out the raw /objects endpoints. Deployments that only use the
+	// content-level API can set it; object routes then return 404.
+	DisableObjectAPI bool `yaml:"disable_object_api"` // default: false
+
+	// CORS configuration. When CORSAllowedOrigins is empty, development servers allow
+	// any origin ("*") and other environments send no CORS headers.
+	CORSAllowedOrigins   []string `yaml:"cors_allowed_origins"`
+	CORSAllowCredentials bool     `yaml:"cors_allow_credentials"`
+
+	// TrustedActorHeader names the request header whose value is recorded as the actor in
+	// the access audit log, e.g. "X-Actor-ID". Clients can set any header, so enable it only
+	// behind a gateway that authenticates callers and overwrites the header. Empty (the
+	// default) records no actor.
+	TrustedActorHeader string `yaml:"trusted_actor_header"`
+
+	// Presigned holds the signing secret, expiry and URL pattern of presigned URLs
+	Presigned PresignedConfig `yaml:"presigned"`
 }
 
 // StorageBackendConfig represents configuration for a storage backend
 type StorageBackendConfig struct {
-	Name   string
-	Type   string // "memory", "fs", "s3"
-	Config map[string]interface{}
+	Name    string                 `yaml:"name"`
+	Type    string                 `yaml:"type"` // "memory", "fs", "s3"
+	Config  map[string]interface{} `yaml:"config"`
+	Timeout time.Duration          `yaml:"timeout"` // Per-operation timeout (e.g. "30s"); zero means no limit
+	// MigrateFrom names the backend this one replaces. Objects missing here are copied
+	// from it on first read.
+	MigrateFrom string `yaml:"migrate_from"`
 }
 
 // Validate validates the service configuration
@@ -126,21 +171,59 @@ func (c *ServiceConfig) Validate() error {
 		return errors.New("database_url is required when using postgres")
 	}
 
+	if c.DBMaxConns < 0 || c.DBMinConns < 0 || c.DBMaxConnLifetime < 0 || c.DBMaxConnIdleTime < 0 {
+		return errors.New("database pool settings must not be negative")
+	}
+	if c.DBMaxConns > 0 && c.DBMinConns > c.DBMaxConns {
+		return fmt.Errorf("db_min_conns (%d) must not exceed db_max_conns (%d)", c.DBMinConns, c.DBMaxConns)
+	}
+
 	// Ensure default storage backend exists in configured backends
 	found := false
+	names := make([]string, 0, len(c.StorageBackends))
 	for _, backend := range c.StorageBackends {
+		names = append(names, backend.Name)
 		if backend.Name == c.DefaultStorageBackend {
 			found = true
 			break
 		}
 	}
 	if !found {
-		return fmt.Errorf("default storage backend '%s' not found in configured backends", c.DefaultStorageBackend)
+		return fmt.Errorf("default storage backend: %w", &simplecontent.BackendNotFoundError{Name: c.DefaultStorageBackend, Available: names})
+	}
+	if _, err := c.tenantBackends(); err != nil {
+		return err
+	}
+	if _, _, err := c.defaultOwner(); err != nil {
+		return err
+	}
+	if err := c.validateMigrations(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// validateMigrations checks that every migrate_from names another configured backend
+func (c *ServiceConfig) validateMigrations() error {
+	names := make([]string, 0, len(c.StorageBackends))
+	for _, backend := range c.StorageBackends {
+		names = append(names, backend.Name)
+	}
+	for _, backend := range c.StorageBackends {
+		if backend.MigrateFrom == "" {
+			continue
+		}
+		if backend.MigrateFrom == backend.Name {
+			return fmt.Errorf("storage backend %s cannot migrate from itself", backend.Name)
+		}
+		if !slices.Contains(names, backend.MigrateFrom) {
+			return fmt.Errorf("migrate_from of storage backend %s: %w", backend.Name, &simplecontent.BackendNotFoundError{Name: backend.MigrateFrom, Available: names})
+		}
+	}
+	return nil
+}
+
 // Validate validates the server configuration (includes service validation + server-specific checks)
 func (c *ServerConfig) Validate() error {
 	// Validate service-level config first
@@ -152,6 +235,9 @@ func (c *ServerConfig) Validate() error {
 	if c.Port == "" {
 		return errors.New("port is required")
 	}
+	if err := c.Presigned.Validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -175,7 +261,44 @@ func (c *ServiceConfig) BuildService() (simplecontent.Service, error) {
 			return nil, fmt.Errorf("failed to build storage backend %s: %w", backendConfig.Name, err)
 		}
 		blobStores[backendConfig.Name] = store
-		options = append(options, simplecontent.WithBlobStore(backendConfig.Name, store))
+		if backendConfig.Timeout > 0 {
+			options = append(options, simplecontent.WithStorageTimeout(backendConfig.Name, backendConfig.Timeout))
+		}
+	}
+	// Backends replacing another one read through to it; the old backend stays registered
+	// for objects still recorded on it
+	for _, backendConfig := range c.StorageBackends {
+		if backendConfig.MigrateFrom == "" {
+			continue
+		}
+		source, ok := blobStores[backendConfig.MigrateFrom]
+		if !ok {
+			return nil, fmt.Errorf("migrate_from of storage backend %s: %w", backendConfig.Name, &simplecontent.BackendNotFoundError{Name: backendConfig.MigrateFrom})
+		}
+		blobStores[backendConfig.Name] = simplecontent.NewMigratingBlobStore(blobStores[backendConfig.Name], source)
+	}
+	for _, backendConfig := range c.StorageBackends {
+		options = append(options, simplecontent.WithBlobStore(backendConfig.Name, blobStores[backendConfig.Name]))
+	}
+	if c.DefaultStorageBackend != "" {
+		options = append(options, simplecontent.WithDefaultStorageBackend(c.DefaultStorageBackend))
+	}
+	tenantBackends, err := c.tenantBackends()
+	if err != nil {
+		return nil, err
+	}
+	if len(tenantBackends) > 0 {
+		options = append(options, simplecontent.WithTenantBackends(tenantBackends))
+	}
+	tenantID, ownerID, err := c.defaultOwner()
+	if err != nil {
+		return nil, err
+	}
+	if tenantID != uuid.Nil {
+		options = append(options, simplecontent.WithDefaultTenant(tenantID))
+	}
+	if ownerID != uuid.Nil {
+		options = append(options, simplecontent.WithDefaultOwner(ownerID))
 	}
 
 	// Set up event sink
@@ -196,6 +319,12 @@ func (c *ServiceConfig) BuildService() (simplecontent.Service, error) {
 		return nil, fmt.Errorf("failed to build object key generator: %w", err)
 	}
 	options = append(options, simplecontent.WithObjectKeyGenerator(keyGenerator))
+	if c.SanitizeObjectKeys {
+		options = append(options, simplecontent.WithObjectKeySanitizer(objectkey.SanitizeKey))
+	}
+	if c.NormalizeKeyCase {
+		options = append(options, simplecontent.WithKeyCaseNormalization())
+	}
 
 	// Set up URL strategy
 	urlStrategy, err := c.buildURLStrategyWithBlobStores(blobStores)
@@ -207,6 +336,45 @@ func (c *ServiceConfig) BuildService() (simplecontent.Service, error) {
 	return simplecontent.New(options...)
 }
 
+// tenantBackends parses TenantBackends, checking every backend is configured
+func (c *ServiceConfig) tenantBackends() (map[uuid.UUID]string, error) {
+	backends := make(map[uuid.UUID]string, len(c.TenantBackends))
+	for tenant, name := range c.TenantBackends {
+		tenantID, err := uuid.Parse(tenant)
+		if err != nil {
+			return nil, fmt.Errorf("tenant backend: invalid tenant id %q: %w", tenant, err)
+		}
+		found := false
+		names := make([]string, 0, len(c.StorageBackends))
+		for _, backend := range c.StorageBackends {
+			names = append(names, backend.Name)
+			if backend.Name == name {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("backend for tenant %s: %w", tenantID, &simplecontent.BackendNotFoundError{Name: name, Available: names})
+		}
+		backends[tenantID] = name
+	}
+	return backends, nil
+}
+
+// defaultOwner parses DefaultTenantID and DefaultOwnerID; unset IDs are uuid.Nil
+func (c *ServiceConfig) defaultOwner() (tenantID, ownerID uuid.UUID, err error) {
+	if c.DefaultTenantID != "" {
+		if tenantID, err = uuid.Parse(c.DefaultTenantID); err != nil {
+			return uuid.Nil, uuid.Nil, fmt.Errorf("invalid default_tenant_id %q: %w", c.DefaultTenantID, err)
+		}
+	}
+	if c.DefaultOwnerID != "" {
+		if ownerID, err = uuid.Parse(c.DefaultOwnerID); err != nil {
+			return uuid.Nil, uuid.Nil, fmt.Errorf("invalid default_owner_id %q: %w", c.DefaultOwnerID, err)
+		}
+	}
+	return tenantID, ownerID, nil
+}
+
 // BuildRepository builds just the repository from configuration
 func (c *ServiceConfig) BuildRepository() (simplecontent.Repository, error) {
 	return c.buildRepository()
@@ -230,22 +398,9 @@ func (c *ServiceConfig) buildRepository() (simplecontent.Repository, error) {
 	case "memory":
 		return memory.New(), nil
 	case "postgres":
-		if c.DatabaseURL == "" {
-			return nil, errors.New("database_url is required for postgres")
-		}
-		cfg, err := pgxpool.ParseConfig(c.DatabaseURL)
+		cfg, err := c.PostgresPoolConfig()
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse DATABASE_URL: %w", err)
-		}
-		// Optionally set search_path for the connection
-		schema := c.DBSchema
-		cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
-			if schema == "" {
-				return nil
-			}
-			// set search_path for this session
-			_, err := conn.Exec(ctx, fmt.Sprintf("SET search_path TO %s", schema))
-			return err
+			return nil, err
 		}
 		pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
 		if err != nil {
@@ -257,6 +412,44 @@ func (c *ServiceConfig) buildRepository() (simplecontent.Repository, error) {
 	}
 }
 
+// PostgresPoolConfig parses DatabaseURL and applies the pool sizing and schema settings.
+// Binaries that build their own pool should start from it so every entry point sizes
+// the pool the same way.
+func (c *ServiceConfig) PostgresPoolConfig() (*pgxpool.Config, error) {
+	if c.DatabaseURL == "" {
+		return nil, errors.New("database_url is required for postgres")
+	}
+	cfg, err := pgxpool.ParseConfig(c.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DATABASE_URL: %w", err)
+	}
+
+	if c.DBMaxConns > 0 {
+		cfg.MaxConns = c.DBMaxConns
+	}
+	if c.DBMinConns > 0 {
+		cfg.MinConns = c.DBMinConns
+	}
+	if c.DBMaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = c.DBMaxConnLifetime
+	}
+	if c.DBMaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = c.DBMaxConnIdleTime
+	}
+
+	// Optionally set search_path for the connection
+	schema := c.DBSchema
+	cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		if schema == "" {
+			return nil
+		}
+		// set search_path for this session
+		_, err := conn.Exec(ctx, fmt.Sprintf("SET search_path TO %s", schema))
+		return err
+	}
+	return cfg, nil
+}
+
 // PingPostgres verifies connectivity to Postgres and optionally sets search_path for the session.
 // It fails if the schema (when provided) does not exist.
 func PingPostgres(databaseURL, schema string) error {
@@ -297,6 +490,7 @@ func (c *ServiceConfig) buildStorageBackend(config StorageBackendConfig) (simple
 		fsConfig := fsstorage.Config{
 			BaseDir:            getString(config.Config, "base_dir", "./data/storage"),
 			URLPrefix:          getString(config.Config, "url_prefix", ""),
+			KeyPrefix:          getString(config.Config, "key_prefix", ""),
 			SignatureSecretKey: getString(config.Config, "signature_secret_key", ""),
 			PresignExpires:     time.Duration(presignExpires) * time.Second,
 		}
@@ -312,10 +506,18 @@ func (c *ServiceConfig) buildStorageBackend(config StorageBackendConfig) (simple
 			UseSSL:                 getBool(config.Config, "use_ssl", true),
 			UsePathStyle:           getBool(config.Config, "use_path_style", false),
 			PresignDuration:        getInt(config.Config, "presign_duration", 3600),
+			KeyPrefix:              getString(config.Config, "key_prefix", ""),
 			EnableSSE:              getBool(config.Config, "enable_sse", false),
 			SSEAlgorithm:           getString(config.Config, "sse_algorithm", "AES256"),
 			SSEKMSKeyID:            getString(config.Config, "sse_kms_key_id", ""),
+			SSEBucketKeyEnabled:    getBool(config.Config, "sse_bucket_key_enabled", false),
+			RequireSSE:             getBool(config.Config, "require_sse", false),
+			ChecksumAlgorithm:      getString(config.Config, "checksum_algorithm", ""),
 			CreateBucketIfNotExist: getBool(config.Config, "create_bucket_if_not_exist", false),
+
+			ParallelDownloadThreshold:   int64(getInt(config.Config, "parallel_download_threshold", 0)),
+			ParallelDownloadPartSize:    int64(getInt(config.Config, "parallel_download_part_size", 0)),
+			ParallelDownloadConcurrency: getInt(config.Config, "parallel_download_concurrency", 0),
 		}
 		return s3storage.New(s3Config)
 
@@ -196,6 +196,40 @@ func TestEnvServerConfig(t *testing.T) {
 	}
 }
 
+func TestEnvTrustedActorHeader(t *testing.T) {
+	t.Setenv("TRUSTED_ACTOR_HEADER", "X-Actor-ID")
+
+	cfg, err := Load(WithEnv(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TrustedActorHeader != "X-Actor-ID" {
+		t.Errorf("expected trusted actor header 'X-Actor-ID', got %q", cfg.TrustedActorHeader)
+	}
+}
+
+func TestEnvCORSConfig(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com, https://admin.example.com,")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+	cfg, err := Load(WithEnv(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cfg.CORSAllowedOrigins) != 2 || cfg.CORSAllowedOrigins[0] != "https://app.example.com" || cfg.CORSAllowedOrigins[1] != "https://admin.example.com" {
+		t.Errorf("unexpected CORS origins: %v", cfg.CORSAllowedOrigins)
+	}
+	if !cfg.CORSAllowCredentials {
+		t.Error("expected CORS credentials to be allowed")
+	}
+
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "maybe")
+	if _, err := Load(WithEnv("")); err == nil {
+		t.Error("expected error for invalid CORS_ALLOW_CREDENTIALS")
+	}
+}
+
 func TestEnvCompleteConfig(t *testing.T) {
 	// Test a complete configuration from environment
 	t.Setenv("PORT", "8888")
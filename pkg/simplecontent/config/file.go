@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WithFile loads configuration from a YAML (.yaml, .yml) or JSON (.json) file.
+// Keys use the snake_case names from the ServerConfig yaml tags; JSON files use the same keys.
+// Only keys present in the file are applied, and storage_backends replaces the configured
+// backends. Unknown keys are rejected.
+//
+// Options run in order, so put WithEnv after WithFile to let environment variables
+// override the file:
+//
+//	cfg, err := config.Load(config.WithFile("config.yaml"), config.WithEnv(""))
+//
+// Example file:
+//
+//	port: "8080"
+//	default_storage_backend: fs
+//	storage_backends:
+//	  - name: fs
+//	    type: fs
+//	    config:
+//	      base_dir: ./data
+//	  - name: archive
+//	    type: s3
+//	    timeout: 30s
+//	    config:
+//	      bucket: archive
+//	      region: us-east-1
+func WithFile(path string) Option {
+	return func(c *ServerConfig) error {
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".yaml", ".yml", ".json":
+		default:
+			return fmt.Errorf("unsupported config file extension %q (use .yaml, .yml or .json)", ext)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		// JSON is a subset of YAML, so one decoder handles both formats
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+
+		for i := range c.StorageBackends {
+			if c.StorageBackends[i].Config == nil {
+				c.StorageBackends[i].Config = map[string]interface{}{}
+			}
+		}
+		return nil
+	}
+}
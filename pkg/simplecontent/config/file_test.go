@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfigYAML = `
+port: "9090"
+environment: production
+default_storage_backend: fs
+enable_previews: false
+storage_backends:
+  - name: fs
+    type: fs
+    config:
+      base_dir: ./data
+      presign_expires_seconds: 600
+  - name: archive
+    type: memory
+    timeout: 30s
+`
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	return path
+}
+
+func TestWithFile_YAML(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", testConfigYAML)
+
+	cfg, err := Load(WithFile(path))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.Port != "9090" || cfg.Environment != "production" {
+		t.Errorf("unexpected server settings: port=%s environment=%s", cfg.Port, cfg.Environment)
+	}
+	if cfg.EnablePreviews {
+		t.Error("expected previews disabled by file")
+	}
+	if !cfg.EnableEventLogging {
+		t.Error("expected default event logging to be kept when absent from file")
+	}
+	if cfg.DefaultStorageBackend != "fs" {
+		t.Errorf("expected default backend fs, got %s", cfg.DefaultStorageBackend)
+	}
+	if len(cfg.StorageBackends) != 2 {
+		t.Fatalf("expected 2 backends, got %d", len(cfg.StorageBackends))
+	}
+
+	fs := cfg.StorageBackends[0]
+	if fs.Name != "fs" || fs.Type != "fs" || fs.Config["base_dir"] != "./data" || fs.Config["presign_expires_seconds"] != 600 {
+		t.Errorf("unexpected fs backend: %+v", fs)
+	}
+	archive := cfg.StorageBackends[1]
+	if archive.Name != "archive" || archive.Type != "memory" || archive.Timeout != 30*time.Second {
+		t.Errorf("unexpected archive backend: %+v", archive)
+	}
+	if archive.Config == nil {
+		t.Error("expected empty config map for backend without config")
+	}
+}
+
+func TestWithFile_EnvOverridesFile(t *testing.T) {
+	path := writeConfigFile(t, "config.yml", testConfigYAML)
+	t.Setenv("DEFAULT_STORAGE_BACKEND", "archive")
+
+	cfg, err := Load(WithFile(path), WithEnv(""))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.DefaultStorageBackend != "archive" {
+		t.Errorf("expected env to override default backend to archive, got %s", cfg.DefaultStorageBackend)
+	}
+	// Unset env vars keep the file's values
+	if cfg.Port != "9090" || len(cfg.StorageBackends) != 2 {
+		t.Errorf("expected file settings to be kept, got port=%s backends=%d", cfg.Port, len(cfg.StorageBackends))
+	}
+}
+
+func TestWithFile_JSON(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{
+		"database_type": "postgres",
+		"database_url": "postgresql://localhost/test",
+		"default_storage_backend": "mem",
+		"storage_backends": [{"name": "mem", "type": "memory", "timeout": "5s"}]
+	}`)
+
+	cfg, err := Load(WithFile(path))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.DatabaseType != "postgres" || cfg.DatabaseURL != "postgresql://localhost/test" {
+		t.Errorf("unexpected database settings: %s %s", cfg.DatabaseType, cfg.DatabaseURL)
+	}
+	if len(cfg.StorageBackends) != 1 || cfg.StorageBackends[0].Timeout != 5*time.Second {
+		t.Errorf("unexpected backends: %+v", cfg.StorageBackends)
+	}
+}
+
+func TestWithFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"unknown key", "config.yaml", "prot: \"8080\"\n"},
+		{"invalid yaml", "config.yaml", "port: [\n"},
+		{"unsupported extension", "config.toml", "port = \"8080\"\n"},
+		// The merged result is validated: the default backend must be defined
+		{"missing default backend", "config.yaml", "default_storage_backend: s3\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.file, tt.content)
+			if _, err := Load(WithFile(path)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+
+	if _, err := Load(WithFile(filepath.Join(t.TempDir(), "missing.yaml"))); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
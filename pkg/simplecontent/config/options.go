@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // WithPort sets the server port
@@ -49,6 +52,18 @@ func WithDatabaseSchema(schema string) Option {
 	}
 }
 
+// WithDatabasePool sets the Postgres connection pool sizing. A zero value keeps the pgx
+// default or the matching pool_* parameter of the database URL.
+func WithDatabasePool(maxConns, minConns int32, maxConnLifetime, maxConnIdleTime time.Duration) Option {
+	return func(c *ServerConfig) error {
+		c.DBMaxConns = maxConns
+		c.DBMinConns = minConns
+		c.DBMaxConnLifetime = maxConnLifetime
+		c.DBMaxConnIdleTime = maxConnIdleTime
+		return nil
+	}
+}
+
 // WithDefaultStorage sets the default storage backend name
 func WithDefaultStorage(name string) Option {
 	return func(c *ServerConfig) error {
@@ -315,6 +330,22 @@ func WithObjectKeyGenerator(generator string) Option {
 	}
 }
 
+// WithObjectKeySanitization enables or disables sanitizing object keys derived from user filenames
+func WithObjectKeySanitization(enabled bool) Option {
+	return func(c *ServerConfig) error {
+		c.SanitizeObjectKeys = enabled
+		return nil
+	}
+}
+
+// WithKeyCaseNormalization enables or disables lowercasing object keys on write and lookup
+func WithKeyCaseNormalization(enabled bool) Option {
+	return func(c *ServerConfig) error {
+		c.NormalizeKeyCase = enabled
+		return nil
+	}
+}
+
 // WithEventLogging enables or disables event logging
 func WithEventLogging(enabled bool) Option {
 	return func(c *ServerConfig) error {
@@ -339,6 +370,136 @@ func WithAdminAPI(enabled bool) Option {
 	}
 }
 
+// WithObjectAPI enables or disables the object-level endpoints
+func WithObjectAPI(enabled bool) Option {
+	return func(c *ServerConfig) error {
+		c.DisableObjectAPI = !enabled
+		return nil
+	}
+}
+
+// WithCORS sets the origins allowed to make cross-origin requests and whether
+// credentialed requests are allowed
+func WithCORS(allowedOrigins []string, allowCredentials bool) Option {
+	return func(c *ServerConfig) error {
+		c.CORSAllowedOrigins = allowedOrigins
+		c.CORSAllowCredentials = allowCredentials
+		return nil
+	}
+}
+
+// WithTrustedActorHeader records the value of the named request header as the actor in
+// the access audit log. Only use it behind a gateway that authenticates callers and sets
+// the header, since clients can send any value.
+func WithTrustedActorHeader(header string) Option {
+	return func(c *ServerConfig) error {
+		c.TrustedActorHeader = header
+		return nil
+	}
+}
+
+// WithPresigned sets the presigned URL defaults: the signing secret, at least
+// MinPresignedSecretLength bytes, the expiry of URLs signed without one and the upload
+// URL pattern. Zero values keep the current settings.
+func WithPresigned(secretKey string, defaultExpiry time.Duration, urlPattern string) Option {
+	return func(c *ServerConfig) error {
+		if secretKey != "" {
+			c.Presigned.SecretKey = secretKey
+		}
+		if defaultExpiry != 0 {
+			c.Presigned.DefaultExpiry = defaultExpiry
+		}
+		if urlPattern != "" {
+			c.Presigned.URLPattern = urlPattern
+		}
+		return c.Presigned.Validate()
+	}
+}
+
+// WithStorageTimeout bounds each operation on an already configured storage backend.
+// Operations that exceed the timeout fail with simplecontent.ErrStorageTimeout.
+func WithStorageTimeout(name string, timeout time.Duration) Option {
+	return func(c *ServerConfig) error {
+		if timeout <= 0 {
+			return fmt.Errorf("storage timeout must be positive, got: %s", timeout)
+		}
+		for i := range c.StorageBackends {
+			if c.StorageBackends[i].Name == name {
+				c.StorageBackends[i].Timeout = timeout
+				return nil
+			}
+		}
+		return fmt.Errorf("storage backend %q not configured", name)
+	}
+}
+
+// WithTenantStorage stores a tenant's new objects on an already configured storage backend,
+// e.g. a bucket of its own. Other tenants keep using the default backend.
+func WithTenantStorage(tenantID, name string) Option {
+	return func(c *ServerConfig) error {
+		if _, err := uuid.Parse(tenantID); err != nil {
+			return fmt.Errorf("invalid tenant id %q: %w", tenantID, err)
+		}
+		for i := range c.StorageBackends {
+			if c.StorageBackends[i].Name == name {
+				if c.TenantBackends == nil {
+					c.TenantBackends = make(map[string]string)
+				}
+				c.TenantBackends[tenantID] = name
+				return nil
+			}
+		}
+		return fmt.Errorf("storage backend %q not configured", name)
+	}
+}
+
+// WithDefaultTenant sets the tenant used when a request names none, for single-tenant
+// deployments
+func WithDefaultTenant(tenantID string) Option {
+	return func(c *ServerConfig) error {
+		if _, err := uuid.Parse(tenantID); err != nil {
+			return fmt.Errorf("invalid default tenant id %q: %w", tenantID, err)
+		}
+		c.DefaultTenantID = tenantID
+		return nil
+	}
+}
+
+// WithDefaultOwner sets the owner used when a request names none
+func WithDefaultOwner(ownerID string) Option {
+	return func(c *ServerConfig) error {
+		if _, err := uuid.Parse(ownerID); err != nil {
+			return fmt.Errorf("invalid default owner id %q: %w", ownerID, err)
+		}
+		c.DefaultOwnerID = ownerID
+		return nil
+	}
+}
+
+// WithStorageMigration makes the named backend read through to the backend it replaces:
+// objects missing on name are copied from the old backend on first read. Both backends must
+// already be configured.
+func WithStorageMigration(name, from string) Option {
+	return func(c *ServerConfig) error {
+		found := false
+		for i := range c.StorageBackends {
+			if c.StorageBackends[i].Name == from {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("storage backend %q not configured", from)
+		}
+		for i := range c.StorageBackends {
+			if c.StorageBackends[i].Name == name {
+				c.StorageBackends[i].MigrateFrom = from
+				return nil
+			}
+		}
+		return fmt.Errorf("storage backend %q not configured", name)
+	}
+}
+
 // WithDefaults is a convenience option that applies sensible defaults
 // This is useful as a base before applying more specific options
 func WithDefaults() Option {
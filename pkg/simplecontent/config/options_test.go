@@ -1,7 +1,11 @@
 package config
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/tendant/simple-content/pkg/simplecontent"
 )
 
 func TestWithPort(t *testing.T) {
@@ -302,6 +306,161 @@ func TestWithAdminAPI(t *testing.T) {
 	}
 }
 
+func TestWithObjectAPI(t *testing.T) {
+	cfg, err := Load(WithObjectAPI(false))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !cfg.DisableObjectAPI {
+		t.Error("expected object API to be disabled")
+	}
+}
+
+func TestWithCORS(t *testing.T) {
+	cfg, err := Load(WithCORS([]string{"https://app.example.com"}, true))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.CORSAllowedOrigins) != 1 || cfg.CORSAllowedOrigins[0] != "https://app.example.com" {
+		t.Errorf("unexpected CORS origins: %v", cfg.CORSAllowedOrigins)
+	}
+	if !cfg.CORSAllowCredentials {
+		t.Error("expected CORS credentials to be allowed")
+	}
+}
+
+func TestWithTrustedActorHeader(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.TrustedActorHeader != "" {
+		t.Errorf("expected no trusted actor header by default, got %q", cfg.TrustedActorHeader)
+	}
+
+	cfg, err = Load(WithTrustedActorHeader("X-Actor-ID"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.TrustedActorHeader != "X-Actor-ID" {
+		t.Errorf("expected trusted actor header 'X-Actor-ID', got %q", cfg.TrustedActorHeader)
+	}
+}
+
+func TestWithStorageTimeout(t *testing.T) {
+	cfg, err := Load(
+		WithMemoryStorage("slow"),
+		WithStorageTimeout("slow", 5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var found bool
+	for _, backend := range cfg.StorageBackends {
+		if backend.Name == "slow" {
+			found = true
+			if backend.Timeout != 5*time.Second {
+				t.Errorf("expected timeout 5s, got %s", backend.Timeout)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("expected slow backend to be configured")
+	}
+
+	if _, err := cfg.BuildService(); err != nil {
+		t.Fatalf("expected service to build, got: %v", err)
+	}
+
+	if _, err := Load(WithStorageTimeout("missing", time.Second)); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+	if _, err := Load(WithMemoryStorage("slow"), WithStorageTimeout("slow", 0)); err == nil {
+		t.Error("expected error for non-positive timeout")
+	}
+}
+
+func TestWithTenantStorage(t *testing.T) {
+	tenantID := "11111111-1111-1111-1111-111111111111"
+	cfg, err := Load(
+		WithMemoryStorage("tenant-bucket"),
+		WithTenantStorage(tenantID, "tenant-bucket"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.TenantBackends[tenantID] != "tenant-bucket" {
+		t.Errorf("expected tenant mapped to tenant-bucket, got %v", cfg.TenantBackends)
+	}
+	if _, err := cfg.BuildService(); err != nil {
+		t.Fatalf("expected service to build, got: %v", err)
+	}
+
+	if _, err := Load(WithTenantStorage(tenantID, "missing")); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+	if _, err := Load(WithMemoryStorage("tenant-bucket"), WithTenantStorage("acme", "tenant-bucket")); err == nil {
+		t.Error("expected error for invalid tenant id")
+	}
+}
+
+func TestWithDefaultTenantAndOwner(t *testing.T) {
+	tenantID := "11111111-1111-1111-1111-111111111111"
+	ownerID := "22222222-2222-2222-2222-222222222222"
+	cfg, err := Load(WithDefaultTenant(tenantID), WithDefaultOwner(ownerID))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	svc, err := cfg.BuildService()
+	if err != nil {
+		t.Fatalf("expected service to build, got: %v", err)
+	}
+	content, err := svc.CreateContent(context.Background(), simplecontent.CreateContentRequest{Name: "notes"})
+	if err != nil {
+		t.Fatalf("expected content to be created, got: %v", err)
+	}
+	if content.TenantID.String() != tenantID || content.OwnerID.String() != ownerID {
+		t.Errorf("expected default tenant and owner, got %s and %s", content.TenantID, content.OwnerID)
+	}
+
+	if _, err := Load(WithDefaultTenant("acme")); err == nil {
+		t.Error("expected error for invalid tenant id")
+	}
+	if _, err := Load(WithDefaultOwner("")); err == nil {
+		t.Error("expected error for invalid owner id")
+	}
+}
+
+func TestWithStorageMigration(t *testing.T) {
+	cfg, err := Load(
+		WithMemoryStorage("old"),
+		WithMemoryStorage("new"),
+		WithDefaultStorage("new"),
+		WithStorageMigration("new", "old"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	svc, err := cfg.BuildService()
+	if err != nil {
+		t.Fatalf("expected service to build, got: %v", err)
+	}
+	backend, err := svc.GetBackend("new")
+	if err != nil {
+		t.Fatalf("expected backend, got: %v", err)
+	}
+	if _, ok := backend.(*simplecontent.MigratingBlobStore); !ok {
+		t.Errorf("expected a migrating store, got %T", backend)
+	}
+
+	if _, err := Load(WithMemoryStorage("new"), WithStorageMigration("new", "missing")); err == nil {
+		t.Error("expected error for unknown source backend")
+	}
+	if _, err := Load(WithMemoryStorage("new"), WithStorageMigration("new", "new")); err == nil {
+		t.Error("expected error for a backend migrating from itself")
+	}
+}
+
 func TestComposedOptions(t *testing.T) {
 	// Test composing multiple options together
 	cfg, err := Load(
@@ -348,6 +507,9 @@ func TestComposedOptions(t *testing.T) {
 	if cfg.EnableAdminAPI {
 		t.Error("expected admin API to be disabled")
 	}
+	if cfg.DisableObjectAPI {
+		t.Error("expected object API to be enabled")
+	}
 }
 
 func TestOptionsOverrideDefaults(t *testing.T) {
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tendant/simple-content/pkg/simplecontent/presigned"
+)
+
+// MinPresignedSecretLength is the shortest accepted Presigned.SecretKey, in bytes
+const MinPresignedSecretLength = 32
+
+// PresignedConfig holds the defaults of the server's presigned URLs. Filesystem backends
+// without their own signature_secret_key or presign_expires_seconds use them, and the
+// mounted presigned handlers validate requests with the signer built from them.
+type PresignedConfig struct {
+	SecretKey     string        `yaml:"secret_key"`     // HMAC key signing the URLs; empty leaves URLs unsigned
+	DefaultExpiry time.Duration `yaml:"default_expiry"` // Lifetime of URLs signed without one (default: 1h)
+	URLPattern    string        `yaml:"url_pattern"`    // Upload path with a {key} placeholder (default: /api/v1/upload/{key})
+}
+
+// Validate checks the secret length, the expiry and the URL pattern
+func (c PresignedConfig) Validate() error {
+	if c.SecretKey != "" && len(c.SecretKey) < MinPresignedSecretLength {
+		return fmt.Errorf("presigned secret_key must be at least %d bytes, got %d", MinPresignedSecretLength, len(c.SecretKey))
+	}
+	if c.DefaultExpiry < 0 {
+		return fmt.Errorf("presigned default_expiry must not be negative, got %s", c.DefaultExpiry)
+	}
+	if c.URLPattern != "" && !strings.Contains(c.URLPattern, "{key}") {
+		return fmt.Errorf("presigned url_pattern %q must contain {key}", c.URLPattern)
+	}
+	return nil
+}
+
+// NewSigner builds the signer for the configured secret, expiry and URL pattern. Without
+// a secret the signer is disabled and accepts unsigned requests.
+func (c PresignedConfig) NewSigner() (*presigned.Signer, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	opts := []presigned.Option{presigned.WithSecretKey(c.SecretKey)}
+	if c.DefaultExpiry > 0 {
+		opts = append(opts, presigned.WithDefaultExpiration(c.DefaultExpiry))
+	}
+	if c.URLPattern != "" {
+		opts = append(opts, presigned.WithURLPattern(c.URLPattern))
+	}
+	return presigned.New(opts...), nil
+}
+
+// applyPresignedDefaults gives filesystem backends the presigned secret and expiry unless
+// they set their own
+func (c *ServerConfig) applyPresignedDefaults() {
+	for i, backend := range c.StorageBackends {
+		if backend.Type != "fs" {
+			continue
+		}
+		if backend.Config == nil {
+			backend.Config = map[string]interface{}{}
+		}
+		if _, ok := backend.Config["signature_secret_key"]; !ok && c.Presigned.SecretKey != "" {
+			backend.Config["signature_secret_key"] = c.Presigned.SecretKey
+		}
+		if _, ok := backend.Config["presign_expires_seconds"]; !ok && c.Presigned.DefaultExpiry > 0 {
+			backend.Config["presign_expires_seconds"] = int(c.Presigned.DefaultExpiry / time.Second)
+		}
+		c.StorageBackends[i] = backend
+	}
+}
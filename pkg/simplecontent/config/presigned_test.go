@@ -0,0 +1,111 @@
+package config
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPresignedSecret = "0123456789abcdef0123456789abcdef"
+
+func TestPresignedSigner(t *testing.T) {
+	cfg, err := Load(
+		WithFilesystemStorage("fs", "./data/storage", "", ""),
+		WithPresigned(testPresignedSecret, 15*time.Minute, "/files/{key}"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	signer, err := cfg.Presigned.NewSigner()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !signer.IsEnabled() {
+		t.Fatal("expected signer with a secret to be enabled")
+	}
+
+	before := time.Now()
+	signed, err := signer.SignURL("PUT", "/files/report.pdf", 0)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("expected a valid URL, got %q: %v", signed, err)
+	}
+	expires, err := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatalf("expected an expires parameter, got %q", signed)
+	}
+	if want := before.Add(15 * time.Minute).Unix(); expires < want || expires > want+1 {
+		t.Errorf("expected URL to expire at %d, got: %d", want, expires)
+	}
+	if key, err := signer.ExtractObjectKey(u.Path); err != nil || key != "report.pdf" {
+		t.Errorf("expected key report.pdf from the configured pattern, got: %q (%v)", key, err)
+	}
+
+	var backend StorageBackendConfig
+	for _, b := range cfg.StorageBackends {
+		if b.Name == "fs" {
+			backend = b
+		}
+	}
+	if backend.Config["signature_secret_key"] != testPresignedSecret {
+		t.Errorf("expected fs backend to inherit the presigned secret, got: %v", backend.Config["signature_secret_key"])
+	}
+	if backend.Config["presign_expires_seconds"] != 900 {
+		t.Errorf("expected fs backend to inherit the presigned expiry, got: %v", backend.Config["presign_expires_seconds"])
+	}
+}
+
+func TestPresignedShortSecret(t *testing.T) {
+	_, err := Load(WithPresigned("too-short", 0, ""))
+	if err == nil || !strings.Contains(err.Error(), "at least 32 bytes") {
+		t.Fatalf("expected short secret error, got: %v", err)
+	}
+
+	cfg := PresignedConfig{SecretKey: testPresignedSecret[:31]}
+	if _, err := cfg.NewSigner(); err == nil {
+		t.Error("expected NewSigner to reject a 31 byte secret, got nil")
+	}
+}
+
+func TestPresignedDisabledWithoutSecret(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	signer, err := cfg.Presigned.NewSigner()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if signer.IsEnabled() {
+		t.Error("expected signer without a secret to be disabled")
+	}
+	if cfg.Presigned.DefaultExpiry != time.Hour {
+		t.Errorf("expected default expiry 1h, got: %s", cfg.Presigned.DefaultExpiry)
+	}
+}
+
+func TestEnvPresigned(t *testing.T) {
+	t.Setenv("PRESIGNED_SECRET_KEY", testPresignedSecret)
+	t.Setenv("PRESIGNED_DEFAULT_EXPIRY", "30m")
+	t.Setenv("PRESIGNED_URL_PATTERN", "/blobs/{key}")
+
+	cfg, err := Load(WithEnv(""))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := PresignedConfig{SecretKey: testPresignedSecret, DefaultExpiry: 30 * time.Minute, URLPattern: "/blobs/{key}"}
+	if cfg.Presigned != want {
+		t.Errorf("expected %+v, got: %+v", want, cfg.Presigned)
+	}
+
+	t.Setenv("PRESIGNED_SECRET_KEY", "short")
+	if _, err := Load(WithEnv("")); err == nil {
+		t.Error("expected error for short secret, got nil")
+	}
+}
@@ -0,0 +1,172 @@
+package simplecontent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/tendant/simple-content/pkg/simplecontent/objectkey"
+)
+
+// contentAddressing returns the key generator when it derives keys from the stored data,
+// as objectkey.ContentAddressedKeyGenerator does
+func (s *service) contentAddressing() (objectkey.ContentAddressed, bool) {
+	generator, ok := s.keyGenerator.(objectkey.ContentAddressed)
+	return generator, ok
+}
+
+// validateContentAddressing checks that a content-addressed key generator has the upload
+// hashes it needs. Keys come from the content ETag, so WithContentETags is required; a key
+// template would replace the generator, and mirror copies would keep the staging keys.
+func (s *service) validateContentAddressing() error {
+	if _, ok := s.contentAddressing(); !ok {
+		return nil
+	}
+	switch {
+	case !s.contentETags:
+		return fmt.Errorf("content-addressed object keys require WithContentETags")
+	case s.keyTemplateText != "":
+		return fmt.Errorf("content-addressed object keys cannot be combined with WithKeyTemplate")
+	case s.mirrorBackend != "":
+		return fmt.Errorf("content-addressed object keys cannot be combined with WithMirrorBackend")
+	}
+	return nil
+}
+
+// stageUpload points an object that already holds data back at its staging key, so that
+// replacing its data cannot overwrite a blob other objects share. It returns the key the
+// object held, to be released once the new data has its own key, or "" when the object is
+// not stored by content.
+func (s *service) stageUpload(object *Object) string {
+	generator, ok := s.contentAddressing()
+	if !ok {
+		return ""
+	}
+	staging := s.sanitizeObjectKey(generator.GenerateKey(object.ContentID, object.ID, nil))
+	if object.ObjectKey == staging {
+		return ""
+	}
+	previousKey := object.ObjectKey
+	object.ObjectKey = staging
+	return previousKey
+}
+
+// addressByContent moves an uploaded object from its staging key to the key of its content
+// ETag. When a blob with the same data is stored already the staged copy is dropped and the
+// object shares the existing blob. previousKey, set when the upload replaced earlier data, is
+// deleted unless other objects still reference it. Failures are logged and leave the object
+// readable at its current key.
+func (s *service) addressByContent(ctx context.Context, object *Object, previousKey string) {
+	generator, ok := s.contentAddressing()
+	if !ok {
+		return
+	}
+	objectMetadata, err := s.repository.GetObjectMetadata(ctx, object.ID)
+	if err != nil || objectMetadata == nil {
+		slog.Warn("Failed to load object metadata for content-addressed key", "object_id", object.ID, "error", err)
+		return
+	}
+	etag, _ := objectMetadata.Metadata[MetaContentETag].(string)
+	if etag == "" {
+		slog.Warn("No content etag, object left at staging key", "object_id", object.ID, "key", object.ObjectKey)
+		return
+	}
+	stored, err := s.repository.GetObject(ctx, object.ID)
+	if err != nil {
+		slog.Warn("Failed to move object to content-addressed key", "object_id", object.ID, "error", err)
+		return
+	}
+	backend, err := s.GetBackend(stored.StorageBackendName)
+	if err != nil {
+		slog.Warn("Failed to move object to content-addressed key", "object_id", object.ID, "error", err)
+		return
+	}
+
+	contentKey := s.sanitizeObjectKey(generator.ContentKey(strings.Trim(etag, `"`)))
+	if contentKey != stored.ObjectKey {
+		if err := s.moveToContentKey(ctx, backend, stored, contentKey); err != nil {
+			slog.Warn("Failed to move object to content-addressed key", "object_id", object.ID, "key", contentKey, "error", err)
+			return
+		}
+		object.ObjectKey = contentKey
+	}
+
+	if previousKey != "" && previousKey != contentKey {
+		released := *stored
+		released.ObjectKey = previousKey
+		s.deleteObjectBlob(ctx, &released)
+	}
+}
+
+// moveToContentKey points stored at contentKey while holding the blob lock, so a concurrent
+// delete of the last other reference cannot remove the blob in between. The staged data is
+// moved to contentKey or, when the same data is stored already, dropped. If the record cannot
+// be updated a moved blob is moved back and stored keeps its staging key.
+func (s *service) moveToContentKey(ctx context.Context, backend BlobStore, stored *Object, contentKey string) error {
+	unlock, err := s.lockBlob(ctx, stored.StorageBackendName, contentKey)
+	if err != nil {
+		return fmt.Errorf("failed to lock blob: %w", err)
+	}
+	defer unlock()
+
+	stagingKey := stored.ObjectKey
+	duplicate, err := blobExists(ctx, backend, contentKey)
+	if err != nil {
+		return err
+	}
+	if !duplicate {
+		if err := blobMove(ctx, backend, stagingKey, contentKey); err != nil {
+			return err
+		}
+	}
+	stored.ObjectKey = contentKey
+	if err := s.repository.UpdateObject(ctx, stored); err != nil {
+		stored.ObjectKey = stagingKey
+		if !duplicate {
+			if undoErr := blobMove(context.WithoutCancel(ctx), backend, contentKey, stagingKey); undoErr != nil {
+				slog.Warn("Failed to move object data back after record update failed", "object_id", stored.ID, "key", contentKey, "error", undoErr)
+			}
+		}
+		return fmt.Errorf("failed to record content-addressed key: %w", err)
+	}
+	if duplicate {
+		// The same data is stored already; the staged copy is a duplicate
+		if err := backend.Delete(ctx, stagingKey); err != nil {
+			slog.Warn("Failed to delete duplicate staged upload", "object_id", stored.ID, "key", stagingKey, "error", err)
+		}
+	}
+	return nil
+}
+
+// lockBlob serializes changes to the references of a blob: pointing an object at it and
+// deleting it once unreferenced. Blobs are only shared under content addressing, so nothing
+// is locked otherwise.
+func (s *service) lockBlob(ctx context.Context, backendName, key string) (func(), error) {
+	if _, ok := s.contentAddressing(); !ok {
+		return func() {}, nil
+	}
+	return s.repository.AcquireLock(ctx, "blob:"+backendName+":"+key)
+}
+
+// blobShared reports whether an object other than object still references its blob. Only
+// content-addressed keys are shared; a failed lookup counts as shared so data is never lost.
+func (s *service) blobShared(ctx context.Context, object *Object) bool {
+	if _, ok := s.contentAddressing(); !ok {
+		return false
+	}
+	others, err := s.repository.ListObjects(ctx, ObjectListFilters{
+		StorageBackendName: &object.StorageBackendName,
+		ObjectKey:          &object.ObjectKey,
+	})
+	if err != nil {
+		slog.Warn("Failed to check blob references, blob kept", "object_id", object.ID, "key", object.ObjectKey, "error", err)
+		return true
+	}
+	for _, other := range others {
+		if other.ID != object.ID {
+			return true
+		}
+	}
+	return false
+}
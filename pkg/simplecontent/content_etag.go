@@ -0,0 +1,165 @@
+package simplecontent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// MetaContentETag is the object metadata key holding the strong ETag computed by the
+// service when WithContentETags is enabled
+const MetaContentETag = "content_etag"
+
+// WithContentETags makes the service hash every upload with SHA-256 and store the result as
+// a quoted strong ETag under MetaContentETag in the object metadata. Unlike backend ETags,
+// which for S3 multipart uploads are not content hashes and which fs and memory storage do
+// not report at all, the same data gets the same ETag on every backend.
+func WithContentETags() Option {
+	return func(s *service) {
+		s.contentETags = true
+	}
+}
+
+// ContentETag returns the strong ETag the service stores for data with the given SHA-256
+func ContentETag(sum []byte) string {
+	return `"` + hex.EncodeToString(sum) + `"`
+}
+
+// etagHasher hashes the bytes of an upload source as a backend reads them. Backends that
+// rewind a seekable source and read parts of it again only extend the hash with bytes past
+// what it has already seen, so retries and re-reads do not corrupt the result.
+type etagHasher struct {
+	reader io.Reader
+	hash   hash.Hash
+	pos    int64 // Offset of the next byte read from reader
+	hashed int64 // Bytes hashed so far, always a prefix of the source
+	gap    bool  // A read skipped ahead of the hashed prefix
+}
+
+func (h *etagHasher) Read(p []byte) (int, error) {
+	n, err := h.reader.Read(p)
+	if n > 0 {
+		end := h.pos + int64(n)
+		switch {
+		case h.pos > h.hashed:
+			h.gap = true
+		case end > h.hashed:
+			h.hash.Write(p[h.hashed-h.pos : n])
+			h.hashed = end
+		}
+		h.pos = end
+	}
+	return n, err
+}
+
+// etag returns the ETag of the source, or "" when the hashed bytes do not cover an object of
+// the given size. A size below zero is not checked.
+func (h *etagHasher) etag(size int64) string {
+	if h.gap || (size >= 0 && h.hashed != size) {
+		return ""
+	}
+	return ContentETag(h.hash.Sum(nil))
+}
+
+// etagReadSeeker keeps a seekable upload source seekable while it is hashed
+type etagReadSeeker struct {
+	*etagHasher
+	seeker io.Seeker
+}
+
+func (h *etagReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	pos, err := h.seeker.Seek(offset, whence)
+	if err == nil {
+		h.pos = pos
+	}
+	return pos, err
+}
+
+// hashUpload wraps an upload source so its content ETag can be stored once the upload is done.
+// It returns the reader unchanged and a nil hasher when content ETags are disabled.
+func (s *service) hashUpload(reader io.Reader) (io.Reader, *etagHasher) {
+	if !s.contentETags {
+		return reader, nil
+	}
+	hasher := &etagHasher{reader: reader, hash: sha256.New()}
+	if seeker, ok := reader.(io.Seeker); ok {
+		return &etagReadSeeker{etagHasher: hasher, seeker: seeker}, hasher
+	}
+	return hasher, hasher
+}
+
+// storeContentETag records the ETag of an uploaded source in the object metadata. It runs
+// after the metadata has been synced from storage, so the stored size is the object's size.
+func (s *service) storeContentETag(ctx context.Context, objectID uuid.UUID, hasher *etagHasher) {
+	if hasher == nil {
+		return
+	}
+	objectMetadata, err := s.repository.GetObjectMetadata(ctx, objectID)
+	if err != nil || objectMetadata == nil {
+		slog.Warn("Failed to load object metadata for content etag", "object_id", objectID, "error", err)
+		return
+	}
+	etag := hasher.etag(objectMetadata.SizeBytes)
+	if etag == "" {
+		slog.Warn("Upload was not read in full, content etag not stored", "object_id", objectID)
+		return
+	}
+	s.setContentETag(ctx, objectMetadata, etag)
+}
+
+// computeContentETag hashes an object's stored data and records its content ETag. It is used
+// for presigned uploads, whose data never passes through the service.
+func (s *service) computeContentETag(ctx context.Context, object *Object, backend BlobStore) {
+	if !s.contentETags {
+		return
+	}
+	objectMetadata, err := s.repository.GetObjectMetadata(ctx, object.ID)
+	if err != nil || objectMetadata == nil {
+		slog.Warn("Failed to load object metadata for content etag", "object_id", object.ID, "error", err)
+		return
+	}
+	rc, err := backend.Download(ctx, object.ObjectKey)
+	if err != nil {
+		slog.Warn("Failed to read object for content etag", "object_id", object.ID, "error", err)
+		return
+	}
+	defer rc.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		slog.Warn("Failed to read object for content etag", "object_id", object.ID, "error", err)
+		return
+	}
+	s.setContentETag(ctx, objectMetadata, ContentETag(h.Sum(nil)))
+}
+
+func (s *service) setContentETag(ctx context.Context, objectMetadata *ObjectMetadata, etag string) {
+	if objectMetadata.Metadata == nil {
+		objectMetadata.Metadata = make(map[string]interface{})
+	}
+	objectMetadata.Metadata[MetaContentETag] = etag
+	if err := s.repository.SetObjectMetadata(ctx, objectMetadata); err != nil {
+		slog.Warn("Failed to store content etag", "object_id", objectMetadata.ObjectID, "error", err)
+	}
+}
+
+// preserveContentETag copies the stored content ETag onto metadata about to replace it, as
+// long as storage still reports the size and ETag the object had when it was hashed
+func (s *service) preserveContentETag(ctx context.Context, objectID uuid.UUID, objectMeta *ObjectMeta, metadata map[string]interface{}) {
+	if !s.contentETags {
+		return
+	}
+	existing, err := s.repository.GetObjectMetadata(ctx, objectID)
+	if err != nil || existing == nil {
+		return
+	}
+	etag, ok := existing.Metadata[MetaContentETag].(string)
+	if !ok || existing.SizeBytes != objectMeta.Size || existing.ETag != objectMeta.ETag {
+		return
+	}
+	metadata[MetaContentETag] = etag
+}
@@ -0,0 +1,332 @@
+package simplecontent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FilterOp is the comparison a ContentFilter condition applies to its field
+type FilterOp string
+
+const (
+	FilterEq  FilterOp = "eq"  // Field equals the single value
+	FilterNe  FilterOp = "ne"  // Field differs from the single value
+	FilterIn  FilterOp = "in"  // Field equals one of the values
+	FilterGt  FilterOp = "gt"  // Field is greater than the single value
+	FilterGte FilterOp = "gte" // Field is greater than or equal to the single value
+	FilterLt  FilterOp = "lt"  // Field is less than the single value
+	FilterLte FilterOp = "lte" // Field is less than or equal to the single value
+)
+
+// filterFieldKind is the Go type the values of a filterable column are compared as
+type filterFieldKind int
+
+const (
+	filterString filterFieldKind = iota
+	filterUUID
+	filterTime
+)
+
+// contentFilterFields are the content columns a ContentFilter can compare
+var contentFilterFields = map[string]filterFieldKind{
+	"id":              filterUUID,
+	"tenant_id":       filterUUID,
+	"owner_id":        filterUUID,
+	"owner_type":      filterString,
+	"name":            filterString,
+	"description":     filterString,
+	"document_type":   filterString,
+	"status":          filterString,
+	"derivation_type": filterString,
+	"created_at":      filterTime,
+	"updated_at":      filterTime,
+}
+
+// ContentFilter is a condition on the first-class columns of content, such as status or
+// document_type. A condition compares Field with Op against Values; a group matches when
+// all of All, or any of Any, match. Build filters with Where, AllOf and AnyOf:
+//
+//	Where("document_type").In("image/png", "image/jpeg").And("status").Eq("uploaded")
+//
+// Values of uuid columns may be uuid.UUIDs or strings, values of time columns time.Times
+// or RFC 3339 strings, so filters decoded from JSON work unchanged.
+type ContentFilter struct {
+	Field  string           `json:"field,omitempty"`
+	Op     FilterOp         `json:"op,omitempty"`
+	Values []interface{}    `json:"values,omitempty"`
+	All    []*ContentFilter `json:"all,omitempty"`
+	Any    []*ContentFilter `json:"any,omitempty"`
+}
+
+// FilterCondition is a field of a filter being built, waiting for its comparison
+type FilterCondition struct {
+	field   string
+	combine func(*ContentFilter) *ContentFilter
+}
+
+// Where starts a filter on the named content column
+func Where(field string) *FilterCondition {
+	return &FilterCondition{field: field, combine: func(f *ContentFilter) *ContentFilter { return f }}
+}
+
+// And continues the filter with a condition that must hold as well
+func (f *ContentFilter) And(field string) *FilterCondition {
+	return &FilterCondition{field: field, combine: func(next *ContentFilter) *ContentFilter { return AllOf(f, next) }}
+}
+
+// Or continues the filter with a condition that may hold instead. Conditions chain left to
+// right, so Where(a).Eq(1).Or(b).Eq(2).And(c).Eq(3) is (a OR b) AND c; use AnyOf and AllOf
+// for other groupings.
+func (f *ContentFilter) Or(field string) *FilterCondition {
+	return &FilterCondition{field: field, combine: func(next *ContentFilter) *ContentFilter { return AnyOf(f, next) }}
+}
+
+func (c *FilterCondition) compare(op FilterOp, values ...interface{}) *ContentFilter {
+	return c.combine(&ContentFilter{Field: c.field, Op: op, Values: values})
+}
+
+// Eq matches content whose field equals value
+func (c *FilterCondition) Eq(value interface{}) *ContentFilter { return c.compare(FilterEq, value) }
+
+// Ne matches content whose field differs from value
+func (c *FilterCondition) Ne(value interface{}) *ContentFilter { return c.compare(FilterNe, value) }
+
+// In matches content whose field equals one of values
+func (c *FilterCondition) In(values ...interface{}) *ContentFilter {
+	return c.compare(FilterIn, values...)
+}
+
+// Gt matches content whose field is greater than value, e.g. created after a time
+func (c *FilterCondition) Gt(value interface{}) *ContentFilter { return c.compare(FilterGt, value) }
+
+// Gte matches content whose field is greater than or equal to value
+func (c *FilterCondition) Gte(value interface{}) *ContentFilter { return c.compare(FilterGte, value) }
+
+// Lt matches content whose field is less than value
+func (c *FilterCondition) Lt(value interface{}) *ContentFilter { return c.compare(FilterLt, value) }
+
+// Lte matches content whose field is less than or equal to value
+func (c *FilterCondition) Lte(value interface{}) *ContentFilter { return c.compare(FilterLte, value) }
+
+// AllOf matches content that every filter matches. Nil filters are skipped.
+func AllOf(filters ...*ContentFilter) *ContentFilter {
+	group := &ContentFilter{}
+	for _, f := range filters {
+		switch {
+		case f == nil:
+		case f.Field == "" && len(f.Any) == 0:
+			group.All = append(group.All, f.All...)
+		default:
+			group.All = append(group.All, f)
+		}
+	}
+	return group
+}
+
+// AnyOf matches content that at least one of the filters matches. Nil filters are skipped.
+func AnyOf(filters ...*ContentFilter) *ContentFilter {
+	group := &ContentFilter{}
+	for _, f := range filters {
+		switch {
+		case f == nil:
+		case f.Field == "" && len(f.All) == 0 && len(f.Any) > 0:
+			group.Any = append(group.Any, f.Any...)
+		default:
+			group.Any = append(group.Any, f)
+		}
+	}
+	return group
+}
+
+// Normalized returns a copy of the filter with every value converted to the type of its
+// column, for repositories to evaluate. An unknown field, operator or unusable value is
+// reported as a FieldError.
+func (f *ContentFilter) Normalized() (*ContentFilter, error) {
+	if f == nil {
+		return nil, nil
+	}
+	if f.Field == "" {
+		if f.Op != "" || len(f.Values) > 0 {
+			return nil, &FieldError{Field: "filter", Reason: "condition without a field"}
+		}
+		normalized := &ContentFilter{}
+		for _, member := range f.All {
+			m, err := member.Normalized()
+			if err != nil {
+				return nil, err
+			}
+			if m != nil {
+				normalized.All = append(normalized.All, m)
+			}
+		}
+		for _, member := range f.Any {
+			m, err := member.Normalized()
+			if err != nil {
+				return nil, err
+			}
+			if m != nil {
+				normalized.Any = append(normalized.Any, m)
+			}
+		}
+		return normalized, nil
+	}
+
+	if len(f.All) > 0 || len(f.Any) > 0 {
+		return nil, &FieldError{Field: f.Field, Reason: "filter condition cannot also be a group"}
+	}
+	kind, ok := contentFilterFields[f.Field]
+	if !ok {
+		return nil, &FieldError{Field: f.Field, Reason: "not a filterable content field"}
+	}
+	switch f.Op {
+	case FilterIn:
+		if len(f.Values) == 0 {
+			return nil, &FieldError{Field: f.Field, Reason: "in needs at least one value"}
+		}
+	case FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte:
+		if len(f.Values) != 1 {
+			return nil, &FieldError{Field: f.Field, Reason: fmt.Sprintf("%s needs exactly one value", f.Op)}
+		}
+	default:
+		return nil, &FieldError{Field: f.Field, Reason: fmt.Sprintf("unknown filter operator %q", f.Op)}
+	}
+	normalized := &ContentFilter{Field: f.Field, Op: f.Op, Values: make([]interface{}, len(f.Values))}
+	for i, value := range f.Values {
+		v, err := normalizeFilterValue(kind, value)
+		if err != nil {
+			return nil, &FieldError{Field: f.Field, Reason: err.Error()}
+		}
+		normalized.Values[i] = v
+	}
+	return normalized, nil
+}
+
+func normalizeFilterValue(kind filterFieldKind, value interface{}) (interface{}, error) {
+	switch kind {
+	case filterUUID:
+		switch v := value.(type) {
+		case uuid.UUID:
+			return v, nil
+		case string:
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid uuid %q", v)
+			}
+			return id, nil
+		}
+	case filterTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time %q", v)
+			}
+			return t, nil
+		}
+	default:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+		if v, ok := value.(fmt.Stringer); ok {
+			return v.String(), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported value %v (%T)", value, value)
+}
+
+// Matches reports whether content satisfies a filter returned by Normalized. A group without
+// members matches all content.
+func (f *ContentFilter) Matches(content *Content) bool {
+	if f == nil {
+		return true
+	}
+	if f.Field == "" {
+		for _, member := range f.All {
+			if !member.Matches(content) {
+				return false
+			}
+		}
+		if len(f.Any) == 0 {
+			return true
+		}
+		for _, member := range f.Any {
+			if member.Matches(content) {
+				return true
+			}
+		}
+		return false
+	}
+
+	actual := contentFieldValue(content, f.Field)
+	if f.Op == FilterIn {
+		for _, value := range f.Values {
+			if compareFilterValues(actual, value) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	cmp := compareFilterValues(actual, f.Values[0])
+	switch f.Op {
+	case FilterEq:
+		return cmp == 0
+	case FilterNe:
+		return cmp != 0
+	case FilterGt:
+		return cmp > 0
+	case FilterGte:
+		return cmp >= 0
+	case FilterLt:
+		return cmp < 0
+	case FilterLte:
+		return cmp <= 0
+	}
+	return false
+}
+
+// contentFieldValue returns the value of a filterable column of content
+func contentFieldValue(content *Content, field string) interface{} {
+	switch field {
+	case "id":
+		return content.ID
+	case "tenant_id":
+		return content.TenantID
+	case "owner_id":
+		return content.OwnerID
+	case "owner_type":
+		return content.OwnerType
+	case "name":
+		return content.Name
+	case "description":
+		return content.Description
+	case "document_type":
+		return content.DocumentType
+	case "status":
+		return content.Status
+	case "derivation_type":
+		return content.DerivationType
+	case "created_at":
+		return content.CreatedAt
+	case "updated_at":
+		return content.UpdatedAt
+	}
+	return nil
+}
+
+// compareFilterValues orders two normalized values of the same column
+func compareFilterValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case uuid.UUID:
+		return strings.Compare(a.String(), b.(uuid.UUID).String())
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package simplecontent
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ContentFull is a content together with its objects and content metadata
+type ContentFull struct {
+	Content  *Content         `json:"content"`
+	Objects  []*Object        `json:"objects"`
+	Metadata *ContentMetadata `json:"metadata,omitempty"` // nil when no metadata has been set
+}
+
+// GetContentFull returns the content, its objects and its content metadata in one call,
+// matching what GetContent, GetObjectsByContentID and GetContentMetadata return
+// separately. Objects and metadata are read with the batch repository queries used by
+// GetContentDetailsBatch, so a missing metadata record is not an error.
+func (s *service) GetContentFull(ctx context.Context, contentID uuid.UUID) (*ContentFull, error) {
+	content, err := s.repository.GetContent(ctx, contentID)
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "get_full", Err: err}
+	}
+
+	ids := []uuid.UUID{contentID}
+	objectsByContent, err := s.repository.GetObjectsByContentIDs(ctx, ids)
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "get_full_objects", Err: err}
+	}
+	metadataByContent, err := s.repository.GetContentMetadataByContentIDs(ctx, ids)
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "get_full_metadata", Err: err}
+	}
+
+	objects := objectsByContent[contentID]
+	if objects == nil {
+		objects = []*Object{}
+	}
+	return &ContentFull{
+		Content:  content,
+		Objects:  objects,
+		Metadata: metadataByContent[contentID],
+	}, nil
+}
@@ -0,0 +1,112 @@
+package simplecontent
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// PrimaryObject summarizes the object a content's downloads serve, its newest live object
+// that is uploaded or processed, for listings that show sizes or build URLs
+type PrimaryObject struct {
+	ID                 uuid.UUID `json:"id"`
+	ObjectKey          string    `json:"object_key"`
+	StorageBackendName string    `json:"storage_backend_name"`
+	Version            int       `json:"version"`
+	Status             string    `json:"status"`
+	SizeBytes          int64     `json:"size_bytes"` // From the object metadata; 0 when it has none
+	MimeType           string    `json:"mime_type,omitempty"`
+	ETag               string    `json:"etag,omitempty"`
+}
+
+// ListContentOption configures ListContentPage
+type ListContentOption func(*listContentOptions)
+
+type listContentOptions struct {
+	totalCount bool
+}
+
+// WithTotalCount makes ListContentPage also count every content matching the request's
+// filters, ignoring Limit and Offset, and report it as Total
+func WithTotalCount() ListContentOption {
+	return func(o *listContentOptions) {
+		o.totalCount = true
+	}
+}
+
+// ListContentResult is one page of content returned by ListContentPage
+type ListContentResult struct {
+	Items   []*Content `json:"items"`
+	Total   *int64     `json:"total,omitempty"` // Set when WithTotalCount was given
+	HasMore bool       `json:"has_more"`        // More content matches after this page
+}
+
+// ListContentPage lists one page of content like ListContent and reports whether more
+// pages follow. With WithTotalCount the matching content is counted with the same filters,
+// so a UI can show "1-20 of 438".
+func (s *service) ListContentPage(ctx context.Context, req ListContentRequest, opts ...ListContentOption) (*ListContentResult, error) {
+	var options listContentOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	s.applyOwnerDefaults(&req.OwnerID, &req.TenantID)
+
+	// Fetch one extra row to tell whether another page follows
+	filters := contentListFilters(req)
+	if req.Limit > 0 {
+		limit := req.Limit + 1
+		filters.Limit = &limit
+	}
+	items, err := s.repository.ListContentWithFilters(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListContentResult{Items: items}
+	if req.Limit > 0 && len(items) > req.Limit {
+		result.Items = items[:req.Limit]
+		result.HasMore = true
+	}
+	if result.Items == nil {
+		result.Items = []*Content{}
+	}
+
+	if options.totalCount {
+		total, err := s.repository.CountContentWithFilters(ctx, ContentCountFilters{
+			OwnerID:       &req.OwnerID,
+			TenantID:      &req.TenantID,
+			CreatedAfter:  req.CreatedAfter,
+			CreatedBefore: req.CreatedBefore,
+			UpdatedAfter:  req.UpdatedAfter,
+			UpdatedBefore: req.UpdatedBefore,
+			OriginalsOnly: req.OriginalsOnly,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+	return result, nil
+}
+
+// contentListFilters converts a ListContentRequest into repository filters
+func contentListFilters(req ListContentRequest) ContentListFilters {
+	filters := ContentListFilters{
+		OwnerID:       &req.OwnerID,
+		TenantID:      &req.TenantID,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		UpdatedAfter:  req.UpdatedAfter,
+		UpdatedBefore: req.UpdatedBefore,
+		OriginalsOnly: req.OriginalsOnly,
+
+		IncludePrimaryObject: req.IncludePrimaryObject,
+	}
+	if req.Limit > 0 {
+		filters.Limit = &req.Limit
+	}
+	if req.Offset > 0 {
+		filters.Offset = &req.Offset
+	}
+	return filters
+}
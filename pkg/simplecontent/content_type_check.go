@@ -0,0 +1,110 @@
+package simplecontent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// sniffLen is the number of bytes http.DetectContentType considers
+const sniffLen = 512
+
+// defaultGenericContentTypes are sniffed or declared types that never cause a mismatch.
+// application/octet-stream is the sniffer's answer for unknown data, text/plain covers
+// JSON, CSV and other text formats, and application/zip covers OOXML and EPUB containers.
+var defaultGenericContentTypes = []string{
+	"application/octet-stream",
+	"text/plain",
+	"application/zip",
+}
+
+// WithStrictContentType makes uploads sniff the first bytes of the data and fail with
+// ErrContentTypeMismatch when the detected type conflicts with the content's declared
+// DocumentType. Declared types such as "image/*" accept any subtype. Contents without a
+// DocumentType are not checked.
+//
+// Generic types, on either side, are tolerated: application/octet-stream, text/plain and
+// application/zip, plus any passed as tolerated.
+func WithStrictContentType(tolerated ...string) Option {
+	return func(s *service) {
+		s.strictContentType = true
+		s.genericContentTypes = append(append([]string(nil), defaultGenericContentTypes...), tolerated...)
+	}
+}
+
+// checkContentType sniffs reader when strict mode is on and returns a reader that still
+// yields the full data. Seekable readers are rewound instead of wrapped.
+func (s *service) checkContentType(reader io.Reader, declared string) (io.Reader, error) {
+	if !s.strictContentType || declared == "" || reader == nil {
+		return reader, nil
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read upload data: %w", err)
+	}
+	head = head[:n]
+
+	if seeker, ok := reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(-int64(n), io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("failed to rewind upload data: %w", err)
+		}
+	} else {
+		reader = io.MultiReader(bytes.NewReader(head), reader)
+	}
+
+	if n == 0 {
+		return reader, nil
+	}
+	detected := http.DetectContentType(head)
+	if !s.contentTypesCompatible(declared, detected) {
+		return nil, fmt.Errorf("%w: declared %s, detected %s", ErrContentTypeMismatch, baseMediaType(declared), baseMediaType(detected))
+	}
+	return reader, nil
+}
+
+// checkContentTypeFor checks an upload against the DocumentType of an existing content
+func (s *service) checkContentTypeFor(ctx context.Context, contentID uuid.UUID, reader io.Reader) (io.Reader, error) {
+	if !s.strictContentType {
+		return reader, nil
+	}
+	content, err := s.repository.GetContent(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.checkContentType(reader, content.DocumentType)
+}
+
+// contentTypesCompatible reports whether detected data may be stored as declared
+func (s *service) contentTypesCompatible(declared, detected string) bool {
+	declared = baseMediaType(declared)
+	detected = baseMediaType(detected)
+	if declared == detected {
+		return true
+	}
+	for _, generic := range s.genericContentTypes {
+		generic = baseMediaType(generic)
+		if declared == generic || detected == generic {
+			return true
+		}
+	}
+	if major, ok := strings.CutSuffix(declared, "/*"); ok {
+		return strings.HasPrefix(detected, major+"/")
+	}
+	return false
+}
+
+// baseMediaType strips parameters and lowercases a media type
+func baseMediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
@@ -0,0 +1,48 @@
+package simplecontent
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// defaultNameMaxLength is the longest content name accepted, in characters, matching the
+// VARCHAR(255) name column of the postgres schema
+const defaultNameMaxLength = 255
+
+// WithNameMaxLength sets the longest content name, in characters, that creating or updating
+// content accepts. The default is 255; zero or a negative n removes the limit.
+func WithNameMaxLength(n int) Option {
+	return func(s *service) {
+		s.nameMaxLength = max(n, 0)
+	}
+}
+
+// WithDescriptionMaxLength sets the longest content description, in characters, that creating
+// or updating content accepts. Descriptions are not limited by default; zero or a negative n
+// removes the limit again.
+func WithDescriptionMaxLength(n int) Option {
+	return func(s *service) {
+		s.descriptionMaxLength = max(n, 0)
+	}
+}
+
+// validateContentFields checks that name and description are valid UTF-8 and within the
+// configured lengths, reporting the first offending field as a FieldError
+func (s *service) validateContentFields(name, description string) error {
+	if err := validateTextField("name", name, s.nameMaxLength); err != nil {
+		return err
+	}
+	return validateTextField("description", description, s.descriptionMaxLength)
+}
+
+func validateTextField(field, value string, maxLength int) error {
+	if !utf8.ValidString(value) {
+		return &FieldError{Field: field, Reason: "not valid UTF-8"}
+	}
+	if maxLength > 0 {
+		if n := utf8.RuneCountInString(value); n > maxLength {
+			return &FieldError{Field: field, Reason: fmt.Sprintf("%d characters exceeds the maximum of %d", n, maxLength)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package simplecontent
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// variantRegistry maps normalized variant names to the transformers that generate them
+type variantRegistry map[string]PreviewTransformer
+
+// WithVariantTransformer registers the transformer DownloadContentVariant uses to generate
+// variant from the original data, e.g. an image scaler for "thumbnail_256". The transformer
+// receives the content's data and MIME type and returns the variant's data. Variants are only
+// generated once WithOnDemandVariants is set.
+func WithVariantTransformer(variant string, transformer PreviewTransformer) Option {
+	return func(s *service) {
+		if s.variantTransformers == nil {
+			s.variantTransformers = make(variantRegistry)
+		}
+		s.variantTransformers[string(NormalizeVariant(variant))] = transformer
+	}
+}
+
+// WithOnDemandVariants lets DownloadContentVariant generate variants that do not exist yet
+// with the transformer registered by WithVariantTransformer. With cache set, a generated
+// variant is stored as derived content of the original, so later downloads serve the stored
+// copy; otherwise it is generated again for every download.
+func WithOnDemandVariants(cache bool) Option {
+	return func(s *service) {
+		s.onDemandVariants = true
+		s.cacheVariants = cache
+	}
+}
+
+// DownloadContentVariant downloads the derived content of contentID for variant. When there is
+// none and on-demand variants are enabled, the variant is generated from the content's data.
+// Generation of a variant is single-flighted with the derivation lock: a request arriving while
+// the variant is generated waits, then serves the cached copy, or generates it in turn when
+// variants are not cached. The lock is released once the variant is generated, so a slow
+// reader of an uncached variant does not hold up other requests. Without a way to produce the variant ErrContentNotFound is returned.
+func (s *service) DownloadContentVariant(ctx context.Context, contentID uuid.UUID, variant string) (io.ReadCloser, error) {
+	variant = string(NormalizeVariant(variant))
+	if derived, err := s.existingDerivedContent(ctx, contentID, variant); err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "download_variant", Err: err}
+	} else if derived != nil {
+		return s.DownloadContent(ctx, derived.ID)
+	}
+
+	transformer := s.variantTransformers[variant]
+	if !s.onDemandVariants || transformer == nil {
+		return nil, &ContentError{ContentID: contentID, Op: "download_variant", Err: fmt.Errorf("%w: no %s variant", ErrContentNotFound, variant)}
+	}
+	content, err := s.repository.GetContent(ctx, contentID)
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "download_variant", Err: ErrContentNotFound}
+	}
+
+	unlock, err := s.lockDerivation(ctx, contentID, variant)
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "download_variant", Err: err}
+	}
+	defer unlock()
+	if s.cacheVariants {
+		// Another request may have generated the variant while this one waited for the lock
+		derived, err := s.existingDerivedContent(ctx, contentID, variant)
+		if err != nil {
+			return nil, &ContentError{ContentID: contentID, Op: "download_variant", Err: err}
+		}
+		if derived == nil {
+			if derived, err = s.generateCachedVariant(ctx, content, variant, transformer, map[string]interface{}{"generated_on_demand": true}); err != nil {
+				return nil, err
+			}
+		}
+		return s.DownloadContent(ctx, derived.ID)
+	}
+
+	// The lock covers generation only; reading the uncached variant is left to the caller
+	return s.generateVariant(ctx, content, transformer)
+}
+
+// generateVariant runs transformer over the data of content
+func (s *service) generateVariant(ctx context.Context, content *Content, transformer PreviewTransformer) (io.ReadCloser, error) {
+	src, err := s.DownloadContent(ctx, content.ID)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := content.DocumentType
+	if metadata, err := s.repository.GetContentMetadata(ctx, content.ID); err == nil && metadata != nil && metadata.MimeType != "" {
+		mimeType = metadata.MimeType
+	}
+	generated, _, err := transformer.Transform(ctx, src, mimeType)
+	if err != nil {
+		src.Close()
+		return nil, &ContentError{ContentID: content.ID, Op: "generate_variant", Err: err}
+	}
+	return &variantReader{ReadCloser: generated, src: src}, nil
+}
+
+// generateCachedVariant generates variant and stores it as derived content of content
+func (s *service) generateCachedVariant(ctx context.Context, content *Content, variant string, transformer PreviewTransformer, metadata map[string]interface{}) (*Content, error) {
+	generated, err := s.generateVariant(ctx, content, transformer)
+	if err != nil {
+		return nil, err
+	}
+	defer generated.Close()
+	return s.UploadDerivedContent(ctx, UploadDerivedContentRequest{
+		ParentID: content.ID,
+		OwnerID:  content.OwnerID,
+		TenantID: content.TenantID,
+		Variant:  variant,
+		Reader:   generated,
+		Metadata: metadata,
+	})
+}
+
+// variantReader closes the original data a variant was generated from along with the
+// variant
+type variantReader struct {
+	io.ReadCloser
+	src io.Closer
+}
+
+func (r *variantReader) Close() error {
+	err := r.ReadCloser.Close()
+	if r.src != nil {
+		if srcErr := r.src.Close(); err == nil {
+			err = srcErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package simplecontent
+
+import "github.com/google/uuid"
+
+// WithDefaultTenant sets the tenant of requests that leave TenantID unset (uuid.Nil), for
+// single-tenant deployments that would otherwise pass the same ID on every call. Create,
+// upload and list requests inherit it; a request naming a tenant keeps its own.
+func WithDefaultTenant(tenantID uuid.UUID) Option {
+	return func(s *service) {
+		s.defaultTenantID = tenantID
+	}
+}
+
+// WithDefaultOwner sets the owner of requests that leave OwnerID unset (uuid.Nil), the way
+// WithDefaultTenant fills the tenant
+func WithDefaultOwner(ownerID uuid.UUID) Option {
+	return func(s *service) {
+		s.defaultOwnerID = ownerID
+	}
+}
+
+// applyOwnerDefaults fills an unset owner and tenant from WithDefaultOwner and WithDefaultTenant
+func (s *service) applyOwnerDefaults(ownerID, tenantID *uuid.UUID) {
+	if *ownerID == uuid.Nil {
+		*ownerID = s.defaultOwnerID
+	}
+	if *tenantID == uuid.Nil {
+		*tenantID = s.defaultTenantID
+	}
+}
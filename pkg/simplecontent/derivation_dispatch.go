@@ -0,0 +1,168 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DerivationJob generates one variant of an uploaded content with the transformer registered
+// by WithVariantTransformer and stores it as derived content
+type DerivationJob struct {
+	ContentID uuid.UUID
+	Variant   string
+
+	run func(ctx context.Context) error
+}
+
+// Run generates the variant. A variant that already exists is left alone.
+func (j *DerivationJob) Run(ctx context.Context) error {
+	return j.run(ctx)
+}
+
+// DerivationDispatcher decides where derivation jobs run. Dispatch may run the job before
+// returning or hand it to workers; a returned error is logged and never fails the upload.
+type DerivationDispatcher interface {
+	Dispatch(ctx context.Context, job *DerivationJob) error
+}
+
+// WithDerivationDispatcher generates the variants registered with WithVariantTransformer as
+// soon as original content is uploaded, one DerivationJob per variant handed to dispatcher.
+// NewInlineDispatcher generates them before the upload returns; NewWorkerPoolDispatcher
+// queues them to background workers.
+func WithDerivationDispatcher(dispatcher DerivationDispatcher) Option {
+	return func(s *service) {
+		s.derivationDispatcher = dispatcher
+	}
+}
+
+// InlineDispatcher runs each derivation job before Dispatch returns
+type InlineDispatcher struct{}
+
+// NewInlineDispatcher creates a dispatcher generating variants inline with the upload
+func NewInlineDispatcher() *InlineDispatcher {
+	return &InlineDispatcher{}
+}
+
+// Dispatch runs job and returns its error
+func (d *InlineDispatcher) Dispatch(ctx context.Context, job *DerivationJob) error {
+	return job.Run(ctx)
+}
+
+// errDispatcherClosed is returned by WorkerPoolDispatcher.Dispatch after Close
+var errDispatcherClosed = errors.New("derivation dispatcher is closed")
+
+// WorkerPoolDispatcher queues derivation jobs to a fixed number of worker goroutines, so
+// uploads return without waiting for their variants
+type WorkerPoolDispatcher struct {
+	jobs   chan queuedDerivation
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// queuedDerivation is a job with the context it was dispatched with
+type queuedDerivation struct {
+	ctx context.Context
+	job *DerivationJob
+}
+
+// NewWorkerPoolDispatcher starts workers goroutines serving a queue of queueSize jobs.
+// Dispatch blocks while the queue is full. Call Close to stop the workers.
+func NewWorkerPoolDispatcher(workers, queueSize int) *WorkerPoolDispatcher {
+	d := &WorkerPoolDispatcher{jobs: make(chan queuedDerivation, max(queueSize, 0))}
+	for range max(workers, 1) {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+func (d *WorkerPoolDispatcher) work() {
+	defer d.wg.Done()
+	for queued := range d.jobs {
+		if err := queued.job.Run(queued.ctx); err != nil {
+			slog.Warn("Derivation job failed", "content_id", queued.job.ContentID, "variant", queued.job.Variant, "error", err)
+		}
+	}
+}
+
+// Dispatch queues job. It runs detached from ctx's cancellation, since the upload that
+// dispatched it usually returns first, but keeps ctx's values.
+func (d *WorkerPoolDispatcher) Dispatch(ctx context.Context, job *DerivationJob) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return errDispatcherClosed
+	}
+	select {
+	case d.jobs <- queuedDerivation{ctx: context.WithoutCancel(ctx), job: job}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting jobs and waits for the queued ones to finish
+func (d *WorkerPoolDispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.jobs)
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+// dispatchDerivations hands a derivation job for every registered variant transformer to the
+// derivation dispatcher, once contentID holds uploaded original data
+func (s *service) dispatchDerivations(ctx context.Context, contentID uuid.UUID) {
+	if s.derivationDispatcher == nil || len(s.variantTransformers) == 0 {
+		return
+	}
+	content, err := s.repository.GetContent(ctx, contentID)
+	if err != nil {
+		return
+	}
+	// Derived content is not derived from again, and a parent must be uploaded first
+	if content.DerivationType != "" {
+		return
+	}
+	if ok, _ := canCreateDerived(ContentStatus(content.Status)); !ok {
+		return
+	}
+
+	variants := make([]string, 0, len(s.variantTransformers))
+	for variant := range s.variantTransformers {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+	for _, variant := range variants {
+		job := &DerivationJob{ContentID: content.ID, Variant: variant}
+		job.run = func(ctx context.Context) error {
+			return s.runDerivation(ctx, content, variant)
+		}
+		if err := s.derivationDispatcher.Dispatch(ctx, job); err != nil {
+			slog.Warn("Failed to dispatch derivation", "content_id", content.ID, "variant", variant, "error", err)
+		}
+	}
+}
+
+// runDerivation generates variant of content under the derivation lock unless it exists
+func (s *service) runDerivation(ctx context.Context, content *Content, variant string) error {
+	unlock, err := s.lockDerivation(ctx, content.ID, variant)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	existing, err := s.existingDerivedContent(ctx, content.ID, variant)
+	if err != nil || existing != nil {
+		return err
+	}
+	_, err = s.generateCachedVariant(ctx, content, variant, s.variantTransformers[variant], map[string]interface{}{"generated_on_upload": true})
+	return err
+}
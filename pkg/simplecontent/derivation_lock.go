@@ -0,0 +1,99 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// lockDerivation single-flights the creation of one variant of a parent. The returned
+// function releases the lock; callers then check for an existing derived content of the
+// variant before creating one, so concurrent workers generating the same thumbnail end up
+// with a single derived content.
+func (s *service) lockDerivation(ctx context.Context, parentID uuid.UUID, variant string) (func(), error) {
+	return s.repository.AcquireLock(ctx, "derive:"+parentID.String()+":"+variant)
+}
+
+// existingDerivedContent returns the live derived content of parentID for variant, or nil
+// when there is none
+func (s *service) existingDerivedContent(ctx context.Context, parentID uuid.UUID, variant string) (*Content, error) {
+	limit := 1
+	existing, err := s.repository.ListDerivedContentWithDetails(ctx, ListDerivedContentParams{
+		ParentID: &parentID,
+		Variant:  &variant,
+		Limit:    &limit,
+	})
+	if err != nil || len(existing) == 0 {
+		return nil, err
+	}
+	return existing[0].Content, nil
+}
+
+// overwriteDerivedContent applies a CreateDerivedContent request with Overwrite to the
+// derived content already holding its variant. The content keeps its ID and objects; its
+// status is reset to initialStatus where the status registry allows the transition, and its
+// name and metadata are replaced when given.
+func (s *service) overwriteDerivedContent(ctx context.Context, existing *Content, req CreateDerivedContentRequest, initialStatus ContentStatus) (*Content, error) {
+	// The reset follows the same transition rules as UpdateContentStatus
+	if ContentStatuses.IsRegistered(existing.Status) && !ContentStatuses.CanTransition(existing.Status, string(initialStatus)) {
+		return nil, &ContentError{
+			ContentID: existing.ID,
+			Op:        "create_derived",
+			Err:       fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, existing.Status, initialStatus),
+		}
+	}
+
+	now := time.Now().UTC()
+	before := *existing
+	existing.Status = string(initialStatus)
+	existing.DerivationType = NormalizeDerivationType(req.DerivationType)
+	if req.Name != "" {
+		existing.Name = req.Name
+	}
+	if req.OwnerType != "" {
+		existing.OwnerType = req.OwnerType
+	}
+	existing.UpdatedAt = now
+	if err := s.repository.UpdateContent(ctx, existing); err != nil {
+		return nil, &ContentError{ContentID: existing.ID, Op: "create_derived", Err: err}
+	}
+
+	if req.Metadata != nil {
+		// Missing metadata is left out of the batch lookup, so only real errors fail here
+		stored, err := s.repository.GetContentMetadataByContentIDs(ctx, []uuid.UUID{existing.ID})
+		if err != nil {
+			return nil, &ContentError{ContentID: existing.ID, Op: "create_derived", Err: err}
+		}
+		metadata := stored[existing.ID]
+		if metadata == nil {
+			metadata = &ContentMetadata{ContentID: existing.ID, CreatedAt: now}
+		}
+		metadata.Metadata = req.Metadata
+		if req.FileName != "" {
+			metadata.FileName = req.FileName
+		}
+		metadata.UpdatedAt = now
+		if err := s.repository.SetContentMetadata(ctx, metadata); err != nil {
+			return nil, &ContentError{ContentID: existing.ID, Op: "create_derived", Err: err}
+		}
+	}
+
+	s.emitContentUpdated(ctx, &before, existing)
+	return existing, nil
+}
+
+// discardDuplicateVariant deletes derived content whose relationship the repository rejected
+// with ErrVariantExists, e.g. when another process created the variant first, so no orphan
+// content is left behind
+func (s *service) discardDuplicateVariant(ctx context.Context, contentID uuid.UUID, err error) {
+	if !errors.Is(err, ErrVariantExists) {
+		return
+	}
+	if err := s.repository.DeleteContent(context.WithoutCancel(ctx), contentID); err != nil {
+		slog.Warn("Failed to delete duplicate derived content", "content_id", contentID, "error", err)
+	}
+}
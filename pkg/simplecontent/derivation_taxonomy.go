@@ -0,0 +1,50 @@
+package simplecontent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// derivationTaxonomy maps each registered derivation type to its allowed variants
+type derivationTaxonomy map[string]map[string]bool
+
+// WithDerivationTaxonomy registers the derivation types the service accepts, each mapped to
+// its allowed variants, e.g. {"thumbnail": {"thumbnail_256", "thumbnail_512"}}. Derived
+// content with an unregistered type fails with ErrUnknownDerivationType, and one with a
+// variant not listed for its type fails with ErrUnknownVariant. A type mapped to no
+// variants accepts any variant. Names are compared case-insensitively.
+//
+// Without this option any derivation type and variant is accepted. The option is not
+// named WithDerivationTypes because that name is taken by the ListDerivedContent filter.
+func WithDerivationTaxonomy(taxonomy map[string][]string) Option {
+	return func(s *service) {
+		s.derivationTypes = make(derivationTaxonomy, len(taxonomy))
+		for derivationType, variants := range taxonomy {
+			allowed := make(map[string]bool, len(variants))
+			for _, variant := range variants {
+				allowed[string(NormalizeVariant(variant))] = true
+			}
+			s.derivationTypes[NormalizeDerivationType(derivationType)] = allowed
+		}
+	}
+}
+
+// validateDerivation checks a derivation type and variant against the registered
+// taxonomy. An empty variant is stored as the derivation type and is not checked.
+func (s *service) validateDerivation(derivationType, variant string) error {
+	if s.derivationTypes == nil {
+		return nil
+	}
+	derivationType = NormalizeDerivationType(strings.TrimSpace(derivationType))
+	allowed, ok := s.derivationTypes[derivationType]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownDerivationType, derivationType)
+	}
+	if variant == "" || len(allowed) == 0 {
+		return nil
+	}
+	if v := string(NormalizeVariant(strings.TrimSpace(variant))); !allowed[v] {
+		return fmt.Errorf("%w: %q for derivation type %q", ErrUnknownVariant, v, derivationType)
+	}
+	return nil
+}
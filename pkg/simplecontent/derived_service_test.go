@@ -2,12 +2,15 @@ package simplecontent_test
 
 import (
     "context"
+    "errors"
     "fmt"
+    "strings"
     "testing"
 
     "github.com/google/uuid"
     simplecontent "github.com/tendant/simple-content/pkg/simplecontent"
     memoryrepo "github.com/tendant/simple-content/pkg/simplecontent/repo/memory"
+    memorystorage "github.com/tendant/simple-content/pkg/simplecontent/storage/memory"
 )
 
 func TestCreateDerived_InferDerivationTypeFromVariant(t *testing.T) {
@@ -74,6 +77,63 @@ func TestListDerivedAndGetRelationship(t *testing.T) {
     if rel.ParentID != parent.ID { t.Fatalf("parent mismatch") }
 }
 
+// TestListDerivedContentWithDetails_MatchesPerChildLookup checks the joined listing returns
+// the same children as listing relationships and calling GetContent for each one.
+func TestListDerivedContentWithDetails_MatchesPerChildLookup(t *testing.T) {
+    svc := mustService(t)
+    ctx := context.Background()
+
+    parent, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+        OwnerID: uuid.New(), TenantID: uuid.New(), Name: "parent",
+    })
+    if err != nil { t.Fatalf("create parent: %v", err) }
+    parent.Status = string(simplecontent.ContentStatusUploaded)
+    if err := svc.UpdateContent(ctx, simplecontent.UpdateContentRequest{Content: parent}); err != nil {
+        t.Fatalf("update parent status: %v", err)
+    }
+
+    var deletedID uuid.UUID
+    for _, v := range []string{"thumbnail_128", "thumbnail_256", "preview_720"} {
+        d, err := svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+            ParentID: parent.ID, OwnerID: parent.OwnerID, TenantID: parent.TenantID, Variant: v,
+        })
+        if err != nil { t.Fatalf("create derived %s: %v", v, err) }
+        if v == "preview_720" { deletedID = d.ID }
+    }
+    // A deleted child must be omitted by both approaches
+    if err := svc.DeleteContent(ctx, deletedID); err != nil { t.Fatalf("delete derived: %v", err) }
+
+    // Loop-based approach
+    rels, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parent.ID))
+    if err != nil { t.Fatalf("list derived: %v", err) }
+    expected := map[uuid.UUID]*simplecontent.Content{}
+    for _, rel := range rels {
+        child, err := svc.GetContent(ctx, rel.ContentID)
+        if err != nil { continue }
+        expected[rel.ContentID] = child
+    }
+
+    items, err := svc.ListDerivedContentWithDetails(ctx, simplecontent.WithParentID(parent.ID))
+    if err != nil { t.Fatalf("list derived with details: %v", err) }
+    if len(items) != len(expected) || len(items) != 2 {
+        t.Fatalf("expected %d children, got %d", len(expected), len(items))
+    }
+    for _, item := range items {
+        want, ok := expected[item.ContentID]
+        if !ok { t.Fatalf("unexpected child %s", item.ContentID) }
+        if item.Content == nil || item.Content.ID != want.ID || item.Content.Name != want.Name ||
+            item.Content.Status != want.Status || item.Content.DerivationType != want.DerivationType {
+            t.Fatalf("child content mismatch: got %+v, want %+v", item.Content, want)
+        }
+        if item.ParentID != parent.ID || item.Variant == "" {
+            t.Fatalf("relationship not populated: %+v", item.DerivedContent)
+        }
+        if item.ProcessingStatus == "" {
+            t.Fatalf("processing status not populated for %s", item.ContentID)
+        }
+    }
+}
+
 // TestBackwardCompatibility_ListDerivedContentParams ensures that existing code
 // using ListDerivedContentParams continues to work with no changes after enhancements
 func TestBackwardCompatibility_ListDerivedContentParams(t *testing.T) {
@@ -375,6 +435,90 @@ func TestBackwardCompatibility_DataConsistency(t *testing.T) {
     })
 }
 
+func TestListDerivedContent_FilterByDerivedStatus(t *testing.T) {
+    svc := mustService(t)
+    ctx := context.Background()
+
+    parent, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+        OwnerID: uuid.New(), TenantID: uuid.New(), Name: "parent",
+    })
+    if err != nil { t.Fatalf("create parent: %v", err) }
+    if err := svc.UpdateContentStatus(ctx, parent.ID, simplecontent.ContentStatusUploaded); err != nil {
+        t.Fatalf("update parent status: %v", err)
+    }
+
+    ids := map[string]uuid.UUID{}
+    for _, v := range []string{"thumbnail_128", "thumbnail_256", "thumbnail_720"} {
+        d, err := svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+            ParentID: parent.ID,
+            OwnerID:  parent.OwnerID,
+            TenantID: parent.TenantID,
+            Variant:  v,
+        })
+        if err != nil { t.Fatalf("create derived %s: %v", v, err) }
+        ids[v] = d.ID
+    }
+
+    // thumbnail_128 completes, thumbnail_256 fails, thumbnail_720 is still processing
+    if err := svc.UpdateContentStatus(ctx, ids["thumbnail_128"], simplecontent.ContentStatusProcessed); err != nil {
+        t.Fatalf("mark processed: %v", err)
+    }
+    if err := svc.MarkDerivationFailed(ctx, ids["thumbnail_256"], "decoder error: unsupported format"); err != nil {
+        t.Fatalf("mark failed: %v", err)
+    }
+
+    completed, err := svc.ListDerivedContent(ctx,
+        simplecontent.WithParentID(parent.ID),
+        simplecontent.WithDerivedStatus(simplecontent.DerivationStatusCompleted),
+    )
+    if err != nil { t.Fatalf("list completed: %v", err) }
+    if len(completed) != 1 { t.Fatalf("expected 1 completed, got %d", len(completed)) }
+    if completed[0].ContentID != ids["thumbnail_128"] { t.Fatalf("unexpected completed derivation %s", completed[0].ContentID) }
+    if completed[0].ProcessingStatus != simplecontent.DerivationStatusCompleted {
+        t.Fatalf("expected processing status completed, got %q", completed[0].ProcessingStatus)
+    }
+    if completed[0].ErrorMessage != "" { t.Fatalf("expected no error message, got %q", completed[0].ErrorMessage) }
+
+    failed, err := svc.ListDerivedContent(ctx,
+        simplecontent.WithParentID(parent.ID),
+        simplecontent.WithDerivedStatus(simplecontent.DerivationStatusFailed),
+    )
+    if err != nil { t.Fatalf("list failed: %v", err) }
+    if len(failed) != 1 { t.Fatalf("expected 1 failed, got %d", len(failed)) }
+    if failed[0].ContentID != ids["thumbnail_256"] { t.Fatalf("unexpected failed derivation %s", failed[0].ContentID) }
+    if failed[0].ProcessingStatus != simplecontent.DerivationStatusFailed {
+        t.Fatalf("expected processing status failed, got %q", failed[0].ProcessingStatus)
+    }
+    if failed[0].ErrorMessage != "decoder error: unsupported format" {
+        t.Fatalf("unexpected error message %q", failed[0].ErrorMessage)
+    }
+
+    notFailed, err := svc.ListDerivedContent(ctx,
+        simplecontent.WithParentID(parent.ID),
+        simplecontent.WithDerivedStatus(simplecontent.DerivationStatusCompleted, simplecontent.DerivationStatusProcessing),
+    )
+    if err != nil { t.Fatalf("list completed+processing: %v", err) }
+    if len(notFailed) != 2 { t.Fatalf("expected 2 completed or processing, got %d", len(notFailed)) }
+
+    all, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parent.ID))
+    if err != nil { t.Fatalf("list all: %v", err) }
+    if len(all) != 3 { t.Fatalf("expected 3 derived without status filter, got %d", len(all)) }
+}
+
+func TestMarkDerivationFailed_NotDerived(t *testing.T) {
+    svc := mustService(t)
+    ctx := context.Background()
+
+    original, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+        OwnerID: uuid.New(), TenantID: uuid.New(), Name: "original",
+    })
+    if err != nil { t.Fatalf("create content: %v", err) }
+
+    if err := svc.MarkDerivationFailed(ctx, original.ID, "boom"); err == nil {
+        t.Fatalf("expected error when marking non-derived content as failed")
+    }
+}
+
 func mustService(t *testing.T) simplecontent.Service {
     t.Helper()
     repo := memoryrepo.New()
@@ -627,3 +771,110 @@ func TestOptionPatternVsLegacyConvenienceFunctions(t *testing.T) {
     if err != nil { t.Fatalf("legacy get thumbnails: %v", err) }
     if len(legacyResults) != 2 { t.Fatalf("expected 2 thumbnails from legacy, got %d", len(legacyResults)) }
 }
+
+// newDeleteTree uploads a parent with two thumbnails and a derived-from-derived preview
+func newDeleteTree(t *testing.T) (simplecontent.Service, *simplecontent.Content, []*simplecontent.Content, simplecontent.BlobStore) {
+    t.Helper()
+    store := memorystorage.New()
+    svc, err := simplecontent.New(
+        simplecontent.WithRepository(memoryrepo.New()),
+        simplecontent.WithBlobStore("memory", store),
+    )
+    if err != nil { t.Fatalf("service new: %v", err) }
+    ctx := context.Background()
+
+    parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+        OwnerID: uuid.New(), TenantID: uuid.New(), Name: "photo.jpg",
+        Reader: strings.NewReader("original"),
+    })
+    if err != nil { t.Fatalf("upload parent: %v", err) }
+
+    var derived []*simplecontent.Content
+    for _, variant := range []string{"thumbnail_128", "thumbnail_256"} {
+        child, err := svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+            ParentID: parent.ID, OwnerID: parent.OwnerID, TenantID: parent.TenantID,
+            DerivationType: "thumbnail", Variant: variant,
+            Reader: strings.NewReader(variant),
+        })
+        if err != nil { t.Fatalf("upload %s: %v", variant, err) }
+        derived = append(derived, child)
+    }
+
+    grandchild, err := svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+        ParentID: derived[1].ID, OwnerID: parent.OwnerID, TenantID: parent.TenantID,
+        DerivationType: "preview", Variant: "preview_64",
+        Reader: strings.NewReader("preview"),
+    })
+    if err != nil { t.Fatalf("upload grandchild: %v", err) }
+    derived = append(derived, grandchild)
+
+    return svc, parent, derived, store
+}
+
+func TestDeleteContent_RefusesWithDerivedContent(t *testing.T) {
+    svc, parent, derived, _ := newDeleteTree(t)
+    ctx := context.Background()
+
+    err := svc.DeleteContent(ctx, parent.ID)
+    if !errors.Is(err, simplecontent.ErrHasDerivedContent) {
+        t.Fatalf("expected ErrHasDerivedContent, got %v", err)
+    }
+    var contentErr *simplecontent.ContentError
+    if !errors.As(err, &contentErr) || contentErr.HTTPStatus() != 409 {
+        t.Fatalf("expected 409 ContentError, got %v", err)
+    }
+
+    // Nothing was deleted
+    for _, c := range append([]*simplecontent.Content{parent}, derived...) {
+        if _, err := svc.GetContent(ctx, c.ID); err != nil {
+            t.Fatalf("content %s should still exist: %v", c.ID, err)
+        }
+    }
+
+    // A leaf has no children and deletes without cascade
+    if err := svc.DeleteContent(ctx, derived[0].ID); err != nil {
+        t.Fatalf("delete leaf: %v", err)
+    }
+}
+
+func TestDeleteContent_CascadeRemovesTree(t *testing.T) {
+    svc, parent, derived, store := newDeleteTree(t)
+    storageSvc := svc.(simplecontent.StorageService)
+    ctx := context.Background()
+
+    tree := append([]*simplecontent.Content{parent}, derived...)
+    var keys []string
+    for _, c := range tree {
+        objects, err := storageSvc.GetObjectsByContentID(ctx, c.ID)
+        if err != nil || len(objects) == 0 { t.Fatalf("objects for %s: %v", c.ID, err) }
+        for _, o := range objects {
+            keys = append(keys, o.ObjectKey)
+        }
+    }
+
+    if err := svc.DeleteContentWithOptions(ctx, parent.ID, simplecontent.DeleteContentOptions{Cascade: true}); err != nil {
+        t.Fatalf("cascade delete: %v", err)
+    }
+
+    for _, c := range tree {
+        if _, err := svc.GetContent(ctx, c.ID); err == nil {
+            t.Fatalf("content %s should be deleted", c.ID)
+        }
+        objects, err := storageSvc.GetObjectsByContentID(ctx, c.ID)
+        if err != nil { t.Fatalf("objects for %s: %v", c.ID, err) }
+        if len(objects) != 0 {
+            t.Fatalf("expected objects of %s to be deleted, got %d", c.ID, len(objects))
+        }
+    }
+    for _, key := range keys {
+        if _, err := store.GetObjectMeta(ctx, key); err == nil {
+            t.Fatalf("expected blob %s to be deleted", key)
+        }
+    }
+
+    rels, err := svc.ListDerivedContent(ctx, simplecontent.WithParentIDs(parent.ID, derived[1].ID))
+    if err != nil { t.Fatalf("list derived: %v", err) }
+    if len(rels) != 0 {
+        t.Fatalf("expected no derived relationships, got %d", len(rels))
+    }
+}
@@ -0,0 +1,109 @@
+package simplecontent
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tendant/simple-content/pkg/simplecontent/urlstrategy"
+)
+
+// Disposition says whether a client should save downloaded data or render it in place
+type Disposition string
+
+const (
+	DispositionAttachment Disposition = "attachment"
+	DispositionInline     Disposition = "inline"
+)
+
+// ParseDisposition parses a disposition given by an API consumer. An empty value is
+// returned as is, meaning the endpoint's default applies.
+func ParseDisposition(value string) (Disposition, error) {
+	switch d := Disposition(strings.ToLower(strings.TrimSpace(value))); d {
+	case "", DispositionAttachment, DispositionInline:
+		return d, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidDisposition, value)
+	}
+}
+
+// DispositionOptions override how a download or preview is presented. The zero value keeps
+// the endpoint's default disposition and the stored file name.
+type DispositionOptions struct {
+	Disposition Disposition // Empty keeps the default: attachment for downloads, inline for previews
+	FileName    string      // Empty keeps the stored file name
+}
+
+// Header returns the Content-Disposition value for data stored as fileName, applying the
+// overrides on top of defaultDisposition. It returns "" when there is neither an override
+// nor a file name, so callers can leave the header unset as before.
+func (o DispositionOptions) Header(defaultDisposition Disposition, fileName string) string {
+	if o.FileName != "" {
+		fileName = o.FileName
+	}
+	disposition := defaultDisposition
+	if o.Disposition != "" {
+		disposition = o.Disposition
+	}
+	if fileName == "" && o.Disposition == "" {
+		return ""
+	}
+	return ContentDisposition(disposition, fileName)
+}
+
+// ContentDisposition formats a Content-Disposition header value such as
+// `attachment; filename="report.pdf"`. Quotes and backslashes in the name are escaped, and a
+// name with non-ASCII characters also gets an RFC 5987 filename* parameter, with an ASCII
+// fallback in filename for older clients.
+func ContentDisposition(disposition Disposition, fileName string) string {
+	if fileName == "" {
+		return string(disposition)
+	}
+	ascii := true
+	var fallback strings.Builder
+	for _, r := range fileName {
+		switch {
+		case r == '"' || r == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			// Control characters cannot appear in a header
+		case r > 0x7e:
+			ascii = false
+			fallback.WriteByte('_')
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	header := fmt.Sprintf("%s; filename=\"%s\"", disposition, fallback.String())
+	if !ascii {
+		header += "; filename*=UTF-8''" + url.PathEscape(fileName)
+	}
+	return header
+}
+
+// WithDownloadDisposition makes the download URLs returned by GetContentDetails request the
+// given disposition and file name, e.g. inline rendering of a PDF or a custom save-as name.
+// Either may be empty to keep its default. How far the override reaches depends on the URL
+// strategy: content-based and CDN URLs carry it as disposition and filename query parameters,
+// and storage-delegated URLs pass it to backends that sign it into the URL, as S3 does.
+func WithDownloadDisposition(disposition Disposition, fileName string) ContentDetailsOption {
+	return func(cfg *ContentDetailsConfig) {
+		cfg.Disposition = disposition
+		cfg.DownloadFileName = fileName
+	}
+}
+
+// downloadURLMetadata describes the primary object for download URL generation, applying
+// the disposition overrides
+func (cfg *ContentDetailsConfig) downloadURLMetadata(fileName, mimeType string, version int) *urlstrategy.URLMetadata {
+	if cfg.DownloadFileName != "" {
+		fileName = cfg.DownloadFileName
+	}
+	return &urlstrategy.URLMetadata{
+		FileName:    fileName,
+		Version:     version,
+		ContentType: mimeType,
+		Disposition: string(cfg.Disposition),
+	}
+}
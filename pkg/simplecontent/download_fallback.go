@@ -0,0 +1,142 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// DownloadSource describes where DownloadContentWithSource read the bytes from
+type DownloadSource struct {
+	ObjectID uuid.UUID `json:"object_id"`
+	Version  int       `json:"version"`
+	Backend  string    `json:"backend"`  // Storage backend that served the data
+	Fallback bool      `json:"fallback"` // True when the latest object's blob was not available
+}
+
+// WithReplicaBackend registers a backend that holds copies of the objects stored on the
+// primary backend under the same object keys. DownloadContent reads from the replica when
+// the blob is missing on the primary. Replicas are tried in the order they are registered.
+func WithReplicaBackend(primary, replica string) Option {
+	return func(s *service) {
+		if s.replicaBackends == nil {
+			s.replicaBackends = make(map[string][]string)
+		}
+		s.replicaBackends[primary] = append(s.replicaBackends[primary], replica)
+	}
+}
+
+// isBlobMissing reports whether a backend download failed because the blob does not exist
+func isBlobMissing(err error) bool {
+	return errors.Is(err, ErrObjectNotFound)
+}
+
+// DownloadContentWithSource downloads the content data like DownloadContent and reports
+// which object version and backend served it. Uploaded objects are tried newest version
+// first; for each one the object's backend is tried, then its replica backends and the
+// mirror backend. Only a missing blob moves the download on to the next source, other
+// storage errors are returned as is. If no source has the data the error wraps
+// ErrBlobNotFound.
+func (s *service) DownloadContentWithSource(ctx context.Context, contentID uuid.UUID) (_ io.ReadCloser, _ *DownloadSource, err error) {
+	ctx, span := s.startSpan(ctx, "DownloadContent", AttrContentID.String(contentID.String()))
+	defer func() { span.end(err) }()
+
+	// Get content to validate status
+	content, err := s.repository.GetContent(ctx, contentID)
+	if err != nil {
+		return nil, nil, &ContentError{
+			ContentID: contentID,
+			Op:        "download_get_content",
+			Err:       err,
+		}
+	}
+
+	// Validate content status for download
+	contentStatus := ContentStatus(content.Status)
+	if ok, statusErr := canDownloadContent(contentStatus); !ok {
+		return nil, nil, &ContentError{
+			ContentID: contentID,
+			Op:        "download",
+			Err:       statusErr,
+		}
+	}
+
+	// Get objects for this content, newest version first
+	objects, err := s.repository.GetObjectsByContentID(ctx, contentID)
+	if err != nil {
+		return nil, nil, &ContentError{
+			ContentID: contentID,
+			Op:        "download_get_objects",
+			Err:       err,
+		}
+	}
+
+	if len(objects) == 0 {
+		return nil, nil, &ContentError{
+			ContentID: contentID,
+			Op:        "download",
+			Err:       ErrNoObjectsFound,
+		}
+	}
+
+	var candidates []*Object
+	for _, obj := range objects {
+		if obj.Status == string(ObjectStatusUploaded) {
+			candidates = append(candidates, obj)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil, &ContentError{
+			ContentID: contentID,
+			Op:        "download",
+			Err:       ErrNoUploadedObjects,
+		}
+	}
+
+	for i, obj := range candidates {
+		backendNames := append([]string{obj.StorageBackendName}, s.replicaBackends[obj.StorageBackendName]...)
+		if s.mirrorBackend != "" && !slices.Contains(backendNames, s.mirrorBackend) {
+			backendNames = append(backendNames, s.mirrorBackend)
+		}
+		for j, name := range backendNames {
+			backend, err := s.GetBackend(name)
+			if err != nil {
+				return nil, nil, &ObjectError{ObjectID: obj.ID, Op: "download_get_backend", Err: err}
+			}
+
+			reader, err := backend.Download(ctx, obj.ObjectKey)
+			reader = s.trackDownload(name, reader, err)
+			if err == nil {
+				source := &DownloadSource{
+					ObjectID: obj.ID,
+					Version:  obj.Version,
+					Backend:  name,
+					Fallback: i > 0 || j > 0,
+				}
+				span.set(AttrObjectID.String(obj.ID.String()), AttrBackend.String(name))
+				if source.Fallback {
+					slog.Warn("Served content download from fallback source",
+						"content_id", contentID, "object_id", obj.ID, "version", obj.Version, "backend", name)
+				}
+				return s.throttleDownload(ctx, reader), source, nil
+			}
+			if !isBlobMissing(err) {
+				return nil, nil, &ObjectError{ObjectID: obj.ID, Op: "download", Err: err}
+			}
+			slog.Warn("Object data missing from storage, trying next source",
+				"content_id", contentID, "object_id", obj.ID, "backend", name)
+		}
+	}
+
+	return nil, nil, &ContentError{
+		ContentID: contentID,
+		Op:        "download",
+		Err:       fmt.Errorf("%w: no version or replica has the data", ErrBlobNotFound),
+	}
+}
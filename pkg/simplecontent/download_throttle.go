@@ -0,0 +1,90 @@
+package simplecontent
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// WithDownloadRateLimit limits every download returned by DownloadContent, DownloadObject
+// and the methods built on them to bytesPerSec, so a single large download cannot saturate
+// the link. The limit applies to each download separately and can be changed for one call
+// with WithRequestDownloadRateLimit. Zero or a negative rate removes the limit.
+func WithDownloadRateLimit(bytesPerSec int64) Option {
+	return func(s *service) {
+		s.downloadRateLimit = max(bytesPerSec, 0)
+	}
+}
+
+type downloadRateLimitContextKey struct{}
+
+// WithRequestDownloadRateLimit returns a context whose downloads stream at bytesPerSec
+// instead of the rate set by WithDownloadRateLimit, e.g. a higher rate for premium tenants.
+// Zero or a negative rate leaves downloads made with the context unlimited.
+func WithRequestDownloadRateLimit(ctx context.Context, bytesPerSec int64) context.Context {
+	return context.WithValue(ctx, downloadRateLimitContextKey{}, max(bytesPerSec, 0))
+}
+
+// downloadRateLimitFor returns the rate downloads made with ctx are limited to
+func (s *service) downloadRateLimitFor(ctx context.Context) int64 {
+	if rate, ok := ctx.Value(downloadRateLimitContextKey{}).(int64); ok {
+		return rate
+	}
+	return s.downloadRateLimit
+}
+
+// throttleDownload wraps reader in a token bucket when downloads made with ctx are limited
+func (s *service) throttleDownload(ctx context.Context, reader io.ReadCloser) io.ReadCloser {
+	rate := s.downloadRateLimitFor(ctx)
+	if rate <= 0 {
+		return reader
+	}
+	// A tenth of a second of data may be read at once, which keeps the stream smooth
+	burst := max(float64(rate)/10, 1)
+	return &throttledReader{ReadCloser: reader, ctx: ctx, rate: float64(rate), burst: burst, tokens: burst, last: time.Now()}
+}
+
+// throttledReader is a token bucket holding up to burst bytes and refilled at rate bytes
+// per second. Reads wait until the bucket holds the bytes requested.
+type throttledReader struct {
+	io.ReadCloser
+	ctx    context.Context
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > int(r.burst) {
+		p = p[:int(r.burst)]
+	}
+	if err := r.wait(len(p)); err != nil {
+		return 0, err
+	}
+	n, err := r.ReadCloser.Read(p)
+	// Bytes requested but not returned go back into the bucket
+	r.tokens = min(r.burst, r.tokens+float64(len(p)-n))
+	return n, err
+}
+
+// wait takes n tokens from the bucket, sleeping until it has refilled enough
+func (r *throttledReader) wait(n int) error {
+	now := time.Now()
+	r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+	r.tokens -= float64(n)
+	if r.tokens >= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(-r.tokens / r.rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-r.ctx.Done():
+		r.tokens += float64(n)
+		return r.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+package simplecontent
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithMetadataEnricher adds an enricher that runs after each successful upload whose
+// content type it supports. Enrichers run synchronously, in the order they were added,
+// before the upload returns. Enrichment failures are logged and never fail the upload.
+func WithMetadataEnricher(enricher MetadataEnricher) Option {
+	return func(s *service) {
+		s.enrichers = append(s.enrichers, enricher)
+	}
+}
+
+// enrichContent runs the matching enrichers for an uploaded object and merges their
+// results into the content metadata. mimeType falls back to the object metadata and then
+// the object type when empty.
+func (s *service) enrichContent(ctx context.Context, object *Object, mimeType string) {
+	if len(s.enrichers) == 0 {
+		return
+	}
+	if mimeType == "" {
+		if objectMetadata, err := s.repository.GetObjectMetadata(ctx, object.ID); err == nil && objectMetadata != nil {
+			mimeType = objectMetadata.MimeType
+		}
+	}
+	if mimeType == "" {
+		mimeType = object.ObjectType
+	}
+
+	backend, err := s.GetBackend(object.StorageBackendName)
+	if err != nil {
+		slog.Warn("Skipping metadata enrichment", "object_id", object.ID, "error", err)
+		return
+	}
+
+	enriched := make(map[string]interface{})
+	for _, enricher := range s.enrichers {
+		if !enricher.SupportsContent(mimeType) {
+			continue
+		}
+		values, err := enricher.Enrich(ctx, object, backend)
+		if err != nil {
+			// Log warning but don't fail - the upload itself succeeded
+			slog.Warn("Metadata enricher failed", "object_id", object.ID, "mime_type", mimeType, "error", err)
+			continue
+		}
+		for k, v := range values {
+			enriched[k] = v
+		}
+	}
+	if len(enriched) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	metadata, err := s.repository.GetContentMetadata(ctx, object.ContentID)
+	if err != nil || metadata == nil {
+		metadata = &ContentMetadata{
+			ContentID: object.ContentID,
+			MimeType:  mimeType,
+			CreatedAt: now,
+		}
+	}
+	if metadata.Metadata == nil {
+		metadata.Metadata = make(map[string]interface{})
+	}
+	for k, v := range enriched {
+		metadata.Metadata[k] = v
+	}
+	metadata.UpdatedAt = now
+
+	if err := s.repository.SetContentMetadata(ctx, metadata); err != nil {
+		slog.Warn("Failed to store enriched metadata", "content_id", object.ContentID, "error", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -57,6 +58,72 @@ var (
 
 	// ErrNoUploadedObjects indicates no uploaded objects were found
 	ErrNoUploadedObjects = errors.New("no uploaded objects found")
+
+	// ErrInvalidRelationType indicates an unknown relation type or one that cannot be linked directly
+	ErrInvalidRelationType = errors.New("invalid relation type")
+
+	// ErrRelationshipNotFound indicates the content relationship does not exist
+	ErrRelationshipNotFound = errors.New("content relationship not found")
+
+	// ErrBlobNotFound indicates an object exists but its data is missing from storage
+	ErrBlobNotFound = errors.New("object data not found in storage")
+
+	// ErrHasDerivedContent indicates content cannot be deleted without cascade because derived content exists
+	ErrHasDerivedContent = errors.New("content has derived content")
+
+	// ErrStorageTimeout indicates a storage backend operation exceeded its configured timeout
+	ErrStorageTimeout = errors.New("storage operation timed out")
+
+	// ErrObjectKeyExists indicates an explicit object key is already in use on the backend
+	ErrObjectKeyExists = errors.New("object key already exists")
+
+	// ErrInvalidObjectKey indicates a supplied object key is empty or unusable
+	ErrInvalidObjectKey = errors.New("invalid object key")
+
+	// ErrContentTypeMismatch indicates uploaded data does not match the content's declared document type
+	ErrContentTypeMismatch = errors.New("content type does not match document type")
+
+	// ErrPresignNotSupported indicates the storage backend cannot issue presigned URLs
+	ErrPresignNotSupported = errors.New("presigned URLs not supported by storage backend")
+
+	// ErrUnknownDerivationType indicates a derivation type missing from the registered taxonomy
+	ErrUnknownDerivationType = errors.New("unknown derivation type")
+
+	// ErrUnknownVariant indicates a variant not registered for its derivation type
+	ErrUnknownVariant = errors.New("unknown derivation variant")
+
+	// ErrInvalidStatusTransition indicates a status change the status registry does not allow
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+	// ErrReadOnly indicates a write attempted on a service running in read-only mode
+	ErrReadOnly = errors.New("service is read-only")
+
+	// ErrURLImportDenied indicates an import URL rejected by the URL import policy
+	ErrURLImportDenied = errors.New("url import denied by policy")
+
+	// ErrURLImportTooLarge indicates an imported resource exceeding the URL import size limit
+	ErrURLImportTooLarge = errors.New("url import exceeds size limit")
+
+	// ErrURLImportFailed indicates the remote server did not return the resource
+	ErrURLImportFailed = errors.New("url import fetch failed")
+
+	// ErrInvalidDisposition indicates a content disposition other than attachment or inline
+	ErrInvalidDisposition = errors.New("invalid content disposition")
+
+	// ErrPreconditionFailed indicates a conditional write whose object no longer has the expected ETag
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrInvalidMetadata indicates metadata rejected by the configured metadata validator
+	ErrInvalidMetadata = errors.New("invalid metadata")
+
+	// ErrInvalidField indicates a request field that failed validation, reported as a FieldError
+	ErrInvalidField = errors.New("invalid field")
+
+	// ErrAppendNotSupported indicates the storage backend cannot append to a stored object
+	ErrAppendNotSupported = errors.New("append not supported by storage backend")
+
+	// ErrVariantExists indicates the parent already has live derived content of the variant
+	ErrVariantExists = errors.New("derived variant already exists")
 )
 
 // ContentError represents an error related to content operations
@@ -103,6 +170,34 @@ func (e *ContentError) HTTPStatus() int {
 		return http.StatusNotFound
 	case errors.Is(e.Err, ErrNoUploadedObjects):
 		return http.StatusNotFound
+	case errors.Is(e.Err, ErrInvalidRelationType):
+		return http.StatusBadRequest
+	case errors.Is(e.Err, ErrRelationshipNotFound):
+		return http.StatusNotFound
+	case errors.Is(e.Err, ErrHasDerivedContent):
+		return http.StatusConflict
+	case errors.Is(e.Err, ErrContentTypeMismatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(e.Err, ErrStorageBackendNotFound):
+		return http.StatusBadRequest
+	case errors.Is(e.Err, ErrUnknownDerivationType):
+		return http.StatusBadRequest
+	case errors.Is(e.Err, ErrUnknownVariant):
+		return http.StatusBadRequest
+	case errors.Is(e.Err, ErrInvalidStatusTransition):
+		return http.StatusConflict
+	case errors.Is(e.Err, ErrInvalidDisposition):
+		return http.StatusBadRequest
+	case errors.Is(e.Err, ErrURLImportDenied):
+		return http.StatusForbidden
+	case errors.Is(e.Err, ErrURLImportTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(e.Err, ErrURLImportFailed):
+		return http.StatusBadGateway
+	case errors.Is(e.Err, ErrInvalidField):
+		return http.StatusBadRequest
+	case errors.Is(e.Err, ErrVariantExists):
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
@@ -138,8 +233,28 @@ func (e *ObjectError) HTTPStatus() int {
 		return http.StatusNotFound
 	case errors.Is(e.Err, ErrInvalidObjectStatus):
 		return http.StatusBadRequest
+	case errors.Is(e.Err, ErrInvalidStatusTransition):
+		return http.StatusConflict
 	case errors.Is(e.Err, ErrObjectNotReady):
 		return http.StatusConflict
+	case errors.Is(e.Err, ErrBlobNotFound):
+		return http.StatusConflict
+	case errors.Is(e.Err, ErrObjectKeyExists):
+		return http.StatusConflict
+	case errors.Is(e.Err, ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(e.Err, ErrInvalidMetadata):
+		return http.StatusBadRequest
+	case errors.Is(e.Err, ErrInvalidObjectKey):
+		return http.StatusBadRequest
+	case errors.Is(e.Err, ErrContentTypeMismatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(e.Err, ErrPresignNotSupported):
+		return http.StatusNotImplemented
+	case errors.Is(e.Err, ErrAppendNotSupported):
+		return http.StatusNotImplemented
+	case errors.Is(e.Err, ErrStorageBackendNotFound):
+		return http.StatusBadRequest
 	case errors.Is(e.Err, ErrUploadFailed):
 		return http.StatusInternalServerError
 	case errors.Is(e.Err, ErrDownloadFailed):
@@ -149,6 +264,39 @@ func (e *ObjectError) HTTPStatus() int {
 	}
 }
 
+// BackendNotFoundError is returned when a request names a storage backend that is not
+// registered. It wraps ErrStorageBackendNotFound.
+type BackendNotFoundError struct {
+	Name      string
+	Available []string
+}
+
+func (e *BackendNotFoundError) Error() string {
+	if len(e.Available) == 0 {
+		return fmt.Sprintf("%v: %s (no backends registered)", ErrStorageBackendNotFound, e.Name)
+	}
+	return fmt.Sprintf("%v: %s (available: %s)", ErrStorageBackendNotFound, e.Name, strings.Join(e.Available, ", "))
+}
+
+func (e *BackendNotFoundError) Unwrap() error {
+	return ErrStorageBackendNotFound
+}
+
+// FieldError is returned when a request field fails validation, e.g. a content name that
+// is too long. It wraps ErrInvalidField.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v %s: %s", ErrInvalidField, e.Field, e.Reason)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidField
+}
+
 // StorageError represents an error related to storage operations
 type StorageError struct {
 	Backend string
@@ -178,6 +326,8 @@ func (e *StorageError) HTTPStatus() int {
 	switch {
 	case errors.Is(e.Err, ErrStorageBackendNotFound):
 		return http.StatusNotFound
+	case errors.Is(e.Err, ErrStorageTimeout):
+		return http.StatusGatewayTimeout
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,118 @@
+package simplecontent
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// EventSchemaVersion is the version of ContentUpdatedEvent, carried in its schema_version
+// field. It is raised when the payload changes in a way consumers must handle, such as a
+// renamed or removed field; added fields keep the version. The other EventSink methods take
+// the domain types directly and carry no version.
+const EventSchemaVersion = 1
+
+// FieldChange is the value of a content field before and after an update
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ContentUpdatedEvent is the payload of a content update. Changes holds only the fields the
+// update changed, keyed by their JSON names, e.g. "name" or "status"; it is empty when the
+// update changed no field. When the content could not be read before the update,
+// ChangesUnavailable is set and Changes is nil, so consumers must not read it as "nothing
+// changed".
+type ContentUpdatedEvent struct {
+	SchemaVersion      int                    `json:"schema_version"`
+	Content            *Content               `json:"content"`
+	Changes            map[string]FieldChange `json:"changes"`
+	ChangesUnavailable bool                   `json:"changes_unavailable,omitempty"`
+}
+
+// Changed reports whether the update changed the named field
+func (e *ContentUpdatedEvent) Changed(field string) bool {
+	_, ok := e.Changes[field]
+	return ok
+}
+
+// ContentUpdateSink is implemented by event sinks that want to know what an update changed,
+// e.g. to react only to status changes. The service calls ContentUpdatedWithChanges instead
+// of ContentUpdated on such sinks.
+type ContentUpdateSink interface {
+	ContentUpdatedWithChanges(ctx context.Context, event *ContentUpdatedEvent) error
+}
+
+// contentChanges returns the fields that differ between before and after, keyed by their
+// JSON names. Timestamps are left out; they change on every update.
+func contentChanges(before, after *Content) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	diffUUID := func(field string, old, new uuid.UUID) {
+		if old != new {
+			changes[field] = FieldChange{Old: old, New: new}
+		}
+	}
+	diffString := func(field, old, new string) {
+		if old != new {
+			changes[field] = FieldChange{Old: old, New: new}
+		}
+	}
+	diffUUID("tenant_id", before.TenantID, after.TenantID)
+	diffUUID("owner_id", before.OwnerID, after.OwnerID)
+	diffString("owner_type", before.OwnerType, after.OwnerType)
+	diffString("name", before.Name, after.Name)
+	diffString("description", before.Description, after.Description)
+	diffString("document_type", before.DocumentType, after.DocumentType)
+	diffString("status", before.Status, after.Status)
+	diffString("derivation_type", before.DerivationType, after.DerivationType)
+	return changes
+}
+
+// wantsContentChanges reports whether the event sink takes content update diffs, so the
+// content is read before it is updated
+func (s *service) wantsContentChanges() bool {
+	_, ok := s.eventSink.(ContentUpdateSink)
+	return ok
+}
+
+// emitContentUpdated fires the content update event, with the changes since before when
+// the sink is a ContentUpdateSink. A nil before marks the changes as unavailable. Errors are
+// logged; they do not fail the update.
+func (s *service) emitContentUpdated(ctx context.Context, before, after *Content) {
+	if s.eventSink == nil {
+		return
+	}
+	var err error
+	if sink, ok := s.eventSink.(ContentUpdateSink); ok {
+		event := &ContentUpdatedEvent{SchemaVersion: EventSchemaVersion, Content: after}
+		if before != nil {
+			event.Changes = contentChanges(before, after)
+		} else {
+			event.ChangesUnavailable = true
+		}
+		err = sink.ContentUpdatedWithChanges(ctx, event)
+	} else {
+		err = s.eventSink.ContentUpdated(ctx, after)
+	}
+	if err != nil {
+		slog.Error("Failed to emit ContentUpdated event", "content_id", after.ID, "error", err)
+	}
+}
+
+// changedFields returns the names of the changed fields in sorted order, or "unknown" when
+// the changes are unavailable
+func changedFields(event *ContentUpdatedEvent) string {
+	if event.ChangesUnavailable {
+		return "unknown"
+	}
+	changes := event.Changes
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ",")
+}
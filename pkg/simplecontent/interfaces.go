@@ -25,7 +25,7 @@ type BlobStore interface {
 	// GetPreviewURL returns a URL for previewing content
 	GetPreviewURL(ctx context.Context, objectKey string) (string, error)
 
-	// Download downloads content directly
+	// Download downloads content directly. Returns ErrObjectNotFound when the object does not exist.
 	Download(ctx context.Context, objectKey string) (io.ReadCloser, error)
 
 	// Delete deletes content
@@ -35,20 +35,92 @@ type BlobStore interface {
 	GetObjectMeta(ctx context.Context, objectKey string) (*ObjectMeta, error)
 }
 
+// UploadOffsetReporter is implemented by storage backends that can report how many bytes
+// of an object are already stored, including data left by an unfinished upload
+type UploadOffsetReporter interface {
+	// GetUploadOffset returns the number of bytes stored for objectKey, or 0 if there are none
+	GetUploadOffset(ctx context.Context, objectKey string) (int64, error)
+}
+
+// ObjectExistsChecker is implemented by storage backends that can check for an object
+// without fetching its metadata. Other backends are checked with GetObjectMeta.
+type ObjectExistsChecker interface {
+	// ObjectExists reports whether objectKey is stored
+	ObjectExists(ctx context.Context, objectKey string) (bool, error)
+}
+
+// ObjectMover is implemented by storage backends that can move an object to a new key
+// without streaming it through the service. Other backends are moved by copying the data
+// and deleting the source.
+type ObjectMover interface {
+	// MoveObject moves srcKey to dstKey. It returns ErrObjectNotFound when srcKey is not
+	// stored and ErrObjectKeyExists when dstKey is already taken.
+	MoveObject(ctx context.Context, srcKey, dstKey string) error
+}
+
+// ObjectCopier is implemented by storage backends that can copy an object to a new key
+// without streaming it through the service, as S3 does with a server-side copy. Other
+// backends are copied by downloading and uploading the data again.
+type ObjectCopier interface {
+	// CopyObject copies srcKey to dstKey and keeps srcKey. It returns ErrObjectNotFound
+	// when srcKey is not stored.
+	CopyObject(ctx context.Context, srcKey, dstKey string) error
+}
+
+// ObjectAppender is implemented by storage backends that can add data to the end of a
+// stored object, such as fs. Other backends cannot append, see ErrAppendNotSupported.
+type ObjectAppender interface {
+	// AppendObject writes the data of reader after the end of objectKey, creating the
+	// object when it is not stored yet
+	AppendObject(ctx context.Context, objectKey string, reader io.Reader) error
+}
+
+// BackendCapabilities lists the direct-access URLs a storage backend can issue
+type BackendCapabilities struct {
+	// PresignedUpload is true when GetUploadURL returns a URL clients can upload to
+	PresignedUpload bool
+	// PresignedDownload is true when GetDownloadURL and GetPreviewURL return URLs clients can read from
+	PresignedDownload bool
+}
+
+// PresignCapable is implemented by storage backends that report whether they can issue
+// presigned URLs. Backends that do not implement it are assumed to support them.
+type PresignCapable interface {
+	Capabilities() BackendCapabilities
+}
+
+// Pinger is implemented by storage backends that can check they are reachable, e.g. that
+// their bucket or base directory is accessible. See PingBackend.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
 // Repository defines the interface for content and object persistence
 type Repository interface {
 	// Content operations
 	CreateContent(ctx context.Context, content *Content) error
 	GetContent(ctx context.Context, id uuid.UUID) (*Content, error)
+	// ContentExists reports whether non-deleted content exists without loading it
+	ContentExists(ctx context.Context, id uuid.UUID) (bool, error)
 	GetContentsByIDs(ctx context.Context, ids []uuid.UUID) ([]*Content, error)
 	UpdateContent(ctx context.Context, content *Content) error
 	DeleteContent(ctx context.Context, id uuid.UUID) error
+	// DeleteContentTree soft-deletes the given contents, their objects and their derived
+	// relationships atomically: either all of them are deleted or none are
+	DeleteContentTree(ctx context.Context, contentIDs []uuid.UUID) error
 	ListContent(ctx context.Context, ownerID, tenantID uuid.UUID) ([]*Content, error)
 	
 	// Content metadata operations
 	SetContentMetadata(ctx context.Context, metadata *ContentMetadata) error
 	GetContentMetadata(ctx context.Context, contentID uuid.UUID) (*ContentMetadata, error)
 	GetContentMetadataByContentIDs(ctx context.Context, contentIDs []uuid.UUID) (map[uuid.UUID]*ContentMetadata, error)
+	// ListContentMetadataHistory returns every version written by SetContentMetadata, oldest first.
+	// SetContentMetadata records the actor from the context (see WithActor) on each version.
+	ListContentMetadataHistory(ctx context.Context, contentID uuid.UUID) ([]*ContentMetadataVersion, error)
+	// UpdateContentTags adds and removes tags on the metadata of many contents atomically,
+	// recording a metadata version for each content whose tags changed. Content without
+	// metadata gets a record holding only the tags. Returns the number of contents changed.
+	UpdateContentTags(ctx context.Context, contentIDs []uuid.UUID, add, remove []string) (int64, error)
 
 	// Status query operations
 	GetContentByStatus(ctx context.Context, status string) ([]*Content, error)
@@ -57,14 +129,27 @@ type Repository interface {
     // Derived content operations
     CreateDerivedContentRelationship(ctx context.Context, params CreateDerivedContentParams) (*DerivedContent, error)
     ListDerivedContent(ctx context.Context, params ListDerivedContentParams) ([]*DerivedContent, error)
-    // GetDerivedRelationshipByContentID returns the derived-content relationship for a given derived content ID
+    // ListDerivedContentWithDetails lists derived relationships joined with their (non-deleted) child content
+    ListDerivedContentWithDetails(ctx context.Context, params ListDerivedContentParams) ([]*DerivedContentWithDetails, error)
+    // GetDerivedRelationshipByContentID returns the derived-content relationship for a given derived content ID.
+    // It returns an error wrapping ErrRelationshipNotFound when the content is not derived.
     GetDerivedRelationshipByContentID(ctx context.Context, contentID uuid.UUID) (*DerivedContent, error)
+    // UpdateDerivedContentRelationship updates the mutable fields of a derived-content relationship
+    UpdateDerivedContentRelationship(ctx context.Context, params UpdateDerivedContentParams) error
+
+	// Typed content relationship operations (attachments, references, ...)
+	CreateContentRelationship(ctx context.Context, relationship *ContentRelationship) error
+	DeleteContentRelationship(ctx context.Context, fromContentID, toContentID uuid.UUID, relType RelationType) error
+	ListContentRelationships(ctx context.Context, params ListContentRelationshipsParams) ([]*ContentRelationship, error)
 
 	// Object operations
 	CreateObject(ctx context.Context, object *Object) error
 	GetObject(ctx context.Context, id uuid.UUID) (*Object, error)
+	// ObjectExists reports whether a non-deleted object exists without loading it
+	ObjectExists(ctx context.Context, id uuid.UUID) (bool, error)
 	GetObjectsByContentID(ctx context.Context, contentID uuid.UUID) ([]*Object, error)
 	GetObjectsByContentIDs(ctx context.Context, contentIDs []uuid.UUID) (map[uuid.UUID][]*Object, error)
+	ListObjects(ctx context.Context, filters ObjectListFilters) ([]*Object, error)
 	GetObjectByObjectKeyAndStorageBackendName(ctx context.Context, objectKey, storageBackendName string) (*Object, error)
 	UpdateObject(ctx context.Context, object *Object) error
 	DeleteObject(ctx context.Context, id uuid.UUID) error
@@ -73,11 +158,38 @@ type Repository interface {
 	SetObjectMetadata(ctx context.Context, metadata *ObjectMetadata) error
 	GetObjectMetadata(ctx context.Context, objectID uuid.UUID) (*ObjectMetadata, error)
 	GetObjectMetadataByObjectIDs(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]*ObjectMetadata, error)
+	// GetUploadStatusesByObjectIDs returns the status, stored size and last update of many
+	// objects in one query. Missing and deleted objects are left out of the map.
+	GetUploadStatusesByObjectIDs(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]*UploadStatus, error)
+	// GetContentStorageStats counts the non-deleted objects of a content and of its derived
+	// contents at any depth, and sums their stored sizes, without loading the objects.
+	// Returns ErrContentNotFound for missing or deleted content.
+	GetContentStorageStats(ctx context.Context, contentID uuid.UUID) (objectCount int, totalBytes int64, err error)
 
 	// Admin operations - for administrative tasks without owner/tenant restrictions
 	ListContentWithFilters(ctx context.Context, filters ContentListFilters) ([]*Content, error)
 	CountContentWithFilters(ctx context.Context, filters ContentCountFilters) (int64, error)
 	GetContentStatistics(ctx context.Context, filters ContentCountFilters, options ContentStatisticsOptions) (*ContentStatisticsResult, error)
+
+	// Access audit operations
+	CreateAccessEvent(ctx context.Context, event *AccessEvent) error
+	// ListAccessEvents returns the access events for a content, oldest first
+	ListAccessEvents(ctx context.Context, contentID uuid.UUID) ([]*AccessEvent, error)
+
+	// Upload intent operations
+	// CreateUploadIntent records an upload intent, replacing any intent for the same object
+	CreateUploadIntent(ctx context.Context, intent *UploadIntent) error
+	// DeleteUploadIntent removes the intent for an object; a missing intent is not an error
+	DeleteUploadIntent(ctx context.Context, objectID uuid.UUID) error
+	// ListUploadIntents returns the intents created before the given time, oldest first
+	ListUploadIntents(ctx context.Context, createdBefore time.Time) ([]*UploadIntent, error)
+
+	// Advisory locks
+	// AcquireLock blocks until the caller holds the lock named key or ctx is done. The lock
+	// is held until unlock is called or ctx is done, so it lasts exactly as long as its
+	// holder and a holder that goes away cannot block other callers forever. Calling unlock
+	// more than once is safe.
+	AcquireLock(ctx context.Context, key string) (unlock func(), err error)
 }
 
 // EventSink defines the interface for event handling
@@ -105,6 +217,15 @@ type EventSink interface {
 
 	// ObjectStatusChanged is fired when object status changes
 	ObjectStatusChanged(ctx context.Context, objectID uuid.UUID, oldStatus, newStatus string) error
+
+	// ContentDownloaded is fired when content data starts streaming to a client
+	ContentDownloaded(ctx context.Context, event *AccessEvent) error
+
+	// ContentPreviewed is fired when a content preview starts streaming to a client
+	ContentPreviewed(ctx context.Context, event *AccessEvent) error
+
+	// ObjectAnnotated is fired when annotations are merged into an object's metadata
+	ObjectAnnotated(ctx context.Context, object *Object, annotations map[string]interface{}) error
 }
 
 // Previewer defines the interface for content preview generation
@@ -116,6 +237,16 @@ type Previewer interface {
 	SupportsContent(mimeType string) bool
 }
 
+// MetadataEnricher extracts metadata from uploaded data, such as image dimensions or video
+// duration. The service merges the returned values into the content metadata after an upload.
+type MetadataEnricher interface {
+	// Enrich reads the object from the blob store and returns the metadata to store
+	Enrich(ctx context.Context, object *Object, blobStore BlobStore) (map[string]interface{}, error)
+
+	// SupportsContent returns true if the enricher handles the given content type
+	SupportsContent(mimeType string) bool
+}
+
 // ObjectMeta contains metadata about an object in storage
 type ObjectMeta struct {
 	Key         string
@@ -130,6 +261,9 @@ type ObjectMeta struct {
 type UploadParams struct {
 	ObjectKey string
 	MimeType  string
+	// IfMatch is the backend ETag the stored object must still have. Backends with conditional
+	// writes reject a mismatch with ErrPreconditionFailed; others ignore it.
+	IfMatch string
 }
 
 // CreateDerivedContentParams contains parameters for creating derived content relationships
@@ -142,6 +276,18 @@ type CreateDerivedContentParams struct {
 	ProcessingMetadata map[string]interface{}
 }
 
+// UpdateDerivedContentParams contains parameters for updating derived content relationships
+type UpdateDerivedContentParams struct {
+	DerivedContentID   uuid.UUID
+	ProcessingMetadata map[string]interface{}
+}
+
+// ListContentRelationshipsParams contains parameters for listing typed content relationships
+type ListContentRelationshipsParams struct {
+	FromContentID uuid.UUID
+	RelationType  RelationType // Empty matches all types
+}
+
 // ListDerivedContentParams contains parameters for listing derived content
 type ListDerivedContentParams struct {
 	// Existing fields (no breaking changes)
@@ -157,6 +303,7 @@ type ListDerivedContentParams struct {
 	Variants         []string             `json:"variants,omitempty"`
 	TypeVariantPairs []TypeVariantPair    `json:"type_variant_pairs,omitempty"`
 	ContentStatus    *string              `json:"content_status,omitempty"`
+	DerivedStatuses  []DerivationStatus   `json:"derived_statuses,omitempty"`
 	CreatedAfter     *time.Time           `json:"created_after,omitempty"`
 	CreatedBefore    *time.Time           `json:"created_before,omitempty"`
 	SortBy           *string              `json:"sort_by,omitempty"`
@@ -228,6 +375,14 @@ func WithContentStatus(status string) ListDerivedContentOption {
 	}
 }
 
+// WithDerivedStatus filters derived content by processing status
+// (e.g., only completed thumbnails). Multiple statuses are OR-ed together.
+func WithDerivedStatus(statuses ...DerivationStatus) ListDerivedContentOption {
+	return func(p *ListDerivedContentParams) {
+		p.DerivedStatuses = statuses
+	}
+}
+
 // WithCreatedAfter sets the created after time filter
 func WithCreatedAfter(t time.Time) ListDerivedContentOption {
 	return func(p *ListDerivedContentParams) {
@@ -0,0 +1,38 @@
+package simplecontent
+
+import (
+	"context"
+	"strings"
+)
+
+// MetaOriginalObjectKey is the object metadata key holding an explicit object key as it
+// was requested, before WithKeyCaseNormalization lowercased it
+const MetaOriginalObjectKey = "original_object_key"
+
+// WithKeyCaseNormalization lowercases every object key the service stores or looks up, so
+// keys differing only in case, such as "File.JPG" and "file.jpg", name the same object
+// on every backend instead of colliding on case-insensitive filesystems and object stores
+// only. Keys are lowercased after the WithObjectKeySanitizer sanitizer. The casing of an
+// explicit CreateObjectRequest.ObjectKey is kept under MetaOriginalObjectKey.
+//
+// Enable it before objects are stored: existing keys with upper case letters are not
+// renamed and can no longer be looked up by key.
+func WithKeyCaseNormalization() Option {
+	return func(s *service) {
+		s.normalizeKeyCase = true
+	}
+}
+
+// foldKeyCase lowercases key when key case normalization is enabled
+func (s *service) foldKeyCase(key string) string {
+	if !s.normalizeKeyCase {
+		return key
+	}
+	return strings.ToLower(key)
+}
+
+// GetObjectByKey returns the object stored under objectKey on the named backend. The key
+// is sanitized and, with WithKeyCaseNormalization, lowercased the way keys are on write.
+func (s *service) GetObjectByKey(ctx context.Context, storageBackendName, objectKey string) (*Object, error) {
+	return s.repository.GetObjectByObjectKeyAndStorageBackendName(ctx, s.sanitizeObjectKey(objectKey), storageBackendName)
+}
@@ -0,0 +1,64 @@
+package simplecontent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/tendant/simple-content/pkg/simplecontent/objectkey"
+)
+
+// WithKeyTemplate generates object keys from a text/template over objectkey.TemplateData,
+// e.g. "{{.Year}}/{{.DocumentType}}/{{.FileName}}" gives "2024/invoices/acme-2024-03.pdf".
+// The template is rendered when an object is created and replaces the key generator for
+// objects without an explicit key. Field values are sanitized to single path segments, and
+// a key already in use gets a short unique suffix, as with CollisionPolicyAutoRename.
+// New fails when the template does not parse.
+func WithKeyTemplate(text string) Option {
+	return func(s *service) {
+		s.keyTemplateText = text
+	}
+}
+
+// prepareKeyTemplate parses the template set with WithKeyTemplate
+func (s *service) prepareKeyTemplate() error {
+	if s.keyTemplateText == "" {
+		return nil
+	}
+	generator, err := objectkey.NewTemplateGenerator(s.keyTemplateText)
+	if err != nil {
+		return err
+	}
+	s.keyTemplate = generator
+	return nil
+}
+
+// templateObjectKey renders the key template for a new object of content and makes the key
+// unique on the backend. variant is looked up for derived content when not known.
+func (s *service) templateObjectKey(ctx context.Context, backend BlobStore, backendName string, content *Content, objectID uuid.UUID, fileName, variant string) (string, error) {
+	keyMetadata := &objectkey.KeyMetadata{
+		FileName:       fileName,
+		ContentType:    content.DocumentType,
+		TenantID:       content.TenantID.String(),
+		OwnerID:        content.OwnerID.String(),
+		IsOriginal:     content.DerivationType == "",
+		DerivationType: content.DerivationType,
+		Variant:        variant,
+		Name:           content.Name,
+		DocumentType:   content.DocumentType,
+		CreatedAt:      content.CreatedAt,
+	}
+	if !keyMetadata.IsOriginal && variant == "" {
+		if derived, err := s.repository.GetDerivedRelationshipByContentID(ctx, content.ID); err == nil && derived != nil {
+			keyMetadata.Variant = derived.Variant
+			keyMetadata.ParentContentID = derived.ParentID
+		}
+	}
+
+	objectKey := s.sanitizeObjectKey(s.keyTemplate.GenerateKey(content.ID, objectID, keyMetadata))
+	objectKey, err := s.resolveObjectKeyCollision(ctx, backend, backendName, objectKey, CollisionPolicyAutoRename)
+	if err != nil {
+		return "", fmt.Errorf("key template: %w", err)
+	}
+	return objectKey, nil
+}
@@ -0,0 +1,51 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// GetContentLineage returns the ancestors of a derived content, from its immediate parent
+// up to the root original. An original has an empty lineage.
+//
+// The chain is followed through derived relationships like the derivation depth check,
+// and stops after maxDerivationWalk steps or when a content repeats, so a corrupted cycle
+// cannot loop forever; the ancestors found up to that point are returned. Repository
+// errors other than a missing relationship are returned rather than read as the root.
+func (s *service) GetContentLineage(ctx context.Context, contentID uuid.UUID) ([]*Content, error) {
+	if err := s.requireContent(ctx, contentID); err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "get_lineage", Err: err}
+	}
+
+	lineage := []*Content{}
+	visited := map[uuid.UUID]bool{contentID: true}
+	current := contentID
+	for len(lineage) < maxDerivationWalk {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		derived, err := s.repository.GetDerivedRelationshipByContentID(ctx, current)
+		if errors.Is(err, ErrRelationshipNotFound) {
+			// Not derived: current is the root original
+			return lineage, nil
+		}
+		if err != nil {
+			return nil, &ContentError{ContentID: current, Op: "get_lineage", Err: err}
+		}
+		if visited[derived.ParentID] {
+			slog.Warn("Derivation cycle in content lineage", "content_id", contentID, "parent_id", derived.ParentID)
+			return lineage, nil
+		}
+		parent, err := s.repository.GetContent(ctx, derived.ParentID)
+		if err != nil {
+			return nil, &ContentError{ContentID: derived.ParentID, Op: "get_lineage_parent", Err: err}
+		}
+		visited[parent.ID] = true
+		lineage = append(lineage, parent)
+		current = parent.ID
+	}
+	return lineage, nil
+}
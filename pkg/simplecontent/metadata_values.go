@@ -0,0 +1,112 @@
+package simplecontent
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Metadata values are stored as JSON, so a value read back is not always the Go type it was
+// written as: integers come back as float64 and times as RFC 3339 strings. The accessors
+// below convert those representations back, sparing callers the type assertions.
+
+// GetString returns the string metadata value of key. ok is false when the key is missing
+// or holds another type.
+func (m *ContentMetadata) GetString(key string) (string, bool) {
+	value, ok := m.Metadata[key].(string)
+	return value, ok
+}
+
+// GetInt returns the integer metadata value of key, whether it is held as a Go integer, a
+// whole float64 as decoded from JSON, or a json.Number. ok is false when the key is missing
+// or the value is not a whole number that fits an int.
+func (m *ContentMetadata) GetInt(key string) (int, bool) {
+	return metadataInt(m.Metadata[key])
+}
+
+// GetTime returns the time metadata value of key, held as a time.Time or as the RFC 3339
+// string JSON encodes it to. ok is false when the key is missing or does not hold a time.
+func (m *ContentMetadata) GetTime(key string) (time.Time, bool) {
+	switch value := m.Metadata[key].(type) {
+	case time.Time:
+		return value, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// DecodeMetadata unmarshals the metadata map into v, a pointer to a struct whose json tags
+// name the metadata keys, the same way encoding/json decodes an object
+func (m *ContentMetadata) DecodeMetadata(v interface{}) error {
+	data, err := json.Marshal(m.Metadata)
+	if err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode metadata: %w", err)
+	}
+	return nil
+}
+
+// EncodeMetadata marshals v, a struct or map, to JSON and merges its fields into the
+// metadata map. Keys v does not produce are kept.
+func (m *ContentMetadata) EncodeMetadata(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+	if m.Metadata == nil {
+		m.Metadata = make(map[string]interface{}, len(fields))
+	}
+	for key, value := range fields {
+		m.Metadata[key] = value
+	}
+	return nil
+}
+
+// metadataInt converts the representations an integer metadata value may have to int
+func metadataInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), v >= math.MinInt && v <= math.MaxInt
+	case uint:
+		return int(v), v <= math.MaxInt
+	case uint8:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		return int(v), uint64(v) <= math.MaxInt
+	case uint64:
+		return int(v), v <= math.MaxInt
+	case float32:
+		return metadataInt(float64(v))
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt || v >= math.MaxInt {
+			return 0, false
+		}
+		return int(v), true
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return 0, false
+		}
+		return metadataInt(i)
+	}
+	return 0, false
+}
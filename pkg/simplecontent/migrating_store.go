@@ -0,0 +1,190 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// MigratingBlobStore moves objects from an old backend to a new one as they are read, e.g.
+// when replacing fs storage with S3. Writes go to the primary (new) backend. A read that
+// misses the primary copies the object from the source (old) backend, so it is served from
+// the primary from then on. Objects that are never read stay on the source.
+//
+// Two concurrent first reads of an object may both copy it; the copies are identical.
+type MigratingBlobStore struct {
+	BlobStore // primary
+	source    BlobStore
+
+	migrated      atomic.Int64
+	migratedBytes atomic.Int64
+	failed        atomic.Int64
+}
+
+// MigrationProgress counts the objects a MigratingBlobStore has copied so far
+type MigrationProgress struct {
+	Migrated      int64 `json:"migrated"`       // objects copied to the primary
+	MigratedBytes int64 `json:"migrated_bytes"` // bytes copied to the primary
+	Failed        int64 `json:"failed"`         // copies that failed; the read was served from the source
+}
+
+// NewMigratingBlobStore returns a store serving primary and migrating missing objects from
+// source on first read
+func NewMigratingBlobStore(primary, source BlobStore) *MigratingBlobStore {
+	return &MigratingBlobStore{BlobStore: primary, source: source}
+}
+
+// Progress returns the migration counters since the store was created
+func (m *MigratingBlobStore) Progress() MigrationProgress {
+	return MigrationProgress{
+		Migrated:      m.migrated.Load(),
+		MigratedBytes: m.migratedBytes.Load(),
+		Failed:        m.failed.Load(),
+	}
+}
+
+// migrate copies objectKey from the source to the primary. It returns ErrObjectNotFound
+// when the source does not have it either.
+func (m *MigratingBlobStore) migrate(ctx context.Context, objectKey string) error {
+	reader, err := m.source.Download(ctx, objectKey)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	params := UploadParams{ObjectKey: objectKey}
+	if meta, err := m.source.GetObjectMeta(ctx, objectKey); err == nil {
+		params.MimeType = meta.ContentType
+	}
+	counter := &countingReader{reader: reader}
+	if err := m.BlobStore.UploadWithParams(ctx, counter, params); err != nil {
+		m.failed.Add(1)
+		return fmt.Errorf("migrate %s: %w", objectKey, err)
+	}
+	m.migrated.Add(1)
+	m.migratedBytes.Add(counter.count.Load())
+	return nil
+}
+
+// ensureMigrated copies objectKey to the primary unless it is there already. Keys the
+// source does not have are left for the primary to report.
+func (m *MigratingBlobStore) ensureMigrated(ctx context.Context, objectKey string) error {
+	exists, err := blobExists(ctx, m.BlobStore, objectKey)
+	if err != nil || exists {
+		return err
+	}
+	if err := m.migrate(ctx, objectKey); err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Download serves the primary's data, migrating the object first when only the source has
+// it. When the copy fails the read is served from the source.
+func (m *MigratingBlobStore) Download(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	reader, err := m.BlobStore.Download(ctx, objectKey)
+	if !errors.Is(err, ErrObjectNotFound) {
+		return reader, err
+	}
+	if err := m.migrate(ctx, objectKey); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, err
+		}
+		return m.source.Download(ctx, objectKey)
+	}
+	return m.BlobStore.Download(ctx, objectKey)
+}
+
+// GetObjectMeta reports the source's metadata for objects not migrated yet
+func (m *MigratingBlobStore) GetObjectMeta(ctx context.Context, objectKey string) (*ObjectMeta, error) {
+	meta, err := m.BlobStore.GetObjectMeta(ctx, objectKey)
+	if errors.Is(err, ErrObjectNotFound) {
+		return m.source.GetObjectMeta(ctx, objectKey)
+	}
+	return meta, err
+}
+
+func (m *MigratingBlobStore) ObjectExists(ctx context.Context, objectKey string) (bool, error) {
+	exists, err := blobExists(ctx, m.BlobStore, objectKey)
+	if err != nil || exists {
+		return exists, err
+	}
+	return blobExists(ctx, m.source, objectKey)
+}
+
+// GetDownloadURL migrates the object first, since the URL points at the primary
+func (m *MigratingBlobStore) GetDownloadURL(ctx context.Context, objectKey string, downloadFilename string) (string, error) {
+	if err := m.ensureMigrated(ctx, objectKey); err != nil {
+		return "", err
+	}
+	return m.BlobStore.GetDownloadURL(ctx, objectKey, downloadFilename)
+}
+
+// GetDownloadURLWithDisposition migrates the object first, since the URL points at the primary
+func (m *MigratingBlobStore) GetDownloadURLWithDisposition(ctx context.Context, objectKey, disposition, downloadFilename string) (string, error) {
+	if err := m.ensureMigrated(ctx, objectKey); err != nil {
+		return "", err
+	}
+	return blobDownloadURLWithDisposition(ctx, m.BlobStore, objectKey, disposition, downloadFilename)
+}
+
+// GetPreviewURL migrates the object first, since the URL points at the primary
+func (m *MigratingBlobStore) GetPreviewURL(ctx context.Context, objectKey string) (string, error) {
+	if err := m.ensureMigrated(ctx, objectKey); err != nil {
+		return "", err
+	}
+	return m.BlobStore.GetPreviewURL(ctx, objectKey)
+}
+
+// Delete removes the object from both backends, so a later read cannot bring it back from
+// the source
+func (m *MigratingBlobStore) Delete(ctx context.Context, objectKey string) error {
+	inSource, err := blobExists(ctx, m.source, objectKey)
+	if err != nil {
+		return err
+	}
+	if inSource {
+		if err := m.source.Delete(ctx, objectKey); err != nil {
+			return err
+		}
+		if inPrimary, err := blobExists(ctx, m.BlobStore, objectKey); err != nil || !inPrimary {
+			return err
+		}
+	}
+	return m.BlobStore.Delete(ctx, objectKey)
+}
+
+func (m *MigratingBlobStore) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	if err := m.ensureMigrated(ctx, srcKey); err != nil {
+		return err
+	}
+	return blobMove(ctx, m.BlobStore, srcKey, dstKey)
+}
+
+func (m *MigratingBlobStore) AppendObject(ctx context.Context, objectKey string, reader io.Reader) error {
+	if err := m.ensureMigrated(ctx, objectKey); err != nil {
+		return err
+	}
+	return blobAppend(ctx, m.BlobStore, objectKey, reader)
+}
+
+func (m *MigratingBlobStore) GetUploadOffset(ctx context.Context, objectKey string) (int64, error) {
+	return blobUploadOffset(ctx, m.BlobStore, objectKey)
+}
+
+func (m *MigratingBlobStore) Capabilities() BackendCapabilities {
+	return GetBackendCapabilities(m.BlobStore)
+}
+
+// Ping checks both backends, since reads that miss the primary still need the source
+func (m *MigratingBlobStore) Ping(ctx context.Context) error {
+	if err := PingBackend(ctx, m.BlobStore); err != nil {
+		return err
+	}
+	if err := PingBackend(ctx, m.source); err != nil {
+		return fmt.Errorf("migration source: %w", err)
+	}
+	return nil
+}
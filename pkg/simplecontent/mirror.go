@@ -0,0 +1,171 @@
+package simplecontent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MirrorFailurePolicy decides what happens to an upload when the mirror write fails
+type MirrorFailurePolicy string
+
+const (
+	// MirrorFailurePolicyFail fails the upload and removes the data written to the primary backend
+	MirrorFailurePolicyFail MirrorFailurePolicy = "fail"
+	// MirrorFailurePolicyWarn logs the failure and keeps the upload on the primary backend only
+	MirrorFailurePolicyWarn MirrorFailurePolicy = "warn"
+)
+
+// MetaReplicas is the object metadata key listing the backends holding a mirror copy
+const MetaReplicas = "replicas"
+
+// WithMirrorBackend mirrors every upload to the named backend. Once the data is written to
+// the object's backend it is copied to the mirror under the same object key before the
+// upload returns, and the mirror is recorded in the object metadata under MetaReplicas.
+// DownloadContent falls back to the mirror when the blob is missing on the primary.
+//
+// A mirror-write failure fails the upload unless WithMirrorFailurePolicy selects
+// MirrorFailurePolicyWarn. Objects uploaded directly to the mirror backend are not copied.
+func WithMirrorBackend(name string) Option {
+	return func(s *service) {
+		s.mirrorBackend = name
+	}
+}
+
+// WithMirrorFailurePolicy sets how uploads react to a failed mirror write.
+// The default is MirrorFailurePolicyFail.
+func WithMirrorFailurePolicy(policy MirrorFailurePolicy) Option {
+	return func(s *service) {
+		s.mirrorPolicy = policy
+	}
+}
+
+// validateMirror checks the mirror configuration once all options have been applied
+func (s *service) validateMirror() error {
+	if s.mirrorBackend == "" {
+		return nil
+	}
+	if _, exists := s.blobStores[s.mirrorBackend]; !exists {
+		return fmt.Errorf("mirror backend: %w", &BackendNotFoundError{Name: s.mirrorBackend, Available: s.backendNames()})
+	}
+	switch s.mirrorPolicy {
+	case "":
+		s.mirrorPolicy = MirrorFailurePolicyFail
+	case MirrorFailurePolicyFail, MirrorFailurePolicyWarn:
+	default:
+		return fmt.Errorf("invalid mirror failure policy %q", s.mirrorPolicy)
+	}
+	return nil
+}
+
+// mirrorUpload copies an object that was just written to its backend onto the mirror
+// backend and records the replica. It returns an error only under MirrorFailurePolicyFail,
+// after deleting the primary copy so the object does not look uploaded.
+func (s *service) mirrorUpload(ctx context.Context, object *Object, mimeType string) error {
+	if s.mirrorBackend == "" || object.StorageBackendName == s.mirrorBackend {
+		return nil
+	}
+
+	err := s.copyToMirror(ctx, object, mimeType)
+	if err == nil {
+		if recordErr := s.recordReplica(ctx, object.ID, s.mirrorBackend); recordErr != nil {
+			// Log warning but don't fail - the mirror copy exists
+			slog.Warn("Failed to record mirror replica", "object_id", object.ID, "mirror", s.mirrorBackend, "error", recordErr)
+		}
+		return nil
+	}
+
+	if s.mirrorPolicy == MirrorFailurePolicyWarn {
+		slog.Warn("Failed to write object to mirror backend", "object_id", object.ID, "mirror", s.mirrorBackend, "error", err)
+		return nil
+	}
+
+	if backend, getErr := s.GetBackend(object.StorageBackendName); getErr == nil {
+		if delErr := backend.Delete(context.WithoutCancel(ctx), object.ObjectKey); delErr != nil {
+			slog.Warn("Failed to delete primary copy after mirror failure", "object_id", object.ID, "key", object.ObjectKey, "error", delErr)
+		}
+	}
+	return &ObjectError{ObjectID: object.ID, Op: "upload_mirror", Err: err}
+}
+
+// copyToMirror streams the object from its backend to the mirror backend
+func (s *service) copyToMirror(ctx context.Context, object *Object, mimeType string) error {
+	primary, err := s.GetBackend(object.StorageBackendName)
+	if err != nil {
+		return err
+	}
+	mirror, err := s.GetBackend(s.mirrorBackend)
+	if err != nil {
+		return err
+	}
+
+	reader, err := primary.Download(ctx, object.ObjectKey)
+	if err != nil {
+		s.stats.backendError(object.StorageBackendName)
+		return fmt.Errorf("failed to read primary copy: %w", err)
+	}
+	defer reader.Close()
+
+	mirrored := *object
+	mirrored.StorageBackendName = s.mirrorBackend
+	if err := s.uploadToBackend(ctx, mirror, &mirrored, reader, mimeType, ""); err != nil {
+		s.stats.backendError(s.mirrorBackend)
+		return err
+	}
+	return nil
+}
+
+// recordReplica adds the backend to the replicas listed in the object metadata
+func (s *service) recordReplica(ctx context.Context, objectID uuid.UUID, backend string) error {
+	now := time.Now().UTC()
+	metadata, err := s.repository.GetObjectMetadata(ctx, objectID)
+	if err != nil || metadata == nil {
+		metadata = &ObjectMetadata{ObjectID: objectID, CreatedAt: now}
+	}
+	if metadata.Metadata == nil {
+		metadata.Metadata = make(map[string]interface{})
+	}
+
+	replicas := replicasFromMetadata(metadata.Metadata)
+	for _, existing := range replicas {
+		if existing == backend {
+			return nil
+		}
+	}
+	metadata.Metadata[MetaReplicas] = append(replicas, backend)
+	metadata.UpdatedAt = now
+	return s.repository.SetObjectMetadata(ctx, metadata)
+}
+
+// preserveReplicas copies the recorded replicas onto metadata that is about to replace
+// the stored object metadata
+func (s *service) preserveReplicas(ctx context.Context, objectID uuid.UUID, metadata map[string]interface{}) {
+	existing, err := s.repository.GetObjectMetadata(ctx, objectID)
+	if err != nil || existing == nil {
+		return
+	}
+	if replicas := replicasFromMetadata(existing.Metadata); len(replicas) > 0 {
+		metadata[MetaReplicas] = replicas
+	}
+}
+
+// replicasFromMetadata reads MetaReplicas, which is a []interface{} once the metadata
+// has been round-tripped through JSON
+func replicasFromMetadata(metadata map[string]interface{}) []string {
+	switch v := metadata[MetaReplicas].(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []interface{}:
+		replicas := make([]string, 0, len(v))
+		for _, item := range v {
+			if name, ok := item.(string); ok {
+				replicas = append(replicas, name)
+			}
+		}
+		return replicas
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MoveObject moves the object's data to newKey on its backend and updates the object
+// record, e.g. to promote an upload from a temporary prefix to its permanent key.
+// Backends implementing ObjectMover move the data in place (rename on fs, server-side
+// copy on S3); others are copied through the service and the source is deleted. Mirror
+// copies recorded under MetaReplicas are moved as well, on a best-effort basis.
+//
+// A content-addressed blob that other objects share is copied to newKey instead, so they
+// keep their data.
+//
+// newKey must be free: ErrObjectKeyExists is returned when another object or stored data
+// already uses it. If the record cannot be updated the data is moved back.
+func (s *service) MoveObject(ctx context.Context, objectID uuid.UUID, newKey string) (*Object, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	object, err := s.repository.GetObject(ctx, objectID)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "move", Err: err}
+	}
+
+	newKey = s.sanitizeObjectKey(newKey)
+	if strings.TrimSpace(newKey) == "" {
+		return nil, &ObjectError{ObjectID: objectID, Op: "move", Err: fmt.Errorf("%w: empty key", ErrInvalidObjectKey)}
+	}
+	if newKey == object.ObjectKey {
+		return object, nil
+	}
+
+	backend, err := s.GetBackend(object.StorageBackendName)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "move", Err: err}
+	}
+	inUse, _, err := s.objectKeyInUse(ctx, backend, object.StorageBackendName, newKey)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "move", Err: fmt.Errorf("failed to check object key: %w", err)}
+	}
+	if inUse {
+		return nil, &ObjectError{ObjectID: objectID, Op: "move", Err: fmt.Errorf("%w: %s on backend %s", ErrObjectKeyExists, newKey, object.StorageBackendName)}
+	}
+
+	oldKey := object.ObjectKey
+	unlock, err := s.lockBlob(ctx, object.StorageBackendName, oldKey)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "move", Err: fmt.Errorf("failed to lock blob: %w", err)}
+	}
+	defer unlock()
+	shared := s.blobShared(ctx, object)
+	if shared {
+		err = s.copyObjectData(ctx, backend, object.StorageBackendName, oldKey, newKey)
+	} else {
+		err = s.moveObjectData(ctx, backend, object.StorageBackendName, oldKey, newKey)
+	}
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "move", Err: err}
+	}
+
+	object.ObjectKey = newKey
+	object.UpdatedAt = time.Now().UTC()
+	if err := s.repository.UpdateObject(ctx, object); err != nil {
+		if shared {
+			if undoErr := backend.Delete(context.WithoutCancel(ctx), newKey); undoErr != nil {
+				slog.Warn("Failed to delete copied object data after record update failed", "object_id", objectID, "key", newKey, "error", undoErr)
+			}
+		} else if undoErr := blobMove(context.WithoutCancel(ctx), backend, newKey, oldKey); undoErr != nil {
+			slog.Warn("Failed to move object data back after record update failed", "object_id", objectID, "key", newKey, "error", undoErr)
+		}
+		return nil, &ObjectError{ObjectID: objectID, Op: "move", Err: err}
+	}
+
+	s.moveReplicas(ctx, object, oldKey, newKey)
+	return object, nil
+}
+
+// moveObjectData moves stored data on the named backend. A missing source is
+// reported as ErrBlobNotFound.
+func (s *service) moveObjectData(ctx context.Context, backend BlobStore, backendName, srcKey, dstKey string) error {
+	return s.objectDataError(blobMove(ctx, backend, srcKey, dstKey), backendName, srcKey, "move")
+}
+
+// copyObjectData copies stored data on the named backend, reporting errors like
+// moveObjectData
+func (s *service) copyObjectData(ctx context.Context, backend BlobStore, backendName, srcKey, dstKey string) error {
+	return s.objectDataError(blobCopy(ctx, backend, srcKey, dstKey), backendName, srcKey, "copy")
+}
+
+// objectDataError maps the error of moving or copying srcKey, counting backend failures
+func (s *service) objectDataError(err error, backendName, srcKey, op string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrObjectNotFound):
+		return fmt.Errorf("%w: %s on backend %s", ErrBlobNotFound, srcKey, backendName)
+	case errors.Is(err, ErrObjectKeyExists):
+		return err
+	default:
+		s.stats.backendError(backendName)
+		return fmt.Errorf("failed to %s object data: %w", op, err)
+	}
+}
+
+// moveReplicas moves the mirror copies of an object to its new key. Failures are logged;
+// the object itself has already moved.
+func (s *service) moveReplicas(ctx context.Context, object *Object, oldKey, newKey string) {
+	metadata, err := s.repository.GetObjectMetadata(ctx, object.ID)
+	if err != nil || metadata == nil {
+		return
+	}
+	for _, name := range replicasFromMetadata(metadata.Metadata) {
+		replica, err := s.GetBackend(name)
+		if err == nil {
+			err = s.moveObjectData(ctx, replica, name, oldKey, newKey)
+		}
+		if err != nil {
+			slog.Warn("Failed to move mirror copy of object", "object_id", object.ID, "mirror", name, "key", oldKey, "error", err)
+		}
+	}
+}
+
+// blobMove moves srcKey to dstKey on the backend, copying the data and deleting the source
+// when the backend does not implement ObjectMover
+func blobMove(ctx context.Context, backend BlobStore, srcKey, dstKey string) error {
+	if mover, ok := backend.(ObjectMover); ok {
+		return mover.MoveObject(ctx, srcKey, dstKey)
+	}
+
+	exists, err := blobExists(ctx, backend, dstKey)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrObjectKeyExists, dstKey)
+	}
+
+	meta, err := backend.GetObjectMeta(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	reader, err := backend.Download(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if err := backend.UploadWithParams(ctx, reader, UploadParams{ObjectKey: dstKey, MimeType: meta.ContentType}); err != nil {
+		return err
+	}
+	if err := backend.Delete(ctx, srcKey); err != nil {
+		// The data is readable at the new key; the old copy is only left behind
+		slog.Warn("Failed to delete source after copying object", "key", srcKey, "error", err)
+	}
+	return nil
+}
+
+// blobCopy copies srcKey to dstKey on the backend, streaming the data through the service
+// when the backend does not implement ObjectCopier
+func blobCopy(ctx context.Context, backend BlobStore, srcKey, dstKey string) error {
+	if copier, ok := backend.(ObjectCopier); ok {
+		return copier.CopyObject(ctx, srcKey, dstKey)
+	}
+	return streamCopy(ctx, backend, srcKey, dstKey)
+}
+
+// streamCopy copies srcKey to dstKey by downloading and uploading the data again
+func streamCopy(ctx context.Context, backend BlobStore, srcKey, dstKey string) error {
+	meta, err := backend.GetObjectMeta(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	reader, err := backend.Download(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return backend.UploadWithParams(ctx, reader, UploadParams{ObjectKey: dstKey, MimeType: meta.ContentType})
+}
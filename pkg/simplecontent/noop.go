@@ -55,6 +55,21 @@ func (n *NoopEventSink) ObjectStatusChanged(ctx context.Context, objectID uuid.U
 	return nil
 }
 
+// ContentDownloaded does nothing and returns nil
+func (n *NoopEventSink) ContentDownloaded(ctx context.Context, event *AccessEvent) error {
+	return nil
+}
+
+// ContentPreviewed does nothing and returns nil
+func (n *NoopEventSink) ContentPreviewed(ctx context.Context, event *AccessEvent) error {
+	return nil
+}
+
+// ObjectAnnotated does nothing and returns nil
+func (n *NoopEventSink) ObjectAnnotated(ctx context.Context, object *Object, annotations map[string]interface{}) error {
+	return nil
+}
+
 // NoopPreviewer is a no-operation implementation of Previewer
 // Always returns nil (no preview generated) and supports no content types
 type NoopPreviewer struct{}
@@ -103,6 +118,12 @@ func (l *LoggingEventSink) ContentUpdated(ctx context.Context, content *Content)
 	return nil
 }
 
+// ContentUpdatedWithChanges logs the content update event with the fields it changed
+func (l *LoggingEventSink) ContentUpdatedWithChanges(ctx context.Context, event *ContentUpdatedEvent) error {
+	l.logger.Infof("Content updated: ID=%s, Name=%s, Changed=%s", event.Content.ID, event.Content.Name, changedFields(event))
+	return nil
+}
+
 // ContentDeleted logs the content deletion event
 func (l *LoggingEventSink) ContentDeleted(ctx context.Context, contentID uuid.UUID) error {
 	l.logger.Infof("Content deleted: ID=%s", contentID)
@@ -139,6 +160,24 @@ func (l *LoggingEventSink) ObjectStatusChanged(ctx context.Context, objectID uui
 	return nil
 }
 
+// ContentDownloaded logs the content download event
+func (l *LoggingEventSink) ContentDownloaded(ctx context.Context, event *AccessEvent) error {
+	l.logger.Infof("Content downloaded: ID=%s, ObjectID=%s, Actor=%s", event.ContentID, event.ObjectID, event.Actor)
+	return nil
+}
+
+// ContentPreviewed logs the content preview event
+func (l *LoggingEventSink) ContentPreviewed(ctx context.Context, event *AccessEvent) error {
+	l.logger.Infof("Content previewed: ID=%s, ObjectID=%s, Actor=%s", event.ContentID, event.ObjectID, event.Actor)
+	return nil
+}
+
+// ObjectAnnotated logs the object annotation event
+func (l *LoggingEventSink) ObjectAnnotated(ctx context.Context, object *Object, annotations map[string]interface{}) error {
+	l.logger.Infof("Object annotated: ID=%s, Keys=%d", object.ID, len(annotations))
+	return nil
+}
+
 // BasicImagePreviewer is a simple previewer that generates preview URLs for common image types
 type BasicImagePreviewer struct {
 	supportedTypes map[string]bool
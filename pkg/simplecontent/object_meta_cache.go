@@ -0,0 +1,117 @@
+package simplecontent
+
+import (
+	"container/list"
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WithObjectMetaCache caches the storage metadata (size, MIME type, ETag) that downloads
+// read from the backend, so repeated downloads of an object within ttl skip the backend
+// lookup, a HeadObject request on S3. At most size objects are cached; the least recently
+// used is dropped first. Uploads, updates and deletes through the service invalidate the
+// object's entry; changes made to storage behind the service's back, or by another service
+// instance, show up once the entry expires.
+func WithObjectMetaCache(ttl time.Duration, size int) Option {
+	return func(s *service) {
+		if ttl <= 0 || size <= 0 {
+			s.metaCache = nil
+			return
+		}
+		s.metaCache = &objectMetaCache{ttl: ttl, size: size, now: time.Now}
+	}
+}
+
+// objectMetaCache is an LRU cache of backend object metadata keyed by object ID
+type objectMetaCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	now     func() time.Time
+	entries map[uuid.UUID]*list.Element
+	order   list.List // Most recently used first
+}
+
+type objectMetaEntry struct {
+	id        uuid.UUID
+	objectKey string // Key the metadata was read from; a moved object misses
+	meta      ObjectMeta
+	expires   time.Time
+}
+
+func (c *objectMetaCache) get(id uuid.UUID, objectKey string) (*ObjectMeta, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*objectMetaEntry)
+	if entry.objectKey != objectKey || !c.now().Before(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	meta := entry.meta
+	meta.Metadata = maps.Clone(entry.meta.Metadata)
+	return &meta, true
+}
+
+func (c *objectMetaCache) put(id uuid.UUID, objectKey string, meta *ObjectMeta) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[uuid.UUID]*list.Element)
+	}
+	entry := &objectMetaEntry{id: id, objectKey: objectKey, meta: *meta, expires: c.now().Add(c.ttl)}
+	entry.meta.Metadata = maps.Clone(meta.Metadata)
+	if elem, ok := c.entries[id]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[id] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*objectMetaEntry).id)
+	}
+}
+
+func (c *objectMetaCache) invalidate(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[id]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+	}
+}
+
+// objectMeta returns the backend metadata of object, from the cache when enabled
+func (s *service) objectMeta(ctx context.Context, backend BlobStore, object *Object) (*ObjectMeta, error) {
+	if s.metaCache != nil {
+		if meta, ok := s.metaCache.get(object.ID, object.ObjectKey); ok {
+			return meta, nil
+		}
+	}
+	meta, err := backend.GetObjectMeta(ctx, object.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	if s.metaCache != nil {
+		s.metaCache.put(object.ID, object.ObjectKey, meta)
+	}
+	return meta, nil
+}
+
+// invalidateObjectMeta drops the cached backend metadata of an object whose data changed
+func (s *service) invalidateObjectMeta(id uuid.UUID) {
+	if s.metaCache != nil {
+		s.metaCache.invalidate(id)
+	}
+}
@@ -0,0 +1,45 @@
+package objectkey
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ContentAddressed is a Generator whose final keys are derived from the stored data rather
+// than from the content and object IDs. GenerateKey returns the staging key an upload is
+// written to; once the SHA-256 of the data is known the blob is moved to ContentKey.
+type ContentAddressed interface {
+	Generator
+	// ContentKey returns the key of data with the given hex-encoded SHA-256
+	ContentKey(sha256Hex string) string
+}
+
+// ContentAddressedKeyGenerator places blobs by the SHA-256 of their data, git style:
+// sha256/ab/cd/abcd1234.... Identical data always gets the same key, so it is stored once.
+// Uploads are staged under staging/{contentID}/{objectID} until their hash is known.
+type ContentAddressedKeyGenerator struct {
+	// ShardLength controls how many hash characters each of the two shard levels uses (default: 2)
+	ShardLength int
+}
+
+func NewContentAddressedKeyGenerator() *ContentAddressedKeyGenerator {
+	return &ContentAddressedKeyGenerator{
+		ShardLength: 2,
+	}
+}
+
+func (g *ContentAddressedKeyGenerator) GenerateKey(contentID, objectID uuid.UUID, metadata *KeyMetadata) string {
+	return fmt.Sprintf("staging/%s/%s", contentID, objectID)
+}
+
+func (g *ContentAddressedKeyGenerator) ContentKey(sha256Hex string) string {
+	shard := g.ShardLength
+	if shard <= 0 {
+		shard = 2
+	}
+	if len(sha256Hex) < 2*shard {
+		return "sha256/" + sha256Hex
+	}
+	return fmt.Sprintf("sha256/%s/%s/%s", sha256Hex[:shard], sha256Hex[shard:2*shard], sha256Hex)
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -26,6 +27,11 @@ type KeyMetadata struct {
 	DerivationType  string    // "thumbnail", "preview", "transcode", etc.
 	Variant         string    // "256x256", "1080p", "small", etc.
 	ParentContentID uuid.UUID // For derived content
+
+	// Content attributes used by TemplateGenerator
+	Name            string
+	DocumentType    string
+	CreatedAt       time.Time
 }
 
 // LegacyGenerator provides the original flat structure for backwards compatibility
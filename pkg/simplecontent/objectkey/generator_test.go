@@ -3,6 +3,7 @@ package objectkey
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -212,6 +213,59 @@ func TestSanitization(t *testing.T) {
 	}
 }
 
+func TestSanitizeKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Plain", "originals/objects/ab/cd12_report.pdf", "originals/objects/ab/cd12_report.pdf"},
+		{"Traversal", "../../etc/passwd", "etc/passwd"},
+		{"EmbeddedTraversal", "uploads/../../../secret", "uploads/secret"},
+		{"BackslashTraversal", "..\\..\\windows\\system32", "windows/system32"},
+		{"AbsolutePath", "/etc/shadow", "etc/shadow"},
+		{"DotSegments", "a/./b//c", "a/b/c"},
+		{"Spaces", "my photo.jpg", "my%20photo.jpg"},
+		{"NullByte", "evil\x00.txt", "evil.txt"},
+		{"ControlChars", "line\nbreak\t.txt", "linebreak.txt"},
+		{"Emoji", "party🎉.png", "party%F0%9F%8E%89.png"},
+		{"UnicodeNormalization", "cafe\u0301.txt", "caf%C3%A9.txt"},
+		{"PercentEscaped", "100%.txt", "100%25.txt"},
+		{"OnlyTraversal", "../..", "unnamed"},
+		{"Empty", "", "unnamed"},
+		{"AlreadyEscaped", "my%20photo.jpg", "my%20photo.jpg"},
+		{"EscapedTraversal", "a/%2E%2E/b", "a/b"},
+		{"InvalidEscape", "50%zz", "50%25zz"},
+		{"EscapedSeparator", "%2E%2E%2Fetc", "%2E%2E%252Fetc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SanitizeKey(tt.input)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if again := SanitizeKey(result); again != result {
+				t.Errorf("expected sanitizing %q again to keep it, got %q", result, again)
+			}
+			for _, segment := range strings.Split(result, "/") {
+				if segment == ".." {
+					t.Errorf("sanitized key %q still contains a traversal segment", result)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeDisplayName(t *testing.T) {
+	if got := SanitizeDisplayName("  résumé\x00 🎉.pdf "); got != "résumé 🎉.pdf" {
+		t.Errorf("unexpected display name %q", got)
+	}
+	if got := SanitizeDisplayName("cafe\u0301.txt"); got != "caf\u00e9.txt" {
+		t.Errorf("expected NFC-normalized name, got %q", got)
+	}
+}
+
 func TestShardingDistribution(t *testing.T) {
 	gen := NewGitLikeGenerator()
 	contentID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
@@ -268,4 +322,81 @@ func BenchmarkGenerators(b *testing.B) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestTemplateGenerator(t *testing.T) {
+	contentID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	objectID := uuid.MustParse("987fcdeb-51a2-43d1-9f12-345678901234")
+	created := time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		template string
+		metadata *KeyMetadata
+		expected string
+	}{
+		{
+			name:     "date and document type",
+			template: "{{.Year}}/{{.DocumentType}}/{{.FileName}}",
+			metadata: &KeyMetadata{FileName: "acme-2024-03.pdf", DocumentType: "invoices", CreatedAt: created},
+			expected: "2024/invoices/acme-2024-03.pdf",
+		},
+		{
+			name:     "unsafe values stay in their segment",
+			template: "{{.DocumentType}}/{{.Month}}/{{.FileName}}",
+			metadata: &KeyMetadata{FileName: "../../etc/pass wd?.pdf", DocumentType: "a/b", CreatedAt: created},
+			expected: "a_b/03/_.._etc_pass_wd_.pdf",
+		},
+		{
+			name:     "file name parts",
+			template: "{{.Extension}}/{{.BaseName}}-{{.Day}}.{{.Extension}}",
+			metadata: &KeyMetadata{FileName: "report.final.csv", CreatedAt: created},
+			expected: "csv/report.final-09.csv",
+		},
+		{
+			name:     "object id without file name",
+			template: "{{.TenantID}}/{{.FileName}}",
+			metadata: &KeyMetadata{TenantID: "acme", CreatedAt: created},
+			expected: "acme/987fcdeb-51a2-43d1-9f12-345678901234",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := NewTemplateGenerator(tt.template)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result := gen.GenerateKey(contentID, objectID, tt.metadata); result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"{{.Year", "{{.Missing}}/{{.FileName}}"} {
+		if _, err := NewTemplateGenerator(invalid); err == nil {
+			t.Errorf("expected error for template %q", invalid)
+		}
+	}
+}
+
+func TestContentAddressedKeyGenerator(t *testing.T) {
+	gen := NewContentAddressedKeyGenerator()
+	contentID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	objectID := uuid.MustParse("987fcdeb-51a2-43d1-9f12-345678901234")
+
+	staging := gen.GenerateKey(contentID, objectID, &KeyMetadata{FileName: "photo.jpg", IsOriginal: true})
+	if expected := "staging/123e4567-e89b-12d3-a456-426614174000/987fcdeb-51a2-43d1-9f12-345678901234"; staging != expected {
+		t.Errorf("expected %s, got %s", expected, staging)
+	}
+
+	sum := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if expected := "sha256/e3/b0/" + sum; gen.ContentKey(sum) != expected {
+		t.Errorf("expected %s, got %s", expected, gen.ContentKey(sum))
+	}
+	wide := &ContentAddressedKeyGenerator{ShardLength: 3}
+	if expected := "sha256/e3b/0c4/" + sum; wide.ContentKey(sum) != expected {
+		t.Errorf("expected %s, got %s", expected, wide.ContentKey(sum))
+	}
+
+	var _ ContentAddressed = gen
+}
@@ -0,0 +1,99 @@
+package objectkey
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// EmptyKeyFallback is the key SanitizeKey returns when nothing of the input is left,
+// e.g. for "../.."
+const EmptyKeyFallback = "unnamed"
+
+// SanitizeKey makes an object key safe for every storage backend:
+//   - control characters (including null bytes) are removed
+//   - unicode is normalized to NFC so visually identical names map to one key
+//   - backslashes are treated as separators and empty, "." and ".." segments are dropped,
+//     so the key can never escape the backend root
+//   - characters outside [A-Za-z0-9._~-] are percent-encoded within each segment; valid
+//     %XX escapes are kept, so sanitizing a sanitized key returns it unchanged
+//   - a key with nothing left becomes EmptyKeyFallback
+//
+// Example: "../../etc/passwd" -> "etc/passwd", "my photo.jpg" -> "my%20photo.jpg"
+func SanitizeKey(key string) string {
+	key = norm.NFC.String(stripControl(key))
+	key = strings.ReplaceAll(key, "\\", "/")
+
+	segments := strings.Split(key, "/")
+	clean := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segment = escapeSegment(segment)
+		// "%2E%2E" kept as an escape would still decode to a traversal segment
+		if decoded, err := url.PathUnescape(segment); err == nil && (decoded == "." || decoded == "..") {
+			continue
+		}
+		clean = append(clean, segment)
+	}
+	if len(clean) == 0 {
+		return EmptyKeyFallback
+	}
+	return strings.Join(clean, "/")
+}
+
+// SanitizeDisplayName removes control characters (including null bytes) from a
+// user-provided filename and normalizes it to NFC. The result is meant for display
+// and metadata, not for building storage paths; use SanitizeKey for those.
+func SanitizeDisplayName(name string) string {
+	return strings.TrimSpace(norm.NFC.String(stripControl(name)))
+}
+
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func escapeSegment(segment string) string {
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		if c == '%' && i+2 < len(segment) && isHex(segment[i+1]) && isHex(segment[i+2]) && !isSeparatorEscape(segment[i+1:i+3]) {
+			// Already escaped; keep it so SanitizeKey is idempotent
+			b.WriteString(segment[i : i+3])
+			i += 2
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// isUnreserved reports whether c is an RFC 3986 unreserved character
+func isUnreserved(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// isSeparatorEscape reports whether the hex digits of an escape encode "/" or a backslash, which
+// are re-escaped so a decoded key cannot gain separators
+func isSeparatorEscape(hex string) bool {
+	return strings.EqualFold(hex, "2F") || strings.EqualFold(hex, "5C")
+}
+
+// isHex reports whether c is a hexadecimal digit
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,109 @@
+package objectkey
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TemplateData holds the fields available to a key template. Every value is sanitized to a
+// single path segment, so only the slashes written in the template itself create directories.
+type TemplateData struct {
+	ContentID      string
+	ObjectID       string
+	TenantID       string
+	OwnerID        string
+	Name           string // Content name
+	DocumentType   string
+	FileName       string // Defaults to the object ID when the content has no file name
+	BaseName       string // FileName without its extension
+	Extension      string // Extension of FileName without the dot, e.g. "pdf"
+	DerivationType string
+	Variant        string
+	Year           string // Creation date, e.g. "2024"
+	Month          string // Zero-padded, e.g. "03"
+	Day            string // Zero-padded, e.g. "09"
+}
+
+// TemplateGenerator renders object keys from a text/template, giving human-readable keys such
+// as "{{.Year}}/{{.DocumentType}}/{{.FileName}}" -> "2024/invoices/acme-2024-03.pdf".
+// Rendered keys are not unique by themselves; the service resolves collisions when it uses
+// the generator through WithKeyTemplate.
+type TemplateGenerator struct {
+	tmpl *template.Template
+}
+
+// NewTemplateGenerator parses text and checks that it renders with the TemplateData fields
+func NewTemplateGenerator(text string) (*TemplateGenerator, error) {
+	tmpl, err := template.New("objectkey").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key template: %w", err)
+	}
+	g := &TemplateGenerator{tmpl: tmpl}
+	if _, err := g.render(TemplateData{}); err != nil {
+		return nil, fmt.Errorf("invalid key template: %w", err)
+	}
+	return g, nil
+}
+
+func (g *TemplateGenerator) GenerateKey(contentID, objectID uuid.UUID, metadata *KeyMetadata) string {
+	key, err := g.render(newTemplateData(contentID, objectID, metadata))
+	if err != nil || strings.Trim(key, "/") == "" {
+		// The template was checked when parsed, so this only happens for empty output
+		return fmt.Sprintf("C/%s/%s", contentID, objectID)
+	}
+	return key
+}
+
+func (g *TemplateGenerator) render(data TemplateData) (string, error) {
+	var b strings.Builder
+	if err := g.tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func newTemplateData(contentID, objectID uuid.UUID, metadata *KeyMetadata) TemplateData {
+	if metadata == nil {
+		metadata = &KeyMetadata{}
+	}
+	created := metadata.CreatedAt
+	if created.IsZero() {
+		created = time.Now()
+	}
+	created = created.UTC()
+
+	fileName := sanitizeTemplateValue(metadata.FileName)
+	if fileName == "" {
+		fileName = objectID.String()
+	}
+	ext := path.Ext(fileName)
+
+	return TemplateData{
+		ContentID:      contentID.String(),
+		ObjectID:       objectID.String(),
+		TenantID:       sanitizeTemplateValue(metadata.TenantID),
+		OwnerID:        sanitizeTemplateValue(metadata.OwnerID),
+		Name:           sanitizeTemplateValue(metadata.Name),
+		DocumentType:   sanitizeTemplateValue(metadata.DocumentType),
+		FileName:       fileName,
+		BaseName:       strings.TrimSuffix(fileName, ext),
+		Extension:      strings.TrimPrefix(ext, "."),
+		DerivationType: sanitizeTemplateValue(metadata.DerivationType),
+		Variant:        sanitizeTemplateValue(metadata.Variant),
+		Year:           created.Format("2006"),
+		Month:          created.Format("01"),
+		Day:            created.Format("02"),
+	}
+}
+
+// sanitizeTemplateValue turns a user-provided value into a single readable path segment:
+// control characters are removed, separators and characters unsafe in filenames become
+// underscores, and "." or ".." can no longer climb out of the template's directories
+func sanitizeTemplateValue(value string) string {
+	return strings.Trim(sanitizeFilename(SanitizeDisplayName(value)), ".")
+}
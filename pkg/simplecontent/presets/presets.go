@@ -139,73 +139,6 @@ func NewTesting(t *testing.T, opts ...TestingOption) simplecontent.Service {
 	return svc
 }
 
-// NewProduction creates a service configured for production deployment.
-//
-// Features:
-//   - Database from environment (DATABASE_TYPE, DATABASE_URL)
-//   - Storage from environment (STORAGE_BACKEND, AWS_S3_BUCKET, etc.)
-//   - URL strategy from environment (URL_STRATEGY, CDN_BASE_URL, etc.)
-//   - Event logging enabled
-//   - Security best practices
-//   - Validation of required configuration
-//
-// Required Environment Variables:
-//   - DATABASE_TYPE: "postgres" (required for production)
-//   - DATABASE_URL: PostgreSQL connection string
-//   - STORAGE_BACKEND: "s3" or "fs"
-//   - AWS_S3_BUCKET: S3 bucket name (if using S3)
-//   - AWS_S3_REGION: S3 region (if using S3)
-//
-// Optional Environment Variables:
-//   - CDN_BASE_URL: CDN base URL for downloads
-//   - URL_STRATEGY: "cdn", "content-based", or "storage-delegated"
-//   - OBJECT_KEY_GENERATOR: "git-like", "tenant-aware", etc.
-//
-// Example:
-//
-//	svc, err := simplecontent.NewProduction()
-//	if err != nil {
-//	    log.Fatal(err)
-//	}
-//
-//	// Use service in production...
-func NewProduction(opts ...ProductionOption) (simplecontent.Service, error) {
-	// Default configuration (loads from environment)
-	cfg := &prodConfig{
-		databaseType:   getEnv("DATABASE_TYPE", "postgres"),
-		databaseURL:    getEnv("DATABASE_URL", ""),
-		storageBackend: getEnv("STORAGE_BACKEND", "s3"),
-	}
-
-	// Apply options
-	for _, opt := range opts {
-		opt(cfg)
-	}
-
-	// Validate required configuration
-	if cfg.databaseType == "memory" {
-		return nil, fmt.Errorf("production preset requires DATABASE_TYPE=postgres (memory not allowed in production)")
-	}
-	if cfg.databaseURL == "" {
-		return nil, fmt.Errorf("DATABASE_URL is required for production")
-	}
-	if cfg.storageBackend == "memory" {
-		return nil, fmt.Errorf("production preset requires persistent storage (s3 or fs, not memory)")
-	}
-
-	// Use the config package to build service
-	// This delegates to the full configuration system
-	configOpts := []interface{}{
-		// Would use config.Load() here in real implementation
-		// For now, we'll build manually
-	}
-	_ = configOpts
-
-	// TODO: Implement full production setup using config package
-	// For now, return error indicating environment setup needed
-	return nil, fmt.Errorf("production preset requires config package integration (not yet implemented)")
-}
-
 // Option types for customization
 
 // devConfig holds development preset configuration
@@ -219,13 +152,6 @@ type testConfig struct {
 	fixtures bool
 }
 
-// prodConfig holds production preset configuration
-type prodConfig struct {
-	databaseType   string
-	databaseURL    string
-	storageBackend string
-}
-
 // DevelopmentOption is a functional option for NewDevelopment
 type DevelopmentOption func(*devConfig)
 
@@ -253,24 +179,6 @@ func WithTestFixtures() TestingOption {
 	}
 }
 
-// ProductionOption is a functional option for NewProduction
-type ProductionOption func(*prodConfig)
-
-// WithProdDatabase sets the production database configuration
-func WithProdDatabase(dbType, url string) ProductionOption {
-	return func(cfg *prodConfig) {
-		cfg.databaseType = dbType
-		cfg.databaseURL = url
-	}
-}
-
-// WithProdStorage sets the production storage backend
-func WithProdStorage(backend string) ProductionOption {
-	return func(cfg *prodConfig) {
-		cfg.storageBackend = backend
-	}
-}
-
 // Helper functions
 
 func getEnv(key, defaultValue string) string {
@@ -0,0 +1,357 @@
+package presets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/objectkey"
+	repopg "github.com/tendant/simple-content/pkg/simplecontent/repo/postgres"
+	fsstorage "github.com/tendant/simple-content/pkg/simplecontent/storage/fs"
+	s3storage "github.com/tendant/simple-content/pkg/simplecontent/storage/s3"
+	"github.com/tendant/simple-content/pkg/simplecontent/urlstrategy"
+)
+
+// NewProduction creates a service configured for production deployment.
+//
+// Features:
+//   - PostgreSQL repository on a pgx connection pool (DATABASE_URL)
+//   - S3 storage, or filesystem storage when STORAGE_BACKEND=fs
+//   - URL strategy from environment (URL_STRATEGY, CDN_BASE_URL, etc.)
+//   - Events are discarded (NoopEventSink); build the service with simplecontent.New to
+//     attach an event sink
+//   - Validation that fails fast on missing or invalid configuration
+//
+// Required Environment Variables:
+//   - DATABASE_URL: PostgreSQL connection string
+//   - AWS_S3_BUCKET: S3 bucket name (if using S3)
+//   - STORAGE_DIR: storage directory (if using fs)
+//
+// Optional Environment Variables:
+//   - DATABASE_TYPE: must be "postgres" when set
+//   - CONTENT_DB_SCHEMA: Postgres schema used as search_path (default: content)
+//   - DATABASE_MAX_CONNS, DATABASE_MIN_CONNS: pool size (default: 10 and 0)
+//   - STORAGE_BACKEND: "s3" (default) or "fs"
+//   - AWS_S3_REGION: S3 region (default: AWS_REGION, then us-east-1)
+//   - AWS_S3_ENDPOINT: custom endpoint for S3-compatible services (enables path-style)
+//   - AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY: static credentials (default: AWS credential chain)
+//   - URL_STRATEGY: "storage-delegated" (default), "content-based" or "cdn"
+//   - CDN_BASE_URL: CDN base URL for downloads (required for the cdn strategy)
+//   - UPLOAD_BASE_URL: upload base URL for the cdn strategy
+//   - API_BASE_URL: API base URL for the content-based strategy (default: /api/v1)
+//   - OBJECT_KEY_GENERATOR: "git-like" (default), "tenant-aware", "high-performance" or "legacy"
+//
+// The pool connects lazily, so NewProduction does not fail when the database is
+// temporarily unreachable. Options override the environment.
+//
+// Returns:
+//   - Service instance
+//   - Cleanup function (call with defer to close the database pool)
+//   - Error if configuration is missing or invalid
+//
+// Example:
+//
+//	svc, cleanup, err := presets.NewProduction(
+//	    presets.WithProdPoolSize(2, 20),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer cleanup()
+func NewProduction(opts ...ProductionOption) (simplecontent.Service, func(), error) {
+	cfg, err := loadProdConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	// Create storage first so a bad storage config does not leave a pool behind
+	backendName, backend, err := cfg.buildStorage()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	urlStrategy, err := cfg.buildURLStrategy(map[string]urlstrategy.BlobStore{backendName: backend})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyGenerator, err := cfg.buildObjectKeyGenerator()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool, err := cfg.buildPool()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(repopg.NewWithPool(pool)),
+		simplecontent.WithBlobStore(backendName, backend),
+		simplecontent.WithEventSink(simplecontent.NewNoopEventSink()),
+		simplecontent.WithURLStrategy(urlStrategy),
+		simplecontent.WithObjectKeyGenerator(keyGenerator),
+	)
+	if err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("failed to create service: %w", err)
+	}
+
+	return svc, pool.Close, nil
+}
+
+// prodConfig holds production preset configuration
+type prodConfig struct {
+	databaseType string
+	databaseURL  string
+	dbSchema     string
+
+	maxConns        int32
+	minConns        int32
+	maxConnLifetime time.Duration
+
+	storageBackend string
+	storageDir     string
+
+	s3Bucket          string
+	s3Region          string
+	s3Endpoint        string
+	s3AccessKeyID     string
+	s3SecretAccessKey string
+
+	urlStrategy        string
+	cdnBaseURL         string
+	uploadBaseURL      string
+	apiBaseURL         string
+	objectKeyGenerator string
+}
+
+// loadProdConfig reads the production configuration from the environment
+func loadProdConfig() (*prodConfig, error) {
+	cfg := &prodConfig{
+		databaseType:       getEnv("DATABASE_TYPE", "postgres"),
+		databaseURL:        getEnv("DATABASE_URL", ""),
+		dbSchema:           getEnv("CONTENT_DB_SCHEMA", "content"),
+		maxConns:           10,
+		storageBackend:     getEnv("STORAGE_BACKEND", "s3"),
+		storageDir:         getEnv("STORAGE_DIR", ""),
+		s3Bucket:           getEnv("AWS_S3_BUCKET", ""),
+		s3Region:           getEnv("AWS_S3_REGION", getEnv("AWS_REGION", "us-east-1")),
+		s3Endpoint:         getEnv("AWS_S3_ENDPOINT", ""),
+		s3AccessKeyID:      getEnv("AWS_ACCESS_KEY_ID", ""),
+		s3SecretAccessKey:  getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		urlStrategy:        getEnv("URL_STRATEGY", "storage-delegated"),
+		cdnBaseURL:         getEnv("CDN_BASE_URL", ""),
+		uploadBaseURL:      getEnv("UPLOAD_BASE_URL", ""),
+		apiBaseURL:         getEnv("API_BASE_URL", "/api/v1"),
+		objectKeyGenerator: getEnv("OBJECT_KEY_GENERATOR", "git-like"),
+	}
+
+	for key, target := range map[string]*int32{
+		"DATABASE_MAX_CONNS": &cfg.maxConns,
+		"DATABASE_MIN_CONNS": &cfg.minConns,
+	} {
+		raw := os.Getenv(key)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		*target = int32(n)
+	}
+
+	return cfg, nil
+}
+
+// validate checks the configuration before any connection is created
+func (cfg *prodConfig) validate() error {
+	if cfg.databaseType != "postgres" {
+		return fmt.Errorf("production preset requires DATABASE_TYPE=postgres (%s not allowed in production)", cfg.databaseType)
+	}
+	if cfg.databaseURL == "" {
+		return errors.New("DATABASE_URL is required for production")
+	}
+	if cfg.maxConns <= 0 {
+		return fmt.Errorf("database pool max connections must be positive, got %d", cfg.maxConns)
+	}
+	if cfg.minConns < 0 || cfg.minConns > cfg.maxConns {
+		return fmt.Errorf("database pool min connections must be between 0 and %d, got %d", cfg.maxConns, cfg.minConns)
+	}
+
+	switch cfg.storageBackend {
+	case "s3":
+		if cfg.s3Bucket == "" {
+			return errors.New("AWS_S3_BUCKET is required for s3 storage")
+		}
+	case "fs":
+		if cfg.storageDir == "" {
+			return errors.New("STORAGE_DIR is required for fs storage")
+		}
+	default:
+		return fmt.Errorf("production preset requires persistent storage (s3 or fs, not %s)", cfg.storageBackend)
+	}
+
+	if cfg.urlStrategy == "cdn" && cfg.cdnBaseURL == "" {
+		return errors.New("CDN_BASE_URL is required for the cdn URL strategy")
+	}
+	return nil
+}
+
+// buildPool creates the pgx pool; connections are opened on first use
+func (cfg *prodConfig) buildPool() (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DATABASE_URL: %w", err)
+	}
+	poolConfig.MaxConns = cfg.maxConns
+	poolConfig.MinConns = cfg.minConns
+	if cfg.maxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.maxConnLifetime
+	}
+	if cfg.dbSchema != "" {
+		searchPath := "SET search_path TO " + pgx.Identifier{cfg.dbSchema}.Sanitize()
+		poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			_, err := conn.Exec(ctx, searchPath)
+			return err
+		}
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pgx pool: %w", err)
+	}
+	return pool, nil
+}
+
+// buildStorage creates the storage backend and returns the name it is registered under
+func (cfg *prodConfig) buildStorage() (string, simplecontent.BlobStore, error) {
+	if cfg.storageBackend == "fs" {
+		backend, err := fsstorage.New(fsstorage.Config{BaseDir: cfg.storageDir})
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to create filesystem storage: %w", err)
+		}
+		return "fs", backend, nil
+	}
+
+	backend, err := s3storage.New(s3storage.Config{
+		Bucket:          cfg.s3Bucket,
+		Region:          cfg.s3Region,
+		Endpoint:        cfg.s3Endpoint,
+		UsePathStyle:    cfg.s3Endpoint != "",
+		AccessKeyID:     cfg.s3AccessKeyID,
+		SecretAccessKey: cfg.s3SecretAccessKey,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create s3 storage: %w", err)
+	}
+	return "s3", backend, nil
+}
+
+func (cfg *prodConfig) buildURLStrategy(blobStores map[string]urlstrategy.BlobStore) (urlstrategy.URLStrategy, error) {
+	switch cfg.urlStrategy {
+	case "storage-delegated":
+		return urlstrategy.NewStorageDelegatedStrategy(blobStores), nil
+	case "content-based":
+		return urlstrategy.NewContentBasedStrategy(cfg.apiBaseURL), nil
+	case "cdn":
+		if cfg.uploadBaseURL != "" {
+			return urlstrategy.NewCDNStrategyWithUpload(cfg.cdnBaseURL, cfg.uploadBaseURL), nil
+		}
+		return urlstrategy.NewCDNStrategy(cfg.cdnBaseURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported URL_STRATEGY: %s", cfg.urlStrategy)
+	}
+}
+
+func (cfg *prodConfig) buildObjectKeyGenerator() (objectkey.Generator, error) {
+	switch cfg.objectKeyGenerator {
+	case "git-like", "default", "":
+		return objectkey.NewGitLikeGenerator(), nil
+	case "tenant-aware":
+		return objectkey.NewTenantAwareGitLikeGenerator(), nil
+	case "high-performance":
+		return objectkey.NewHighPerformanceGenerator(), nil
+	case "legacy":
+		return objectkey.NewLegacyGenerator(), nil
+	default:
+		return nil, fmt.Errorf("unsupported OBJECT_KEY_GENERATOR: %s", cfg.objectKeyGenerator)
+	}
+}
+
+// ProductionOption is a functional option for NewProduction
+type ProductionOption func(*prodConfig)
+
+// WithProdDatabase sets the production database configuration
+func WithProdDatabase(dbType, url string) ProductionOption {
+	return func(cfg *prodConfig) {
+		cfg.databaseType = dbType
+		cfg.databaseURL = url
+	}
+}
+
+// WithProdPoolSize sets the minimum and maximum number of pooled database connections
+func WithProdPoolSize(minConns, maxConns int32) ProductionOption {
+	return func(cfg *prodConfig) {
+		cfg.minConns = minConns
+		cfg.maxConns = maxConns
+	}
+}
+
+// WithProdConnMaxLifetime sets how long a pooled connection is kept before it is replaced
+func WithProdConnMaxLifetime(d time.Duration) ProductionOption {
+	return func(cfg *prodConfig) {
+		cfg.maxConnLifetime = d
+	}
+}
+
+// WithProdStorage sets the production storage backend
+func WithProdStorage(backend string) ProductionOption {
+	return func(cfg *prodConfig) {
+		cfg.storageBackend = backend
+	}
+}
+
+// WithProdS3Bucket sets the S3 bucket and region
+func WithProdS3Bucket(bucket, region string) ProductionOption {
+	return func(cfg *prodConfig) {
+		cfg.storageBackend = "s3"
+		cfg.s3Bucket = bucket
+		if region != "" {
+			cfg.s3Region = region
+		}
+	}
+}
+
+// WithProdURLStrategy sets the URL strategy: "storage-delegated", "content-based" or "cdn"
+func WithProdURLStrategy(strategy string) ProductionOption {
+	return func(cfg *prodConfig) {
+		cfg.urlStrategy = strategy
+	}
+}
+
+// WithProdCDN selects the CDN URL strategy with the given download and upload base URLs.
+// An empty uploadBaseURL keeps uploads on storage URLs.
+func WithProdCDN(cdnBaseURL, uploadBaseURL string) ProductionOption {
+	return func(cfg *prodConfig) {
+		cfg.urlStrategy = "cdn"
+		cfg.cdnBaseURL = cdnBaseURL
+		cfg.uploadBaseURL = uploadBaseURL
+	}
+}
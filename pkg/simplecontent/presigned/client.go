@@ -2,18 +2,23 @@ package presigned
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 )
 
-// Client provides methods for uploading files to presigned URLs
+// Client provides methods for uploading files to presigned URLs and downloading from them
 type Client struct {
 	httpClient      *http.Client
 	retryAttempts   int
 	retryDelay      time.Duration
 	progressFunc    ProgressFunc
+	tlsConfig       *tls.Config
 }
 
 // ProgressFunc is called during upload to report progress
@@ -37,9 +42,34 @@ func NewClient(opts ...ClientOption) *Client {
 		opt(c)
 	}
 
+	if c.tlsConfig != nil {
+		c.httpClient = withTLSConfig(c.httpClient, c.tlsConfig)
+	}
+
 	return c
 }
 
+// withTLSConfig returns a copy of client whose transport uses tlsConfig.
+// The caller's client and transport are not modified. Custom RoundTrippers
+// that are not *http.Transport are left as-is.
+func withTLSConfig(client *http.Client, tlsConfig *tls.Config) *http.Client {
+	clientCopy := *client
+
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return &clientCopy
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	clientCopy.Transport = transport
+	return &clientCopy
+}
+
 // WithHTTPClient sets a custom HTTP client
 func WithHTTPClient(client *http.Client) ClientOption {
 	return func(c *Client) {
@@ -47,6 +77,16 @@ func WithHTTPClient(client *http.Client) ClientOption {
 	}
 }
 
+// WithTLSConfig sets the TLS configuration used for uploads, e.g. custom root CAs
+// for storage endpoints with self-signed certificates. It composes with
+// WithHTTPClient in any order: the TLS config is applied to a copy of that
+// client's *http.Transport (or the default transport if none is set).
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
 // WithRetry configures retry behavior
 func WithRetry(attempts int, delay time.Duration) ClientOption {
 	return func(c *Client) {
@@ -77,11 +117,24 @@ func (c *Client) Upload(ctx context.Context, presignedURL string, data io.Reader
 
 	// Wrap reader with progress tracking if enabled
 	reader := data
+	var progress *progressReader
 	if c.progressFunc != nil {
-		reader = &progressReader{
+		progress = &progressReader{
 			reader:   data,
 			callback: c.progressFunc,
 		}
+		reader = progress
+	}
+
+	// Seekable data, such as a file, is rewound so every attempt sends all of it
+	seeker, _ := data.(io.Seeker)
+	var start int64
+	if seeker != nil {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			start = offset
+		} else {
+			seeker = nil
+		}
 	}
 
 	var lastErr error
@@ -93,6 +146,14 @@ func (c *Client) Upload(ctx context.Context, presignedURL string, data io.Reader
 				return ctx.Err()
 			case <-time.After(c.retryDelay * time.Duration(attempt)):
 			}
+			if seeker != nil {
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					return fmt.Errorf("failed to rewind upload data: %w", err)
+				}
+				if progress != nil {
+					progress.bytesRead = 0
+				}
+			}
 		}
 
 		// Create upload request
@@ -103,6 +164,9 @@ func (c *Client) Upload(ctx context.Context, presignedURL string, data io.Reader
 
 		// Set headers
 		req.Header.Set("Content-Type", uploadOpts.contentType)
+		if uploadOpts.contentLength > 0 {
+			req.ContentLength = uploadOpts.contentLength
+		}
 		for k, v := range uploadOpts.headers {
 			req.Header.Set(k, v)
 		}
@@ -139,10 +203,59 @@ func (c *Client) UploadWithContentType(ctx context.Context, presignedURL string,
 	return c.Upload(ctx, presignedURL, data, WithContentType(contentType))
 }
 
+// UploadFile uploads the file at path to a presigned URL, closing it afterwards. The
+// Content-Type is inferred from the file extension, or from the first bytes of the file
+// when the extension is unknown, and Content-Length is set from the file size. Options
+// such as WithContentType override the inferred values. Progress is reported through
+// WithProgress, and retries send the whole file again.
+//
+// Example:
+//   err := client.UploadFile(ctx, presignedURL, "photos/cat.png")
+func (c *Client) UploadFile(ctx context.Context, presignedURL, path string, opts ...UploadOption) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot upload directory %s", path)
+	}
+	contentType, err := detectFileContentType(file)
+	if err != nil {
+		return err
+	}
+
+	fileOpts := []UploadOption{WithContentType(contentType), WithContentLength(info.Size())}
+	return c.Upload(ctx, presignedURL, file, append(fileOpts, opts...)...)
+}
+
+// detectFileContentType returns the MIME type of the file's extension, or the type
+// sniffed from its first 512 bytes, leaving the file positioned at its start
+func detectFileContentType(file *os.File) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(file.Name())); contentType != "" {
+		return contentType, nil
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind file: %w", err)
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
 // uploadOptions contains upload configuration
 type uploadOptions struct {
-	contentType string
-	headers     map[string]string
+	contentType   string
+	contentLength int64
+	headers       map[string]string
 }
 
 // UploadOption is a functional option for Upload method
@@ -155,6 +268,14 @@ func WithContentType(contentType string) UploadOption {
 	}
 }
 
+// WithContentLength sets the Content-Length of the upload, for readers whose size the
+// HTTP client cannot determine. Some storage services reject uploads without it.
+func WithContentLength(n int64) UploadOption {
+	return func(o *uploadOptions) {
+		o.contentLength = n
+	}
+}
+
 // WithHeader adds a custom header to the upload request
 func WithHeader(key, value string) UploadOption {
 	return func(o *uploadOptions) {
@@ -0,0 +1,179 @@
+package presigned
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingTransport counts requests before delegating to the wrapped transport
+type recordingTransport struct {
+	next  http.RoundTripper
+	calls int32
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.calls, 1)
+	return t.next.RoundTrip(req)
+}
+
+func TestClient_WithHTTPClient(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt so the retry option is exercised with the custom client
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport := &recordingTransport{next: http.DefaultTransport}
+	var progressed int64
+	client := NewClient(
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithRetry(2, time.Millisecond),
+		WithProgress(func(n int64) { progressed = n }),
+	)
+
+	if err := client.Upload(context.Background(), server.URL+"/upload", bytes.NewReader([]byte("hello"))); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if got := atomic.LoadInt32(&transport.calls); got != 2 {
+		t.Fatalf("expected custom client to send 2 requests, got %d", got)
+	}
+	if progressed != 5 {
+		t.Fatalf("expected progress to report 5 bytes, got %d", progressed)
+	}
+}
+
+func TestClient_WithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	tlsConfig := &tls.Config{RootCAs: pool}
+
+	t.Run("untrusted certificate fails", func(t *testing.T) {
+		client := NewClient(WithRetry(1, time.Millisecond))
+		if err := client.Upload(context.Background(), server.URL, bytes.NewReader([]byte("x"))); err == nil {
+			t.Fatal("expected TLS verification error")
+		}
+	})
+
+	t.Run("custom roots", func(t *testing.T) {
+		client := NewClient(WithTLSConfig(tlsConfig), WithRetry(1, time.Millisecond))
+		if err := client.Upload(context.Background(), server.URL, bytes.NewReader([]byte("x"))); err != nil {
+			t.Fatalf("upload: %v", err)
+		}
+	})
+
+	t.Run("composes with custom client", func(t *testing.T) {
+		base := &http.Transport{}
+		httpClient := &http.Client{Transport: base, Timeout: time.Minute}
+		client := NewClient(WithTLSConfig(tlsConfig), WithHTTPClient(httpClient))
+
+		if err := client.Upload(context.Background(), server.URL, bytes.NewReader([]byte("x"))); err != nil {
+			t.Fatalf("upload: %v", err)
+		}
+		if client.httpClient.Timeout != time.Minute {
+			t.Fatalf("expected custom client settings to be kept")
+		}
+		if httpClient.Transport != base || (base.TLSClientConfig != nil && base.TLSClientConfig.RootCAs != nil) {
+			t.Fatal("caller's client and transport must not be modified")
+		}
+	})
+}
+
+func TestClient_UploadFile(t *testing.T) {
+	type received struct {
+		contentType   string
+		contentLength int64
+		body          string
+	}
+	var attempts int32
+	var last received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		last = received{contentType: r.Header.Get("Content-Type"), contentLength: r.ContentLength, body: string(body)}
+		// Fail the first attempt of the retry case so the file has to be sent again
+		if r.URL.Path == "/flaky" && atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeFile := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		return path
+	}
+	pngData := "\x89PNG\r\n\x1a\n" + "image bytes"
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     []UploadOption
+		wantType string
+		wantBody string
+	}{
+		{"by extension", writeFile("cat.png", pngData), nil, "image/png", pngData},
+		{"sniffed without extension", writeFile("report", "%PDF-1.7 document"), nil, "application/pdf", "%PDF-1.7 document"},
+		{"text", writeFile("notes.txt", "plain notes"), nil, "text/plain; charset=utf-8", "plain notes"},
+		{"explicit type wins", writeFile("data.png", pngData), []UploadOption{WithContentType("application/x-custom")}, "application/x-custom", pngData},
+	}
+	client := NewClient(WithRetry(1, time.Millisecond))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := client.UploadFile(context.Background(), server.URL+"/upload", tt.path, tt.opts...); err != nil {
+				t.Fatalf("upload file: %v", err)
+			}
+			if last.contentType != tt.wantType {
+				t.Fatalf("expected content type %q, got %q", tt.wantType, last.contentType)
+			}
+			if last.contentLength != int64(len(tt.wantBody)) || last.body != tt.wantBody {
+				t.Fatalf("expected %d bytes %q, got %d bytes %q", len(tt.wantBody), tt.wantBody, last.contentLength, last.body)
+			}
+		})
+	}
+
+	t.Run("retry resends the whole file", func(t *testing.T) {
+		var progressed int64
+		client := NewClient(WithRetry(2, time.Millisecond), WithProgress(func(n int64) { progressed = n }))
+		path := writeFile("retry.txt", "sent twice")
+		if err := client.UploadFile(context.Background(), server.URL+"/flaky", path); err != nil {
+			t.Fatalf("upload file: %v", err)
+		}
+		if last.body != "sent twice" {
+			t.Fatalf("expected the retry to send the whole file, got %q", last.body)
+		}
+		if progressed != int64(len("sent twice")) {
+			t.Fatalf("expected progress to restart with the retry, got %d", progressed)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if err := client.UploadFile(context.Background(), server.URL+"/upload", filepath.Join(dir, "missing.txt")); !os.IsNotExist(errors.Unwrap(err)) {
+			t.Fatalf("expected not exist error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package presigned
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DownloadResumable downloads presignedURL into w and returns the size of the downloaded
+// data. When the connection drops, the download resumes where it stopped with a Range
+// request carrying the ETag of the first response in If-Match, so bytes of a source that
+// changed in between are never stitched onto the earlier ones: the server answers 412 and
+// the download restarts from the beginning. Sources without a strong ETag restart instead
+// of resuming. Failed requests are retried as configured by WithRetry; a request that made
+// progress does not count against the attempts. Progress is reported through WithProgress.
+//
+// After a restart onto a smaller source, bytes past the returned size are left in w, so
+// callers writing to a file should truncate it to the returned size.
+//
+// Example:
+//
+//	file, _ := os.Create("video.mp4")
+//	size, err := client.DownloadResumable(ctx, presignedURL, file)
+//	file.Truncate(size)
+func (c *Client) DownloadResumable(ctx context.Context, presignedURL string, w io.WriterAt) (int64, error) {
+	var (
+		offset  int64
+		total   int64 = -1
+		etag    string
+		lastErr error
+	)
+	for attempt := 0; attempt < c.retryAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return offset, ctx.Err()
+			case <-time.After(c.retryDelay * time.Duration(attempt)):
+			}
+		}
+
+		// Resuming needs an ETag to guard the range; without one start over
+		if offset > 0 && etag == "" {
+			offset, total = 0, -1
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, presignedURL, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create request: %w", err)
+		}
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			req.Header.Set("If-Match", etag)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("download failed: %w", err)
+			continue
+		}
+
+		start := int64(0)
+		switch resp.StatusCode {
+		case http.StatusOK:
+			// A full response, also sent by servers ignoring Range, restarts the data
+			etag = strongETag(resp.Header.Get("ETag"))
+			total = resp.ContentLength
+		case http.StatusPartialContent:
+			rangeStart, rangeTotal, err := parseContentRange(resp.Header.Get("Content-Range"))
+			if err != nil || rangeStart != offset {
+				resp.Body.Close()
+				return offset, fmt.Errorf("download failed: unexpected Content-Range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+			}
+			if served := strongETag(resp.Header.Get("ETag")); served != "" && served != etag {
+				// A server that ignored If-Match sent bytes of another version
+				resp.Body.Close()
+				offset, total, etag = 0, -1, ""
+				lastErr = errors.New("download failed: source changed during download")
+				continue
+			}
+			start, total = rangeStart, rangeTotal
+		case http.StatusPreconditionFailed, http.StatusRequestedRangeNotSatisfiable:
+			// The source changed since the first response; start over
+			resp.Body.Close()
+			offset, total, etag = 0, -1, ""
+			lastErr = fmt.Errorf("download failed: source changed during download (%s)", resp.Status)
+			continue
+		default:
+			resp.Body.Close()
+			lastErr = fmt.Errorf("download failed with status: %s", resp.Status)
+			// Don't retry on client errors (4xx)
+			if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+				return offset, lastErr
+			}
+			continue
+		}
+
+		n, err := c.copyAt(w, resp.Body, start)
+		resp.Body.Close()
+		offset = start + n
+		if err == nil && (total < 0 || offset >= total) {
+			return offset, nil
+		}
+		if n > 0 {
+			attempt = -1 // Progress was made, so the next failure starts a fresh count
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("download interrupted at byte %d: %w", offset, err)
+		} else {
+			lastErr = fmt.Errorf("download interrupted at byte %d of %d", offset, total)
+		}
+		if ctx.Err() != nil {
+			return offset, ctx.Err()
+		}
+	}
+
+	return offset, fmt.Errorf("download failed after %d attempts: %w", c.retryAttempts, lastErr)
+}
+
+// copyAt copies body into w from offset on, reporting the bytes downloaded so far through
+// the progress callback
+func (c *Client) copyAt(w io.WriterAt, body io.Reader, offset int64) (int64, error) {
+	if c.progressFunc != nil {
+		body = &progressReader{reader: body, bytesRead: offset, callback: c.progressFunc}
+	}
+	return io.Copy(io.NewOffsetWriter(w, offset), body)
+}
+
+// strongETag returns etag unless it is empty or weak; If-Match only matches strong ETags
+func strongETag(etag string) string {
+	if strings.HasPrefix(etag, "W/") {
+		return ""
+	}
+	return etag
+}
+
+// parseContentRange parses "bytes <start>-<end>/<total>" into its start and total; an
+// unknown total ("*") is returned as -1
+func parseContentRange(header string) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	byteRange, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	first, _, ok := strings.Cut(byteRange, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	if size == "*" {
+		return start, -1, nil
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	return start, total, nil
+}
@@ -0,0 +1,142 @@
+package presigned
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rangeServer serves one version of a file with its ETag, honoring Range and If-Match.
+// The first request is cut off after dropAfter bytes, like a dropped connection.
+type rangeServer struct {
+	mu        sync.Mutex
+	data      string
+	etag      string
+	dropAfter int
+	requests  []string // Range, If-Match and status of each request
+	onDrop    func(s *rangeServer)
+}
+
+func (s *rangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data, etag := s.data, s.etag
+	drop := len(s.requests) == 0
+	record := func(status int) {
+		s.requests = append(s.requests, fmt.Sprintf("range=%q if-match=%q %d", r.Header.Get("Range"), r.Header.Get("If-Match"), status))
+	}
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != etag {
+		record(http.StatusPreconditionFailed)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	start, status := 0, http.StatusOK
+	if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+		fmt.Sscanf(spec, "%d-", &start)
+		status = http.StatusPartialContent
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+	}
+	record(status)
+	s.mu.Unlock()
+
+	body := data[start:]
+	w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+	w.WriteHeader(status)
+	if drop {
+		w.Write([]byte(body[:s.dropAfter]))
+		w.(http.Flusher).Flush()
+		if s.onDrop != nil {
+			s.mu.Lock()
+			s.onDrop(s)
+			s.mu.Unlock()
+		}
+		panic(http.ErrAbortHandler)
+	}
+	w.Write([]byte(body))
+}
+
+func TestClient_DownloadResumable(t *testing.T) {
+	download := func(t *testing.T, server *rangeServer, opts ...ClientOption) (string, int64) {
+		t.Helper()
+		httpServer := httptest.NewServer(server)
+		defer httpServer.Close()
+
+		path := filepath.Join(t.TempDir(), "download")
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("create file: %v", err)
+		}
+		defer file.Close()
+		client := NewClient(append([]ClientOption{WithRetry(3, time.Millisecond)}, opts...)...)
+		size, err := client.DownloadResumable(context.Background(), httpServer.URL+"/video.mp4", file)
+		if err != nil {
+			t.Fatalf("download resumable: %v", err)
+		}
+		if err := file.Truncate(size); err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read file: %v", err)
+		}
+		return string(got), size
+	}
+
+	t.Run("resumes after a disconnect", func(t *testing.T) {
+		var progressed int64
+		server := &rangeServer{data: "0123456789abcdefghij", etag: `"v1"`, dropAfter: 8}
+		got, size := download(t, server, WithProgress(func(n int64) { progressed = n }))
+		if got != server.data || size != int64(len(server.data)) {
+			t.Fatalf("expected %q, got %q (%d bytes)", server.data, got, size)
+		}
+		want := []string{
+			`range="" if-match="" 200`,
+			`range="bytes=8-" if-match="\"v1\"" 206`,
+		}
+		if strings.Join(server.requests, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("expected requests\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(server.requests, "\n"))
+		}
+		if progressed != size {
+			t.Fatalf("expected progress to reach %d, got %d", size, progressed)
+		}
+	})
+
+	t.Run("changed source restarts", func(t *testing.T) {
+		server := &rangeServer{data: "old version of the file", etag: `"v1"`, dropAfter: 10}
+		server.onDrop = func(s *rangeServer) {
+			s.data, s.etag = "new version", `"v2"`
+		}
+		got, size := download(t, server)
+		if got != "new version" || size != int64(len("new version")) {
+			t.Fatalf("expected only the new version, got %q (%d bytes)", got, size)
+		}
+		want := []string{
+			`range="" if-match="" 200`,
+			`range="bytes=10-" if-match="\"v1\"" 412`,
+			`range="" if-match="" 200`,
+		}
+		if strings.Join(server.requests, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("expected requests\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(server.requests, "\n"))
+		}
+	})
+
+	t.Run("without an etag restarts", func(t *testing.T) {
+		server := &rangeServer{data: "no validator here", dropAfter: 5}
+		got, _ := download(t, server)
+		if got != server.data {
+			t.Fatalf("expected %q, got %q", server.data, got)
+		}
+		if len(server.requests) != 2 || !strings.HasPrefix(server.requests[1], `range=""`) {
+			t.Fatalf("expected a full second request, got %v", server.requests)
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package presigned
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Signature validation errors
 var (
@@ -21,13 +24,56 @@ var (
 
 	// ErrInvalidSignature is returned when the signature is invalid
 	ErrInvalidSignature = errors.New("presigned: invalid signature")
+
+	// ErrNotInManifest is returned when a validly signed URL was not issued by SignManifest
+	ErrNotInManifest = errors.New("presigned: URL is not part of a manifest")
+
+	// ErrKeyOutsideScope is returned when an object key is outside the prefix set with WithKeyPrefix
+	ErrKeyOutsideScope = errors.New("presigned: object key outside signer scope")
+
+	// ErrUnsupportedSignatureVersion is returned when the v query parameter names a signature
+	// version this signer cannot verify
+	ErrUnsupportedSignatureVersion = errors.New("presigned: unsupported signature version")
+
+	// ErrHeaderMismatch is returned when a request lacks a header the URL was signed with,
+	// or sends a different value
+	ErrHeaderMismatch = errors.New("presigned: request header does not match signed header")
 )
 
+// Manifest errors
+var (
+	// ErrInvalidManifest is returned when a manifest has no keys or repeats a key
+	ErrInvalidManifest = errors.New("presigned: invalid manifest")
+
+	// ErrManifestIncomplete is returned by CompleteManifest when some keys were not uploaded
+	ErrManifestIncomplete = errors.New("presigned: manifest upload incomplete")
+)
+
+// InvalidAttemptError describes a request rejected by the validation middleware, as passed
+// to the handler set with WithInvalidAttemptHandler
+type InvalidAttemptError struct {
+	RemoteIP  string // Client address of the request, without the port
+	ObjectKey string // Object key the request targeted; empty when the path does not match the URL pattern
+	Err       error  // The validation error, e.g. ErrInvalidSignature or ErrExpired
+}
+
+func (e *InvalidAttemptError) Error() string {
+	return fmt.Sprintf("presigned: rejected request for %q from %s: %v", e.ObjectKey, e.RemoteIP, e.Err)
+}
+
+func (e *InvalidAttemptError) Unwrap() error {
+	return e.Err
+}
+
 // IsAuthError returns true if the error is a signature validation error
 func IsAuthError(err error) bool {
 	return errors.Is(err, ErrMissingSignature) ||
 		errors.Is(err, ErrMissingExpiration) ||
 		errors.Is(err, ErrInvalidExpiration) ||
 		errors.Is(err, ErrExpired) ||
-		errors.Is(err, ErrInvalidSignature)
+		errors.Is(err, ErrInvalidSignature) ||
+		errors.Is(err, ErrNotInManifest) ||
+		errors.Is(err, ErrKeyOutsideScope) ||
+		errors.Is(err, ErrUnsupportedSignatureVersion) ||
+		errors.Is(err, ErrHeaderMismatch)
 }
@@ -1,6 +1,7 @@
 package presigned
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/api"
 )
 
 // SignatureValidator is an interface for storage backends that support signature validation
@@ -25,16 +27,60 @@ type SignatureValidator interface {
 type Handlers struct {
 	blobStores     map[string]simplecontent.BlobStore
 	defaultBackend string
+	signer         *Signer // Validates requests for backends that do not validate signatures
+}
+
+// HandlerOption is a functional option for NewHandlers
+type HandlerOption func(*Handlers)
+
+// WithRequestSigner validates requests with signer when the storage backend does not
+// validate signatures itself, e.g. memory storage serving URLs signed by the application.
+// Backends implementing SignatureValidator keep validating with their own signers.
+func WithRequestSigner(signer *Signer) HandlerOption {
+	return func(h *Handlers) {
+		h.signer = signer
+	}
 }
 
 // NewHandlers creates a new set of presigned URL handlers
 // blobStores: map of storage backend name to BlobStore implementation
 // defaultBackend: name of the default storage backend to use (typically "fs")
-func NewHandlers(blobStores map[string]simplecontent.BlobStore, defaultBackend string) *Handlers {
-	return &Handlers{
+func NewHandlers(blobStores map[string]simplecontent.BlobStore, defaultBackend string, opts ...HandlerOption) *Handlers {
+	h := &Handlers{
 		blobStores:     blobStores,
 		defaultBackend: defaultBackend,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// validateWithSigner checks a request the backend does not validate against the signer set
+// with WithRequestSigner. It writes the error response and returns false when the request
+// is rejected.
+func (h *Handlers) validateWithSigner(w http.ResponseWriter, r *http.Request, blobStore simplecontent.BlobStore) bool {
+	if validator, ok := blobStore.(SignatureValidator); ok && validator.IsSignedURLEnabled() {
+		return true
+	}
+	if h.signer == nil || !h.signer.IsEnabled() {
+		return true
+	}
+	err := h.signer.ValidateRequest(r)
+	if err == nil {
+		return true
+	}
+	h.signer.reportInvalidAttempt(r, err)
+	log.Printf("Presigned signature validation failed for %s: %v", r.URL.Path, err)
+	switch {
+	case errors.Is(err, ErrMissingSignature):
+		api.WriteError(w, http.StatusUnauthorized, "missing_signature", "signature parameter is required", nil)
+	case errors.Is(err, ErrMissingExpiration):
+		api.WriteError(w, http.StatusUnauthorized, "missing_expires", "expires parameter is required", nil)
+	default:
+		api.WriteError(w, http.StatusForbidden, "invalid_signature", err.Error(), nil)
+	}
+	return false
 }
 
 // HandleUpload handles PUT requests to presigned upload URLs
@@ -50,14 +96,14 @@ func (h *Handlers) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	// chi.URLParam(r, "*") gives us everything after /upload/
 	objectKey := chi.URLParam(r, "*")
 	if objectKey == "" {
-		writeError(w, http.StatusBadRequest, "missing_object_key", "object key is required in URL path", nil)
+		api.WriteError(w, http.StatusBadRequest, "missing_object_key", "object key is required in URL path", nil)
 		return
 	}
 
 	// Get the default storage backend (assumes filesystem)
 	blobStore, ok := h.blobStores[h.defaultBackend]
 	if !ok {
-		writeError(w, http.StatusInternalServerError, "storage_backend_not_found",
+		api.WriteError(w, http.StatusInternalServerError, "storage_backend_not_found",
 			fmt.Sprintf("storage backend %s not found", h.defaultBackend), nil)
 		return
 	}
@@ -69,35 +115,39 @@ func (h *Handlers) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		expiresStr := r.URL.Query().Get("expires")
 
 		if signature == "" {
-			writeError(w, http.StatusUnauthorized, "missing_signature", "signature parameter is required", nil)
+			api.WriteError(w, http.StatusUnauthorized, "missing_signature", "signature parameter is required", nil)
 			return
 		}
 		if expiresStr == "" {
-			writeError(w, http.StatusUnauthorized, "missing_expires", "expires parameter is required", nil)
+			api.WriteError(w, http.StatusUnauthorized, "missing_expires", "expires parameter is required", nil)
 			return
 		}
 
 		expiresAt, err := strconv.ParseInt(expiresStr, 10, 64)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_expires", "expires parameter must be a valid timestamp", nil)
+			api.WriteError(w, http.StatusBadRequest, "invalid_expires", "expires parameter must be a valid timestamp", nil)
 			return
 		}
 
 		// Validate signature
 		if err := validator.ValidateUploadSignature(objectKey, signature, expiresAt); err != nil {
 			log.Printf("Presigned upload signature validation failed for objectKey %s: %v", objectKey, err)
-			writeError(w, http.StatusForbidden, "invalid_signature", err.Error(), nil)
+			api.WriteError(w, http.StatusForbidden, "invalid_signature", err.Error(), nil)
 			return
 		}
 
 		log.Printf("Presigned upload signature validated for objectKey: %s", objectKey)
 	}
 
+	if !h.validateWithSigner(w, r, blobStore) {
+		return
+	}
+
 	// Upload the file to storage
 	err := blobStore.Upload(r.Context(), objectKey, r.Body)
 	if err != nil {
 		log.Printf("Presigned upload failed for objectKey %s: %v", objectKey, err)
-		writeError(w, http.StatusInternalServerError, "upload_failed",
+		api.WriteError(w, http.StatusInternalServerError, "upload_failed",
 			fmt.Sprintf("failed to upload file: %v", err), nil)
 		return
 	}
@@ -120,7 +170,7 @@ func (h *Handlers) HandleDownload(w http.ResponseWriter, r *http.Request) {
 	// Extract object key from URL path
 	objectKey := chi.URLParam(r, "*")
 	if objectKey == "" {
-		writeError(w, http.StatusBadRequest, "missing_object_key", "object key is required in URL path", nil)
+		api.WriteError(w, http.StatusBadRequest, "missing_object_key", "object key is required in URL path", nil)
 		return
 	}
 
@@ -129,7 +179,7 @@ func (h *Handlers) HandleDownload(w http.ResponseWriter, r *http.Request) {
 	// Get the default storage backend (assumes filesystem)
 	blobStore, ok := h.blobStores[h.defaultBackend]
 	if !ok {
-		writeError(w, http.StatusInternalServerError, "storage_backend_not_found",
+		api.WriteError(w, http.StatusInternalServerError, "storage_backend_not_found",
 			fmt.Sprintf("storage backend %s not found", h.defaultBackend), nil)
 		return
 	}
@@ -141,35 +191,39 @@ func (h *Handlers) HandleDownload(w http.ResponseWriter, r *http.Request) {
 		expiresStr := r.URL.Query().Get("expires")
 
 		if signature == "" {
-			writeError(w, http.StatusUnauthorized, "missing_signature", "signature parameter is required", nil)
+			api.WriteError(w, http.StatusUnauthorized, "missing_signature", "signature parameter is required", nil)
 			return
 		}
 		if expiresStr == "" {
-			writeError(w, http.StatusUnauthorized, "missing_expires", "expires parameter is required", nil)
+			api.WriteError(w, http.StatusUnauthorized, "missing_expires", "expires parameter is required", nil)
 			return
 		}
 
 		expiresAt, err := strconv.ParseInt(expiresStr, 10, 64)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_expires", "expires parameter must be a valid timestamp", nil)
+			api.WriteError(w, http.StatusBadRequest, "invalid_expires", "expires parameter must be a valid timestamp", nil)
 			return
 		}
 
 		// Validate signature
 		if err := validator.ValidateDownloadSignature(objectKey, signature, expiresAt, filename); err != nil {
 			log.Printf("Presigned download signature validation failed for objectKey %s: %v", objectKey, err)
-			writeError(w, http.StatusForbidden, "invalid_signature", err.Error(), nil)
+			api.WriteError(w, http.StatusForbidden, "invalid_signature", err.Error(), nil)
 			return
 		}
 
 		log.Printf("Presigned download signature validated for objectKey: %s", objectKey)
 	}
 
+	if !h.validateWithSigner(w, r, blobStore) {
+		return
+	}
+
 	// Download and serve the file
 	rc, err := blobStore.Download(r.Context(), objectKey)
 	if err != nil {
 		log.Printf("Presigned download failed for objectKey %s: %v", objectKey, err)
-		writeError(w, http.StatusNotFound, "download_failed", "object not found", nil)
+		api.WriteError(w, http.StatusNotFound, "download_failed", "object not found", nil)
 		return
 	}
 	defer rc.Close()
@@ -177,6 +231,8 @@ func (h *Handlers) HandleDownload(w http.ResponseWriter, r *http.Request) {
 	// Set content headers
 	if meta, err := blobStore.GetObjectMeta(r.Context(), objectKey); err == nil {
 		w.Header().Set("Content-Type", meta.ContentType)
+		// Known length lets clients detect truncated transfers
+		w.Header().Set("Content-Length", strconv.FormatInt(meta.Size, 10))
 	}
 
 	if filename != "" {
@@ -201,14 +257,14 @@ func (h *Handlers) HandlePreview(w http.ResponseWriter, r *http.Request) {
 	// Extract object key from URL path
 	objectKey := chi.URLParam(r, "*")
 	if objectKey == "" {
-		writeError(w, http.StatusBadRequest, "missing_object_key", "object key is required in URL path", nil)
+		api.WriteError(w, http.StatusBadRequest, "missing_object_key", "object key is required in URL path", nil)
 		return
 	}
 
 	// Get the default storage backend (assumes filesystem)
 	blobStore, ok := h.blobStores[h.defaultBackend]
 	if !ok {
-		writeError(w, http.StatusInternalServerError, "storage_backend_not_found",
+		api.WriteError(w, http.StatusInternalServerError, "storage_backend_not_found",
 			fmt.Sprintf("storage backend %s not found", h.defaultBackend), nil)
 		return
 	}
@@ -220,35 +276,39 @@ func (h *Handlers) HandlePreview(w http.ResponseWriter, r *http.Request) {
 		expiresStr := r.URL.Query().Get("expires")
 
 		if signature == "" {
-			writeError(w, http.StatusUnauthorized, "missing_signature", "signature parameter is required", nil)
+			api.WriteError(w, http.StatusUnauthorized, "missing_signature", "signature parameter is required", nil)
 			return
 		}
 		if expiresStr == "" {
-			writeError(w, http.StatusUnauthorized, "missing_expires", "expires parameter is required", nil)
+			api.WriteError(w, http.StatusUnauthorized, "missing_expires", "expires parameter is required", nil)
 			return
 		}
 
 		expiresAt, err := strconv.ParseInt(expiresStr, 10, 64)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid_expires", "expires parameter must be a valid timestamp", nil)
+			api.WriteError(w, http.StatusBadRequest, "invalid_expires", "expires parameter must be a valid timestamp", nil)
 			return
 		}
 
 		// Validate signature
 		if err := validator.ValidatePreviewSignature(objectKey, signature, expiresAt); err != nil {
 			log.Printf("Presigned preview signature validation failed for objectKey %s: %v", objectKey, err)
-			writeError(w, http.StatusForbidden, "invalid_signature", err.Error(), nil)
+			api.WriteError(w, http.StatusForbidden, "invalid_signature", err.Error(), nil)
 			return
 		}
 
 		log.Printf("Presigned preview signature validated for objectKey: %s", objectKey)
 	}
 
+	if !h.validateWithSigner(w, r, blobStore) {
+		return
+	}
+
 	// Download and serve the file (same as download but without Content-Disposition header)
 	rc, err := blobStore.Download(r.Context(), objectKey)
 	if err != nil {
 		log.Printf("Presigned preview failed for objectKey %s: %v", objectKey, err)
-		writeError(w, http.StatusNotFound, "preview_failed", "object not found", nil)
+		api.WriteError(w, http.StatusNotFound, "preview_failed", "object not found", nil)
 		return
 	}
 	defer rc.Close()
@@ -256,6 +316,8 @@ func (h *Handlers) HandlePreview(w http.ResponseWriter, r *http.Request) {
 	// Set content type for inline preview
 	if meta, err := blobStore.GetObjectMeta(r.Context(), objectKey); err == nil {
 		w.Header().Set("Content-Type", meta.ContentType)
+		// Known length lets clients detect truncated transfers
+		w.Header().Set("Content-Length", strconv.FormatInt(meta.Size, 10))
 	}
 
 	// Stream file to response
@@ -271,23 +333,3 @@ func (h *Handlers) Mount(r chi.Router) {
 	r.Get("/download/*", h.HandleDownload)
 	r.Get("/preview/*", h.HandlePreview)
 }
-
-// writeError writes a JSON error response
-func writeError(w http.ResponseWriter, status int, code, message string, details interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-
-	errorResponse := map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":    code,
-			"message": message,
-		},
-	}
-
-	if details != nil {
-		errorResponse["error"].(map[string]interface{})["details"] = details
-	}
-
-	// Simple JSON encoding
-	fmt.Fprintf(w, `{"error":{"code":"%s","message":"%s"}}`, code, message)
-}
@@ -0,0 +1,98 @@
+package presigned
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"strings"
+)
+
+// signatureLen is the length of a hex-encoded HMAC-SHA256 signature
+const signatureLen = 2 * sha256.Size
+
+// maxPooledPayload bounds the payload buffer kept by a pooled hasher, so one huge path
+// does not pin memory
+const maxPooledPayload = 4 << 10
+
+// hasher is an HMAC-SHA256 instance keyed with the signer's secret, pooled with the
+// buffer its payloads are built in
+type hasher struct {
+	mac     hash.Hash
+	payload []byte
+}
+
+func (s *Signer) newHasher() *hasher {
+	return &hasher{mac: hmac.New(sha256.New, s.secretKey), payload: make([]byte, 0, 256)}
+}
+
+// sign writes the hex HMAC-SHA256 of the payload built by appendPayload to dst. The
+// hasher is reset before every use, so no state carries over between signatures.
+func (s *Signer) sign(dst *[signatureLen]byte, appendPayload func([]byte) []byte) {
+	h := s.hashers.Get().(*hasher)
+	h.mac.Reset()
+	h.payload = appendPayload(h.payload[:0])
+	h.mac.Write(h.payload)
+
+	var sum [sha256.Size]byte
+	hex.Encode(dst[:], h.mac.Sum(sum[:0]))
+
+	if cap(h.payload) <= maxPooledPayload {
+		s.hashers.Put(h)
+	}
+}
+
+// parsePattern splits the URL pattern around its {key} placeholder
+func (s *Signer) parsePattern() {
+	prefix, suffix, found := strings.Cut(s.urlPattern, "{key}")
+	s.patternPrefix, s.patternSuffix, s.patternValid = prefix, suffix, found
+}
+
+// signatureEqual compares a signature with the expected one in constant time. Only the
+// length, which is public, can end the comparison early.
+func signatureEqual(signature string, expected *[signatureLen]byte) bool {
+	if len(signature) != signatureLen {
+		return false
+	}
+	var diff byte
+	for i := 0; i < signatureLen; i++ {
+		diff |= signature[i] ^ expected[i]
+	}
+	return subtle.ConstantTimeByteEq(diff, 0) == 1
+}
+
+// scanSignatureQuery reads the signature, expires and version parameters from a raw query
+// without allocating. ok is false when the query holds other parameters or escaped values,
+// which need the full parse; the first value of each parameter wins, as with url.Values.Get.
+func scanSignatureQuery(rawQuery string) (signature, expires, version string, ok bool) {
+	sawSignature, sawExpires, sawVersion := false, false, false
+	for rawQuery != "" {
+		var pair string
+		pair, rawQuery, _ = strings.Cut(rawQuery, "&")
+		if pair == "" {
+			continue
+		}
+		if strings.ContainsAny(pair, "%+;") {
+			return "", "", "", false
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		switch key {
+		case "signature":
+			if !sawSignature {
+				signature, sawSignature = value, true
+			}
+		case "expires":
+			if !sawExpires {
+				expires, sawExpires = value, true
+			}
+		case versionParam:
+			if !sawVersion {
+				version, sawVersion = value, true
+			}
+		default:
+			return "", "", "", false
+		}
+	}
+	return signature, expires, version, true
+}
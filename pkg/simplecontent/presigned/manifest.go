@@ -0,0 +1,156 @@
+package presigned
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// manifestParam is the query parameter binding an upload URL to its manifest
+const manifestParam = "manifest"
+
+// Manifest is a set of presigned upload URLs issued together by SignManifest. Every URL
+// carries the manifest ID and expires at the same time, so the set lapses as a unit.
+type Manifest struct {
+	ID        string            `json:"id"`
+	Keys      []string          `json:"keys"`
+	URLs      map[string]string `json:"urls"` // object key -> presigned PUT URL
+	ExpiresAt time.Time         `json:"expires_at"`
+	Signature string            `json:"signature"` // Covers ID, keys and expiry; checked by CompleteManifest
+}
+
+// SignManifest signs a PUT URL for each object key, built from the URL pattern like the
+// URLs accepted by ExtractObjectKey. The URLs share one manifest ID and expiration; they
+// are absolute when WithBaseURL is set. A zero expiresIn uses the default expiration.
+//
+// Example:
+//
+//	m, err := signer.SignManifest([]string{"doc.pdf", "doc/attachment-1.png"}, 30*time.Minute)
+//	// m.URLs["doc.pdf"]: /upload/doc.pdf?manifest=9f2c...&signature=abc123...&expires=1696789012
+func (s *Signer) SignManifest(keys []string, expiresIn time.Duration) (*Manifest, error) {
+	if len(s.secretKey) == 0 {
+		return nil, ErrNoSecretKey
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrInvalidManifest)
+	}
+	if !strings.Contains(s.urlPattern, "{key}") {
+		return nil, fmt.Errorf("URL pattern does not contain {key} placeholder")
+	}
+	if expiresIn == 0 {
+		expiresIn = s.defaultExpiration
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate manifest ID: %w", err)
+	}
+	expiresAt := time.Now().Add(expiresIn).Unix()
+
+	m := &Manifest{
+		ID:        hex.EncodeToString(idBytes),
+		Keys:      make([]string, 0, len(keys)),
+		URLs:      make(map[string]string, len(keys)),
+		ExpiresAt: time.Unix(expiresAt, 0).UTC(),
+	}
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("%w: empty key", ErrInvalidManifest)
+		}
+		if _, dup := m.URLs[key]; dup {
+			return nil, fmt.Errorf("%w: duplicate key %s", ErrInvalidManifest, key)
+		}
+		if err := s.checkKeyScope(key); err != nil {
+			return nil, err
+		}
+		path := strings.Replace(s.urlPattern, "{key}", key, 1) + "?" + manifestParam + "=" + m.ID
+		signed := s.signPathAt(http.MethodPut, path, expiresAt, nil)
+		if !s.relativeURLs {
+			signed = s.baseURL + signed
+		}
+		m.Keys = append(m.Keys, key)
+		m.URLs[key] = signed
+	}
+	m.Signature = s.manifestSignature(m.ID, m.Keys, expiresAt)
+	return m, nil
+}
+
+// ValidateManifestRequest validates an upload request made to a URL from SignManifest and
+// returns the manifest ID and object key. The key belongs to the manifest when the
+// signature, which covers the manifest ID, is valid; URLs from SignURL fail with
+// ErrNotInManifest.
+func (s *Signer) ValidateManifestRequest(r *http.Request) (manifestID, objectKey string, err error) {
+	if err := s.ValidateRequest(r); err != nil {
+		return "", "", err
+	}
+	manifestID = r.URL.Query().Get(manifestParam)
+	if manifestID == "" {
+		return "", "", ErrNotInManifest
+	}
+	objectKey, err = s.ExtractObjectKey(r.URL.Path)
+	if err != nil {
+		return "", "", err
+	}
+	return manifestID, objectKey, nil
+}
+
+// CompleteManifest confirms that every key of the manifest was uploaded to store. The
+// manifest signature is checked first, so a manifest handed back by a client cannot have
+// keys removed. Missing keys are listed in the ErrManifestIncomplete error. Completion is
+// allowed after the manifest expires, since each upload was checked when it was made.
+func (s *Signer) CompleteManifest(ctx context.Context, m *Manifest, store simplecontent.BlobStore) error {
+	if len(s.secretKey) == 0 {
+		return ErrNoSecretKey
+	}
+	if m == nil || len(m.Keys) == 0 {
+		return fmt.Errorf("%w: no keys", ErrInvalidManifest)
+	}
+	expected := s.manifestSignature(m.ID, m.Keys, m.ExpiresAt.Unix())
+	if !hmac.Equal([]byte(m.Signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+
+	var missing []string
+	for _, key := range m.Keys {
+		exists, err := objectExists(ctx, store, key)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", key, err)
+		}
+		if !exists {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s", ErrManifestIncomplete, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// manifestSignature signs the manifest ID, keys and expiration
+func (s *Signer) manifestSignature(id string, keys []string, expiresAt int64) string {
+	payload := "MANIFEST|" + id + "|" + strconv.FormatInt(expiresAt, 10) + "|" + strings.Join(keys, "\n")
+	return s.generateSignature(payload)
+}
+
+// objectExists reports whether objectKey is stored on the backend
+func objectExists(ctx context.Context, store simplecontent.BlobStore, objectKey string) (bool, error) {
+	if checker, ok := store.(simplecontent.ObjectExistsChecker); ok {
+		return checker.ObjectExists(ctx, objectKey)
+	}
+	if _, err := store.GetObjectMeta(ctx, objectKey); err != nil {
+		if errors.Is(err, simplecontent.ErrObjectNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,139 @@
+package presigned
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	memorystorage "github.com/tendant/simple-content/pkg/simplecontent/storage/memory"
+)
+
+func TestSignManifest(t *testing.T) {
+	signer := New(WithSecretKey("test-secret-key-at-least-32-bytes!"))
+	keys := []string{"docs/report.pdf", "docs/report/attachment-1.png", "docs/report/attachment-2.png"}
+
+	m, err := signer.SignManifest(keys, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("sign manifest: %v", err)
+	}
+	if m.ID == "" || m.Signature == "" {
+		t.Fatalf("expected manifest ID and signature, got %+v", m)
+	}
+	if len(m.URLs) != len(keys) {
+		t.Fatalf("expected %d URLs, got %d", len(keys), len(m.URLs))
+	}
+
+	// Every URL validates to its own key and carries the shared manifest ID and expiry
+	var expires string
+	for _, key := range keys {
+		signed := m.URLs[key]
+		req := httptest.NewRequest(http.MethodPut, signed, nil)
+		id, gotKey, err := signer.ValidateManifestRequest(req)
+		if err != nil {
+			t.Fatalf("validate %s: %v", key, err)
+		}
+		if id != m.ID || gotKey != key {
+			t.Fatalf("expected %s/%s, got %s/%s", m.ID, key, id, gotKey)
+		}
+		if expires == "" {
+			expires = req.URL.Query().Get("expires")
+		} else if req.URL.Query().Get("expires") != expires {
+			t.Fatalf("expected all URLs to expire together")
+		}
+	}
+
+	t.Run("KeyOutsideManifest", func(t *testing.T) {
+		// Moving a signature to another key breaks it
+		moved := strings.Replace(m.URLs[keys[0]], "report.pdf", "other.pdf", 1)
+		_, _, err := signer.ValidateManifestRequest(httptest.NewRequest(http.MethodPut, moved, nil))
+		if !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("expected ErrInvalidSignature, got %v", err)
+		}
+
+		// A single signed URL is not part of any manifest
+		single, err := signer.SignURL(http.MethodPut, "/upload/docs/report.pdf", time.Minute)
+		if err != nil {
+			t.Fatalf("sign url: %v", err)
+		}
+		_, _, err = signer.ValidateManifestRequest(httptest.NewRequest(http.MethodPut, single, nil))
+		if !errors.Is(err, ErrNotInManifest) {
+			t.Fatalf("expected ErrNotInManifest, got %v", err)
+		}
+
+		// Swapping in another manifest ID breaks the signature
+		swapped := strings.Replace(m.URLs[keys[0]], "manifest="+m.ID, "manifest=0123456789abcdef0123456789abcdef", 1)
+		_, _, err = signer.ValidateManifestRequest(httptest.NewRequest(http.MethodPut, swapped, nil))
+		if !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("expected ErrInvalidSignature for swapped manifest, got %v", err)
+		}
+	})
+
+	t.Run("InvalidKeys", func(t *testing.T) {
+		if _, err := signer.SignManifest(nil, time.Minute); !errors.Is(err, ErrInvalidManifest) {
+			t.Fatalf("expected ErrInvalidManifest for no keys, got %v", err)
+		}
+		if _, err := signer.SignManifest([]string{"a", "a"}, time.Minute); !errors.Is(err, ErrInvalidManifest) {
+			t.Fatalf("expected ErrInvalidManifest for duplicate keys, got %v", err)
+		}
+		if _, err := New().SignManifest([]string{"a"}, time.Minute); !errors.Is(err, ErrNoSecretKey) {
+			t.Fatalf("expected ErrNoSecretKey, got %v", err)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		expired, err := signer.SignManifest([]string{"a.txt", "b.txt"}, -time.Minute)
+		if err != nil {
+			t.Fatalf("sign manifest: %v", err)
+		}
+		for _, key := range expired.Keys {
+			_, _, err := signer.ValidateManifestRequest(httptest.NewRequest(http.MethodPut, expired.URLs[key], nil))
+			if !errors.Is(err, ErrExpired) {
+				t.Fatalf("expected ErrExpired for %s, got %v", key, err)
+			}
+		}
+	})
+}
+
+func TestCompleteManifest(t *testing.T) {
+	ctx := context.Background()
+	signer := New(WithSecretKey("test-secret-key-at-least-32-bytes!"))
+	store := memorystorage.New()
+
+	m, err := signer.SignManifest([]string{"bundle/doc.pdf", "bundle/a.png", "bundle/b.png"}, time.Hour)
+	if err != nil {
+		t.Fatalf("sign manifest: %v", err)
+	}
+
+	upload := func(key string) {
+		t.Helper()
+		if err := store.Upload(ctx, key, strings.NewReader(key)); err != nil {
+			t.Fatalf("upload %s: %v", key, err)
+		}
+	}
+
+	upload("bundle/doc.pdf")
+	upload("bundle/a.png")
+	err = signer.CompleteManifest(ctx, m, store)
+	if !errors.Is(err, ErrManifestIncomplete) {
+		t.Fatalf("expected ErrManifestIncomplete, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "bundle/b.png") || strings.Contains(err.Error(), "bundle/a.png") {
+		t.Fatalf("expected only the missing key in the error, got %v", err)
+	}
+
+	upload("bundle/b.png")
+	if err := signer.CompleteManifest(ctx, m, store); err != nil {
+		t.Fatalf("complete manifest: %v", err)
+	}
+
+	// Dropping a key from the manifest invalidates it
+	tampered := *m
+	tampered.Keys = m.Keys[:2]
+	if err := signer.CompleteManifest(ctx, &tampered, store); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for tampered manifest, got %v", err)
+	}
+}
@@ -2,7 +2,9 @@ package presigned
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 )
 
@@ -43,6 +45,7 @@ func ValidateMiddlewareWithSigner(signer *Signer, next http.Handler) http.Handle
 
 		// Validate the request signature
 		if err := signer.ValidateRequest(r); err != nil {
+			signer.reportInvalidAttempt(r, err)
 			handleValidationError(w, err)
 			return
 		}
@@ -50,6 +53,7 @@ func ValidateMiddlewareWithSigner(signer *Signer, next http.Handler) http.Handle
 		// Extract object key from URL path
 		objectKey, err := signer.ExtractObjectKey(r.URL.Path)
 		if err != nil {
+			signer.reportInvalidAttempt(r, err)
 			log.Printf("presigned: failed to extract object key: %v", err)
 			http.Error(w, "Invalid upload URL", http.StatusBadRequest)
 			return
@@ -79,6 +83,20 @@ func ObjectKeyFromContext(ctx context.Context) string {
 	return ""
 }
 
+// reportInvalidAttempt passes a rejected request to the handler set with
+// WithInvalidAttemptHandler, if any
+func (s *Signer) reportInvalidAttempt(r *http.Request, err error) {
+	if s.invalidAttempts == nil {
+		return
+	}
+	remoteIP, _, splitErr := net.SplitHostPort(r.RemoteAddr)
+	if splitErr != nil {
+		remoteIP = r.RemoteAddr
+	}
+	objectKey, _ := s.ExtractObjectKey(r.URL.Path)
+	s.invalidAttempts(r, &InvalidAttemptError{RemoteIP: remoteIP, ObjectKey: objectKey, Err: err})
+}
+
 // handleValidationError writes an appropriate HTTP error response based on the validation error
 func handleValidationError(w http.ResponseWriter, err error) {
 	switch {
@@ -92,6 +110,12 @@ func handleValidationError(w http.ResponseWriter, err error) {
 		http.Error(w, "Presigned URL has expired", http.StatusForbidden)
 	case err == ErrInvalidSignature:
 		http.Error(w, "Invalid signature", http.StatusForbidden)
+	case errors.Is(err, ErrKeyOutsideScope):
+		http.Error(w, "Object key outside allowed scope", http.StatusForbidden)
+	case errors.Is(err, ErrUnsupportedSignatureVersion):
+		http.Error(w, "Unsupported signature version", http.StatusBadRequest)
+	case errors.Is(err, ErrHeaderMismatch):
+		http.Error(w, "Request headers do not match the signed headers", http.StatusForbidden)
 	default:
 		log.Printf("presigned: validation error: %v", err)
 		http.Error(w, "Authentication failed", http.StatusForbidden)
@@ -1,6 +1,10 @@
 package presigned
 
-import "time"
+import (
+	"net/http"
+	"strings"
+	"time"
+)
 
 // Option is a functional option for configuring a Signer
 type Option func(*Signer)
@@ -30,6 +34,45 @@ func WithURLPattern(pattern string) Option {
 	}
 }
 
+// WithBaseURL makes SignURL return absolute URLs against baseURL, e.g.
+// "https://files.example.com". The base URL is not part of the signature, so a proxy
+// may rewrite the host without breaking validation.
+func WithBaseURL(baseURL string) Option {
+	return func(s *Signer) {
+		s.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithRelativeURLs makes SignURL and SignURLWithBase return paths only, for clients served
+// from the same origin as the upload endpoints
+func WithRelativeURLs() Option {
+	return func(s *Signer) {
+		s.relativeURLs = true
+	}
+}
+
+// WithKeyPrefix scopes the signer to object keys under prefix, e.g. "tenant-a/". SignURL,
+// SignManifest and ValidateRequest then fail with ErrKeyOutsideScope for keys outside the
+// prefix, including keys that climb out of it with ".." segments. Include the trailing
+// slash: "tenant-a" would also admit "tenant-ab/".
+func WithKeyPrefix(prefix string) Option {
+	return func(s *Signer) {
+		s.keyPrefix = prefix
+	}
+}
+
+// WithInvalidAttemptHandler registers fn to be called by ValidateMiddlewareWithSigner for every
+// request it rejects, e.g. to count invalid signatures or alert on a burst of them. err is an
+// *InvalidAttemptError carrying the client IP and the attempted object key, and wraps the
+// validation error, so errors.Is tells ErrInvalidSignature from ErrExpired. Neither the
+// request nor err carry the secret key or the expected signature. fn runs before the error
+// response is written and should not block.
+func WithInvalidAttemptHandler(fn func(r *http.Request, err error)) Option {
+	return func(s *Signer) {
+		s.invalidAttempts = fn
+	}
+}
+
 // WithCustomPayloadFunc allows customizing the signature payload format
 // The function receives (method, path, expiresAt) and should return the payload string
 // Default format is: METHOD|PATH|EXPIRES
@@ -0,0 +1,60 @@
+package presigned
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// signedHeadersParam is the query parameter listing the headers a URL was signed with, one
+// "name:value" pair per value
+const signedHeadersParam = "headers"
+
+// SignOption configures a single signed URL
+type SignOption func(*signOptions)
+
+type signOptions struct {
+	headers []string // see canonicalHeaders
+}
+
+func newSignOptions(opts []SignOption) signOptions {
+	var o signOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithSignedHeaders binds a URL to request headers, e.g. {"Content-Type": "application/pdf"}
+// to only accept a PDF upload. The headers are listed in the URL and covered by its
+// signature; ValidateRequest rejects a request whose headers differ with ErrHeaderMismatch.
+// Names are case-insensitive; values must match exactly, apart from surrounding spaces.
+func WithSignedHeaders(headers map[string]string) SignOption {
+	return func(o *signOptions) {
+		o.headers = canonicalHeaders(headers)
+	}
+}
+
+// canonicalHeaders returns the headers as sorted "name:value" pairs with lowercase names, so
+// the signature does not depend on map order
+func canonicalHeaders(headers map[string]string) []string {
+	pairs := make([]string, 0, len(headers))
+	for name, value := range headers {
+		pairs = append(pairs, strings.ToLower(strings.TrimSpace(name))+":"+strings.TrimSpace(value))
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// checkSignedHeaders reports ErrHeaderMismatch unless the request carries every signed header
+// with its signed value
+func checkSignedHeaders(r *http.Request, headers []string) error {
+	for _, header := range headers {
+		name, value, _ := strings.Cut(header, ":")
+		if got := strings.TrimSpace(r.Header.Get(name)); got != value {
+			return fmt.Errorf("%w: %s", ErrHeaderMismatch, name)
+		}
+	}
+	return nil
+}
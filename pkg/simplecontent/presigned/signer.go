@@ -1,23 +1,41 @@
 package presigned
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/url"
+	pathpkg "path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// SignatureVersion1 is the signature version of URLs signed by this package. Its payload
+// is METHOD|PATH|EXPIRES, or the one built by WithCustomPayloadFunc.
+const SignatureVersion1 = "1"
+
+// versionParam is the query parameter naming the signature version of a URL. URLs signed
+// before it was added carry none and are verified as version 1.
+const versionParam = "v"
+
 // Signer generates and validates HMAC-signed presigned URLs
 type Signer struct {
 	secretKey          []byte
 	defaultExpiration  time.Duration
 	urlPattern         string // e.g., "/upload/{key}" or "/api/v1/upload/{key}"
 	customPayloadFunc  func(method, path string, expiresAt int64) string
+	baseURL            string // Prepended to signed paths, see WithBaseURL
+	relativeURLs       bool   // Return signed paths without any base URL, see WithRelativeURLs
+	keyPrefix          string // Object keys must start with this prefix, see WithKeyPrefix
+	invalidAttempts    func(r *http.Request, err error) // Called by the middleware on rejected requests, see WithInvalidAttemptHandler
+
+	// Parsed once by New so validation does not re-parse urlPattern
+	patternPrefix      string
+	patternSuffix      string
+	patternValid       bool
+
+	hashers            sync.Pool // *hasher, reused across signatures
 }
 
 // New creates a new Signer with the given options
@@ -30,70 +48,128 @@ func New(opts ...Option) *Signer {
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.parsePattern()
+	s.hashers.New = func() any { return s.newHasher() }
 
 	return s
 }
 
 // SignURL generates a presigned URL for the given HTTP method and path
-// Returns the complete URL with signature and expiration query parameters
+// Returns the complete URL with signature and expiration query parameters. The URL is
+// relative unless WithBaseURL is set, in which case it is absolute against the base URL.
+// Only the path and query are signed; if path is an absolute URL its scheme and host are
+// kept in the result but left out of the signature.
+//
+// Options such as WithSignedHeaders bind the URL to more of the request.
 //
 // Example:
 //   url, err := signer.SignURL("PUT", "/upload/myfile.pdf", 1*time.Hour)
-//   // Returns: /upload/myfile.pdf?signature=abc123...&expires=1696789012
-func (s *Signer) SignURL(method, path string, expiresIn time.Duration) (string, error) {
+//   // Returns: /upload/myfile.pdf?signature=abc123...&expires=1696789012&v=1
+func (s *Signer) SignURL(method, path string, expiresIn time.Duration, opts ...SignOption) (string, error) {
+	base, path := splitBaseURL(path)
+	if base == "" {
+		base = s.baseURL
+	}
+	return s.signWithBase(base, method, path, expiresIn, opts)
+}
+
+// signWithBase signs path and joins it to base, unless relative URLs are configured
+func (s *Signer) signWithBase(base, method, path string, expiresIn time.Duration, opts []SignOption) (string, error) {
+	signedPath, err := s.signPath(method, path, expiresIn, newSignOptions(opts).headers)
+	if err != nil {
+		return "", err
+	}
+	if s.relativeURLs {
+		return signedPath, nil
+	}
+	return base + signedPath, nil
+}
+
+// signPath appends the signature and expiration query parameters to path
+func (s *Signer) signPath(method, path string, expiresIn time.Duration, headers []string) (string, error) {
 	if len(s.secretKey) == 0 {
 		return "", ErrNoSecretKey
 	}
 
+	if err := s.checkPathScope(path); err != nil {
+		return "", err
+	}
+
 	if expiresIn == 0 {
 		expiresIn = s.defaultExpiration
 	}
 
 	// Calculate expiration timestamp
-	expiresAt := time.Now().Add(expiresIn).Unix()
-
-	// Create signature payload
-	payload := s.createPayload(method, path, expiresAt)
+	return s.signPathAt(method, path, time.Now().Add(expiresIn).Unix(), headers), nil
+}
 
+// signPathAt appends the signature and query parameters for a fixed expiration timestamp.
+// headers are the canonical signed headers, see canonicalHeaders.
+func (s *Signer) signPathAt(method, path string, expiresAt int64, headers []string) string {
 	// Generate HMAC-SHA256 signature
-	signature := s.generateSignature(payload)
+	var signature [signatureLen]byte
+	s.signRequestWithHeaders(&signature, method, path, expiresAt, headers)
 
 	// Build signed URL
 	separator := "?"
 	if strings.Contains(path, "?") {
 		separator = "&"
 	}
-	signedURL := fmt.Sprintf("%s%ssignature=%s&expires=%d",
-		path, separator, signature, expiresAt)
-
-	return signedURL, nil
+	signed := fmt.Sprintf("%s%ssignature=%s&expires=%d&%s=%s",
+		path, separator, signature[:], expiresAt, versionParam, SignatureVersion1)
+	for _, header := range headers {
+		signed += "&" + signedHeadersParam + "=" + url.QueryEscape(header)
+	}
+	return signed
 }
 
-// SignURLWithBase generates a presigned URL with a base URL prefix
+// SignURLWithBase generates a presigned URL with a base URL prefix, overriding WithBaseURL.
+// The base URL is not signed. With WithRelativeURLs the base URL is dropped.
 //
 // Example:
 //   url, err := signer.SignURLWithBase("https://api.example.com", "PUT", "/upload/myfile.pdf", 1*time.Hour)
-//   // Returns: https://api.example.com/upload/myfile.pdf?signature=abc123...&expires=1696789012
-func (s *Signer) SignURLWithBase(baseURL, method, path string, expiresIn time.Duration) (string, error) {
-	signedPath, err := s.SignURL(method, path, expiresIn)
-	if err != nil {
-		return "", err
+//   // Returns: https://api.example.com/upload/myfile.pdf?signature=abc123...&expires=1696789012&v=1
+func (s *Signer) SignURLWithBase(baseURL, method, path string, expiresIn time.Duration, opts ...SignOption) (string, error) {
+	return s.signWithBase(strings.TrimSuffix(baseURL, "/"), method, path, expiresIn, opts)
+}
+
+// splitBaseURL splits an absolute URL into its scheme and host and the rest. Paths are
+// returned unchanged with an empty base.
+func splitBaseURL(rawURL string) (string, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", rawURL
 	}
-	return baseURL + signedPath, nil
+	base := u.Scheme + "://" + u.Host
+	return base, strings.TrimPrefix(rawURL, base)
 }
 
 // ValidateRequest validates the signature and expiration of an HTTP request
-// Returns an error if the signature is invalid or the URL has expired
+// Returns an error if the signature is invalid or the URL has expired. The v query
+// parameter selects how the signature is verified; unknown versions are rejected with
+// ErrUnsupportedSignatureVersion. A URL signed with WithSignedHeaders also requires the
+// request to carry those headers, failing with ErrHeaderMismatch otherwise.
 func (s *Signer) ValidateRequest(r *http.Request) error {
+	// The scope applies even when signatures are not checked
+	if err := s.checkPathScope(r.URL.Path); err != nil {
+		return err
+	}
+
 	if len(s.secretKey) == 0 {
 		// No secret key configured - allow all requests (backward compatibility)
 		return nil
 	}
 
-	// Extract signature and expiration from query parameters
-	query := r.URL.Query()
-	signature := query.Get("signature")
-	expiresStr := query.Get("expires")
+	// Extract signature, expiration and version from query parameters. A query holding
+	// nothing else is scanned in place; any other parameter needs the full parse below.
+	signature, expiresStr, version, onlySignature := scanSignatureQuery(r.URL.RawQuery)
+	var query url.Values
+	if !onlySignature {
+		query = r.URL.Query()
+		signature = query.Get("signature")
+		expiresStr = query.Get("expires")
+		version = query.Get(versionParam)
+	}
 
 	if signature == "" {
 		return ErrMissingSignature
@@ -109,11 +185,13 @@ func (s *Signer) ValidateRequest(r *http.Request) error {
 
 	// Extract path without query parameters
 	path := r.URL.Path
-	if r.URL.RawQuery != "" {
-		// Preserve original query params (except signature and expires)
+	var headers []string
+	if !onlySignature {
+		headers = query[signedHeadersParam]
+		// Preserve original query params (except signature, expires, version and headers)
 		cleanQuery := url.Values{}
 		for k, v := range query {
-			if k != "signature" && k != "expires" {
+			if k != "signature" && k != "expires" && k != versionParam && k != signedHeadersParam {
 				cleanQuery[k] = v
 			}
 		}
@@ -122,25 +200,40 @@ func (s *Signer) ValidateRequest(r *http.Request) error {
 		}
 	}
 
-	// Validate signature
-	return s.Validate(r.Method, path, signature, expiresAt)
+	// Validate signature; the scope was checked above
+	switch version {
+	case "", SignatureVersion1:
+		if err := s.validate(r.Method, path, signature, expiresAt, headers); err != nil {
+			return err
+		}
+		return checkSignedHeaders(r, headers)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedSignatureVersion, version)
+	}
 }
 
-// Validate validates the signature and expiration for a given method, path, signature, and expiration timestamp
+// Validate validates the signature and expiration for a given method, path, signature, and expiration timestamp.
+// The signature is verified as SignatureVersion1.
 func (s *Signer) Validate(method, path, signature string, expiresAt int64) error {
+	if err := s.checkPathScope(path); err != nil {
+		return err
+	}
+	return s.validate(method, path, signature, expiresAt, nil)
+}
+
+// validate checks expiration and signature without the key scope
+func (s *Signer) validate(method, path, signature string, expiresAt int64, headers []string) error {
 	// Check expiration
 	if time.Now().Unix() > expiresAt {
 		return ErrExpired
 	}
 
-	// Recreate the payload that was signed
-	payload := s.createPayload(method, path, expiresAt)
-
-	// Generate expected signature
-	expectedSignature := s.generateSignature(payload)
+	// Generate expected signature from the payload that was signed
+	var expectedSignature [signatureLen]byte
+	s.signRequestWithHeaders(&expectedSignature, method, path, expiresAt, headers)
 
 	// Compare signatures using constant-time comparison to prevent timing attacks
-	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+	if !signatureEqual(signature, &expectedSignature) {
 		return ErrInvalidSignature
 	}
 
@@ -154,17 +247,13 @@ func (s *Signer) Validate(method, path, signature string, expiresAt int64) error
 //   key, err := signer.ExtractObjectKey("/upload/myfile.pdf")
 //   // Returns: "myfile.pdf"
 func (s *Signer) ExtractObjectKey(path string) (string, error) {
-	// Parse the URL pattern to find where {key} is located
-	pattern := s.urlPattern
-	placeholder := "{key}"
-
-	idx := strings.Index(pattern, placeholder)
-	if idx == -1 {
+	// The URL pattern was split around {key} by New
+	if !s.patternValid {
 		return "", fmt.Errorf("URL pattern does not contain {key} placeholder")
 	}
 
-	prefix := pattern[:idx]
-	suffix := pattern[idx+len(placeholder):]
+	prefix := s.patternPrefix
+	suffix := s.patternSuffix
 
 	// Remove prefix and suffix from path
 	if !strings.HasPrefix(path, prefix) {
@@ -180,24 +269,80 @@ func (s *Signer) ExtractObjectKey(path string) (string, error) {
 	return key, nil
 }
 
+// checkPathScope extracts the object key from a URL path, query included or not, and
+// checks it against the key prefix
+func (s *Signer) checkPathScope(path string) error {
+	if s.keyPrefix == "" {
+		return nil
+	}
+	path, _, _ = strings.Cut(path, "?")
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	key, err := s.ExtractObjectKey(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrKeyOutsideScope, err)
+	}
+	return s.checkKeyScope(key)
+}
+
+// checkKeyScope reports ErrKeyOutsideScope unless the key, with "." and ".." segments
+// resolved, starts with the key prefix
+func (s *Signer) checkKeyScope(key string) error {
+	if s.keyPrefix == "" {
+		return nil
+	}
+	cleaned := strings.TrimPrefix(pathpkg.Clean("/"+key), "/")
+	if !strings.HasPrefix(cleaned, s.keyPrefix) {
+		return fmt.Errorf("%w: %s", ErrKeyOutsideScope, key)
+	}
+	return nil
+}
+
 // IsEnabled returns true if signature validation is enabled (secret key is set)
 func (s *Signer) IsEnabled() bool {
 	return len(s.secretKey) > 0
 }
 
-// createPayload creates the signature payload
+// appendPayload appends the signature payload to buf
 // Default format: METHOD|PATH|EXPIRES
 // Can be customized using WithCustomPayloadFunc
-func (s *Signer) createPayload(method, path string, expiresAt int64) string {
+func (s *Signer) appendPayload(buf []byte, method, path string, expiresAt int64) []byte {
 	if s.customPayloadFunc != nil {
-		return s.customPayloadFunc(method, path, expiresAt)
+		return append(buf, s.customPayloadFunc(method, path, expiresAt)...)
 	}
-	return fmt.Sprintf("%s|%s|%d", method, path, expiresAt)
+	buf = append(buf, method...)
+	buf = append(buf, '|')
+	buf = append(buf, path...)
+	buf = append(buf, '|')
+	return strconv.AppendInt(buf, expiresAt, 10)
+}
+
+// signRequest writes the signature of a request to dst
+func (s *Signer) signRequest(dst *[signatureLen]byte, method, path string, expiresAt int64) {
+	s.signRequestWithHeaders(dst, method, path, expiresAt, nil)
+}
+
+// signRequestWithHeaders writes the signature of a request bound to the canonical signed
+// headers to dst. Without headers the payload is the one of signRequest, so URLs signed
+// without headers are unchanged.
+func (s *Signer) signRequestWithHeaders(dst *[signatureLen]byte, method, path string, expiresAt int64, headers []string) {
+	s.sign(dst, func(buf []byte) []byte {
+		buf = s.appendPayload(buf, method, path, expiresAt)
+		for _, header := range headers {
+			// Header values cannot contain a newline, so it separates them unambiguously
+			buf = append(buf, '\n')
+			buf = append(buf, header...)
+		}
+		return buf
+	})
 }
 
 // generateSignature generates HMAC-SHA256 signature for the given payload
 func (s *Signer) generateSignature(payload string) string {
-	h := hmac.New(sha256.New, s.secretKey)
-	h.Write([]byte(payload))
-	return hex.EncodeToString(h.Sum(nil))
+	var signature [signatureLen]byte
+	s.sign(&signature, func(buf []byte) []byte {
+		return append(buf, payload...)
+	})
+	return string(signature[:])
 }
@@ -0,0 +1,431 @@
+package presigned
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSigner_URLModes(t *testing.T) {
+	const secret = "test-secret-key-at-least-32-bytes!"
+
+	validate := func(t *testing.T, signer *Signer, method, target string) error {
+		t.Helper()
+		return signer.ValidateRequest(httptest.NewRequest(method, target, nil))
+	}
+
+	t.Run("AbsoluteWithBaseURL", func(t *testing.T) {
+		signer := New(WithSecretKey(secret), WithBaseURL("https://files.example.com/"))
+		signed, err := signer.SignURL(http.MethodPut, "/upload/docs/report.pdf", time.Hour)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		if !strings.HasPrefix(signed, "https://files.example.com/upload/docs/report.pdf?signature=") {
+			t.Fatalf("expected absolute URL, got %s", signed)
+		}
+		if err := validate(t, signer, http.MethodPut, signed); err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+
+		// A proxy forwarding to another host does not break the signature
+		rewritten := strings.Replace(signed, "https://files.example.com", "http://backend.internal:8080", 1)
+		if err := validate(t, signer, http.MethodPut, rewritten); err != nil {
+			t.Fatalf("validate after host rewrite: %v", err)
+		}
+
+		// The path is signed
+		tampered := strings.Replace(signed, "report.pdf", "other.pdf", 1)
+		if err := validate(t, signer, http.MethodPut, tampered); err != ErrInvalidSignature {
+			t.Fatalf("expected ErrInvalidSignature for changed path, got %v", err)
+		}
+	})
+
+	t.Run("AbsolutePathArgument", func(t *testing.T) {
+		signer := New(WithSecretKey(secret))
+		signed, err := signer.SignURL(http.MethodGet, "https://cdn.example.com/download/a.txt?filename=a.txt", time.Hour)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		if !strings.HasPrefix(signed, "https://cdn.example.com/download/a.txt?filename=a.txt&signature=") {
+			t.Fatalf("unexpected URL %s", signed)
+		}
+		if err := validate(t, signer, http.MethodGet, signed); err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+	})
+
+	t.Run("Relative", func(t *testing.T) {
+		signer := New(WithSecretKey(secret), WithBaseURL("https://files.example.com"), WithRelativeURLs())
+		for _, sign := range []func() (string, error){
+			func() (string, error) { return signer.SignURL(http.MethodPut, "/upload/a.txt", time.Hour) },
+			func() (string, error) {
+				return signer.SignURLWithBase("https://api.example.com", http.MethodPut, "/upload/a.txt", time.Hour)
+			},
+		} {
+			signed, err := sign()
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			if !strings.HasPrefix(signed, "/upload/a.txt?signature=") {
+				t.Fatalf("expected relative URL, got %s", signed)
+			}
+			if err := validate(t, signer, http.MethodPut, signed); err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+		}
+	})
+
+	t.Run("DefaultIsRelative", func(t *testing.T) {
+		signer := New(WithSecretKey(secret))
+		signed, err := signer.SignURL(http.MethodPut, "/upload/a.txt", time.Hour)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		if !strings.HasPrefix(signed, "/upload/a.txt?") {
+			t.Fatalf("expected relative URL, got %s", signed)
+		}
+	})
+}
+
+func TestSigner_KeyPrefix(t *testing.T) {
+	const secret = "test-secret-key-at-least-32-bytes!"
+	signer := New(WithSecretKey(secret), WithKeyPrefix("tenant-a/"))
+
+	signed, err := signer.SignURL(http.MethodPut, "/upload/tenant-a/file", time.Hour)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := signer.ValidateRequest(httptest.NewRequest(http.MethodPut, signed, nil)); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	if _, err := signer.SignURL(http.MethodPut, "/upload/tenant-b/file", time.Hour); !errors.Is(err, ErrKeyOutsideScope) {
+		t.Fatalf("expected ErrKeyOutsideScope when signing, got %v", err)
+	}
+	if _, err := signer.SignURL(http.MethodPut, "/upload/tenant-a/../tenant-b/file", time.Hour); !errors.Is(err, ErrKeyOutsideScope) {
+		t.Fatalf("expected ErrKeyOutsideScope for traversal, got %v", err)
+	}
+	if _, err := signer.SignManifest([]string{"tenant-a/doc.pdf", "tenant-b/file"}, time.Hour); !errors.Is(err, ErrKeyOutsideScope) {
+		t.Fatalf("expected ErrKeyOutsideScope for manifest, got %v", err)
+	}
+
+	// A validly signed URL for another tenant is still rejected
+	other, err := New(WithSecretKey(secret)).SignURL(http.MethodPut, "/upload/tenant-b/file", time.Hour)
+	if err != nil {
+		t.Fatalf("sign unscoped: %v", err)
+	}
+	err = signer.ValidateRequest(httptest.NewRequest(http.MethodPut, other, nil))
+	if !errors.Is(err, ErrKeyOutsideScope) {
+		t.Fatalf("expected ErrKeyOutsideScope when validating, got %v", err)
+	}
+	if !IsAuthError(err) {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler := ValidateMiddlewareWithSigner(signer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not run for a key outside the scope")
+	}))
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, other, nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+}
+
+// referenceSignature is the unpooled HMAC-SHA256 the signer must reproduce
+func referenceSignature(secret, method, path string, expiresAt int64) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(fmt.Sprintf("%s|%s|%d", method, path, expiresAt)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestSigner_PooledHashersReset(t *testing.T) {
+	const secret = "test-secret-key-at-least-32-bytes!"
+	signer := New(WithSecretKey(secret))
+	expiresAt := time.Now().Add(time.Hour).Unix()
+
+	// Sequential reuse: payloads of varying length must not leak into each other
+	for i := 0; i < 50; i++ {
+		path := "/upload/" + strings.Repeat("k", i%7) + fmt.Sprint(i)
+		want := referenceSignature(secret, "PUT", path, expiresAt)
+		var got [signatureLen]byte
+		signer.signRequest(&got, "PUT", path, expiresAt)
+		if string(got[:]) != want {
+			t.Fatalf("signature %d = %s, want %s", i, got[:], want)
+		}
+	}
+
+	// Concurrent reuse across goroutines
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				path := fmt.Sprintf("/upload/g%d/%d", g, i)
+				signedURL, err := signer.SignURL("PUT", path, time.Hour)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if err := signer.ValidateRequest(httptest.NewRequest("PUT", signedURL, nil)); err != nil {
+					errs <- fmt.Errorf("%s: %w", signedURL, err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	// Manifest payloads share the pool
+	if got, want := signer.generateSignature("any|payload"), hex.EncodeToString(hmacSum(secret, "any|payload")); got != want {
+		t.Fatalf("generateSignature = %s, want %s", got, want)
+	}
+}
+
+func hmacSum(secret, payload string) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+func TestSigner_QueryFastPath(t *testing.T) {
+	signer := New(WithSecretKey("test-secret-key-at-least-32-bytes!"))
+	signedURL, err := signer.SignURL("GET", "/download/a.txt", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(target string) error {
+		return signer.ValidateRequest(httptest.NewRequest("GET", target, nil))
+	}
+	if err := validate(signedURL); err != nil {
+		t.Fatalf("signed URL: %v", err)
+	}
+
+	// Escaped values take the full parse: %31 decodes to the leading 1 of expires
+	if !strings.Contains(signedURL, "expires=1") {
+		t.Fatalf("unexpected expires in %s", signedURL)
+	}
+	if err := validate(strings.Replace(signedURL, "expires=1", "expires=%31", 1)); err != nil {
+		t.Fatalf("escaped expires: %v", err)
+	}
+
+	// Parameters that were not signed still invalidate the signature
+	if err := validate(signedURL + "&x-id=1"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("extra parameter: got %v, want ErrInvalidSignature", err)
+	}
+
+	// A signature of the right length with one changed character is rejected
+	i := strings.Index(signedURL, "signature=") + len("signature=")
+	flipped := byte('0')
+	if signedURL[i] == '0' {
+		flipped = '1'
+	}
+	tampered := signedURL[:i] + string(flipped) + signedURL[i+1:]
+	if err := validate(tampered); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("tampered signature: got %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestSigner_SignatureVersion(t *testing.T) {
+	signer := New(WithSecretKey("test-secret-key-at-least-32-bytes!"))
+	validate := func(target string) error {
+		return signer.ValidateRequest(httptest.NewRequest("PUT", target, nil))
+	}
+
+	signedURL, err := signer.SignURL("PUT", "/upload/a.txt", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(signedURL, "&v="+SignatureVersion1) {
+		t.Fatalf("expected version parameter in %s", signedURL)
+	}
+	if err := validate(signedURL); err != nil {
+		t.Fatalf("v1 URL: %v", err)
+	}
+
+	// Query parameters before the signature take the full parse
+	withQuery, err := signer.SignURL("PUT", "/upload/a.txt?content_type=text", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate(withQuery); err != nil {
+		t.Fatalf("v1 URL with query: %v", err)
+	}
+
+	// URLs signed before the version parameter existed are verified as v1
+	legacy := strings.TrimSuffix(signedURL, "&v="+SignatureVersion1)
+	if err := validate(legacy); err != nil {
+		t.Fatalf("unversioned URL: %v", err)
+	}
+
+	// A v1 signature relabelled as v2 is rejected, not verified with the v1 payload
+	for _, spoofed := range []string{
+		strings.Replace(signedURL, "&v=1", "&v=2", 1),
+		strings.Replace(withQuery, "&v=1", "&v=2", 1),
+	} {
+		err := validate(spoofed)
+		if !errors.Is(err, ErrUnsupportedSignatureVersion) {
+			t.Fatalf("spoofed v2 %s: got %v, want ErrUnsupportedSignatureVersion", spoofed, err)
+		}
+		if !IsAuthError(err) {
+			t.Fatalf("expected auth error, got %v", err)
+		}
+	}
+}
+
+func TestSigner_InvalidAttemptHandler(t *testing.T) {
+	const secret = "test-secret-key-at-least-32-bytes!"
+	var attempts []error
+	signer := New(
+		WithSecretKey(secret),
+		WithInvalidAttemptHandler(func(r *http.Request, err error) {
+			attempts = append(attempts, err)
+		}),
+	)
+	handler := ValidateMiddlewareWithSigner(signer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(t *testing.T, target string) int {
+		req := httptest.NewRequest(http.MethodPut, target, nil)
+		req.RemoteAddr = "203.0.113.7:51234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	signed, err := signer.SignURL(http.MethodPut, "/upload/docs/report.pdf", time.Hour)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if code := serve(t, signed); code != http.StatusNoContent || len(attempts) != 0 {
+		t.Fatalf("valid request: got %d with %d attempts reported", code, len(attempts))
+	}
+
+	// A signature moved to another key
+	tampered := strings.Replace(signed, "report.pdf", "payroll.pdf", 1)
+	if code := serve(t, tampered); code != http.StatusForbidden {
+		t.Fatalf("tampered request: expected 403, got %d", code)
+	}
+	if len(attempts) != 1 {
+		t.Fatalf("expected 1 reported attempt, got %d", len(attempts))
+	}
+	var attempt *InvalidAttemptError
+	if !errors.As(attempts[0], &attempt) {
+		t.Fatalf("expected *InvalidAttemptError, got %T", attempts[0])
+	}
+	if !errors.Is(attempts[0], ErrInvalidSignature) || errors.Is(attempts[0], ErrExpired) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", attempts[0])
+	}
+	if attempt.RemoteIP != "203.0.113.7" || attempt.ObjectKey != "docs/payroll.pdf" {
+		t.Fatalf("unexpected attempt details: %+v", attempt)
+	}
+	if strings.Contains(attempt.Error(), secret) {
+		t.Fatalf("attempt error leaks the secret: %s", attempt.Error())
+	}
+
+	expired, err := signer.SignURL(http.MethodPut, "/upload/docs/report.pdf", -time.Minute)
+	if err != nil {
+		t.Fatalf("sign expired: %v", err)
+	}
+	serve(t, expired)
+	if len(attempts) != 2 || !errors.Is(attempts[1], ErrExpired) {
+		t.Fatalf("expected a reported ErrExpired, got %v", attempts)
+	}
+}
+
+func TestSigner_SignedHeaders(t *testing.T) {
+	signer := New(WithSecretKey("test-secret-key-at-least-32-bytes!"))
+	signed, err := signer.SignURL(http.MethodPut, "/upload/docs/report.pdf", time.Hour,
+		WithSignedHeaders(map[string]string{"Content-Type": "application/pdf"}))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if !strings.Contains(signed, "headers=content-type%3Aapplication%2Fpdf") {
+		t.Fatalf("expected the signed header in the URL, got %s", signed)
+	}
+
+	validate := func(target, contentType string) error {
+		req := httptest.NewRequest(http.MethodPut, target, nil)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		return signer.ValidateRequest(req)
+	}
+	if err := validate(signed, "application/pdf"); err != nil {
+		t.Fatalf("approved content type: %v", err)
+	}
+	for _, contentType := range []string{"image/png", ""} {
+		err := validate(signed, contentType)
+		if !errors.Is(err, ErrHeaderMismatch) {
+			t.Fatalf("content type %q: got %v, want ErrHeaderMismatch", contentType, err)
+		}
+		if !IsAuthError(err) {
+			t.Fatalf("expected auth error, got %v", err)
+		}
+	}
+
+	// Dropping or changing the signed header in the URL breaks the signature
+	for _, tampered := range []string{
+		strings.Replace(signed, "&headers=content-type%3Aapplication%2Fpdf", "", 1),
+		strings.Replace(signed, "application%2Fpdf", "image%2Fpng", 1),
+	} {
+		if err := validate(tampered, "image/png"); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("tampered %s: got %v, want ErrInvalidSignature", tampered, err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler := ValidateMiddlewareWithSigner(New(WithSecretKey("test-secret-key-at-least-32-bytes!")), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not run for a mismatched header")
+	}))
+	req := httptest.NewRequest(http.MethodPut, signed, nil)
+	req.Header.Set("Content-Type", "image/png")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+}
+
+func BenchmarkSigner_ValidateRequest(b *testing.B) {
+	signer := New(WithSecretKey("test-secret-key-at-least-32-bytes!"))
+	signedURL, err := signer.SignURL("GET", "/download/2024/10/report.pdf", time.Hour)
+	if err != nil {
+		b.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", signedURL, nil)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := signer.ValidateRequest(req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkSigner_SignURL(b *testing.B) {
+	signer := New(WithSecretKey("test-secret-key-at-least-32-bytes!"))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := signer.SignURL("PUT", "/upload/2024/10/report.pdf", time.Hour); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
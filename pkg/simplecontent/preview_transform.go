@@ -0,0 +1,105 @@
+package simplecontent
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PreviewTransformer turns an object's stored data into the data served as its preview,
+// e.g. a scaled-down image or markdown rendered as HTML
+type PreviewTransformer interface {
+	// Transform reads the original data of the given MIME type from src and returns the
+	// preview and its MIME type. The returned reader is closed by the caller.
+	Transform(ctx context.Context, src io.Reader, mimeType string) (io.ReadCloser, string, error)
+}
+
+// transformerRegistry maps MIME types, or "type/*" wildcards, to preview transformers
+type transformerRegistry map[string]PreviewTransformer
+
+// WithPreviewTransformer registers the transformer used for previews of mimeType, which can be
+// an exact type like "image/png" or a wildcard like "image/*". Exact types take precedence;
+// previews of types without a transformer are served unchanged.
+func WithPreviewTransformer(mimeType string, transformer PreviewTransformer) Option {
+	return func(s *service) {
+		if s.previewTransformers == nil {
+			s.previewTransformers = make(transformerRegistry)
+		}
+		s.previewTransformers[strings.ToLower(mimeType)] = transformer
+	}
+}
+
+// previewTransformerFor returns the transformer registered for mimeType, or nil to pass the
+// original data through
+func (s *service) previewTransformerFor(mimeType string) PreviewTransformer {
+	if len(s.previewTransformers) == 0 || mimeType == "" {
+		return nil
+	}
+	mimeType, _, _ = strings.Cut(strings.ToLower(mimeType), ";")
+	mimeType = strings.TrimSpace(mimeType)
+	if transformer, ok := s.previewTransformers[mimeType]; ok {
+		return transformer
+	}
+	if major, _, ok := strings.Cut(mimeType, "/"); ok {
+		return s.previewTransformers[major+"/*"]
+	}
+	return nil
+}
+
+// objectMimeType returns the MIME type recorded for an object, falling back to its object type
+func (s *service) objectMimeType(ctx context.Context, object *Object) string {
+	if metadata, err := s.repository.GetObjectMetadata(ctx, object.ID); err == nil && metadata != nil && metadata.MimeType != "" {
+		return metadata.MimeType
+	}
+	return object.ObjectType
+}
+
+// DownloadObjectPreview downloads an object for preview. When a transformer is registered for
+// the object's MIME type the data is passed through it; the returned meta then carries the
+// preview's content type and a Size of -1, as the preview length is not known up front.
+func (s *service) DownloadObjectPreview(ctx context.Context, id uuid.UUID) (io.ReadCloser, *ObjectMeta, error) {
+	reader, meta, err := s.DownloadObjectWithMeta(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	object, err := s.repository.GetObject(ctx, id)
+	if err != nil {
+		reader.Close()
+		return nil, nil, &ObjectError{ObjectID: id, Op: "preview", Err: err}
+	}
+
+	mimeType := s.objectMimeType(ctx, object)
+	if mimeType == "" {
+		mimeType = meta.ContentType
+	}
+	transformer := s.previewTransformerFor(mimeType)
+	if transformer == nil {
+		return reader, meta, nil
+	}
+
+	preview, previewType, err := transformer.Transform(ctx, reader, mimeType)
+	if err != nil {
+		reader.Close()
+		return nil, nil, &ObjectError{ObjectID: id, Op: "preview_transform", Err: err}
+	}
+	previewMeta := *meta
+	previewMeta.Size = -1
+	previewMeta.ContentType = previewType
+	return &previewReader{ReadCloser: preview, original: reader}, &previewMeta, nil
+}
+
+// previewReader closes the original download together with the transformed preview
+type previewReader struct {
+	io.ReadCloser
+	original io.Closer
+}
+
+func (r *previewReader) Close() error {
+	err := r.ReadCloser.Close()
+	if origErr := r.original.Close(); err == nil {
+		err = origErr
+	}
+	return err
+}
@@ -0,0 +1,19 @@
+package simplecontent
+
+// WithReadOnly runs the service in read-only mode, for deployments against a read replica
+// or standby. Every operation that would write metadata or object data, or hand out an
+// upload URL, fails with ErrReadOnly before touching the repository or a backend; reads
+// work normally.
+func WithReadOnly() Option {
+	return func(s *service) {
+		s.readOnly = true
+	}
+}
+
+// checkWritable fails with ErrReadOnly when the service runs in read-only mode
+func (s *service) checkWritable() error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
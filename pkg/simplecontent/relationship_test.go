@@ -0,0 +1,107 @@
+package simplecontent_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+func TestContentRelationships(t *testing.T) {
+	svc := setupTestService(t)
+	ctx := context.Background()
+
+	newContent := func(name string) *simplecontent.Content {
+		content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     name,
+		})
+		require.NoError(t, err)
+		return content
+	}
+
+	doc := newContent("Report")
+	attachment := newContent("Appendix")
+	related := newContent("Related report")
+
+	t.Run("LinkAttachment", func(t *testing.T) {
+		rel, err := svc.LinkContent(ctx, doc.ID, attachment.ID, simplecontent.RelationTypeAttachment)
+		require.NoError(t, err)
+		assert.Equal(t, doc.ID, rel.FromContentID)
+		assert.Equal(t, attachment.ID, rel.ToContentID)
+		assert.Equal(t, simplecontent.RelationTypeAttachment, rel.RelationType)
+
+		_, err = svc.LinkContent(ctx, doc.ID, related.ID, simplecontent.RelationTypeRelated)
+		require.NoError(t, err)
+
+		attachments, err := svc.ListRelated(ctx, doc.ID, simplecontent.RelationTypeAttachment)
+		require.NoError(t, err)
+		require.Len(t, attachments, 1)
+		assert.Equal(t, attachment.ID, attachments[0].ToContentID)
+
+		// Relationships are directed
+		reverse, err := svc.ListRelated(ctx, attachment.ID, simplecontent.RelationTypeAttachment)
+		require.NoError(t, err)
+		assert.Empty(t, reverse)
+	})
+
+	t.Run("LinkIsIdempotent", func(t *testing.T) {
+		_, err := svc.LinkContent(ctx, doc.ID, attachment.ID, simplecontent.RelationTypeAttachment)
+		require.NoError(t, err)
+
+		attachments, err := svc.ListRelated(ctx, doc.ID, simplecontent.RelationTypeAttachment)
+		require.NoError(t, err)
+		assert.Len(t, attachments, 1)
+	})
+
+	t.Run("ListAllIncludesDerived", func(t *testing.T) {
+		require.NoError(t, svc.UpdateContentStatus(ctx, doc.ID, simplecontent.ContentStatusUploaded))
+		thumb, err := svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+			ParentID: doc.ID,
+			OwnerID:  doc.OwnerID,
+			TenantID: doc.TenantID,
+			Variant:  "thumbnail_256",
+		})
+		require.NoError(t, err)
+
+		derived, err := svc.ListRelated(ctx, doc.ID, simplecontent.RelationTypeDerived)
+		require.NoError(t, err)
+		require.Len(t, derived, 1)
+		assert.Equal(t, thumb.ID, derived[0].ToContentID)
+
+		all, err := svc.ListRelated(ctx, doc.ID, "")
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+	})
+
+	t.Run("RejectsDerivedAndUnknownTypes", func(t *testing.T) {
+		_, err := svc.LinkContent(ctx, doc.ID, related.ID, simplecontent.RelationTypeDerived)
+		assert.ErrorIs(t, err, simplecontent.ErrInvalidRelationType)
+
+		_, err = svc.LinkContent(ctx, doc.ID, related.ID, simplecontent.RelationType("sibling"))
+		assert.ErrorIs(t, err, simplecontent.ErrInvalidRelationType)
+
+		_, err = svc.LinkContent(ctx, doc.ID, doc.ID, simplecontent.RelationTypeRelated)
+		assert.ErrorIs(t, err, simplecontent.ErrInvalidRelationType)
+	})
+
+	t.Run("LinkMissingContent", func(t *testing.T) {
+		_, err := svc.LinkContent(ctx, doc.ID, uuid.New(), simplecontent.RelationTypeReference)
+		assert.ErrorIs(t, err, simplecontent.ErrContentNotFound)
+	})
+
+	t.Run("Unlink", func(t *testing.T) {
+		require.NoError(t, svc.UnlinkContent(ctx, doc.ID, attachment.ID, simplecontent.RelationTypeAttachment))
+
+		attachments, err := svc.ListRelated(ctx, doc.ID, simplecontent.RelationTypeAttachment)
+		require.NoError(t, err)
+		assert.Empty(t, attachments)
+
+		err = svc.UnlinkContent(ctx, doc.ID, attachment.ID, simplecontent.RelationTypeAttachment)
+		assert.ErrorIs(t, err, simplecontent.ErrRelationshipNotFound)
+	})
+}
@@ -19,8 +19,22 @@ type Repository struct {
 	objects           map[uuid.UUID]*simplecontent.Object
 	objectMetadata    map[uuid.UUID]*simplecontent.ObjectMetadata
 	derivedContents   map[uuid.UUID]*simplecontent.DerivedContent
+	relationships     map[relationshipKey]*simplecontent.ContentRelationship
+	accessEvents      map[uuid.UUID][]*simplecontent.AccessEvent // content_id -> events in insertion order
+	metadataHistory   map[uuid.UUID][]*simplecontent.ContentMetadataVersion // content_id -> versions, oldest first
+	uploadIntents     map[uuid.UUID]*simplecontent.UploadIntent // object_id -> pending intent
 	objectsByContent  map[uuid.UUID][]uuid.UUID // content_id -> []object_id
 	objectsByKey      map[string]uuid.UUID      // "backend:key" -> object_id
+
+	locksMu           sync.Mutex
+	locks             map[string]chan struct{} // lock key -> semaphore holding one token while locked
+}
+
+// relationshipKey identifies a typed content relationship
+type relationshipKey struct {
+	from    uuid.UUID
+	to      uuid.UUID
+	relType simplecontent.RelationType
 }
 
 // New creates a new in-memory repository
@@ -31,6 +45,10 @@ func New() simplecontent.Repository {
 		objects:           make(map[uuid.UUID]*simplecontent.Object),
 		objectMetadata:    make(map[uuid.UUID]*simplecontent.ObjectMetadata),
 		derivedContents:   make(map[uuid.UUID]*simplecontent.DerivedContent),
+		relationships:     make(map[relationshipKey]*simplecontent.ContentRelationship),
+		accessEvents:      make(map[uuid.UUID][]*simplecontent.AccessEvent),
+		metadataHistory:   make(map[uuid.UUID][]*simplecontent.ContentMetadataVersion),
+		uploadIntents:     make(map[uuid.UUID]*simplecontent.UploadIntent),
 		objectsByContent:  make(map[uuid.UUID][]uuid.UUID),
 		objectsByKey:      make(map[string]uuid.UUID),
 	}
@@ -66,6 +84,14 @@ func (r *Repository) GetContent(ctx context.Context, id uuid.UUID) (*simpleconte
 	return &contentCopy, nil
 }
 
+func (r *Repository) ContentExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	content, exists := r.contents[id]
+	return exists && content.DeletedAt == nil, nil
+}
+
 func (r *Repository) GetContentsByIDs(ctx context.Context, ids []uuid.UUID) ([]*simplecontent.Content, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -114,6 +140,36 @@ func (r *Repository) DeleteContent(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (r *Repository) DeleteContentTree(ctx context.Context, contentIDs []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Validate everything first so a missing content leaves the tree untouched
+	for _, id := range contentIDs {
+		if _, exists := r.contents[id]; !exists {
+			return simplecontent.ErrContentNotFound
+		}
+	}
+
+	now := time.Now()
+	for _, id := range contentIDs {
+		c := r.contents[id]
+		c.DeletedAt = &now
+		c.UpdatedAt = now
+
+		for _, objectID := range r.objectsByContent[id] {
+			if object, exists := r.objects[objectID]; exists && object.DeletedAt == nil {
+				object.DeletedAt = &now
+				object.UpdatedAt = now
+			}
+		}
+
+		// Derived relationships are keyed by the derived content ID
+		delete(r.derivedContents, id)
+	}
+	return nil
+}
+
 func (r *Repository) ListContent(ctx context.Context, ownerID, tenantID uuid.UUID) ([]*simplecontent.Content, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -153,10 +209,114 @@ func (r *Repository) SetContentMetadata(ctx context.Context, metadata *simplecon
 	metadataCopy.UpdatedAt = time.Now()
 	
 	r.contentMetadata[metadata.ContentID] = &metadataCopy
+
+	history := r.metadataHistory[metadata.ContentID]
+	r.metadataHistory[metadata.ContentID] = append(history, &simplecontent.ContentMetadataVersion{
+		ContentID:  metadata.ContentID,
+		Version:    len(history) + 1,
+		Actor:      simplecontent.ActorFromContext(ctx),
+		RecordedAt: metadataCopy.UpdatedAt,
+		Metadata:   snapshotContentMetadata(&metadataCopy),
+	})
 	
 	return nil
 }
 
+func (r *Repository) UpdateContentTags(ctx context.Context, contentIDs []uuid.UUID, add, remove []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changed int64
+	now := time.Now()
+	for _, id := range contentIDs {
+		if _, exists := r.contents[id]; !exists {
+			continue
+		}
+		metadata, exists := r.contentMetadata[id]
+		if !exists {
+			metadata = &simplecontent.ContentMetadata{ContentID: id, CreatedAt: now}
+		}
+
+		tags, modified := applyTagChanges(metadata.Tags, add, remove)
+		if !modified {
+			continue
+		}
+		updated := *metadata
+		updated.Tags = tags
+		updated.UpdatedAt = now
+		r.contentMetadata[id] = &updated
+
+		history := r.metadataHistory[id]
+		r.metadataHistory[id] = append(history, &simplecontent.ContentMetadataVersion{
+			ContentID:  id,
+			Version:    len(history) + 1,
+			Actor:      simplecontent.ActorFromContext(ctx),
+			RecordedAt: now,
+			Metadata:   snapshotContentMetadata(&updated),
+		})
+		changed++
+	}
+	return changed, nil
+}
+
+// applyTagChanges returns tags without the removed ones and with the added ones appended,
+// keeping the existing order. A tag both added and removed is removed.
+func applyTagChanges(tags, add, remove []string) ([]string, bool) {
+	removed := make(map[string]bool, len(remove))
+	for _, tag := range remove {
+		removed[tag] = true
+	}
+	present := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags)+len(add))
+	modified := false
+	for _, tag := range tags {
+		if removed[tag] {
+			modified = true
+			continue
+		}
+		present[tag] = true
+		result = append(result, tag)
+	}
+	for _, tag := range add {
+		if removed[tag] || present[tag] {
+			continue
+		}
+		present[tag] = true
+		result = append(result, tag)
+		modified = true
+	}
+	return result, modified
+}
+
+// snapshotContentMetadata copies metadata so later edits to its map or tags don't change
+// recorded versions
+func snapshotContentMetadata(metadata *simplecontent.ContentMetadata) simplecontent.ContentMetadata {
+	snapshot := *metadata
+	if metadata.Tags != nil {
+		snapshot.Tags = append([]string(nil), metadata.Tags...)
+	}
+	if metadata.Metadata != nil {
+		snapshot.Metadata = make(map[string]interface{}, len(metadata.Metadata))
+		for k, v := range metadata.Metadata {
+			snapshot.Metadata[k] = v
+		}
+	}
+	return snapshot
+}
+
+func (r *Repository) ListContentMetadataHistory(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.ContentMetadataVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*simplecontent.ContentMetadataVersion, 0, len(r.metadataHistory[contentID]))
+	for _, version := range r.metadataHistory[contentID] {
+		versionCopy := *version
+		versionCopy.Metadata = snapshotContentMetadata(&version.Metadata)
+		result = append(result, &versionCopy)
+	}
+	return result, nil
+}
+
 func (r *Repository) GetContentMetadata(ctx context.Context, contentID uuid.UUID) (*simplecontent.ContentMetadata, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -268,6 +428,14 @@ func (r *Repository) GetObject(ctx context.Context, id uuid.UUID) (*simpleconten
 	return &objectCopy, nil
 }
 
+func (r *Repository) ObjectExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	object, exists := r.objects[id]
+	return exists && object.DeletedAt == nil, nil
+}
+
 func (r *Repository) GetObjectsByContentID(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.Object, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -329,6 +497,61 @@ func (r *Repository) GetObjectsByContentIDs(ctx context.Context, contentIDs []uu
 	return result, nil
 }
 
+func (r *Repository) ListObjects(ctx context.Context, filters simplecontent.ObjectListFilters) ([]*simplecontent.Object, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var candidates []uuid.UUID
+	if filters.ContentID != nil {
+		candidates = r.objectsByContent[*filters.ContentID]
+	} else {
+		for id := range r.objects {
+			candidates = append(candidates, id)
+		}
+	}
+
+	result := []*simplecontent.Object{}
+	for _, objectID := range candidates {
+		object, exists := r.objects[objectID]
+		if !exists || object.DeletedAt != nil {
+			continue
+		}
+		if filters.Status != nil && object.Status != *filters.Status {
+			continue
+		}
+		if filters.StorageBackendName != nil && object.StorageBackendName != *filters.StorageBackendName {
+			continue
+		}
+		if filters.ObjectKey != nil && object.ObjectKey != *filters.ObjectKey {
+			continue
+		}
+		objectCopy := *object
+		result = append(result, &objectCopy)
+	}
+
+	// Sort by version descending, newest first within a version
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Version != result[j].Version {
+			return result[i].Version > result[j].Version
+		}
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	// Apply pagination
+	if filters.Offset != nil && *filters.Offset > 0 {
+		if *filters.Offset >= len(result) {
+			return []*simplecontent.Object{}, nil
+		}
+		result = result[*filters.Offset:]
+	}
+
+	if filters.Limit != nil && *filters.Limit > 0 && *filters.Limit < len(result) {
+		result = result[:*filters.Limit]
+	}
+
+	return result, nil
+}
+
 func (r *Repository) GetObjectByObjectKeyAndStorageBackendName(ctx context.Context, objectKey, storageBackendName string) (*simplecontent.Object, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -438,6 +661,77 @@ func (r *Repository) GetObjectMetadataByObjectIDs(ctx context.Context, objectIDs
 	return result, nil
 }
 
+func (r *Repository) GetUploadStatusesByObjectIDs(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]*simplecontent.UploadStatus, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[uuid.UUID]*simplecontent.UploadStatus)
+	for _, objectID := range objectIDs {
+		object, exists := r.objects[objectID]
+		if !exists || object.DeletedAt != nil {
+			continue
+		}
+		status := &simplecontent.UploadStatus{
+			ObjectID:  objectID,
+			Status:    simplecontent.ObjectStatus(object.Status),
+			UpdatedAt: object.UpdatedAt,
+		}
+		if metadata, exists := r.objectMetadata[objectID]; exists {
+			status.SizeBytes = metadata.SizeBytes
+			if metadata.UpdatedAt.After(status.UpdatedAt) {
+				status.UpdatedAt = metadata.UpdatedAt
+			}
+		}
+		result[objectID] = status
+	}
+
+	return result, nil
+}
+
+func (r *Repository) GetContentStorageStats(ctx context.Context, contentID uuid.UUID) (int, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if content, exists := r.contents[contentID]; !exists || content.DeletedAt != nil {
+		return 0, 0, simplecontent.ErrContentNotFound
+	}
+
+	// Derived relationships are keyed by the derived content ID
+	children := make(map[uuid.UUID][]uuid.UUID)
+	for childID, derived := range r.derivedContents {
+		if content, exists := r.contents[childID]; exists && content.DeletedAt == nil {
+			children[derived.ParentID] = append(children[derived.ParentID], childID)
+		}
+	}
+
+	var objectCount int
+	var totalBytes int64
+	visited := map[uuid.UUID]bool{contentID: true}
+	queue := []uuid.UUID{contentID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, objectID := range r.objectsByContent[id] {
+			object, exists := r.objects[objectID]
+			if !exists || object.DeletedAt != nil {
+				continue
+			}
+			objectCount++
+			if metadata, exists := r.objectMetadata[objectID]; exists {
+				totalBytes += metadata.SizeBytes
+			}
+		}
+		for _, childID := range children[id] {
+			if !visited[childID] {
+				visited[childID] = true
+				queue = append(queue, childID)
+			}
+		}
+	}
+
+	return objectCount, totalBytes, nil
+}
+
 // Derived content operations
 
 func (r *Repository) CreateDerivedContentRelationship(ctx context.Context, params simplecontent.CreateDerivedContentParams) (*simplecontent.DerivedContent, error) {
@@ -451,6 +745,18 @@ func (r *Repository) CreateDerivedContentRelationship(ctx context.Context, param
 	if _, exists := r.contents[params.DerivedContentID]; !exists {
 		return nil, simplecontent.ErrContentNotFound
 	}
+	// A parent has one live derived content per non-empty variant, like the postgres unique
+	// index on the stored variant
+	if params.Variant != "" {
+		for _, existing := range r.derivedContents {
+			if existing.ParentID != params.ParentID || existing.ContentID == params.DerivedContentID || existing.Variant != params.Variant {
+				continue
+			}
+			if content, exists := r.contents[existing.ContentID]; exists && content.DeletedAt == nil {
+				return nil, fmt.Errorf("%w: %s of content %s", simplecontent.ErrVariantExists, params.Variant, params.ParentID)
+			}
+		}
+	}
 	
 	now := time.Now()
     derived := &simplecontent.DerivedContent{
@@ -500,18 +806,134 @@ func (r *Repository) ListDerivedContent(ctx context.Context, params simpleconten
 	return result, nil
 }
 
+func (r *Repository) ListDerivedContentWithDetails(ctx context.Context, params simplecontent.ListDerivedContentParams) ([]*simplecontent.DerivedContentWithDetails, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var derivedList []*simplecontent.DerivedContent
+	contents := make(map[uuid.UUID]*simplecontent.Content)
+	for _, derived := range r.derivedContents {
+		// Mirror the inner join with content: skip children that are missing or deleted
+		content, exists := r.contents[derived.ContentID]
+		if !exists || content.DeletedAt != nil {
+			continue
+		}
+		if r.matchesEnhancedFilters(derived, params) {
+			derivedCopy := *derived
+			derivedCopy.Status = content.Status
+			derivedCopy.DocumentType = content.DocumentType
+			contentCopy := *content
+			contents[derived.ContentID] = &contentCopy
+			derivedList = append(derivedList, &derivedCopy)
+		}
+	}
+
+	r.sortDerivedContent(derivedList, params)
+	derivedList = r.paginateDerivedContent(derivedList, params)
+
+	result := make([]*simplecontent.DerivedContentWithDetails, 0, len(derivedList))
+	for _, derived := range derivedList {
+		result = append(result, &simplecontent.DerivedContentWithDetails{
+			DerivedContent: derived,
+			Content:        contents[derived.ContentID],
+		})
+	}
+	return result, nil
+}
+
 func (r *Repository) GetDerivedRelationshipByContentID(ctx context.Context, contentID uuid.UUID) (*simplecontent.DerivedContent, error) {
     r.mu.RLock()
     defer r.mu.RUnlock()
 
     dc, exists := r.derivedContents[contentID]
     if !exists {
-        return nil, fmt.Errorf("derived relationship not found for content %s", contentID)
+        return nil, fmt.Errorf("%w: no derived relationship for content %s", simplecontent.ErrRelationshipNotFound, contentID)
     }
     copy := *dc
     return &copy, nil
 }
 
+func (r *Repository) UpdateDerivedContentRelationship(ctx context.Context, params simplecontent.UpdateDerivedContentParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dc, exists := r.derivedContents[params.DerivedContentID]
+	if !exists {
+		return fmt.Errorf("derived relationship not found for content %s", params.DerivedContentID)
+	}
+
+	dc.ProcessingMetadata = params.ProcessingMetadata
+	dc.UpdatedAt = time.Now()
+	return nil
+}
+
+// Typed content relationship operations
+
+func (r *Repository) CreateContentRelationship(ctx context.Context, relationship *simplecontent.ContentRelationship) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.contents[relationship.FromContentID]; !exists {
+		return simplecontent.ErrContentNotFound
+	}
+	if _, exists := r.contents[relationship.ToContentID]; !exists {
+		return simplecontent.ErrContentNotFound
+	}
+
+	key := relationshipKey{from: relationship.FromContentID, to: relationship.ToContentID, relType: relationship.RelationType}
+	if existing, exists := r.relationships[key]; exists {
+		// Linking is idempotent; keep the original creation time
+		relationship.CreatedAt = existing.CreatedAt
+		return nil
+	}
+
+	relCopy := *relationship
+	r.relationships[key] = &relCopy
+	return nil
+}
+
+func (r *Repository) DeleteContentRelationship(ctx context.Context, fromContentID, toContentID uuid.UUID, relType simplecontent.RelationType) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := relationshipKey{from: fromContentID, to: toContentID, relType: relType}
+	if _, exists := r.relationships[key]; !exists {
+		return simplecontent.ErrRelationshipNotFound
+	}
+	delete(r.relationships, key)
+	return nil
+}
+
+func (r *Repository) ListContentRelationships(ctx context.Context, params simplecontent.ListContentRelationshipsParams) ([]*simplecontent.ContentRelationship, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*simplecontent.ContentRelationship
+	for key, rel := range r.relationships {
+		if key.from != params.FromContentID {
+			continue
+		}
+		if params.RelationType != "" && key.relType != params.RelationType {
+			continue
+		}
+		// Skip links to deleted content
+		if target, exists := r.contents[key.to]; !exists || target.DeletedAt != nil {
+			continue
+		}
+		relCopy := *rel
+		result = append(result, &relCopy)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ToContentID.String() < result[j].ToContentID.String()
+	})
+
+	return result, nil
+}
+
 // Enhanced filtering logic for derived content
 func (r *Repository) matchesEnhancedFilters(derived *simplecontent.DerivedContent, params simplecontent.ListDerivedContentParams) bool {
 	// Existing logic for backward compatibility
@@ -590,6 +1012,25 @@ func (r *Repository) matchesEnhancedFilters(derived *simplecontent.DerivedConten
 		}
 	}
 
+	// Derivation processing status filtering (computed from the derived content's status)
+	if len(params.DerivedStatuses) > 0 {
+		content, exists := r.contents[derived.ContentID]
+		if !exists {
+			return false
+		}
+		actualStatus := simplecontent.DerivationStatusFromContentStatus(content.Status)
+		found := false
+		for _, status := range params.DerivedStatuses {
+			if status == actualStatus {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
 	// Temporal filtering
 	if params.CreatedAfter != nil && derived.CreatedAt.Before(*params.CreatedAfter) {
 		return false
@@ -694,6 +1135,11 @@ func (r *Repository) ListContentWithFilters(ctx context.Context, filters simplec
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	where, err := filters.Where.Normalized()
+	if err != nil {
+		return nil, err
+	}
+
 	var result []*simplecontent.Content
 
 	for _, content := range r.contents {
@@ -796,6 +1242,15 @@ func (r *Repository) ListContentWithFilters(ctx context.Context, filters simplec
 		if filters.UpdatedBefore != nil && content.UpdatedAt.After(*filters.UpdatedBefore) {
 			continue
 		}
+		if filters.WithoutUploadedObjects && r.hasUploadedObject(content.ID) {
+			continue
+		}
+		if _, derived := r.derivedContents[content.ID]; filters.OriginalsOnly && derived {
+			continue
+		}
+		if !where.Matches(content) {
+			continue
+		}
 
 		result = append(result, content)
 	}
@@ -843,6 +1298,15 @@ func (r *Repository) ListContentWithFilters(ctx context.Context, filters simplec
 		result = result[:*filters.Limit]
 	}
 
+	if filters.IncludePrimaryObject {
+		// Copies, so the stored content does not keep the primary object
+		for i, content := range result {
+			withObject := *content
+			withObject.PrimaryObject = r.primaryObject(content.ID)
+			result[i] = &withObject
+		}
+	}
+
 	return result, nil
 }
 
@@ -850,6 +1314,11 @@ func (r *Repository) CountContentWithFilters(ctx context.Context, filters simple
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	where, err := filters.Where.Normalized()
+	if err != nil {
+		return 0, err
+	}
+
 	var count int64
 
 	for _, content := range r.contents {
@@ -952,6 +1421,15 @@ func (r *Repository) CountContentWithFilters(ctx context.Context, filters simple
 		if filters.UpdatedBefore != nil && content.UpdatedAt.After(*filters.UpdatedBefore) {
 			continue
 		}
+		if filters.WithoutUploadedObjects && r.hasUploadedObject(content.ID) {
+			continue
+		}
+		if _, derived := r.derivedContents[content.ID]; filters.OriginalsOnly && derived {
+			continue
+		}
+		if !where.Matches(content) {
+			continue
+		}
 
 		count++
 	}
@@ -963,6 +1441,11 @@ func (r *Repository) GetContentStatistics(ctx context.Context, filters simplecon
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	where, err := filters.Where.Normalized()
+	if err != nil {
+		return nil, err
+	}
+
 	result := &simplecontent.ContentStatisticsResult{
 		ByStatus:         make(map[string]int64),
 		ByTenant:         make(map[string]int64),
@@ -971,6 +1454,7 @@ func (r *Repository) GetContentStatistics(ctx context.Context, filters simplecon
 	}
 
 	var oldest, newest *time.Time
+	blobs := make(map[string]bool) // checksums seen so far
 
 	for _, content := range r.contents {
 		// Skip deleted unless specifically requested
@@ -1072,6 +1556,15 @@ func (r *Repository) GetContentStatistics(ctx context.Context, filters simplecon
 		if filters.UpdatedBefore != nil && content.UpdatedAt.After(*filters.UpdatedBefore) {
 			continue
 		}
+		if filters.WithoutUploadedObjects && r.hasUploadedObject(content.ID) {
+			continue
+		}
+		if _, derived := r.derivedContents[content.ID]; filters.OriginalsOnly && derived {
+			continue
+		}
+		if !where.Matches(content) {
+			continue
+		}
 
 		// Count this content
 		result.TotalCount++
@@ -1115,10 +1608,176 @@ func (r *Repository) GetContentStatistics(ctx context.Context, filters simplecon
 				newest = &t
 			}
 		}
+
+		// Deduplication: references beyond the first of a checksum are saved bytes
+		if options.IncludeDedupBreakdown {
+			if metadata, ok := r.contentMetadata[content.ID]; ok && metadata.Checksum != "" {
+				result.TotalReferences++
+				if blobs[metadata.Checksum] {
+					result.BytesSaved += metadata.FileSize
+				}
+				blobs[metadata.Checksum] = true
+			}
+		}
 	}
+	result.UniqueBlobs = int64(len(blobs))
 
 	result.OldestContent = oldest
 	result.NewestContent = newest
 
 	return result, nil
 }
+
+// hasUploadedObject reports whether a live object of the content holds uploaded data. The
+// caller must hold r.mu.
+func (r *Repository) hasUploadedObject(contentID uuid.UUID) bool {
+	for _, objectID := range r.objectsByContent[contentID] {
+		object, ok := r.objects[objectID]
+		if !ok || object.DeletedAt != nil {
+			continue
+		}
+		switch simplecontent.ObjectStatus(object.Status) {
+		case simplecontent.ObjectStatusUploaded, simplecontent.ObjectStatusProcessed:
+			return true
+		}
+	}
+	return false
+}
+
+// primaryObject returns the newest live uploaded or processed object of the content with
+// its metadata, or nil when there is none. The caller must hold r.mu.
+func (r *Repository) primaryObject(contentID uuid.UUID) *simplecontent.PrimaryObject {
+	var primary *simplecontent.Object
+	for _, objectID := range r.objectsByContent[contentID] {
+		object, ok := r.objects[objectID]
+		if !ok || object.DeletedAt != nil {
+			continue
+		}
+		switch simplecontent.ObjectStatus(object.Status) {
+		case simplecontent.ObjectStatusUploaded, simplecontent.ObjectStatusProcessed:
+			if primary == nil || object.Version > primary.Version {
+				primary = object
+			}
+		}
+	}
+	if primary == nil {
+		return nil
+	}
+	result := &simplecontent.PrimaryObject{
+		ID:                 primary.ID,
+		ObjectKey:          primary.ObjectKey,
+		StorageBackendName: primary.StorageBackendName,
+		Version:            primary.Version,
+		Status:             primary.Status,
+	}
+	if metadata, ok := r.objectMetadata[primary.ID]; ok {
+		result.SizeBytes = metadata.SizeBytes
+		result.MimeType = metadata.MimeType
+		result.ETag = metadata.ETag
+	}
+	return result
+}
+
+// Access audit operations
+
+func (r *Repository) CreateAccessEvent(ctx context.Context, event *simplecontent.AccessEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.contents[event.ContentID]; !exists {
+		return simplecontent.ErrContentNotFound
+	}
+
+	eventCopy := *event
+	r.accessEvents[event.ContentID] = append(r.accessEvents[event.ContentID], &eventCopy)
+	return nil
+}
+
+func (r *Repository) ListAccessEvents(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.AccessEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*simplecontent.AccessEvent, 0, len(r.accessEvents[contentID]))
+	for _, event := range r.accessEvents[contentID] {
+		eventCopy := *event
+		result = append(result, &eventCopy)
+	}
+
+	// Stable sort keeps insertion order for events recorded at the same instant
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].OccurredAt.Before(result[j].OccurredAt)
+	})
+	return result, nil
+}
+
+// Upload intent operations
+
+func (r *Repository) CreateUploadIntent(ctx context.Context, intent *simplecontent.UploadIntent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	intentCopy := *intent
+	r.uploadIntents[intent.ObjectID] = &intentCopy
+	return nil
+}
+
+func (r *Repository) DeleteUploadIntent(ctx context.Context, objectID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.uploadIntents, objectID)
+	return nil
+}
+
+func (r *Repository) ListUploadIntents(ctx context.Context, createdBefore time.Time) ([]*simplecontent.UploadIntent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []*simplecontent.UploadIntent{}
+	for _, intent := range r.uploadIntents {
+		if !intent.CreatedAt.Before(createdBefore) {
+			continue
+		}
+		intentCopy := *intent
+		result = append(result, &intentCopy)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// Advisory locks
+
+// AcquireLock takes the in-process lock named key. Locks are only shared by callers of the
+// same repository instance.
+func (r *Repository) AcquireLock(ctx context.Context, key string) (func(), error) {
+	r.locksMu.Lock()
+	if r.locks == nil {
+		r.locks = make(map[string]chan struct{})
+	}
+	sem, ok := r.locks[key]
+	if !ok {
+		sem = make(chan struct{}, 1)
+		r.locks[key] = sem
+	}
+	r.locksMu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() { <-sem })
+	}
+	// The lock goes with its holder's context
+	stop := context.AfterFunc(ctx, release)
+	return func() {
+		stop()
+		release()
+	}, nil
+}
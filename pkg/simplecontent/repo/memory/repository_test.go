@@ -542,6 +542,129 @@ func TestMemoryRepository_DerivedContentOperations(t *testing.T) {
 	})
 }
 
+func TestMemoryRepository_ContentRelationshipOperations(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+
+	newContent := func(name string) *simplecontent.Content {
+		content := &simplecontent.Content{
+			ID:        uuid.New(),
+			TenantID:  uuid.New(),
+			OwnerID:   uuid.New(),
+			Name:      name,
+			Status:    string(simplecontent.ContentStatusCreated),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		require.NoError(t, repo.CreateContent(ctx, content))
+		return content
+	}
+
+	doc := newContent("Document")
+	attachment := newContent("Attachment")
+	reference := newContent("Reference")
+
+	link := func(to uuid.UUID, relType simplecontent.RelationType) {
+		require.NoError(t, repo.CreateContentRelationship(ctx, &simplecontent.ContentRelationship{
+			FromContentID: doc.ID,
+			ToContentID:   to,
+			RelationType:  relType,
+			CreatedAt:     time.Now(),
+		}))
+	}
+	link(attachment.ID, simplecontent.RelationTypeAttachment)
+	link(reference.ID, simplecontent.RelationTypeReference)
+	link(attachment.ID, simplecontent.RelationTypeAttachment) // idempotent
+
+	t.Run("ListByType", func(t *testing.T) {
+		rels, err := repo.ListContentRelationships(ctx, simplecontent.ListContentRelationshipsParams{
+			FromContentID: doc.ID,
+			RelationType:  simplecontent.RelationTypeAttachment,
+		})
+		require.NoError(t, err)
+		require.Len(t, rels, 1)
+		assert.Equal(t, attachment.ID, rels[0].ToContentID)
+	})
+
+	t.Run("ListAllTypes", func(t *testing.T) {
+		rels, err := repo.ListContentRelationships(ctx, simplecontent.ListContentRelationshipsParams{FromContentID: doc.ID})
+		require.NoError(t, err)
+		assert.Len(t, rels, 2)
+	})
+
+	t.Run("MissingContent", func(t *testing.T) {
+		err := repo.CreateContentRelationship(ctx, &simplecontent.ContentRelationship{
+			FromContentID: doc.ID,
+			ToContentID:   uuid.New(),
+			RelationType:  simplecontent.RelationTypeRelated,
+		})
+		assert.ErrorIs(t, err, simplecontent.ErrContentNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, repo.DeleteContentRelationship(ctx, doc.ID, reference.ID, simplecontent.RelationTypeReference))
+		err := repo.DeleteContentRelationship(ctx, doc.ID, reference.ID, simplecontent.RelationTypeReference)
+		assert.ErrorIs(t, err, simplecontent.ErrRelationshipNotFound)
+	})
+
+	t.Run("ExcludesDeletedTargets", func(t *testing.T) {
+		require.NoError(t, repo.DeleteContent(ctx, attachment.ID))
+		rels, err := repo.ListContentRelationships(ctx, simplecontent.ListContentRelationshipsParams{FromContentID: doc.ID})
+		require.NoError(t, err)
+		assert.Empty(t, rels)
+	})
+}
+
+func TestMemoryRepository_AccessEventOperations(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+
+	content := &simplecontent.Content{
+		ID:        uuid.New(),
+		TenantID:  uuid.New(),
+		OwnerID:   uuid.New(),
+		Name:      "Audited",
+		Status:    string(simplecontent.ContentStatusUploaded),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, repo.CreateContent(ctx, content))
+
+	now := time.Now().UTC()
+	record := func(action simplecontent.AccessAction, actor string, at time.Time) {
+		require.NoError(t, repo.CreateAccessEvent(ctx, &simplecontent.AccessEvent{
+			ID:         uuid.New(),
+			ContentID:  content.ID,
+			Action:     action,
+			Actor:      actor,
+			OccurredAt: at,
+		}))
+	}
+	record(simplecontent.AccessActionPreview, "bob", now)
+	record(simplecontent.AccessActionDownload, "alice", now.Add(-time.Minute))
+
+	t.Run("ListOldestFirst", func(t *testing.T) {
+		events, err := repo.ListAccessEvents(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "alice", events[0].Actor)
+		assert.Equal(t, simplecontent.AccessActionDownload, events[0].Action)
+		assert.Equal(t, "bob", events[1].Actor)
+	})
+
+	t.Run("MissingContent", func(t *testing.T) {
+		err := repo.CreateAccessEvent(ctx, &simplecontent.AccessEvent{ID: uuid.New(), ContentID: uuid.New()})
+		assert.ErrorIs(t, err, simplecontent.ErrContentNotFound)
+	})
+
+	t.Run("KeptAfterDelete", func(t *testing.T) {
+		require.NoError(t, repo.DeleteContent(ctx, content.ID))
+		events, err := repo.ListAccessEvents(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+}
+
 func TestMemoryRepository_BatchOperations(t *testing.T) {
 	repo := memory.New()
 	ctx := context.Background()
@@ -848,3 +971,526 @@ func TestMemoryRepositoryConcurrency(t *testing.T) {
 		<-done
 	}
 }
+
+func TestMemoryRepository_ContentMetadataHistory(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+
+	content := &simplecontent.Content{
+		ID:        uuid.New(),
+		TenantID:  uuid.New(),
+		OwnerID:   uuid.New(),
+		Name:      "Versioned",
+		Status:    string(simplecontent.ContentStatusCreated),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, repo.CreateContent(ctx, content))
+
+	for i, title := range []string{"first", "second", "third"} {
+		actorCtx := simplecontent.WithActor(ctx, fmt.Sprintf("user-%d", i+1))
+		require.NoError(t, repo.SetContentMetadata(actorCtx, &simplecontent.ContentMetadata{
+			ContentID: content.ID,
+			Metadata:  map[string]interface{}{"title": title},
+		}))
+	}
+
+	history, err := repo.ListContentMetadataHistory(ctx, content.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	for i, title := range []string{"first", "second", "third"} {
+		assert.Equal(t, i+1, history[i].Version)
+		assert.Equal(t, fmt.Sprintf("user-%d", i+1), history[i].Actor)
+		assert.Equal(t, title, history[i].Metadata.Metadata["title"])
+		assert.False(t, history[i].RecordedAt.IsZero())
+	}
+	assert.False(t, history[2].RecordedAt.Before(history[0].RecordedAt))
+
+	// Editing the returned versions doesn't change the recorded history
+	history[0].Metadata.Metadata["title"] = "changed"
+	history, err = repo.ListContentMetadataHistory(ctx, content.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "first", history[0].Metadata.Metadata["title"])
+
+	current, err := repo.GetContentMetadata(ctx, content.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "third", current.Metadata["title"])
+}
+
+func TestMemoryRepository_ListContentTimeRange(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+	ownerID, tenantID := uuid.New(), uuid.New()
+	base := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	// One content per day, each updated a day after it was created
+	ids := make([]uuid.UUID, 3)
+	for i := range ids {
+		ids[i] = uuid.New()
+		created := base.AddDate(0, 0, i)
+		require.NoError(t, repo.CreateContent(ctx, &simplecontent.Content{
+			ID:        ids[i],
+			TenantID:  tenantID,
+			OwnerID:   ownerID,
+			Name:      fmt.Sprintf("content %d", i),
+			Status:    string(simplecontent.ContentStatusCreated),
+			CreatedAt: created,
+			UpdatedAt: created.AddDate(0, 0, 1),
+		}))
+	}
+
+	idsOf := func(contents []*simplecontent.Content) []uuid.UUID {
+		out := make([]uuid.UUID, 0, len(contents))
+		for _, c := range contents {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	after, before := base.AddDate(0, 0, 1), base.AddDate(0, 0, 2)
+	contents, err := repo.ListContentWithFilters(ctx, simplecontent.ContentListFilters{
+		OwnerID:       &ownerID,
+		TenantID:      &tenantID,
+		CreatedAfter:  &after,
+		CreatedBefore: &before,
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{ids[1], ids[2]}, idsOf(contents))
+
+	updatedBefore := base.AddDate(0, 0, 1)
+	contents, err = repo.ListContentWithFilters(ctx, simplecontent.ContentListFilters{
+		OwnerID:       &ownerID,
+		TenantID:      &tenantID,
+		UpdatedBefore: &updatedBefore,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{ids[0]}, idsOf(contents))
+}
+
+func TestMemoryRepository_ListObjects(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+	contentID := uuid.New()
+	otherContentID := uuid.New()
+	for _, id := range []uuid.UUID{contentID, otherContentID} {
+		require.NoError(t, repo.CreateContent(ctx, &simplecontent.Content{
+			ID:       id,
+			TenantID: uuid.New(),
+			OwnerID:  uuid.New(),
+			Name:     "objects",
+			Status:   string(simplecontent.ContentStatusCreated),
+		}))
+	}
+
+	create := func(contentID uuid.UUID, version int, backend string, status simplecontent.ObjectStatus) uuid.UUID {
+		id := uuid.New()
+		require.NoError(t, repo.CreateObject(ctx, &simplecontent.Object{
+			ID:                 id,
+			ContentID:          contentID,
+			StorageBackendName: backend,
+			ObjectKey:          id.String(),
+			Version:            version,
+			Status:             string(status),
+			CreatedAt:          time.Now(),
+		}))
+		return id
+	}
+	s3Uploaded := create(contentID, 1, "s3", simplecontent.ObjectStatusUploaded)
+	s3Created := create(contentID, 2, "s3", simplecontent.ObjectStatusCreated)
+	fsUploaded := create(contentID, 3, "fs", simplecontent.ObjectStatusUploaded)
+	create(otherContentID, 1, "s3", simplecontent.ObjectStatusUploaded)
+
+	idsOf := func(objects []*simplecontent.Object) []uuid.UUID {
+		out := make([]uuid.UUID, 0, len(objects))
+		for _, o := range objects {
+			out = append(out, o.ID)
+		}
+		return out
+	}
+	uploaded := string(simplecontent.ObjectStatusUploaded)
+	s3 := "s3"
+
+	objects, err := repo.ListObjects(ctx, simplecontent.ObjectListFilters{ContentID: &contentID, Status: &uploaded})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{fsUploaded, s3Uploaded}, idsOf(objects))
+
+	objects, err = repo.ListObjects(ctx, simplecontent.ObjectListFilters{ContentID: &contentID, StorageBackendName: &s3})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{s3Created, s3Uploaded}, idsOf(objects))
+
+	objects, err = repo.ListObjects(ctx, simplecontent.ObjectListFilters{ContentID: &contentID, Status: &uploaded, StorageBackendName: &s3})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{s3Uploaded}, idsOf(objects))
+
+	// Without a content every object matching the filters is listed
+	objects, err = repo.ListObjects(ctx, simplecontent.ObjectListFilters{StorageBackendName: &s3, Status: &uploaded})
+	require.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	limit, offset := 1, 1
+	objects, err = repo.ListObjects(ctx, simplecontent.ObjectListFilters{ContentID: &contentID, Limit: &limit, Offset: &offset})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{s3Created}, idsOf(objects))
+}
+
+func TestMemoryRepository_UploadIntents(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+	base := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	older := &simplecontent.UploadIntent{ObjectID: uuid.New(), ContentID: uuid.New(), StorageBackendName: "fs", ObjectKey: "a", CreatedAt: base}
+	newer := &simplecontent.UploadIntent{ObjectID: uuid.New(), ContentID: uuid.New(), StorageBackendName: "fs", ObjectKey: "b", CreatedAt: base.Add(time.Minute)}
+	require.NoError(t, repo.CreateUploadIntent(ctx, newer))
+	require.NoError(t, repo.CreateUploadIntent(ctx, older))
+
+	intents, err := repo.ListUploadIntents(ctx, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, intents, 2)
+	assert.Equal(t, older.ObjectID, intents[0].ObjectID)
+	assert.Equal(t, newer.ObjectID, intents[1].ObjectID)
+
+	intents, err = repo.ListUploadIntents(ctx, base.Add(30*time.Second))
+	require.NoError(t, err)
+	require.Len(t, intents, 1)
+	assert.Equal(t, older.ObjectID, intents[0].ObjectID)
+
+	// Recording an intent again replaces it
+	replaced := *older
+	replaced.ObjectKey = "a2"
+	require.NoError(t, repo.CreateUploadIntent(ctx, &replaced))
+	require.NoError(t, repo.DeleteUploadIntent(ctx, newer.ObjectID))
+	require.NoError(t, repo.DeleteUploadIntent(ctx, uuid.New()))
+
+	intents, err = repo.ListUploadIntents(ctx, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, intents, 1)
+	assert.Equal(t, "a2", intents[0].ObjectKey)
+}
+
+func TestMemoryRepository_DedupStatistics(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	// Three copies of one 100-byte blob, two of a 40-byte blob, one unique and one without a checksum
+	blobs := []struct {
+		checksum string
+		size     int64
+	}{
+		{"sha256:aaa", 100}, {"sha256:aaa", 100}, {"sha256:aaa", 100},
+		{"sha256:bbb", 40}, {"sha256:bbb", 40},
+		{"sha256:ccc", 7},
+		{"", 500},
+	}
+	for i, blob := range blobs {
+		content := &simplecontent.Content{
+			ID:        uuid.New(),
+			TenantID:  tenantID,
+			OwnerID:   uuid.New(),
+			Name:      fmt.Sprintf("copy %d", i),
+			Status:    string(simplecontent.ContentStatusUploaded),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		require.NoError(t, repo.CreateContent(ctx, content))
+		require.NoError(t, repo.SetContentMetadata(ctx, &simplecontent.ContentMetadata{
+			ContentID: content.ID,
+			FileSize:  blob.size,
+			Checksum:  blob.checksum,
+		}))
+	}
+
+	stats, err := repo.GetContentStatistics(ctx, simplecontent.ContentCountFilters{TenantID: &tenantID},
+		simplecontent.ContentStatisticsOptions{IncludeDedupBreakdown: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), stats.TotalCount)
+	assert.Equal(t, int64(3), stats.UniqueBlobs)
+	assert.Equal(t, int64(6), stats.TotalReferences)
+	assert.Equal(t, int64(2*100+40), stats.BytesSaved)
+
+	// Not computed unless requested
+	stats, err = repo.GetContentStatistics(ctx, simplecontent.ContentCountFilters{TenantID: &tenantID},
+		simplecontent.ContentStatisticsOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, stats.UniqueBlobs)
+	assert.Zero(t, stats.BytesSaved)
+}
+
+func TestMemoryRepository_ContentWithoutUploadedObjects(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	newContent := func(name string, objectStatuses ...simplecontent.ObjectStatus) uuid.UUID {
+		content := &simplecontent.Content{
+			ID:        uuid.New(),
+			TenantID:  tenantID,
+			OwnerID:   uuid.New(),
+			Name:      name,
+			Status:    string(simplecontent.ContentStatusCreated),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		require.NoError(t, repo.CreateContent(ctx, content))
+		for i, status := range objectStatuses {
+			require.NoError(t, repo.CreateObject(ctx, &simplecontent.Object{
+				ID:                 uuid.New(),
+				ContentID:          content.ID,
+				StorageBackendName: "memory",
+				ObjectKey:          fmt.Sprintf("%s/%d", name, i),
+				Version:            i + 1,
+				Status:             string(status),
+				CreatedAt:          time.Now(),
+				UpdatedAt:          time.Now(),
+			}))
+		}
+		return content.ID
+	}
+
+	noObjects := newContent("no-objects")
+	pending := newContent("pending", simplecontent.ObjectStatusCreated)
+	failed := newContent("failed", simplecontent.ObjectStatusFailed)
+	newContent("uploaded", simplecontent.ObjectStatusUploaded)
+	newContent("retried", simplecontent.ObjectStatusFailed, simplecontent.ObjectStatusUploaded)
+	newContent("derived", simplecontent.ObjectStatusProcessed)
+
+	contents, err := repo.ListContentWithFilters(ctx, simplecontent.ContentListFilters{TenantID: &tenantID, WithoutUploadedObjects: true})
+	require.NoError(t, err)
+	var ids []uuid.UUID
+	for _, content := range contents {
+		ids = append(ids, content.ID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{noObjects, pending, failed}, ids)
+
+	count, err := repo.CountContentWithFilters(ctx, simplecontent.ContentCountFilters{TenantID: &tenantID, WithoutUploadedObjects: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	count, err = repo.CountContentWithFilters(ctx, simplecontent.ContentCountFilters{TenantID: &tenantID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), count)
+}
+
+func TestMemoryRepository_ContentFilter(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	newContent := func(documentType, status, ownerType string) uuid.UUID {
+		content := &simplecontent.Content{
+			ID:           uuid.New(),
+			TenantID:     tenantID,
+			OwnerID:      uuid.New(),
+			OwnerType:    ownerType,
+			DocumentType: documentType,
+			Status:       status,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+		require.NoError(t, repo.CreateContent(ctx, content))
+		return content.ID
+	}
+
+	uploadedPDF := newContent("application/pdf", "uploaded", "user")
+	uploadedPNG := newContent("image/png", "uploaded", "user")
+	newContent("image/png", "created", "user")
+	newContent("text/plain", "uploaded", "user")
+	groupText := newContent("text/plain", "created", "group")
+
+	// (document_type IN (pdf, png) AND status = uploaded) OR owner_type = group, for the tenant
+	filter := simplecontent.AllOf(
+		simplecontent.Where("tenant_id").Eq(tenantID.String()),
+		simplecontent.AnyOf(
+			simplecontent.Where("document_type").In("application/pdf", "image/png").And("status").Eq("uploaded"),
+			simplecontent.Where("owner_type").Eq("group"),
+		),
+	)
+	contents, err := repo.ListContentWithFilters(ctx, simplecontent.ContentListFilters{Where: filter})
+	require.NoError(t, err)
+	var ids []uuid.UUID
+	for _, content := range contents {
+		ids = append(ids, content.ID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{uploadedPDF, uploadedPNG, groupText}, ids)
+
+	count, err := repo.CountContentWithFilters(ctx, simplecontent.ContentCountFilters{Where: filter})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	stats, err := repo.GetContentStatistics(ctx, simplecontent.ContentCountFilters{Where: filter}, simplecontent.ContentStatisticsOptions{IncludeStatusBreakdown: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"uploaded": 2, "created": 1}, stats.ByStatus)
+
+	// The fields combine with the filter
+	status := "created"
+	count, err = repo.CountContentWithFilters(ctx, simplecontent.ContentCountFilters{Status: &status, Where: filter})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = repo.ListContentWithFilters(ctx, simplecontent.ContentListFilters{Where: simplecontent.Where("password").Eq("x")})
+	assert.ErrorIs(t, err, simplecontent.ErrInvalidField)
+}
+
+func TestMemoryRepository_Exists(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+
+	content := &simplecontent.Content{ID: uuid.New(), TenantID: uuid.New(), OwnerID: uuid.New(), Status: string(simplecontent.ContentStatusCreated)}
+	require.NoError(t, repo.CreateContent(ctx, content))
+	object := &simplecontent.Object{ID: uuid.New(), ContentID: content.ID, StorageBackendName: "memory", ObjectKey: "k", Status: string(simplecontent.ObjectStatusCreated)}
+	require.NoError(t, repo.CreateObject(ctx, object))
+
+	exists, err := repo.ContentExists(ctx, content.ID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = repo.ObjectExists(ctx, object.ID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.ContentExists(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = repo.ObjectExists(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Deleted rows no longer exist
+	require.NoError(t, repo.DeleteObject(ctx, object.ID))
+	require.NoError(t, repo.DeleteContent(ctx, content.ID))
+	exists, err = repo.ContentExists(ctx, content.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = repo.ObjectExists(ctx, object.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestMemoryRepository_UniqueDerivedVariant(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+
+	newContent := func() *simplecontent.Content {
+		content := &simplecontent.Content{ID: uuid.New(), TenantID: uuid.New(), OwnerID: uuid.New(), Status: string(simplecontent.ContentStatusCreated)}
+		require.NoError(t, repo.CreateContent(ctx, content))
+		return content
+	}
+	link := func(parent, derived *simplecontent.Content, variant string) error {
+		_, err := repo.CreateDerivedContentRelationship(ctx, simplecontent.CreateDerivedContentParams{
+			ParentID:         parent.ID,
+			DerivedContentID: derived.ID,
+			DerivationType:   "thumbnail",
+			Variant:          variant,
+		})
+		return err
+	}
+	parent, first := newContent(), newContent()
+	require.NoError(t, link(parent, first, "thumbnail_256"))
+
+	assert.ErrorIs(t, link(parent, newContent(), "thumbnail_256"), simplecontent.ErrVariantExists)
+	assert.NoError(t, link(parent, newContent(), "thumbnail_128"), "other variants are accepted")
+	assert.NoError(t, link(newContent(), newContent(), "thumbnail_256"), "other parents are accepted")
+	assert.NoError(t, link(parent, newContent(), ""))
+	assert.NoError(t, link(parent, newContent(), ""), "relationships without a variant are not unique")
+
+	// Deleting the derived content frees its variant
+	require.NoError(t, repo.DeleteContent(ctx, first.ID))
+	assert.NoError(t, link(parent, newContent(), "thumbnail_256"))
+}
+
+func TestMemoryRepository_AcquireLock(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+
+	unlock, err := repo.AcquireLock(ctx, "derive:a")
+	require.NoError(t, err)
+
+	// Other keys are independent
+	unlockOther, err := repo.AcquireLock(ctx, "derive:b")
+	require.NoError(t, err)
+	unlockOther()
+
+	// A held lock blocks until ctx is done
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = repo.AcquireLock(waitCtx, "derive:a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+	unlock() // Releasing twice is harmless
+
+	// The lock is held for as long as its holder's context, however long that is
+	holderCtx, cancelHolder := context.WithCancel(ctx)
+	_, err = repo.AcquireLock(holderCtx, "derive:a")
+	require.NoError(t, err)
+	waitCtx, cancel = context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = repo.AcquireLock(waitCtx, "derive:a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// and released once the holder's context ends
+	cancelHolder()
+	waitCtx, cancel = context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	unlockNext, err := repo.AcquireLock(waitCtx, "derive:a")
+	require.NoError(t, err)
+	unlockNext()
+}
+
+func TestMemoryRepository_ContentStorageStats(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+
+	newContent := func() *simplecontent.Content {
+		content := &simplecontent.Content{ID: uuid.New(), TenantID: uuid.New(), OwnerID: uuid.New(), Status: string(simplecontent.ContentStatusUploaded)}
+		require.NoError(t, repo.CreateContent(ctx, content))
+		return content
+	}
+	newObject := func(content *simplecontent.Content, size int64) *simplecontent.Object {
+		object := &simplecontent.Object{ID: uuid.New(), ContentID: content.ID, StorageBackendName: "memory", ObjectKey: uuid.NewString(), Version: 1, Status: string(simplecontent.ObjectStatusUploaded)}
+		require.NoError(t, repo.CreateObject(ctx, object))
+		require.NoError(t, repo.SetObjectMetadata(ctx, &simplecontent.ObjectMetadata{ObjectID: object.ID, SizeBytes: size}))
+		return object
+	}
+	derive := func(parent *simplecontent.Content, variant string) *simplecontent.Content {
+		derived := newContent()
+		_, err := repo.CreateDerivedContentRelationship(ctx, simplecontent.CreateDerivedContentParams{
+			ParentID:         parent.ID,
+			DerivedContentID: derived.ID,
+			DerivationType:   "thumbnail",
+			Variant:          variant,
+		})
+		require.NoError(t, err)
+		return derived
+	}
+
+	original := newContent()
+	newObject(original, 1000)
+	newObject(original, 500)
+	thumbnail := derive(original, "thumbnail_256")
+	newObject(thumbnail, 200)
+	preview := derive(thumbnail, "preview_64")
+	newObject(preview, 40)
+	removed := newObject(original, 9999)
+	require.NoError(t, repo.DeleteObject(ctx, removed.ID))
+	newObject(newContent(), 7777) // unrelated content
+
+	objectCount, totalBytes, err := repo.GetContentStorageStats(ctx, original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, objectCount, "objects of the content and its derived contents, without deleted ones")
+	assert.Equal(t, int64(1000+500+200+40), totalBytes)
+
+	objectCount, totalBytes, err = repo.GetContentStorageStats(ctx, thumbnail.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, objectCount)
+	assert.Equal(t, int64(240), totalBytes)
+
+	// Deleted derived content no longer counts
+	require.NoError(t, repo.DeleteContent(ctx, preview.ID))
+	objectCount, totalBytes, err = repo.GetContentStorageStats(ctx, original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, objectCount)
+	assert.Equal(t, int64(1700), totalBytes)
+
+	_, _, err = repo.GetContentStorageStats(ctx, uuid.New())
+	assert.ErrorIs(t, err, simplecontent.ErrContentNotFound)
+}
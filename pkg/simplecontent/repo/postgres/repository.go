@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -69,6 +70,30 @@ func (r *Repository) handlePostgresError(operation string, err error) error {
 	return fmt.Errorf("database error in %s: %w", operation, err)
 }
 
+// IsTransientError reports whether err is a transient PostgreSQL failure that is safe to retry:
+// serialization failures, deadlocks, connection exceptions, and errors pgconn marks safe to retry.
+// It is intended as the classifier for repo.WithRetry.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "40001": // serialization_failure
+			return true
+		case pgErr.Code == "40P01": // deadlock_detected
+			return true
+		case strings.HasPrefix(pgErr.Code, "08"): // connection_exception class
+			return true
+		case pgErr.Code == "57P01" || pgErr.Code == "57P03": // admin_shutdown, cannot_connect_now
+			return true
+		}
+		return false
+	}
+	return pgconn.SafeToRetry(err)
+}
+
 // Content operations
 
 func (r *Repository) CreateContent(ctx context.Context, content *simplecontent.Content) error {
@@ -112,6 +137,20 @@ func (r *Repository) GetContent(ctx context.Context, id uuid.UUID) (*simpleconte
 	return &content, nil
 }
 
+func (r *Repository) ContentExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `SELECT 1 FROM content WHERE id = $1 AND deleted_at IS NULL LIMIT 1`
+
+	var one int
+	err := r.db.QueryRow(ctx, query, id).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (r *Repository) GetContentsByIDs(ctx context.Context, ids []uuid.UUID) ([]*simplecontent.Content, error) {
 	if len(ids) == 0 {
 		return []*simplecontent.Content{}, nil
@@ -165,12 +204,41 @@ func (r *Repository) UpdateContent(ctx context.Context, content *simplecontent.C
 }
 
 func (r *Repository) DeleteContent(ctx context.Context, id uuid.UUID) error {
-	// Soft delete: set deleted_at timestamp, keep status at last operational state
-	query := `UPDATE content SET deleted_at = NOW() WHERE id = $1`
+	// Soft delete: set deleted_at timestamp, keep status at last operational state. A derived
+	// content's relationship is deleted with it, which frees its variant for a new derivation.
+	query := `
+		WITH deleted_content AS (
+			UPDATE content SET deleted_at = NOW() WHERE id = $1
+		)
+		UPDATE content_derived SET deleted_at = NOW()
+		WHERE content_id = $1 AND deleted_at IS NULL`
 	_, err := r.db.Exec(ctx, query, id)
 	return err
 }
 
+func (r *Repository) DeleteContentTree(ctx context.Context, contentIDs []uuid.UUID) error {
+	if len(contentIDs) == 0 {
+		return nil
+	}
+
+	// A single statement so the contents, objects and relationships are deleted atomically
+	query := `
+		WITH deleted_content AS (
+			UPDATE content SET deleted_at = NOW()
+			WHERE id = ANY($1) AND deleted_at IS NULL
+		), deleted_objects AS (
+			UPDATE object SET deleted_at = NOW()
+			WHERE content_id = ANY($1) AND deleted_at IS NULL
+		)
+		UPDATE content_derived SET deleted_at = NOW()
+		WHERE content_id = ANY($1) AND deleted_at IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, contentIDs); err != nil {
+		return r.handlePostgresError("delete content tree", err)
+	}
+	return nil
+}
+
 func (r *Repository) ListContent(ctx context.Context, ownerID, tenantID uuid.UUID) ([]*simplecontent.Content, error) {
 	query := `
         SELECT id, tenant_id, owner_id, owner_type, name, description,
@@ -209,29 +277,132 @@ func (r *Repository) SetContentMetadata(ctx context.Context, metadata *simplecon
 		metadata.CreatedAt = now
 	}
 
+	// The upsert and the history row are written by one statement so they can't diverge
 	query := `
-		INSERT INTO content_metadata (
+		WITH upserted AS (
+			INSERT INTO content_metadata (
+				content_id, tags, file_size, file_name, mime_type,
+				checksum, checksum_algorithm, metadata, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (content_id) DO UPDATE SET
+				tags = EXCLUDED.tags,
+				file_size = EXCLUDED.file_size,
+				file_name = EXCLUDED.file_name,
+				mime_type = EXCLUDED.mime_type,
+				checksum = EXCLUDED.checksum,
+				checksum_algorithm = EXCLUDED.checksum_algorithm,
+				metadata = EXCLUDED.metadata,
+				updated_at = EXCLUDED.updated_at
+			RETURNING content_id, tags, file_size, file_name, mime_type,
+				checksum, checksum_algorithm, metadata, created_at, updated_at
+		)
+		INSERT INTO content_metadata_history (
 			content_id, tags, file_size, file_name, mime_type,
-			checksum, checksum_algorithm, metadata, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (content_id) DO UPDATE SET
-			tags = EXCLUDED.tags,
-			file_size = EXCLUDED.file_size,
-			file_name = EXCLUDED.file_name,
-			mime_type = EXCLUDED.mime_type,
-			checksum = EXCLUDED.checksum,
-			checksum_algorithm = EXCLUDED.checksum_algorithm,
-			metadata = EXCLUDED.metadata,
-			updated_at = EXCLUDED.updated_at`
+			checksum, checksum_algorithm, metadata, created_at, recorded_at, actor
+		)
+		SELECT content_id, tags, file_size, file_name, mime_type,
+			checksum, checksum_algorithm, metadata, created_at, updated_at, $11
+		FROM upserted`
 
 	_, err := r.db.Exec(ctx, query,
 		metadata.ContentID, metadata.Tags, metadata.FileSize, metadata.FileName,
 		metadata.MimeType, metadata.Checksum, metadata.ChecksumAlgorithm,
-		metadata.Metadata, metadata.CreatedAt, now)
+		metadata.Metadata, metadata.CreatedAt, now, simplecontent.ActorFromContext(ctx))
 
 	return err
 }
 
+// UpdateContentTags computes the new tags, upserts them and records history in one
+// statement, so the whole batch is applied or none of it is
+func (r *Repository) UpdateContentTags(ctx context.Context, contentIDs []uuid.UUID, add, remove []string) (int64, error) {
+	if len(contentIDs) == 0 || (len(add) == 0 && len(remove) == 0) {
+		return 0, nil
+	}
+	if add == nil {
+		add = []string{}
+	}
+	if remove == nil {
+		remove = []string{}
+	}
+
+	query := `
+		WITH target AS (
+			SELECT c.id, COALESCE(m.tags, '{}') AS old_tags
+			FROM content c LEFT JOIN content_metadata m ON m.content_id = c.id
+			WHERE c.id = ANY($1)
+		), computed AS (
+			SELECT id, old_tags,
+				ARRAY(SELECT t FROM unnest(old_tags) WITH ORDINALITY AS kept(t, n)
+					WHERE t <> ALL($3::text[]) ORDER BY n) ||
+				ARRAY(SELECT t FROM unnest($2::text[]) WITH ORDINALITY AS added(t, n)
+					WHERE t <> ALL(old_tags) AND t <> ALL($3::text[]) ORDER BY n) AS new_tags
+			FROM target
+		), upserted AS (
+			INSERT INTO content_metadata (
+				content_id, tags, file_size, file_name, mime_type,
+				checksum, checksum_algorithm, metadata, created_at, updated_at
+			)
+			SELECT id, new_tags, 0, '', '', '', '', '{}', NOW(), NOW()
+			FROM computed WHERE new_tags IS DISTINCT FROM old_tags
+			ON CONFLICT (content_id) DO UPDATE SET
+				tags = EXCLUDED.tags,
+				updated_at = EXCLUDED.updated_at
+			RETURNING content_id, tags, file_size, file_name, mime_type,
+				checksum, checksum_algorithm, metadata, created_at, updated_at
+		)
+		INSERT INTO content_metadata_history (
+			content_id, tags, file_size, file_name, mime_type,
+			checksum, checksum_algorithm, metadata, created_at, recorded_at, actor
+		)
+		SELECT content_id, tags, file_size, file_name, mime_type,
+			checksum, checksum_algorithm, metadata, created_at, updated_at, $4
+		FROM upserted`
+
+	tag, err := r.db.Exec(ctx, query, contentIDs, add, remove, simplecontent.ActorFromContext(ctx))
+	if err != nil {
+		return 0, r.handlePostgresError("update content tags", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListContentMetadataHistory numbers versions by insertion order, which keeps concurrent
+// writers from needing to agree on the next version number
+func (r *Repository) ListContentMetadataHistory(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.ContentMetadataVersion, error) {
+	query := `
+		SELECT ROW_NUMBER() OVER (ORDER BY id) AS version, actor, recorded_at,
+			   content_id, tags, file_size, file_name, mime_type,
+			   checksum, checksum_algorithm, metadata, created_at
+		FROM content_metadata_history
+		WHERE content_id = $1
+		ORDER BY id ASC`
+
+	rows, err := r.db.Query(ctx, query, contentID)
+	if err != nil {
+		return nil, r.handlePostgresError("list content metadata history", err)
+	}
+	defer rows.Close()
+
+	var results []*simplecontent.ContentMetadataVersion
+	for rows.Next() {
+		var version simplecontent.ContentMetadataVersion
+		m := &version.Metadata
+		if err := rows.Scan(&version.Version, &version.Actor, &version.RecordedAt,
+			&m.ContentID, &m.Tags, &m.FileSize, &m.FileName, &m.MimeType,
+			&m.Checksum, &m.ChecksumAlgorithm, &m.Metadata, &m.CreatedAt); err != nil {
+			return nil, r.handlePostgresError("scan content metadata version", err)
+		}
+		version.ContentID = m.ContentID
+		m.UpdatedAt = version.RecordedAt
+		results = append(results, &version)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, r.handlePostgresError("iterate content metadata history rows", err)
+	}
+
+	return results, nil
+}
+
 func (r *Repository) GetContentMetadata(ctx context.Context, contentID uuid.UUID) (*simplecontent.ContentMetadata, error) {
 	query := `
 		SELECT content_id, tags, file_size, file_name, mime_type,
@@ -400,6 +571,20 @@ func (r *Repository) GetObject(ctx context.Context, id uuid.UUID) (*simpleconten
 	return &object, nil
 }
 
+func (r *Repository) ObjectExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `SELECT 1 FROM object WHERE id = $1 AND deleted_at IS NULL LIMIT 1`
+
+	var one int
+	err := r.db.QueryRow(ctx, query, id).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (r *Repository) GetObjectsByContentID(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.Object, error) {
 	query := `
         SELECT id, content_id, storage_backend_name, storage_class, object_key,
@@ -465,6 +650,75 @@ func (r *Repository) GetObjectsByContentIDs(ctx context.Context, contentIDs []uu
 	return result, nil
 }
 
+func (r *Repository) ListObjects(ctx context.Context, filters simplecontent.ObjectListFilters) ([]*simplecontent.Object, error) {
+	query := `
+        SELECT id, content_id, storage_backend_name, storage_class, object_key,
+               file_name, version, object_type, status, created_at, updated_at
+        FROM object WHERE deleted_at IS NULL`
+
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters.ContentID != nil {
+		query += fmt.Sprintf(" AND content_id = $%d", argIndex)
+		args = append(args, *filters.ContentID)
+		argIndex++
+	}
+	if filters.Status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, *filters.Status)
+		argIndex++
+	}
+	if filters.StorageBackendName != nil {
+		query += fmt.Sprintf(" AND storage_backend_name = $%d", argIndex)
+		args = append(args, *filters.StorageBackendName)
+		argIndex++
+	}
+	if filters.ObjectKey != nil {
+		query += fmt.Sprintf(" AND object_key = $%d", argIndex)
+		args = append(args, *filters.ObjectKey)
+		argIndex++
+	}
+
+	query += " ORDER BY version DESC, created_at DESC"
+
+	// Pagination
+	if filters.Limit != nil {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, *filters.Limit)
+		argIndex++
+	}
+	if filters.Offset != nil {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, *filters.Offset)
+		argIndex++
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, r.handlePostgresError("list objects", err)
+	}
+	defer rows.Close()
+
+	objects := []*simplecontent.Object{}
+	for rows.Next() {
+		var object simplecontent.Object
+		if err := rows.Scan(
+			&object.ID, &object.ContentID, &object.StorageBackendName, &object.StorageClass,
+			&object.ObjectKey, &object.FileName, &object.Version, &object.ObjectType,
+			&object.Status, &object.CreatedAt, &object.UpdatedAt); err != nil {
+			return nil, r.handlePostgresError("scan object", err)
+		}
+		objects = append(objects, &object)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.handlePostgresError("iterate object rows", err)
+	}
+
+	return objects, nil
+}
+
 func (r *Repository) GetObjectByObjectKeyAndStorageBackendName(ctx context.Context, objectKey, storageBackendName string) (*simplecontent.Object, error) {
 	query := `
 		SELECT id, content_id, storage_backend_name, storage_class, object_key,
@@ -585,6 +839,71 @@ func (r *Repository) GetObjectMetadataByObjectIDs(ctx context.Context, objectIDs
 	return result, nil
 }
 
+func (r *Repository) GetUploadStatusesByObjectIDs(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]*simplecontent.UploadStatus, error) {
+	if len(objectIDs) == 0 {
+		return make(map[uuid.UUID]*simplecontent.UploadStatus), nil
+	}
+
+	query := `
+		SELECT o.id, o.status, COALESCE(m.size_bytes, 0),
+		       GREATEST(o.updated_at, COALESCE(m.updated_at, o.updated_at))
+		FROM object o
+		LEFT JOIN object_metadata m ON m.object_id = o.id
+		WHERE o.id = ANY($1) AND o.deleted_at IS NULL`
+
+	rows, err := r.db.Query(ctx, query, objectIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID]*simplecontent.UploadStatus)
+	for rows.Next() {
+		var status simplecontent.UploadStatus
+		var objectStatus string
+		if err := rows.Scan(&status.ObjectID, &objectStatus, &status.SizeBytes, &status.UpdatedAt); err != nil {
+			return nil, err
+		}
+		status.Status = simplecontent.ObjectStatus(objectStatus)
+		result[status.ObjectID] = &status
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *Repository) GetContentStorageStats(ctx context.Context, contentID uuid.UUID) (int, int64, error) {
+	// UNION rather than UNION ALL stops the walk on a cyclic derivation
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id FROM content WHERE id = $1 AND deleted_at IS NULL
+			UNION
+			SELECT cd.content_id
+			FROM content_derived cd
+			JOIN tree t ON cd.parent_id = t.id
+			JOIN content c ON c.id = cd.content_id AND c.deleted_at IS NULL
+			WHERE cd.deleted_at IS NULL
+		)
+		SELECT COUNT(DISTINCT t.id), COUNT(o.id), COALESCE(SUM(m.size_bytes), 0)
+		FROM tree t
+		LEFT JOIN object o ON o.content_id = t.id AND o.deleted_at IS NULL
+		LEFT JOIN object_metadata m ON m.object_id = o.id`
+
+	var contentCount, objectCount int
+	var totalBytes int64
+	if err := r.db.QueryRow(ctx, query, contentID).Scan(&contentCount, &objectCount, &totalBytes); err != nil {
+		return 0, 0, err
+	}
+	if contentCount == 0 {
+		return 0, 0, simplecontent.ErrContentNotFound
+	}
+
+	return objectCount, totalBytes, nil
+}
+
 // Derived content operations (simplified implementations)
 
 func (r *Repository) CreateDerivedContentRelationship(ctx context.Context, params simplecontent.CreateDerivedContentParams) (*simplecontent.DerivedContent, error) {
@@ -606,6 +925,10 @@ func (r *Repository) CreateDerivedContentRelationship(ctx context.Context, param
 		&derived.DerivationParams, &derived.ProcessingMetadata,
 		&derived.CreatedAt, &derived.UpdatedAt)
 
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_content_derived_parent_variant_unique" {
+		return nil, fmt.Errorf("%w: %s of content %s", simplecontent.ErrVariantExists, params.Variant, params.ParentID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -649,6 +972,52 @@ func (r *Repository) ListDerivedContent(ctx context.Context, params simpleconten
 	return result, nil
 }
 
+// ListDerivedContentWithDetails joins each derived relationship with its child content in a
+// single query. Children whose content is deleted are excluded by the inner join.
+func (r *Repository) ListDerivedContentWithDetails(ctx context.Context, params simplecontent.ListDerivedContentParams) ([]*simplecontent.DerivedContentWithDetails, error) {
+	query, args := r.buildDerivedContentQuery(`
+		SELECT cd.parent_id, cd.content_id, cd.derivation_type, cd.variant, cd.derivation_params,
+			   cd.processing_metadata, cd.created_at, cd.updated_at,
+			   c.id, c.tenant_id, c.owner_id, c.owner_type, c.name, c.description,
+			   c.document_type, c.status, c.derivation_type, c.created_at, c.updated_at
+		FROM content_derived cd
+		JOIN content c ON cd.content_id = c.id AND c.deleted_at IS NULL
+		WHERE cd.deleted_at IS NULL
+	`, params)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query derived content with details: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*simplecontent.DerivedContentWithDetails
+	for rows.Next() {
+		derived := &simplecontent.DerivedContent{}
+		content := &simplecontent.Content{}
+		err := rows.Scan(
+			&derived.ParentID, &derived.ContentID,
+			&derived.DerivationType, &derived.Variant,
+			&derived.DerivationParams, &derived.ProcessingMetadata,
+			&derived.CreatedAt, &derived.UpdatedAt,
+			&content.ID, &content.TenantID, &content.OwnerID, &content.OwnerType,
+			&content.Name, &content.Description, &content.DocumentType,
+			&content.Status, &content.DerivationType, &content.CreatedAt, &content.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan derived content with details: %w", err)
+		}
+		derived.DocumentType = content.DocumentType
+		derived.Status = content.Status
+		result = append(result, &simplecontent.DerivedContentWithDetails{
+			DerivedContent: derived,
+			Content:        content,
+		})
+	}
+
+	return result, rows.Err()
+}
+
 func (r *Repository) GetDerivedRelationshipByContentID(ctx context.Context, contentID uuid.UUID) (*simplecontent.DerivedContent, error) {
 	query := `
         SELECT parent_id, content_id, variant as derivation_type, derivation_params,
@@ -661,15 +1030,217 @@ func (r *Repository) GetDerivedRelationshipByContentID(ctx context.Context, cont
 		&derived.DerivationParams, &derived.ProcessingMetadata,
 		&derived.CreatedAt, &derived.UpdatedAt,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no derived relationship for content %s", simplecontent.ErrRelationshipNotFound, contentID)
+	}
 	if err != nil {
 		return nil, r.handlePostgresError("get derived relationship by content id", err)
 	}
 	return &derived, nil
 }
 
+func (r *Repository) UpdateDerivedContentRelationship(ctx context.Context, params simplecontent.UpdateDerivedContentParams) error {
+	query := `
+        UPDATE content_derived SET processing_metadata = $2, updated_at = NOW()
+        WHERE content_id = $1 AND deleted_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, params.DerivedContentID, params.ProcessingMetadata)
+	if err != nil {
+		return r.handlePostgresError("update derived relationship", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("derived relationship not found for content %s", params.DerivedContentID)
+	}
+	return nil
+}
+
+// Typed content relationship operations
+
+func (r *Repository) CreateContentRelationship(ctx context.Context, relationship *simplecontent.ContentRelationship) error {
+	// Linking is idempotent: an existing link is kept along with its original created_at
+	query := `
+		INSERT INTO content_relationship (from_content_id, to_content_id, relation_type, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (from_content_id, to_content_id, relation_type)
+		DO UPDATE SET from_content_id = EXCLUDED.from_content_id
+		RETURNING created_at`
+
+	err := r.db.QueryRow(ctx, query,
+		relationship.FromContentID, relationship.ToContentID,
+		string(relationship.RelationType), relationship.CreatedAt,
+	).Scan(&relationship.CreatedAt)
+	if err != nil {
+		return r.handlePostgresError("create content relationship", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteContentRelationship(ctx context.Context, fromContentID, toContentID uuid.UUID, relType simplecontent.RelationType) error {
+	query := `
+		DELETE FROM content_relationship
+		WHERE from_content_id = $1 AND to_content_id = $2 AND relation_type = $3`
+
+	tag, err := r.db.Exec(ctx, query, fromContentID, toContentID, string(relType))
+	if err != nil {
+		return r.handlePostgresError("delete content relationship", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return simplecontent.ErrRelationshipNotFound
+	}
+	return nil
+}
+
+func (r *Repository) ListContentRelationships(ctx context.Context, params simplecontent.ListContentRelationshipsParams) ([]*simplecontent.ContentRelationship, error) {
+	query := `
+		SELECT cr.from_content_id, cr.to_content_id, cr.relation_type, cr.created_at
+		FROM content_relationship cr
+		JOIN content c ON c.id = cr.to_content_id AND c.deleted_at IS NULL
+		WHERE cr.from_content_id = $1`
+	args := []interface{}{params.FromContentID}
+
+	if params.RelationType != "" {
+		query += " AND cr.relation_type = $2"
+		args = append(args, string(params.RelationType))
+	}
+	query += " ORDER BY cr.created_at ASC, cr.to_content_id ASC"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, r.handlePostgresError("list content relationships", err)
+	}
+	defer rows.Close()
+
+	var results []*simplecontent.ContentRelationship
+	for rows.Next() {
+		var rel simplecontent.ContentRelationship
+		var relType string
+		if err := rows.Scan(&rel.FromContentID, &rel.ToContentID, &relType, &rel.CreatedAt); err != nil {
+			return nil, r.handlePostgresError("scan content relationship", err)
+		}
+		rel.RelationType = simplecontent.RelationType(relType)
+		results = append(results, &rel)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, r.handlePostgresError("iterate content relationship rows", err)
+	}
+
+	return results, nil
+}
+
+func (r *Repository) CreateAccessEvent(ctx context.Context, event *simplecontent.AccessEvent) error {
+	query := `
+		INSERT INTO content_access_event (id, content_id, object_id, action, actor, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	var objectID *uuid.UUID
+	if event.ObjectID != uuid.Nil {
+		objectID = &event.ObjectID
+	}
+	_, err := r.db.Exec(ctx, query,
+		event.ID, event.ContentID, objectID, string(event.Action), event.Actor, event.OccurredAt,
+	)
+	if err != nil {
+		return r.handlePostgresError("create access event", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListAccessEvents(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.AccessEvent, error) {
+	query := `
+		SELECT id, content_id, object_id, action, actor, occurred_at
+		FROM content_access_event
+		WHERE content_id = $1
+		ORDER BY occurred_at ASC, id ASC`
+
+	rows, err := r.db.Query(ctx, query, contentID)
+	if err != nil {
+		return nil, r.handlePostgresError("list access events", err)
+	}
+	defer rows.Close()
+
+	var results []*simplecontent.AccessEvent
+	for rows.Next() {
+		var event simplecontent.AccessEvent
+		var objectID *uuid.UUID
+		var action string
+		if err := rows.Scan(&event.ID, &event.ContentID, &objectID, &action, &event.Actor, &event.OccurredAt); err != nil {
+			return nil, r.handlePostgresError("scan access event", err)
+		}
+		if objectID != nil {
+			event.ObjectID = *objectID
+		}
+		event.Action = simplecontent.AccessAction(action)
+		results = append(results, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, r.handlePostgresError("iterate access event rows", err)
+	}
+
+	return results, nil
+}
+
+func (r *Repository) CreateUploadIntent(ctx context.Context, intent *simplecontent.UploadIntent) error {
+	query := `
+		INSERT INTO object_upload_intent (object_id, content_id, storage_backend_name, object_key, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (object_id) DO UPDATE SET
+			content_id = EXCLUDED.content_id,
+			storage_backend_name = EXCLUDED.storage_backend_name,
+			object_key = EXCLUDED.object_key,
+			created_at = EXCLUDED.created_at`
+
+	_, err := r.db.Exec(ctx, query,
+		intent.ObjectID, intent.ContentID, intent.StorageBackendName, intent.ObjectKey, intent.CreatedAt,
+	)
+	if err != nil {
+		return r.handlePostgresError("create upload intent", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteUploadIntent(ctx context.Context, objectID uuid.UUID) error {
+	query := `DELETE FROM object_upload_intent WHERE object_id = $1`
+
+	if _, err := r.db.Exec(ctx, query, objectID); err != nil {
+		return r.handlePostgresError("delete upload intent", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListUploadIntents(ctx context.Context, createdBefore time.Time) ([]*simplecontent.UploadIntent, error) {
+	query := `
+		SELECT object_id, content_id, storage_backend_name, object_key, created_at
+		FROM object_upload_intent
+		WHERE created_at < $1
+		ORDER BY created_at ASC, object_id ASC`
+
+	rows, err := r.db.Query(ctx, query, createdBefore)
+	if err != nil {
+		return nil, r.handlePostgresError("list upload intents", err)
+	}
+	defer rows.Close()
+
+	results := []*simplecontent.UploadIntent{}
+	for rows.Next() {
+		var intent simplecontent.UploadIntent
+		if err := rows.Scan(&intent.ObjectID, &intent.ContentID, &intent.StorageBackendName, &intent.ObjectKey, &intent.CreatedAt); err != nil {
+			return nil, r.handlePostgresError("scan upload intent", err)
+		}
+		results = append(results, &intent)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, r.handlePostgresError("iterate upload intent rows", err)
+	}
+
+	return results, nil
+}
+
 // buildEnhancedQuery builds a PostgreSQL query with enhanced filtering capabilities
 func (r *Repository) buildEnhancedQuery(params simplecontent.ListDerivedContentParams) (string, []interface{}) {
-	query := `
+	return r.buildDerivedContentQuery(`
 		SELECT cd.parent_id, cd.content_id, cd.derivation_type, cd.variant, cd.derivation_params,
 			   cd.processing_metadata, cd.created_at, cd.updated_at,
 			   COALESCE(c.document_type, '') as document_type,
@@ -677,7 +1248,13 @@ func (r *Repository) buildEnhancedQuery(params simplecontent.ListDerivedContentP
 		FROM content_derived cd
 		LEFT JOIN content c ON cd.content_id = c.id
 		WHERE cd.deleted_at IS NULL
-	`
+	`, params)
+}
+
+// buildDerivedContentQuery appends the derived content filters, sorting and pagination to
+// baseQuery, which must select FROM content_derived cd joined with content c.
+func (r *Repository) buildDerivedContentQuery(baseQuery string, params simplecontent.ListDerivedContentParams) (string, []interface{}) {
+	query := baseQuery
 
 	var args []interface{}
 	argIndex := 1
@@ -749,6 +1326,17 @@ func (r *Repository) buildEnhancedQuery(params simplecontent.ListDerivedContentP
 		argIndex++
 	}
 
+	// Derivation processing status filtering (mapped onto content.status)
+	if len(params.DerivedStatuses) > 0 {
+		var statuses []string
+		for _, status := range params.DerivedStatuses {
+			statuses = append(statuses, status.ContentStatuses()...)
+		}
+		query += fmt.Sprintf(" AND c.status = ANY($%d)", argIndex)
+		args = append(args, statuses)
+		argIndex++
+	}
+
 	// Temporal filtering
 	if params.CreatedAfter != nil {
 		query += fmt.Sprintf(" AND cd.created_at > $%d", argIndex)
@@ -796,6 +1384,13 @@ func (r *Repository) ListContentWithFilters(ctx context.Context, filters simplec
         SELECT id, tenant_id, owner_id, owner_type, name, description,
                document_type, status, derivation_type, created_at, updated_at
         FROM content WHERE 1=1`
+	if filters.IncludePrimaryObject {
+		query = `
+        SELECT id, tenant_id, owner_id, owner_type, name, description,
+               document_type, status, derivation_type, created_at, updated_at,` +
+			primaryObjectColumns + `
+        FROM content` + primaryObjectJoin + ` WHERE 1=1`
+	}
 
 	args := []interface{}{}
 	argIndex := 1
@@ -881,6 +1476,21 @@ func (r *Repository) ListContentWithFilters(ctx context.Context, filters simplec
 		args = append(args, *filters.UpdatedBefore)
 		argIndex++
 	}
+	if filters.WithoutUploadedObjects {
+		query += withoutUploadedObjectsClause
+	}
+	if filters.OriginalsOnly {
+		query += originalsOnlyClause
+	}
+	if filters.Where != nil {
+		clause, whereArgs, err := contentFilterClause(filters.Where, argIndex)
+		if err != nil {
+			return nil, err
+		}
+		query += clause
+		args = append(args, whereArgs...)
+		argIndex += len(whereArgs)
+	}
 
 	// Sorting
 	sortBy := "created_at"
@@ -919,12 +1529,20 @@ func (r *Repository) ListContentWithFilters(ctx context.Context, filters simplec
 	var contents []*simplecontent.Content
 	for rows.Next() {
 		var content simplecontent.Content
-		if err := rows.Scan(
+		dest := []interface{}{
 			&content.ID, &content.TenantID, &content.OwnerID, &content.OwnerType,
 			&content.Name, &content.Description, &content.DocumentType,
-			&content.Status, &content.DerivationType, &content.CreatedAt, &content.UpdatedAt); err != nil {
+			&content.Status, &content.DerivationType, &content.CreatedAt, &content.UpdatedAt}
+		var primary primaryObjectRow
+		if filters.IncludePrimaryObject {
+			dest = append(dest, primary.dest()...)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, r.handlePostgresError("scan content", err)
 		}
+		if filters.IncludePrimaryObject {
+			content.PrimaryObject = primary.object()
+		}
 		contents = append(contents, &content)
 	}
 
@@ -935,6 +1553,71 @@ func (r *Repository) ListContentWithFilters(ctx context.Context, filters simplec
 	return contents, nil
 }
 
+// primaryObjectJoin joins each content's newest live uploaded or processed object and its
+// metadata. Its columns are all prefixed primary_ so the content columns stay unambiguous.
+const primaryObjectJoin = `
+        LEFT JOIN LATERAL (
+            SELECT o.id AS primary_id, o.object_key AS primary_object_key,
+                   o.storage_backend_name AS primary_storage_backend_name,
+                   o.version AS primary_version, o.status AS primary_status,
+                   om.size_bytes AS primary_size_bytes, om.mime_type AS primary_mime_type,
+                   om.etag AS primary_etag
+            FROM object o
+            LEFT JOIN object_metadata om ON om.object_id = o.id
+            WHERE o.content_id = content.id AND o.deleted_at IS NULL AND o.status IN ('uploaded', 'processed')
+            ORDER BY o.version DESC
+            LIMIT 1) po ON true`
+
+// primaryObjectColumns selects the columns of primaryObjectJoin in primaryObjectRow order
+const primaryObjectColumns = `
+               primary_id, primary_object_key, primary_storage_backend_name, primary_version,
+               primary_status, primary_size_bytes, primary_mime_type, primary_etag`
+
+// primaryObjectRow scans the nullable columns of primaryObjectJoin
+type primaryObjectRow struct {
+	id                 *uuid.UUID
+	objectKey          *string
+	storageBackendName *string
+	version            *int
+	status             *string
+	sizeBytes          *int64
+	mimeType           *string
+	etag               *string
+}
+
+func (p *primaryObjectRow) dest() []interface{} {
+	return []interface{}{&p.id, &p.objectKey, &p.storageBackendName, &p.version,
+		&p.status, &p.sizeBytes, &p.mimeType, &p.etag}
+}
+
+// object returns the scanned primary object, or nil when the content has none
+func (p *primaryObjectRow) object() *simplecontent.PrimaryObject {
+	if p.id == nil {
+		return nil
+	}
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	object := &simplecontent.PrimaryObject{
+		ID:                 *p.id,
+		ObjectKey:          deref(p.objectKey),
+		StorageBackendName: deref(p.storageBackendName),
+		Status:             deref(p.status),
+		MimeType:           deref(p.mimeType),
+		ETag:               deref(p.etag),
+	}
+	if p.version != nil {
+		object.Version = *p.version
+	}
+	if p.sizeBytes != nil {
+		object.SizeBytes = *p.sizeBytes
+	}
+	return object
+}
+
 func (r *Repository) CountContentWithFilters(ctx context.Context, filters simplecontent.ContentCountFilters) (int64, error) {
 	query := "SELECT COUNT(*) FROM content WHERE 1=1"
 
@@ -1022,6 +1705,20 @@ func (r *Repository) CountContentWithFilters(ctx context.Context, filters simple
 		args = append(args, *filters.UpdatedBefore)
 		argIndex++
 	}
+	if filters.WithoutUploadedObjects {
+		query += withoutUploadedObjectsClause
+	}
+	if filters.OriginalsOnly {
+		query += originalsOnlyClause
+	}
+	if filters.Where != nil {
+		clause, whereArgs, err := contentFilterClause(filters.Where, argIndex)
+		if err != nil {
+			return 0, err
+		}
+		query += clause
+		args = append(args, whereArgs...)
+	}
 
 	var count int64
 	err := r.db.QueryRow(ctx, query, args...).Scan(&count)
@@ -1048,7 +1745,10 @@ func (r *Repository) GetContentStatistics(ctx context.Context, filters simplecon
 	result.TotalCount = totalCount
 
 	// Build base WHERE clause for all statistics queries
-	baseWhere, baseArgs := r.buildStatisticsWhereClause(filters)
+	baseWhere, baseArgs, err := r.buildStatisticsWhereClause(filters)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get status breakdown
 	if options.IncludeStatusBreakdown {
@@ -1144,11 +1844,94 @@ func (r *Repository) GetContentStatistics(ctx context.Context, filters simplecon
 		result.NewestContent = newest
 	}
 
+	// Get deduplication totals from content metadata checksums
+	if options.IncludeDedupBreakdown {
+		query := `
+			SELECT COUNT(*), COALESCE(SUM(refs), 0)::bigint, COALESCE(SUM((refs - 1) * size), 0)::bigint
+			FROM (
+				SELECT checksum, COUNT(*) AS refs, MAX(file_size) AS size
+				FROM content_metadata
+				WHERE checksum <> '' AND content_id IN (SELECT id FROM content WHERE ` + baseWhere + `)
+				GROUP BY checksum
+			) blobs`
+		err := r.db.QueryRow(ctx, query, baseArgs...).Scan(&result.UniqueBlobs, &result.TotalReferences, &result.BytesSaved)
+		if err != nil {
+			return nil, r.handlePostgresError("get dedup breakdown", err)
+		}
+	}
+
 	return result, nil
 }
 
+// withoutUploadedObjectsClause keeps content none of whose live objects holds uploaded data
+const withoutUploadedObjectsClause = ` AND NOT EXISTS (
+	SELECT 1 FROM object o
+	WHERE o.content_id = content.id AND o.deleted_at IS NULL AND o.status IN ('uploaded', 'processed'))`
+
+// originalsOnlyClause drops content that has a live derivation relationship to a parent
+const originalsOnlyClause = ` AND NOT EXISTS (
+	SELECT 1 FROM content_derived cd
+	WHERE cd.content_id = content.id AND cd.deleted_at IS NULL)`
+
+// contentFilterClause compiles filter into an " AND (...)" clause whose values are bound to
+// parameters numbered from argIndex. Field names are checked against the filterable columns
+// by Normalized, so they are safe to use as column names.
+func contentFilterClause(filter *simplecontent.ContentFilter, argIndex int) (string, []interface{}, error) {
+	normalized, err := filter.Normalized()
+	if err != nil {
+		return "", nil, err
+	}
+	var args []interface{}
+	return " AND " + compileContentFilter(normalized, argIndex, &args), args, nil
+}
+
+func compileContentFilter(f *simplecontent.ContentFilter, argIndex int, args *[]interface{}) string {
+	param := func(value interface{}) string {
+		*args = append(*args, value)
+		return fmt.Sprintf("$%d", argIndex+len(*args)-1)
+	}
+
+	if f.Field == "" {
+		var all []string
+		for _, member := range f.All {
+			all = append(all, compileContentFilter(member, argIndex, args))
+		}
+		if len(f.Any) > 0 {
+			var anyOf []string
+			for _, member := range f.Any {
+				anyOf = append(anyOf, compileContentFilter(member, argIndex, args))
+			}
+			all = append(all, "("+strings.Join(anyOf, " OR ")+")")
+		}
+		if len(all) == 0 {
+			return "TRUE"
+		}
+		return "(" + strings.Join(all, " AND ") + ")"
+	}
+
+	switch f.Op {
+	case simplecontent.FilterIn:
+		params := make([]string, len(f.Values))
+		for i, value := range f.Values {
+			params[i] = param(value)
+		}
+		return fmt.Sprintf("%s IN (%s)", f.Field, strings.Join(params, ", "))
+	case simplecontent.FilterNe:
+		// Unlike <>, also true for NULL columns such as derivation_type of originals
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", f.Field, param(f.Values[0]))
+	}
+	operators := map[simplecontent.FilterOp]string{
+		simplecontent.FilterEq:  "=",
+		simplecontent.FilterGt:  ">",
+		simplecontent.FilterGte: ">=",
+		simplecontent.FilterLt:  "<",
+		simplecontent.FilterLte: "<=",
+	}
+	return fmt.Sprintf("%s %s %s", f.Field, operators[f.Op], param(f.Values[0]))
+}
+
 // buildStatisticsWhereClause builds the WHERE clause for statistics queries
-func (r *Repository) buildStatisticsWhereClause(filters simplecontent.ContentCountFilters) (string, []interface{}) {
+func (r *Repository) buildStatisticsWhereClause(filters simplecontent.ContentCountFilters) (string, []interface{}, error) {
 	where := "1=1"
 	args := []interface{}{}
 	argIndex := 1
@@ -1233,6 +2016,70 @@ func (r *Repository) buildStatisticsWhereClause(filters simplecontent.ContentCou
 		args = append(args, *filters.UpdatedBefore)
 		argIndex++
 	}
+	if filters.WithoutUploadedObjects {
+		where += withoutUploadedObjectsClause
+	}
+	if filters.OriginalsOnly {
+		where += originalsOnlyClause
+	}
+	if filters.Where != nil {
+		clause, whereArgs, err := contentFilterClause(filters.Where, argIndex)
+		if err != nil {
+			return "", nil, err
+		}
+		where += clause
+		args = append(args, whereArgs...)
+	}
+
+	return where, args, nil
+}
+
+// Advisory locks
+
+// AcquireLock takes a session-level postgres advisory lock on the hash of key, so callers in
+// every process sharing the database are serialized. With a pool the lock is held on a
+// connection reserved until unlock or until ctx is done; postgres also drops it when the
+// session ends, e.g. when the holding process dies.
+//
+// Without a pool (New with a single connection or transaction) the lock is taken on that
+// shared session. Advisory locks are re-entrant per session, so callers sharing it are only
+// serialized against other sessions, not against each other, and the unlock runs on the
+// shared connection, which pgx does not allow to be used concurrently. Use NewWithPool when
+// locks must serialize callers within the process.
+func (r *Repository) AcquireLock(ctx context.Context, key string) (func(), error) {
+	db := r.db
+	var conn *pgxpool.Conn
+	if pool, ok := r.db.(*pgxpool.Pool); ok {
+		var err error
+		if conn, err = pool.Acquire(ctx); err != nil {
+			return nil, r.handlePostgresError("acquire lock", err)
+		}
+		db = conn
+	}
 
-	return where, args
+	if _, err := db.Exec(ctx, `SELECT pg_advisory_lock(hashtext($1))`, key); err != nil {
+		if conn != nil {
+			conn.Release()
+		}
+		return nil, r.handlePostgresError("acquire lock", err)
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			if _, err := db.Exec(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, key); err != nil && conn != nil {
+				// Closing the session is the only other way to drop the lock
+				conn.Conn().Close(context.Background())
+			}
+			if conn != nil {
+				conn.Release()
+			}
+		})
+	}
+	// The lock goes with its holder's context
+	stop := context.AfterFunc(ctx, release)
+	return func() {
+		stop()
+		release()
+	}, nil
 }
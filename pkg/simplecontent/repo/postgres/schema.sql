@@ -78,6 +78,49 @@ CREATE TABLE IF NOT EXISTS content_derived (
     PRIMARY KEY (parent_id, content_id)
 );
 
+CREATE TABLE IF NOT EXISTS content_relationship (
+    from_content_id UUID NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    to_content_id UUID NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    relation_type VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (from_content_id, to_content_id, relation_type)
+);
+
+CREATE TABLE IF NOT EXISTS content_access_event (
+    id UUID PRIMARY KEY,
+    content_id UUID NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    object_id UUID,
+    action VARCHAR(32) NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Content metadata history: one row per write to content_metadata
+CREATE TABLE IF NOT EXISTS content_metadata_history (
+    id BIGSERIAL PRIMARY KEY,
+    content_id UUID NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    tags TEXT[],
+    file_size BIGINT,
+    file_name VARCHAR(500),
+    mime_type VARCHAR(100),
+    checksum VARCHAR(100),
+    checksum_algorithm VARCHAR(50),
+    metadata JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    actor TEXT NOT NULL DEFAULT ''
+);
+
+-- Upload intents: write-ahead records of uploads in progress
+CREATE TABLE IF NOT EXISTS object_upload_intent (
+    object_id UUID PRIMARY KEY,
+    content_id UUID NOT NULL,
+    storage_backend_name VARCHAR(64) NOT NULL,
+    object_key VARCHAR(1024) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
 
 -- Indexes for better query performance
 
@@ -96,6 +139,19 @@ CREATE INDEX IF NOT EXISTS idx_object_created_at ON object(created_at);
 -- Derived content indexes
 CREATE INDEX IF NOT EXISTS idx_content_derived_parent ON content_derived(parent_id);
 CREATE INDEX IF NOT EXISTS idx_content_derived_variant ON content_derived(variant);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_content_derived_parent_variant_unique ON content_derived(parent_id, variant) WHERE deleted_at IS NULL AND variant <> '';
+
+-- Content relationship indexes
+CREATE INDEX IF NOT EXISTS idx_content_relationship_to ON content_relationship(to_content_id);
+
+-- Content access event indexes
+CREATE INDEX IF NOT EXISTS idx_content_access_event_content ON content_access_event(content_id, occurred_at);
+
+-- Content metadata history indexes
+CREATE INDEX IF NOT EXISTS idx_content_metadata_history_content ON content_metadata_history(content_id, id);
+
+-- Upload intent indexes
+CREATE INDEX IF NOT EXISTS idx_object_upload_intent_created ON object_upload_intent(created_at);
 
 
 -- Functions for automatic timestamp updates
@@ -0,0 +1,363 @@
+// Package repo contains helpers that decorate simplecontent.Repository implementations.
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// RetryClassifier reports whether an error is transient and the operation may be retried.
+type RetryClassifier func(err error) bool
+
+// Decorator wraps a Repository with additional behavior.
+type Decorator func(simplecontent.Repository) simplecontent.Repository
+
+// RetryOption configures the retry decorator.
+type RetryOption func(*retryRepository)
+
+// WithBackoff sets the initial delay between attempts and the maximum delay.
+// The delay doubles after every failed attempt. Defaults: 50ms initial, 2s max.
+func WithBackoff(initial, max time.Duration) RetryOption {
+	return func(r *retryRepository) {
+		r.initialBackoff = initial
+		r.maxBackoff = max
+	}
+}
+
+// WithRetry returns a decorator that retries repository operations when the classifier
+// marks the returned error as retryable (e.g., postgres.IsTransientError).
+//
+// Only idempotent operations are retried: reads, full-row updates, metadata upserts and
+// soft deletes. Create operations are passed through unchanged because a retry after an
+// ambiguous failure could insert a duplicate record. Non-retryable errors are returned
+// immediately and untouched.
+//
+// Example:
+//
+//	repository := repo.WithRetry(3, postgres.IsTransientError)(postgres.NewWithPool(pool))
+func WithRetry(maxAttempts int, classifier RetryClassifier, opts ...RetryOption) Decorator {
+	return func(next simplecontent.Repository) simplecontent.Repository {
+		r := &retryRepository{
+			Repository:     next,
+			maxAttempts:    maxAttempts,
+			classifier:     classifier,
+			initialBackoff: 50 * time.Millisecond,
+			maxBackoff:     2 * time.Second,
+		}
+		for _, opt := range opts {
+			opt(r)
+		}
+		if r.maxAttempts < 1 {
+			r.maxAttempts = 1
+		}
+		return r
+	}
+}
+
+// retryRepository embeds the wrapped repository so non-idempotent operations
+// (CreateContent, CreateObject, CreateDerivedContentRelationship) pass through as-is.
+type retryRepository struct {
+	simplecontent.Repository
+	maxAttempts    int
+	classifier     RetryClassifier
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// retry runs fn until it succeeds, returns a non-retryable error, or attempts are exhausted.
+func retry[T any](ctx context.Context, r *retryRepository, fn func() (T, error)) (T, error) {
+	delay := r.initialBackoff
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || attempt >= r.maxAttempts || r.classifier == nil || !r.classifier(err) {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > r.maxBackoff {
+			delay = r.maxBackoff
+		}
+	}
+}
+
+// retryErr adapts retry for operations that only return an error.
+func retryErr(ctx context.Context, r *retryRepository, fn func() error) error {
+	_, err := retry(ctx, r, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// Content operations
+
+func (r *retryRepository) GetContent(ctx context.Context, id uuid.UUID) (*simplecontent.Content, error) {
+	return retry(ctx, r, func() (*simplecontent.Content, error) {
+		return r.Repository.GetContent(ctx, id)
+	})
+}
+
+func (r *retryRepository) ContentExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return retry(ctx, r, func() (bool, error) {
+		return r.Repository.ContentExists(ctx, id)
+	})
+}
+
+func (r *retryRepository) GetContentsByIDs(ctx context.Context, ids []uuid.UUID) ([]*simplecontent.Content, error) {
+	return retry(ctx, r, func() ([]*simplecontent.Content, error) {
+		return r.Repository.GetContentsByIDs(ctx, ids)
+	})
+}
+
+func (r *retryRepository) UpdateContent(ctx context.Context, content *simplecontent.Content) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.UpdateContent(ctx, content)
+	})
+}
+
+func (r *retryRepository) DeleteContent(ctx context.Context, id uuid.UUID) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.DeleteContent(ctx, id)
+	})
+}
+
+func (r *retryRepository) DeleteContentTree(ctx context.Context, contentIDs []uuid.UUID) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.DeleteContentTree(ctx, contentIDs)
+	})
+}
+
+func (r *retryRepository) ListContent(ctx context.Context, ownerID, tenantID uuid.UUID) ([]*simplecontent.Content, error) {
+	return retry(ctx, r, func() ([]*simplecontent.Content, error) {
+		return r.Repository.ListContent(ctx, ownerID, tenantID)
+	})
+}
+
+// Content metadata operations
+
+func (r *retryRepository) SetContentMetadata(ctx context.Context, metadata *simplecontent.ContentMetadata) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.SetContentMetadata(ctx, metadata)
+	})
+}
+
+func (r *retryRepository) UpdateContentTags(ctx context.Context, contentIDs []uuid.UUID, add, remove []string) (int64, error) {
+	return retry(ctx, r, func() (int64, error) {
+		return r.Repository.UpdateContentTags(ctx, contentIDs, add, remove)
+	})
+}
+
+func (r *retryRepository) GetContentMetadata(ctx context.Context, contentID uuid.UUID) (*simplecontent.ContentMetadata, error) {
+	return retry(ctx, r, func() (*simplecontent.ContentMetadata, error) {
+		return r.Repository.GetContentMetadata(ctx, contentID)
+	})
+}
+
+func (r *retryRepository) ListContentMetadataHistory(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.ContentMetadataVersion, error) {
+	return retry(ctx, r, func() ([]*simplecontent.ContentMetadataVersion, error) {
+		return r.Repository.ListContentMetadataHistory(ctx, contentID)
+	})
+}
+
+func (r *retryRepository) GetContentMetadataByContentIDs(ctx context.Context, contentIDs []uuid.UUID) (map[uuid.UUID]*simplecontent.ContentMetadata, error) {
+	return retry(ctx, r, func() (map[uuid.UUID]*simplecontent.ContentMetadata, error) {
+		return r.Repository.GetContentMetadataByContentIDs(ctx, contentIDs)
+	})
+}
+
+// Status query operations
+
+func (r *retryRepository) GetContentByStatus(ctx context.Context, status string) ([]*simplecontent.Content, error) {
+	return retry(ctx, r, func() ([]*simplecontent.Content, error) {
+		return r.Repository.GetContentByStatus(ctx, status)
+	})
+}
+
+func (r *retryRepository) GetObjectsByStatus(ctx context.Context, status string) ([]*simplecontent.Object, error) {
+	return retry(ctx, r, func() ([]*simplecontent.Object, error) {
+		return r.Repository.GetObjectsByStatus(ctx, status)
+	})
+}
+
+// Derived and typed relationship operations
+
+func (r *retryRepository) ListDerivedContent(ctx context.Context, params simplecontent.ListDerivedContentParams) ([]*simplecontent.DerivedContent, error) {
+	return retry(ctx, r, func() ([]*simplecontent.DerivedContent, error) {
+		return r.Repository.ListDerivedContent(ctx, params)
+	})
+}
+
+func (r *retryRepository) ListDerivedContentWithDetails(ctx context.Context, params simplecontent.ListDerivedContentParams) ([]*simplecontent.DerivedContentWithDetails, error) {
+	return retry(ctx, r, func() ([]*simplecontent.DerivedContentWithDetails, error) {
+		return r.Repository.ListDerivedContentWithDetails(ctx, params)
+	})
+}
+
+func (r *retryRepository) GetDerivedRelationshipByContentID(ctx context.Context, contentID uuid.UUID) (*simplecontent.DerivedContent, error) {
+	return retry(ctx, r, func() (*simplecontent.DerivedContent, error) {
+		return r.Repository.GetDerivedRelationshipByContentID(ctx, contentID)
+	})
+}
+
+func (r *retryRepository) UpdateDerivedContentRelationship(ctx context.Context, params simplecontent.UpdateDerivedContentParams) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.UpdateDerivedContentRelationship(ctx, params)
+	})
+}
+
+func (r *retryRepository) DeleteContentRelationship(ctx context.Context, fromContentID, toContentID uuid.UUID, relType simplecontent.RelationType) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.DeleteContentRelationship(ctx, fromContentID, toContentID, relType)
+	})
+}
+
+func (r *retryRepository) ListContentRelationships(ctx context.Context, params simplecontent.ListContentRelationshipsParams) ([]*simplecontent.ContentRelationship, error) {
+	return retry(ctx, r, func() ([]*simplecontent.ContentRelationship, error) {
+		return r.Repository.ListContentRelationships(ctx, params)
+	})
+}
+
+// Object operations
+
+func (r *retryRepository) GetObject(ctx context.Context, id uuid.UUID) (*simplecontent.Object, error) {
+	return retry(ctx, r, func() (*simplecontent.Object, error) {
+		return r.Repository.GetObject(ctx, id)
+	})
+}
+
+func (r *retryRepository) ObjectExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return retry(ctx, r, func() (bool, error) {
+		return r.Repository.ObjectExists(ctx, id)
+	})
+}
+
+func (r *retryRepository) GetObjectsByContentID(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.Object, error) {
+	return retry(ctx, r, func() ([]*simplecontent.Object, error) {
+		return r.Repository.GetObjectsByContentID(ctx, contentID)
+	})
+}
+
+func (r *retryRepository) GetObjectsByContentIDs(ctx context.Context, contentIDs []uuid.UUID) (map[uuid.UUID][]*simplecontent.Object, error) {
+	return retry(ctx, r, func() (map[uuid.UUID][]*simplecontent.Object, error) {
+		return r.Repository.GetObjectsByContentIDs(ctx, contentIDs)
+	})
+}
+
+func (r *retryRepository) ListObjects(ctx context.Context, filters simplecontent.ObjectListFilters) ([]*simplecontent.Object, error) {
+	return retry(ctx, r, func() ([]*simplecontent.Object, error) {
+		return r.Repository.ListObjects(ctx, filters)
+	})
+}
+
+func (r *retryRepository) GetObjectByObjectKeyAndStorageBackendName(ctx context.Context, objectKey, storageBackendName string) (*simplecontent.Object, error) {
+	return retry(ctx, r, func() (*simplecontent.Object, error) {
+		return r.Repository.GetObjectByObjectKeyAndStorageBackendName(ctx, objectKey, storageBackendName)
+	})
+}
+
+func (r *retryRepository) UpdateObject(ctx context.Context, object *simplecontent.Object) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.UpdateObject(ctx, object)
+	})
+}
+
+func (r *retryRepository) DeleteObject(ctx context.Context, id uuid.UUID) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.DeleteObject(ctx, id)
+	})
+}
+
+// Object metadata operations
+
+func (r *retryRepository) SetObjectMetadata(ctx context.Context, metadata *simplecontent.ObjectMetadata) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.SetObjectMetadata(ctx, metadata)
+	})
+}
+
+func (r *retryRepository) GetObjectMetadata(ctx context.Context, objectID uuid.UUID) (*simplecontent.ObjectMetadata, error) {
+	return retry(ctx, r, func() (*simplecontent.ObjectMetadata, error) {
+		return r.Repository.GetObjectMetadata(ctx, objectID)
+	})
+}
+
+func (r *retryRepository) GetObjectMetadataByObjectIDs(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]*simplecontent.ObjectMetadata, error) {
+	return retry(ctx, r, func() (map[uuid.UUID]*simplecontent.ObjectMetadata, error) {
+		return r.Repository.GetObjectMetadataByObjectIDs(ctx, objectIDs)
+	})
+}
+
+func (r *retryRepository) GetUploadStatusesByObjectIDs(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]*simplecontent.UploadStatus, error) {
+	return retry(ctx, r, func() (map[uuid.UUID]*simplecontent.UploadStatus, error) {
+		return r.Repository.GetUploadStatusesByObjectIDs(ctx, objectIDs)
+	})
+}
+
+func (r *retryRepository) GetContentStorageStats(ctx context.Context, contentID uuid.UUID) (int, int64, error) {
+	type storageStats struct {
+		objectCount int
+		totalBytes  int64
+	}
+	stats, err := retry(ctx, r, func() (storageStats, error) {
+		objectCount, totalBytes, err := r.Repository.GetContentStorageStats(ctx, contentID)
+		return storageStats{objectCount, totalBytes}, err
+	})
+	return stats.objectCount, stats.totalBytes, err
+}
+
+// Admin operations
+
+func (r *retryRepository) ListContentWithFilters(ctx context.Context, filters simplecontent.ContentListFilters) ([]*simplecontent.Content, error) {
+	return retry(ctx, r, func() ([]*simplecontent.Content, error) {
+		return r.Repository.ListContentWithFilters(ctx, filters)
+	})
+}
+
+func (r *retryRepository) CountContentWithFilters(ctx context.Context, filters simplecontent.ContentCountFilters) (int64, error) {
+	return retry(ctx, r, func() (int64, error) {
+		return r.Repository.CountContentWithFilters(ctx, filters)
+	})
+}
+
+func (r *retryRepository) GetContentStatistics(ctx context.Context, filters simplecontent.ContentCountFilters, options simplecontent.ContentStatisticsOptions) (*simplecontent.ContentStatisticsResult, error) {
+	return retry(ctx, r, func() (*simplecontent.ContentStatisticsResult, error) {
+		return r.Repository.GetContentStatistics(ctx, filters, options)
+	})
+}
+
+// Access audit operations
+
+func (r *retryRepository) ListAccessEvents(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.AccessEvent, error) {
+	return retry(ctx, r, func() ([]*simplecontent.AccessEvent, error) {
+		return r.Repository.ListAccessEvents(ctx, contentID)
+	})
+}
+
+// Upload intent operations
+
+func (r *retryRepository) CreateUploadIntent(ctx context.Context, intent *simplecontent.UploadIntent) error {
+	// Safe to retry: the insert replaces any intent recorded for the same object
+	return retryErr(ctx, r, func() error {
+		return r.Repository.CreateUploadIntent(ctx, intent)
+	})
+}
+
+func (r *retryRepository) DeleteUploadIntent(ctx context.Context, objectID uuid.UUID) error {
+	return retryErr(ctx, r, func() error {
+		return r.Repository.DeleteUploadIntent(ctx, objectID)
+	})
+}
+
+func (r *retryRepository) ListUploadIntents(ctx context.Context, createdBefore time.Time) ([]*simplecontent.UploadIntent, error) {
+	return retry(ctx, r, func() ([]*simplecontent.UploadIntent, error) {
+		return r.Repository.ListUploadIntents(ctx, createdBefore)
+	})
+}
@@ -0,0 +1,134 @@
+package repo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/repo"
+	"github.com/tendant/simple-content/pkg/simplecontent/repo/memory"
+)
+
+var (
+	errTransient = errors.New("serialization failure")
+	errPermanent = errors.New("constraint violation")
+)
+
+func isTransient(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+// stubRepository fails calls with the queued errors before delegating to the embedded repository.
+type stubRepository struct {
+	simplecontent.Repository
+	errs  []error
+	calls int
+}
+
+func (s *stubRepository) nextErr() error {
+	s.calls++
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func (s *stubRepository) GetContent(ctx context.Context, id uuid.UUID) (*simplecontent.Content, error) {
+	if err := s.nextErr(); err != nil {
+		return nil, err
+	}
+	return s.Repository.GetContent(ctx, id)
+}
+
+func (s *stubRepository) CreateContent(ctx context.Context, content *simplecontent.Content) error {
+	if err := s.nextErr(); err != nil {
+		return err
+	}
+	return s.Repository.CreateContent(ctx, content)
+}
+
+func newContent(t *testing.T, r simplecontent.Repository) *simplecontent.Content {
+	t.Helper()
+	content := &simplecontent.Content{
+		ID:        uuid.New(),
+		TenantID:  uuid.New(),
+		OwnerID:   uuid.New(),
+		Status:    string(simplecontent.ContentStatusCreated),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, r.CreateContent(context.Background(), content))
+	return content
+}
+
+func TestWithRetry_RetriesTransientErrors(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRepository{Repository: memory.New()}
+	content := newContent(t, stub.Repository)
+
+	stub.errs = []error{errTransient, errTransient}
+	r := repo.WithRetry(3, isTransient, repo.WithBackoff(time.Millisecond, time.Millisecond))(stub)
+
+	got, err := r.GetContent(ctx, content.ID)
+	require.NoError(t, err)
+	assert.Equal(t, content.ID, got.ID)
+	assert.Equal(t, 3, stub.calls)
+}
+
+func TestWithRetry_StopsOnNonRetryableError(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRepository{Repository: memory.New()}
+	content := newContent(t, stub.Repository)
+
+	stub.errs = []error{errTransient, errPermanent, errTransient}
+	r := repo.WithRetry(5, isTransient, repo.WithBackoff(time.Millisecond, time.Millisecond))(stub)
+
+	_, err := r.GetContent(ctx, content.ID)
+	assert.Same(t, errPermanent, err, "non-retryable error should be returned untouched")
+	assert.Equal(t, 2, stub.calls)
+}
+
+func TestWithRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRepository{Repository: memory.New()}
+	content := newContent(t, stub.Repository)
+
+	stub.errs = []error{errTransient, errTransient, errTransient}
+	r := repo.WithRetry(2, isTransient, repo.WithBackoff(time.Millisecond, time.Millisecond))(stub)
+
+	_, err := r.GetContent(ctx, content.ID)
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 2, stub.calls)
+}
+
+func TestWithRetry_DoesNotRetryCreates(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRepository{Repository: memory.New(), errs: []error{errTransient}}
+	r := repo.WithRetry(3, isTransient, repo.WithBackoff(time.Millisecond, time.Millisecond))(stub)
+
+	err := r.CreateContent(ctx, &simplecontent.Content{ID: uuid.New()})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 1, stub.calls)
+}
+
+func TestWithRetry_StopsWhenContextCancelled(t *testing.T) {
+	stub := &stubRepository{Repository: memory.New()}
+	content := newContent(t, stub.Repository)
+
+	stub.errs = []error{errTransient, errTransient}
+	r := repo.WithRetry(3, isTransient, repo.WithBackoff(time.Hour, time.Hour))(stub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := r.GetContent(ctx, content.ID)
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 1, stub.calls)
+}
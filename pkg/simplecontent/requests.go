@@ -2,6 +2,7 @@ package simplecontent
 
 import (
 	"io"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -42,6 +43,9 @@ type CreateDerivedContentRequest struct {
 	OwnerType      string
 	Name           string
 	FileName       string
+	// Overwrite updates the parent's existing derived content of the variant in place
+	// instead of failing with ErrVariantExists
+	Overwrite      bool
 }
 
 // UpdateContentRequest contains parameters for updating content
@@ -49,10 +53,64 @@ type UpdateContentRequest struct {
 	Content *Content
 }
 
-// ListContentRequest contains parameters for listing content
+// DeleteContentOptions controls how DeleteContentWithOptions treats derived content.
+// With Cascade, all derived descendants and their objects are deleted together with the
+// content and their blobs are removed from storage. Without it, content that still has
+// derived children is not deleted and ErrHasDerivedContent is returned.
+type DeleteContentOptions struct {
+	Cascade bool
+}
+
+// DeleteObjectOptions controls how DeleteObjectWithOptions treats the object's stored data.
+// By default the blob is removed from storage together with the object. KeepBlob only
+// detaches the object record and leaves the blob in place, e.g. during a re-import.
+type DeleteObjectOptions struct {
+	KeepBlob bool
+}
+
+// ListContentRequest contains parameters for listing content, newest first. The optional time bounds
+// are inclusive; contents outside any set bound are left out. Limit and Offset select a page.
 type ListContentRequest struct {
 	OwnerID  uuid.UUID
 	TenantID uuid.UUID
+
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+
+	Limit  int // Optional - 0 returns all matches
+	Offset int
+
+	// OriginalsOnly leaves out derived content (thumbnails, previews, ...), listing only
+	// top-level content as a library view would
+	OriginalsOnly bool
+
+	// IncludePrimaryObject sets Content.PrimaryObject on each listed content
+	IncludePrimaryObject bool
+}
+
+// WithOriginalsOnly returns a copy of the request listing only content that is not derived
+// from other content
+func (r ListContentRequest) WithOriginalsOnly() ListContentRequest {
+	r.OriginalsOnly = true
+	return r
+}
+
+// WithPrimaryObject returns a copy of the request that loads each content's primary object,
+// its key and size, together with the content, without a query per content
+func (r ListContentRequest) WithPrimaryObject() ListContentRequest {
+	r.IncludePrimaryObject = true
+	return r
+}
+
+// ListObjectsRequest contains parameters for listing objects. Empty fields do not filter.
+type ListObjectsRequest struct {
+	ContentID uuid.UUID
+	Status    ObjectStatus
+	Backend   string
+	Limit     int // Optional - 0 returns all matches
+	Offset    int
 }
 
 // SetContentMetadataRequest contains parameters for setting content metadata
@@ -71,10 +129,13 @@ type SetContentMetadataRequest struct {
 // CreateObjectRequest contains parameters for creating an object
 type CreateObjectRequest struct {
 	ContentID          uuid.UUID
-	StorageBackendName string
+	StorageBackendName string // Optional - uses the tenant's backend or the default if empty
 	Version            int
 	ObjectKey          string
 	FileName           string
+	// CollisionPolicy applies when ObjectKey is set and already in use (optional, defaults
+	// to the service policy set with WithCollisionPolicy)
+	CollisionPolicy CollisionPolicy
 }
 
 // UploadObjectRequest contains parameters for uploading an object
@@ -82,6 +143,12 @@ type UploadObjectRequest struct {
 	ObjectID uuid.UUID
 	Reader   io.Reader
 	MimeType string // Optional - for metadata
+	// SizeBytes is the expected total size, reported as TotalBytes by GetUploadProgress (optional)
+	SizeBytes int64
+	// IfMatch makes the upload conditional: it fails with ErrPreconditionFailed unless the object
+	// still has this ETag, either its content ETag or its backend ETag. "*" only requires that
+	// data was uploaded before (optional)
+	IfMatch string
 }
 
 // UploadContentRequest contains parameters for uploading content with data.
@@ -100,6 +167,21 @@ type UploadContentRequest struct {
 	CustomMetadata     map[string]interface{} // Optional - additional metadata
 }
 
+// ImportFromURLRequest contains parameters for importing content from a remote URL.
+// The MIME type and file name default to what the response headers report.
+type ImportFromURLRequest struct {
+	URL                string
+	OwnerID            uuid.UUID
+	TenantID           uuid.UUID
+	Name               string // Optional - defaults to the file name
+	Description        string
+	DocumentType       string // Optional - defaults to the response Content-Type
+	StorageBackendName string // Optional - uses default if empty
+	FileName           string // Optional - defaults to Content-Disposition or the URL path
+	Tags               []string // Optional - for metadata
+	CustomMetadata     map[string]interface{} // Optional - additional metadata
+}
+
 // UploadDerivedContentRequest contains parameters for uploading derived content.
 // This replaces the workflow of CreateDerivedContent + CreateObject + UploadObject.
 type UploadDerivedContentRequest struct {
@@ -139,6 +221,9 @@ type ContentDetailsOption func(*ContentDetailsConfig)
 type ContentDetailsConfig struct {
 	IncludeUploadURL bool
 	URLExpiryTime    int // Seconds
+	// Disposition and DownloadFileName override the download URL's presentation, see WithDownloadDisposition
+	Disposition      Disposition
+	DownloadFileName string
 }
 
 // WithUploadAccess configures GetContentDetails to include upload URLs for content that needs data
@@ -0,0 +1,106 @@
+package simplecontent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// RestoreObjectVersion rolls content back to an earlier version by copying that version's
+// data into a new object with the next version number, which becomes the version served
+// by downloads. Every existing version, including the one that was current, is kept. The
+// copy is a server-side copy on backends implementing ObjectCopier. The version must be
+// uploaded; ErrObjectNotFound is returned when the content has no such version and
+// ErrObjectNotReady when its data was never uploaded.
+func (s *service) RestoreObjectVersion(ctx context.Context, contentID uuid.UUID, version int) (*Object, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := s.requireContent(ctx, contentID); err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "restore_version", Err: err}
+	}
+
+	// Concurrent restores of a content would otherwise pick the same new version number
+	unlock, err := s.repository.AcquireLock(ctx, "version:"+contentID.String())
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "restore_version", Err: err}
+	}
+	defer unlock()
+
+	objects, err := s.repository.GetObjectsByContentID(ctx, contentID)
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "restore_version", Err: err}
+	}
+	var source *Object
+	latest := 0
+	for _, object := range objects {
+		latest = max(latest, object.Version)
+		if object.Version == version {
+			source = object
+		}
+	}
+	if source == nil {
+		return nil, &ContentError{ContentID: contentID, Op: "restore_version", Err: fmt.Errorf("%w: version %d", ErrObjectNotFound, version)}
+	}
+	if source.Status != string(ObjectStatusUploaded) {
+		return nil, &ObjectError{ObjectID: source.ID, Op: "restore_version", Err: ErrObjectNotReady}
+	}
+	backend, err := s.GetBackend(source.StorageBackendName)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: source.ID, Op: "restore_version", Err: err}
+	}
+	sourceMetadata, _ := s.repository.GetObjectMetadata(ctx, source.ID)
+
+	restored, err := s.CreateObject(ctx, CreateObjectRequest{
+		ContentID:          contentID,
+		StorageBackendName: source.StorageBackendName,
+		Version:            latest + 1,
+		FileName:           source.FileName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.beginUpload(ctx, restored); err != nil {
+		return nil, err
+	}
+	var keepIntent bool
+	defer s.endUpload(ctx, restored.ID, &keepIntent)
+
+	if err := blobCopy(ctx, backend, source.ObjectKey, restored.ObjectKey); err != nil {
+		s.stats.backendError(source.StorageBackendName)
+		return nil, &StorageError{Backend: source.StorageBackendName, Key: restored.ObjectKey, Op: "copy", Err: err}
+	}
+	mimeType := source.ObjectType
+	if sourceMetadata != nil && sourceMetadata.MimeType != "" {
+		mimeType = sourceMetadata.MimeType
+	}
+	if err := s.mirrorUpload(ctx, restored, mimeType); err != nil {
+		return nil, err
+	}
+
+	objectMetadata, err := s.updateObjectFromStorage(ctx, restored.ID)
+	if err != nil {
+		keepIntent = true
+		return nil, err
+	}
+	// The data is unchanged, so the content ETag of the source still holds
+	if sourceMetadata != nil {
+		if etag, ok := sourceMetadata.Metadata[MetaContentETag].(string); ok && s.contentETags {
+			s.setContentETag(ctx, objectMetadata, etag)
+		}
+	}
+	if restored, err = s.repository.GetObject(ctx, restored.ID); err != nil {
+		return nil, &ObjectError{ObjectID: restored.ID, Op: "restore_version", Err: err}
+	}
+	s.addressByContent(ctx, restored, "")
+
+	if s.eventSink != nil {
+		if err := s.eventSink.ObjectUploaded(ctx, restored); err != nil {
+			// Log error but don't fail the operation
+			slog.Error("Failed to emit ObjectUploaded event", "object_id", restored.ID, "error", err)
+		}
+	}
+	return restored, nil
+}
@@ -13,23 +13,34 @@ type Service interface {
 	// Content operations
 	CreateContent(ctx context.Context, req CreateContentRequest) (*Content, error)
 	GetContent(ctx context.Context, id uuid.UUID) (*Content, error)
+	// ContentExists reports whether content exists, for callers that do not need the content itself
+	ContentExists(ctx context.Context, id uuid.UUID) (bool, error)
 	UpdateContent(ctx context.Context, req UpdateContentRequest) error
 	DeleteContent(ctx context.Context, id uuid.UUID) error
+	DeleteContentWithOptions(ctx context.Context, id uuid.UUID, opts DeleteContentOptions) error
 	ListContent(ctx context.Context, req ListContentRequest) ([]*Content, error)
+	ListContentPage(ctx context.Context, req ListContentRequest, opts ...ListContentOption) (*ListContentResult, error)
 
 	// Unified content upload operations (replaces object-based workflow)
 	UploadContent(ctx context.Context, req UploadContentRequest) (*Content, error)
 	UploadDerivedContent(ctx context.Context, req UploadDerivedContentRequest) (*Content, error)
+	// ImportContentFromURL fetches a remote resource and stores it as new content
+	ImportContentFromURL(ctx context.Context, req ImportFromURLRequest) (*Content, error)
 
 	// Async workflow support: upload object for existing content
 	UploadObjectForContent(ctx context.Context, req UploadObjectForContentRequest) (*Object, error)
 
 	// Content data access
 	DownloadContent(ctx context.Context, contentID uuid.UUID) (io.ReadCloser, error)
+	DownloadContentWithSource(ctx context.Context, contentID uuid.UUID) (io.ReadCloser, *DownloadSource, error)
+	// DownloadContentVariant downloads the derived content of the given variant, generating
+	// it when WithOnDemandVariants is enabled and no such derived content exists
+	DownloadContentVariant(ctx context.Context, contentID uuid.UUID, variant string) (io.ReadCloser, error)
 
 	// Content metadata operations
 	SetContentMetadata(ctx context.Context, req SetContentMetadataRequest) error
 	GetContentMetadata(ctx context.Context, contentID uuid.UUID) (*ContentMetadata, error)
+	GetContentMetadataHistory(ctx context.Context, contentID uuid.UUID) ([]*ContentMetadataVersion, error)
 
 	// Status management operations
 	UpdateContentStatus(ctx context.Context, id uuid.UUID, newStatus ContentStatus) error
@@ -39,19 +50,41 @@ type Service interface {
 
 	// Object query operations
 	GetObjectsByContentID(ctx context.Context, contentID uuid.UUID) ([]*Object, error)
+	ListObjects(ctx context.Context, req ListObjectsRequest) ([]*Object, error)
 
 	// Storage backend operations
 	RegisterBackend(name string, backend BlobStore)
 	GetBackend(name string) (BlobStore, error)
 
 	// Derived content operations
+	// CreateDerivedContent returns the existing derived content when the parent already has
+	// one for the variant. Concurrent calls for the same variant are serialized with a
+	// repository lock, so they create a single derived content.
 	CreateDerivedContent(ctx context.Context, req CreateDerivedContentRequest) (*Content, error)
 	GetDerivedRelationship(ctx context.Context, contentID uuid.UUID) (*DerivedContent, error)
 	ListDerivedContent(ctx context.Context, options ...ListDerivedContentOption) ([]*DerivedContent, error)
+	ListDerivedContentWithDetails(ctx context.Context, options ...ListDerivedContentOption) ([]*DerivedContentWithDetails, error)
+	MarkDerivationFailed(ctx context.Context, contentID uuid.UUID, errorMessage string) error
+	SetExpectedDerivations(ctx context.Context, contentID uuid.UUID, variants ...string) error
+	// GetContentLineage returns the ancestors of a content, immediate parent first and root last
+	GetContentLineage(ctx context.Context, contentID uuid.UUID) ([]*Content, error)
+
+	// Typed content relationship operations. Derivation is a special case managed by
+	// CreateDerivedContent; ListRelated with RelationTypeDerived lists derived children.
+	LinkContent(ctx context.Context, fromContentID, toContentID uuid.UUID, relType RelationType) (*ContentRelationship, error)
+	UnlinkContent(ctx context.Context, fromContentID, toContentID uuid.UUID, relType RelationType) error
+	ListRelated(ctx context.Context, contentID uuid.UUID, relType RelationType) ([]*ContentRelationship, error)
 
 	// Content details operations (unified interface for clients)
 	GetContentDetails(ctx context.Context, contentID uuid.UUID, options ...ContentDetailsOption) (*ContentDetails, error)
 	GetContentDetailsBatch(ctx context.Context, contentIDs []uuid.UUID, options ...ContentDetailsOption) ([]*ContentDetails, error)
+	GetContentFull(ctx context.Context, contentID uuid.UUID) (*ContentFull, error)
+
+	// Access audit operations
+	RecordContentAccess(ctx context.Context, event *AccessEvent) error
+
+	// Operational counters since the service was created
+	Stats() ServiceStats
 }
 
 // StorageService defines operations for advanced users who need direct object access.
@@ -60,19 +93,62 @@ type StorageService interface {
 	// Object operations (internal use only)
 	CreateObject(ctx context.Context, req CreateObjectRequest) (*Object, error)
 	GetObject(ctx context.Context, id uuid.UUID) (*Object, error)
+	// ObjectExists reports whether an object exists, for callers that do not need the object itself
+	ObjectExists(ctx context.Context, id uuid.UUID) (bool, error)
 	GetObjectsByContentID(ctx context.Context, contentID uuid.UUID) ([]*Object, error)
 	UpdateObject(ctx context.Context, object *Object) error
+	ListObjects(ctx context.Context, req ListObjectsRequest) ([]*Object, error)
 	DeleteObject(ctx context.Context, id uuid.UUID) error
+	DeleteObjectWithOptions(ctx context.Context, id uuid.UUID, opts DeleteObjectOptions) error
+	// MoveObject moves the object's data to newKey on the same backend and updates the
+	// object record. Returns ErrObjectKeyExists if newKey is taken.
+	MoveObject(ctx context.Context, objectID uuid.UUID, newKey string) (*Object, error)
+	// RestoreObjectVersion copies an earlier object version of the content into a new,
+	// latest version, keeping all versions. Returns the new object.
+	RestoreObjectVersion(ctx context.Context, contentID uuid.UUID, version int) (*Object, error)
+	// AppendToObject appends r to the object's data on backends implementing ObjectAppender
+	// and returns the updated metadata. Other backends return ErrAppendNotSupported.
+	AppendToObject(ctx context.Context, objectID uuid.UUID, r io.Reader) (*ObjectMetadata, error)
+	// GetObjectByKey returns the object stored under objectKey on the named backend,
+	// normalizing the key the way keys are normalized on write
+	GetObjectByKey(ctx context.Context, storageBackendName, objectKey string) (*Object, error)
 
 	// Object upload/download operations (internal use only)
 	UploadObject(ctx context.Context, req UploadObjectRequest) error
 	DownloadObject(ctx context.Context, objectID uuid.UUID) (io.ReadCloser, error)
+	DownloadObjectWithMeta(ctx context.Context, objectID uuid.UUID) (io.ReadCloser, *ObjectMeta, error)
+	// DownloadObjectPreview returns the object's data as served for preview, passed through
+	// the PreviewTransformer registered for its MIME type
+	DownloadObjectPreview(ctx context.Context, objectID uuid.UUID) (io.ReadCloser, *ObjectMeta, error)
+	GetUploadProgress(ctx context.Context, objectID uuid.UUID) (*UploadProgress, error)
+	// GetUploadStatuses returns status, stored size and last update for many objects at once
+	GetUploadStatuses(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]UploadStatus, error)
+	GetUploadOffset(ctx context.Context, objectID uuid.UUID) (int64, error)
 	GetUploadURL(ctx context.Context, objectID uuid.UUID) (string, error)
 	GetDownloadURL(ctx context.Context, objectID uuid.UUID) (string, error)
 	GetPreviewURL(ctx context.Context, objectID uuid.UUID) (string, error)
 
+	// RecoverIncompleteUploads reconciles the upload intents left behind by a crash (see
+	// WithUploadIntents), finalizing or failing each object by whether its data was stored
+	RecoverIncompleteUploads(ctx context.Context) (*UploadRecoveryReport, error)
+
+	// DownloadContentBundle streams a tar archive of the content and all its derived
+	// variants to w
+	DownloadContentBundle(ctx context.Context, contentID uuid.UUID, w io.Writer) error
+
 	// Object metadata operations (internal use only)
 	SetObjectMetadata(ctx context.Context, objectID uuid.UUID, metadata map[string]interface{}) error
 	GetObjectMetadata(ctx context.Context, objectID uuid.UUID) (map[string]interface{}, error)
+	// GetObjectsMetadata returns the metadata of every object of a content, keyed by object
+	// ID, loaded in one batch instead of a GetObjectMetadata call per object
+	GetObjectsMetadata(ctx context.Context, contentID uuid.UUID) (map[uuid.UUID]map[string]interface{}, error)
+	// AnnotateObject merges annotations into an uploaded object's metadata without touching
+	// its data, validated by WithMetadataValidator and reported as ObjectAnnotated
+	AnnotateObject(ctx context.Context, objectID uuid.UUID, annotations map[string]interface{}) error
 	UpdateObjectMetaFromStorage(ctx context.Context, objectID uuid.UUID) (*ObjectMetadata, error)
+
+	// ConfirmUpload completes a client-side (presigned) upload: it verifies the blob exists
+	// in storage, syncs its metadata and marks the object uploaded. Returns ErrBlobNotFound
+	// if nothing was uploaded.
+	ConfirmUpload(ctx context.Context, objectID uuid.UUID) (*Object, error)
 }
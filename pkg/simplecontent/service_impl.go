@@ -2,15 +2,18 @@ package simplecontent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/tendant/simple-content/pkg/simplecontent/objectkey"
 	"github.com/tendant/simple-content/pkg/simplecontent/urlstrategy"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // service implements both the Service and StorageService interfaces
@@ -19,8 +22,47 @@ type service struct {
 	blobStores   map[string]BlobStore
 	eventSink    EventSink
 	previewer    Previewer
+	enrichers    []MetadataEnricher
 	keyGenerator objectkey.Generator
 	urlStrategy  urlstrategy.URLStrategy // Pluggable URL generation strategy
+	keySanitizer func(string) string     // Optional object key sanitizer
+
+	uploadProgressInterval time.Duration            // How often UploadObject records bytes_written
+	storageTimeouts        map[string]time.Duration // Per-backend operation timeouts
+	replicaBackends        map[string][]string      // Replica backends read when a blob is missing
+	mirrorBackend          string                   // Backend every upload is copied to
+	mirrorPolicy           MirrorFailurePolicy      // What to do when the mirror write fails
+	stats                  serviceStats             // Operational counters reported by Stats
+	collisionPolicy        CollisionPolicy          // Default handling of explicit object keys already in use
+	strictContentType      bool                     // Reject uploads whose sniffed type conflicts with DocumentType
+	genericContentTypes    []string                 // Types tolerated by the strict content type check
+	uploadIntents          bool                     // Record a write-ahead intent around every blob write
+	uploadIntentGrace      time.Duration            // Age below which recovery leaves intents alone
+	derivationTypes        derivationTaxonomy       // Registered derivation types and variants; nil accepts any
+	tempDir                string                   // Directory for temporary files; empty uses os.TempDir
+	tracer                 trace.Tracer             // Starts spans around service operations; nil disables tracing
+	readOnly               bool                     // Reject every write with ErrReadOnly
+	maxDerivationDepth     int                      // Levels derived content may sit below its original
+	previewTransformers    transformerRegistry      // Preview transformers by MIME type; nil serves originals
+	contentETags           bool                     // Store a SHA-256 strong ETag with every upload
+	urlImportPolicy        URLImportPolicy          // Host, size and timeout limits for ImportContentFromURL
+	keyTemplateText        string                   // Object key template set by WithKeyTemplate
+	keyTemplate            objectkey.Generator      // Parsed key template; nil uses keyGenerator
+	objectLocks            keyedMutex               // Serializes conditional uploads per object
+	defaultBackend         string                   // Backend used when a request names none
+	tenantBackends         map[uuid.UUID]string     // Backend for each tenant's new objects
+	metaCache              *objectMetaCache         // Cached backend metadata for downloads; nil disables caching
+	defaultTenantID        uuid.UUID                // Tenant of requests that name none
+	defaultOwnerID         uuid.UUID                // Owner of requests that name none
+	metadataValidator      MetadataValidator        // Checks annotations before AnnotateObject stores them
+	variantTransformers    variantRegistry          // Transformers generating variants for DownloadContentVariant
+	onDemandVariants       bool                     // Generate missing variants in DownloadContentVariant
+	cacheVariants          bool                     // Store generated variants as derived content
+	nameMaxLength          int                      // Longest accepted content name in characters; 0 is unlimited
+	descriptionMaxLength   int                      // Longest accepted content description in characters; 0 is unlimited
+	downloadRateLimit      int64                    // Bytes per second each download may stream; 0 is unlimited
+	normalizeKeyCase       bool                     // Lowercase object keys on write and lookup
+	derivationDispatcher   DerivationDispatcher     // Runs variant generation after uploads; nil generates none
 }
 
 // Option represents a functional option for configuring the service
@@ -64,6 +106,17 @@ func WithObjectKeyGenerator(generator objectkey.Generator) Option {
 	}
 }
 
+// WithObjectKeySanitizer sanitizes every object key before it is stored, whether
+// generated or supplied in CreateObjectRequest.ObjectKey. Pass objectkey.SanitizeKey
+// to strip path traversal, normalize unicode and percent-encode unsafe characters.
+// When set, file names are also stripped of control characters before being stored
+// as the object's display name and "file_name" metadata.
+func WithObjectKeySanitizer(sanitizer func(key string) string) Option {
+	return func(s *service) {
+		s.keySanitizer = sanitizer
+	}
+}
+
 // WithURLStrategy sets the URL generation strategy for the service
 func WithURLStrategy(strategy urlstrategy.URLStrategy) Option {
 	return func(s *service) {
@@ -74,16 +127,36 @@ func WithURLStrategy(strategy urlstrategy.URLStrategy) Option {
 // New creates a new service instance with the given options
 func New(options ...Option) (Service, error) {
 	s := &service{
-		blobStores: make(map[string]BlobStore),
+		blobStores:             make(map[string]BlobStore),
+		uploadProgressInterval: defaultUploadProgressInterval,
+		maxDerivationDepth:     defaultMaxDerivationDepth,
+		nameMaxLength:          defaultNameMaxLength,
+		stats:                  serviceStats{startedAt: time.Now().UTC()},
 	}
 
 	for _, option := range options {
 		option(s)
 	}
+	s.applyStorageTimeouts()
 
 	if s.repository == nil {
 		return nil, fmt.Errorf("repository is required")
 	}
+	if err := s.validateMirror(); err != nil {
+		return nil, err
+	}
+	if err := s.validateBackendRouting(); err != nil {
+		return nil, err
+	}
+	if err := s.prepareTempDir(); err != nil {
+		return nil, err
+	}
+	if err := s.prepareKeyTemplate(); err != nil {
+		return nil, err
+	}
+	if err := s.validateContentAddressing(); err != nil {
+		return nil, err
+	}
 
 	// Set default key generator if none provided
 	if s.keyGenerator == nil {
@@ -101,16 +174,36 @@ func New(options ...Option) (Service, error) {
 // NewStorageService creates a new service instance that implements StorageService for advanced object operations
 func NewStorageService(options ...Option) (StorageService, error) {
 	s := &service{
-		blobStores: make(map[string]BlobStore),
+		blobStores:             make(map[string]BlobStore),
+		uploadProgressInterval: defaultUploadProgressInterval,
+		maxDerivationDepth:     defaultMaxDerivationDepth,
+		nameMaxLength:          defaultNameMaxLength,
+		stats:                  serviceStats{startedAt: time.Now().UTC()},
 	}
 
 	for _, option := range options {
 		option(s)
 	}
+	s.applyStorageTimeouts()
 
 	if s.repository == nil {
 		return nil, fmt.Errorf("repository is required")
 	}
+	if err := s.validateMirror(); err != nil {
+		return nil, err
+	}
+	if err := s.validateBackendRouting(); err != nil {
+		return nil, err
+	}
+	if err := s.prepareTempDir(); err != nil {
+		return nil, err
+	}
+	if err := s.prepareKeyTemplate(); err != nil {
+		return nil, err
+	}
+	if err := s.validateContentAddressing(); err != nil {
+		return nil, err
+	}
 
 	// Set default key generator if none provided
 	if s.keyGenerator == nil {
@@ -128,6 +221,13 @@ func NewStorageService(options ...Option) (StorageService, error) {
 // Content operations
 
 func (s *service) CreateContent(ctx context.Context, req CreateContentRequest) (*Content, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	s.applyOwnerDefaults(&req.OwnerID, &req.TenantID)
+	if err := s.validateContentFields(req.Name, req.Description); err != nil {
+		return nil, &ContentError{Op: "create", Err: err}
+	}
 	now := time.Now().UTC()
 	content := &Content{
 		ID:             uuid.New(),
@@ -162,9 +262,49 @@ func (s *service) CreateContent(ctx context.Context, req CreateContentRequest) (
 	return content, nil
 }
 
-const maxDerivationDepth = 5
+// defaultMaxDerivationDepth is how many levels derived content may sit below its original
+const defaultMaxDerivationDepth = 5
+
+// maxDerivationWalk bounds the ancestor walk of computeDerivationDepth, and with it the
+// depth WithMaxDerivationDepth accepts
+const maxDerivationWalk = 100
+
+// WithMaxDerivationDepth sets how many levels derived content may sit below its original:
+// 1 allows thumbnails of originals but not thumbnails of thumbnails. Creating a derivation
+// deeper than that fails with ErrMaxDerivationDepth. The default is 5; values below 1 keep
+// it, and values above 100 are capped.
+func WithMaxDerivationDepth(n int) Option {
+	return func(s *service) {
+		if n < 1 {
+			return
+		}
+		s.maxDerivationDepth = min(n, maxDerivationWalk)
+	}
+}
+
+// checkDerivationDepth fails when a derivation of the parent would exceed the maximum depth
+func (s *service) checkDerivationDepth(ctx context.Context, parentID uuid.UUID, op string) error {
+	if s.computeDerivationDepth(ctx, parentID) >= s.maxDerivationDepth {
+		return &ContentError{
+			ContentID: parentID,
+			Op:        op,
+			Err:       ErrMaxDerivationDepth,
+		}
+	}
+	return nil
+}
+
+func (s *service) CreateDerivedContent(ctx context.Context, req CreateDerivedContentRequest) (_ *Content, err error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	s.applyOwnerDefaults(&req.OwnerID, &req.TenantID)
+	ctx, span := s.startSpan(ctx, "CreateDerivedContent", AttrParentID.String(req.ParentID.String()))
+	defer func() { span.end(err) }()
+	if err := s.validateContentFields(req.Name, ""); err != nil {
+		return nil, &ContentError{ContentID: req.ParentID, Op: "create_derived", Err: err}
+	}
 
-func (s *service) CreateDerivedContent(ctx context.Context, req CreateDerivedContentRequest) (*Content, error) {
 	// Verify parent content exists and validate status
 	parentContent, err := s.repository.GetContent(ctx, req.ParentID)
 	if err != nil {
@@ -186,18 +326,46 @@ func (s *service) CreateDerivedContent(ctx context.Context, req CreateDerivedCon
 	}
 
 	// Check derivation depth limit
-	depth := s.computeDerivationDepth(ctx, req.ParentID)
-	if depth >= maxDerivationDepth {
+	if err := s.checkDerivationDepth(ctx, req.ParentID, "create_derived"); err != nil {
+		return nil, err
+	}
+
+	// Infer derivation_type from variant if missing
+	if req.DerivationType == "" && req.Variant != "" {
+		req.DerivationType = DerivationTypeFromVariant(req.Variant)
+	}
+	span.set(AttrDerivationType.String(req.DerivationType), AttrVariant.String(req.Variant))
+	if err := s.validateDerivation(req.DerivationType, req.Variant); err != nil {
 		return nil, &ContentError{
 			ContentID: req.ParentID,
 			Op:        "create_derived",
-			Err:       ErrMaxDerivationDepth,
+			Err:       err,
 		}
 	}
 
-	// Infer derivation_type from variant if missing
-	if req.DerivationType == "" && req.Variant != "" {
-		req.DerivationType = DerivationTypeFromVariant(req.Variant)
+	// Determine variant to persist in relationship
+	variant := req.Variant
+	if variant == "" {
+		variant = req.DerivationType
+	}
+	variant = string(NormalizeVariant(variant))
+
+	// One derived content per parent and variant: concurrent requests wait for the first,
+	// then fail with ErrVariantExists or, with Overwrite, update the content it created
+	var existing *Content
+	if variant != "" {
+		unlock, err := s.lockDerivation(ctx, req.ParentID, variant)
+		if err != nil {
+			return nil, &ContentError{ContentID: req.ParentID, Op: "create_derived", Err: err}
+		}
+		defer unlock()
+		existing, err = s.existingDerivedContent(ctx, req.ParentID, variant)
+		if err != nil {
+			return nil, &ContentError{ContentID: req.ParentID, Op: "create_derived", Err: err}
+		}
+		if existing != nil && !req.Overwrite {
+			return nil, &ContentError{ContentID: req.ParentID, Op: "create_derived", Err: fmt.Errorf("%w: %s", ErrVariantExists, variant)}
+		}
 	}
 
 	// Determine initial status (defaults to "created")
@@ -213,6 +381,9 @@ func (s *service) CreateDerivedContent(ctx context.Context, req CreateDerivedCon
 		}
 		initialStatus = req.InitialStatus
 	}
+	if existing != nil {
+		return s.overwriteDerivedContent(ctx, existing, req, initialStatus)
+	}
 
 	// Create derived content
 	now := time.Now().UTC()
@@ -235,6 +406,7 @@ func (s *service) CreateDerivedContent(ctx context.Context, req CreateDerivedCon
 			Err:       err,
 		}
 	}
+	span.set(AttrContentID.String(content.ID.String()))
 
 	// Create content metadata if provided
 	if req.Metadata != nil {
@@ -255,21 +427,16 @@ func (s *service) CreateDerivedContent(ctx context.Context, req CreateDerivedCon
 	}
 
 	// Create derived content relationship
-	// Determine variant to persist in relationship
-	variant := req.Variant
-	if variant == "" {
-		variant = req.DerivationType
-	}
-
 	_, err = s.repository.CreateDerivedContentRelationship(ctx, CreateDerivedContentParams{
 		ParentID:           req.ParentID,
 		DerivedContentID:   content.ID,
-		DerivationType:     req.DerivationType,                // Store the derivation type (e.g., "thumbnail")
-		Variant:            string(NormalizeVariant(variant)), // Store the specific variant (e.g., "thumbnail_256")
+		DerivationType:     req.DerivationType, // Store the derivation type (e.g., "thumbnail")
+		Variant:            variant,            // Store the specific variant (e.g., "thumbnail_256")
 		DerivationParams:   req.Metadata,
 		ProcessingMetadata: nil,
 	})
 	if err != nil {
+		s.discardDuplicateVariant(ctx, content.ID, err)
 		return nil, &ContentError{
 			ContentID: content.ID,
 			Op:        "create_derived",
@@ -292,9 +459,42 @@ func (s *service) GetContent(ctx context.Context, id uuid.UUID) (*Content, error
 	return s.repository.GetContent(ctx, id)
 }
 
+func (s *service) ContentExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.repository.ContentExists(ctx, id)
+}
+
+// requireContent fails with ErrContentNotFound unless the content exists
+func (s *service) requireContent(ctx context.Context, id uuid.UUID) error {
+	exists, err := s.repository.ContentExists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrContentNotFound
+	}
+	return nil
+}
+
 func (s *service) UpdateContent(ctx context.Context, req UpdateContentRequest) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	if err := s.validateContentFields(req.Content.Name, req.Content.Description); err != nil {
+		return &ContentError{ContentID: req.Content.ID, Op: "update", Err: err}
+	}
 	req.Content.UpdatedAt = time.Now().UTC()
 
+	// The stored content is only read when the event sink wants the changes
+	var before *Content
+	if s.wantsContentChanges() {
+		stored, err := s.repository.GetContent(ctx, req.Content.ID)
+		if err != nil {
+			slog.Warn("Failed to read content before update, changes unavailable", "content_id", req.Content.ID, "error", err)
+		} else {
+			before = stored
+		}
+	}
+
 	if err := s.repository.UpdateContent(ctx, req.Content); err != nil {
 		return &ContentError{
 			ContentID: req.Content.ID,
@@ -304,17 +504,19 @@ func (s *service) UpdateContent(ctx context.Context, req UpdateContentRequest) e
 	}
 
 	// Fire event
-	if s.eventSink != nil {
-		if err := s.eventSink.ContentUpdated(ctx, req.Content); err != nil {
-			// Log error but don't fail the operation
-			slog.Error("Failed to emit ContentUpdated event", "content_id", req.Content.ID, "error", err)
-		}
-	}
+	s.emitContentUpdated(ctx, before, req.Content)
 
 	return nil
 }
 
 func (s *service) DeleteContent(ctx context.Context, id uuid.UUID) error {
+	return s.DeleteContentWithOptions(ctx, id, DeleteContentOptions{})
+}
+
+func (s *service) DeleteContentWithOptions(ctx context.Context, id uuid.UUID, opts DeleteContentOptions) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
 	// Get content to validate status
 	content, err := s.repository.GetContent(ctx, id)
 	if err != nil {
@@ -335,7 +537,9 @@ func (s *service) DeleteContent(ctx context.Context, id uuid.UUID) error {
 		}
 	}
 
-	if err := s.repository.DeleteContent(ctx, id); err != nil {
+	// Collect the derived tree; with cascade the whole tree goes, otherwise any child blocks deletion
+	tree, err := s.collectDerivedTree(ctx, id, opts.Cascade)
+	if err != nil {
 		return &ContentError{
 			ContentID: id,
 			Op:        "delete",
@@ -343,24 +547,126 @@ func (s *service) DeleteContent(ctx context.Context, id uuid.UUID) error {
 		}
 	}
 
-	// Fire event
+	if !opts.Cascade {
+		if err := s.repository.DeleteContent(ctx, id); err != nil {
+			return &ContentError{
+				ContentID: id,
+				Op:        "delete",
+				Err:       err,
+			}
+		}
+	} else {
+		// Look up objects before they are soft-deleted so their blobs can be removed afterwards
+		objectsByContent, err := s.repository.GetObjectsByContentIDs(ctx, tree)
+		if err != nil {
+			return &ContentError{
+				ContentID: id,
+				Op:        "delete_get_objects",
+				Err:       err,
+			}
+		}
+
+		if err := s.repository.DeleteContentTree(ctx, tree); err != nil {
+			return &ContentError{
+				ContentID: id,
+				Op:        "delete_cascade",
+				Err:       err,
+			}
+		}
+
+		for _, contentID := range tree {
+			for _, object := range objectsByContent[contentID] {
+				s.deleteObjectBlob(ctx, object)
+				if s.eventSink != nil {
+					if err := s.eventSink.ObjectDeleted(ctx, object.ID); err != nil {
+						// Log error but don't fail the operation
+						slog.Error("Failed to emit ObjectDeleted event", "object_id", object.ID, "error", err)
+					}
+				}
+			}
+		}
+	}
+
+	// Fire events
 	if s.eventSink != nil {
-		if err := s.eventSink.ContentDeleted(ctx, id); err != nil {
-			// Log error but don't fail the operation
-			slog.Error("Failed to emit ContentDeleted event", "content_id", id, "error", err)
+		for _, contentID := range tree {
+			if err := s.eventSink.ContentDeleted(ctx, contentID); err != nil {
+				// Log error but don't fail the operation
+				slog.Error("Failed to emit ContentDeleted event", "content_id", contentID, "error", err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// collectDerivedTree returns id followed by all of its derived descendants, parents before
+// children. Without cascade it fails with ErrHasDerivedContent as soon as a child is found.
+func (s *service) collectDerivedTree(ctx context.Context, id uuid.UUID, cascade bool) ([]uuid.UUID, error) {
+	tree := []uuid.UUID{id}
+	seen := map[uuid.UUID]bool{id: true}
+	for i := 0; i < len(tree); i++ {
+		children, err := s.repository.ListDerivedContentWithDetails(ctx, ListDerivedContentParams{ParentID: &tree[i]})
+		if err != nil {
+			return nil, err
+		}
+		if len(children) > 0 && !cascade {
+			return nil, fmt.Errorf("%w: %d derived content item(s) exist, use cascade to delete them", ErrHasDerivedContent, len(children))
+		}
+		for _, child := range children {
+			if seen[child.ContentID] {
+				continue
+			}
+			if ok, statusErr := canDeleteContent(ContentStatus(child.Content.Status), false); !ok {
+				return nil, fmt.Errorf("derived content %s: %w", child.ContentID, statusErr)
+			}
+			seen[child.ContentID] = true
+			tree = append(tree, child.ContentID)
+		}
+	}
+	return tree, nil
+}
+
+// deleteObjectBlob removes an object's data from its storage backend unless other objects
+// still share it; the blob lock keeps a new reference from appearing between the check and
+// the delete. The object row is already deleted, so failures only leave an orphaned blob
+// and are logged.
+func (s *service) deleteObjectBlob(ctx context.Context, object *Object) {
+	backend, err := s.GetBackend(object.StorageBackendName)
+	if err != nil {
+		slog.Warn("Failed to delete blob for deleted object", "object_id", object.ID, "backend", object.StorageBackendName, "error", err)
+		return
+	}
+	unlock, err := s.lockBlob(ctx, object.StorageBackendName, object.ObjectKey)
+	if err != nil {
+		slog.Warn("Failed to lock blob for deleted object, blob kept", "object_id", object.ID, "key", object.ObjectKey, "error", err)
+		return
+	}
+	defer unlock()
+	if s.blobShared(ctx, object) {
+		return
+	}
+	if err := backend.Delete(ctx, object.ObjectKey); err != nil {
+		slog.Warn("Failed to delete blob for deleted object", "object_id", object.ID, "backend", object.StorageBackendName, "key", object.ObjectKey, "error", err)
+	}
+}
+
 func (s *service) ListContent(ctx context.Context, req ListContentRequest) ([]*Content, error) {
-	return s.repository.ListContent(ctx, req.OwnerID, req.TenantID)
+	s.applyOwnerDefaults(&req.OwnerID, &req.TenantID)
+	if req.CreatedAfter == nil && req.CreatedBefore == nil && req.UpdatedAfter == nil && req.UpdatedBefore == nil &&
+		req.Limit == 0 && req.Offset == 0 && !req.OriginalsOnly && !req.IncludePrimaryObject {
+		return s.repository.ListContent(ctx, req.OwnerID, req.TenantID)
+	}
+	// Time-bounded, paged, originals-only and primary object listings go through the filtered query, which applies them in the repository
+	return s.repository.ListContentWithFilters(ctx, contentListFilters(req))
 }
 
 // Status management operations
 
 func (s *service) UpdateContentStatus(ctx context.Context, id uuid.UUID, newStatus ContentStatus) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
 	// Fetch current content to get old status
 	content, err := s.repository.GetContent(ctx, id)
 	if err != nil {
@@ -381,6 +687,13 @@ func (s *service) UpdateContentStatus(ctx context.Context, id uuid.UUID, newStat
 	}
 
 	oldStatus := content.Status
+	if ContentStatuses.IsRegistered(oldStatus) && !ContentStatuses.CanTransition(oldStatus, string(newStatus)) {
+		return &ContentError{
+			ContentID: id,
+			Op:        "update_status",
+			Err:       fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, oldStatus, newStatus),
+		}
+	}
 
 	// Update status and timestamp
 	content.Status = string(newStatus)
@@ -406,6 +719,9 @@ func (s *service) UpdateContentStatus(ctx context.Context, id uuid.UUID, newStat
 }
 
 func (s *service) UpdateObjectStatus(ctx context.Context, id uuid.UUID, newStatus ObjectStatus) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
 	// Fetch current object to get old status
 	object, err := s.repository.GetObject(ctx, id)
 	if err != nil {
@@ -426,6 +742,13 @@ func (s *service) UpdateObjectStatus(ctx context.Context, id uuid.UUID, newStatu
 	}
 
 	oldStatus := object.Status
+	if ObjectStatuses.IsRegistered(oldStatus) && !ObjectStatuses.CanTransition(oldStatus, string(newStatus)) {
+		return &ObjectError{
+			ObjectID: id,
+			Op:       "update_status",
+			Err:      fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, oldStatus, newStatus),
+		}
+	}
 
 	// Update status and timestamp
 	object.Status = string(newStatus)
@@ -470,8 +793,32 @@ func (s *service) GetObjectsByStatus(ctx context.Context, status ObjectStatus) (
 
 // Unified content upload operations
 
-func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (*Content, error) {
-	// Step 1: Create the content
+func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (_ *Content, err error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	s.applyOwnerDefaults(&req.OwnerID, &req.TenantID)
+	ctx, span := s.startSpan(ctx, "UploadContent")
+	defer func() { span.end(err) }()
+	req.Reader = span.countReader(req.Reader)
+	if err := s.validateContentFields(req.Name, req.Description); err != nil {
+		return nil, &ContentError{Op: "upload", Err: err}
+	}
+
+	// Check the data against the declared type before anything is created
+	dataReader, err := s.checkContentType(req.Reader, req.DocumentType)
+	if err != nil {
+		return nil, &ContentError{Op: "upload", Err: err}
+	}
+
+	// Step 1: Determine storage backend, before anything is created
+	storageBackend, backend, err := s.resolveTenantBackend(req.TenantID, req.StorageBackendName)
+	if err != nil {
+		return nil, &ContentError{Op: "upload", Err: err}
+	}
+	span.set(AttrBackend.String(storageBackend))
+
+	// Step 2: Create the content
 	now := time.Now().UTC()
 	content := &Content{
 		ID:           uuid.New(),
@@ -492,23 +839,17 @@ func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (
 			Err:       err,
 		}
 	}
-
-	// Step 2: Determine storage backend
-	storageBackend := req.StorageBackendName
-	if storageBackend == "" {
-		// Use first available backend as default
-		for name := range s.blobStores {
-			storageBackend = name
-			break
-		}
-	}
-	if storageBackend == "" {
-		return nil, fmt.Errorf("no storage backend available")
-	}
+	span.set(AttrContentID.String(content.ID.String()))
 
 	// Step 3: Create the object
 	objectID := uuid.New()
 	objectKey := fmt.Sprintf("%s/%s", content.ID.String(), objectID.String())
+	if s.keyTemplate != nil {
+		objectKey, err = s.templateObjectKey(ctx, backend, storageBackend, content, objectID, req.FileName, "")
+		if err != nil {
+			return nil, &ObjectError{ObjectID: objectID, Op: "upload_create_object", Err: err}
+		}
+	}
 
 	object := &Object{
 		ID:                 objectID,
@@ -529,32 +870,39 @@ func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (
 			Err:      err,
 		}
 	}
-
-	// Step 4: Upload the data
-	backend, err := s.GetBackend(storageBackend)
-	if err != nil {
-		return nil, &ObjectError{ObjectID: objectID, Op: "upload_get_backend", Err: err}
+	span.set(AttrObjectID.String(objectID.String()))
+	if err := s.beginUpload(ctx, object); err != nil {
+		return nil, err
 	}
+	var keepIntent bool
+	defer s.endUpload(ctx, objectID, &keepIntent)
 
+	// Step 4: Upload the data
 	// Upload with metadata if provided
+	reader, uploadDone := s.trackUpload(storageBackend, dataReader)
+	reader, etagHasher := s.hashUpload(reader)
 	if req.DocumentType != "" || req.FileName != "" {
 		uploadParams := UploadParams{
 			ObjectKey: objectKey,
 			MimeType:  req.DocumentType,
 		}
-		if err := backend.UploadWithParams(ctx, req.Reader, uploadParams); err != nil {
-			return nil, &ObjectError{ObjectID: objectID, Op: "upload_data", Err: err}
-		}
+		err = backend.UploadWithParams(ctx, reader, uploadParams)
 	} else {
 		// Simple upload without metadata
-		if err := backend.Upload(ctx, objectKey, req.Reader); err != nil {
-			return nil, &ObjectError{ObjectID: objectID, Op: "upload_data", Err: err}
-		}
+		err = backend.Upload(ctx, objectKey, reader)
+	}
+	uploadDone(err)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "upload_data", Err: err}
+	}
+	if err := s.mirrorUpload(ctx, object, req.DocumentType); err != nil {
+		return nil, err
 	}
 
 	// Step 5: Update object status
 	object.Status = string(ObjectStatusUploaded)
 	if err := s.repository.UpdateObject(ctx, object); err != nil {
+		keepIntent = true
 		return nil, &ObjectError{ObjectID: objectID, Op: "upload_update_status", Err: err}
 	}
 
@@ -569,9 +917,8 @@ func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (
 		if storageMetadata.ContentType != "" {
 			metadata["mime_type"] = storageMetadata.ContentType
 		}
-		if storageMetadata.Size > 0 {
-			metadata["file_size"] = storageMetadata.Size
-		}
+		// Zero-byte objects record their size too, telling them apart from a missing size
+		metadata["file_size"] = storageMetadata.Size
 		if storageMetadata.ETag != "" {
 			metadata["etag"] = storageMetadata.ETag
 		}
@@ -586,6 +933,7 @@ func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (
 		for k, v := range storageMetadata.Metadata {
 			metadata[k] = v
 		}
+		s.preserveReplicas(ctx, objectID, metadata)
 
 		objectMetadata := &ObjectMetadata{
 			ObjectID:  objectID,
@@ -600,6 +948,8 @@ func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (
 			// Log warning but don't fail - object was uploaded successfully
 			slog.Warn("Failed to set object metadata", "object_id", objectID, "error", err)
 		}
+		s.storeContentETag(ctx, objectID, etagHasher)
+		s.addressByContent(ctx, object, "")
 	}
 
 	// Step 6: Create content metadata if provided
@@ -627,6 +977,8 @@ func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (
 		}
 	}
 
+	s.enrichContent(ctx, object, req.DocumentType)
+
 	// Step 7: Update content status to uploaded
 	content.Status = string(ContentStatusUploaded)
 	content.UpdatedAt = time.Now().UTC()
@@ -634,6 +986,7 @@ func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (
 		// Log warning but don't fail - content was uploaded successfully
 		slog.Warn("Failed to update content status to uploaded", "content_id", content.ID, "error", err)
 	}
+	s.dispatchDerivations(ctx, content.ID)
 
 	// Fire event
 	if s.eventSink != nil {
@@ -646,7 +999,15 @@ func (s *service) UploadContent(ctx context.Context, req UploadContentRequest) (
 	return content, nil
 }
 
-func (s *service) UploadDerivedContent(ctx context.Context, req UploadDerivedContentRequest) (*Content, error) {
+func (s *service) UploadDerivedContent(ctx context.Context, req UploadDerivedContentRequest) (_ *Content, err error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	s.applyOwnerDefaults(&req.OwnerID, &req.TenantID)
+	ctx, span := s.startSpan(ctx, "UploadDerivedContent", AttrParentID.String(req.ParentID.String()))
+	defer func() { span.end(err) }()
+	req.Reader = span.countReader(req.Reader)
+
 	// Step 1: Verify parent content exists and validate status
 	parentContent, err := s.repository.GetContent(ctx, req.ParentID)
 	if err != nil {
@@ -667,11 +1028,46 @@ func (s *service) UploadDerivedContent(ctx context.Context, req UploadDerivedCon
 		}
 	}
 
+	// Check derivation depth limit
+	if err := s.checkDerivationDepth(ctx, req.ParentID, "upload_derived"); err != nil {
+		return nil, err
+	}
+
 	// Step 2: Infer derivation_type from variant if missing
 	derivationType := req.DerivationType
 	if derivationType == "" && req.Variant != "" {
 		derivationType = DerivationTypeFromVariant(req.Variant)
 	}
+	span.set(AttrDerivationType.String(derivationType), AttrVariant.String(req.Variant))
+	if err := s.validateDerivation(derivationType, req.Variant); err != nil {
+		return nil, &ContentError{ContentID: req.ParentID, Op: "upload_derived", Err: err}
+	}
+
+	// Step 2.5: Determine storage backend, before the derived content is created
+	storageBackend, backend, err := s.resolveTenantBackend(req.TenantID, req.StorageBackendName)
+	if err != nil {
+		return nil, &ContentError{ContentID: req.ParentID, Op: "upload_derived", Err: err}
+	}
+	span.set(AttrBackend.String(storageBackend))
+
+	// Persist the variant as CreateDerivedContent does, so both keep one derived content per
+	// parent and variant
+	variant := req.Variant
+	if variant == "" {
+		variant = derivationType
+	}
+	variant = string(NormalizeVariant(variant))
+
+	// A parent has one derived content per variant; check before anything is created
+	if variant != "" {
+		existing, err := s.existingDerivedContent(ctx, req.ParentID, variant)
+		if err != nil {
+			return nil, &ContentError{ContentID: req.ParentID, Op: "upload_derived", Err: err}
+		}
+		if existing != nil {
+			return nil, &ContentError{ContentID: req.ParentID, Op: "upload_derived", Err: fmt.Errorf("%w: %s", ErrVariantExists, variant)}
+		}
+	}
 
 	// Step 3: Create derived content
 	now := time.Now().UTC()
@@ -692,17 +1088,19 @@ func (s *service) UploadDerivedContent(ctx context.Context, req UploadDerivedCon
 			Err:       err,
 		}
 	}
+	span.set(AttrContentID.String(content.ID.String()))
 
 	// Step 4: Create derived content relationship
 	_, err = s.repository.CreateDerivedContentRelationship(ctx, CreateDerivedContentParams{
 		ParentID:           req.ParentID,
 		DerivedContentID:   content.ID,
 		DerivationType:     derivationType,
-		Variant:            req.Variant,
+		Variant:            variant,
 		DerivationParams:   req.Metadata,
 		ProcessingMetadata: nil,
 	})
 	if err != nil {
+		s.discardDuplicateVariant(ctx, content.ID, err)
 		return nil, &ContentError{
 			ContentID: content.ID,
 			Op:        "upload_derived_create",
@@ -710,28 +1108,17 @@ func (s *service) UploadDerivedContent(ctx context.Context, req UploadDerivedCon
 		}
 	}
 
-	// Step 5: Determine storage backend
-	storageBackend := req.StorageBackendName
-	if storageBackend == "" {
-		// Use first available backend as default
-		for name := range s.blobStores {
-			storageBackend = name
-			break
-		}
-	}
-	if storageBackend == "" {
-		return nil, &ContentError{
-			ContentID: content.ID,
-			Op:        "upload_derived_create",
-			Err:       ErrNoStorageBackend,
-		}
-	}
-
-	// Step 6: Create the object
+	// Step 5: Create the object
 	objectID := uuid.New()
 
 	// Generate object key using the configured generator
 	objectKey := s.generateDerivedObjectKey(content.ID, objectID, req.ParentID, derivationType, req.Variant, content)
+	if s.keyTemplate != nil {
+		objectKey, err = s.templateObjectKey(ctx, backend, storageBackend, content, objectID, req.FileName, req.Variant)
+		if err != nil {
+			return nil, &ObjectError{ObjectID: objectID, Op: "upload_derived_create_object", Err: err}
+		}
+	}
 
 	object := &Object{
 		ID:                 objectID,
@@ -752,8 +1139,14 @@ func (s *service) UploadDerivedContent(ctx context.Context, req UploadDerivedCon
 			Err:      err,
 		}
 	}
+	span.set(AttrObjectID.String(objectID.String()))
+	if err := s.beginUpload(ctx, object); err != nil {
+		return nil, err
+	}
+	var keepIntent bool
+	defer s.endUpload(ctx, objectID, &keepIntent)
 
-	// Step 7: Create object metadata
+	// Step 6: Create object metadata
 	objectMetadata := &ObjectMetadata{
 		ObjectID:  objectID,
 		CreatedAt: now,
@@ -767,30 +1160,36 @@ func (s *service) UploadDerivedContent(ctx context.Context, req UploadDerivedCon
 		}
 	}
 
-	// Step 8: Upload the data
-	backend, err := s.GetBackend(storageBackend)
-	if err != nil {
-		return nil, &ObjectError{ObjectID: objectID, Op: "upload_derived_get_backend", Err: err}
-	}
-
+	// Step 7: Upload the data
 	// Simple upload for derived content
-	if err := backend.Upload(ctx, objectKey, req.Reader); err != nil {
+	reader, uploadDone := s.trackUpload(storageBackend, req.Reader)
+	reader, etagHasher := s.hashUpload(reader)
+	err = backend.Upload(ctx, objectKey, reader)
+	uploadDone(err)
+	if err != nil {
 		return nil, &ObjectError{ObjectID: objectID, Op: "upload_derived_data", Err: err}
 	}
+	if err := s.mirrorUpload(ctx, object, ""); err != nil {
+		return nil, err
+	}
 
-	// Step 9: Update object status
+	// Step 8: Update object status
 	object.Status = string(ObjectStatusUploaded)
 	if err := s.repository.UpdateObject(ctx, object); err != nil {
+		keepIntent = true
 		return nil, &ObjectError{ObjectID: objectID, Op: "upload_derived_update_status", Err: err}
 	}
 
-	// Step 10: Update object metadata
+	// Step 9: Update object metadata
 	object_metadata, err := s.updateObjectFromStorage(ctx, objectID)
 	if err != nil {
 		// Log warning but don't fail - object was uploaded successfully
+	} else {
+		s.storeContentETag(ctx, objectID, etagHasher)
+		s.addressByContent(ctx, object, "")
 	}
 
-	// Step 11: Create content metadata if provided
+	// Step 10: Create content metadata if provided
 	if req.FileName != "" || len(req.Tags) > 0 {
 		metadata := &ContentMetadata{
 			ContentID: content.ID,
@@ -814,7 +1213,7 @@ func (s *service) UploadDerivedContent(ctx context.Context, req UploadDerivedCon
 		}
 	}
 
-	// Step 12: Update content status to processed
+	// Step 11: Update content status to processed
 	// Derived content is set to "processed" (not "uploaded") because derived content
 	// IS the output of processing - once uploaded, it's immediately ready to serve.
 	// Original content uses "uploaded" status, derived content uses "processed" status.
@@ -838,7 +1237,14 @@ func (s *service) UploadDerivedContent(ctx context.Context, req UploadDerivedCon
 	return content, nil
 }
 
-func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectForContentRequest) (*Object, error) {
+func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectForContentRequest) (_ *Object, err error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	ctx, span := s.startSpan(ctx, "UploadObjectForContent", AttrContentID.String(req.ContentID.String()))
+	defer func() { span.end(err) }()
+	req.Reader = span.countReader(req.Reader)
+
 	// Step 1: Verify content exists
 	content, err := s.repository.GetContent(ctx, req.ContentID)
 	if err != nil {
@@ -849,22 +1255,21 @@ func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectFo
 		}
 	}
 
-	// Step 2: Determine storage backend
-	storageBackend := req.StorageBackendName
-	if storageBackend == "" {
-		// Use first available backend as default
-		for name := range s.blobStores {
-			storageBackend = name
-			break
-		}
+	dataReader, err := s.checkContentType(req.Reader, content.DocumentType)
+	if err != nil {
+		return nil, &ContentError{ContentID: req.ContentID, Op: "upload_object", Err: err}
 	}
-	if storageBackend == "" {
+
+	// Step 2: Determine storage backend
+	storageBackend, backend, err := s.resolveTenantBackend(content.TenantID, req.StorageBackendName)
+	if err != nil {
 		return nil, &ContentError{
 			ContentID: req.ContentID,
-			Op:        "upload_object_get_content",
-			Err:       ErrNoStorageBackend,
+			Op:        "upload_object",
+			Err:       err,
 		}
 	}
+	span.set(AttrBackend.String(storageBackend))
 
 	// Get content metadata for filename
 	var contentMetadata *ContentMetadata
@@ -877,6 +1282,7 @@ func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectFo
 	// Step 3: Create the object
 	now := time.Now().UTC()
 	objectID := uuid.New()
+	span.set(AttrObjectID.String(objectID.String()))
 
 	// Generate object key using the configured generator
 	var objectKey string
@@ -893,6 +1299,16 @@ func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectFo
 		// For original content, use standard key generation with metadata
 		objectKey = s.generateObjectKey(req.ContentID, objectID, contentMetadata)
 	}
+	if s.keyTemplate != nil {
+		fileName := req.FileName
+		if fileName == "" && contentMetadata != nil {
+			fileName = contentMetadata.FileName
+		}
+		objectKey, err = s.templateObjectKey(ctx, backend, storageBackend, content, objectID, fileName, "")
+		if err != nil {
+			return nil, &ObjectError{ObjectID: objectID, Op: "upload_object_create", Err: err}
+		}
+	}
 
 	object := &Object{
 		ID:                 objectID,
@@ -921,6 +1337,11 @@ func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectFo
 			Err:      err,
 		}
 	}
+	if err := s.beginUpload(ctx, object); err != nil {
+		return nil, err
+	}
+	var keepIntent bool
+	defer s.endUpload(ctx, objectID, &keepIntent)
 
 	// Step 4: Create object metadata
 	objectMetadata := &ObjectMetadata{
@@ -938,30 +1359,31 @@ func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectFo
 	}
 
 	// Step 5: Upload the data
-	backend, err := s.GetBackend(storageBackend)
-	if err != nil {
-		return nil, &ObjectError{ObjectID: objectID, Op: "upload_object_get_backend", Err: err}
-	}
-
 	// Upload with metadata if provided
+	reader, uploadDone := s.trackUpload(storageBackend, dataReader)
+	reader, etagHasher := s.hashUpload(reader)
 	if req.MimeType != "" {
 		uploadParams := UploadParams{
 			ObjectKey: objectKey,
 			MimeType:  req.MimeType,
 		}
-		if err := backend.UploadWithParams(ctx, req.Reader, uploadParams); err != nil {
-			return nil, &ObjectError{ObjectID: objectID, Op: "upload_object_data", Err: err}
-		}
+		err = backend.UploadWithParams(ctx, reader, uploadParams)
 	} else {
 		// Simple upload without metadata
-		if err := backend.Upload(ctx, objectKey, req.Reader); err != nil {
-			return nil, &ObjectError{ObjectID: objectID, Op: "upload_object_data", Err: err}
-		}
+		err = backend.Upload(ctx, objectKey, reader)
+	}
+	uploadDone(err)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "upload_object_data", Err: err}
+	}
+	if err := s.mirrorUpload(ctx, object, req.MimeType); err != nil {
+		return nil, err
 	}
 
 	// Step 6: Update object status to uploaded
 	object.Status = string(ObjectStatusUploaded)
 	if err := s.repository.UpdateObject(ctx, object); err != nil {
+		keepIntent = true
 		return nil, &ObjectError{ObjectID: objectID, Op: "upload_object_update_status", Err: err}
 	}
 
@@ -969,6 +1391,9 @@ func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectFo
 	object_metadata, err := s.updateObjectFromStorage(ctx, objectID)
 	if err != nil {
 		// Log warning but don't fail - object was uploaded successfully
+	} else {
+		s.storeContentETag(ctx, objectID, etagHasher)
+		s.addressByContent(ctx, object, "")
 	}
 
 	// Step 8: Update content status to uploaded for original content
@@ -984,6 +1409,8 @@ func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectFo
 		// Log warning but don't fail - object was uploaded successfully
 		slog.Warn("Failed to update object metadata from storage", "content_id", content.ID, "error", err)
 	}
+	s.enrichContent(ctx, object, req.MimeType)
+	s.dispatchDerivations(ctx, object.ContentID)
 
 	// Fire event
 	if s.eventSink != nil {
@@ -997,76 +1424,18 @@ func (s *service) UploadObjectForContent(ctx context.Context, req UploadObjectFo
 }
 
 func (s *service) DownloadContent(ctx context.Context, contentID uuid.UUID) (io.ReadCloser, error) {
-	// Get content to validate status
-	content, err := s.repository.GetContent(ctx, contentID)
-	if err != nil {
-		return nil, &ContentError{
-			ContentID: contentID,
-			Op:        "download_get_content",
-			Err:       err,
-		}
-	}
-
-	// Validate content status for download
-	contentStatus := ContentStatus(content.Status)
-	if ok, statusErr := canDownloadContent(contentStatus); !ok {
-		return nil, &ContentError{
-			ContentID: contentID,
-			Op:        "download",
-			Err:       statusErr,
-		}
-	}
-
-	// Get objects for this content
-	objects, err := s.repository.GetObjectsByContentID(ctx, contentID)
-	if err != nil {
-		return nil, &ContentError{
-			ContentID: contentID,
-			Op:        "download_get_objects",
-			Err:       err,
-		}
-	}
-
-	if len(objects) == 0 {
-		return nil, &ContentError{
-			ContentID: contentID,
-			Op:        "download",
-			Err:       ErrNoObjectsFound,
-		}
-	}
-
-	// Use the first uploaded object
-	var targetObject *Object
-	for _, obj := range objects {
-		if obj.Status == string(ObjectStatusUploaded) {
-			targetObject = obj
-			break
-		}
-	}
-
-	if targetObject == nil {
-		return nil, &ContentError{
-			ContentID: contentID,
-			Op:        "download",
-			Err:       ErrNoUploadedObjects,
-		}
-	}
-
-	// Download from storage
-	backend, err := s.GetBackend(targetObject.StorageBackendName)
-	if err != nil {
-		return nil, &ObjectError{ObjectID: targetObject.ID, Op: "download_get_backend", Err: err}
-	}
-
-	return backend.Download(ctx, targetObject.ObjectKey)
+	reader, _, err := s.DownloadContentWithSource(ctx, contentID)
+	return reader, err
 }
 
 // Content metadata operations
 
 func (s *service) SetContentMetadata(ctx context.Context, req SetContentMetadataRequest) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
 	// Verify content exists
-	_, err := s.repository.GetContent(ctx, req.ContentID)
-	if err != nil {
+	if err := s.requireContent(ctx, req.ContentID); err != nil {
 		return &ContentError{
 			ContentID: req.ContentID,
 			Op:        "set_metadata",
@@ -1125,14 +1494,116 @@ func (s *service) GetContentMetadata(ctx context.Context, contentID uuid.UUID) (
 	return s.repository.GetContentMetadata(ctx, contentID)
 }
 
+// GetContentMetadataHistory returns every recorded version of the content metadata, oldest
+// first. Each metadata write, including the updates made during uploads, adds a version
+// carrying the actor from the context.
+func (s *service) GetContentMetadataHistory(ctx context.Context, contentID uuid.UUID) ([]*ContentMetadataVersion, error) {
+	history, err := s.repository.ListContentMetadataHistory(ctx, contentID)
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "get_metadata_history", Err: err}
+	}
+	return history, nil
+}
+
+// expectedDerivationsKey is the content metadata key holding the variants a parent expects
+const expectedDerivationsKey = "expected_derivations"
+
+// SetExpectedDerivations records the derivation variants (e.g., "thumbnail_256") that must be
+// completed before GetContentDetails reports the content as ready. Passing no variants clears
+// the expectation. Other content metadata is preserved.
+func (s *service) SetExpectedDerivations(ctx context.Context, contentID uuid.UUID, variants ...string) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	if err := s.requireContent(ctx, contentID); err != nil {
+		return &ContentError{
+			ContentID: contentID,
+			Op:        "set_expected_derivations",
+			Err:       ErrContentNotFound,
+		}
+	}
+
+	now := time.Now().UTC()
+	metadata, err := s.repository.GetContentMetadata(ctx, contentID)
+	if err != nil || metadata == nil {
+		metadata = &ContentMetadata{
+			ContentID: contentID,
+			CreatedAt: now,
+		}
+	}
+	if metadata.Metadata == nil {
+		metadata.Metadata = make(map[string]interface{})
+	}
+
+	if len(variants) == 0 {
+		delete(metadata.Metadata, expectedDerivationsKey)
+	} else {
+		normalized := make([]string, 0, len(variants))
+		for _, v := range variants {
+			normalized = append(normalized, string(NormalizeVariant(v)))
+		}
+		metadata.Metadata[expectedDerivationsKey] = normalized
+	}
+	metadata.UpdatedAt = now
+
+	return s.repository.SetContentMetadata(ctx, metadata)
+}
+
+// expectedDerivationsFromMetadata reads expected derivation variants from content metadata.
+// Handles both []string (in-memory) and []interface{} (JSON decoded) representations.
+func expectedDerivationsFromMetadata(metadata *ContentMetadata) []string {
+	if metadata == nil || metadata.Metadata == nil {
+		return nil
+	}
+	switch v := metadata.Metadata[expectedDerivationsKey].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// applyExpectedDerivations reports expected variants that have not completed and
+// clears Ready when any are missing.
+func applyExpectedDerivations(details *ContentDetails, expected []string, completedVariants map[string]bool) {
+	for _, variant := range expected {
+		if !completedVariants[string(NormalizeVariant(variant))] {
+			details.MissingDerivations = append(details.MissingDerivations, variant)
+		}
+	}
+	if len(details.MissingDerivations) > 0 {
+		details.Ready = false
+	}
+}
+
 // Object operations
 
 func (s *service) CreateObject(ctx context.Context, req CreateObjectRequest) (*Object, error) {
-	// Verify storage backend exists
-	_, err := s.GetBackend(req.StorageBackendName)
-	if err != nil {
+	if err := s.checkWritable(); err != nil {
 		return nil, err
 	}
+	// Verify storage backend exists; without one the content's tenant picks it
+	var backend BlobStore
+	var err error
+	if req.StorageBackendName == "" {
+		content, err := s.repository.GetContent(ctx, req.ContentID)
+		if err != nil {
+			return nil, &ContentError{ContentID: req.ContentID, Op: "create_object", Err: err}
+		}
+		req.StorageBackendName, backend, err = s.resolveTenantBackend(content.TenantID, "")
+		if err != nil {
+			return nil, &ContentError{ContentID: req.ContentID, Op: "create_object", Err: err}
+		}
+	} else if backend, err = s.GetBackend(req.StorageBackendName); err != nil {
+		return nil, &ContentError{ContentID: req.ContentID, Op: "create_object", Err: err}
+	}
 
 	// Get content metadata (optional)
 	var contentMetadata *ContentMetadata
@@ -1147,8 +1618,27 @@ func (s *service) CreateObject(ctx context.Context, req CreateObjectRequest) (*O
 
 	// Generate object key if not provided
 	objectKey := req.ObjectKey
-	if objectKey == "" {
+	if objectKey == "" && s.keyTemplate != nil {
+		content, err := s.repository.GetContent(ctx, req.ContentID)
+		if err != nil {
+			return nil, &ContentError{ContentID: req.ContentID, Op: "create_object", Err: err}
+		}
+		fileName := req.FileName
+		if contentMetadata != nil && contentMetadata.FileName != "" {
+			fileName = contentMetadata.FileName
+		}
+		objectKey, err = s.templateObjectKey(ctx, backend, req.StorageBackendName, content, objectID, fileName, "")
+		if err != nil {
+			return nil, &ObjectError{ObjectID: objectID, Op: "create", Err: err}
+		}
+	} else if objectKey == "" {
 		objectKey = s.generateObjectKey(req.ContentID, objectID, contentMetadata)
+	} else {
+		objectKey = s.sanitizeObjectKey(objectKey)
+		objectKey, err = s.resolveObjectKeyCollision(ctx, backend, req.StorageBackendName, objectKey, req.CollisionPolicy)
+		if err != nil {
+			return nil, &ObjectError{ObjectID: objectID, Op: "create", Err: err}
+		}
 	}
 
 	// Persist with content metadata if file name exists
@@ -1174,6 +1664,9 @@ func (s *service) CreateObject(ctx context.Context, req CreateObjectRequest) (*O
 		object.ObjectType = contentMetadata.MimeType
 		object.FileName = contentMetadata.FileName
 	}
+	if s.keySanitizer != nil {
+		object.FileName = objectkey.SanitizeDisplayName(object.FileName)
+	}
 
 	if err := s.repository.CreateObject(ctx, object); err != nil {
 		return nil, &ObjectError{
@@ -1193,6 +1686,9 @@ func (s *service) CreateObject(ctx context.Context, req CreateObjectRequest) (*O
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	if s.normalizeKeyCase && req.ObjectKey != "" && req.ObjectKey != object.ObjectKey {
+		objectMetadata.Metadata[MetaOriginalObjectKey] = req.ObjectKey
+	}
 	if err := s.repository.SetObjectMetadata(ctx, objectMetadata); err != nil {
 		return nil, &ObjectError{
 			ObjectID: objectID,
@@ -1216,11 +1712,52 @@ func (s *service) GetObject(ctx context.Context, id uuid.UUID) (*Object, error)
 	return s.repository.GetObject(ctx, id)
 }
 
+func (s *service) ObjectExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.repository.ObjectExists(ctx, id)
+}
+
+// requireObject fails with ErrObjectNotFound unless the object exists
+func (s *service) requireObject(ctx context.Context, id uuid.UUID) error {
+	exists, err := s.repository.ObjectExists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrObjectNotFound
+	}
+	return nil
+}
+
 func (s *service) GetObjectsByContentID(ctx context.Context, contentID uuid.UUID) ([]*Object, error) {
 	return s.repository.GetObjectsByContentID(ctx, contentID)
 }
 
+// ListObjects returns the objects matching the request, filtered in the repository
+func (s *service) ListObjects(ctx context.Context, req ListObjectsRequest) ([]*Object, error) {
+	filters := ObjectListFilters{}
+	if req.ContentID != uuid.Nil {
+		filters.ContentID = &req.ContentID
+	}
+	if req.Status != "" {
+		status := string(req.Status)
+		filters.Status = &status
+	}
+	if req.Backend != "" {
+		filters.StorageBackendName = &req.Backend
+	}
+	if req.Limit > 0 {
+		filters.Limit = &req.Limit
+	}
+	if req.Offset > 0 {
+		filters.Offset = &req.Offset
+	}
+	return s.repository.ListObjects(ctx, filters)
+}
+
 func (s *service) UpdateObject(ctx context.Context, object *Object) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
 	object.UpdatedAt = time.Now().UTC()
 
 	if err := s.repository.UpdateObject(ctx, object); err != nil {
@@ -1230,11 +1767,28 @@ func (s *service) UpdateObject(ctx context.Context, object *Object) error {
 			Err:      err,
 		}
 	}
+	s.invalidateObjectMeta(object.ID)
 
 	return nil
 }
 
 func (s *service) DeleteObject(ctx context.Context, id uuid.UUID) error {
+	return s.DeleteObjectWithOptions(ctx, id, DeleteObjectOptions{})
+}
+
+func (s *service) DeleteObjectWithOptions(ctx context.Context, id uuid.UUID, opts DeleteObjectOptions) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	// Look up the object before it is soft-deleted so its blob can be removed afterwards
+	object, err := s.repository.GetObject(ctx, id)
+	if err != nil {
+		return &ObjectError{
+			ObjectID: id,
+			Op:       "delete_get_object",
+			Err:      err,
+		}
+	}
 	if err := s.repository.DeleteObject(ctx, id); err != nil {
 		return &ObjectError{
 			ObjectID: id,
@@ -1242,6 +1796,10 @@ func (s *service) DeleteObject(ctx context.Context, id uuid.UUID) error {
 			Err:      err,
 		}
 	}
+	s.invalidateObjectMeta(id)
+	if !opts.KeepBlob {
+		s.deleteObjectBlob(ctx, object)
+	}
 
 	// Fire event
 	if s.eventSink != nil {
@@ -1256,11 +1814,19 @@ func (s *service) DeleteObject(ctx context.Context, id uuid.UUID) error {
 
 // Object upload/download operations
 
-func (s *service) UploadObject(ctx context.Context, req UploadObjectRequest) error {
+func (s *service) UploadObject(ctx context.Context, req UploadObjectRequest) (err error) {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	ctx, span := s.startSpan(ctx, "UploadObject", AttrObjectID.String(req.ObjectID.String()))
+	defer func() { span.end(err) }()
+	req.Reader = span.countReader(req.Reader)
+
 	object, err := s.repository.GetObject(ctx, req.ObjectID)
 	if err != nil {
 		return &ObjectError{ObjectID: req.ObjectID, Op: "upload", Err: err}
 	}
+	span.set(AttrContentID.String(object.ContentID.String()), AttrBackend.String(object.StorageBackendName))
 
 	// Get the backend implementation
 	backend, err := s.GetBackend(object.StorageBackendName)
@@ -1268,15 +1834,97 @@ func (s *service) UploadObject(ctx context.Context, req UploadObjectRequest) err
 		return &ObjectError{ObjectID: req.ObjectID, Op: "upload", Err: err}
 	}
 
+	dataReader, err := s.checkContentTypeFor(ctx, object.ContentID, req.Reader)
+	if err != nil {
+		return &ObjectError{ObjectID: req.ObjectID, Op: "upload", Err: err}
+	}
+
+	// A conditional upload holds the object's lock until its new ETag is stored
+	var backendETag string
+	if req.IfMatch != "" {
+		unlock := s.objectLocks.lock(object.ID)
+		defer unlock()
+		if backendETag, err = s.checkIfMatch(ctx, object, req.IfMatch); err != nil {
+			return err
+		}
+	}
+
+	if err := s.beginUpload(ctx, object); err != nil {
+		return err
+	}
+	var keepIntent bool
+	defer s.endUpload(ctx, object.ID, &keepIntent)
+
+	// Data stored by content may be shared, so new data is written to the staging key
+	previousKey := s.stageUpload(object)
+	if previousKey != "" {
+		backendETag = ""
+	}
+
+	// Periodically record bytes_written so GetUploadProgress can report long uploads
+	reader, uploadDone := s.trackUpload(object.StorageBackendName, dataReader)
+	reader, etagHasher := s.hashUpload(reader)
+	stopProgress := func() {}
+	if s.uploadProgressInterval > 0 {
+		var counter *countingReader
+		reader, counter = countUpload(reader)
+		stopProgress = s.trackUploadProgress(ctx, object.ID, counter, req.SizeBytes)
+	}
+
 	// Upload the object with or without metadata
-	if req.MimeType != "" {
+	err = s.uploadToBackend(ctx, backend, object, reader, req.MimeType, backendETag)
+	stopProgress()
+	uploadDone(err)
+	s.invalidateObjectMeta(object.ID)
+	if errors.Is(err, ErrPreconditionFailed) {
+		return &ObjectError{ObjectID: req.ObjectID, Op: "upload", Err: err}
+	}
+	if err != nil {
+		return err
+	}
+	if previousKey != "" {
+		if err := s.repository.UpdateObject(ctx, object); err != nil {
+			keepIntent = true
+			return &ObjectError{ObjectID: req.ObjectID, Op: "upload", Err: err}
+		}
+	}
+	if err := s.mirrorUpload(ctx, object, req.MimeType); err != nil {
+		return err
+	}
+
+	// Update object metadata from storage; this also marks the object uploaded
+	if _, err := s.updateObjectFromStorage(ctx, req.ObjectID); err != nil {
+		keepIntent = true
+		return err
+	}
+	s.storeContentETag(ctx, req.ObjectID, etagHasher)
+	s.addressByContent(ctx, object, previousKey)
+	s.enrichContent(ctx, object, req.MimeType)
+	s.dispatchDerivations(ctx, object.ContentID)
+
+	// Fire event
+	if s.eventSink != nil {
+		if err := s.eventSink.ObjectUploaded(ctx, object); err != nil {
+			// Log error but don't fail the operation
+			slog.Error("Failed to emit ObjectUploaded event", "object_id", object.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// uploadToBackend writes reader to the object's key, passing the mime type when known and
+// the backend ETag the stored object must still have for a conditional upload
+func (s *service) uploadToBackend(ctx context.Context, backend BlobStore, object *Object, reader io.Reader, mimeType, ifMatch string) error {
+	if mimeType != "" || ifMatch != "" {
 		// Upload with metadata
 		uploadParams := UploadParams{
 			ObjectKey: object.ObjectKey,
-			MimeType:  req.MimeType,
+			MimeType:  mimeType,
+			IfMatch:   ifMatch,
 		}
 
-		if err := backend.UploadWithParams(ctx, req.Reader, uploadParams); err != nil {
+		if err := backend.UploadWithParams(ctx, reader, uploadParams); err != nil {
 			return &StorageError{
 				Backend: object.StorageBackendName,
 				Key:     object.ObjectKey,
@@ -1286,7 +1934,7 @@ func (s *service) UploadObject(ctx context.Context, req UploadObjectRequest) err
 		}
 	} else {
 		// Simple upload without metadata
-		if err := backend.Upload(ctx, object.ObjectKey, req.Reader); err != nil {
+		if err := backend.Upload(ctx, object.ObjectKey, reader); err != nil {
 			return &StorageError{
 				Backend: object.StorageBackendName,
 				Key:     object.ObjectKey,
@@ -1295,33 +1943,78 @@ func (s *service) UploadObject(ctx context.Context, req UploadObjectRequest) err
 			}
 		}
 	}
+	return nil
+}
 
-	// Update object metadata from storage
-	if _, err := s.updateObjectFromStorage(ctx, req.ObjectID); err != nil {
-		return err
+func (s *service) DownloadObject(ctx context.Context, id uuid.UUID) (_ io.ReadCloser, err error) {
+	ctx, span := s.startSpan(ctx, "DownloadObject", AttrObjectID.String(id.String()))
+	defer func() { span.end(err) }()
+
+	object, backend, err := s.downloadableObject(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	span.set(AttrContentID.String(object.ContentID.String()), AttrBackend.String(object.StorageBackendName))
 
-	// Fire event
-	if s.eventSink != nil {
-		if err := s.eventSink.ObjectUploaded(ctx, object); err != nil {
-			// Log error but don't fail the operation
-			slog.Error("Failed to emit ObjectUploaded event", "object_id", object.ID, "error", err)
+	// Download the object
+	reader, err := backend.Download(ctx, object.ObjectKey)
+	reader = s.trackDownload(object.StorageBackendName, reader, err)
+	if err != nil {
+		return nil, &StorageError{
+			Backend: object.StorageBackendName,
+			Key:     object.ObjectKey,
+			Op:      "download",
+			Err:     err,
 		}
 	}
 
-	return nil
+	return s.throttleDownload(ctx, reader), nil
+}
+
+// DownloadObjectWithMeta downloads an object along with the storage metadata reported
+// by its backend. The metadata Size lets HTTP handlers set Content-Length so clients
+// can detect truncated transfers.
+func (s *service) DownloadObjectWithMeta(ctx context.Context, id uuid.UUID) (io.ReadCloser, *ObjectMeta, error) {
+	object, backend, err := s.downloadableObject(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta, err := s.objectMeta(ctx, backend, object)
+	if err != nil {
+		return nil, nil, &StorageError{
+			Backend: object.StorageBackendName,
+			Key:     object.ObjectKey,
+			Op:      "get_object_meta",
+			Err:     err,
+		}
+	}
+
+	reader, err := backend.Download(ctx, object.ObjectKey)
+	reader = s.trackDownload(object.StorageBackendName, reader, err)
+	if err != nil {
+		return nil, nil, &StorageError{
+			Backend: object.StorageBackendName,
+			Key:     object.ObjectKey,
+			Op:      "download",
+			Err:     err,
+		}
+	}
+
+	return s.throttleDownload(ctx, reader), meta, nil
 }
 
-func (s *service) DownloadObject(ctx context.Context, id uuid.UUID) (io.ReadCloser, error) {
+// downloadableObject loads an object, checks that its status allows download and resolves its backend.
+func (s *service) downloadableObject(ctx context.Context, id uuid.UUID) (*Object, BlobStore, error) {
 	object, err := s.repository.GetObject(ctx, id)
 	if err != nil {
-		return nil, &ObjectError{ObjectID: id, Op: "download", Err: err}
+		return nil, nil, &ObjectError{ObjectID: id, Op: "download", Err: err}
 	}
 
 	// Validate object status for download
 	objectStatus := ObjectStatus(object.Status)
 	if ok, statusErr := canDownloadObject(objectStatus); !ok {
-		return nil, &ObjectError{
+		return nil, nil, &ObjectError{
 			ObjectID: id,
 			Op:       "download",
 			Err:      statusErr,
@@ -1331,24 +2024,16 @@ func (s *service) DownloadObject(ctx context.Context, id uuid.UUID) (io.ReadClos
 	// Get the backend implementation
 	backend, err := s.GetBackend(object.StorageBackendName)
 	if err != nil {
-		return nil, &ObjectError{ObjectID: id, Op: "download", Err: err}
-	}
-
-	// Download the object
-	reader, err := backend.Download(ctx, object.ObjectKey)
-	if err != nil {
-		return nil, &StorageError{
-			Backend: object.StorageBackendName,
-			Key:     object.ObjectKey,
-			Op:      "download",
-			Err:     err,
-		}
+		return nil, nil, &ObjectError{ObjectID: id, Op: "download", Err: err}
 	}
 
-	return reader, nil
+	return object, backend, nil
 }
 
 func (s *service) GetUploadURL(ctx context.Context, id uuid.UUID) (string, error) {
+	if err := s.checkWritable(); err != nil {
+		return "", err
+	}
 	object, err := s.repository.GetObject(ctx, id)
 	if err != nil {
 		return "", &ObjectError{ObjectID: id, Op: "get_upload_url", Err: err}
@@ -1358,6 +2043,9 @@ func (s *service) GetUploadURL(ctx context.Context, id uuid.UUID) (string, error
 	if err != nil {
 		return "", &ObjectError{ObjectID: id, Op: "get_upload_url", Err: err}
 	}
+	if !GetBackendCapabilities(backend).PresignedUpload {
+		return "", &ObjectError{ObjectID: id, Op: "get_upload_url", Err: presignUnsupported(object.StorageBackendName)}
+	}
 
 	return backend.GetUploadURL(ctx, object.ObjectKey)
 }
@@ -1372,6 +2060,9 @@ func (s *service) GetDownloadURL(ctx context.Context, id uuid.UUID) (string, err
 	if err != nil {
 		return "", &ObjectError{ObjectID: id, Op: "get_download_url", Err: err}
 	}
+	if !GetBackendCapabilities(backend).PresignedDownload {
+		return "", &ObjectError{ObjectID: id, Op: "get_download_url", Err: presignUnsupported(object.StorageBackendName)}
+	}
 
 	return backend.GetDownloadURL(ctx, object.ObjectKey, object.FileName)
 }
@@ -1386,6 +2077,14 @@ func (s *service) GetPreviewURL(ctx context.Context, id uuid.UUID) (string, erro
 	if err != nil {
 		return "", &ObjectError{ObjectID: id, Op: "get_preview_url", Err: err}
 	}
+	// The backend would serve the original bytes, so transformed previews go through the
+	// URL strategy, whose content-based URLs are served by the preview handler
+	if s.previewTransformerFor(s.objectMimeType(ctx, object)) != nil {
+		return s.urlStrategy.GeneratePreviewURL(ctx, object.ContentID, object.ObjectKey, object.StorageBackendName)
+	}
+	if !GetBackendCapabilities(backend).PresignedDownload {
+		return "", &ObjectError{ObjectID: id, Op: "get_preview_url", Err: presignUnsupported(object.StorageBackendName)}
+	}
 
 	return backend.GetPreviewURL(ctx, object.ObjectKey)
 }
@@ -1464,11 +2163,7 @@ func (s *service) GetContentDetails(ctx context.Context, contentID uuid.UUID, op
 
 		// Verify if content is ready
 		if strings.ToLower(content.Status) == string(ContentStatusUploaded) {
-			if downloadURL, err := s.urlStrategy.GenerateDownloadURL(ctx, contentID, primaryObject.ObjectKey, primaryObject.StorageBackendName, &urlstrategy.URLMetadata{
-				FileName:    fileName,
-				Version:     primaryObject.Version,
-				ContentType: mimeType,
-			}); err == nil {
+			if downloadURL, err := s.urlStrategy.GenerateDownloadURL(ctx, contentID, primaryObject.ObjectKey, primaryObject.StorageBackendName, cfg.downloadURLMetadata(fileName, mimeType, primaryObject.Version)); err == nil {
 				result.Download = downloadURL
 			}
 
@@ -1478,8 +2173,9 @@ func (s *service) GetContentDetails(ctx context.Context, contentID uuid.UUID, op
 			}
 		}
 
-		// Generate upload URL if requested
-		if cfg.IncludeUploadURL {
+		// Generate upload URL if requested. Backend upload URLs are skipped for backends
+		// that cannot presign them.
+		if cfg.IncludeUploadURL && s.canIssueUploadURL(primaryObject.StorageBackendName) {
 			if uploadURL, err := s.urlStrategy.GenerateUploadURL(ctx, contentID, primaryObject.ObjectKey, primaryObject.StorageBackendName); err == nil {
 				result.Upload = uploadURL
 				// Set expiry time if upload URL was generated
@@ -1498,7 +2194,12 @@ func (s *service) GetContentDetails(ctx context.Context, contentID uuid.UUID, op
 	}
 
 	// Organize derived content URLs by type
+	completedVariants := make(map[string]bool)
 	for _, derived := range derivedContent {
+		if derived.Status == string(ContentStatusProcessed) {
+			completedVariants[string(NormalizeVariant(derived.Variant))] = true
+		}
+
 		// Extract variant without prefix (e.g., "256" from "thumbnail_256")
 		variant := derived.Variant
 		if idx := strings.LastIndex(variant, "_"); idx >= 0 {
@@ -1539,6 +2240,9 @@ func (s *service) GetContentDetails(ctx context.Context, contentID uuid.UUID, op
 		// Derived content that is not ready simply won't appear in the thumbnails/previews/transcodes maps.
 	}
 
+	// Pipelines that declared expected derivations are only ready once every expected variant completed
+	applyExpectedDerivations(result, expectedDerivationsFromMetadata(contentMetadata), completedVariants)
+
 	// Add content timestamps
 	result.CreatedAt = content.CreatedAt
 	result.UpdatedAt = content.UpdatedAt
@@ -1549,8 +2253,11 @@ func (s *service) GetContentDetails(ctx context.Context, contentID uuid.UUID, op
 // Object metadata operations
 
 func (s *service) SetObjectMetadata(ctx context.Context, objectID uuid.UUID, metadata map[string]interface{}) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
 	// Verify object exists
-	if _, err := s.repository.GetObject(ctx, objectID); err != nil {
+	if err := s.requireObject(ctx, objectID); err != nil {
 		return &ObjectError{ObjectID: objectID, Op: "set_metadata", Err: err}
 	}
 
@@ -1594,7 +2301,7 @@ func (s *service) SetObjectMetadata(ctx context.Context, objectID uuid.UUID, met
 
 func (s *service) GetObjectMetadata(ctx context.Context, objectID uuid.UUID) (map[string]interface{}, error) {
 	// Verify object exists
-	if _, err := s.repository.GetObject(ctx, objectID); err != nil {
+	if err := s.requireObject(ctx, objectID); err != nil {
 		return nil, &ObjectError{ObjectID: objectID, Op: "get_metadata", Err: err}
 	}
 
@@ -1606,7 +2313,43 @@ func (s *service) GetObjectMetadata(ctx context.Context, objectID uuid.UUID) (ma
 	return objectMetadata.Metadata, nil
 }
 
+func (s *service) GetObjectsMetadata(ctx context.Context, contentID uuid.UUID) (map[uuid.UUID]map[string]interface{}, error) {
+	if err := s.requireContent(ctx, contentID); err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "get_objects_metadata", Err: err}
+	}
+
+	objects, err := s.repository.GetObjectsByContentID(ctx, contentID)
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "get_objects_metadata", Err: err}
+	}
+	result := make(map[uuid.UUID]map[string]interface{}, len(objects))
+	if len(objects) == 0 {
+		return result, nil
+	}
+
+	objectIDs := make([]uuid.UUID, 0, len(objects))
+	for _, object := range objects {
+		objectIDs = append(objectIDs, object.ID)
+	}
+	metadataByObject, err := s.repository.GetObjectMetadataByObjectIDs(ctx, objectIDs)
+	if err != nil {
+		return nil, &ContentError{ContentID: contentID, Op: "get_objects_metadata", Err: err}
+	}
+	// Objects without stored metadata get an empty map, so every object is present
+	for _, id := range objectIDs {
+		metadata := map[string]interface{}{}
+		if objectMetadata, ok := metadataByObject[id]; ok && objectMetadata.Metadata != nil {
+			metadata = objectMetadata.Metadata
+		}
+		result[id] = metadata
+	}
+	return result, nil
+}
+
 func (s *service) UpdateObjectMetaFromStorage(ctx context.Context, objectID uuid.UUID) (*ObjectMetadata, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
 	// Get the object
 	object, err := s.repository.GetObject(ctx, objectID)
 	if err != nil {
@@ -1620,6 +2363,7 @@ func (s *service) UpdateObjectMetaFromStorage(ctx context.Context, objectID uuid
 	}
 
 	// Get object meta from storage
+	s.invalidateObjectMeta(objectID)
 	objectMeta, err := backend.GetObjectMeta(ctx, object.ObjectKey)
 	if err != nil {
 		return nil, &StorageError{
@@ -1636,6 +2380,8 @@ func (s *service) UpdateObjectMetaFromStorage(ctx context.Context, objectID uuid
 	for k, v := range objectMeta.Metadata {
 		metadata[k] = v
 	}
+	s.preserveReplicas(ctx, objectID, metadata)
+	s.preserveContentETag(ctx, objectID, objectMeta, metadata)
 
 	objectMetadata := &ObjectMetadata{
 		ObjectID:  objectID,
@@ -1661,20 +2407,117 @@ func (s *service) UpdateObjectMetaFromStorage(ctx context.Context, objectID uuid
 	return objectMetadata, nil
 }
 
+func (s *service) ConfirmUpload(ctx context.Context, objectID uuid.UUID) (*Object, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	object, err := s.repository.GetObject(ctx, objectID)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "confirm_upload", Err: err}
+	}
+
+	backend, err := s.GetBackend(object.StorageBackendName)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "confirm_upload", Err: err}
+	}
+
+	// The client claims the upload is done; make sure the blob is actually there
+	if _, err := backend.GetObjectMeta(ctx, object.ObjectKey); errors.Is(err, ErrObjectNotFound) || errors.Is(err, ErrBlobNotFound) {
+		return nil, &ObjectError{
+			ObjectID: objectID,
+			Op:       "confirm_upload",
+			Err:      fmt.Errorf("%w: %v", ErrBlobNotFound, err),
+		}
+	} else if err != nil {
+		s.stats.backendError(object.StorageBackendName)
+		return nil, &StorageError{
+			Backend: object.StorageBackendName,
+			Key:     object.ObjectKey,
+			Op:      "confirm_upload",
+			Err:     err,
+		}
+	}
+	if err := s.mirrorUpload(ctx, object, object.ObjectType); err != nil {
+		return nil, err
+	}
+
+	// Sync size, etag and mime type from storage; this also marks the object uploaded
+	if _, err := s.UpdateObjectMetaFromStorage(ctx, objectID); err != nil {
+		return nil, err
+	}
+	s.computeContentETag(ctx, object, backend)
+	s.addressByContent(ctx, object, "")
+	s.enrichContent(ctx, object, "")
+	s.dispatchDerivations(ctx, object.ContentID)
+
+	confirmed, err := s.repository.GetObject(ctx, objectID)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "confirm_upload", Err: err}
+	}
+
+	if s.eventSink != nil {
+		if oldStatus := object.Status; oldStatus != confirmed.Status {
+			if err := s.eventSink.ObjectStatusChanged(ctx, objectID, oldStatus, confirmed.Status); err != nil {
+				slog.Error("Failed to emit ObjectStatusChanged event", "object_id", objectID, "old_status", oldStatus, "new_status", confirmed.Status, "error", err)
+			}
+		}
+		if err := s.eventSink.ObjectUploaded(ctx, confirmed); err != nil {
+			// Log error but don't fail the operation
+			slog.Error("Failed to emit ObjectUploaded event", "object_id", objectID, "error", err)
+		}
+	}
+
+	return confirmed, nil
+}
+
 // Storage backend operations
 
 func (s *service) RegisterBackend(name string, backend BlobStore) {
-	s.blobStores[name] = backend
+	s.blobStores[name] = s.withStorageTimeout(name, backend)
 }
 
 func (s *service) GetBackend(name string) (BlobStore, error) {
 	backend, exists := s.blobStores[name]
 	if !exists {
-		return nil, fmt.Errorf("%w: %s", ErrStorageBackendNotFound, name)
+		return nil, &BackendNotFoundError{Name: name, Available: s.backendNames()}
 	}
 	return backend, nil
 }
 
+// resolveBackend returns the named backend, or the default backend when name is empty,
+// falling back to the first registered one. It fails with ErrNoStorageBackend when no
+// backend is registered.
+func (s *service) resolveBackend(name string) (string, BlobStore, error) {
+	if name == "" {
+		name = s.defaultBackend
+	}
+	if name == "" {
+		// Use first available backend as default
+		for registered := range s.blobStores {
+			name = registered
+			break
+		}
+	}
+	if name == "" {
+		return "", nil, ErrNoStorageBackend
+	}
+	backend, err := s.GetBackend(name)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, backend, nil
+}
+
+// backendNames returns the registered backend names in sorted order
+func (s *service) backendNames() []string {
+	names := make([]string, 0, len(s.blobStores))
+	for name := range s.blobStores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Helper methods
 
 func (s *service) generateObjectKey(contentID, objectID uuid.UUID, contentMetadata *ContentMetadata) string {
@@ -1688,7 +2531,7 @@ func (s *service) generateObjectKey(contentID, objectID uuid.UUID, contentMetada
 		}
 	}
 
-	return s.keyGenerator.GenerateKey(contentID, objectID, keyMetadata)
+	return s.sanitizeObjectKey(s.keyGenerator.GenerateKey(contentID, objectID, keyMetadata))
 }
 
 func (s *service) generateDerivedObjectKey(contentID, objectID, parentContentID uuid.UUID, derivationType, variant string, content *Content) string {
@@ -1705,7 +2548,15 @@ func (s *service) generateDerivedObjectKey(contentID, objectID, parentContentID
 		keyMetadata.OwnerID = content.OwnerID.String()
 	}
 
-	return s.keyGenerator.GenerateKey(contentID, objectID, keyMetadata)
+	return s.sanitizeObjectKey(s.keyGenerator.GenerateKey(contentID, objectID, keyMetadata))
+}
+
+// sanitizeObjectKey applies the configured key sanitizer, if any, and key case normalization
+func (s *service) sanitizeObjectKey(key string) string {
+	if s.keySanitizer == nil {
+		return s.foldKeyCase(key)
+	}
+	return s.foldKeyCase(s.keySanitizer(key))
 }
 
 func (s *service) updateObjectFromStorage(ctx context.Context, objectID uuid.UUID) (*ObjectMetadata, error) {
@@ -1751,6 +2602,11 @@ func (s *service) ListDerivedContent(ctx context.Context, options ...ListDerived
 		return nil, err
 	}
 
+	// Propagate processing state computed from the derived content status
+	for _, d := range derived {
+		populateProcessingState(d)
+	}
+
 	// Enhance with URLs, objects, and metadata if requested
 	if params.IncludeURLs || params.IncludeObjects || params.IncludeMetadata {
 		for _, d := range derived {
@@ -1765,6 +2621,188 @@ func (s *service) ListDerivedContent(ctx context.Context, options ...ListDerived
 	return derived, nil
 }
 
+// ListDerivedContentWithDetails lists derived content like ListDerivedContent but also
+// returns each child's full Content, loaded by the repository in a single query.
+// Children whose content has been deleted are omitted.
+func (s *service) ListDerivedContentWithDetails(ctx context.Context, options ...ListDerivedContentOption) ([]*DerivedContentWithDetails, error) {
+	params := ListDerivedContentParams{}
+	for _, option := range options {
+		option(&params)
+	}
+
+	items, err := s.repository.ListDerivedContentWithDetails(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		populateProcessingState(item.DerivedContent)
+	}
+
+	if params.IncludeURLs || params.IncludeObjects || params.IncludeMetadata {
+		for _, item := range items {
+			if err := s.enhanceDerivedContent(ctx, item.DerivedContent, params); err != nil {
+				fmt.Printf("Failed to enhance derived content %s: %v\n", item.ContentID, err)
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// derivationErrorMessageKey is the processing_metadata key holding a failed derivation's error message
+const derivationErrorMessageKey = "error_message"
+
+// MarkDerivationFailed marks derived content as failed and records the failure reason
+// on its derived-content relationship.
+func (s *service) MarkDerivationFailed(ctx context.Context, contentID uuid.UUID, errorMessage string) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	derived, err := s.repository.GetDerivedRelationshipByContentID(ctx, contentID)
+	if err != nil {
+		return &ContentError{
+			ContentID: contentID,
+			Op:        "mark_derivation_failed",
+			Err:       err,
+		}
+	}
+
+	processingMetadata := make(map[string]interface{}, len(derived.ProcessingMetadata)+1)
+	for k, v := range derived.ProcessingMetadata {
+		processingMetadata[k] = v
+	}
+	processingMetadata[derivationErrorMessageKey] = errorMessage
+
+	if err := s.repository.UpdateDerivedContentRelationship(ctx, UpdateDerivedContentParams{
+		DerivedContentID:   contentID,
+		ProcessingMetadata: processingMetadata,
+	}); err != nil {
+		return &ContentError{
+			ContentID: contentID,
+			Op:        "mark_derivation_failed",
+			Err:       err,
+		}
+	}
+
+	return s.UpdateContentStatus(ctx, contentID, ContentStatusFailed)
+}
+
+// populateProcessingState fills the computed ProcessingStatus and ErrorMessage fields
+func populateProcessingState(derived *DerivedContent) {
+	derived.ProcessingStatus = DerivationStatusFromContentStatus(derived.Status)
+	if derived.ProcessingStatus != DerivationStatusFailed {
+		return
+	}
+	if msg, ok := derived.ProcessingMetadata[derivationErrorMessageKey].(string); ok {
+		derived.ErrorMessage = msg
+	}
+}
+
+// Typed content relationships
+
+// LinkContent creates a typed relationship from one content to another. Linking is
+// idempotent: linking the same pair with the same type again returns the existing link.
+func (s *service) LinkContent(ctx context.Context, fromContentID, toContentID uuid.UUID, relType RelationType) (*ContentRelationship, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if !relType.IsValid() || relType == RelationTypeDerived {
+		return nil, &ContentError{
+			ContentID: fromContentID,
+			Op:        "link_content",
+			Err:       fmt.Errorf("%w: %q (use CreateDerivedContent for derivations)", ErrInvalidRelationType, relType),
+		}
+	}
+	if fromContentID == toContentID {
+		return nil, &ContentError{
+			ContentID: fromContentID,
+			Op:        "link_content",
+			Err:       fmt.Errorf("%w: content cannot be linked to itself", ErrInvalidRelationType),
+		}
+	}
+
+	for _, id := range []uuid.UUID{fromContentID, toContentID} {
+		if err := s.requireContent(ctx, id); err != nil {
+			return nil, &ContentError{ContentID: id, Op: "link_content", Err: err}
+		}
+	}
+
+	relationship := &ContentRelationship{
+		FromContentID: fromContentID,
+		ToContentID:   toContentID,
+		RelationType:  relType,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if err := s.repository.CreateContentRelationship(ctx, relationship); err != nil {
+		return nil, &ContentError{ContentID: fromContentID, Op: "link_content", Err: err}
+	}
+
+	return relationship, nil
+}
+
+// UnlinkContent removes a typed relationship created by LinkContent.
+func (s *service) UnlinkContent(ctx context.Context, fromContentID, toContentID uuid.UUID, relType RelationType) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	if !relType.IsValid() || relType == RelationTypeDerived {
+		return &ContentError{
+			ContentID: fromContentID,
+			Op:        "unlink_content",
+			Err:       fmt.Errorf("%w: %q", ErrInvalidRelationType, relType),
+		}
+	}
+
+	if err := s.repository.DeleteContentRelationship(ctx, fromContentID, toContentID, relType); err != nil {
+		return &ContentError{ContentID: fromContentID, Op: "unlink_content", Err: err}
+	}
+	return nil
+}
+
+// ListRelated lists outgoing relationships of a content. An empty relType lists all
+// types; RelationTypeDerived (or empty) includes derived children from the
+// derived-content table.
+func (s *service) ListRelated(ctx context.Context, contentID uuid.UUID, relType RelationType) ([]*ContentRelationship, error) {
+	if relType != "" && !relType.IsValid() {
+		return nil, &ContentError{
+			ContentID: contentID,
+			Op:        "list_related",
+			Err:       fmt.Errorf("%w: %q", ErrInvalidRelationType, relType),
+		}
+	}
+
+	var result []*ContentRelationship
+
+	if relType == "" || relType == RelationTypeDerived {
+		derived, err := s.repository.ListDerivedContent(ctx, ListDerivedContentParams{ParentID: &contentID})
+		if err != nil {
+			return nil, &ContentError{ContentID: contentID, Op: "list_related", Err: err}
+		}
+		for _, d := range derived {
+			result = append(result, &ContentRelationship{
+				FromContentID: d.ParentID,
+				ToContentID:   d.ContentID,
+				RelationType:  RelationTypeDerived,
+				CreatedAt:     d.CreatedAt,
+			})
+		}
+	}
+
+	if relType != RelationTypeDerived {
+		related, err := s.repository.ListContentRelationships(ctx, ListContentRelationshipsParams{
+			FromContentID: contentID,
+			RelationType:  relType,
+		})
+		if err != nil {
+			return nil, &ContentError{ContentID: contentID, Op: "list_related", Err: err}
+		}
+		result = append(result, related...)
+	}
+
+	return result, nil
+}
+
 // Helper methods for enhancement
 
 func (s *service) enhanceDerivedContent(ctx context.Context, derived *DerivedContent, params ListDerivedContentParams) error {
@@ -1983,11 +3021,7 @@ func (s *service) GetContentDetailsBatch(ctx context.Context, contentIDs []uuid.
 			// Generate URLs only for uploaded content
 			if strings.ToLower(content.Status) == string(ContentStatusUploaded) {
 				// Generate download URL
-				if downloadURL, err := s.urlStrategy.GenerateDownloadURL(ctx, contentID, primaryObject.ObjectKey, primaryObject.StorageBackendName, &urlstrategy.URLMetadata{
-					FileName:    fileName,
-					Version:     primaryObject.Version,
-					ContentType: mimeType,
-				}); err == nil {
+				if downloadURL, err := s.urlStrategy.GenerateDownloadURL(ctx, contentID, primaryObject.ObjectKey, primaryObject.StorageBackendName, cfg.downloadURLMetadata(fileName, mimeType, primaryObject.Version)); err == nil {
 					details.Download = downloadURL
 				}
 
@@ -2017,12 +3051,20 @@ func (s *service) GetContentDetailsBatch(ctx context.Context, contentIDs []uuid.
 		fmt.Printf("Failed to get derived content: %v\n", err)
 	} else {
 		// Organize derived content by parent ID
+		completedVariants := make(map[uuid.UUID]map[string]bool)
 		for _, derived := range derivedContent {
 			details, ok := resultMap[derived.ParentID]
 			if !ok {
 				continue
 			}
 
+			if derived.Status == string(ContentStatusProcessed) {
+				if completedVariants[derived.ParentID] == nil {
+					completedVariants[derived.ParentID] = make(map[string]bool)
+				}
+				completedVariants[derived.ParentID][string(NormalizeVariant(derived.Variant))] = true
+			}
+
 			// Extract variant without prefix
 			variant := derived.Variant
 			if idx := strings.LastIndex(variant, "_"); idx >= 0 {
@@ -2051,6 +3093,11 @@ func (s *service) GetContentDetailsBatch(ctx context.Context, contentIDs []uuid.
 				}
 			}
 		}
+
+		// Apply expected derivations per parent
+		for contentID, details := range resultMap {
+			applyExpectedDerivations(details, expectedDerivationsFromMetadata(metadataMap[contentID]), completedVariants[contentID])
+		}
 	}
 
 	// Build ordered result array based on input contentIDs order
@@ -2065,16 +3112,15 @@ func (s *service) GetContentDetailsBatch(ctx context.Context, contentIDs []uuid.
 }
 
 // computeDerivationDepth computes the derivation depth by recursively traversing the parent chain
-// Maximum depth is capped at 100 to prevent infinite loops
+// Maximum depth is capped at maxDerivationWalk to prevent infinite loops
 func (s *service) computeDerivationDepth(ctx context.Context, contentID uuid.UUID) int {
 	return s.computeDerivationDepthWithLimit(ctx, contentID, 0)
 }
 
 func (s *service) computeDerivationDepthWithLimit(ctx context.Context, contentID uuid.UUID, currentDepth int) int {
-	// Hard limit to prevent infinite loops (should never reach this with max depth of 5)
-	const maxSafetyDepth = 100
-	if currentDepth >= maxSafetyDepth {
-		return maxSafetyDepth
+	// Hard limit to prevent infinite loops (never reached within the configured max depth)
+	if currentDepth >= maxDerivationWalk {
+		return maxDerivationWalk
 	}
 
 	derived, err := s.repository.GetDerivedRelationshipByContentID(ctx, contentID)
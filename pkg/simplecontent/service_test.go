@@ -1,11 +1,20 @@
 package simplecontent_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,7 +23,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tendant/simple-content/pkg/simplecontent"
 	"github.com/tendant/simple-content/pkg/simplecontent/repo/memory"
+	"github.com/tendant/simple-content/pkg/simplecontent/objectkey"
+	fsstorage "github.com/tendant/simple-content/pkg/simplecontent/storage/fs"
 	memorystorage "github.com/tendant/simple-content/pkg/simplecontent/storage/memory"
+	"github.com/tendant/simple-content/pkg/simplecontent/urlstrategy"
 )
 
 func TestServiceCreation(t *testing.T) {
@@ -170,6 +182,33 @@ func TestContentOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("ListContentTimeRange", func(t *testing.T) {
+		ownerID, tenantID := uuid.New(), uuid.New()
+
+		older, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: ownerID, TenantID: tenantID, Name: "older"})
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+		cutoff := time.Now().UTC()
+		time.Sleep(5 * time.Millisecond)
+		newer, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: ownerID, TenantID: tenantID, Name: "newer"})
+		require.NoError(t, err)
+
+		contents, err := svc.ListContent(ctx, simplecontent.ListContentRequest{OwnerID: ownerID, TenantID: tenantID, CreatedAfter: &cutoff})
+		require.NoError(t, err)
+		require.Len(t, contents, 1)
+		assert.Equal(t, newer.ID, contents[0].ID)
+
+		contents, err = svc.ListContent(ctx, simplecontent.ListContentRequest{OwnerID: ownerID, TenantID: tenantID, CreatedBefore: &cutoff})
+		require.NoError(t, err)
+		require.Len(t, contents, 1)
+		assert.Equal(t, older.ID, contents[0].ID)
+
+		// Bounds never widen the listing past the owner and tenant
+		contents, err = svc.ListContent(ctx, simplecontent.ListContentRequest{OwnerID: uuid.New(), TenantID: tenantID, CreatedBefore: &cutoff})
+		require.NoError(t, err)
+		assert.Empty(t, contents)
+	})
+
 	t.Run("UpdateContent", func(t *testing.T) {
 		// Create content
 		req := simplecontent.CreateContentRequest{
@@ -393,6 +432,485 @@ func TestObjectUploadDownload(t *testing.T) {
 	})
 }
 
+func TestDownloadObjectWithMeta(t *testing.T) {
+	fsStore, err := fsstorage.New(fsstorage.Config{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+
+	backends := map[string]simplecontent.BlobStore{
+		"memory": memorystorage.New(),
+		"fs":     fsStore,
+	}
+
+	for name, store := range backends {
+		t.Run(name, func(t *testing.T) {
+			svc, err := simplecontent.New(
+				simplecontent.WithRepository(memory.New()),
+				simplecontent.WithBlobStore(name, store),
+			)
+			require.NoError(t, err)
+			storageSvc := svc.(simplecontent.StorageService)
+			ctx := context.Background()
+
+			content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+				OwnerID:  uuid.New(),
+				TenantID: uuid.New(),
+				Name:     "Download meta " + name,
+			})
+			require.NoError(t, err)
+
+			object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+				ContentID:          content.ID,
+				StorageBackendName: name,
+				Version:            1,
+			})
+			require.NoError(t, err)
+
+			testData := "payload with a known length"
+			require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+				ObjectID: object.ID,
+				Reader:   strings.NewReader(testData),
+				MimeType: "text/plain",
+			}))
+
+			reader, meta, err := storageSvc.DownloadObjectWithMeta(ctx, object.ID)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			require.NotNil(t, meta)
+			assert.Equal(t, int64(len(testData)), meta.Size)
+
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, testData, string(data))
+		})
+	}
+}
+
+// slowReader returns chunkSize bytes per Read, sleeping between reads
+type slowReader struct {
+	remaining int
+	chunkSize int
+	delay     time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	n := r.chunkSize
+	if n > r.remaining {
+		n = r.remaining
+	}
+	if n > len(p) {
+		n = len(p)
+	}
+	for i := 0; i < n; i++ {
+		p[i] = 'x'
+	}
+	r.remaining -= n
+	return n, nil
+}
+
+func TestGetUploadProgress(t *testing.T) {
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithUploadProgressInterval(5*time.Millisecond),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+	ctx := context.Background()
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Slow upload",
+	})
+	require.NoError(t, err)
+
+	object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+		ContentID:          content.ID,
+		StorageBackendName: "memory",
+		Version:            1,
+	})
+	require.NoError(t, err)
+
+	// Nothing recorded before the upload starts
+	progress, err := storageSvc.GetUploadProgress(ctx, object.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), progress.BytesWritten)
+	assert.False(t, progress.Completed)
+
+	const total = 200
+	uploadErr := make(chan error, 1)
+	go func() {
+		uploadErr <- storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID:  object.ID,
+			Reader:    &slowReader{remaining: total, chunkSize: 10, delay: 5 * time.Millisecond},
+			SizeBytes: total,
+		})
+	}()
+
+	var observed []int64
+	for done := false; !done; {
+		select {
+		case err := <-uploadErr:
+			require.NoError(t, err)
+			done = true
+		case <-time.After(3 * time.Millisecond):
+			p, err := storageSvc.GetUploadProgress(ctx, object.ID)
+			require.NoError(t, err)
+			if !p.Completed && p.BytesWritten > 0 {
+				assert.Equal(t, int64(total), p.TotalBytes)
+				observed = append(observed, p.BytesWritten)
+			}
+		}
+	}
+
+	require.NotEmpty(t, observed, "expected intermediate progress while uploading")
+	for i := 1; i < len(observed); i++ {
+		assert.GreaterOrEqual(t, observed[i], observed[i-1], "progress must not go backwards")
+	}
+	assert.Less(t, observed[0], int64(total))
+
+	progress, err = storageSvc.GetUploadProgress(ctx, object.ID)
+	require.NoError(t, err)
+	assert.True(t, progress.Completed)
+	assert.Equal(t, int64(total), progress.BytesWritten)
+	assert.Equal(t, int64(total), progress.TotalBytes)
+}
+
+func TestGetUploadStatuses(t *testing.T) {
+	svc, storageSvc := setupTestServiceWithStorage(t)
+	ctx := context.Background()
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Dashboard uploads",
+	})
+	require.NoError(t, err)
+
+	createObject := func(version int) *simplecontent.Object {
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            version,
+		})
+		require.NoError(t, err)
+		return object
+	}
+	pending := createObject(1)
+	uploaded := createObject(2)
+	require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+		ObjectID: uploaded.ID,
+		Reader:   strings.NewReader("uploaded data"),
+	}))
+	deleted := createObject(3)
+	require.NoError(t, storageSvc.DeleteObject(ctx, deleted.ID))
+	missing := uuid.New()
+
+	statuses, err := storageSvc.GetUploadStatuses(ctx, []uuid.UUID{pending.ID, uploaded.ID, deleted.ID, missing})
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, simplecontent.ObjectStatusCreated, statuses[pending.ID].Status)
+	assert.Equal(t, int64(0), statuses[pending.ID].SizeBytes)
+
+	assert.Equal(t, simplecontent.ObjectStatusUploaded, statuses[uploaded.ID].Status)
+	assert.Equal(t, int64(len("uploaded data")), statuses[uploaded.ID].SizeBytes)
+	assert.False(t, statuses[uploaded.ID].UpdatedAt.Before(uploaded.UpdatedAt))
+
+	assert.NotContains(t, statuses, deleted.ID)
+	assert.NotContains(t, statuses, missing)
+
+	statuses, err = storageSvc.GetUploadStatuses(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+}
+
+// slowBlobStore writes part of each upload and then stalls until the context is done
+type slowBlobStore struct {
+	simplecontent.BlobStore
+	delay   time.Duration
+	deletes []string
+}
+
+func (s *slowBlobStore) Upload(ctx context.Context, objectKey string, reader io.Reader) error {
+	if err := s.BlobStore.Upload(ctx, objectKey, strings.NewReader("partial")); err != nil {
+		return err
+	}
+	select {
+	case <-time.After(s.delay):
+		return s.BlobStore.Upload(ctx, objectKey, reader)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *slowBlobStore) GetObjectMeta(ctx context.Context, objectKey string) (*simplecontent.ObjectMeta, error) {
+	select {
+	case <-time.After(s.delay):
+		return s.BlobStore.GetObjectMeta(ctx, objectKey)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *slowBlobStore) Delete(ctx context.Context, objectKey string) error {
+	s.deletes = append(s.deletes, objectKey)
+	return s.BlobStore.Delete(ctx, objectKey)
+}
+
+// pingingBlobStore records the pings it receives and whether they carried a deadline
+type pingingBlobStore struct {
+	simplecontent.BlobStore
+	pings        int
+	withDeadline bool
+}
+
+func (s *pingingBlobStore) Ping(ctx context.Context) error {
+	s.pings++
+	_, s.withDeadline = ctx.Deadline()
+	return nil
+}
+
+// dispositionBlobStore signs the requested disposition into its download URLs and copies
+// objects server-side
+type dispositionBlobStore struct {
+	simplecontent.BlobStore
+	copies int
+}
+
+func (s *dispositionBlobStore) GetDownloadURLWithDisposition(ctx context.Context, objectKey, disposition, downloadFilename string) (string, error) {
+	return "https://storage.example.com/" + objectKey + "?disposition=" + disposition, nil
+}
+
+func (s *dispositionBlobStore) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	s.copies++
+	return nil
+}
+
+func TestWithStorageTimeout(t *testing.T) {
+	ctx := context.Background()
+	inner := memorystorage.New()
+	slow := &slowBlobStore{BlobStore: inner, delay: time.Second}
+
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithStorageTimeout("slow", 20*time.Millisecond),
+		simplecontent.WithBlobStore("slow", slow),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Timeout",
+	})
+	require.NoError(t, err)
+
+	t.Run("UploadTimesOutAndCleansUp", func(t *testing.T) {
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "slow",
+			Version:            1,
+		})
+		require.NoError(t, err)
+
+		start := time.Now()
+		err = storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: object.ID,
+			Reader:   strings.NewReader("payload"),
+		})
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.True(t, errors.Is(err, simplecontent.ErrStorageTimeout))
+
+		var storageErr *simplecontent.StorageError
+		require.True(t, errors.As(err, &storageErr))
+		assert.Equal(t, 504, storageErr.HTTPStatus())
+
+		// The partial write was removed
+		assert.Equal(t, []string{object.ObjectKey}, slow.deletes)
+		_, err = inner.GetObjectMeta(ctx, object.ObjectKey)
+		assert.Error(t, err)
+	})
+
+	t.Run("ObjectMetaTimesOut", func(t *testing.T) {
+		backend, err := svc.GetBackend("slow")
+		require.NoError(t, err)
+
+		_, err = backend.GetObjectMeta(ctx, "missing")
+		assert.True(t, errors.Is(err, simplecontent.ErrStorageTimeout))
+	})
+
+	t.Run("PingIsForwarded", func(t *testing.T) {
+		pinging := &pingingBlobStore{BlobStore: memorystorage.New()}
+		pingSvc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("pinging", pinging),
+			simplecontent.WithStorageTimeout("pinging", time.Second),
+		)
+		require.NoError(t, err)
+		backend, err := pingSvc.GetBackend("pinging")
+		require.NoError(t, err)
+
+		require.NoError(t, simplecontent.PingBackend(ctx, backend))
+		assert.Equal(t, 1, pinging.pings, "the backend's own Ping is used instead of the probe")
+		assert.True(t, pinging.withDeadline)
+	})
+
+	t.Run("OptionalInterfacesAreForwarded", func(t *testing.T) {
+		disposition := &dispositionBlobStore{BlobStore: memorystorage.New()}
+		fsStore, err := fsstorage.New(fsstorage.Config{BaseDir: t.TempDir(), URLPrefix: "/api/v1", SignatureSecretKey: "test-secret"})
+		require.NoError(t, err)
+		wrappedSvc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("s3", disposition),
+			simplecontent.WithBlobStore("fs", fsStore),
+			simplecontent.WithStorageTimeout("s3", time.Second),
+			simplecontent.WithStorageTimeout("fs", time.Second),
+		)
+		require.NoError(t, err)
+
+		backend, err := wrappedSvc.GetBackend("s3")
+		require.NoError(t, err)
+		signer, ok := backend.(urlstrategy.DispositionURLSigner)
+		require.True(t, ok, "the disposition signer is not hidden by the wrapper")
+		url, err := signer.GetDownloadURLWithDisposition(ctx, "docs/a.pdf", "inline", "a.pdf")
+		require.NoError(t, err)
+		assert.Contains(t, url, "disposition=inline")
+
+		copier, ok := backend.(simplecontent.ObjectCopier)
+		require.True(t, ok)
+		require.NoError(t, copier.CopyObject(ctx, "docs/a.pdf", "docs/b.pdf"))
+		assert.Equal(t, 1, disposition.copies, "the server-side copy is used")
+
+		fsBackend, err := wrappedSvc.GetBackend("fs")
+		require.NoError(t, err)
+		validator, ok := fsBackend.(interface{ IsSignedURLEnabled() bool })
+		require.True(t, ok)
+		assert.True(t, validator.IsSignedURLEnabled())
+	})
+
+	t.Run("CallerCancellationIsNotATimeout", func(t *testing.T) {
+		backend, err := svc.GetBackend("slow")
+		require.NoError(t, err)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = backend.GetObjectMeta(cancelled, "missing")
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, simplecontent.ErrStorageTimeout))
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("FastBackendsAreUnaffected", func(t *testing.T) {
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            2,
+		})
+		require.NoError(t, err)
+
+		err = storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: object.ID,
+			Reader:   strings.NewReader("payload"),
+		})
+		require.NoError(t, err)
+
+		reader, err := storageSvc.DownloadObject(ctx, object.ID)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		assert.Equal(t, "payload", string(data))
+	})
+}
+
+func TestCreateObject_KeySanitizer(t *testing.T) {
+	baseDir := t.TempDir()
+	fsStore, err := fsstorage.New(fsstorage.Config{BaseDir: baseDir})
+	require.NoError(t, err)
+
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("fs", fsStore),
+		simplecontent.WithObjectKeySanitizer(objectkey.SanitizeKey),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+	ctx := context.Background()
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Adversarial keys",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		objectKey string
+		fileName  string
+		wantKey   string
+		wantName  string
+	}{
+		{"Traversal", "../../etc/passwd", "../../etc/passwd", "etc/passwd", "../../etc/passwd"},
+		{"Emoji", "uploads/party 🎉.png", "party 🎉.png", "uploads/party%20%F0%9F%8E%89.png", "party 🎉.png"},
+		{"NullByte", "uploads/evil\x00.txt", "evil\x00.txt", "uploads/evil.txt", "evil.txt"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+				ContentID:          content.ID,
+				StorageBackendName: "fs",
+				Version:            i + 1,
+				ObjectKey:          tt.objectKey,
+				FileName:           tt.fileName,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKey, object.ObjectKey)
+			assert.Equal(t, tt.wantName, object.FileName, "display filename should be preserved")
+
+			metadata, err := storageSvc.GetObjectMetadata(ctx, object.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, metadata["file_name"])
+
+			require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+				ObjectID: object.ID,
+				Reader:   strings.NewReader("data"),
+			}))
+			_, err = os.Stat(filepath.Join(baseDir, tt.wantKey))
+			assert.NoError(t, err, "object should be stored inside the base directory")
+		})
+	}
+
+	t.Run("KeysUnchangedWithoutSanitizer", func(t *testing.T) {
+		plainSvc, plainStorage := setupTestServiceWithStorage(t)
+		plainContent, err := plainSvc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Raw keys",
+		})
+		require.NoError(t, err)
+
+		object, err := plainStorage.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          plainContent.ID,
+			StorageBackendName: "memory",
+			Version:            1,
+			ObjectKey:          "raw key/../x",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "raw key/../x", object.ObjectKey)
+	})
+}
+
 func TestErrorHandling(t *testing.T) {
 	svc, storageSvc := setupTestServiceWithStorage(t)
 	ctx := context.Background()
@@ -645,52 +1163,392 @@ func TestUploadObjectForContent(t *testing.T) {
 	assert.Equal(t, object.ID, objects[0].ID)
 }
 
-func TestAsyncWorkflow(t *testing.T) {
-	svc := setupTestService(t)
+func TestDownloadContentFallback(t *testing.T) {
 	ctx := context.Background()
 
-	t.Run("CompleteAsyncWorkflow", func(t *testing.T) {
-		// Step 1: Create parent content
-		parentContent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
-			OwnerID:      uuid.New(),
-			TenantID:     uuid.New(),
-			Name:         "Source Image",
-			DocumentType: "image/png",
-			Reader:       strings.NewReader("source image data"),
-			FileName:     "source.png",
-		})
+	setup := func(t *testing.T) (simplecontent.Service, simplecontent.StorageService, *memorystorage.Backend, *memorystorage.Backend) {
+		primary := memorystorage.New().(*memorystorage.Backend)
+		replica := memorystorage.New().(*memorystorage.Backend)
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("memory", primary),
+			simplecontent.WithBlobStore("replica", replica),
+			simplecontent.WithReplicaBackend("memory", "replica"),
+		)
 		require.NoError(t, err)
-		assert.Equal(t, string(simplecontent.ContentStatusUploaded), parentContent.Status)
+		return svc, svc.(simplecontent.StorageService), primary, replica
+	}
 
-		// Step 2: Create derived content placeholder (before processing)
-		derivedContent, err := svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
-			ParentID:       parentContent.ID,
-			OwnerID:        parentContent.OwnerID,
-			TenantID:       parentContent.TenantID,
-			DerivationType: "thumbnail",
-			Variant:        "thumbnail_256",
-			InitialStatus:  simplecontent.ContentStatusProcessing,
-			Metadata: map[string]interface{}{
-				"target_size": "256x256",
-				"format":      "jpeg",
-			},
+	upload := func(t *testing.T, svc simplecontent.Service) (*simplecontent.Content, *simplecontent.Object) {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:            uuid.New(),
+			TenantID:           uuid.New(),
+			Name:               "Versioned",
+			DocumentType:       "text/plain",
+			StorageBackendName: "memory",
+			Reader:             strings.NewReader("version one"),
+			FileName:           "doc.txt",
 		})
 		require.NoError(t, err)
-		assert.Equal(t, string(simplecontent.ContentStatusProcessing), derivedContent.Status,
-			"Derived content should start in 'processing' state")
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return content, objects[0]
+	}
 
-		// Step 3: Simulate worker processing (generate thumbnail)
-		// In real workflow, worker would:
-		// - Download parent content
-		// - Generate thumbnail
-		// - Upload thumbnail data
-		thumbnailData := strings.NewReader("generated thumbnail data")
+	readAll := func(t *testing.T, reader io.ReadCloser) string {
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data)
+	}
 
-		// Step 4: Upload object for the derived content
-		object, err := svc.UploadObjectForContent(ctx, simplecontent.UploadObjectForContentRequest{
-			ContentID: derivedContent.ID,
-			Reader:    thumbnailData,
-			FileName:  "thumb_256.jpg",
+	t.Run("OlderVersionServesMissingLatest", func(t *testing.T) {
+		svc, storageSvc, primary, _ := setup(t)
+		content, v1 := upload(t, svc)
+
+		v2, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            2,
+		})
+		require.NoError(t, err)
+		require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: v2.ID,
+			Reader:   strings.NewReader("version two"),
+		}))
+
+		reader, source, err := svc.DownloadContentWithSource(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "version two", readAll(t, reader))
+		assert.Equal(t, v2.ID, source.ObjectID)
+		assert.False(t, source.Fallback)
+
+		// Remove the latest blob; the previous version serves the download
+		require.NoError(t, primary.Delete(ctx, v2.ObjectKey))
+
+		reader, source, err = svc.DownloadContentWithSource(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "version one", readAll(t, reader))
+		assert.Equal(t, v1.ID, source.ObjectID)
+		assert.Equal(t, 1, source.Version)
+		assert.Equal(t, "memory", source.Backend)
+		assert.True(t, source.Fallback)
+
+		reader, err = svc.DownloadContent(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "version one", readAll(t, reader))
+	})
+
+	t.Run("ReplicaServesMissingPrimary", func(t *testing.T) {
+		svc, _, primary, replica := setup(t)
+		content, v1 := upload(t, svc)
+
+		require.NoError(t, replica.Upload(ctx, v1.ObjectKey, strings.NewReader("replica copy")))
+		require.NoError(t, primary.Delete(ctx, v1.ObjectKey))
+
+		reader, source, err := svc.DownloadContentWithSource(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "replica copy", readAll(t, reader))
+		assert.Equal(t, v1.ID, source.ObjectID)
+		assert.Equal(t, "replica", source.Backend)
+		assert.True(t, source.Fallback)
+	})
+
+	t.Run("AllSourcesMissing", func(t *testing.T) {
+		svc, _, primary, _ := setup(t)
+		content, v1 := upload(t, svc)
+		require.NoError(t, primary.Delete(ctx, v1.ObjectKey))
+
+		_, _, err := svc.DownloadContentWithSource(ctx, content.ID)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, simplecontent.ErrBlobNotFound)
+	})
+}
+
+// failingUploadStore is a memory backend whose uploads always fail
+type failingUploadStore struct {
+	simplecontent.BlobStore
+}
+
+func (f *failingUploadStore) Upload(ctx context.Context, objectKey string, reader io.Reader) error {
+	return errors.New("mirror unavailable")
+}
+
+func (f *failingUploadStore) UploadWithParams(ctx context.Context, reader io.Reader, params simplecontent.UploadParams) error {
+	return errors.New("mirror unavailable")
+}
+
+func TestMirrorBackend(t *testing.T) {
+	ctx := context.Background()
+
+	uploadRequest := func() simplecontent.UploadContentRequest {
+		return simplecontent.UploadContentRequest{
+			OwnerID:            uuid.New(),
+			TenantID:           uuid.New(),
+			Name:               "Mirrored",
+			DocumentType:       "text/plain",
+			StorageBackendName: "primary",
+			Reader:             strings.NewReader("mirrored data"),
+			FileName:           "mirrored.txt",
+		}
+	}
+
+	t.Run("WritesBothBackends", func(t *testing.T) {
+		primary := memorystorage.New()
+		mirror := memorystorage.New()
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("primary", primary),
+			simplecontent.WithBlobStore("mirror", mirror),
+			simplecontent.WithMirrorBackend("mirror"),
+		)
+		require.NoError(t, err)
+		storageSvc := svc.(simplecontent.StorageService)
+
+		content, err := svc.UploadContent(ctx, uploadRequest())
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		object := objects[0]
+
+		for name, store := range map[string]simplecontent.BlobStore{"primary": primary, "mirror": mirror} {
+			reader, err := store.Download(ctx, object.ObjectKey)
+			require.NoError(t, err, name)
+			data, err := io.ReadAll(reader)
+			reader.Close()
+			require.NoError(t, err)
+			assert.Equal(t, "mirrored data", string(data), name)
+		}
+
+		metadata, err := storageSvc.GetObjectMetadata(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mirror"}, metadata[simplecontent.MetaReplicas])
+
+		// Refreshing metadata from storage keeps the replica list
+		_, err = storageSvc.UpdateObjectMetaFromStorage(ctx, object.ID)
+		require.NoError(t, err)
+		metadata, err = storageSvc.GetObjectMetadata(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mirror"}, metadata[simplecontent.MetaReplicas])
+
+		// Downloads fall back to the mirror copy
+		require.NoError(t, primary.Delete(ctx, object.ObjectKey))
+		reader, source, err := svc.DownloadContentWithSource(ctx, content.ID)
+		require.NoError(t, err)
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "mirrored data", string(data))
+		assert.Equal(t, "mirror", source.Backend)
+	})
+
+	t.Run("WarnPolicyKeepsUpload", func(t *testing.T) {
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("primary", memorystorage.New()),
+			simplecontent.WithBlobStore("mirror", &failingUploadStore{BlobStore: memorystorage.New()}),
+			simplecontent.WithMirrorBackend("mirror"),
+			simplecontent.WithMirrorFailurePolicy(simplecontent.MirrorFailurePolicyWarn),
+		)
+		require.NoError(t, err)
+		storageSvc := svc.(simplecontent.StorageService)
+
+		content, err := svc.UploadContent(ctx, uploadRequest())
+		require.NoError(t, err)
+		assert.Equal(t, string(simplecontent.ContentStatusUploaded), content.Status)
+
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		metadata, err := storageSvc.GetObjectMetadata(ctx, objects[0].ID)
+		require.NoError(t, err)
+		assert.NotContains(t, metadata, simplecontent.MetaReplicas)
+
+		reader, err := svc.DownloadContent(ctx, content.ID)
+		require.NoError(t, err)
+		reader.Close()
+	})
+
+	t.Run("FailPolicyFailsUpload", func(t *testing.T) {
+		primary := memorystorage.New()
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("primary", primary),
+			simplecontent.WithBlobStore("mirror", &failingUploadStore{BlobStore: memorystorage.New()}),
+			simplecontent.WithMirrorBackend("mirror"),
+		)
+		require.NoError(t, err)
+		storageSvc := svc.(simplecontent.StorageService)
+
+		content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Mirrored",
+		})
+		require.NoError(t, err)
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "primary",
+			Version:            1,
+		})
+		require.NoError(t, err)
+
+		err = storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: object.ID,
+			Reader:   strings.NewReader("mirrored data"),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "mirror unavailable")
+
+		// The primary copy is removed so the object is not left looking uploaded
+		_, err = primary.Download(ctx, object.ObjectKey)
+		assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+	})
+
+	t.Run("UnknownMirrorBackend", func(t *testing.T) {
+		_, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("primary", memorystorage.New()),
+			simplecontent.WithMirrorBackend("missing"),
+		)
+		assert.ErrorIs(t, err, simplecontent.ErrStorageBackendNotFound)
+	})
+}
+
+// blockingReader returns its data only after release is closed
+type blockingReader struct {
+	started chan struct{}
+	release chan struct{}
+	reader  io.Reader
+	once    bool
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	if !r.once {
+		r.once = true
+		close(r.started)
+		<-r.release
+	}
+	return r.reader.Read(p)
+}
+
+func TestServiceStats(t *testing.T) {
+	ctx := context.Background()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithBlobStore("broken", &failingUploadStore{BlobStore: memorystorage.New()}),
+	)
+	require.NoError(t, err)
+
+	stats := svc.Stats()
+	assert.False(t, stats.StartedAt.IsZero())
+	assert.Zero(t, stats.UploadsCompleted)
+	assert.Empty(t, stats.ErrorsByBackend)
+
+	// An upload is reported in flight until the backend finishes reading it
+	source := &blockingReader{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		reader:  strings.NewReader("twelve bytes"),
+	}
+	uploaded := make(chan *simplecontent.Content, 1)
+	go func() {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:            uuid.New(),
+			TenantID:           uuid.New(),
+			Name:               "Counted",
+			StorageBackendName: "memory",
+			Reader:             source,
+		})
+		assert.NoError(t, err)
+		uploaded <- content
+	}()
+	<-source.started
+	assert.Equal(t, int64(1), svc.Stats().UploadsInFlight)
+	close(source.release)
+	content := <-uploaded
+	require.NotNil(t, content)
+
+	reader, err := svc.DownloadContent(ctx, content.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, "twelve bytes", string(data))
+
+	// A failed upload counts against its backend
+	_, err = svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:            uuid.New(),
+		TenantID:           uuid.New(),
+		Name:               "Broken",
+		StorageBackendName: "broken",
+		Reader:             strings.NewReader("lost"),
+	})
+	require.Error(t, err)
+
+	stats = svc.Stats()
+	assert.Equal(t, int64(0), stats.UploadsInFlight)
+	assert.Equal(t, int64(1), stats.UploadsCompleted)
+	assert.Equal(t, int64(1), stats.UploadsFailed)
+	assert.Equal(t, int64(12), stats.BytesUploaded)
+	assert.Equal(t, int64(1), stats.Downloads)
+	assert.Equal(t, int64(12), stats.BytesDownloaded)
+	assert.Equal(t, map[string]int64{"broken": 1}, stats.ErrorsByBackend)
+
+	// The snapshot is a copy
+	stats.ErrorsByBackend["broken"] = 100
+	assert.Equal(t, int64(1), svc.Stats().ErrorsByBackend["broken"])
+}
+
+func TestAsyncWorkflow(t *testing.T) {
+	svc := setupTestService(t)
+	ctx := context.Background()
+
+	t.Run("CompleteAsyncWorkflow", func(t *testing.T) {
+		// Step 1: Create parent content
+		parentContent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         "Source Image",
+			DocumentType: "image/png",
+			Reader:       strings.NewReader("source image data"),
+			FileName:     "source.png",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, string(simplecontent.ContentStatusUploaded), parentContent.Status)
+
+		// Step 2: Create derived content placeholder (before processing)
+		derivedContent, err := svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+			ParentID:       parentContent.ID,
+			OwnerID:        parentContent.OwnerID,
+			TenantID:       parentContent.TenantID,
+			DerivationType: "thumbnail",
+			Variant:        "thumbnail_256",
+			InitialStatus:  simplecontent.ContentStatusProcessing,
+			Metadata: map[string]interface{}{
+				"target_size": "256x256",
+				"format":      "jpeg",
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, string(simplecontent.ContentStatusProcessing), derivedContent.Status,
+			"Derived content should start in 'processing' state")
+
+		// Step 3: Simulate worker processing (generate thumbnail)
+		// In real workflow, worker would:
+		// - Download parent content
+		// - Generate thumbnail
+		// - Upload thumbnail data
+		thumbnailData := strings.NewReader("generated thumbnail data")
+
+		// Step 4: Upload object for the derived content
+		object, err := svc.UploadObjectForContent(ctx, simplecontent.UploadObjectForContentRequest{
+			ContentID: derivedContent.ID,
+			Reader:    thumbnailData,
+			FileName:  "thumb_256.jpg",
 			MimeType:  "image/jpeg",
 		})
 		require.NoError(t, err)
@@ -781,99 +1639,402 @@ func TestAsyncWorkflow(t *testing.T) {
 	})
 }
 
-// Benchmark tests
-func BenchmarkCreateContent(b *testing.B) {
-	svc := setupBenchmarkService(b)
+func TestContentMetadataHistory(t *testing.T) {
+	svc := setupTestService(t)
 	ctx := context.Background()
 
-	req := simplecontent.CreateContentRequest{
-		OwnerID:     uuid.New(),
-		TenantID:    uuid.New(),
-		Name:        "Benchmark Content",
-		Description: "Content for benchmarking",
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Versioned",
+	})
+	require.NoError(t, err)
+
+	titles := []string{"Draft", "Review", "Final"}
+	for i, title := range titles {
+		actorCtx := simplecontent.WithActor(ctx, fmt.Sprintf("editor-%d", i+1))
+		require.NoError(t, svc.SetContentMetadata(actorCtx, simplecontent.SetContentMetadataRequest{
+			ContentID: content.ID,
+			Title:     title,
+		}))
 	}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		req.Name = fmt.Sprintf("Benchmark Content %d", i)
-		_, err := svc.CreateContent(ctx, req)
-		if err != nil {
-			b.Fatal(err)
-		}
+	history, err := svc.GetContentMetadataHistory(ctx, content.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	for i, title := range titles {
+		assert.Equal(t, i+1, history[i].Version)
+		assert.Equal(t, fmt.Sprintf("editor-%d", i+1), history[i].Actor)
+		assert.Equal(t, title, history[i].Metadata.Metadata["title"])
 	}
+
+	// The current read still returns the latest version
+	current, err := svc.GetContentMetadata(ctx, content.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Final", current.Metadata["title"])
 }
 
-func BenchmarkUploadDownload(b *testing.B) {
-	svc := setupBenchmarkService(b)
-	storageSvc, ok := svc.(simplecontent.StorageService)
-	if !ok {
-		b.Fatal("Service should implement StorageService interface")
-	}
+func TestCreateObjectCollisionPolicy(t *testing.T) {
 	ctx := context.Background()
 
-	// Setup
-	contentReq := simplecontent.CreateContentRequest{
-		OwnerID:  uuid.New(),
-		TenantID: uuid.New(),
-		Name:     "Benchmark Content",
+	setup := func(t *testing.T, opts ...simplecontent.Option) (simplecontent.StorageService, simplecontent.BlobStore, uuid.UUID) {
+		store := memorystorage.New()
+		opts = append([]simplecontent.Option{
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("memory", store),
+		}, opts...)
+		svc, err := simplecontent.New(opts...)
+		require.NoError(t, err)
+		content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Collisions",
+		})
+		require.NoError(t, err)
+		return svc.(simplecontent.StorageService), store, content.ID
 	}
-	content, err := svc.CreateContent(ctx, contentReq)
-	require.NoError(b, err)
 
-	testData := strings.Repeat("test data ", 1000) // ~9KB of data
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		// Create object
-		objReq := simplecontent.CreateObjectRequest{
-			ContentID:          content.ID,
+	createObject := func(storageSvc simplecontent.StorageService, contentID uuid.UUID, policy simplecontent.CollisionPolicy) (*simplecontent.Object, error) {
+		return storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          contentID,
 			StorageBackendName: "memory",
-			Version:            i + 1,
-		}
-		object, err := storageSvc.CreateObject(ctx, objReq)
-		if err != nil {
-			b.Fatal(err)
-		}
+			Version:            1,
+			ObjectKey:          "docs/report.pdf",
+			CollisionPolicy:    policy,
+		})
+	}
 
-		// Upload
-		reader := strings.NewReader(testData)
-		uploadReq := simplecontent.UploadObjectRequest{
-			ObjectID: object.ID,
-			Reader:   reader,
-		}
-		err = storageSvc.UploadObject(ctx, uploadReq)
-		if err != nil {
-			b.Fatal(err)
-		}
+	t.Run("NoPolicyKeepsKey", func(t *testing.T) {
+		storageSvc, _, contentID := setup(t)
+		_, err := createObject(storageSvc, contentID, "")
+		require.NoError(t, err)
+		second, err := createObject(storageSvc, contentID, "")
+		require.NoError(t, err)
+		assert.Equal(t, "docs/report.pdf", second.ObjectKey)
+	})
 
-		// Download
-		downloadReader, err := storageSvc.DownloadObject(ctx, object.ID)
-		if err != nil {
-			b.Fatal(err)
-		}
-		_, err = io.ReadAll(downloadReader)
-		if err != nil {
-			b.Fatal(err)
-		}
-		downloadReader.Close()
-	}
-}
+	t.Run("Error", func(t *testing.T) {
+		storageSvc, store, contentID := setup(t, simplecontent.WithCollisionPolicy(simplecontent.CollisionPolicyError))
+		_, err := createObject(storageSvc, contentID, "")
+		require.NoError(t, err)
+		_, err = createObject(storageSvc, contentID, "")
+		assert.ErrorIs(t, err, simplecontent.ErrObjectKeyExists)
 
-// Helper interface that both *testing.T and *testing.B implement
-type testingInterface interface {
-	Helper()
-	Errorf(format string, args ...interface{})
-	FailNow()
-}
+		// Data stored on the backend without an object record also counts
+		require.NoError(t, store.Upload(ctx, "docs/orphan.pdf", strings.NewReader("orphan")))
+		_, err = storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          contentID,
+			StorageBackendName: "memory",
+			Version:            1,
+			ObjectKey:          "docs/orphan.pdf",
+		})
+		assert.ErrorIs(t, err, simplecontent.ErrObjectKeyExists)
+	})
 
-// Helper function for benchmark tests
-func setupBenchmarkService(b *testing.B) simplecontent.Service {
-	repo := memory.New()
-	store := memorystorage.New()
-	eventSink := simplecontent.NewNoopEventSink()
-	previewer := simplecontent.NewBasicImagePreviewer()
+	t.Run("Overwrite", func(t *testing.T) {
+		storageSvc, store, contentID := setup(t)
+		first, err := createObject(storageSvc, contentID, "")
+		require.NoError(t, err)
+		require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: first.ID,
+			Reader:   strings.NewReader("old"),
+		}))
 
-	svc, err := simplecontent.New(
+		second, err := createObject(storageSvc, contentID, simplecontent.CollisionPolicyOverwrite)
+		require.NoError(t, err)
+		assert.Equal(t, first.ObjectKey, second.ObjectKey)
+		require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: second.ID,
+			Reader:   strings.NewReader("new"),
+		}))
+
+		// The previous object is soft deleted and the stored data replaced
+		_, err = storageSvc.GetObject(ctx, first.ID)
+		assert.Error(t, err)
+		reader, err := store.Download(ctx, second.ObjectKey)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+	})
+
+	t.Run("AutoRename", func(t *testing.T) {
+		storageSvc, _, contentID := setup(t)
+		first, err := createObject(storageSvc, contentID, simplecontent.CollisionPolicyAutoRename)
+		require.NoError(t, err)
+		assert.Equal(t, "docs/report.pdf", first.ObjectKey)
+
+		second, err := createObject(storageSvc, contentID, simplecontent.CollisionPolicyAutoRename)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.ObjectKey, second.ObjectKey)
+		assert.Regexp(t, `^docs/report-[0-9a-f]{8}\.pdf$`, second.ObjectKey)
+	})
+
+	t.Run("InvalidPolicy", func(t *testing.T) {
+		storageSvc, _, contentID := setup(t)
+		_, err := createObject(storageSvc, contentID, "")
+		require.NoError(t, err)
+		_, err = createObject(storageSvc, contentID, "replace")
+		assert.Error(t, err)
+	})
+}
+
+func TestStrictContentType(t *testing.T) {
+	ctx := context.Background()
+	pdfData := "%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"
+	jpegData := "\xff\xd8\xff\xe0\x00\x10JFIF\x00"
+
+	newService := func(t *testing.T, opts ...simplecontent.Option) simplecontent.Service {
+		opts = append([]simplecontent.Option{
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("memory", memorystorage.New()),
+		}, opts...)
+		svc, err := simplecontent.New(opts...)
+		require.NoError(t, err)
+		return svc
+	}
+
+	upload := func(svc simplecontent.Service, documentType, data string) (*simplecontent.Content, error) {
+		return svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         "Photo",
+			DocumentType: documentType,
+			Reader:       strings.NewReader(data),
+			FileName:     "photo.jpg",
+		})
+	}
+
+	t.Run("RejectsPDFForImage", func(t *testing.T) {
+		svc := newService(t, simplecontent.WithStrictContentType())
+		_, err := upload(svc, "image/jpeg", pdfData)
+		assert.ErrorIs(t, err, simplecontent.ErrContentTypeMismatch)
+
+		// Uploading to an existing image content is checked too
+		content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         "Photo",
+			DocumentType: "image/jpeg",
+		})
+		require.NoError(t, err)
+		_, err = svc.UploadObjectForContent(ctx, simplecontent.UploadObjectForContentRequest{
+			ContentID: content.ID,
+			Reader:    strings.NewReader(pdfData),
+		})
+		assert.ErrorIs(t, err, simplecontent.ErrContentTypeMismatch)
+
+		storageSvc := svc.(simplecontent.StorageService)
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            1,
+		})
+		require.NoError(t, err)
+		err = storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: object.ID,
+			Reader:   strings.NewReader(pdfData),
+		})
+		assert.ErrorIs(t, err, simplecontent.ErrContentTypeMismatch)
+	})
+
+	t.Run("AcceptsMatchingData", func(t *testing.T) {
+		svc := newService(t, simplecontent.WithStrictContentType())
+		content, err := upload(svc, "image/jpeg", jpegData)
+		require.NoError(t, err)
+
+		// The sniffed bytes are still stored
+		reader, err := svc.DownloadContent(ctx, content.ID)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		assert.Equal(t, jpegData, string(data))
+
+		_, err = upload(svc, "image/*", jpegData)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ToleratesGenericTypes", func(t *testing.T) {
+		svc := newService(t, simplecontent.WithStrictContentType())
+		_, err := upload(svc, "application/json", `{"name": "photo"}`)
+		assert.NoError(t, err)
+		_, err = upload(svc, "application/octet-stream", pdfData)
+		assert.NoError(t, err)
+
+		svc = newService(t, simplecontent.WithStrictContentType("application/pdf"))
+		_, err = upload(svc, "image/jpeg", pdfData)
+		assert.NoError(t, err)
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		_, err := upload(newService(t), "image/jpeg", pdfData)
+		assert.NoError(t, err)
+	})
+}
+
+func TestPresignCapabilities(t *testing.T) {
+	ctx := context.Background()
+
+	fsStore, err := fsstorage.New(fsstorage.Config{
+		BaseDir:            t.TempDir(),
+		URLPrefix:          "http://localhost:8080/api/v1",
+		SignatureSecretKey: "test-secret",
+	})
+	require.NoError(t, err)
+	memStore := memorystorage.New()
+	assert.False(t, simplecontent.GetBackendCapabilities(memStore).PresignedUpload)
+	assert.True(t, simplecontent.GetBackendCapabilities(fsStore).PresignedUpload)
+
+	blobStores := map[string]urlstrategy.BlobStore{"memory": memStore, "fs": fsStore}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memStore),
+		simplecontent.WithBlobStore("fs", fsStore),
+		simplecontent.WithURLStrategy(urlstrategy.NewStorageDelegatedStrategy(blobStores)),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	createObject := func(t *testing.T, backend string) (*simplecontent.Content, *simplecontent.Object) {
+		content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Direct upload",
+		})
+		require.NoError(t, err)
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: backend,
+			Version:            1,
+		})
+		require.NoError(t, err)
+		return content, object
+	}
+
+	t.Run("Capable", func(t *testing.T) {
+		content, object := createObject(t, "fs")
+		uploadURL, err := storageSvc.GetUploadURL(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Contains(t, uploadURL, "signature=")
+		downloadURL, err := storageSvc.GetDownloadURL(ctx, object.ID)
+		require.NoError(t, err)
+		assert.NotEmpty(t, downloadURL)
+
+		details, err := svc.GetContentDetails(ctx, content.ID, simplecontent.WithUploadAccess())
+		require.NoError(t, err)
+		assert.NotEmpty(t, details.Upload)
+	})
+
+	t.Run("NotCapable", func(t *testing.T) {
+		content, object := createObject(t, "memory")
+		_, err := storageSvc.GetUploadURL(ctx, object.ID)
+		assert.ErrorIs(t, err, simplecontent.ErrPresignNotSupported)
+		_, err = storageSvc.GetDownloadURL(ctx, object.ID)
+		assert.ErrorIs(t, err, simplecontent.ErrPresignNotSupported)
+		_, err = storageSvc.GetPreviewURL(ctx, object.ID)
+		assert.ErrorIs(t, err, simplecontent.ErrPresignNotSupported)
+
+		details, err := svc.GetContentDetails(ctx, content.ID, simplecontent.WithUploadAccess())
+		require.NoError(t, err)
+		assert.Empty(t, details.Upload)
+	})
+}
+
+// Benchmark tests
+func BenchmarkCreateContent(b *testing.B) {
+	svc := setupBenchmarkService(b)
+	ctx := context.Background()
+
+	req := simplecontent.CreateContentRequest{
+		OwnerID:     uuid.New(),
+		TenantID:    uuid.New(),
+		Name:        "Benchmark Content",
+		Description: "Content for benchmarking",
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req.Name = fmt.Sprintf("Benchmark Content %d", i)
+		_, err := svc.CreateContent(ctx, req)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUploadDownload(b *testing.B) {
+	svc := setupBenchmarkService(b)
+	storageSvc, ok := svc.(simplecontent.StorageService)
+	if !ok {
+		b.Fatal("Service should implement StorageService interface")
+	}
+	ctx := context.Background()
+
+	// Setup
+	contentReq := simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Benchmark Content",
+	}
+	content, err := svc.CreateContent(ctx, contentReq)
+	require.NoError(b, err)
+
+	testData := strings.Repeat("test data ", 1000) // ~9KB of data
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Create object
+		objReq := simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            i + 1,
+		}
+		object, err := storageSvc.CreateObject(ctx, objReq)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		// Upload
+		reader := strings.NewReader(testData)
+		uploadReq := simplecontent.UploadObjectRequest{
+			ObjectID: object.ID,
+			Reader:   reader,
+		}
+		err = storageSvc.UploadObject(ctx, uploadReq)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		// Download
+		downloadReader, err := storageSvc.DownloadObject(ctx, object.ID)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = io.ReadAll(downloadReader)
+		if err != nil {
+			b.Fatal(err)
+		}
+		downloadReader.Close()
+	}
+}
+
+// Helper interface that both *testing.T and *testing.B implement
+type testingInterface interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	FailNow()
+}
+
+// Helper function for benchmark tests
+func setupBenchmarkService(b *testing.B) simplecontent.Service {
+	repo := memory.New()
+	store := memorystorage.New()
+	eventSink := simplecontent.NewNoopEventSink()
+	previewer := simplecontent.NewBasicImagePreviewer()
+
+	svc, err := simplecontent.New(
 		simplecontent.WithRepository(repo),
 		simplecontent.WithBlobStore("memory", store),
 		simplecontent.WithEventSink(eventSink),
@@ -1155,6 +2316,98 @@ func TestGetContentDetails(t *testing.T) {
 		assert.True(t, details.Ready, "Parent should be ready when uploaded")
 		assert.NotEmpty(t, details.Thumbnails, "Thumbnails should be available when derived content is processed")
 	})
+
+	// Test 9: Ready flag - expected derivations partially completed (not ready, missing reported)
+	t.Run("ReadyFlag_ExpectedDerivationsMissing", func(t *testing.T) {
+		parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      ownerID,
+			TenantID:     tenantID,
+			Name:         "Parent Content",
+			DocumentType: "image/jpeg",
+			Reader:       strings.NewReader("parent image data"),
+			FileName:     "parent.jpg",
+		})
+		require.NoError(t, err)
+
+		err = svc.SetExpectedDerivations(ctx, parent.ID, "thumbnail_128", "thumbnail_256", "thumbnail_720")
+		require.NoError(t, err)
+
+		for _, variant := range []string{"thumbnail_128", "thumbnail_256"} {
+			_, err = svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+				ParentID:       parent.ID,
+				OwnerID:        ownerID,
+				TenantID:       tenantID,
+				DerivationType: "thumbnail",
+				Variant:        variant,
+				Reader:         strings.NewReader("thumbnail data"),
+				FileName:       variant + ".jpg",
+			})
+			require.NoError(t, err)
+		}
+
+		details, err := svc.GetContentDetails(ctx, parent.ID)
+		require.NoError(t, err)
+		assert.False(t, details.Ready, "Parent should not be ready while an expected derivation is missing")
+		assert.Equal(t, []string{"thumbnail_720"}, details.MissingDerivations)
+		assert.Len(t, details.Thumbnails, 2)
+
+		// Complete the last expected derivation
+		_, err = svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:       parent.ID,
+			OwnerID:        ownerID,
+			TenantID:       tenantID,
+			DerivationType: "thumbnail",
+			Variant:        "thumbnail_720",
+			Reader:         strings.NewReader("thumbnail data"),
+			FileName:       "thumbnail_720.jpg",
+		})
+		require.NoError(t, err)
+
+		details, err = svc.GetContentDetails(ctx, parent.ID)
+		require.NoError(t, err)
+		assert.True(t, details.Ready, "Parent should be ready once all expected derivations completed")
+		assert.Empty(t, details.MissingDerivations)
+
+		// Metadata set before the expectation is preserved
+		metadata, err := svc.GetContentMetadata(ctx, parent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "parent.jpg", metadata.FileName)
+	})
+
+	// Test 10: Ready flag - expected derivation exists but is not completed
+	t.Run("ReadyFlag_ExpectedDerivationNotCompleted", func(t *testing.T) {
+		parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      ownerID,
+			TenantID:     tenantID,
+			Name:         "Parent Content",
+			DocumentType: "image/jpeg",
+			Reader:       strings.NewReader("parent image data"),
+			FileName:     "parent.jpg",
+		})
+		require.NoError(t, err)
+		require.NoError(t, svc.SetExpectedDerivations(ctx, parent.ID, "thumbnail_256"))
+
+		_, err = svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+			ParentID:       parent.ID,
+			OwnerID:        ownerID,
+			TenantID:       tenantID,
+			DerivationType: "thumbnail",
+			Variant:        "thumbnail_256",
+			InitialStatus:  simplecontent.ContentStatusProcessing,
+		})
+		require.NoError(t, err)
+
+		details, err := svc.GetContentDetails(ctx, parent.ID)
+		require.NoError(t, err)
+		assert.False(t, details.Ready)
+		assert.Equal(t, []string{"thumbnail_256"}, details.MissingDerivations)
+
+		batch, err := svc.GetContentDetailsBatch(ctx, []uuid.UUID{parent.ID})
+		require.NoError(t, err)
+		require.Len(t, batch, 1)
+		assert.False(t, batch[0].Ready)
+		assert.Equal(t, []string{"thumbnail_256"}, batch[0].MissingDerivations)
+	})
 }
 
 func TestGetContentDetailsBatch(t *testing.T) {
@@ -1491,3 +2744,3452 @@ func BenchmarkGetContentDetailsBatch(b *testing.B) {
 		}
 	})
 }
+
+func TestGetContentFull(t *testing.T) {
+	ctx := context.Background()
+	svc := setupTestService(t)
+
+	t.Run("MatchesIndividualCalls", func(t *testing.T) {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         "Full Content",
+			DocumentType: "text/plain",
+			Reader:       strings.NewReader("full content data"),
+			FileName:     "full.txt",
+			Tags:         []string{"a", "b"},
+		})
+		require.NoError(t, err)
+
+		full, err := svc.GetContentFull(ctx, content.ID)
+		require.NoError(t, err)
+
+		got, err := svc.GetContent(ctx, content.ID)
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		metadata, err := svc.GetContentMetadata(ctx, content.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, got, full.Content)
+		assert.Equal(t, objects, full.Objects)
+		require.Len(t, full.Objects, 1)
+		assert.Equal(t, string(simplecontent.ObjectStatusUploaded), full.Objects[0].Status)
+		assert.Equal(t, metadata, full.Metadata)
+		assert.Equal(t, "full.txt", full.Metadata.FileName)
+	})
+
+	t.Run("NoObjectsOrMetadata", func(t *testing.T) {
+		content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Empty Content",
+		})
+		require.NoError(t, err)
+
+		full, err := svc.GetContentFull(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, content.ID, full.Content.ID)
+		assert.NotNil(t, full.Objects)
+		assert.Empty(t, full.Objects)
+		assert.Nil(t, full.Metadata)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := svc.GetContentFull(ctx, uuid.New())
+		assert.ErrorIs(t, err, simplecontent.ErrContentNotFound)
+	})
+}
+
+func TestMoveObject(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (simplecontent.Service, simplecontent.StorageService, *memorystorage.Backend) {
+		store := memorystorage.New().(*memorystorage.Backend)
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("memory", store),
+		)
+		require.NoError(t, err)
+		return svc, svc.(simplecontent.StorageService), store
+	}
+
+	upload := func(t *testing.T, svc simplecontent.Service, storageSvc simplecontent.StorageService, key, data string) *simplecontent.Object {
+		content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Movable",
+		})
+		require.NoError(t, err)
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			ObjectKey:          key,
+		})
+		require.NoError(t, err)
+		require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: object.ID,
+			Reader:   strings.NewReader(data),
+			MimeType: "text/plain",
+		}))
+		return object
+	}
+
+	t.Run("MovesDataAndRecord", func(t *testing.T) {
+		svc, storageSvc, store := setup(t)
+		object := upload(t, svc, storageSvc, "tmp/upload.txt", "moved data")
+
+		moved, err := storageSvc.MoveObject(ctx, object.ID, "final/report.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "final/report.txt", moved.ObjectKey)
+
+		got, err := storageSvc.GetObject(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "final/report.txt", got.ObjectKey)
+
+		reader, err := storageSvc.DownloadObject(ctx, object.ID)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "moved data", string(data))
+
+		_, err = store.GetObjectMeta(ctx, "tmp/upload.txt")
+		assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+	})
+
+	t.Run("DestinationTaken", func(t *testing.T) {
+		svc, storageSvc, _ := setup(t)
+		object := upload(t, svc, storageSvc, "a.txt", "a")
+		upload(t, svc, storageSvc, "b.txt", "b")
+
+		_, err := storageSvc.MoveObject(ctx, object.ID, "b.txt")
+		assert.ErrorIs(t, err, simplecontent.ErrObjectKeyExists)
+
+		got, err := storageSvc.GetObject(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "a.txt", got.ObjectKey)
+	})
+
+	t.Run("SameKey", func(t *testing.T) {
+		svc, storageSvc, _ := setup(t)
+		object := upload(t, svc, storageSvc, "same.txt", "same")
+
+		moved, err := storageSvc.MoveObject(ctx, object.ID, "same.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "same.txt", moved.ObjectKey)
+	})
+
+	t.Run("EmptyKey", func(t *testing.T) {
+		svc, storageSvc, _ := setup(t)
+		object := upload(t, svc, storageSvc, "empty.txt", "empty")
+
+		_, err := storageSvc.MoveObject(ctx, object.ID, "  ")
+		assert.ErrorIs(t, err, simplecontent.ErrInvalidObjectKey)
+	})
+
+	t.Run("ObjectNotFound", func(t *testing.T) {
+		_, storageSvc, _ := setup(t)
+		_, err := storageSvc.MoveObject(ctx, uuid.New(), "x.txt")
+		assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+	})
+}
+
+func TestRestoreObjectVersion(t *testing.T) {
+	ctx := context.Background()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:            uuid.New(),
+		TenantID:           uuid.New(),
+		Name:               "Versioned",
+		DocumentType:       "text/plain",
+		StorageBackendName: "memory",
+		Reader:             strings.NewReader("version one"),
+		FileName:           "doc.txt",
+	})
+	require.NoError(t, err)
+	for version, data := range map[int]string{2: "version two", 3: "version three"} {
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            version,
+		})
+		require.NoError(t, err)
+		require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: object.ID,
+			Reader:   strings.NewReader(data),
+		}))
+	}
+
+	download := func(t *testing.T) (string, *simplecontent.DownloadSource) {
+		t.Helper()
+		reader, source, err := svc.DownloadContentWithSource(ctx, content.ID)
+		require.NoError(t, err)
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data), source
+	}
+	data, source := download(t)
+	require.Equal(t, "version three", data)
+	require.Equal(t, 3, source.Version)
+
+	restored, err := storageSvc.RestoreObjectVersion(ctx, content.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, restored.Version)
+	assert.Equal(t, string(simplecontent.ObjectStatusUploaded), restored.Status)
+	assert.Equal(t, "doc.txt", restored.FileName)
+
+	// The restored data is served under the new version
+	data, source = download(t)
+	assert.Equal(t, "version one", data)
+	assert.Equal(t, restored.ID, source.ObjectID)
+	assert.Equal(t, 4, source.Version)
+
+	// History is kept
+	objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+	require.NoError(t, err)
+	require.Len(t, objects, 4)
+	for i, object := range objects {
+		assert.Equal(t, 4-i, object.Version)
+	}
+	reader, err := storageSvc.DownloadObject(ctx, objects[1].ID)
+	require.NoError(t, err)
+	previous, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "version three", string(previous))
+
+	t.Run("MissingVersion", func(t *testing.T) {
+		_, err := storageSvc.RestoreObjectVersion(ctx, content.ID, 9)
+		assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+	})
+
+	t.Run("VersionNotUploaded", func(t *testing.T) {
+		_, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            5,
+		})
+		require.NoError(t, err)
+		_, err = storageSvc.RestoreObjectVersion(ctx, content.ID, 5)
+		assert.ErrorIs(t, err, simplecontent.ErrObjectNotReady)
+	})
+}
+
+func TestListObjects(t *testing.T) {
+	ctx := context.Background()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithBlobStore("s3", memorystorage.New()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Listed Objects",
+	})
+	require.NoError(t, err)
+
+	create := func(backend string, version int, upload bool) *simplecontent.Object {
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: backend,
+			Version:            version,
+		})
+		require.NoError(t, err)
+		if upload {
+			require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+				ObjectID: object.ID,
+				Reader:   strings.NewReader("data"),
+			}))
+		}
+		return object
+	}
+	s3Uploaded := create("s3", 1, true)
+	create("s3", 2, false)
+	memoryUploaded := create("memory", 3, true)
+
+	t.Run("ByStatus", func(t *testing.T) {
+		objects, err := svc.ListObjects(ctx, simplecontent.ListObjectsRequest{
+			ContentID: content.ID,
+			Status:    simplecontent.ObjectStatusUploaded,
+		})
+		require.NoError(t, err)
+		require.Len(t, objects, 2)
+		assert.Equal(t, memoryUploaded.ID, objects[0].ID)
+		assert.Equal(t, s3Uploaded.ID, objects[1].ID)
+	})
+
+	t.Run("ByStatusAndBackend", func(t *testing.T) {
+		objects, err := svc.ListObjects(ctx, simplecontent.ListObjectsRequest{
+			ContentID: content.ID,
+			Status:    simplecontent.ObjectStatusUploaded,
+			Backend:   "s3",
+		})
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		assert.Equal(t, s3Uploaded.ID, objects[0].ID)
+	})
+
+	t.Run("ByBackend", func(t *testing.T) {
+		objects, err := svc.ListObjects(ctx, simplecontent.ListObjectsRequest{
+			ContentID: content.ID,
+			Backend:   "s3",
+		})
+		require.NoError(t, err)
+		assert.Len(t, objects, 2)
+	})
+
+	t.Run("Paginated", func(t *testing.T) {
+		objects, err := svc.ListObjects(ctx, simplecontent.ListObjectsRequest{
+			ContentID: content.ID,
+			Limit:     2,
+			Offset:    1,
+		})
+		require.NoError(t, err)
+		require.Len(t, objects, 2)
+		assert.Equal(t, 2, objects[0].Version)
+	})
+}
+
+func TestUnknownStorageBackend(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("s3", memorystorage.New()),
+		simplecontent.WithBlobStore("fs", memorystorage.New()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	owner, tenant := uuid.New(), uuid.New()
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  owner,
+		TenantID: tenant,
+		Name:     "Unknown Backend",
+	})
+	require.NoError(t, err)
+
+	t.Run("CreateObject", func(t *testing.T) {
+		_, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "gcs",
+		})
+		require.ErrorIs(t, err, simplecontent.ErrStorageBackendNotFound)
+
+		var backendErr *simplecontent.BackendNotFoundError
+		require.ErrorAs(t, err, &backendErr)
+		assert.Equal(t, "gcs", backendErr.Name)
+		assert.Equal(t, []string{"fs", "s3"}, backendErr.Available)
+		assert.Contains(t, err.Error(), "storage backend not found: gcs (available: fs, s3)")
+
+		var contentErr *simplecontent.ContentError
+		require.ErrorAs(t, err, &contentErr)
+		assert.Equal(t, http.StatusBadRequest, contentErr.HTTPStatus())
+	})
+
+	t.Run("UploadContentCreatesNothing", func(t *testing.T) {
+		_, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:            owner,
+			TenantID:           tenant,
+			Name:               "Never Stored",
+			StorageBackendName: "gcs",
+			Reader:             strings.NewReader("data"),
+		})
+		require.ErrorIs(t, err, simplecontent.ErrStorageBackendNotFound)
+		assert.Contains(t, err.Error(), "available: fs, s3")
+
+		contents, err := svc.ListContent(ctx, simplecontent.ListContentRequest{OwnerID: owner, TenantID: tenant})
+		require.NoError(t, err)
+		assert.Len(t, contents, 1, "only the content created by the test should exist")
+	})
+
+	t.Run("UploadDerivedContent", func(t *testing.T) {
+		parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:            owner,
+			TenantID:           tenant,
+			Name:               "Parent",
+			StorageBackendName: "fs",
+			Reader:             strings.NewReader("parent"),
+		})
+		require.NoError(t, err)
+
+		_, err = svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:           parent.ID,
+			OwnerID:            owner,
+			TenantID:           tenant,
+			DerivationType:     "thumbnail",
+			Variant:            "thumbnail_256",
+			StorageBackendName: "gcs",
+			Reader:             strings.NewReader("thumb"),
+		})
+		require.ErrorIs(t, err, simplecontent.ErrStorageBackendNotFound)
+
+		derived, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parent.ID))
+		require.NoError(t, err)
+		assert.Empty(t, derived)
+	})
+
+	t.Run("UploadObjectForContent", func(t *testing.T) {
+		_, err := svc.UploadObjectForContent(ctx, simplecontent.UploadObjectForContentRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "gcs",
+			Reader:             strings.NewReader("data"),
+		})
+		require.ErrorIs(t, err, simplecontent.ErrStorageBackendNotFound)
+
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Empty(t, objects)
+	})
+}
+
+func TestRecoverIncompleteUploads(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, grace time.Duration) (simplecontent.Service, simplecontent.StorageService, simplecontent.Repository, *memorystorage.Backend) {
+		repo := memory.New()
+		store := memorystorage.New().(*memorystorage.Backend)
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(repo),
+			simplecontent.WithBlobStore("memory", store),
+			simplecontent.WithUploadIntents(grace),
+		)
+		require.NoError(t, err)
+		return svc, svc.(simplecontent.StorageService), repo, store
+	}
+
+	// crashedUpload leaves an object as a crash during upload would: the object row and
+	// its intent exist, the status is still created
+	crashedUpload := func(t *testing.T, svc simplecontent.Service, storageSvc simplecontent.StorageService, repo simplecontent.Repository) *simplecontent.Object {
+		content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Crashed Upload",
+		})
+		require.NoError(t, err)
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            1,
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo.CreateUploadIntent(ctx, &simplecontent.UploadIntent{
+			ObjectID:           object.ID,
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			ObjectKey:          object.ObjectKey,
+			CreatedAt:          time.Now().UTC().Add(-time.Minute),
+		}))
+		return object
+	}
+
+	pendingIntents := func(t *testing.T, repo simplecontent.Repository) []*simplecontent.UploadIntent {
+		intents, err := repo.ListUploadIntents(ctx, time.Now().UTC().Add(time.Hour))
+		require.NoError(t, err)
+		return intents
+	}
+
+	t.Run("BlobWrittenStatusNotUpdated", func(t *testing.T) {
+		svc, storageSvc, repo, store := setup(t, 0)
+		object := crashedUpload(t, svc, storageSvc, repo)
+		require.NoError(t, store.Upload(ctx, object.ObjectKey, strings.NewReader("written before the crash")))
+
+		report, err := storageSvc.RecoverIncompleteUploads(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{object.ID}, report.Finalized)
+		assert.Empty(t, report.Failed)
+
+		recovered, err := storageSvc.GetObject(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Equal(t, string(simplecontent.ObjectStatusUploaded), recovered.Status)
+		assert.Empty(t, pendingIntents(t, repo))
+	})
+
+	t.Run("BlobNeverWritten", func(t *testing.T) {
+		svc, storageSvc, repo, _ := setup(t, 0)
+		object := crashedUpload(t, svc, storageSvc, repo)
+
+		report, err := storageSvc.RecoverIncompleteUploads(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{object.ID}, report.Failed)
+
+		recovered, err := storageSvc.GetObject(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Equal(t, string(simplecontent.ObjectStatusFailed), recovered.Status)
+		assert.Empty(t, pendingIntents(t, repo))
+	})
+
+	t.Run("DeletedObjectBlobRemoved", func(t *testing.T) {
+		svc, storageSvc, repo, store := setup(t, 0)
+		object := crashedUpload(t, svc, storageSvc, repo)
+		require.NoError(t, store.Upload(ctx, object.ObjectKey, strings.NewReader("orphan")))
+		require.NoError(t, repo.DeleteObject(ctx, object.ID))
+
+		report, err := storageSvc.RecoverIncompleteUploads(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{object.ID}, report.Cleared)
+
+		_, err = store.GetObjectMeta(ctx, object.ObjectKey)
+		assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+	})
+
+	t.Run("RecentIntentsSkipped", func(t *testing.T) {
+		svc, storageSvc, repo, _ := setup(t, time.Hour)
+		object := crashedUpload(t, svc, storageSvc, repo)
+
+		report, err := storageSvc.RecoverIncompleteUploads(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, report.Failed)
+
+		unchanged, err := storageSvc.GetObject(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Equal(t, string(simplecontent.ObjectStatusCreated), unchanged.Status)
+		assert.Len(t, pendingIntents(t, repo), 1)
+	})
+
+	t.Run("IntentRecordedDuringUpload", func(t *testing.T) {
+		svc, _, repo, _ := setup(t, 0)
+		source := &blockingReader{
+			started: make(chan struct{}),
+			release: make(chan struct{}),
+			reader:  strings.NewReader("in flight"),
+		}
+		done := make(chan error, 1)
+		go func() {
+			_, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+				OwnerID:  uuid.New(),
+				TenantID: uuid.New(),
+				Name:     "In Flight",
+				Reader:   source,
+			})
+			done <- err
+		}()
+
+		<-source.started
+		assert.Len(t, pendingIntents(t, repo), 1)
+		close(source.release)
+		require.NoError(t, <-done)
+		assert.Empty(t, pendingIntents(t, repo))
+	})
+
+	t.Run("CompletedUploadsLeaveNoIntent", func(t *testing.T) {
+		svc, storageSvc, repo, _ := setup(t, 0)
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Completed",
+			Reader:   strings.NewReader("data"),
+		})
+		require.NoError(t, err)
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            2,
+		})
+		require.NoError(t, err)
+		require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+			ObjectID: object.ID,
+			Reader:   strings.NewReader("more data"),
+		}))
+
+		assert.Empty(t, pendingIntents(t, repo))
+	})
+
+	t.Run("StatusUpdateFailureKeepsIntent", func(t *testing.T) {
+		repo := &uploadedStatusFailingRepository{Repository: memory.New(), fail: true}
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(repo),
+			simplecontent.WithBlobStore("memory", memorystorage.New()),
+			simplecontent.WithUploadIntents(0),
+		)
+		require.NoError(t, err)
+		storageSvc := svc.(simplecontent.StorageService)
+
+		_, err = svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Unrecorded",
+			Reader:   strings.NewReader("stored but not recorded"),
+		})
+		require.Error(t, err)
+		intents := pendingIntents(t, repo)
+		require.Len(t, intents, 1, "the failed upload must stay reconcilable")
+
+		// Once the repository recovers, the upload is finalized from its intent
+		repo.fail = false
+		report, err := storageSvc.RecoverIncompleteUploads(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{intents[0].ObjectID}, report.Finalized)
+		assert.Empty(t, pendingIntents(t, repo))
+	})
+}
+
+// uploadedStatusFailingRepository fails object updates that mark an object uploaded while
+// fail is set
+type uploadedStatusFailingRepository struct {
+	simplecontent.Repository
+	fail bool
+}
+
+func (r *uploadedStatusFailingRepository) UpdateObject(ctx context.Context, object *simplecontent.Object) error {
+	if r.fail && object.Status == string(simplecontent.ObjectStatusUploaded) {
+		return errors.New("connection reset")
+	}
+	return r.Repository.UpdateObject(ctx, object)
+}
+
+func TestDerivationTypeTaxonomy(t *testing.T) {
+	ctx := context.Background()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithDerivationTaxonomy(map[string][]string{
+			"thumbnail": {"thumbnail_256", "thumbnail_512"},
+			"preview":   nil,
+		}),
+	)
+	require.NoError(t, err)
+
+	parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:            uuid.New(),
+		TenantID:           uuid.New(),
+		Name:               "Photo",
+		StorageBackendName: "memory",
+		Reader:             strings.NewReader("image data"),
+		FileName:           "photo.jpg",
+	})
+	require.NoError(t, err)
+
+	derive := func(derivationType, variant string) (*simplecontent.Content, error) {
+		return svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+			ParentID:       parent.ID,
+			OwnerID:        parent.OwnerID,
+			TenantID:       parent.TenantID,
+			DerivationType: derivationType,
+			Variant:        variant,
+		})
+	}
+
+	t.Run("RegisteredVariant", func(t *testing.T) {
+		derived, err := derive("thumbnail", "thumbnail_256")
+		require.NoError(t, err)
+		assert.Equal(t, "thumbnail", derived.DerivationType)
+
+		// The type is inferred from the variant before validation
+		_, err = derive("", "Thumbnail_512")
+		require.NoError(t, err)
+	})
+
+	t.Run("UnknownVariant", func(t *testing.T) {
+		_, err := derive("thumbnail", "thumbnial_256")
+		require.ErrorIs(t, err, simplecontent.ErrUnknownVariant)
+
+		var contentErr *simplecontent.ContentError
+		require.ErrorAs(t, err, &contentErr)
+		assert.Equal(t, http.StatusBadRequest, contentErr.HTTPStatus())
+	})
+
+	t.Run("UnknownDerivationType", func(t *testing.T) {
+		_, err := derive("thumbnial", "")
+		require.ErrorIs(t, err, simplecontent.ErrUnknownDerivationType)
+
+		_, err = svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:           parent.ID,
+			Variant:            "transcode_720p",
+			StorageBackendName: "memory",
+			Reader:             strings.NewReader("video data"),
+		})
+		require.ErrorIs(t, err, simplecontent.ErrUnknownDerivationType)
+	})
+
+	t.Run("TypeWithoutVariantList", func(t *testing.T) {
+		_, err := derive("preview", "preview_anything")
+		require.NoError(t, err)
+	})
+
+	t.Run("PermissiveByDefault", func(t *testing.T) {
+		plain := setupTestService(t)
+		original, err := plain.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:            uuid.New(),
+			TenantID:           uuid.New(),
+			Name:               "Photo",
+			StorageBackendName: "memory",
+			Reader:             strings.NewReader("image data"),
+		})
+		require.NoError(t, err)
+
+		_, err = plain.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+			ParentID:       original.ID,
+			OwnerID:        original.OwnerID,
+			TenantID:       original.TenantID,
+			DerivationType: "thumbnial",
+			Variant:        "thumbnial_256",
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestListContentPage(t *testing.T) {
+	svc := setupTestService(t)
+	ctx := context.Background()
+
+	ownerID, tenantID := uuid.New(), uuid.New()
+	for i := 0; i < 5; i++ {
+		_, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:  ownerID,
+			TenantID: tenantID,
+			Name:     fmt.Sprintf("Content %d", i),
+		})
+		require.NoError(t, err)
+	}
+	// Content of another owner is neither listed nor counted
+	_, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: uuid.New(), TenantID: tenantID, Name: "Other"})
+	require.NoError(t, err)
+
+	t.Run("FirstPageWithTotal", func(t *testing.T) {
+		page, err := svc.ListContentPage(ctx, simplecontent.ListContentRequest{
+			OwnerID: ownerID, TenantID: tenantID, Limit: 2,
+		}, simplecontent.WithTotalCount())
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		require.NotNil(t, page.Total)
+		assert.Equal(t, int64(5), *page.Total)
+		assert.True(t, page.HasMore)
+	})
+
+	t.Run("LastPage", func(t *testing.T) {
+		page, err := svc.ListContentPage(ctx, simplecontent.ListContentRequest{
+			OwnerID: ownerID, TenantID: tenantID, Limit: 2, Offset: 4,
+		}, simplecontent.WithTotalCount())
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, int64(5), *page.Total)
+		assert.False(t, page.HasMore)
+	})
+
+	t.Run("WithoutTotal", func(t *testing.T) {
+		page, err := svc.ListContentPage(ctx, simplecontent.ListContentRequest{
+			OwnerID: ownerID, TenantID: tenantID, Limit: 5,
+		})
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 5)
+		assert.Nil(t, page.Total)
+		assert.False(t, page.HasMore)
+	})
+
+	t.Run("TotalRespectsFilters", func(t *testing.T) {
+		future := time.Now().Add(time.Hour)
+		page, err := svc.ListContentPage(ctx, simplecontent.ListContentRequest{
+			OwnerID: ownerID, TenantID: tenantID, CreatedAfter: &future, Limit: 2,
+		}, simplecontent.WithTotalCount())
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+		assert.Equal(t, int64(0), *page.Total)
+	})
+
+	t.Run("ListContentHonorsLimit", func(t *testing.T) {
+		contents, err := svc.ListContent(ctx, simplecontent.ListContentRequest{OwnerID: ownerID, TenantID: tenantID, Limit: 3})
+		require.NoError(t, err)
+		assert.Len(t, contents, 3)
+	})
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	store := memorystorage.New()
+
+	// Seed data through a writable service sharing the repository and backend
+	writer, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", store),
+	)
+	require.NoError(t, err)
+	content, err := writer.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "Replicated",
+		DocumentType: "text/plain",
+		Reader:       strings.NewReader("replicated data"),
+		FileName:     "replicated.txt",
+	})
+	require.NoError(t, err)
+
+	reader, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", store),
+		simplecontent.WithReadOnly(),
+	)
+	require.NoError(t, err)
+
+	t.Run("WritesRejected", func(t *testing.T) {
+		_, err := reader.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: uuid.New(), TenantID: uuid.New(), Name: "New"})
+		assert.ErrorIs(t, err, simplecontent.ErrReadOnly)
+
+		_, err = reader.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "New",
+			Reader:   strings.NewReader("data"),
+		})
+		assert.ErrorIs(t, err, simplecontent.ErrReadOnly)
+
+		assert.ErrorIs(t, reader.UpdateContent(ctx, simplecontent.UpdateContentRequest{Content: content}), simplecontent.ErrReadOnly)
+		assert.ErrorIs(t, reader.DeleteContent(ctx, content.ID), simplecontent.ErrReadOnly)
+		assert.ErrorIs(t, reader.SetContentMetadata(ctx, simplecontent.SetContentMetadataRequest{ContentID: content.ID}), simplecontent.ErrReadOnly)
+
+		_, err = reader.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:       content.ID,
+			DerivationType: "thumbnail",
+			Variant:        "thumbnail_64",
+			Reader:         strings.NewReader("thumb"),
+		})
+		assert.ErrorIs(t, err, simplecontent.ErrReadOnly)
+
+		// Nothing was written
+		got, err := writer.GetContent(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Replicated", got.Name)
+		assert.Nil(t, got.DeletedAt)
+	})
+
+	t.Run("ReadsSucceed", func(t *testing.T) {
+		got, err := reader.GetContent(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, content.ID, got.ID)
+
+		rc, err := reader.DownloadContent(ctx, content.ID)
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "replicated data", string(data))
+
+		details, err := reader.GetContentDetails(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, content.ID.String(), details.ID)
+
+		contents, err := reader.ListContent(ctx, simplecontent.ListContentRequest{OwnerID: content.OwnerID, TenantID: content.TenantID})
+		require.NoError(t, err)
+		assert.Len(t, contents, 1)
+	})
+}
+
+func TestMaxDerivationDepth(t *testing.T) {
+	ctx := context.Background()
+	newService := func(t *testing.T, opts ...simplecontent.Option) simplecontent.Service {
+		svc, err := simplecontent.New(append([]simplecontent.Option{
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("memory", memorystorage.New()),
+		}, opts...)...)
+		require.NoError(t, err)
+		return svc
+	}
+	upload := func(t *testing.T, svc simplecontent.Service) *simplecontent.Content {
+		original, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Photo",
+			Reader:   strings.NewReader("image data"),
+			FileName: "photo.jpg",
+		})
+		require.NoError(t, err)
+		return original
+	}
+	// deriveFrom uploads a thumbnail of parent, so it can be derived from in turn
+	deriveFrom := func(svc simplecontent.Service, parent *simplecontent.Content) (*simplecontent.Content, error) {
+		return svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:       parent.ID,
+			OwnerID:        parent.OwnerID,
+			TenantID:       parent.TenantID,
+			DerivationType: "thumbnail",
+			Variant:        "thumbnail_128",
+			Reader:         strings.NewReader("thumbnail data"),
+			FileName:       "thumb.jpg",
+		})
+	}
+
+	t.Run("UpToAndBeyondConfiguredDepth", func(t *testing.T) {
+		svc := newService(t, simplecontent.WithMaxDerivationDepth(2))
+		level0 := upload(t, svc)
+
+		level1, err := deriveFrom(svc, level0)
+		require.NoError(t, err)
+		level2, err := deriveFrom(svc, level1)
+		require.NoError(t, err)
+
+		_, err = deriveFrom(svc, level2)
+		assert.ErrorIs(t, err, simplecontent.ErrMaxDerivationDepth)
+		_, err = svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+			ParentID:       level2.ID,
+			OwnerID:        level2.OwnerID,
+			TenantID:       level2.TenantID,
+			DerivationType: "thumbnail",
+			Variant:        "thumbnail_64",
+		})
+		assert.ErrorIs(t, err, simplecontent.ErrMaxDerivationDepth)
+
+		// Siblings at an allowed depth are still accepted
+		_, err = svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:       level1.ID,
+			OwnerID:        level1.OwnerID,
+			TenantID:       level1.TenantID,
+			DerivationType: "thumbnail",
+			Variant:        "thumbnail_256",
+			Reader:         strings.NewReader("thumbnail data"),
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("OnlyOriginals", func(t *testing.T) {
+		svc := newService(t, simplecontent.WithMaxDerivationDepth(1))
+		thumbnail, err := deriveFrom(svc, upload(t, svc))
+		require.NoError(t, err)
+
+		_, err = deriveFrom(svc, thumbnail)
+		assert.ErrorIs(t, err, simplecontent.ErrMaxDerivationDepth)
+	})
+
+	t.Run("DefaultDepth", func(t *testing.T) {
+		svc := newService(t)
+		parent := upload(t, svc)
+		for i := 0; i < 5; i++ {
+			derived, err := deriveFrom(svc, parent)
+			require.NoError(t, err, "level %d", i+1)
+			parent = derived
+		}
+		_, err := deriveFrom(svc, parent)
+		assert.ErrorIs(t, err, simplecontent.ErrMaxDerivationDepth)
+	})
+}
+
+func TestGetContentLineage(t *testing.T) {
+	ctx := context.Background()
+	svc := setupTestService(t)
+
+	original, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Photo",
+		Reader:   strings.NewReader("image data"),
+		FileName: "photo.jpg",
+	})
+	require.NoError(t, err)
+	deriveFrom := func(parent *simplecontent.Content, variant string) *simplecontent.Content {
+		derived, err := svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:       parent.ID,
+			OwnerID:        parent.OwnerID,
+			TenantID:       parent.TenantID,
+			DerivationType: "thumbnail",
+			Variant:        variant,
+			Reader:         strings.NewReader("thumbnail data"),
+			FileName:       "thumb.jpg",
+		})
+		require.NoError(t, err)
+		return derived
+	}
+	child := deriveFrom(original, "thumbnail_512")
+	grandchild := deriveFrom(child, "thumbnail_128")
+
+	lineage, err := svc.GetContentLineage(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.Len(t, lineage, 2)
+	assert.Equal(t, child.ID, lineage[0].ID)
+	assert.Equal(t, original.ID, lineage[1].ID)
+
+	lineage, err = svc.GetContentLineage(ctx, original.ID)
+	require.NoError(t, err)
+	assert.Empty(t, lineage)
+
+	_, err = svc.GetContentLineage(ctx, uuid.New())
+	assert.Error(t, err)
+}
+
+// derivedLookupFailingRepository fails derived relationship lookups once failLookups is set
+type derivedLookupFailingRepository struct {
+	simplecontent.Repository
+	failLookups bool
+}
+
+func (r *derivedLookupFailingRepository) GetDerivedRelationshipByContentID(ctx context.Context, contentID uuid.UUID) (*simplecontent.DerivedContent, error) {
+	if r.failLookups {
+		return nil, errors.New("connection reset")
+	}
+	return r.Repository.GetDerivedRelationshipByContentID(ctx, contentID)
+}
+
+func TestGetContentLineageLookupError(t *testing.T) {
+	ctx := context.Background()
+	repo := &derivedLookupFailingRepository{Repository: memory.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+	)
+	require.NoError(t, err)
+
+	original, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Photo",
+		Reader:   strings.NewReader("image data"),
+		FileName: "photo.jpg",
+	})
+	require.NoError(t, err)
+	derived, err := svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+		ParentID:       original.ID,
+		OwnerID:        original.OwnerID,
+		TenantID:       original.TenantID,
+		DerivationType: "thumbnail",
+		Variant:        "thumbnail_128",
+		Reader:         strings.NewReader("thumbnail data"),
+		FileName:       "thumb.jpg",
+	})
+	require.NoError(t, err)
+
+	// A failed lookup must not be read as reaching the root
+	repo.failLookups = true
+	lineage, err := svc.GetContentLineage(ctx, derived.ID)
+	assert.Error(t, err)
+	assert.Nil(t, lineage)
+}
+
+func TestDeleteObjectWithOptions(t *testing.T) {
+	ctx := context.Background()
+	store := memorystorage.New()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", store),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	upload := func(t *testing.T) *simplecontent.Object {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "Report",
+			Reader:   strings.NewReader("report data"),
+			FileName: "report.txt",
+		})
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return objects[0]
+	}
+	blobExists := func(t *testing.T, key string) bool {
+		exists, err := store.(simplecontent.ObjectExistsChecker).ObjectExists(ctx, key)
+		require.NoError(t, err)
+		return exists
+	}
+
+	t.Run("DefaultDeletesBlob", func(t *testing.T) {
+		object := upload(t)
+		require.True(t, blobExists(t, object.ObjectKey))
+
+		require.NoError(t, storageSvc.DeleteObject(ctx, object.ID))
+		_, err := storageSvc.GetObject(ctx, object.ID)
+		assert.Error(t, err)
+		assert.False(t, blobExists(t, object.ObjectKey))
+	})
+
+	t.Run("KeepBlobDetachesOnly", func(t *testing.T) {
+		object := upload(t)
+
+		require.NoError(t, storageSvc.DeleteObjectWithOptions(ctx, object.ID, simplecontent.DeleteObjectOptions{KeepBlob: true}))
+		_, err := storageSvc.GetObject(ctx, object.ID)
+		assert.Error(t, err)
+		assert.True(t, blobExists(t, object.ObjectKey))
+	})
+
+	t.Run("MissingObject", func(t *testing.T) {
+		err := storageSvc.DeleteObject(ctx, uuid.New())
+		assert.Error(t, err)
+	})
+}
+
+// halvingTransformer is a fake image scaler: its preview keeps every other byte
+type halvingTransformer struct {
+	calls []string
+}
+
+func (h *halvingTransformer) Transform(ctx context.Context, src io.Reader, mimeType string) (io.ReadCloser, string, error) {
+	h.calls = append(h.calls, mimeType)
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, "", err
+	}
+	scaled := make([]byte, 0, len(data)/2)
+	for i := 0; i < len(data); i += 2 {
+		scaled = append(scaled, data[i])
+	}
+	return io.NopCloser(strings.NewReader(string(scaled))), "image/jpeg", nil
+}
+
+func TestPreviewTransformers(t *testing.T) {
+	ctx := context.Background()
+	transformer := &halvingTransformer{}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithPreviewTransformer("image/*", transformer),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	upload := func(t *testing.T, data, documentType, fileName string) *simplecontent.Object {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         fileName,
+			DocumentType: documentType,
+			Reader:       strings.NewReader(data),
+			FileName:     fileName,
+		})
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return objects[0]
+	}
+	preview := func(t *testing.T, object *simplecontent.Object) (string, *simplecontent.ObjectMeta) {
+		reader, meta, err := storageSvc.DownloadObjectPreview(ctx, object.ID)
+		require.NoError(t, err)
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data), meta
+	}
+
+	t.Run("ImageIsDownsized", func(t *testing.T) {
+		object := upload(t, "abcdefgh", "image/png", "photo.png")
+
+		data, meta := preview(t, object)
+		assert.Equal(t, "aceg", data)
+		assert.Equal(t, "image/jpeg", meta.ContentType)
+		assert.Equal(t, int64(-1), meta.Size)
+		assert.Equal(t, []string{"image/png"}, transformer.calls)
+
+		// The preview URL points at the service, which serves the transformed preview
+		previewURL, err := storageSvc.GetPreviewURL(ctx, object.ID)
+		require.NoError(t, err)
+		assert.Contains(t, previewURL, object.ContentID.String())
+	})
+
+	t.Run("PDFPassesThrough", func(t *testing.T) {
+		transformer.calls = nil
+		object := upload(t, "%PDF-1.4 document", "application/pdf", "doc.pdf")
+
+		data, meta := preview(t, object)
+		assert.Equal(t, "%PDF-1.4 document", data)
+		assert.Equal(t, int64(len(data)), meta.Size)
+		assert.Empty(t, transformer.calls)
+
+		// Without a transformer the backend's own preview URL is used, which memory cannot presign
+		_, err := storageSvc.GetPreviewURL(ctx, object.ID)
+		assert.ErrorIs(t, err, simplecontent.ErrPresignNotSupported)
+	})
+}
+
+func TestContentETags(t *testing.T) {
+	ctx := context.Background()
+	data := "identical payload on every backend"
+	sum := sha256.Sum256([]byte(data))
+	want := simplecontent.ContentETag(sum[:])
+
+	fsStore, err := fsstorage.New(fsstorage.Config{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	backends := map[string]simplecontent.BlobStore{
+		"memory": memorystorage.New(),
+		"fs":     fsStore,
+	}
+
+	etags := make(map[string]string)
+	for name, store := range backends {
+		t.Run(name, func(t *testing.T) {
+			svc, err := simplecontent.New(
+				simplecontent.WithRepository(memory.New()),
+				simplecontent.WithBlobStore(name, store),
+				simplecontent.WithContentETags(),
+			)
+			require.NoError(t, err)
+			storageSvc := svc.(simplecontent.StorageService)
+
+			contentETag := func(t *testing.T, objectID uuid.UUID) string {
+				md, err := storageSvc.GetObjectMetadata(ctx, objectID)
+				require.NoError(t, err)
+				etag, _ := md[simplecontent.MetaContentETag].(string)
+				return etag
+			}
+
+			content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+				OwnerID:      uuid.New(),
+				TenantID:     uuid.New(),
+				Name:         "etag " + name,
+				DocumentType: "text/plain",
+				Reader:       strings.NewReader(data),
+				FileName:     "etag.txt",
+			})
+			require.NoError(t, err)
+			objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+			require.NoError(t, err)
+			require.Len(t, objects, 1)
+			etags[name] = contentETag(t, objects[0].ID)
+			assert.Equal(t, want, etags[name])
+
+			// A non-seekable source hashes the same
+			object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+				ContentID:          content.ID,
+				StorageBackendName: name,
+				Version:            2,
+			})
+			require.NoError(t, err)
+			require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+				ObjectID: object.ID,
+				Reader:   io.MultiReader(strings.NewReader(data[:10]), strings.NewReader(data[10:])),
+			}))
+			assert.Equal(t, want, contentETag(t, object.ID))
+
+			// Resyncing from unchanged storage keeps the ETag
+			_, err = storageSvc.UpdateObjectMetaFromStorage(ctx, object.ID)
+			require.NoError(t, err)
+			assert.Equal(t, want, contentETag(t, object.ID))
+		})
+	}
+	assert.Equal(t, etags["memory"], etags["fs"])
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		svc := setupTestService(t)
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Name:     "no etag",
+			Reader:   strings.NewReader(data),
+		})
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		md, err := svc.(simplecontent.StorageService).GetObjectMetadata(ctx, objects[0].ID)
+		require.NoError(t, err)
+		assert.NotContains(t, md, simplecontent.MetaContentETag)
+	})
+}
+
+func TestImportContentFromURL(t *testing.T) {
+	ctx := context.Background()
+	csv := "id,name\n1,alpha\n2,beta\n"
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/exports/latest":
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="report.csv"`)
+			_, _ = io.WriteString(w, csv)
+		case "/files/notes.txt":
+			_, _ = io.WriteString(w, "plain notes")
+		case "/redirect":
+			http.Redirect(w, r, "http://blocked.internal/secret", http.StatusFound)
+		case "/large":
+			// No Content-Length, so only the streaming limit can catch it
+			w.(http.Flusher).Flush()
+			_, _ = io.WriteString(w, strings.Repeat("x", 64))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer source.Close()
+
+	newService := func(t *testing.T, policy simplecontent.URLImportPolicy) simplecontent.Service {
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("memory", memorystorage.New()),
+			simplecontent.WithURLImportPolicy(policy),
+		)
+		require.NoError(t, err)
+		return svc
+	}
+	local := simplecontent.URLImportPolicy{AllowedHosts: []string{"127.0.0.1"}, AllowPrivateNetworks: true}
+	importURL := func(svc simplecontent.Service, path string) (*simplecontent.Content, error) {
+		return svc.ImportContentFromURL(ctx, simplecontent.ImportFromURLRequest{
+			URL:      source.URL + path,
+			OwnerID:  uuid.New(),
+			TenantID: uuid.New(),
+			Tags:     []string{"imported"},
+		})
+	}
+
+	t.Run("HeadersDescribeContent", func(t *testing.T) {
+		svc := newService(t, local)
+		content, err := importURL(svc, "/exports/latest")
+		require.NoError(t, err)
+		assert.Equal(t, "report.csv", content.Name)
+		assert.Equal(t, "text/csv", content.DocumentType)
+		assert.Equal(t, string(simplecontent.ContentStatusUploaded), content.Status)
+
+		reader, err := svc.DownloadContent(ctx, content.ID)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		assert.Equal(t, csv, string(data))
+
+		metadata, err := svc.GetContentMetadata(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "report.csv", metadata.FileName)
+		assert.Equal(t, int64(len(csv)), metadata.FileSize)
+		assert.Equal(t, []string{"imported"}, metadata.Tags)
+		assert.Equal(t, source.URL+"/exports/latest", metadata.Metadata["source_url"])
+	})
+
+	t.Run("FileNameFromPath", func(t *testing.T) {
+		content, err := importURL(newService(t, local), "/files/notes.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "notes.txt", content.Name)
+		assert.Equal(t, "text/plain", content.DocumentType)
+	})
+
+	t.Run("PrivateAddressDeniedByDefault", func(t *testing.T) {
+		_, err := importURL(newService(t, simplecontent.URLImportPolicy{}), "/files/notes.txt")
+		assert.ErrorIs(t, err, simplecontent.ErrURLImportDenied)
+	})
+
+	t.Run("HostPolicy", func(t *testing.T) {
+		_, err := importURL(newService(t, simplecontent.URLImportPolicy{
+			AllowedHosts:         []string{"*.example.com"},
+			AllowPrivateNetworks: true,
+		}), "/files/notes.txt")
+		assert.ErrorIs(t, err, simplecontent.ErrURLImportDenied)
+
+		_, err = importURL(newService(t, simplecontent.URLImportPolicy{
+			DeniedHosts:          []string{"127.0.0.1"},
+			AllowPrivateNetworks: true,
+		}), "/files/notes.txt")
+		assert.ErrorIs(t, err, simplecontent.ErrURLImportDenied)
+
+		// Redirects are checked against the policy too
+		_, err = importURL(newService(t, local), "/redirect")
+		assert.ErrorIs(t, err, simplecontent.ErrURLImportDenied)
+
+		_, err = newService(t, local).ImportContentFromURL(ctx, simplecontent.ImportFromURLRequest{URL: "file:///etc/passwd"})
+		assert.ErrorIs(t, err, simplecontent.ErrURLImportDenied)
+	})
+
+	t.Run("SizeLimit", func(t *testing.T) {
+		limited := local
+		limited.MaxSize = 16
+		_, err := importURL(newService(t, limited), "/exports/latest")
+		assert.ErrorIs(t, err, simplecontent.ErrURLImportTooLarge)
+
+		_, err = importURL(newService(t, limited), "/large")
+		assert.ErrorIs(t, err, simplecontent.ErrURLImportTooLarge)
+	})
+
+	t.Run("FailedFetch", func(t *testing.T) {
+		_, err := importURL(newService(t, local), "/missing")
+		assert.ErrorIs(t, err, simplecontent.ErrURLImportFailed)
+	})
+}
+
+func TestKeyTemplate(t *testing.T) {
+	ctx := context.Background()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithKeyTemplate("{{.Year}}/{{.DocumentType}}/{{.FileName}}"),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	upload := func(t *testing.T, fileName string) *simplecontent.Object {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         "invoice",
+			DocumentType: "invoices",
+			Reader:       strings.NewReader("invoice data"),
+			FileName:     fileName,
+		})
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return objects[0]
+	}
+	year := time.Now().UTC().Format("2006")
+
+	first := upload(t, "../acme: 2024/03?.pdf")
+	assert.Equal(t, year+"/invoices/_acme__2024_03_.pdf", first.ObjectKey)
+
+	// The same file name again gets a unique key next to the first one
+	second := upload(t, "../acme: 2024/03?.pdf")
+	assert.NotEqual(t, first.ObjectKey, second.ObjectKey)
+	assert.Regexp(t, `^`+year+`/invoices/_acme__2024_03_-[0-9a-f]{8}\.pdf$`, second.ObjectKey)
+
+	// Objects created without an explicit key use the template as well
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "statement",
+		DocumentType: "statements",
+	})
+	require.NoError(t, err)
+	object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+		ContentID:          content.ID,
+		StorageBackendName: "memory",
+		Version:            1,
+		FileName:           "march.pdf",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, year+"/statements/march.pdf", object.ObjectKey)
+
+	_, err = simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithKeyTemplate("{{.Unknown}}"),
+	)
+	assert.Error(t, err)
+}
+
+func TestConditionalUpload(t *testing.T) {
+	ctx := context.Background()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithContentETags(),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "conditional",
+		DocumentType: "text/plain",
+		Reader:       strings.NewReader("v1"),
+		FileName:     "doc.txt",
+	})
+	require.NoError(t, err)
+	objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	objectID := objects[0].ID
+
+	etagOf := func(t *testing.T) string {
+		md, err := storageSvc.GetObjectMetadata(ctx, objectID)
+		require.NoError(t, err)
+		etag, _ := md[simplecontent.MetaContentETag].(string)
+		require.NotEmpty(t, etag)
+		return etag
+	}
+	read := func(t *testing.T) string {
+		rc, err := storageSvc.DownloadObject(ctx, objectID)
+		require.NoError(t, err)
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return string(data)
+	}
+	v1 := etagOf(t)
+
+	// A writer that saw v1 replaces it
+	require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+		ObjectID: objectID,
+		Reader:   strings.NewReader("v2"),
+		IfMatch:  v1,
+	}))
+	assert.Equal(t, "v2", read(t))
+	v2 := etagOf(t)
+	assert.NotEqual(t, v1, v2)
+
+	// Another writer that saw v1 is now stale
+	err = storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+		ObjectID: objectID,
+		Reader:   strings.NewReader("stale"),
+		IfMatch:  v1,
+	})
+	require.ErrorIs(t, err, simplecontent.ErrPreconditionFailed)
+	var objErr *simplecontent.ObjectError
+	require.ErrorAs(t, err, &objErr)
+	assert.Equal(t, http.StatusPreconditionFailed, objErr.HTTPStatus())
+	assert.Equal(t, "v2", read(t))
+
+	// Of two writers racing with the same ETag exactly one wins
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+				ObjectID: objectID,
+				Reader:   strings.NewReader(fmt.Sprintf("racer %d", i)),
+				IfMatch:  v2,
+			})
+		}(i)
+	}
+	wg.Wait()
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			require.ErrorIs(t, err, simplecontent.ErrPreconditionFailed)
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed)
+
+	// "*" only requires existing data
+	require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+		ObjectID: objectID,
+		Reader:   strings.NewReader("v4"),
+		IfMatch:  "*",
+	}))
+	fresh, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+		ContentID:          content.ID,
+		StorageBackendName: "memory",
+		Version:            2,
+	})
+	require.NoError(t, err)
+	err = storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{
+		ObjectID: fresh.ID,
+		Reader:   strings.NewReader("new"),
+		IfMatch:  "*",
+	})
+	require.ErrorIs(t, err, simplecontent.ErrPreconditionFailed)
+}
+
+func TestTenantBackends(t *testing.T) {
+	ctx := context.Background()
+	tenantA, tenantB := uuid.New(), uuid.New()
+	shared, dedicated := memorystorage.New(), memorystorage.New()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("shared", shared),
+		simplecontent.WithBlobStore("tenant-a", dedicated),
+		simplecontent.WithDefaultStorageBackend("shared"),
+		simplecontent.WithTenantBackends(map[uuid.UUID]string{tenantA: "tenant-a"}),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	upload := func(t *testing.T, tenantID uuid.UUID, data string) *simplecontent.Object {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     tenantID,
+			Name:         "routed",
+			DocumentType: "text/plain",
+			Reader:       strings.NewReader(data),
+			FileName:     "routed.txt",
+		})
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return objects[0]
+	}
+
+	objectA := upload(t, tenantA, "tenant a data")
+	assert.Equal(t, "tenant-a", objectA.StorageBackendName)
+	_, err = dedicated.GetObjectMeta(ctx, objectA.ObjectKey)
+	assert.NoError(t, err, "tenant A's data should be on its backend")
+	_, err = shared.GetObjectMeta(ctx, objectA.ObjectKey)
+	assert.Error(t, err)
+
+	objectB := upload(t, tenantB, "tenant b")
+	assert.Equal(t, "shared", objectB.StorageBackendName)
+	_, err = shared.GetObjectMeta(ctx, objectB.ObjectKey)
+	assert.NoError(t, err, "tenant B's data should be on the default backend")
+
+	// CreateObject without a backend follows the content's tenant; a named backend wins
+	object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{ContentID: objectA.ContentID, Version: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a", object.StorageBackendName)
+	object, err = storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{ContentID: objectA.ContentID, StorageBackendName: "shared", Version: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "shared", object.StorageBackendName)
+
+	// Usage is accounted to the backend the data landed on
+	stats := svc.Stats()
+	assert.Equal(t, int64(len("tenant a data")), stats.BytesUploadedByBackend["tenant-a"])
+	assert.Equal(t, int64(len("tenant b")), stats.BytesUploadedByBackend["shared"])
+
+	_, err = simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("shared", shared),
+		simplecontent.WithTenantBackends(map[uuid.UUID]string{tenantA: "missing"}),
+	)
+	assert.ErrorIs(t, err, simplecontent.ErrStorageBackendNotFound)
+}
+
+func TestDownloadDisposition(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, `attachment; filename="report.pdf"`, simplecontent.ContentDisposition(simplecontent.DispositionAttachment, "report.pdf"))
+	assert.Equal(t, `inline; filename="say \"hi\".txt"`, simplecontent.ContentDisposition(simplecontent.DispositionInline, `say "hi".txt`))
+	assert.Equal(t, `attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`, simplecontent.ContentDisposition(simplecontent.DispositionAttachment, "résumé.pdf"))
+
+	d, err := simplecontent.ParseDisposition(" Inline ")
+	require.NoError(t, err)
+	assert.Equal(t, simplecontent.DispositionInline, d)
+	_, err = simplecontent.ParseDisposition("embed")
+	assert.ErrorIs(t, err, simplecontent.ErrInvalidDisposition)
+
+	// Overrides apply on top of the endpoint default; without either nothing is set
+	assert.Equal(t, "", simplecontent.DispositionOptions{}.Header(simplecontent.DispositionAttachment, ""))
+	assert.Equal(t, `inline; filename="a.pdf"`, simplecontent.DispositionOptions{}.Header(simplecontent.DispositionInline, "a.pdf"))
+	assert.Equal(t, `inline; filename="b.pdf"`, simplecontent.DispositionOptions{Disposition: simplecontent.DispositionInline, FileName: "b.pdf"}.Header(simplecontent.DispositionAttachment, "a.pdf"))
+
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithURLStrategy(urlstrategy.NewContentBasedStrategy("/api/v1")),
+	)
+	require.NoError(t, err)
+	content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "report",
+		DocumentType: "application/pdf",
+		Reader:       strings.NewReader("%PDF"),
+		FileName:     "report.pdf",
+	})
+	require.NoError(t, err)
+
+	details, err := svc.GetContentDetails(ctx, content.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, details.Download, "disposition=")
+
+	details, err = svc.GetContentDetails(ctx, content.ID, simplecontent.WithDownloadDisposition(simplecontent.DispositionInline, "custom name.pdf"))
+	require.NoError(t, err)
+	assert.Contains(t, details.Download, "disposition=inline")
+	assert.Contains(t, details.Download, "filename=custom+name.pdf")
+}
+
+// countingDownloadStore counts the downloads served by the wrapped backend
+type countingDownloadStore struct {
+	simplecontent.BlobStore
+	downloads int
+}
+
+func (c *countingDownloadStore) Download(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	c.downloads++
+	return c.BlobStore.Download(ctx, objectKey)
+}
+
+func TestMigratingBlobStore(t *testing.T) {
+	ctx := context.Background()
+	oldStore := &countingDownloadStore{BlobStore: memorystorage.New()}
+	newStore := &countingDownloadStore{BlobStore: memorystorage.New()}
+	migrating := simplecontent.NewMigratingBlobStore(newStore, oldStore)
+
+	require.NoError(t, oldStore.UploadWithParams(ctx, strings.NewReader("legacy data"), simplecontent.UploadParams{ObjectKey: "legacy/key", MimeType: "text/plain"}))
+
+	read := func(t *testing.T) string {
+		rc, err := migrating.Download(ctx, "legacy/key")
+		require.NoError(t, err)
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	// The first read copies the object to the new backend
+	assert.Equal(t, "legacy data", read(t))
+	assert.Equal(t, 1, oldStore.downloads)
+	meta, err := newStore.GetObjectMeta(ctx, "legacy/key")
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", meta.ContentType)
+	assert.Equal(t, simplecontent.MigrationProgress{Migrated: 1, MigratedBytes: int64(len("legacy data"))}, migrating.Progress())
+
+	// The second read is served by the new backend alone
+	assert.Equal(t, "legacy data", read(t))
+	assert.Equal(t, 1, oldStore.downloads)
+	assert.Equal(t, int64(1), migrating.Progress().Migrated)
+
+	_, err = migrating.Download(ctx, "missing/key")
+	assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+
+	// Deleting removes the object from both backends so it cannot come back
+	require.NoError(t, migrating.Delete(ctx, "legacy/key"))
+	exists, err := migrating.ObjectExists(ctx, "legacy/key")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Content recorded on the new backend is served while its data is still on the old one
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("old", oldStore),
+		simplecontent.WithBlobStore("new", migrating),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+	content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:            uuid.New(),
+		TenantID:           uuid.New(),
+		Name:               "legacy",
+		DocumentType:       "text/plain",
+		StorageBackendName: "new",
+		Reader:             strings.NewReader("moved soon"),
+		FileName:           "legacy.txt",
+	})
+	require.NoError(t, err)
+	objects, err := storageSvc.GetObjectsByContentID(ctx, content.ID)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	// Simulate data written before the switch: only the old backend has it
+	require.NoError(t, oldStore.Upload(ctx, objects[0].ObjectKey, strings.NewReader("moved soon")))
+	require.NoError(t, newStore.Delete(ctx, objects[0].ObjectKey))
+
+	rc, err := svc.DownloadContent(ctx, content.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "moved soon", string(data))
+	_, err = newStore.GetObjectMeta(ctx, objects[0].ObjectKey)
+	assert.NoError(t, err, "the download should have migrated the data")
+
+	// Pings and disposition URLs reach the wrapped backends
+	pingingSource := &pingingBlobStore{BlobStore: memorystorage.New()}
+	require.NoError(t, pingingSource.Upload(ctx, "report.pdf", strings.NewReader("pdf")))
+	primary := &dispositionBlobStore{BlobStore: memorystorage.New()}
+	forwarding := simplecontent.NewMigratingBlobStore(primary, pingingSource)
+	require.NoError(t, simplecontent.PingBackend(ctx, forwarding))
+	assert.Equal(t, 1, pingingSource.pings)
+	url, err := forwarding.GetDownloadURLWithDisposition(ctx, "report.pdf", "attachment", "report.pdf")
+	require.NoError(t, err)
+	assert.Contains(t, url, "disposition=attachment")
+	_, err = primary.GetObjectMeta(ctx, "report.pdf")
+	assert.NoError(t, err, "the object is migrated before its URL is handed out")
+}
+
+func TestZeroByteUpload(t *testing.T) {
+	ctx := context.Background()
+	fsStore, err := fsstorage.New(fsstorage.Config{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+
+	for name, store := range map[string]simplecontent.BlobStore{"memory": memorystorage.New(), "fs": fsStore} {
+		t.Run(name, func(t *testing.T) {
+			svc, err := simplecontent.New(
+				simplecontent.WithRepository(memory.New()),
+				simplecontent.WithBlobStore(name, store),
+			)
+			require.NoError(t, err)
+			storageSvc := svc.(simplecontent.StorageService)
+
+			assertEmpty := func(t *testing.T, objectID uuid.UUID) {
+				rc, err := storageSvc.DownloadObject(ctx, objectID)
+				require.NoError(t, err)
+				data, err := io.ReadAll(rc)
+				rc.Close()
+				require.NoError(t, err)
+				assert.Empty(t, data)
+
+				object, err := storageSvc.GetObject(ctx, objectID)
+				require.NoError(t, err)
+				assert.Equal(t, string(simplecontent.ObjectStatusUploaded), object.Status)
+				meta, err := store.GetObjectMeta(ctx, object.ObjectKey)
+				require.NoError(t, err, "the backend should hold an empty object")
+				assert.Equal(t, int64(0), meta.Size)
+			}
+
+			content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+				OwnerID:      uuid.New(),
+				TenantID:     uuid.New(),
+				Name:         "empty",
+				DocumentType: "text/plain",
+				Reader:       strings.NewReader(""),
+				FileName:     "empty.txt",
+			})
+			require.NoError(t, err)
+			objects, err := storageSvc.GetObjectsByContentID(ctx, content.ID)
+			require.NoError(t, err)
+			require.Len(t, objects, 1)
+			assertEmpty(t, objects[0].ID)
+
+			md, err := storageSvc.GetObjectMetadata(ctx, objects[0].ID)
+			require.NoError(t, err)
+			assert.EqualValues(t, 0, md["file_size"])
+			details, err := svc.GetContentDetails(ctx, content.ID)
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), details.FileSize)
+			assert.True(t, details.Ready)
+
+			rc, err := svc.DownloadContent(ctx, content.ID)
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			require.NoError(t, err)
+			assert.Empty(t, data)
+
+			// UploadObject replacing data with nothing leaves an empty object, not a missing one
+			object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{ContentID: content.ID, StorageBackendName: name, Version: 2})
+			require.NoError(t, err)
+			require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{ObjectID: object.ID, Reader: strings.NewReader("")}))
+			assertEmpty(t, object.ID)
+		})
+	}
+}
+
+func TestContentAddressedKeys(t *testing.T) {
+	ctx := context.Background()
+	data := "same bytes, stored once"
+	sum := sha256.Sum256([]byte(data))
+	wantKey := objectkey.NewContentAddressedKeyGenerator().ContentKey(fmt.Sprintf("%x", sum))
+
+	_, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithObjectKeyGenerator(objectkey.NewContentAddressedKeyGenerator()),
+	)
+	assert.Error(t, err, "content-addressed keys need content ETags")
+
+	baseDir := t.TempDir()
+	fsStore, err := fsstorage.New(fsstorage.Config{BaseDir: baseDir})
+	require.NoError(t, err)
+	countFiles := func(t *testing.T) int {
+		files := 0
+		require.NoError(t, filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				files++
+			}
+			return err
+		}))
+		return files
+	}
+
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("fs", fsStore),
+		simplecontent.WithContentETags(),
+		simplecontent.WithObjectKeyGenerator(objectkey.NewContentAddressedKeyGenerator()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	upload := func(t *testing.T, name, body string) *simplecontent.Object {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         name,
+			DocumentType: "text/plain",
+			Reader:       strings.NewReader(body),
+			FileName:     name + ".txt",
+		})
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return objects[0]
+	}
+	download := func(t *testing.T, objectID uuid.UUID) string {
+		rc, err := storageSvc.DownloadObject(ctx, objectID)
+		require.NoError(t, err)
+		defer rc.Close()
+		body, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	first := upload(t, "first", data)
+	second := upload(t, "second", data)
+	assert.Equal(t, wantKey, first.ObjectKey)
+	assert.Equal(t, first.ObjectKey, second.ObjectKey)
+	assert.Equal(t, 1, countFiles(t), "identical uploads share one blob")
+	assert.Equal(t, data, download(t, second.ID))
+
+	other := upload(t, "other", "different bytes")
+	assert.NotEqual(t, first.ObjectKey, other.ObjectKey)
+	assert.Equal(t, 2, countFiles(t))
+
+	// Replacing the data of a shared object leaves the other reference intact
+	require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{ObjectID: first.ID, Reader: strings.NewReader("replaced")}))
+	replaced, err := storageSvc.GetObject(ctx, first.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, wantKey, replaced.ObjectKey)
+	assert.Equal(t, "replaced", download(t, first.ID))
+	assert.Equal(t, data, download(t, second.ID))
+	assert.Equal(t, 3, countFiles(t))
+
+	// Replacing it again drops the blob only it referenced
+	require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{ObjectID: first.ID, Reader: strings.NewReader(data)}))
+	assert.Equal(t, data, download(t, first.ID))
+	assert.Equal(t, 2, countFiles(t))
+
+	// A shared blob is deleted with its last reference
+	require.NoError(t, storageSvc.DeleteObject(ctx, first.ID))
+	assert.Equal(t, data, download(t, second.ID))
+	assert.Equal(t, 2, countFiles(t))
+	require.NoError(t, storageSvc.DeleteObject(ctx, second.ID))
+	assert.Equal(t, 1, countFiles(t))
+}
+
+func TestContentAddressedMoveKeepsSharedBlob(t *testing.T) {
+	ctx := context.Background()
+	store := memorystorage.New()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", store),
+		simplecontent.WithContentETags(),
+		simplecontent.WithObjectKeyGenerator(objectkey.NewContentAddressedKeyGenerator()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	upload := func(t *testing.T, name string) *simplecontent.Object {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         name,
+			DocumentType: "text/plain",
+			Reader:       strings.NewReader("same"),
+			FileName:     name + ".txt",
+		})
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return objects[0]
+	}
+	download := func(t *testing.T, objectID uuid.UUID) string {
+		rc, err := storageSvc.DownloadObject(ctx, objectID)
+		require.NoError(t, err)
+		defer rc.Close()
+		body, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	first := upload(t, "first")
+	second := upload(t, "second")
+	require.Equal(t, first.ObjectKey, second.ObjectKey)
+
+	moved, err := storageSvc.MoveObject(ctx, first.ID, "moved/first.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "moved/first.txt", moved.ObjectKey)
+	assert.Equal(t, "same", download(t, first.ID))
+	assert.Equal(t, "same", download(t, second.ID), "the other reference keeps the shared blob")
+
+	// The last reference moves the blob itself
+	moved, err = storageSvc.MoveObject(ctx, second.ID, "moved/second.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "same", download(t, second.ID))
+	_, err = store.GetObjectMeta(ctx, first.ObjectKey)
+	assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+}
+
+// referenceHookRepository runs hook once before an object update that points an object at
+// key, so a test can act between the duplicate check and the new reference being recorded
+type referenceHookRepository struct {
+	simplecontent.Repository
+	key  string
+	hook func()
+	once sync.Once
+}
+
+func (r *referenceHookRepository) UpdateObject(ctx context.Context, object *simplecontent.Object) error {
+	if r.hook != nil && object.ObjectKey == r.key {
+		r.once.Do(r.hook)
+	}
+	return r.Repository.UpdateObject(ctx, object)
+}
+
+func TestContentAddressedDeleteRacesNewDuplicate(t *testing.T) {
+	ctx := context.Background()
+	repo := &referenceHookRepository{Repository: memory.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithContentETags(),
+		simplecontent.WithObjectKeyGenerator(objectkey.NewContentAddressedKeyGenerator()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	upload := func(t *testing.T, name string) *simplecontent.Object {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         name,
+			DocumentType: "text/plain",
+			Reader:       strings.NewReader("same"),
+			FileName:     name + ".txt",
+		})
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return objects[0]
+	}
+
+	first := upload(t, "first")
+
+	// Delete the only reference while a duplicate is about to point at its blob
+	deleted := make(chan error, 1)
+	repo.key = first.ObjectKey
+	repo.hook = func() {
+		go func() { deleted <- storageSvc.DeleteObject(ctx, first.ID) }()
+		select {
+		case err := <-deleted:
+			deleted <- err
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	second := upload(t, "second")
+	require.NoError(t, <-deleted)
+	require.Equal(t, first.ObjectKey, second.ObjectKey)
+
+	rc, err := storageSvc.DownloadObject(ctx, second.ID)
+	require.NoError(t, err, "the blob outlives the deleted reference")
+	defer rc.Close()
+	body, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "same", string(body))
+}
+
+// countingMetaStore counts the metadata lookups served by the wrapped backend
+type countingMetaStore struct {
+	simplecontent.BlobStore
+	lookups int
+}
+
+func (c *countingMetaStore) GetObjectMeta(ctx context.Context, objectKey string) (*simplecontent.ObjectMeta, error) {
+	c.lookups++
+	return c.BlobStore.GetObjectMeta(ctx, objectKey)
+}
+
+// failingMetaStore fails metadata lookups with err once it is set
+type failingMetaStore struct {
+	simplecontent.BlobStore
+	err error
+}
+
+func (f *failingMetaStore) GetObjectMeta(ctx context.Context, objectKey string) (*simplecontent.ObjectMeta, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.BlobStore.GetObjectMeta(ctx, objectKey)
+}
+
+func TestGetUploadOffsetBackendError(t *testing.T) {
+	ctx := context.Background()
+	store := &failingMetaStore{BlobStore: memorystorage.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", store),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "resumable",
+	})
+	require.NoError(t, err)
+	object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+		ContentID:          content.ID,
+		StorageBackendName: "memory",
+		Version:            1,
+	})
+	require.NoError(t, err)
+
+	// Nothing stored yet: the upload starts at byte 0
+	offset, err := storageSvc.GetUploadOffset(ctx, object.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+
+	// A transient backend error must not tell the client to re-send from byte 0
+	store.err = errors.New("connection reset")
+	_, err = storageSvc.GetUploadOffset(ctx, object.ID)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, store.err)
+}
+
+func TestConfirmUploadBackendError(t *testing.T) {
+	ctx := context.Background()
+	store := &failingMetaStore{BlobStore: memorystorage.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", store),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "presigned",
+	})
+	require.NoError(t, err)
+	object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+		ContentID:          content.ID,
+		StorageBackendName: "memory",
+		Version:            1,
+	})
+	require.NoError(t, err)
+
+	// A missing blob is reported as such
+	_, err = storageSvc.ConfirmUpload(ctx, object.ID)
+	assert.ErrorIs(t, err, simplecontent.ErrBlobNotFound)
+
+	// Backend failures are not mistaken for a missing blob
+	store.err = errors.New("connection reset")
+	_, err = storageSvc.ConfirmUpload(ctx, object.ID)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, store.err)
+	assert.NotErrorIs(t, err, simplecontent.ErrBlobNotFound)
+}
+
+func TestObjectMetaCache(t *testing.T) {
+	ctx := context.Background()
+	store := &countingMetaStore{BlobStore: memorystorage.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", store),
+		simplecontent.WithObjectMetaCache(time.Minute, 10),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "cached",
+		DocumentType: "text/plain",
+		Reader:       strings.NewReader("first version"),
+		FileName:     "cached.txt",
+	})
+	require.NoError(t, err)
+	objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+	require.NoError(t, err)
+	objectID := objects[0].ID
+
+	download := func(t *testing.T) (*simplecontent.ObjectMeta, int) {
+		before := store.lookups
+		rc, meta, err := storageSvc.DownloadObjectWithMeta(ctx, objectID)
+		require.NoError(t, err)
+		rc.Close()
+		return meta, store.lookups - before
+	}
+
+	meta, lookups := download(t)
+	assert.Equal(t, 1, lookups)
+	assert.Equal(t, int64(len("first version")), meta.Size)
+
+	// A second download within the TTL reuses the cached metadata
+	meta, lookups = download(t)
+	assert.Equal(t, 0, lookups)
+	assert.Equal(t, int64(len("first version")), meta.Size)
+	assert.Equal(t, "text/plain", meta.ContentType)
+
+	// New data invalidates the entry
+	require.NoError(t, storageSvc.UploadObject(ctx, simplecontent.UploadObjectRequest{ObjectID: objectID, Reader: strings.NewReader("second, longer version")}))
+	meta, lookups = download(t)
+	assert.Equal(t, 1, lookups)
+	assert.Equal(t, int64(len("second, longer version")), meta.Size)
+
+	// So does updating the object
+	object, err := storageSvc.GetObject(ctx, objectID)
+	require.NoError(t, err)
+	require.NoError(t, storageSvc.UpdateObject(ctx, object))
+	_, lookups = download(t)
+	assert.Equal(t, 1, lookups)
+	_, lookups = download(t)
+	assert.Equal(t, 0, lookups)
+}
+
+func TestListContentOriginalsOnly(t *testing.T) {
+	ctx := context.Background()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+	)
+	require.NoError(t, err)
+
+	ownerID, tenantID := uuid.New(), uuid.New()
+	var originals []uuid.UUID
+	for _, name := range []string{"beach.jpg", "forest.jpg"} {
+		original, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      ownerID,
+			TenantID:     tenantID,
+			Name:         name,
+			DocumentType: "image/jpeg",
+			Reader:       strings.NewReader("jpeg " + name),
+			FileName:     name,
+		})
+		require.NoError(t, err)
+		originals = append(originals, original.ID)
+		_, err = svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:       original.ID,
+			OwnerID:        ownerID,
+			TenantID:       tenantID,
+			DerivationType: "thumbnail",
+			Variant:        "thumbnail_128",
+			Reader:         strings.NewReader("thumb " + name),
+			FileName:       "thumb_" + name,
+		})
+		require.NoError(t, err)
+	}
+
+	ids := func(contents []*simplecontent.Content) []uuid.UUID {
+		out := make([]uuid.UUID, 0, len(contents))
+		for _, c := range contents {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	req := simplecontent.ListContentRequest{OwnerID: ownerID, TenantID: tenantID}
+	all, err := svc.ListContent(ctx, req)
+	require.NoError(t, err)
+	assert.Len(t, all, 4, "originals and thumbnails")
+
+	listed, err := svc.ListContent(ctx, req.WithOriginalsOnly())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, originals, ids(listed))
+
+	page, err := svc.ListContentPage(ctx, simplecontent.ListContentRequest{OwnerID: ownerID, TenantID: tenantID, Limit: 1, OriginalsOnly: true}, simplecontent.WithTotalCount())
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Contains(t, originals, page.Items[0].ID)
+	assert.True(t, page.HasMore)
+	require.NotNil(t, page.Total)
+	assert.Equal(t, int64(2), *page.Total)
+}
+
+// queryCountingRepository counts the listing and object queries made through it
+type queryCountingRepository struct {
+	simplecontent.Repository
+	lists         int
+	objectQueries int
+}
+
+func (r *queryCountingRepository) ListContentWithFilters(ctx context.Context, filters simplecontent.ContentListFilters) ([]*simplecontent.Content, error) {
+	r.lists++
+	return r.Repository.ListContentWithFilters(ctx, filters)
+}
+
+func (r *queryCountingRepository) GetObjectsByContentID(ctx context.Context, contentID uuid.UUID) ([]*simplecontent.Object, error) {
+	r.objectQueries++
+	return r.Repository.GetObjectsByContentID(ctx, contentID)
+}
+
+func (r *queryCountingRepository) GetObjectMetadata(ctx context.Context, objectID uuid.UUID) (*simplecontent.ObjectMetadata, error) {
+	r.objectQueries++
+	return r.Repository.GetObjectMetadata(ctx, objectID)
+}
+
+func TestListContentWithPrimaryObject(t *testing.T) {
+	ctx := context.Background()
+	repo := &queryCountingRepository{Repository: memory.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+	)
+	require.NoError(t, err)
+
+	ownerID, tenantID := uuid.New(), uuid.New()
+	sizes := map[uuid.UUID]int64{}
+	for _, data := range []string{"short", "a little longer"} {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      ownerID,
+			TenantID:     tenantID,
+			Name:         "note.txt",
+			DocumentType: "text/plain",
+			Reader:       strings.NewReader(data),
+			FileName:     "note.txt",
+		})
+		require.NoError(t, err)
+		sizes[content.ID] = int64(len(data))
+	}
+	// Content without uploaded data has no primary object
+	empty, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: ownerID, TenantID: tenantID, Name: "empty"})
+	require.NoError(t, err)
+
+	req := simplecontent.ListContentRequest{OwnerID: ownerID, TenantID: tenantID}
+	plain, err := svc.ListContent(ctx, req)
+	require.NoError(t, err)
+	for _, content := range plain {
+		assert.Nil(t, content.PrimaryObject)
+	}
+
+	repo.lists, repo.objectQueries = 0, 0
+	listed, err := svc.ListContent(ctx, req.WithPrimaryObject())
+	require.NoError(t, err)
+	require.Len(t, listed, 3)
+	assert.Equal(t, 1, repo.lists, "one listing query")
+	assert.Equal(t, 0, repo.objectQueries, "no per-content object queries")
+	for _, content := range listed {
+		if content.ID == empty.ID {
+			assert.Nil(t, content.PrimaryObject)
+			continue
+		}
+		require.NotNil(t, content.PrimaryObject, content.ID)
+		assert.Equal(t, sizes[content.ID], content.PrimaryObject.SizeBytes)
+		assert.NotEmpty(t, content.PrimaryObject.ObjectKey)
+		assert.Equal(t, "memory", content.PrimaryObject.StorageBackendName)
+
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		assert.Equal(t, objects[0].ID, content.PrimaryObject.ID)
+		assert.Equal(t, objects[0].ObjectKey, content.PrimaryObject.ObjectKey)
+	}
+
+	// The primary object is not stored with the content
+	stored, err := svc.GetContent(ctx, listed[0].ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.PrimaryObject)
+}
+
+// slowCreateRepository widens the window between the existence check and the insert of
+// derived content, so concurrent creations overlap
+type slowCreateRepository struct {
+	simplecontent.Repository
+	creates sync.Map // derived content IDs passed to CreateDerivedContentRelationship
+}
+
+func (r *slowCreateRepository) CreateDerivedContentRelationship(ctx context.Context, params simplecontent.CreateDerivedContentParams) (*simplecontent.DerivedContent, error) {
+	time.Sleep(20 * time.Millisecond)
+	r.creates.Store(params.DerivedContentID, true)
+	return r.Repository.CreateDerivedContentRelationship(ctx, params)
+}
+
+func TestCreateDerivedContentSingleFlight(t *testing.T) {
+	ctx := context.Background()
+	repo := &slowCreateRepository{Repository: memory.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+	)
+	require.NoError(t, err)
+
+	parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "photo.jpg",
+		DocumentType: "image/jpeg",
+		Reader:       strings.NewReader("jpeg"),
+		FileName:     "photo.jpg",
+	})
+	require.NoError(t, err)
+
+	const workers = 2
+	results := make([]*simplecontent.Content, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// With Overwrite the worker arriving second updates the first one's content
+			results[i], errs[i] = svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+				ParentID:  parent.ID,
+				OwnerID:   parent.OwnerID,
+				TenantID:  parent.TenantID,
+				Variant:   "thumbnail_256",
+				Overwrite: true,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		require.NoError(t, errs[i])
+	}
+	assert.Equal(t, results[0].ID, results[1].ID)
+	creates := 0
+	repo.creates.Range(func(_, _ any) bool { creates++; return true })
+	assert.Equal(t, 1, creates, "exactly one derived content created")
+
+	derived, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parent.ID))
+	require.NoError(t, err)
+	assert.Len(t, derived, 1)
+
+	// Another variant is not held up by the first
+	other, err := svc.CreateDerivedContent(ctx, simplecontent.CreateDerivedContentRequest{
+		ParentID: parent.ID,
+		OwnerID:  parent.OwnerID,
+		TenantID: parent.TenantID,
+		Variant:  "thumbnail_128",
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, results[0].ID, other.ID)
+}
+
+func TestCreateDerivedContentDuplicateVariant(t *testing.T) {
+	ctx := context.Background()
+	svc := setupTestService(t)
+	parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "photo.jpg",
+		DocumentType: "image/jpeg",
+		Reader:       strings.NewReader("jpeg"),
+		FileName:     "photo.jpg",
+	})
+	require.NoError(t, err)
+	request := func(overwrite bool) simplecontent.CreateDerivedContentRequest {
+		return simplecontent.CreateDerivedContentRequest{
+			ParentID:      parent.ID,
+			OwnerID:       parent.OwnerID,
+			TenantID:      parent.TenantID,
+			Variant:       "thumbnail_256",
+			Name:          "thumb",
+			InitialStatus: simplecontent.ContentStatusProcessing,
+			Overwrite:     overwrite,
+		}
+	}
+
+	first, err := svc.CreateDerivedContent(ctx, request(false))
+	require.NoError(t, err)
+
+	_, err = svc.CreateDerivedContent(ctx, request(false))
+	assert.ErrorIs(t, err, simplecontent.ErrVariantExists)
+	var contentErr *simplecontent.ContentError
+	require.ErrorAs(t, err, &contentErr)
+	assert.Equal(t, http.StatusConflict, contentErr.HTTPStatus())
+
+	_, err = svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+		ParentID: parent.ID,
+		OwnerID:  parent.OwnerID,
+		TenantID: parent.TenantID,
+		Variant:  "thumbnail_256",
+		Reader:   strings.NewReader("thumb"),
+	})
+	assert.ErrorIs(t, err, simplecontent.ErrVariantExists)
+
+	// Overwrite updates the existing derived content in place
+	require.NoError(t, svc.UpdateContentStatus(ctx, first.ID, simplecontent.ContentStatusFailed))
+	req := request(true)
+	req.Name = "thumb v2"
+	req.Metadata = map[string]interface{}{"width": 256}
+	overwritten, err := svc.CreateDerivedContent(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, overwritten.ID)
+	assert.Equal(t, "thumb v2", overwritten.Name)
+	assert.Equal(t, string(simplecontent.ContentStatusProcessing), overwritten.Status)
+
+	derived, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parent.ID))
+	require.NoError(t, err)
+	assert.Len(t, derived, 1, "duplicate attempts leave a single derived content")
+
+	// Overwrite resets the status only along the allowed transitions
+	require.NoError(t, svc.UpdateContentStatus(ctx, first.ID, simplecontent.ContentStatusArchived))
+	_, err = svc.CreateDerivedContent(ctx, request(true))
+	assert.ErrorIs(t, err, simplecontent.ErrInvalidStatusTransition)
+
+	// Uploads without a variant store the derivation type, like CreateDerivedContent
+	uploadPreview := func() error {
+		_, err := svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:       parent.ID,
+			OwnerID:        parent.OwnerID,
+			TenantID:       parent.TenantID,
+			DerivationType: "Preview",
+			Reader:         strings.NewReader("preview"),
+		})
+		return err
+	}
+	require.NoError(t, uploadPreview())
+	assert.ErrorIs(t, uploadPreview(), simplecontent.ErrVariantExists)
+	previews, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parent.ID), simplecontent.WithVariant("preview"))
+	require.NoError(t, err)
+	assert.Len(t, previews, 1)
+
+	// Once deleted the variant can be derived again
+	require.NoError(t, svc.UpdateContentStatus(ctx, first.ID, simplecontent.ContentStatusUploaded))
+	require.NoError(t, svc.UpdateContentStatus(ctx, first.ID, simplecontent.ContentStatusFailed))
+	require.NoError(t, svc.DeleteContent(ctx, first.ID))
+	again, err := svc.CreateDerivedContent(ctx, request(false))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, again.ID)
+}
+
+// variantScaler is a fake image scaler generating variants: it keeps every other byte
+type variantScaler struct {
+	calls atomic.Int32
+}
+
+func (v *variantScaler) Transform(ctx context.Context, src io.Reader, mimeType string) (io.ReadCloser, string, error) {
+	v.calls.Add(1)
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, "", err
+	}
+	scaled := make([]byte, 0, len(data)/2)
+	for i := 0; i < len(data); i += 2 {
+		scaled = append(scaled, data[i])
+	}
+	// Give concurrent requests time to pile up on the generation
+	time.Sleep(20 * time.Millisecond)
+	return io.NopCloser(bytes.NewReader(scaled)), mimeType, nil
+}
+
+func TestDownloadContentVariant(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, opts ...simplecontent.Option) (simplecontent.Service, *variantScaler, *simplecontent.Content) {
+		scaler := &variantScaler{}
+		svc, err := simplecontent.New(append([]simplecontent.Option{
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("memory", memorystorage.New()),
+			simplecontent.WithVariantTransformer("thumbnail_256", scaler),
+		}, opts...)...)
+		require.NoError(t, err)
+		parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         "photo.jpg",
+			DocumentType: "image/jpeg",
+			Reader:       strings.NewReader("abcdef"),
+			FileName:     "photo.jpg",
+		})
+		require.NoError(t, err)
+		return svc, scaler, parent
+	}
+
+	download := func(t *testing.T, svc simplecontent.Service, contentID uuid.UUID) string {
+		t.Helper()
+		reader, err := svc.DownloadContentVariant(ctx, contentID, "thumbnail_256")
+		require.NoError(t, err)
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("ServesExistingVariant", func(t *testing.T) {
+		svc, scaler, parent := setup(t, simplecontent.WithOnDemandVariants(true))
+		_, err := svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID: parent.ID,
+			OwnerID:  parent.OwnerID,
+			TenantID: parent.TenantID,
+			Variant:  "thumbnail_256",
+			Reader:   strings.NewReader("pre-generated"),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "pre-generated", download(t, svc, parent.ID))
+		assert.Zero(t, scaler.calls.Load(), "existing variant is not regenerated")
+	})
+
+	t.Run("GeneratesAndCachesOnMiss", func(t *testing.T) {
+		svc, scaler, parent := setup(t, simplecontent.WithOnDemandVariants(true))
+
+		assert.Equal(t, "ace", download(t, svc, parent.ID))
+		assert.Equal(t, int32(1), scaler.calls.Load())
+
+		derived, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parent.ID))
+		require.NoError(t, err)
+		require.Len(t, derived, 1)
+		assert.Equal(t, "thumbnail_256", derived[0].Variant)
+
+		// The cached copy serves the next download
+		assert.Equal(t, "ace", download(t, svc, parent.ID))
+		assert.Equal(t, int32(1), scaler.calls.Load())
+	})
+
+	t.Run("ConcurrentMissesGenerateOnce", func(t *testing.T) {
+		svc, scaler, parent := setup(t, simplecontent.WithOnDemandVariants(true))
+
+		const workers = 4
+		results := make([]string, workers)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = download(t, svc, parent.ID)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			assert.Equal(t, "ace", result)
+		}
+		assert.Equal(t, int32(1), scaler.calls.Load(), "variant generated once")
+		derived, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parent.ID))
+		require.NoError(t, err)
+		assert.Len(t, derived, 1)
+	})
+
+	t.Run("UncachedGeneratesEveryTime", func(t *testing.T) {
+		svc, scaler, parent := setup(t, simplecontent.WithOnDemandVariants(false))
+
+		assert.Equal(t, "ace", download(t, svc, parent.ID))
+		assert.Equal(t, "ace", download(t, svc, parent.ID))
+		assert.Equal(t, int32(2), scaler.calls.Load())
+
+		derived, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parent.ID))
+		require.NoError(t, err)
+		assert.Empty(t, derived)
+	})
+
+	t.Run("UncachedReaderDoesNotHoldLock", func(t *testing.T) {
+		svc, _, parent := setup(t, simplecontent.WithOnDemandVariants(false))
+
+		// An open, unread variant must not block the next request for it
+		first, err := svc.DownloadContentVariant(ctx, parent.ID, "thumbnail_256")
+		require.NoError(t, err)
+		defer first.Close()
+
+		done := make(chan error, 1)
+		go func() {
+			second, err := svc.DownloadContentVariant(ctx, parent.ID, "thumbnail_256")
+			if err == nil {
+				err = second.Close()
+			}
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("second download waited for the first reader to be closed")
+		}
+	})
+
+	t.Run("NotGeneratedWhenDisabled", func(t *testing.T) {
+		svc, scaler, parent := setup(t)
+
+		_, err := svc.DownloadContentVariant(ctx, parent.ID, "thumbnail_256")
+		assert.ErrorIs(t, err, simplecontent.ErrContentNotFound)
+		assert.Zero(t, scaler.calls.Load())
+	})
+}
+
+func TestDefaultTenantAndOwner(t *testing.T) {
+	ctx := context.Background()
+	defaultTenant, defaultOwner := uuid.New(), uuid.New()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithDefaultTenant(defaultTenant),
+		simplecontent.WithDefaultOwner(defaultOwner),
+	)
+	require.NoError(t, err)
+
+	// Omitted IDs inherit the defaults
+	created, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{Name: "notes"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultTenant, created.TenantID)
+	assert.Equal(t, defaultOwner, created.OwnerID)
+
+	uploaded, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		Name:         "photo.jpg",
+		DocumentType: "image/jpeg",
+		Reader:       strings.NewReader("jpeg data"),
+		FileName:     "photo.jpg",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, defaultTenant, uploaded.TenantID)
+	assert.Equal(t, defaultOwner, uploaded.OwnerID)
+
+	thumbnail, err := svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+		ParentID:       uploaded.ID,
+		DerivationType: "thumbnail",
+		Variant:        "thumbnail_128",
+		Reader:         strings.NewReader("thumb data"),
+		FileName:       "thumb.jpg",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, defaultTenant, thumbnail.TenantID)
+	assert.Equal(t, defaultOwner, thumbnail.OwnerID)
+
+	// Explicit IDs override the defaults
+	otherTenant, otherOwner := uuid.New(), uuid.New()
+	explicit, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  otherOwner,
+		TenantID: otherTenant,
+		Name:     "explicit",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, otherTenant, explicit.TenantID)
+	assert.Equal(t, otherOwner, explicit.OwnerID)
+
+	// Listing without IDs lists the default owner's content
+	listed, err := svc.ListContent(ctx, simplecontent.ListContentRequest{})
+	require.NoError(t, err)
+	assert.Len(t, listed, 3)
+	page, err := svc.ListContentPage(ctx, simplecontent.ListContentRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 3)
+	listed, err = svc.ListContent(ctx, simplecontent.ListContentRequest{OwnerID: otherOwner, TenantID: otherTenant})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, explicit.ID, listed[0].ID)
+}
+
+type metadataQueryRepository struct {
+	simplecontent.Repository
+	singleLookups atomic.Int32
+	batchLookups  atomic.Int32
+}
+
+func (r *metadataQueryRepository) GetObjectMetadata(ctx context.Context, objectID uuid.UUID) (*simplecontent.ObjectMetadata, error) {
+	r.singleLookups.Add(1)
+	return r.Repository.GetObjectMetadata(ctx, objectID)
+}
+
+func (r *metadataQueryRepository) GetObjectMetadataByObjectIDs(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]*simplecontent.ObjectMetadata, error) {
+	r.batchLookups.Add(1)
+	return r.Repository.GetObjectMetadataByObjectIDs(ctx, objectIDs)
+}
+
+func TestGetObjectsMetadata(t *testing.T) {
+	ctx := context.Background()
+	repo := &metadataQueryRepository{Repository: memory.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "scans",
+	})
+	require.NoError(t, err)
+	var objectIDs []uuid.UUID
+	for i, name := range []string{"page1.pdf", "page2.pdf", "page3.pdf"} {
+		object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+			ContentID:          content.ID,
+			StorageBackendName: "memory",
+			Version:            i + 1,
+		})
+		require.NoError(t, err)
+		require.NoError(t, storageSvc.SetObjectMetadata(ctx, object.ID, map[string]interface{}{"file_name": name}))
+		objectIDs = append(objectIDs, object.ID)
+	}
+	// An object whose metadata was never set is still listed
+	bare, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+		ContentID:          content.ID,
+		StorageBackendName: "memory",
+		Version:            4,
+	})
+	require.NoError(t, err)
+
+	repo.singleLookups.Store(0)
+	metadata, err := storageSvc.GetObjectsMetadata(ctx, content.ID)
+	require.NoError(t, err)
+	require.Len(t, metadata, 4)
+	for i, id := range objectIDs {
+		assert.Equal(t, fmt.Sprintf("page%d.pdf", i+1), metadata[id]["file_name"])
+	}
+	assert.Contains(t, metadata, bare.ID)
+	assert.Empty(t, metadata[bare.ID]["file_name"])
+	assert.Equal(t, int32(0), repo.singleLookups.Load(), "no per-object lookups")
+	assert.Equal(t, int32(1), repo.batchLookups.Load(), "one batch lookup")
+
+	_, err = storageSvc.GetObjectsMetadata(ctx, uuid.New())
+	assert.ErrorIs(t, err, simplecontent.ErrContentNotFound)
+}
+
+// readerAtStore reads uploads the way the S3 uploader reads a ReaderAt source: in
+// sections, without copying them into buffers. It records whether each upload got one.
+type readerAtStore struct {
+	simplecontent.BlobStore
+	readerAt []bool
+}
+
+func (s *readerAtStore) Upload(ctx context.Context, objectKey string, reader io.Reader) error {
+	return s.UploadWithParams(ctx, reader, simplecontent.UploadParams{ObjectKey: objectKey})
+}
+
+func (s *readerAtStore) UploadWithParams(ctx context.Context, reader io.Reader, params simplecontent.UploadParams) error {
+	source, ok := reader.(interface {
+		io.ReaderAt
+		io.Seeker
+	})
+	s.readerAt = append(s.readerAt, ok)
+	if ok {
+		size, err := source.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		reader = io.NewSectionReader(source, 0, size)
+	}
+	return s.BlobStore.UploadWithParams(ctx, reader, params)
+}
+
+// uploadPassthrough uploads data to a new object of content without any reader-wrapping options
+func uploadPassthrough(tb testing.TB, storageSvc simplecontent.StorageService, contentID uuid.UUID, version int, data []byte) {
+	tb.Helper()
+	object, err := storageSvc.CreateObject(context.Background(), simplecontent.CreateObjectRequest{
+		ContentID:          contentID,
+		StorageBackendName: "memory",
+		Version:            version,
+	})
+	require.NoError(tb, err)
+	require.NoError(tb, storageSvc.UploadObject(context.Background(), simplecontent.UploadObjectRequest{
+		ObjectID: object.ID,
+		Reader:   bytes.NewReader(data),
+		MimeType: "application/octet-stream",
+	}))
+}
+
+func TestUploadStreamingPassthrough(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	spill := t.TempDir()
+
+	ctx := context.Background()
+	store := &readerAtStore{BlobStore: memorystorage.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", store),
+		simplecontent.WithTempDir(spill),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "archive",
+	})
+	require.NoError(t, err)
+	data := bytes.Repeat([]byte("passthrough "), 4096)
+	uploadPassthrough(t, storageSvc, content.ID, 1, data)
+
+	_, err = svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "archive.bin",
+		DocumentType: "application/octet-stream",
+		Reader:       bytes.NewReader(data),
+	})
+	require.NoError(t, err)
+
+	// The backend got the caller's ReaderAt, and bytes read through it are still counted
+	assert.Equal(t, []bool{true, true}, store.readerAt)
+	assert.Equal(t, int64(2*len(data)), svc.Stats().BytesUploaded)
+
+	for _, dir := range []string{tmp, spill} {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "no temp files in %s", dir)
+	}
+}
+
+func BenchmarkUploadStreamingPassthrough(b *testing.B) {
+	tmp := b.TempDir()
+	b.Setenv("TMPDIR", tmp)
+
+	ctx := context.Background()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", &readerAtStore{BlobStore: memorystorage.New()}),
+	)
+	require.NoError(b, err)
+	storageSvc := svc.(simplecontent.StorageService)
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "Benchmark Content",
+	})
+	require.NoError(b, err)
+	data := bytes.Repeat([]byte("benchmark "), 100*1024) // ~1MB
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		uploadPassthrough(b, storageSvc, content.ID, i+1, data)
+	}
+	b.StopTimer()
+
+	entries, err := os.ReadDir(tmp)
+	require.NoError(b, err)
+	if len(entries) > 0 {
+		b.Fatalf("expected no temp files, found %d", len(entries))
+	}
+}
+
+// annotationSink records the ObjectAnnotated events fired by the service
+type annotationSink struct {
+	simplecontent.NoopEventSink
+	events []map[string]interface{}
+}
+
+func (s *annotationSink) ObjectAnnotated(ctx context.Context, object *simplecontent.Object, annotations map[string]interface{}) error {
+	s.events = append(s.events, annotations)
+	return nil
+}
+
+func TestAnnotateObject(t *testing.T) {
+	ctx := context.Background()
+	sink := &annotationSink{}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithEventSink(sink),
+		simplecontent.WithMetadataValidator(func(ctx context.Context, object *simplecontent.Object, annotations map[string]interface{}) error {
+			if _, ok := annotations["mime_type"]; ok {
+				return errors.New("mime_type is synced from storage")
+			}
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "receipt.jpg",
+		DocumentType: "image/jpeg",
+		Reader:       strings.NewReader("jpeg data"),
+		FileName:     "receipt.jpg",
+	})
+	require.NoError(t, err)
+	objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	objectID := objects[0].ID
+
+	require.NoError(t, storageSvc.AnnotateObject(ctx, objectID, map[string]interface{}{
+		"labels":   []string{"receipt"},
+		"ocr_text": "TOTAL 12.50",
+	}))
+	require.NoError(t, storageSvc.AnnotateObject(ctx, objectID, map[string]interface{}{
+		"labels": []string{"receipt", "grocery"},
+	}))
+
+	metadata, err := storageSvc.GetObjectMetadata(ctx, objectID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"receipt", "grocery"}, metadata["labels"], "later annotations overwrite")
+	assert.Equal(t, "TOTAL 12.50", metadata["ocr_text"], "earlier annotations are kept")
+	assert.Equal(t, "receipt.jpg", metadata["file_name"], "upload metadata is kept")
+
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, map[string]interface{}{"labels": []string{"receipt", "grocery"}}, sink.events[1])
+
+	// The validator rejects the whole set and nothing is stored or reported
+	err = storageSvc.AnnotateObject(ctx, objectID, map[string]interface{}{"mime_type": "text/plain", "color": "red"})
+	assert.ErrorIs(t, err, simplecontent.ErrInvalidMetadata)
+	metadata, err = storageSvc.GetObjectMetadata(ctx, objectID)
+	require.NoError(t, err)
+	assert.NotContains(t, metadata, "color")
+	assert.Len(t, sink.events, 2)
+
+	err = storageSvc.AnnotateObject(ctx, uuid.New(), map[string]interface{}{"labels": []string{"x"}})
+	assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+}
+
+// metadataLookupFailingRepository fails object metadata lookups once failLookups is set
+type metadataLookupFailingRepository struct {
+	simplecontent.Repository
+	failLookups bool
+}
+
+func (r *metadataLookupFailingRepository) GetObjectMetadataByObjectIDs(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]*simplecontent.ObjectMetadata, error) {
+	if r.failLookups {
+		return nil, errors.New("connection reset")
+	}
+	return r.Repository.GetObjectMetadataByObjectIDs(ctx, objectIDs)
+}
+
+func TestAnnotateObjectLookupError(t *testing.T) {
+	ctx := context.Background()
+	repo := &metadataLookupFailingRepository{Repository: memory.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "receipt.jpg",
+		DocumentType: "image/jpeg",
+		Reader:       strings.NewReader("jpeg data"),
+		FileName:     "receipt.jpg",
+	})
+	require.NoError(t, err)
+	objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	// A failed lookup is returned instead of replacing the synced metadata
+	repo.failLookups = true
+	err = storageSvc.AnnotateObject(ctx, objects[0].ID, map[string]interface{}{"labels": []string{"receipt"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection reset")
+
+	stored, err := repo.Repository.GetObjectMetadata(ctx, objects[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("jpeg data")), stored.SizeBytes)
+	assert.NotContains(t, stored.Metadata, "labels")
+}
+
+func TestContentFieldValidation(t *testing.T) {
+	ctx := context.Background()
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithNameMaxLength(10),
+		simplecontent.WithDescriptionMaxLength(20),
+	)
+	require.NoError(t, err)
+
+	create := func(name, description string) (*simplecontent.Content, error) {
+		return svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+			OwnerID:     uuid.New(),
+			TenantID:    uuid.New(),
+			Name:        name,
+			Description: description,
+		})
+	}
+	fieldOf := func(t *testing.T, err error) string {
+		t.Helper()
+		require.ErrorIs(t, err, simplecontent.ErrInvalidField)
+		var fieldErr *simplecontent.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		var contentErr *simplecontent.ContentError
+		require.ErrorAs(t, err, &contentErr)
+		assert.Equal(t, http.StatusBadRequest, contentErr.HTTPStatus())
+		return fieldErr.Field
+	}
+
+	// Lengths count characters, not bytes
+	content, err := create("Überblick", "Ünïcødé description")
+	require.NoError(t, err)
+
+	_, err = create("a name that is too long", "")
+	assert.Equal(t, "name", fieldOf(t, err))
+	_, err = create("bad \xff name", "")
+	assert.Equal(t, "name", fieldOf(t, err))
+	_, err = create("ok", "a description longer than the limit")
+	assert.Equal(t, "description", fieldOf(t, err))
+
+	_, err = svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "\xc3\x28",
+		Reader:   strings.NewReader("data"),
+	})
+	assert.Equal(t, "name", fieldOf(t, err))
+
+	content.Description = "invalid \xe2\x82 utf-8"
+	err = svc.UpdateContent(ctx, simplecontent.UpdateContentRequest{Content: content})
+	assert.Equal(t, "description", fieldOf(t, err))
+	stored, err := svc.GetContent(ctx, content.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ünïcødé description", stored.Description)
+
+	content.Name = "Renamed"
+	content.Description = "Valid"
+	require.NoError(t, svc.UpdateContent(ctx, simplecontent.UpdateContentRequest{Content: content}))
+
+	t.Run("DefaultNameLimit", func(t *testing.T) {
+		svc, err := simplecontent.New(simplecontent.WithRepository(memory.New()))
+		require.NoError(t, err)
+		_, err = svc.CreateContent(ctx, simplecontent.CreateContentRequest{Name: strings.Repeat("n", 255)})
+		require.NoError(t, err)
+		_, err = svc.CreateContent(ctx, simplecontent.CreateContentRequest{Name: strings.Repeat("n", 256)})
+		assert.ErrorIs(t, err, simplecontent.ErrInvalidField)
+	})
+}
+
+func TestDownloadRateLimit(t *testing.T) {
+	ctx := context.Background()
+	const rate = 100_000
+	data := bytes.Repeat([]byte("x"), 50_000)
+
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithDownloadRateLimit(rate),
+	)
+	require.NoError(t, err)
+	content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:  uuid.New(),
+		TenantID: uuid.New(),
+		Name:     "large.bin",
+		Reader:   bytes.NewReader(data),
+	})
+	require.NoError(t, err)
+
+	download := func(ctx context.Context) ([]byte, time.Duration) {
+		start := time.Now()
+		reader, err := svc.DownloadContent(ctx, content.ID)
+		require.NoError(t, err)
+		defer reader.Close()
+		got, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return got, time.Since(start)
+	}
+
+	// The bucket starts with a tenth of a second of data, the rest streams at the rate
+	minimum := time.Duration(float64(len(data)-rate/10) / rate * float64(time.Second))
+	got, elapsed := download(ctx)
+	assert.Equal(t, data, got)
+	assert.GreaterOrEqual(t, elapsed, minimum)
+
+	// A per-request rate replaces the service rate
+	got, elapsed = download(simplecontent.WithRequestDownloadRateLimit(ctx, 0))
+	assert.Equal(t, data, got)
+	assert.Less(t, elapsed, minimum)
+
+	got, elapsed = download(simplecontent.WithRequestDownloadRateLimit(ctx, rate/2))
+	assert.Equal(t, data, got)
+	assert.GreaterOrEqual(t, elapsed, 2*minimum)
+
+	// A throttled read gives up when the request is cancelled
+	cancelled, cancel := context.WithCancel(ctx)
+	reader, err := svc.DownloadContent(cancelled, content.ID)
+	require.NoError(t, err)
+	defer reader.Close()
+	cancel()
+	_, err = io.ReadAll(reader)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAppendToObject(t *testing.T) {
+	ctx := context.Background()
+	fsStore, err := fsstorage.New(fsstorage.Config{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("fs", fsStore),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithContentETags(),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: uuid.New(), TenantID: uuid.New(), Name: "app.log"})
+	require.NoError(t, err)
+	object, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{ContentID: content.ID, StorageBackendName: "fs", Version: 1})
+	require.NoError(t, err)
+
+	meta, err := storageSvc.AppendToObject(ctx, object.ID, strings.NewReader("first line\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("first line\n")), meta.SizeBytes)
+	meta, err = storageSvc.AppendToObject(ctx, object.ID, strings.NewReader("second line\n"))
+	require.NoError(t, err)
+
+	want := "first line\nsecond line\n"
+	assert.Equal(t, int64(len(want)), meta.SizeBytes)
+	sum := sha256.Sum256([]byte(want))
+	assert.Equal(t, simplecontent.ContentETag(sum[:]), meta.Metadata[simplecontent.MetaContentETag])
+
+	rc, err := storageSvc.DownloadObject(ctx, object.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, want, string(data))
+	object, err = storageSvc.GetObject(ctx, object.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(simplecontent.ObjectStatusUploaded), object.Status)
+
+	// Backends that cannot append say so
+	memObject, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{ContentID: content.ID, StorageBackendName: "memory", Version: 2})
+	require.NoError(t, err)
+	_, err = storageSvc.AppendToObject(ctx, memObject.ID, strings.NewReader("nope"))
+	assert.ErrorIs(t, err, simplecontent.ErrAppendNotSupported)
+}
+
+func TestAppendToSharedContentAddressedObject(t *testing.T) {
+	ctx := context.Background()
+	fsStore, err := fsstorage.New(fsstorage.Config{BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("fs", fsStore),
+		simplecontent.WithContentETags(),
+		simplecontent.WithObjectKeyGenerator(objectkey.NewContentAddressedKeyGenerator()),
+	)
+	require.NoError(t, err)
+	storageSvc := svc.(simplecontent.StorageService)
+
+	upload := func(t *testing.T, name string) *simplecontent.Object {
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         name,
+			DocumentType: "text/plain",
+			Reader:       strings.NewReader("same"),
+			FileName:     name + ".txt",
+		})
+		require.NoError(t, err)
+		objects, err := svc.GetObjectsByContentID(ctx, content.ID)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		return objects[0]
+	}
+	download := func(t *testing.T, objectID uuid.UUID) string {
+		rc, err := storageSvc.DownloadObject(ctx, objectID)
+		require.NoError(t, err)
+		defer rc.Close()
+		body, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	first := upload(t, "first")
+	second := upload(t, "second")
+	require.Equal(t, first.ObjectKey, second.ObjectKey)
+
+	_, err = storageSvc.AppendToObject(ctx, first.ID, strings.NewReader("-appended"))
+	require.NoError(t, err)
+	assert.Equal(t, "same-appended", download(t, first.ID))
+	assert.Equal(t, "same", download(t, second.ID), "the other reference keeps the shared blob")
+
+	sum := sha256.Sum256([]byte("same-appended"))
+	appended, err := storageSvc.GetObject(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, objectkey.NewContentAddressedKeyGenerator().ContentKey(fmt.Sprintf("%x", sum)), appended.ObjectKey)
+}
+
+func TestKeyCaseNormalization(t *testing.T) {
+	ctx := context.Background()
+	for _, normalize := range []bool{true, false} {
+		t.Run(fmt.Sprintf("normalize=%v", normalize), func(t *testing.T) {
+			opts := []simplecontent.Option{
+				simplecontent.WithRepository(memory.New()),
+				simplecontent.WithBlobStore("memory", memorystorage.New()),
+			}
+			if normalize {
+				opts = append(opts, simplecontent.WithKeyCaseNormalization())
+			}
+			svc, err := simplecontent.New(opts...)
+			require.NoError(t, err)
+			storageSvc := svc.(simplecontent.StorageService)
+			content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: uuid.New(), TenantID: uuid.New(), Name: "photo"})
+			require.NoError(t, err)
+
+			upper, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+				ContentID: content.ID, StorageBackendName: "memory", Version: 1, ObjectKey: "photos/File.JPG",
+			})
+			require.NoError(t, err)
+			lower, err := storageSvc.CreateObject(ctx, simplecontent.CreateObjectRequest{
+				ContentID: content.ID, StorageBackendName: "memory", Version: 2, ObjectKey: "photos/file.jpg",
+				CollisionPolicy: simplecontent.CollisionPolicyError,
+			})
+
+			if normalize {
+				assert.ErrorIs(t, err, simplecontent.ErrObjectKeyExists, "keys differing only in case are the same key")
+				assert.Equal(t, "photos/file.jpg", upper.ObjectKey)
+				found, err := storageSvc.GetObjectByKey(ctx, "memory", "PHOTOS/file.Jpg")
+				require.NoError(t, err)
+				assert.Equal(t, upper.ID, found.ID)
+				md, err := storageSvc.GetObjectMetadata(ctx, upper.ID)
+				require.NoError(t, err)
+				assert.Equal(t, "photos/File.JPG", md[simplecontent.MetaOriginalObjectKey])
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEqual(t, upper.ID, lower.ID)
+			found, err := storageSvc.GetObjectByKey(ctx, "memory", "photos/File.JPG")
+			require.NoError(t, err)
+			assert.Equal(t, upper.ID, found.ID)
+			found, err = storageSvc.GetObjectByKey(ctx, "memory", "photos/file.jpg")
+			require.NoError(t, err)
+			assert.Equal(t, lower.ID, found.ID)
+			_, err = storageSvc.GetObjectByKey(ctx, "memory", "PHOTOS/FILE.JPG")
+			assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+		})
+	}
+}
+
+// updateSink records the content update events fired by the service
+type updateSink struct {
+	simplecontent.NoopEventSink
+	events []*simplecontent.ContentUpdatedEvent
+}
+
+func (s *updateSink) ContentUpdatedWithChanges(ctx context.Context, event *simplecontent.ContentUpdatedEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestContentUpdatedEventChanges(t *testing.T) {
+	ctx := context.Background()
+	sink := &updateSink{}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithEventSink(sink),
+	)
+	require.NoError(t, err)
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "draft.txt",
+		DocumentType: "text/plain",
+	})
+	require.NoError(t, err)
+
+	content.Name = "final.txt"
+	require.NoError(t, svc.UpdateContent(ctx, simplecontent.UpdateContentRequest{Content: content}))
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	assert.Equal(t, simplecontent.EventSchemaVersion, event.SchemaVersion)
+	assert.Equal(t, content.ID, event.Content.ID)
+	assert.Equal(t, map[string]simplecontent.FieldChange{
+		"name": {Old: "draft.txt", New: "final.txt"},
+	}, event.Changes, "only the name changed")
+	assert.False(t, event.Changed("status"))
+
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"schema_version":1`)
+	assert.Contains(t, string(payload), `"changes":{"name":{"old":"draft.txt","new":"final.txt"}}`)
+
+	// An update that changes nothing reports no changes
+	require.NoError(t, svc.UpdateContent(ctx, simplecontent.UpdateContentRequest{Content: content}))
+	require.Len(t, sink.events, 2)
+	assert.Empty(t, sink.events[1].Changes)
+	assert.False(t, sink.events[1].ChangesUnavailable)
+}
+
+// contentReadFailingRepository fails content reads once failReads is set
+type contentReadFailingRepository struct {
+	simplecontent.Repository
+	failReads bool
+}
+
+func (r *contentReadFailingRepository) GetContent(ctx context.Context, id uuid.UUID) (*simplecontent.Content, error) {
+	if r.failReads {
+		return nil, errors.New("connection reset")
+	}
+	return r.Repository.GetContent(ctx, id)
+}
+
+func TestContentUpdatedEventChangesUnavailable(t *testing.T) {
+	ctx := context.Background()
+	sink := &updateSink{}
+	repo := &contentReadFailingRepository{Repository: memory.New()}
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(repo),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithEventSink(sink),
+	)
+	require.NoError(t, err)
+
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{
+		OwnerID:      uuid.New(),
+		TenantID:     uuid.New(),
+		Name:         "draft.txt",
+		DocumentType: "text/plain",
+	})
+	require.NoError(t, err)
+
+	// A failed pre-read still updates the content, but the event must not claim no changes
+	repo.failReads = true
+	content.Name = "final.txt"
+	require.NoError(t, svc.UpdateContent(ctx, simplecontent.UpdateContentRequest{Content: content}))
+	require.Len(t, sink.events, 1)
+	assert.True(t, sink.events[0].ChangesUnavailable)
+	assert.Nil(t, sink.events[0].Changes)
+
+	payload, err := json.Marshal(sink.events[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"changes_unavailable":true`)
+}
+
+// gatedTransformer holds every transformation until release is closed
+type gatedTransformer struct {
+	simplecontent.PreviewTransformer
+	release chan struct{}
+}
+
+func (g *gatedTransformer) Transform(ctx context.Context, src io.Reader, mimeType string) (io.ReadCloser, string, error) {
+	<-g.release
+	return g.PreviewTransformer.Transform(ctx, src, mimeType)
+}
+
+func TestDerivationDispatcher(t *testing.T) {
+	ctx := context.Background()
+
+	upload := func(t *testing.T, transformer simplecontent.PreviewTransformer, dispatcher simplecontent.DerivationDispatcher) (simplecontent.Service, *simplecontent.Content) {
+		t.Helper()
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("memory", memorystorage.New()),
+			simplecontent.WithVariantTransformer("thumbnail_256", transformer),
+			simplecontent.WithDerivationDispatcher(dispatcher),
+		)
+		require.NoError(t, err)
+		parent, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         "photo.jpg",
+			DocumentType: "image/jpeg",
+			Reader:       strings.NewReader("abcdef"),
+			FileName:     "photo.jpg",
+		})
+		require.NoError(t, err)
+		return svc, parent
+	}
+	variants := func(t *testing.T, svc simplecontent.Service, parentID uuid.UUID) []*simplecontent.DerivedContent {
+		t.Helper()
+		derived, err := svc.ListDerivedContent(ctx, simplecontent.WithParentID(parentID))
+		require.NoError(t, err)
+		return derived
+	}
+	variantData := func(t *testing.T, svc simplecontent.Service, parentID uuid.UUID) string {
+		t.Helper()
+		reader, err := svc.DownloadContentVariant(ctx, parentID, "thumbnail_256")
+		require.NoError(t, err)
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("Inline", func(t *testing.T) {
+		scaler := &variantScaler{}
+		svc, parent := upload(t, scaler, simplecontent.NewInlineDispatcher())
+
+		derived := variants(t, svc, parent.ID)
+		require.Len(t, derived, 1, "the variant exists when the upload returns")
+		assert.Equal(t, "thumbnail_256", derived[0].Variant)
+		assert.Equal(t, "ace", variantData(t, svc, parent.ID))
+		assert.Equal(t, int32(1), scaler.calls.Load())
+	})
+
+	t.Run("Queued", func(t *testing.T) {
+		scaler := &variantScaler{}
+		transformer := &gatedTransformer{PreviewTransformer: scaler, release: make(chan struct{})}
+		pool := simplecontent.NewWorkerPoolDispatcher(1, 8)
+		svc, parent := upload(t, transformer, pool)
+
+		assert.Empty(t, variants(t, svc, parent.ID), "the upload does not wait for the worker")
+
+		close(transformer.release)
+		pool.Close()
+		derived := variants(t, svc, parent.ID)
+		require.Len(t, derived, 1, "the variant exists once the worker ran")
+		assert.Equal(t, "thumbnail_256", derived[0].Variant)
+		assert.Equal(t, "ace", variantData(t, svc, parent.ID))
+
+		// A closed pool takes no more jobs; the upload still succeeds
+		_, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:  parent.OwnerID,
+			TenantID: parent.TenantID,
+			Name:     "late.jpg",
+			Reader:   strings.NewReader("late"),
+		})
+		require.NoError(t, err)
+	})
+}
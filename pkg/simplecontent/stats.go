@@ -0,0 +1,172 @@
+package simplecontent
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ServiceStats is a snapshot of the runtime counters kept since the service was created.
+// It is meant for lightweight /metrics style endpoints; counters are never reset.
+type ServiceStats struct {
+	StartedAt        time.Time `json:"started_at"`
+	UploadsInFlight  int64     `json:"uploads_in_flight"`
+	UploadsCompleted int64     `json:"uploads_completed"`
+	UploadsFailed    int64     `json:"uploads_failed"`
+	Downloads        int64     `json:"downloads"`        // Downloads opened through the service
+	BytesUploaded    int64     `json:"bytes_uploaded"`   // Bytes read from upload sources, including failed uploads
+	BytesDownloaded  int64     `json:"bytes_downloaded"` // Bytes read by callers from download streams
+	// ErrorsByBackend counts failed uploads, downloads and mirror copies per storage backend
+	ErrorsByBackend map[string]int64 `json:"errors_by_backend"`
+	// BytesUploadedByBackend counts the bytes of completed uploads per storage backend, so
+	// traffic to tenant backends can be accounted separately
+	BytesUploadedByBackend map[string]int64 `json:"bytes_uploaded_by_backend"`
+}
+
+// serviceStats holds the counters behind Stats
+type serviceStats struct {
+	startedAt        time.Time
+	uploadsInFlight  atomic.Int64
+	uploadsCompleted atomic.Int64
+	uploadsFailed    atomic.Int64
+	downloads        atomic.Int64
+	bytesUploaded    atomic.Int64
+	bytesDownloaded  atomic.Int64
+
+	mu                     sync.Mutex
+	errorsByBackend        map[string]int64
+	bytesUploadedByBackend map[string]int64
+}
+
+// Stats returns a snapshot of the operational counters
+func (s *service) Stats() ServiceStats {
+	st := &s.stats
+	st.mu.Lock()
+	errorsByBackend := make(map[string]int64, len(st.errorsByBackend))
+	for name, count := range st.errorsByBackend {
+		errorsByBackend[name] = count
+	}
+	bytesUploadedByBackend := make(map[string]int64, len(st.bytesUploadedByBackend))
+	for name, count := range st.bytesUploadedByBackend {
+		bytesUploadedByBackend[name] = count
+	}
+	st.mu.Unlock()
+
+	return ServiceStats{
+		StartedAt:              st.startedAt,
+		UploadsInFlight:        st.uploadsInFlight.Load(),
+		UploadsCompleted:       st.uploadsCompleted.Load(),
+		UploadsFailed:          st.uploadsFailed.Load(),
+		Downloads:              st.downloads.Load(),
+		BytesUploaded:          st.bytesUploaded.Load(),
+		BytesDownloaded:        st.bytesDownloaded.Load(),
+		ErrorsByBackend:        errorsByBackend,
+		BytesUploadedByBackend: bytesUploadedByBackend,
+	}
+}
+
+// backendError counts a failed operation on the named backend
+func (st *serviceStats) backendError(backend string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.errorsByBackend == nil {
+		st.errorsByBackend = make(map[string]int64)
+	}
+	st.errorsByBackend[backend]++
+}
+
+// backendUpload adds the bytes of a completed upload to the named backend's total
+func (st *serviceStats) backendUpload(backend string, bytes int64) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.bytesUploadedByBackend == nil {
+		st.bytesUploadedByBackend = make(map[string]int64)
+	}
+	st.bytesUploadedByBackend[backend] += bytes
+}
+
+// trackUpload counts an upload to the named backend as in flight and returns the
+// source wrapped to count bytes. Seekable sources stay seekable so backends can still
+// rewind them, and sources that also support ReadAt keep it, so the S3 uploader can read
+// parts straight from the source instead of copying them into buffers. done must be
+// called with the upload result.
+func (s *service) trackUpload(backend string, reader io.Reader) (io.Reader, func(err error)) {
+	st := &s.stats
+	st.uploadsInFlight.Add(1)
+	counter := &statsReader{reader: reader, counter: &st.bytesUploaded}
+	var counted io.Reader = counter
+	if seeker, ok := reader.(io.Seeker); ok {
+		readSeeker := &statsReadSeeker{statsReader: counter, seeker: seeker}
+		counted = readSeeker
+		if readerAt, ok := reader.(io.ReaderAt); ok {
+			counted = &statsReaderAtSeeker{statsReadSeeker: readSeeker, readerAt: readerAt}
+		}
+	}
+	return counted, func(err error) {
+		st.uploadsInFlight.Add(-1)
+		if err != nil {
+			st.uploadsFailed.Add(1)
+			st.backendError(backend)
+			return
+		}
+		st.uploadsCompleted.Add(1)
+		st.backendUpload(backend, counter.read.Load())
+	}
+}
+
+// trackDownload counts a download result from the named backend, wrapping a successful
+// stream so the bytes read by the caller are counted
+func (s *service) trackDownload(backend string, reader io.ReadCloser, err error) io.ReadCloser {
+	if err != nil {
+		s.stats.backendError(backend)
+		return reader
+	}
+	s.stats.downloads.Add(1)
+	return &statsReadCloser{statsReader: statsReader{reader: reader, counter: &s.stats.bytesDownloaded}, closer: reader}
+}
+
+// statsReader adds the bytes read through it to a shared counter and its own total
+type statsReader struct {
+	reader  io.Reader
+	counter *atomic.Int64
+	read    atomic.Int64
+}
+
+func (r *statsReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.counter.Add(int64(n))
+	r.read.Add(int64(n))
+	return n, err
+}
+
+type statsReadSeeker struct {
+	*statsReader
+	seeker io.Seeker
+}
+
+func (r *statsReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.seeker.Seek(offset, whence)
+}
+
+// statsReaderAtSeeker counts the bytes of parts read concurrently with ReadAt
+type statsReaderAtSeeker struct {
+	*statsReadSeeker
+	readerAt io.ReaderAt
+}
+
+func (r *statsReaderAtSeeker) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.readerAt.ReadAt(p, off)
+	r.counter.Add(int64(n))
+	r.read.Add(int64(n))
+	return n, err
+}
+
+type statsReadCloser struct {
+	statsReader
+	closer io.Closer
+}
+
+func (r *statsReadCloser) Close() error {
+	return r.closer.Close()
+}
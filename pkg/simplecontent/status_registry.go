@@ -0,0 +1,166 @@
+package simplecontent
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// StatusDefinition describes one status of a content or object lifecycle
+type StatusDefinition struct {
+	Name string
+
+	// Terminal marks a resting state no further processing is expected to leave. Only the
+	// statuses listed in Next can follow a terminal status.
+	Terminal bool
+
+	// Next lists the statuses that may follow this one. Nil lets a non-terminal status move
+	// to any registered status.
+	Next []string
+}
+
+// StatusRegistry is a status vocabulary and the transitions allowed between its statuses.
+// ContentStatuses and ObjectStatuses hold the vocabularies checked by the service; pipelines
+// with domain-specific states register them there.
+type StatusRegistry struct {
+	mu       sync.RWMutex
+	statuses map[string]StatusDefinition
+}
+
+// NewStatusRegistry returns a registry holding the given statuses
+func NewStatusRegistry(defs ...StatusDefinition) *StatusRegistry {
+	r := &StatusRegistry{statuses: make(map[string]StatusDefinition, len(defs))}
+	for _, def := range defs {
+		r.statuses[def.Name] = def
+	}
+	return r
+}
+
+// Register adds a status, or replaces the definition of one already registered. Statuses
+// named in Next must be registered too, though they may be registered afterwards.
+func (r *StatusRegistry) Register(def StatusDefinition) error {
+	if def.Name == "" {
+		return fmt.Errorf("status name is required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses[def.Name] = def
+	return nil
+}
+
+// Unregister removes a status from the vocabulary. Records already in it are no longer
+// checked against the registry.
+func (r *StatusRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.statuses, name)
+}
+
+// IsRegistered reports whether status belongs to the vocabulary
+func (r *StatusRegistry) IsRegistered(status string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.statuses[status]
+	return ok
+}
+
+// IsTerminal reports whether status is a registered terminal status
+func (r *StatusRegistry) IsTerminal(status string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.statuses[status].Terminal
+}
+
+// CanTransition reports whether a record may move from one status to another. Both must be
+// registered; staying in the same status is always allowed.
+func (r *StatusRegistry) CanTransition(from, to string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.statuses[from]
+	if !ok {
+		return false
+	}
+	if _, ok := r.statuses[to]; !ok {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	if def.Next == nil {
+		return !def.Terminal
+	}
+	for _, next := range def.Next {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Statuses returns the registered status names, sorted
+func (r *StatusRegistry) Statuses() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.statuses))
+	for name := range r.statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ContentStatuses is the content status vocabulary. Uploaded and processed are the resting
+// states of originals and derivatives; they can still be reprocessed, archived or failed.
+var ContentStatuses = NewStatusRegistry(
+	StatusDefinition{Name: string(ContentStatusCreated)},
+	StatusDefinition{Name: string(ContentStatusUploading)},
+	StatusDefinition{Name: string(ContentStatusUploaded), Terminal: true, Next: []string{
+		string(ContentStatusUploading), string(ContentStatusProcessing), string(ContentStatusProcessed),
+		string(ContentStatusFailed), string(ContentStatusArchived),
+	}},
+	StatusDefinition{Name: string(ContentStatusProcessing)},
+	StatusDefinition{Name: string(ContentStatusProcessed), Terminal: true, Next: []string{
+		string(ContentStatusProcessing), string(ContentStatusFailed), string(ContentStatusArchived),
+	}},
+	StatusDefinition{Name: string(ContentStatusFailed)},
+	StatusDefinition{Name: string(ContentStatusArchived), Terminal: true, Next: []string{
+		string(ContentStatusUploaded), string(ContentStatusProcessed),
+	}},
+	StatusDefinition{Name: string(ContentStatusDeleted), Terminal: true},
+)
+
+// ObjectStatuses is the object status vocabulary
+var ObjectStatuses = NewStatusRegistry(
+	StatusDefinition{Name: string(ObjectStatusCreated)},
+	StatusDefinition{Name: string(ObjectStatusUploading)},
+	StatusDefinition{Name: string(ObjectStatusUploaded), Terminal: true, Next: []string{
+		string(ObjectStatusUploading), string(ObjectStatusProcessing), string(ObjectStatusProcessed),
+		string(ObjectStatusFailed),
+	}},
+	StatusDefinition{Name: string(ObjectStatusProcessing)},
+	StatusDefinition{Name: string(ObjectStatusProcessed), Terminal: true, Next: []string{
+		string(ObjectStatusProcessing), string(ObjectStatusFailed),
+	}},
+	StatusDefinition{Name: string(ObjectStatusFailed)},
+	StatusDefinition{Name: string(ObjectStatusDeleted), Terminal: true},
+)
+
+// IsTerminal reports whether the content status is a terminal status
+func (s ContentStatus) IsTerminal() bool {
+	return ContentStatuses.IsTerminal(string(s))
+}
+
+// CanTransition reports whether content may move from this status to next
+func (s ContentStatus) CanTransition(next ContentStatus) bool {
+	return ContentStatuses.CanTransition(string(s), string(next))
+}
+
+// IsTerminal reports whether the object status is a terminal status
+func (s ObjectStatus) IsTerminal() bool {
+	return ObjectStatuses.IsTerminal(string(s))
+}
+
+// CanTransition reports whether an object may move from this status to next
+func (s ObjectStatus) CanTransition(next ObjectStatus) bool {
+	return ObjectStatuses.CanTransition(string(s), string(next))
+}
@@ -271,3 +271,94 @@ func TestGetObjectsByStatus(t *testing.T) {
 		assert.Equal(t, created2.ID, results[0].ID)
 	})
 }
+
+// TestStatusRegistryDefaults tests the default content and object vocabularies
+func TestStatusRegistryDefaults(t *testing.T) {
+	t.Run("ContentVocabulary", func(t *testing.T) {
+		for _, status := range []simplecontent.ContentStatus{
+			simplecontent.ContentStatusCreated, simplecontent.ContentStatusUploading, simplecontent.ContentStatusUploaded,
+			simplecontent.ContentStatusProcessing, simplecontent.ContentStatusProcessed, simplecontent.ContentStatusFailed,
+			simplecontent.ContentStatusArchived, simplecontent.ContentStatusDeleted,
+		} {
+			assert.True(t, status.IsValid(), status)
+		}
+
+		assert.False(t, simplecontent.ContentStatusCreated.IsTerminal())
+		assert.False(t, simplecontent.ContentStatusFailed.IsTerminal())
+		assert.True(t, simplecontent.ContentStatusUploaded.IsTerminal())
+		assert.True(t, simplecontent.ContentStatusProcessed.IsTerminal())
+
+		assert.True(t, simplecontent.ContentStatusCreated.CanTransition(simplecontent.ContentStatusProcessed))
+		assert.True(t, simplecontent.ContentStatusFailed.CanTransition(simplecontent.ContentStatusUploading))
+		assert.True(t, simplecontent.ContentStatusProcessed.CanTransition(simplecontent.ContentStatusFailed))
+		assert.True(t, simplecontent.ContentStatusUploaded.CanTransition(simplecontent.ContentStatusUploaded))
+		assert.False(t, simplecontent.ContentStatusProcessed.CanTransition(simplecontent.ContentStatusCreated))
+		assert.False(t, simplecontent.ContentStatusDeleted.CanTransition(simplecontent.ContentStatusUploaded))
+		assert.False(t, simplecontent.ContentStatusCreated.CanTransition("invalid"))
+	})
+
+	t.Run("ObjectVocabulary", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"created", "uploading", "uploaded", "processing", "processed", "failed", "deleted"},
+			simplecontent.ObjectStatuses.Statuses())
+		assert.True(t, simplecontent.ObjectStatusUploaded.IsTerminal())
+		assert.True(t, simplecontent.ObjectStatusUploading.CanTransition(simplecontent.ObjectStatusUploaded))
+		assert.False(t, simplecontent.ObjectStatusUploaded.CanTransition(simplecontent.ObjectStatusCreated))
+	})
+
+	t.Run("ServiceRejectsDisallowedTransition", func(t *testing.T) {
+		svc := setupStatusTestService(t)
+		ctx := context.Background()
+
+		content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: uuid.New(), TenantID: uuid.New(), Name: "Doc"})
+		require.NoError(t, err)
+		require.NoError(t, svc.UpdateContentStatus(ctx, content.ID, simplecontent.ContentStatusProcessed))
+
+		err = svc.UpdateContentStatus(ctx, content.ID, simplecontent.ContentStatusCreated)
+		assert.True(t, errors.Is(err, simplecontent.ErrInvalidStatusTransition))
+
+		got, err := svc.GetContent(ctx, content.ID)
+		require.NoError(t, err)
+		assert.Equal(t, string(simplecontent.ContentStatusProcessed), got.Status)
+	})
+}
+
+// TestStatusRegistryCustomStatus tests a domain-specific status registered for a pipeline
+func TestStatusRegistryCustomStatus(t *testing.T) {
+	const scanning = simplecontent.ContentStatus("virus_scanning")
+	const quarantined = simplecontent.ContentStatus("quarantined")
+
+	// The registry is shared by the package; leave it as the other tests expect
+	registry := simplecontent.ContentStatuses
+	t.Cleanup(func() {
+		registry.Unregister(string(scanning))
+		registry.Unregister(string(quarantined))
+	})
+	require.NoError(t, registry.Register(simplecontent.StatusDefinition{
+		Name: string(scanning),
+		Next: []string{string(simplecontent.ContentStatusUploaded), string(quarantined)},
+	}))
+	require.NoError(t, registry.Register(simplecontent.StatusDefinition{Name: string(quarantined), Terminal: true}))
+	assert.Error(t, registry.Register(simplecontent.StatusDefinition{}))
+
+	assert.True(t, scanning.IsValid())
+	assert.False(t, scanning.IsTerminal())
+	assert.True(t, quarantined.IsTerminal())
+	assert.True(t, scanning.CanTransition(quarantined))
+	assert.False(t, scanning.CanTransition(simplecontent.ContentStatusProcessed))
+	assert.False(t, quarantined.CanTransition(simplecontent.ContentStatusUploaded))
+
+	parsed, err := simplecontent.ParseContentStatus("virus_scanning")
+	require.NoError(t, err)
+	assert.Equal(t, scanning, parsed)
+
+	// The service accepts the custom statuses and enforces their transitions
+	svc := setupStatusTestService(t)
+	ctx := context.Background()
+	content, err := svc.CreateContent(ctx, simplecontent.CreateContentRequest{OwnerID: uuid.New(), TenantID: uuid.New(), Name: "Scanned"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.UpdateContentStatus(ctx, content.ID, scanning))
+	require.NoError(t, svc.UpdateContentStatus(ctx, content.ID, quarantined))
+	err = svc.UpdateContentStatus(ctx, content.ID, simplecontent.ContentStatusUploaded)
+	assert.True(t, errors.Is(err, simplecontent.ErrInvalidStatusTransition))
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,18 +21,28 @@ import (
 type Backend struct {
 	mu             sync.RWMutex
 	baseDir        string
+	keyPrefix      string // KeyPrefix directory every object key is stored under
 	urlPrefix      string
+	urlPath        string            // Path component of urlPrefix, part of every signed path
+	urlOrigin      string            // Scheme and host of urlPrefix; empty for relative URLs
 	signer         *presigned.Signer // For authenticated presigned upload URLs
-	downloadSigner *presigned.Signer // For authenticated presigned download/preview URLs
+	downloadSigner *presigned.Signer // For authenticated presigned download URLs
+	previewSigner  *presigned.Signer // For authenticated presigned preview URLs
 	presignExpires time.Duration     // Default expiration for presigned URLs
 }
 
 // Config options for the filesystem backend
 type Config struct {
 	BaseDir            string        // Base directory for storing files
-	URLPrefix          string        // Optional URL prefix for download/upload URLs
+	URLPrefix          string        // Optional URL prefix for download/upload URLs, e.g. "http://localhost:8080/api/v1"
 	SignatureSecretKey string        // Secret key for signing presigned URLs (optional, enables auth)
 	PresignExpires     time.Duration // Default expiration for presigned URLs (default: 1 hour)
+	// KeyPrefix stores every object under this subdirectory of BaseDir, e.g. "app-a", so
+	// several services can share one directory. Object keys passed in stay prefix-free.
+	KeyPrefix string
+	// RelativeURLs returns URLs as paths without the scheme and host of URLPrefix, for
+	// clients served from the same origin. Signatures only cover the path either way.
+	RelativeURLs bool
 }
 
 // New creates a new filesystem storage backend
@@ -52,41 +64,68 @@ func New(config Config) (simplecontent.BlobStore, error) {
 
 	backend := &Backend{
 		baseDir:        config.BaseDir,
-		urlPrefix:      config.URLPrefix,
+		keyPrefix:      strings.Trim(config.KeyPrefix, "/"),
+		urlPrefix:      strings.TrimSuffix(config.URLPrefix, "/"),
 		presignExpires: presignExpires,
 	}
+	if config.URLPrefix != "" {
+		prefix, err := url.Parse(backend.urlPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL prefix: %w", err)
+		}
+		backend.urlPath = prefix.Path
+		if !config.RelativeURLs {
+			backend.urlOrigin = strings.TrimSuffix(backend.urlPrefix, prefix.Path)
+		}
+	}
 
-	// Initialize presigned signers if secret key is provided
+	// Initialize presigned signers if secret key is provided. URLs are signed over the
+	// full request path, including the path of the URL prefix, which is what
+	// presigned.ValidateMiddleware checks on the server receiving them.
 	if config.SignatureSecretKey != "" {
-		// Upload signer (PUT method)
-		backend.signer = presigned.New(
-			presigned.WithSecretKey(config.SignatureSecretKey),
-			presigned.WithDefaultExpiration(presignExpires),
-			presigned.WithURLPattern("/upload/{key}"),
-		)
-
-		// Download signer (GET method)
-		backend.downloadSigner = presigned.New(
-			presigned.WithSecretKey(config.SignatureSecretKey),
-			presigned.WithDefaultExpiration(presignExpires),
-			presigned.WithURLPattern("/download/{key}"),
-		)
+		newSigner := func(route string) *presigned.Signer {
+			return presigned.New(
+				presigned.WithSecretKey(config.SignatureSecretKey),
+				presigned.WithDefaultExpiration(presignExpires),
+				presigned.WithURLPattern(backend.urlPath+route+"{key}"),
+				presigned.WithBaseURL(backend.urlOrigin),
+			)
+		}
+		backend.signer = newSigner("/upload/")           // PUT
+		backend.downloadSigner = newSigner("/download/") // GET
+		backend.previewSigner = newSigner("/preview/")   // GET
 	}
 
 	return backend, nil
 }
 
+// path returns the file path objectKey is stored at
+func (b *Backend) path(objectKey string) string {
+	return filepath.Join(b.baseDir, b.keyPrefix, objectKey)
+}
+
+// signedPath returns the request path a presigned URL for the route and key is signed over.
+// The download filename is part of the signature, encoded as presigned.Signer.ValidateRequest
+// re-encodes remaining query parameters.
+func (b *Backend) signedPath(route, objectKey, filename string) string {
+	path := b.urlPath + route + objectKey
+	if filename != "" {
+		path += "?" + url.Values{"filename": {filename}}.Encode()
+	}
+	return path
+}
+
 // GetObjectMeta retrieves metadata for an object in the filesystem
 func (b *Backend) GetObjectMeta(ctx context.Context, objectKey string) (*simplecontent.ObjectMeta, error) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	filePath := filepath.Join(b.baseDir, objectKey)
+	filePath := b.path(objectKey)
 
 	// Check if file exists
 	info, err := os.Stat(filePath)
 	if os.IsNotExist(err) {
-		return nil, errors.New("object not found")
+		return nil, simplecontent.ErrObjectNotFound
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
@@ -121,20 +160,45 @@ func (b *Backend) GetUploadURL(ctx context.Context, objectKey string) (string, e
 		return "", errors.New("direct upload required for filesystem backend")
 	}
 
-	path := "/upload/" + objectKey
-
 	// If signer is configured, generate signed URL
 	if b.signer != nil {
-		return b.signer.SignURLWithBase(b.urlPrefix, "PUT", path, b.presignExpires)
+		return b.signer.SignURL("PUT", b.signedPath("/upload/", objectKey, ""), b.presignExpires)
 	}
 
 	// Otherwise, return unsigned URL (for backward compatibility)
-	return b.urlPrefix + path, nil
+	return b.urlOrigin + b.signedPath("/upload/", objectKey, ""), nil
+}
+
+// Capabilities reports URL support, which requires a configured URL prefix. URLs are
+// signed when a signing secret is configured.
+func (b *Backend) Capabilities() simplecontent.BackendCapabilities {
+	return simplecontent.BackendCapabilities{
+		PresignedUpload:   b.urlPrefix != "",
+		PresignedDownload: b.urlPrefix != "",
+	}
+}
+
+// Ping checks that the base directory exists and is a directory
+func (b *Backend) Ping(ctx context.Context) error {
+	info, err := os.Stat(b.baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to stat base directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("base directory %s is not a directory", b.baseDir)
+	}
+	return nil
 }
 
+// partialSuffix marks a file that is still being written. Uploads are written to the
+// partial file and renamed into place once complete, so an interrupted upload leaves
+// its bytes behind for GetUploadOffset instead of a truncated object.
+const partialSuffix = ".partial"
+
 // Upload uploads content directly to the filesystem
 func (b *Backend) Upload(ctx context.Context, objectKey string, reader io.Reader) error {
-	filePath := filepath.Join(b.baseDir, objectKey)
+	filePath := b.path(objectKey)
+	partialPath := filePath + partialSuffix
 
 	// Create directory structure if it doesn't exist
 	dir := filepath.Dir(filePath)
@@ -143,21 +207,56 @@ func (b *Backend) Upload(ctx context.Context, objectKey string, reader io.Reader
 	}
 
 	// Create file
-	file, err := os.Create(filePath)
+	file, err := os.Create(partialPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
-	defer file.Close()
 
 	// Copy data from reader to file
 	_, err = io.Copy(file, reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
+	if err := os.Rename(partialPath, filePath); err != nil {
+		return fmt.Errorf("failed to finalize file: %w", err)
+	}
+
 	return nil
 }
 
+// GetUploadOffset returns the size of the object, or of its partial file while an
+// upload is unfinished. A key with neither reports 0.
+func (b *Backend) GetUploadOffset(ctx context.Context, objectKey string) (int64, error) {
+	filePath := b.path(objectKey)
+
+	for _, path := range []string{filePath, filePath + partialSuffix} {
+		info, err := os.Stat(path)
+		if err == nil {
+			return info.Size(), nil
+		}
+		if !os.IsNotExist(err) {
+			return 0, fmt.Errorf("failed to get file info: %w", err)
+		}
+	}
+	return 0, nil
+}
+
+// ObjectExists reports whether a complete file is stored under the key
+func (b *Backend) ObjectExists(ctx context.Context, objectKey string) (bool, error) {
+	_, err := os.Stat(b.path(objectKey))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to get file info: %w", err)
+}
+
 // UploadWithParams uploads content with additional parameters
 func (b *Backend) UploadWithParams(ctx context.Context, reader io.Reader, params simplecontent.UploadParams) error {
 	// For filesystem, we don't store MIME type separately, it's detected on read
@@ -170,20 +269,16 @@ func (b *Backend) GetDownloadURL(ctx context.Context, objectKey string, download
 		return "", errors.New("direct download required for filesystem backend")
 	}
 
-	path := "/download/" + objectKey
-
-	// Add filename to path if provided (will be included in signature)
-	if downloadFilename != "" {
-		path = path + "?filename=" + downloadFilename
-	}
+	// The filename, if provided, is included in the signature
+	path := b.signedPath("/download/", objectKey, downloadFilename)
 
 	// If signer is configured, generate signed URL
 	if b.downloadSigner != nil {
-		return b.downloadSigner.SignURLWithBase(b.urlPrefix, "GET", path, b.presignExpires)
+		return b.downloadSigner.SignURL("GET", path, b.presignExpires)
 	}
 
 	// Otherwise, return unsigned URL (backward compatibility)
-	return b.urlPrefix + path, nil
+	return b.urlOrigin + path, nil
 }
 
 // GetPreviewURL returns a URL for previewing content
@@ -192,25 +287,23 @@ func (b *Backend) GetPreviewURL(ctx context.Context, objectKey string) (string,
 		return "", errors.New("direct preview required for filesystem backend")
 	}
 
-	path := "/preview/" + objectKey
-
 	// If signer is configured, generate signed URL
-	if b.downloadSigner != nil {
-		return b.downloadSigner.SignURLWithBase(b.urlPrefix, "GET", path, b.presignExpires)
+	if b.previewSigner != nil {
+		return b.previewSigner.SignURL("GET", b.signedPath("/preview/", objectKey, ""), b.presignExpires)
 	}
 
 	// Otherwise, return unsigned URL (backward compatibility)
-	return b.urlPrefix + path, nil
+	return b.urlOrigin + b.signedPath("/preview/", objectKey, ""), nil
 }
 
 // Download downloads content directly from the filesystem
 func (b *Backend) Download(ctx context.Context, objectKey string) (io.ReadCloser, error) {
-	filePath := filepath.Join(b.baseDir, objectKey)
+	filePath := b.path(objectKey)
 
 	// Check if file exists and open it
 	file, err := os.Open(filePath)
 	if os.IsNotExist(err) {
-		return nil, errors.New("object not found")
+		return nil, simplecontent.ErrObjectNotFound
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
@@ -220,10 +313,22 @@ func (b *Backend) Download(ctx context.Context, objectKey string) (io.ReadCloser
 
 // Delete deletes content from the filesystem
 func (b *Backend) Delete(ctx context.Context, objectKey string) error {
-	filePath := filepath.Join(b.baseDir, objectKey)
+	filePath := b.path(objectKey)
+
+	// Remove data left by an unfinished upload along with the object
+	removedPartial := false
+	if err := os.Remove(filePath + partialSuffix); err == nil {
+		removedPartial = true
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete partial file: %w", err)
+	}
 
 	// Check if file exists
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		if removedPartial {
+			b.cleanupEmptyDirectories(filepath.Dir(filePath))
+			return nil
+		}
 		return errors.New("object not found")
 	}
 
@@ -238,6 +343,70 @@ func (b *Backend) Delete(ctx context.Context, objectKey string) error {
 	return nil
 }
 
+// MoveObject renames the file to dstKey, creating its directories as needed
+func (b *Backend) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	srcPath := b.path(srcKey)
+	dstPath := b.path(dstKey)
+
+	if _, err := os.Stat(srcPath); os.IsNotExist(err) {
+		return simplecontent.ErrObjectNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to get file info: %w", err)
+	}
+	// os.Rename replaces an existing file, so check the destination first
+	if _, err := os.Stat(dstPath); err == nil {
+		return simplecontent.ErrObjectKeyExists
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(srcPath, dstPath); err != nil {
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+
+	b.cleanupEmptyDirectories(filepath.Dir(srcPath))
+	return nil
+}
+
+// AppendObject appends the data of reader to the file of objectKey, creating it when it
+// does not exist. A failed append truncates the file back to its previous size.
+func (b *Backend) AppendObject(ctx context.Context, objectKey string, reader io.Reader) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	filePath := b.path(objectKey)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	_, err = io.Copy(file, reader)
+	if err != nil {
+		file.Truncate(info.Size())
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to append to file: %w", err)
+	}
+	return nil
+}
+
 // cleanupEmptyDirectories recursively removes empty directories up to baseDir
 func (b *Backend) cleanupEmptyDirectories(dir string) {
 	// Don't remove the base directory
@@ -264,8 +433,7 @@ func (b *Backend) ValidateUploadSignature(objectKey, signature string, expiresAt
 		return nil
 	}
 
-	path := "/upload/" + objectKey
-	return b.signer.Validate("PUT", path, signature, expiresAt)
+	return b.signer.Validate("PUT", b.signedPath("/upload/", objectKey, ""), signature, expiresAt)
 }
 
 // IsSignedURLEnabled returns true if signed URLs are enabled for this backend
@@ -273,12 +441,23 @@ func (b *Backend) IsSignedURLEnabled() bool {
 	return b.signer != nil && b.signer.IsEnabled()
 }
 
-// GetSigner returns the presigned.Signer if configured, nil otherwise
-// This allows external code to use the signer directly
+// GetSigner returns the upload presigned.Signer if configured, nil otherwise
+// This allows external code to use the signer directly, e.g. with
+// presigned.ValidateMiddlewareWithSigner, whose URL pattern then matches the upload URLs
 func (b *Backend) GetSigner() *presigned.Signer {
 	return b.signer
 }
 
+// GetDownloadSigner returns the download presigned.Signer if configured, nil otherwise
+func (b *Backend) GetDownloadSigner() *presigned.Signer {
+	return b.downloadSigner
+}
+
+// GetPreviewSigner returns the preview presigned.Signer if configured, nil otherwise
+func (b *Backend) GetPreviewSigner() *presigned.Signer {
+	return b.previewSigner
+}
+
 // ValidateDownloadSignature validates a presigned download URL signature
 // Returns nil if signature is valid, error otherwise
 func (b *Backend) ValidateDownloadSignature(objectKey, signature string, expiresAt int64, filename string) error {
@@ -288,22 +467,17 @@ func (b *Backend) ValidateDownloadSignature(objectKey, signature string, expires
 		return nil
 	}
 
-	path := "/download/" + objectKey
-	if filename != "" {
-		path = path + "?filename=" + filename
-	}
-	return b.downloadSigner.Validate("GET", path, signature, expiresAt)
+	return b.downloadSigner.Validate("GET", b.signedPath("/download/", objectKey, filename), signature, expiresAt)
 }
 
 // ValidatePreviewSignature validates a presigned preview URL signature
 // Returns nil if signature is valid, error otherwise
 func (b *Backend) ValidatePreviewSignature(objectKey, signature string, expiresAt int64) error {
-	if b.downloadSigner == nil {
+	if b.previewSigner == nil {
 		// No signature validation configured - allow all previews
 		// This provides backward compatibility
 		return nil
 	}
 
-	path := "/preview/" + objectKey
-	return b.downloadSigner.Validate("GET", path, signature, expiresAt)
+	return b.previewSigner.Validate("GET", b.signedPath("/preview/", objectKey, ""), signature, expiresAt)
 }
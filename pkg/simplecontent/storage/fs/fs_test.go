@@ -3,10 +3,19 @@ package fs
 import (
     "bytes"
     "context"
+    "errors"
     "io"
+    "net/http"
+    "net/http/httptest"
     "os"
     "path/filepath"
+    "strings"
     "testing"
+    "testing/iotest"
+
+    "github.com/go-chi/chi/v5"
+    "github.com/tendant/simple-content/pkg/simplecontent"
+    "github.com/tendant/simple-content/pkg/simplecontent/presigned"
 )
 
 func TestFSBackend_BasicOps(t *testing.T) {
@@ -75,3 +84,339 @@ func TestFSBackend_URLMethods_NoPrefix(t *testing.T) {
     }
 }
 
+
+func TestFSBackend_UploadOffset(t *testing.T) {
+    tmp := t.TempDir()
+    b, err := New(Config{BaseDir: tmp})
+    if err != nil {
+        t.Fatalf("new fs backend: %v", err)
+    }
+    backend := b.(*Backend)
+    ctx := context.Background()
+    key := "C/resume/file.bin"
+
+    // Fresh key
+    offset, err := backend.GetUploadOffset(ctx, key)
+    if err != nil || offset != 0 {
+        t.Fatalf("expected offset 0, got %d (err=%v)", offset, err)
+    }
+
+    // Interrupted upload keeps the bytes written so far but no object
+    err = backend.Upload(ctx, key, io.MultiReader(bytes.NewReader([]byte("partial")), iotest.ErrReader(errors.New("reset"))))
+    if err == nil {
+        t.Fatal("expected interrupted upload to fail")
+    }
+    offset, err = backend.GetUploadOffset(ctx, key)
+    if err != nil || offset != 7 {
+        t.Fatalf("expected offset 7, got %d (err=%v)", offset, err)
+    }
+    if _, err := backend.GetObjectMeta(ctx, key); err == nil {
+        t.Fatal("partial upload must not be visible as an object")
+    }
+
+    // Delete removes the partial data
+    if err := backend.Delete(ctx, key); err != nil {
+        t.Fatalf("delete partial: %v", err)
+    }
+    if _, err := os.Stat(filepath.Join(tmp, key+partialSuffix)); !os.IsNotExist(err) {
+        t.Fatalf("expected partial file removed, stat err=%v", err)
+    }
+
+    // Completed upload reports the object size
+    if err := backend.Upload(ctx, key, bytes.NewReader([]byte("complete data"))); err != nil {
+        t.Fatalf("upload: %v", err)
+    }
+    offset, err = backend.GetUploadOffset(ctx, key)
+    if err != nil || offset != 13 {
+        t.Fatalf("expected offset 13, got %d (err=%v)", offset, err)
+    }
+    if _, err := os.Stat(filepath.Join(tmp, key+partialSuffix)); !os.IsNotExist(err) {
+        t.Fatalf("expected no partial file after upload, stat err=%v", err)
+    }
+}
+
+func TestFSBackend_SignedURLsValidateWithPresigned(t *testing.T) {
+    const secret = "test-secret-key-at-least-32-bytes!"
+    ctx := context.Background()
+
+    put := func(t *testing.T, url, body string) *http.Response {
+        t.Helper()
+        req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+        if err != nil {
+            t.Fatalf("new request: %v", err)
+        }
+        resp, err := http.DefaultClient.Do(req)
+        if err != nil {
+            t.Fatalf("put: %v", err)
+        }
+        resp.Body.Close()
+        return resp
+    }
+
+    readStored := func(t *testing.T, store simplecontent.BlobStore, key string) string {
+        t.Helper()
+        rc, err := store.Download(ctx, key)
+        if err != nil {
+            t.Fatalf("download stored file: %v", err)
+        }
+        defer rc.Close()
+        data, _ := io.ReadAll(rc)
+        return string(data)
+    }
+
+    t.Run("ValidateMiddleware", func(t *testing.T) {
+        mux := http.NewServeMux()
+        srv := httptest.NewServer(mux)
+        defer srv.Close()
+
+        store, err := New(Config{BaseDir: t.TempDir(), URLPrefix: srv.URL, SignatureSecretKey: secret})
+        if err != nil {
+            t.Fatalf("new fs backend: %v", err)
+        }
+        mux.Handle("/upload/", presigned.ValidateMiddleware(secret, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            if err := store.Upload(r.Context(), presigned.ObjectKeyFromContext(r.Context()), r.Body); err != nil {
+                http.Error(w, err.Error(), http.StatusInternalServerError)
+            }
+        })))
+
+        key := "originals/a1/report.pdf"
+        uploadURL, err := store.GetUploadURL(ctx, key)
+        if err != nil {
+            t.Fatalf("get upload url: %v", err)
+        }
+        if resp := put(t, uploadURL, "signed upload"); resp.StatusCode != http.StatusOK {
+            t.Fatalf("expected 200, got %d", resp.StatusCode)
+        }
+        if got := readStored(t, store, key); got != "signed upload" {
+            t.Fatalf("unexpected stored data %q", got)
+        }
+
+        // A tampered signature is rejected
+        tampered := strings.Replace(uploadURL, "signature=", "signature=0", 1)
+        if resp := put(t, tampered, "forged"); resp.StatusCode != http.StatusForbidden {
+            t.Fatalf("expected 403 for tampered signature, got %d", resp.StatusCode)
+        }
+    })
+
+    t.Run("DownloadFilenameWithMiddleware", func(t *testing.T) {
+        mux := http.NewServeMux()
+        srv := httptest.NewServer(mux)
+        defer srv.Close()
+
+        b, err := New(Config{BaseDir: t.TempDir(), URLPrefix: srv.URL + "/files", SignatureSecretKey: secret})
+        if err != nil {
+            t.Fatalf("new fs backend: %v", err)
+        }
+        store := b.(*Backend)
+        mux.Handle("/files/download/", presigned.ValidateMiddlewareWithSigner(store.GetDownloadSigner(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            _, _ = io.WriteString(w, presigned.ObjectKeyFromContext(r.Context()))
+        })))
+
+        downloadURL, err := store.GetDownloadURL(ctx, "docs/q3.pdf", "Q3 report.pdf")
+        if err != nil {
+            t.Fatalf("get download url: %v", err)
+        }
+        resp, err := http.Get(downloadURL)
+        if err != nil {
+            t.Fatalf("get: %v", err)
+        }
+        body, _ := io.ReadAll(resp.Body)
+        resp.Body.Close()
+        if resp.StatusCode != http.StatusOK || string(body) != "docs/q3.pdf" {
+            t.Fatalf("expected validated download of docs/q3.pdf, got %d %q", resp.StatusCode, body)
+        }
+    })
+
+    t.Run("PresignedHandlersUnderPrefix", func(t *testing.T) {
+        // Mounted the way cmd/server-configured mounts them
+        r := chi.NewRouter()
+        srv := httptest.NewServer(r)
+        defer srv.Close()
+
+        store, err := New(Config{BaseDir: t.TempDir(), URLPrefix: srv.URL + "/api/v1", SignatureSecretKey: secret})
+        if err != nil {
+            t.Fatalf("new fs backend: %v", err)
+        }
+        r.Route("/api/v1", func(r chi.Router) {
+            presigned.NewHandlers(map[string]simplecontent.BlobStore{"fs": store}, "fs").Mount(r)
+        })
+
+        key := "originals/b2/photo.jpg"
+        uploadURL, err := store.GetUploadURL(ctx, key)
+        if err != nil {
+            t.Fatalf("get upload url: %v", err)
+        }
+        if !strings.HasPrefix(uploadURL, srv.URL+"/api/v1/upload/") {
+            t.Fatalf("unexpected upload url %s", uploadURL)
+        }
+        if resp := put(t, uploadURL, "photo bytes"); resp.StatusCode != http.StatusOK {
+            t.Fatalf("expected 200, got %d", resp.StatusCode)
+        }
+        if got := readStored(t, store, key); got != "photo bytes" {
+            t.Fatalf("unexpected stored data %q", got)
+        }
+
+        previewURL, err := store.GetPreviewURL(ctx, key)
+        if err != nil {
+            t.Fatalf("get preview url: %v", err)
+        }
+        resp, err := http.Get(previewURL)
+        if err != nil {
+            t.Fatalf("get preview: %v", err)
+        }
+        body, _ := io.ReadAll(resp.Body)
+        resp.Body.Close()
+        if resp.StatusCode != http.StatusOK || string(body) != "photo bytes" {
+            t.Fatalf("expected preview of stored file, got %d %q", resp.StatusCode, body)
+        }
+
+        // An unsigned upload is rejected
+        if resp := put(t, srv.URL+"/api/v1/upload/"+key, "unsigned"); resp.StatusCode != http.StatusUnauthorized {
+            t.Fatalf("expected 401 for unsigned upload, got %d", resp.StatusCode)
+        }
+    })
+}
+
+func TestFSBackend_RelativeURLs(t *testing.T) {
+    b, err := New(Config{
+        BaseDir:            t.TempDir(),
+        URLPrefix:          "https://files.example.com/api/v1",
+        SignatureSecretKey: "test-secret-key-at-least-32-bytes!",
+        RelativeURLs:       true,
+    })
+    if err != nil {
+        t.Fatalf("new fs backend: %v", err)
+    }
+    store := b.(*Backend)
+
+    uploadURL, err := store.GetUploadURL(context.Background(), "docs/a.txt")
+    if err != nil {
+        t.Fatalf("get upload url: %v", err)
+    }
+    if !strings.HasPrefix(uploadURL, "/api/v1/upload/docs/a.txt?signature=") {
+        t.Fatalf("expected relative upload url, got %s", uploadURL)
+    }
+    req := httptest.NewRequest(http.MethodPut, uploadURL, nil)
+    if err := store.GetSigner().ValidateRequest(req); err != nil {
+        t.Fatalf("validate relative url: %v", err)
+    }
+}
+
+func TestFSBackend_MoveObject(t *testing.T) {
+    tmp := t.TempDir()
+    b, err := New(Config{BaseDir: tmp})
+    if err != nil {
+        t.Fatalf("new fs backend: %v", err)
+    }
+    store := b.(*Backend)
+    ctx := context.Background()
+
+    if err := store.Upload(ctx, "tmp/a/file.txt", strings.NewReader("move me")); err != nil {
+        t.Fatalf("upload: %v", err)
+    }
+    if err := store.Upload(ctx, "taken.txt", strings.NewReader("taken")); err != nil {
+        t.Fatalf("upload: %v", err)
+    }
+
+    if err := store.MoveObject(ctx, "tmp/a/file.txt", "taken.txt"); !errors.Is(err, simplecontent.ErrObjectKeyExists) {
+        t.Fatalf("expected ErrObjectKeyExists, got %v", err)
+    }
+    if err := store.MoveObject(ctx, "missing.txt", "new.txt"); !errors.Is(err, simplecontent.ErrObjectNotFound) {
+        t.Fatalf("expected ErrObjectNotFound, got %v", err)
+    }
+
+    if err := store.MoveObject(ctx, "tmp/a/file.txt", "final/file.txt"); err != nil {
+        t.Fatalf("move: %v", err)
+    }
+    if _, err := store.GetObjectMeta(ctx, "tmp/a/file.txt"); !errors.Is(err, simplecontent.ErrObjectNotFound) {
+        t.Fatalf("expected old key to be gone, got %v", err)
+    }
+    rc, err := store.Download(ctx, "final/file.txt")
+    if err != nil {
+        t.Fatalf("download: %v", err)
+    }
+    defer rc.Close()
+    got, _ := io.ReadAll(rc)
+    if string(got) != "move me" {
+        t.Fatalf("unexpected content: %q", got)
+    }
+    if _, err := os.Stat(filepath.Join(tmp, "tmp")); !os.IsNotExist(err) {
+        t.Fatalf("expected empty source directories to be removed, got %v", err)
+    }
+}
+
+func TestFSBackend_AppendObject(t *testing.T) {
+    b, err := New(Config{BaseDir: t.TempDir()})
+    if err != nil {
+        t.Fatalf("new fs backend: %v", err)
+    }
+    store := b.(*Backend)
+    ctx := context.Background()
+
+    // The first append creates the object
+    if err := store.AppendObject(ctx, "logs/app.log", strings.NewReader("hello ")); err != nil {
+        t.Fatalf("append: %v", err)
+    }
+    if err := store.AppendObject(ctx, "logs/app.log", strings.NewReader("world")); err != nil {
+        t.Fatalf("append: %v", err)
+    }
+
+    meta, err := store.GetObjectMeta(ctx, "logs/app.log")
+    if err != nil {
+        t.Fatalf("get meta: %v", err)
+    }
+    if meta.Size != int64(len("hello world")) {
+        t.Fatalf("unexpected size: %d", meta.Size)
+    }
+    rc, err := store.Download(ctx, "logs/app.log")
+    if err != nil {
+        t.Fatalf("download: %v", err)
+    }
+    defer rc.Close()
+    got, _ := io.ReadAll(rc)
+    if string(got) != "hello world" {
+        t.Fatalf("unexpected content: %q", got)
+    }
+}
+
+func TestFSBackend_KeyPrefix(t *testing.T) {
+    tmp := t.TempDir()
+    b, err := New(Config{BaseDir: tmp, KeyPrefix: "app-a/"})
+    if err != nil {
+        t.Fatalf("new fs backend: %v", err)
+    }
+    ctx := context.Background()
+
+    if err := b.Upload(ctx, "x", strings.NewReader("prefixed")); err != nil {
+        t.Fatalf("upload: %v", err)
+    }
+    if data, err := os.ReadFile(filepath.Join(tmp, "app-a", "x")); err != nil || string(data) != "prefixed" {
+        t.Fatalf("expected data stored at app-a/x, got %q, %v", data, err)
+    }
+    if _, err := os.Stat(filepath.Join(tmp, "x")); !os.IsNotExist(err) {
+        t.Fatalf("expected nothing stored outside the prefix, got %v", err)
+    }
+
+    meta, err := b.GetObjectMeta(ctx, "x")
+    if err != nil {
+        t.Fatalf("get meta: %v", err)
+    }
+    if meta.Key != "x" {
+        t.Fatalf("expected key without prefix, got %q", meta.Key)
+    }
+    rc, err := b.Download(ctx, "x")
+    if err != nil {
+        t.Fatalf("download: %v", err)
+    }
+    defer rc.Close()
+    got, _ := io.ReadAll(rc)
+    if string(got) != "prefixed" {
+        t.Fatalf("unexpected content: %q", got)
+    }
+    if err := b.Delete(ctx, "x"); err != nil {
+        t.Fatalf("delete: %v", err)
+    }
+    if _, err := os.Stat(filepath.Join(tmp, "app-a", "x")); !os.IsNotExist(err) {
+        t.Fatalf("expected prefixed file removed, got %v", err)
+    }
+}
@@ -32,11 +32,11 @@ func (b *Backend) GetObjectMeta(ctx context.Context, objectKey string) (*simplec
 
 	data, exists := b.objects[objectKey]
 	if !exists {
-		return nil, errors.New("object not found")
+		return nil, simplecontent.ErrObjectNotFound
 	}
 	mimeType, exists := b.objectsMimeType[objectKey]
 	if !exists {
-		return nil, errors.New("object not found")
+		return nil, simplecontent.ErrObjectNotFound
 	}
 
 	meta := &simplecontent.ObjectMeta{
@@ -55,6 +55,16 @@ func (b *Backend) GetUploadURL(ctx context.Context, objectKey string) (string, e
 	return "", errors.New("direct upload required for memory backend")
 }
 
+// Capabilities reports that the memory backend cannot issue presigned URLs
+func (b *Backend) Capabilities() simplecontent.BackendCapabilities {
+	return simplecontent.BackendCapabilities{}
+}
+
+// Ping always succeeds; the memory backend is always reachable
+func (b *Backend) Ping(ctx context.Context) error {
+	return nil
+}
+
 // Upload uploads content directly
 func (b *Backend) Upload(ctx context.Context, objectKey string, reader io.Reader) error {
 	data, err := io.ReadAll(reader)
@@ -101,12 +111,59 @@ func (b *Backend) Download(ctx context.Context, objectKey string) (io.ReadCloser
 
 	data, exists := b.objects[objectKey]
 	if !exists {
-		return nil, errors.New("object not found")
+		return nil, simplecontent.ErrObjectNotFound
 	}
 
 	return io.NopCloser(bytes.NewReader(data)), nil
 }
 
+// ObjectExists reports whether content is stored under the key
+func (b *Backend) ObjectExists(ctx context.Context, objectKey string) (bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	_, exists := b.objects[objectKey]
+	return exists, nil
+}
+
+// MoveObject reassigns the stored data and MIME type to dstKey
+func (b *Backend) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data, exists := b.objects[srcKey]
+	if !exists {
+		return simplecontent.ErrObjectNotFound
+	}
+	if _, taken := b.objects[dstKey]; taken {
+		return simplecontent.ErrObjectKeyExists
+	}
+
+	b.objects[dstKey] = data
+	if mimeType, ok := b.objectsMimeType[srcKey]; ok {
+		b.objectsMimeType[dstKey] = mimeType
+	}
+	delete(b.objects, srcKey)
+	delete(b.objectsMimeType, srcKey)
+	return nil
+}
+
+// CopyObject copies the data and MIME type of srcKey to dstKey
+func (b *Backend) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data, exists := b.objects[srcKey]
+	if !exists {
+		return simplecontent.ErrObjectNotFound
+	}
+	b.objects[dstKey] = append([]byte(nil), data...)
+	if mimeType, ok := b.objectsMimeType[srcKey]; ok {
+		b.objectsMimeType[dstKey] = mimeType
+	}
+	return nil
+}
+
 // Delete deletes content
 func (b *Backend) Delete(ctx context.Context, objectKey string) error {
 	b.mu.Lock()
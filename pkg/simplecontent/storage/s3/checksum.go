@@ -0,0 +1,168 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// Supported values for Config.ChecksumAlgorithm
+const (
+	ChecksumCRC32C = "CRC32C"
+	ChecksumSHA256 = "SHA256"
+)
+
+// Metadata keys set by GetObjectMeta for the stored object checksum
+const (
+	MetaChecksumCRC32C = "checksum_crc32c"
+	MetaChecksumSHA256 = "checksum_sha256"
+)
+
+// ErrChecksumMismatch is returned by uploads when the checksum S3 computed for the
+// stored object differs from the checksum of the data that was sent.
+var ErrChecksumMismatch = errors.New("object checksum mismatch")
+
+// validateChecksumAlgorithm normalizes Config.ChecksumAlgorithm
+func validateChecksumAlgorithm(algorithm string) (string, error) {
+	switch normalized := strings.ToUpper(algorithm); normalized {
+	case "", ChecksumCRC32C, ChecksumSHA256:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("unsupported checksum algorithm %q (use %s or %s)", algorithm, ChecksumCRC32C, ChecksumSHA256)
+	}
+}
+
+func newChecksumHash(algorithm string) hash.Hash {
+	if algorithm == ChecksumSHA256 {
+		return sha256.New()
+	}
+	return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+}
+
+// uploadedChecksum returns the checksum S3 reported for the configured algorithm
+func uploadedChecksum(out *manager.UploadOutput, algorithm string) string {
+	var checksum *string
+	switch algorithm {
+	case ChecksumCRC32C:
+		checksum = out.ChecksumCRC32C
+	case ChecksumSHA256:
+		checksum = out.ChecksumSHA256
+	}
+	return aws.ToString(checksum)
+}
+
+// isChecksumUnsupported reports whether the service rejected the checksum headers,
+// as older MinIO releases do
+func isChecksumUnsupported(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "NotImplemented":
+		return true
+	case "InvalidArgument", "InvalidRequest":
+		return strings.Contains(strings.ToLower(apiErr.ErrorMessage()), "checksum")
+	}
+	return false
+}
+
+// upload sends the object, adding the configured checksum. The checksum S3 reports for a
+// single-part upload is compared with the data sent; multipart uploads report a composite
+// checksum that S3 has already validated part by part.
+//
+// When the service does not support checksums, the backend falls back to Content-MD5 for
+// this and all later uploads. Content-MD5 only covers single-part uploads, so objects larger
+// than one part are then stored without an integrity check.
+func (b *Backend) upload(ctx context.Context, input *s3.PutObjectInput) error {
+	uploader := manager.NewUploader(b.client)
+	algorithm := b.config.ChecksumAlgorithm
+	if algorithm == "" {
+		_, err := uploader.Upload(ctx, input)
+		return err
+	}
+	if b.checksumUnsupported.Load() {
+		return b.uploadWithContentMD5(ctx, uploader, input)
+	}
+
+	source := input.Body
+	h := newChecksumHash(algorithm)
+	checksumInput := *input
+	checksumInput.Body = io.TeeReader(source, h)
+	checksumInput.ChecksumAlgorithm = types.ChecksumAlgorithm(algorithm)
+
+	out, err := uploader.Upload(ctx, &checksumInput)
+	if err != nil {
+		if !isChecksumUnsupported(err) {
+			return err
+		}
+		b.checksumUnsupported.Store(true)
+		slog.Warn("S3 service rejected checksum headers, falling back to Content-MD5", "bucket", b.bucket, "error", err)
+
+		// The source can only be sent again if it can be rewound
+		seeker, ok := source.(io.Seeker)
+		if !ok {
+			return err
+		}
+		if _, seekErr := seeker.Seek(0, io.SeekStart); seekErr != nil {
+			return err
+		}
+		return b.uploadWithContentMD5(ctx, uploader, input)
+	}
+
+	got := uploadedChecksum(out, algorithm)
+	if got == "" || strings.Contains(got, "-") {
+		// No checksum reported or a composite multipart checksum
+		return nil
+	}
+	if want := base64.StdEncoding.EncodeToString(h.Sum(nil)); got != want {
+		// input.Key already carries the key prefix, so delete it as is rather than with Delete
+		key := aws.ToString(input.Key)
+		_, delErr := b.client.DeleteObject(context.WithoutCancel(ctx), &s3.DeleteObjectInput{Bucket: input.Bucket, Key: input.Key})
+		if delErr != nil {
+			slog.Warn("Failed to delete object after checksum mismatch", "key", key, "error", delErr)
+		}
+		return fmt.Errorf("%w: %s %s sent, %s stored", ErrChecksumMismatch, algorithm, want, got)
+	}
+	return nil
+}
+
+// uploadWithContentMD5 sends bodies that fit in a single part with a Content-MD5 header
+func (b *Backend) uploadWithContentMD5(ctx context.Context, uploader *manager.Uploader, input *s3.PutObjectInput) error {
+	head, err := io.ReadAll(io.LimitReader(input.Body, manager.DefaultUploadPartSize+1))
+	if err != nil {
+		return err
+	}
+
+	md5Input := *input
+	if int64(len(head)) <= manager.DefaultUploadPartSize {
+		sum := md5.Sum(head)
+		md5Input.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+		md5Input.Body = bytes.NewReader(head)
+	} else {
+		md5Input.Body = io.MultiReader(bytes.NewReader(head), input.Body)
+	}
+
+	// Keep the SDK from adding its own default checksum headers
+	_, err = uploader.Upload(ctx, &md5Input, func(u *manager.Uploader) {
+		u.ClientOptions = append(u.ClientOptions, func(o *s3.Options) {
+			o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		})
+	})
+	return err
+}
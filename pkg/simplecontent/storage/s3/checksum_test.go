@@ -0,0 +1,253 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// fakeChecksumS3 is a minimal path-style S3 endpoint that stores objects with the
+// checksum headers they were uploaded with
+type fakeChecksumS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	headers  map[string]http.Header
+	deletes  []string
+	puts     int
+	override string // checksum reported on PUT instead of the one received
+	legacy   bool   // reject checksum headers like older MinIO releases
+}
+
+func newFakeChecksumS3(t *testing.T) (*fakeChecksumS3, *httptest.Server) {
+	f := &fakeChecksumS3{objects: map[string][]byte{}, headers: map[string]http.Header{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeChecksumS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		f.puts++
+		if f.legacy {
+			for name := range r.Header {
+				if strings.HasPrefix(strings.ToLower(name), "x-amz-checksum-") {
+					w.WriteHeader(http.StatusNotImplemented)
+					fmt.Fprint(w, "<Error><Code>NotImplemented</Code><Message>checksum not supported</Message></Error>")
+					return
+				}
+			}
+		}
+		// Conditional writes as S3 answers them
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+			current, ok := f.objects[key]
+			if !ok || ifMatch != fmt.Sprintf("\"%x\"", md5.Sum(current)) {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				fmt.Fprint(w, "<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>")
+				return
+			}
+		}
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = body
+		f.headers[key] = r.Header.Clone()
+		if crc := r.Header.Get("X-Amz-Checksum-Crc32c"); crc != "" {
+			if f.override != "" {
+				crc = f.override
+			}
+			w.Header().Set("X-Amz-Checksum-Crc32c", crc)
+		}
+		w.Header().Set("ETag", fmt.Sprintf("\"%x\"", md5.Sum(body)))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodHead:
+		body, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("X-Amz-Checksum-Mode") == "ENABLED" {
+			if crc := f.headers[key].Get("X-Amz-Checksum-Crc32c"); crc != "" {
+				w.Header().Set("X-Amz-Checksum-Crc32c", crc)
+			}
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", fmt.Sprintf("\"%x\"", md5.Sum(body)))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		body, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>")
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Header().Set("ETag", fmt.Sprintf("\"%x\"", md5.Sum(body)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		f.deletes = append(f.deletes, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newChecksumBackend(t *testing.T, endpoint string) *Backend {
+	t.Helper()
+	return newChecksumBackendWithPrefix(t, endpoint, "")
+}
+
+func newChecksumBackendWithPrefix(t *testing.T, endpoint, keyPrefix string) *Backend {
+	t.Helper()
+	backend, err := New(Config{
+		Bucket:            "test-bucket",
+		Region:            "us-east-1",
+		AccessKeyID:       "test-key",
+		SecretAccessKey:   "test-secret",
+		Endpoint:          endpoint,
+		UsePathStyle:      true,
+		ChecksumAlgorithm: "crc32c",
+		KeyPrefix:         keyPrefix,
+	})
+	require.NoError(t, err)
+	return backend.(*Backend)
+}
+
+func TestS3Backend_ChecksumAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	content := "checksummed content"
+	crc := crc32.Checksum([]byte(content), crc32.MakeTable(crc32.Castagnoli))
+	wantCRC := base64.StdEncoding.EncodeToString([]byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)})
+
+	t.Run("InvalidAlgorithm", func(t *testing.T) {
+		_, err := New(Config{Bucket: "test-bucket", ChecksumAlgorithm: "MD4"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported checksum algorithm")
+	})
+
+	t.Run("HeaderSentAndRoundTrips", func(t *testing.T) {
+		fake, srv := newFakeChecksumS3(t)
+		backend := newChecksumBackend(t, srv.URL)
+
+		require.NoError(t, backend.Upload(ctx, "docs/a.txt", strings.NewReader(content)))
+
+		sent := fake.headers["/test-bucket/docs/a.txt"]
+		require.NotNil(t, sent)
+		assert.Equal(t, wantCRC, sent.Get("X-Amz-Checksum-Crc32c"))
+
+		meta, err := backend.GetObjectMeta(ctx, "docs/a.txt")
+		require.NoError(t, err)
+		assert.Equal(t, wantCRC, meta.Metadata[MetaChecksumCRC32C])
+	})
+
+	t.Run("MismatchFailsAndDeletes", func(t *testing.T) {
+		fake, srv := newFakeChecksumS3(t)
+		fake.override = "AAAAAA=="
+		backend := newChecksumBackend(t, srv.URL)
+
+		err := backend.Upload(ctx, "docs/b.txt", strings.NewReader(content))
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+		assert.Equal(t, []string{"/test-bucket/docs/b.txt"}, fake.deletes)
+	})
+
+	t.Run("MismatchDeletesPrefixedKey", func(t *testing.T) {
+		fake, srv := newFakeChecksumS3(t)
+		fake.override = "AAAAAA=="
+		backend := newChecksumBackendWithPrefix(t, srv.URL, "app-a")
+
+		err := backend.Upload(ctx, "docs/b.txt", strings.NewReader(content))
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+		assert.Equal(t, []string{"/test-bucket/app-a/docs/b.txt"}, fake.deletes)
+		assert.NotContains(t, fake.objects, "/test-bucket/app-a/docs/b.txt", "the corrupt object is removed")
+	})
+
+	t.Run("FallsBackToContentMD5", func(t *testing.T) {
+		fake, srv := newFakeChecksumS3(t)
+		fake.legacy = true
+		backend := newChecksumBackend(t, srv.URL)
+		md5Sum := md5.Sum([]byte(content))
+		wantMD5 := base64.StdEncoding.EncodeToString(md5Sum[:])
+
+		// A seekable source is resent with Content-MD5
+		require.NoError(t, backend.Upload(ctx, "docs/c.txt", bytes.NewReader([]byte(content))))
+		assert.Equal(t, 2, fake.puts)
+		assert.Equal(t, wantMD5, fake.headers["/test-bucket/docs/c.txt"].Get("Content-Md5"))
+
+		// Later uploads go straight to Content-MD5
+		require.NoError(t, backend.Upload(ctx, "docs/d.txt", io.NopCloser(strings.NewReader(content))))
+		assert.Equal(t, 3, fake.puts)
+		assert.Equal(t, wantMD5, fake.headers["/test-bucket/docs/d.txt"].Get("Content-Md5"))
+	})
+}
+
+func TestS3Backend_ConditionalUpload(t *testing.T) {
+	ctx := context.Background()
+	fake, srv := newFakeChecksumS3(t)
+	backend := newChecksumBackend(t, srv.URL)
+
+	require.NoError(t, backend.Upload(ctx, "docs/v.txt", strings.NewReader("v1")))
+	meta, err := backend.GetObjectMeta(ctx, "docs/v.txt")
+	require.NoError(t, err)
+
+	// A writer holding the current ETag replaces the object
+	require.NoError(t, backend.UploadWithParams(ctx, strings.NewReader("v2"), simplecontent.UploadParams{
+		ObjectKey: "docs/v.txt",
+		IfMatch:   meta.ETag,
+	}))
+	assert.Equal(t, `"`+meta.ETag+`"`, fake.headers["/test-bucket/docs/v.txt"].Get("If-Match"))
+
+	// The ETag of v1 is now stale
+	err = backend.UploadWithParams(ctx, strings.NewReader("v3"), simplecontent.UploadParams{
+		ObjectKey: "docs/v.txt",
+		IfMatch:   meta.ETag,
+	})
+	require.ErrorIs(t, err, simplecontent.ErrPreconditionFailed)
+	assert.Equal(t, "v2", string(fake.objects["/test-bucket/docs/v.txt"]))
+}
+
+func TestS3Backend_ZeroByteUpload(t *testing.T) {
+	ctx := context.Background()
+	fake, srv := newFakeChecksumS3(t)
+	backend := newChecksumBackend(t, srv.URL)
+
+	// A seekable empty body, and a stream that ends immediately
+	require.NoError(t, backend.Upload(ctx, "empty/seekable", strings.NewReader("")))
+	require.NoError(t, backend.UploadWithParams(ctx, io.MultiReader(), simplecontent.UploadParams{ObjectKey: "empty/stream", MimeType: "text/plain"}))
+
+	for _, key := range []string{"empty/seekable", "empty/stream"} {
+		body, stored := fake.objects["/test-bucket/"+key]
+		require.True(t, stored, "%s should be written as a zero-byte object", key)
+		assert.Empty(t, body)
+
+		meta, err := backend.GetObjectMeta(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), meta.Size)
+
+		reader, err := backend.Download(ctx, key)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		assert.Empty(t, data)
+	}
+	assert.Equal(t, 2, fake.puts)
+}
@@ -0,0 +1,164 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+const (
+	defaultParallelDownloadPartSize    = 8 << 20 // 8 MiB
+	defaultParallelDownloadConcurrency = 4
+)
+
+// parallelDownload opens objects of at least Config.ParallelDownloadThreshold bytes as a
+// reader fed by concurrent range GETs. It reports false for smaller objects, which are
+// downloaded with a single GET.
+func (b *Backend) parallelDownload(ctx context.Context, objectKey string) (io.ReadCloser, bool, error) {
+	head, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(objectKey)),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, false, simplecontent.ErrObjectNotFound
+		}
+		return nil, false, fmt.Errorf("failed to download from S3: %w", err)
+	}
+	if err := b.checkSSE(objectKey, head.ServerSideEncryption); err != nil {
+		return nil, false, err
+	}
+
+	size := aws.ToInt64(head.ContentLength)
+	if size < b.config.ParallelDownloadThreshold {
+		return nil, false, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r := &rangeReader{
+		cancel: cancel,
+		queue:  make(chan chan rangePart, b.config.ParallelDownloadConcurrency),
+		slots:  make(chan struct{}, b.config.ParallelDownloadConcurrency),
+	}
+	go r.schedule(ctx, b, objectKey, aws.ToString(head.ETag), size)
+	return r, true, nil
+}
+
+// getRange fetches bytes [start, end] of an object. The ETag pins every range to the
+// object version that was measured, so an overwrite mid-download fails instead of
+// mixing two versions.
+func (b *Backend) getRange(ctx context.Context, objectKey, etag string, start, end int64) ([]byte, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(objectKey)),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+	}
+	if etag != "" {
+		input.IfMatch = aws.String(etag)
+	}
+	result, err := b.client.GetObject(ctx, input)
+	if err != nil {
+		var notFound *types.NoSuchKey
+		if errors.As(err, &notFound) {
+			return nil, simplecontent.ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to download range %d-%d from S3: %w", start, end, err)
+	}
+	defer result.Body.Close()
+
+	data := make([]byte, end-start+1)
+	if _, err := io.ReadFull(result.Body, data); err != nil {
+		return nil, fmt.Errorf("failed to read range %d-%d from S3: %w", start, end, err)
+	}
+	return data, nil
+}
+
+// rangePart is the result of one range GET
+type rangePart struct {
+	data []byte
+	err  error
+}
+
+// rangeReader returns the parts of a parallel download in order. A slot is taken before
+// each range is fetched and given back once the reader has consumed it, so at most
+// ParallelDownloadConcurrency parts are in flight or buffered at any time.
+type rangeReader struct {
+	cancel  context.CancelFunc
+	queue   chan chan rangePart
+	slots   chan struct{}
+	current []byte
+	holding bool
+	err     error
+}
+
+// schedule starts the range GETs in object order and queues their results
+func (r *rangeReader) schedule(ctx context.Context, b *Backend, objectKey, etag string, size int64) {
+	defer close(r.queue)
+	partSize := b.config.ParallelDownloadPartSize
+	for start := int64(0); start < size; start += partSize {
+		select {
+		case r.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		end := min(start+partSize, size) - 1
+		result := make(chan rangePart, 1)
+		go func(start, end int64) {
+			data, err := b.getRange(ctx, objectKey, etag, start, end)
+			result <- rangePart{data: data, err: err}
+		}(start, end)
+
+		select {
+		case r.queue <- result:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	for len(r.current) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.holding {
+			<-r.slots
+			r.holding = false
+		}
+		result, ok := <-r.queue
+		if !ok {
+			r.err = io.EOF
+			continue
+		}
+		part := <-result
+		if part.err != nil {
+			r.err = part.err
+			r.cancel()
+			continue
+		}
+		r.current = part.data
+		r.holding = true
+	}
+
+	n := copy(p, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
+
+// Close stops the download; fetches still running are cancelled
+func (r *rangeReader) Close() error {
+	r.cancel()
+	r.current = nil
+	if r.err == nil {
+		r.err = errors.New("read from closed download")
+	}
+	return nil
+}
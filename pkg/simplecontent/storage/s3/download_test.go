@@ -0,0 +1,179 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// fakeRangeS3 is a minimal path-style S3 endpoint that serves stored objects with range
+// and If-Match support
+type fakeRangeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	ranges  int
+	gets    int
+}
+
+func newFakeRangeS3(t *testing.T) (*fakeRangeS3, *httptest.Server) {
+	f := &fakeRangeS3{objects: map[string][]byte{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeRangeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	data, ok := f.objects[r.URL.Path]
+	if r.Method == http.MethodGet {
+		f.gets++
+		if r.Header.Get("Range") != "" {
+			f.ranges++
+		}
+	}
+	f.mu.Unlock()
+
+	if !ok {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>")
+		return
+	}
+	w.Header().Set("ETag", fmt.Sprintf("%q", fmt.Sprintf("etag-%d", len(data))))
+	http.ServeContent(w, r, "", time.Unix(0, 0), bytes.NewReader(data))
+}
+
+func newRangeBackend(t *testing.T, endpoint string, threshold, partSize int64, concurrency int) *Backend {
+	backend, err := New(Config{
+		Bucket:                      "test-bucket",
+		AccessKeyID:                 "minioadmin",
+		SecretAccessKey:             "minioadmin",
+		Endpoint:                    endpoint,
+		UsePathStyle:                true,
+		ParallelDownloadThreshold:   threshold,
+		ParallelDownloadPartSize:    partSize,
+		ParallelDownloadConcurrency: concurrency,
+	})
+	require.NoError(t, err)
+	return backend.(*Backend)
+}
+
+func TestS3Backend_ParallelDownload(t *testing.T) {
+	ctx := context.Background()
+	data := make([]byte, 10*64*1024+123) // last range is short
+	rand.New(rand.NewSource(1)).Read(data)
+
+	fake, srv := newFakeRangeS3(t)
+	fake.objects["/test-bucket/big.bin"] = data
+	fake.objects["/test-bucket/small.bin"] = data[:1000]
+
+	download := func(t *testing.T, backend *Backend, key string) []byte {
+		reader, err := backend.Download(ctx, key)
+		require.NoError(t, err)
+		defer reader.Close()
+		got, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return got
+	}
+
+	t.Run("MatchesSequentialDownload", func(t *testing.T) {
+		sequential := download(t, newRangeBackend(t, srv.URL, 0, 0, 0), "big.bin")
+		fake.ranges = 0
+
+		parallel := download(t, newRangeBackend(t, srv.URL, 1024, 64*1024, 3), "big.bin")
+		assert.Equal(t, sequential, parallel)
+		assert.Equal(t, data, parallel)
+		assert.Equal(t, 11, fake.ranges)
+	})
+
+	t.Run("BelowThresholdUsesSingleGet", func(t *testing.T) {
+		fake.gets, fake.ranges = 0, 0
+		got := download(t, newRangeBackend(t, srv.URL, 1024*1024, 64*1024, 3), "small.bin")
+		assert.Equal(t, data[:1000], got)
+		assert.Equal(t, 1, fake.gets)
+		assert.Zero(t, fake.ranges)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := newRangeBackend(t, srv.URL, 1, 64*1024, 3).Download(ctx, "missing.bin")
+		assert.ErrorIs(t, err, simplecontent.ErrObjectNotFound)
+	})
+
+	t.Run("CloseBeforeEnd", func(t *testing.T) {
+		reader, err := newRangeBackend(t, srv.URL, 1, 1024, 2).Download(ctx, "big.bin")
+		require.NoError(t, err)
+		buf := make([]byte, 10)
+		_, err = io.ReadFull(reader, buf)
+		require.NoError(t, err)
+		assert.Equal(t, data[:10], buf)
+		require.NoError(t, reader.Close())
+		_, err = reader.Read(buf)
+		assert.Error(t, err)
+	})
+}
+
+// TestS3Backend_ParallelDownloadIntegration compares parallel and sequential downloads
+// against a running MinIO instance
+func TestS3Backend_ParallelDownloadIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	endpoint := os.Getenv("AWS_S3_ENDPOINT")
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	bucket := os.Getenv("AWS_S3_BUCKET")
+	if endpoint == "" || accessKey == "" || secretKey == "" || bucket == "" {
+		t.Skip("Skipping integration test: S3/MinIO environment variables not set")
+	}
+
+	newBackend := func(threshold int64) simplecontent.BlobStore {
+		backend, err := New(Config{
+			Bucket:                      bucket,
+			Region:                      "us-east-1",
+			AccessKeyID:                 accessKey,
+			SecretAccessKey:             secretKey,
+			Endpoint:                    endpoint,
+			UsePathStyle:                true,
+			CreateBucketIfNotExist:      true,
+			ParallelDownloadThreshold:   threshold,
+			ParallelDownloadPartSize:    256 * 1024,
+			ParallelDownloadConcurrency: 4,
+		})
+		require.NoError(t, err)
+		return backend
+	}
+	sequential := newBackend(0)
+	parallel := newBackend(1)
+
+	ctx := context.Background()
+	objectKey := fmt.Sprintf("test/parallel/%d/file.bin", time.Now().UnixNano())
+	data := make([]byte, 3*1024*1024+777)
+	rand.New(rand.NewSource(2)).Read(data)
+	require.NoError(t, sequential.Upload(ctx, objectKey, bytes.NewReader(data)))
+	t.Cleanup(func() { _ = sequential.Delete(context.Background(), objectKey) })
+
+	readAll := func(store simplecontent.BlobStore) []byte {
+		reader, err := store.Download(ctx, objectKey)
+		require.NoError(t, err)
+		defer reader.Close()
+		got, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return got
+	}
+	expected := readAll(sequential)
+	assert.Equal(t, data, expected)
+	assert.Equal(t, expected, readAll(parallel))
+}
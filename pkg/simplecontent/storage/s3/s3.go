@@ -5,16 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	smithyendpoints "github.com/aws/smithy-go/endpoints"
 	"github.com/tendant/simple-content/pkg/simplecontent"
 )
@@ -30,11 +33,30 @@ type Config struct {
 	UsePathStyle    bool   // Use path-style addressing (default: false)
 	PresignDuration int    // Duration in seconds for presigned URLs (default: 3600)
 
+	// KeyPrefix namespaces the backend within a shared bucket, e.g. "app-a". Every object
+	// key is stored under the prefix and callers keep using keys without it.
+	KeyPrefix string
+
 	// Server-side encryption options
 	EnableSSE    bool   // Enable server-side encryption
 	SSEAlgorithm string // SSE algorithm (AES256 or aws:kms)
 	SSEKMSKeyID  string // Optional KMS key ID for aws:kms algorithm
 
+	SSEBucketKeyEnabled bool // Use an S3 Bucket Key for SSE-KMS to reduce KMS requests
+	RequireSSE          bool // Reject reads of objects that are not server-side encrypted
+
+	// ChecksumAlgorithm adds an object checksum to uploads: ChecksumCRC32C or ChecksumSHA256.
+	// Empty leaves checksums to the SDK defaults.
+	ChecksumAlgorithm string
+
+	// Objects of at least ParallelDownloadThreshold bytes are downloaded with concurrent
+	// range GETs of ParallelDownloadPartSize bytes (default 8 MiB), at most
+	// ParallelDownloadConcurrency (default 4) at a time, which also bounds the memory held
+	// per download. Zero downloads every object with a single GET.
+	ParallelDownloadThreshold   int64
+	ParallelDownloadPartSize    int64
+	ParallelDownloadConcurrency int
+
 	// MinIO/S3-compatible service options
 	CreateBucketIfNotExist bool // Create bucket if it doesn't exist
 }
@@ -46,6 +68,10 @@ type Backend struct {
 	presignClient   *s3.PresignClient
 	presignDuration time.Duration
 	config          Config
+	keyPrefix       string // KeyPrefix with a trailing slash, or empty
+
+	// checksumUnsupported is set once the service rejects checksum headers
+	checksumUnsupported atomic.Bool
 }
 
 // New creates a new S3-compatible storage backend
@@ -54,6 +80,8 @@ func New(config Config) (simplecontent.BlobStore, error) {
 		return nil, errors.New("bucket name is required")
 	}
 
+	var err error
+
 	if config.Region == "" {
 		config.Region = "us-east-1"
 	}
@@ -62,9 +90,20 @@ func New(config Config) (simplecontent.BlobStore, error) {
 		config.PresignDuration = 3600 // 1 hour default
 	}
 
+	if config.ParallelDownloadPartSize <= 0 {
+		config.ParallelDownloadPartSize = defaultParallelDownloadPartSize
+	}
+	if config.ParallelDownloadConcurrency <= 0 {
+		config.ParallelDownloadConcurrency = defaultParallelDownloadConcurrency
+	}
+
+	config.ChecksumAlgorithm, err = validateChecksumAlgorithm(config.ChecksumAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set up AWS config
 	var awsCfg aws.Config
-	var err error
 
 	if config.AccessKeyID != "" && config.SecretAccessKey != "" {
 		// Use provided credentials
@@ -113,6 +152,9 @@ func New(config Config) (simplecontent.BlobStore, error) {
 		presignDuration: time.Duration(config.PresignDuration) * time.Second,
 		config:          config,
 	}
+	if prefix := strings.Trim(config.KeyPrefix, "/"); prefix != "" {
+		backend.keyPrefix = prefix + "/"
+	}
 
 	// Create bucket if requested
 	if config.CreateBucketIfNotExist {
@@ -145,7 +187,19 @@ func (r *customEndpointResolver) ResolveEndpoint(ctx context.Context, params s3.
 	}, nil
 }
 
-// createBucketIfNotExists creates the bucket if it doesn't exist
+// key returns the bucket key of objectKey under the configured KeyPrefix
+func (b *Backend) key(objectKey string) string {
+	return b.keyPrefix + objectKey
+}
+
+// isMinIO reports whether the backend targets a MinIO-style S3-compatible service:
+// a custom endpoint addressed path-style
+func (b *Backend) isMinIO() bool {
+	return b.config.Endpoint != "" && b.config.UsePathStyle
+}
+
+// createBucketIfNotExists creates the bucket if it doesn't exist. Creating a bucket that
+// already exists, including one created concurrently by another process, is not an error.
 func (b *Backend) createBucketIfNotExists(ctx context.Context) error {
 	// Check if bucket exists
 	_, err := b.client.HeadBucket(ctx, &s3.HeadBucketInput{
@@ -157,9 +211,9 @@ func (b *Backend) createBucketIfNotExists(ctx context.Context) error {
 		return nil
 	}
 
-	// Check if error is "bucket not found"
-	var notFound *types.NotFound
-	if !errors.As(err, &notFound) {
+	// Check if error is "bucket not found"; HEAD responses have no body, so
+	// some S3-compatible services only report a bare 404
+	if !isNotFound(err) {
 		return fmt.Errorf("failed to check bucket: %w", err)
 	}
 
@@ -168,7 +222,7 @@ func (b *Backend) createBucketIfNotExists(ctx context.Context) error {
 		Bucket: aws.String(b.bucket),
 	}
 
-	// Add location constraint for regions other than us-east-1
+	// us-east-1 is the default location and rejects an explicit constraint
 	if b.config.Region != "us-east-1" {
 		createInput.CreateBucketConfiguration = &types.CreateBucketConfiguration{
 			LocationConstraint: types.BucketLocationConstraint(b.config.Region),
@@ -176,28 +230,145 @@ func (b *Backend) createBucketIfNotExists(ctx context.Context) error {
 	}
 
 	_, err = b.client.CreateBucket(ctx, createInput)
-	if err != nil {
+	if err != nil && createInput.CreateBucketConfiguration != nil && b.isMinIO() && isLocationConstraintError(err) {
+		// MinIO only accepts a constraint matching its own configured region; without
+		// one it creates the bucket in that region
+		createInput.CreateBucketConfiguration = nil
+		_, err = b.client.CreateBucket(ctx, createInput)
+	}
+	if err != nil && !isBucketAlreadyExists(err) {
 		return fmt.Errorf("failed to create bucket: %w", err)
 	}
 
 	return nil
 }
 
-// GetObjectMeta retrieves metadata for an object in S3
+// isNotFound reports whether err is a missing bucket or object response
+func isNotFound(err error) bool {
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return true
+	}
+	var noSuchBucket *types.NoSuchBucket
+	if errors.As(err, &noSuchBucket) {
+		return true
+	}
+	var respErr *awshttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound
+}
+
+// isBucketAlreadyExists reports whether CreateBucket failed because the bucket exists
+func isBucketAlreadyExists(err error) bool {
+	var ownedByYou *types.BucketAlreadyOwnedByYou
+	if errors.As(err, &ownedByYou) {
+		return true
+	}
+	var exists *types.BucketAlreadyExists
+	if errors.As(err, &exists) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "BucketAlreadyOwnedByYou", "BucketAlreadyExists":
+			return true
+		}
+	}
+	return false
+}
+
+// isLocationConstraintError reports whether CreateBucket rejected the LocationConstraint
+func isLocationConstraintError(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "InvalidLocationConstraint", "IllegalLocationConstraintException", "InvalidRegion":
+		return true
+	}
+	return false
+}
+
+// isPreconditionFailed reports whether S3 rejected a conditional write because the object
+// changed, or because a concurrent conditional write to it was in progress
+func isPreconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "PreconditionFailed", "ConditionalRequestConflict":
+		return true
+	}
+	return false
+}
+
+// ErrObjectNotEncrypted is returned by reads when Config.RequireSSE is set and
+// the object is not server-side encrypted.
+var ErrObjectNotEncrypted = errors.New("object is not server-side encrypted")
+
+// Metadata keys set by GetObjectMeta describing server-side encryption
+const (
+	MetaServerSideEncryption = "server_side_encryption"
+	MetaSSEKMSKeyID          = "sse_kms_key_id"
+	MetaSSEBucketKeyEnabled  = "sse_bucket_key_enabled"
+)
+
+// applySSE sets the configured server-side encryption options on a PutObject request
+func (b *Backend) applySSE(input *s3.PutObjectInput) {
+	if !b.config.EnableSSE {
+		return
+	}
+	switch b.config.SSEAlgorithm {
+	case "AES256":
+		input.ServerSideEncryption = types.ServerSideEncryptionAes256
+	case "aws:kms":
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		if b.config.SSEKMSKeyID != "" {
+			input.SSEKMSKeyId = aws.String(b.config.SSEKMSKeyID)
+		}
+		if b.config.SSEBucketKeyEnabled {
+			input.BucketKeyEnabled = aws.Bool(true)
+		}
+	}
+}
+
+// checkSSE enforces Config.RequireSSE for the encryption reported by S3 on read
+func (b *Backend) checkSSE(objectKey string, sse types.ServerSideEncryption) error {
+	if b.config.RequireSSE && sse == "" {
+		return fmt.Errorf("%w: %s", ErrObjectNotEncrypted, objectKey)
+	}
+	return nil
+}
+
+// GetObjectMeta retrieves metadata for an object in S3.
+// Server-side encryption details returned by HeadObject are surfaced in Metadata
+// under MetaServerSideEncryption, MetaSSEKMSKeyID and MetaSSEBucketKeyEnabled. When
+// Config.ChecksumAlgorithm is set, the stored checksum is surfaced under MetaChecksumCRC32C
+// or MetaChecksumSHA256.
 func (b *Backend) GetObjectMeta(ctx context.Context, objectKey string) (*simplecontent.ObjectMeta, error) {
-	result, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+	input := &s3.HeadObjectInput{
 		Bucket: aws.String(b.bucket),
-		Key:    aws.String(objectKey),
-	})
+		Key:    aws.String(b.key(objectKey)),
+	}
+	if b.config.ChecksumAlgorithm != "" {
+		input.ChecksumMode = types.ChecksumModeEnabled
+	}
+	result, err := b.client.HeadObject(ctx, input)
 
 	if err != nil {
 		var notFound *types.NotFound
 		if errors.As(err, &notFound) {
-			return nil, errors.New("object not found")
+			return nil, simplecontent.ErrObjectNotFound
 		}
 		return nil, fmt.Errorf("failed to get object metadata: %w", err)
 	}
 
+	if err := b.checkSSE(objectKey, result.ServerSideEncryption); err != nil {
+		return nil, err
+	}
+
 	contentType := "application/octet-stream"
 	if result.ContentType != nil {
 		contentType = *result.ContentType
@@ -208,6 +379,21 @@ func (b *Backend) GetObjectMeta(ctx context.Context, objectKey string) (*simplec
 		metadata[k] = v
 	}
 	metadata["content_type"] = contentType
+	if result.ServerSideEncryption != "" {
+		metadata[MetaServerSideEncryption] = string(result.ServerSideEncryption)
+	}
+	if result.SSEKMSKeyId != nil {
+		metadata[MetaSSEKMSKeyID] = *result.SSEKMSKeyId
+	}
+	if result.BucketKeyEnabled != nil && *result.BucketKeyEnabled {
+		metadata[MetaSSEBucketKeyEnabled] = "true"
+	}
+	if result.ChecksumCRC32C != nil {
+		metadata[MetaChecksumCRC32C] = *result.ChecksumCRC32C
+	}
+	if result.ChecksumSHA256 != nil {
+		metadata[MetaChecksumSHA256] = *result.ChecksumSHA256
+	}
 
 	meta := &simplecontent.ObjectMeta{
 		Key:         objectKey,
@@ -221,25 +407,116 @@ func (b *Backend) GetObjectMeta(ctx context.Context, objectKey string) (*simplec
 	return meta, nil
 }
 
+// ObjectExists reports whether an object is stored under the key
+func (b *Backend) ObjectExists(ctx context.Context, objectKey string) (bool, error) {
+	_, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(objectKey)),
+	})
+	if err == nil {
+		return true, nil
+	}
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check object: %w", err)
+}
+
+// GetUploadOffset returns how many bytes of the object are stored. For an unfinished
+// multipart upload this is the total size of its completed parts; once the object is
+// complete it is the object size. A key with neither reports 0.
+func (b *Backend) GetUploadOffset(ctx context.Context, objectKey string) (int64, error) {
+	uploadID, err := b.latestMultipartUpload(ctx, objectKey)
+	if err != nil {
+		return 0, err
+	}
+
+	if uploadID != "" {
+		var total int64
+		paginator := s3.NewListPartsPaginator(b.client, &s3.ListPartsInput{
+			Bucket:   aws.String(b.bucket),
+			Key:      aws.String(b.key(objectKey)),
+			UploadId: aws.String(uploadID),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("failed to list upload parts: %w", err)
+			}
+			for _, part := range page.Parts {
+				total += aws.ToInt64(part.Size)
+			}
+		}
+		return total, nil
+	}
+
+	result, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(objectKey)),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	return aws.ToInt64(result.ContentLength), nil
+}
+
+// latestMultipartUpload returns the ID of the most recently started multipart upload
+// for objectKey, or "" if none is in progress
+func (b *Backend) latestMultipartUpload(ctx context.Context, objectKey string) (string, error) {
+	var (
+		uploadID  string
+		initiated time.Time
+	)
+	paginator := s3.NewListMultipartUploadsPaginator(b.client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(b.key(objectKey)),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to list multipart uploads: %w", err)
+		}
+		for _, upload := range page.Uploads {
+			// Prefix also matches longer keys
+			if aws.ToString(upload.Key) != b.key(objectKey) {
+				continue
+			}
+			if uploadID == "" || aws.ToTime(upload.Initiated).After(initiated) {
+				uploadID = aws.ToString(upload.UploadId)
+				initiated = aws.ToTime(upload.Initiated)
+			}
+		}
+	}
+	return uploadID, nil
+}
+
+// Ping checks that the bucket is reachable with the configured credentials
+func (b *Backend) Ping(ctx context.Context) error {
+	if _, err := b.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(b.bucket)}); err != nil {
+		return fmt.Errorf("failed to reach bucket %s: %w", b.bucket, err)
+	}
+	return nil
+}
+
+// Capabilities reports that S3 presigns upload, download and preview URLs
+func (b *Backend) Capabilities() simplecontent.BackendCapabilities {
+	return simplecontent.BackendCapabilities{PresignedUpload: true, PresignedDownload: true}
+}
+
 // GetUploadURL returns a presigned URL for uploading content
 func (b *Backend) GetUploadURL(ctx context.Context, objectKey string) (string, error) {
 	input := &s3.PutObjectInput{
 		Bucket: aws.String(b.bucket),
-		Key:    aws.String(objectKey),
+		Key:    aws.String(b.key(objectKey)),
 	}
 
 	// Add server-side encryption if enabled
-	if b.config.EnableSSE {
-		switch b.config.SSEAlgorithm {
-		case "AES256":
-			input.ServerSideEncryption = types.ServerSideEncryptionAes256
-		case "aws:kms":
-			input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
-			if b.config.SSEKMSKeyID != "" {
-				input.SSEKMSKeyId = aws.String(b.config.SSEKMSKeyID)
-			}
-		}
-	}
+	b.applySSE(input)
 
 	result, err := b.presignClient.PresignPutObject(ctx, input, func(opts *s3.PresignOptions) {
 		opts.Expires = b.presignDuration
@@ -254,28 +531,16 @@ func (b *Backend) GetUploadURL(ctx context.Context, objectKey string) (string, e
 
 // Upload uploads content directly to S3
 func (b *Backend) Upload(ctx context.Context, objectKey string, reader io.Reader) error {
-	uploader := manager.NewUploader(b.client)
-
 	input := &s3.PutObjectInput{
 		Bucket: aws.String(b.bucket),
-		Key:    aws.String(objectKey),
+		Key:    aws.String(b.key(objectKey)),
 		Body:   reader,
 	}
 
 	// Add server-side encryption if enabled
-	if b.config.EnableSSE {
-		switch b.config.SSEAlgorithm {
-		case "AES256":
-			input.ServerSideEncryption = types.ServerSideEncryptionAes256
-		case "aws:kms":
-			input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
-			if b.config.SSEKMSKeyID != "" {
-				input.SSEKMSKeyId = aws.String(b.config.SSEKMSKeyID)
-			}
-		}
-	}
+	b.applySSE(input)
 
-	_, err := uploader.Upload(ctx, input)
+	err := b.upload(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to upload to S3: %w", err)
 	}
@@ -285,30 +550,28 @@ func (b *Backend) Upload(ctx context.Context, objectKey string, reader io.Reader
 
 // UploadWithParams uploads content with additional parameters
 func (b *Backend) UploadWithParams(ctx context.Context, reader io.Reader, params simplecontent.UploadParams) error {
-	uploader := manager.NewUploader(b.client)
-
 	input := &s3.PutObjectInput{
-		Bucket:      aws.String(b.bucket),
-		Key:         aws.String(params.ObjectKey),
-		Body:        reader,
-		ContentType: aws.String(params.MimeType),
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(params.ObjectKey)),
+		Body:   reader,
+	}
+	if params.MimeType != "" {
+		input.ContentType = aws.String(params.MimeType)
+	}
+	// The uploader copies IfMatch onto CompleteMultipartUpload, so large uploads are
+	// conditional too. GetObjectMeta reports ETags without quotes.
+	if params.IfMatch != "" {
+		input.IfMatch = aws.String(`"` + strings.Trim(params.IfMatch, `"`) + `"`)
 	}
 
 	// Add server-side encryption if enabled
-	if b.config.EnableSSE {
-		switch b.config.SSEAlgorithm {
-		case "AES256":
-			input.ServerSideEncryption = types.ServerSideEncryptionAes256
-		case "aws:kms":
-			input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
-			if b.config.SSEKMSKeyID != "" {
-				input.SSEKMSKeyId = aws.String(b.config.SSEKMSKeyID)
-			}
-		}
-	}
+	b.applySSE(input)
 
-	_, err := uploader.Upload(ctx, input)
+	err := b.upload(ctx, input)
 	if err != nil {
+		if params.IfMatch != "" && isPreconditionFailed(err) {
+			return fmt.Errorf("failed to upload to S3 with params: %w: %v", simplecontent.ErrPreconditionFailed, err)
+		}
 		return fmt.Errorf("failed to upload to S3 with params: %w", err)
 	}
 
@@ -317,14 +580,32 @@ func (b *Backend) UploadWithParams(ctx context.Context, reader io.Reader, params
 
 // GetDownloadURL returns a presigned URL for downloading content
 func (b *Backend) GetDownloadURL(ctx context.Context, objectKey string, downloadFilename string) (string, error) {
+	if downloadFilename == "" {
+		return b.presignDownload(ctx, objectKey, "")
+	}
+	return b.presignDownload(ctx, objectKey, simplecontent.ContentDisposition(simplecontent.DispositionAttachment, downloadFilename))
+}
+
+// GetDownloadURLWithDisposition returns a presigned download URL that makes S3 answer with
+// the given disposition, "attachment" or "inline", and file name
+func (b *Backend) GetDownloadURLWithDisposition(ctx context.Context, objectKey, disposition, downloadFilename string) (string, error) {
+	d, err := simplecontent.ParseDisposition(disposition)
+	if err != nil {
+		return "", err
+	}
+	if d == "" {
+		d = simplecontent.DispositionAttachment
+	}
+	return b.presignDownload(ctx, objectKey, simplecontent.ContentDisposition(d, downloadFilename))
+}
+
+func (b *Backend) presignDownload(ctx context.Context, objectKey, contentDisposition string) (string, error) {
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(b.bucket),
-		Key:    aws.String(objectKey),
+		Key:    aws.String(b.key(objectKey)),
 	}
-
-	// Set response content disposition if filename is provided
-	if downloadFilename != "" {
-		input.ResponseContentDisposition = aws.String(fmt.Sprintf("attachment; filename=\"%s\"", downloadFilename))
+	if contentDisposition != "" {
+		input.ResponseContentDisposition = aws.String(contentDisposition)
 	}
 
 	result, err := b.presignClient.PresignGetObject(ctx, input, func(opts *s3.PresignOptions) {
@@ -342,7 +623,7 @@ func (b *Backend) GetDownloadURL(ctx context.Context, objectKey string, download
 func (b *Backend) GetPreviewURL(ctx context.Context, objectKey string) (string, error) {
 	input := &s3.GetObjectInput{
 		Bucket:                     aws.String(b.bucket),
-		Key:                        aws.String(objectKey),
+		Key:                        aws.String(b.key(objectKey)),
 		ResponseContentDisposition: aws.String("inline"),
 	}
 
@@ -357,29 +638,109 @@ func (b *Backend) GetPreviewURL(ctx context.Context, objectKey string) (string,
 	return result.URL, nil
 }
 
-// Download downloads content directly from S3
+// Download downloads content directly from S3. Objects above Config.ParallelDownloadThreshold
+// are fetched with concurrent range GETs and streamed back in order.
 func (b *Backend) Download(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	if b.config.ParallelDownloadThreshold > 0 {
+		if reader, ok, err := b.parallelDownload(ctx, objectKey); err != nil || ok {
+			return reader, err
+		}
+	}
+
 	result, err := b.client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(b.bucket),
-		Key:    aws.String(objectKey),
+		Key:    aws.String(b.key(objectKey)),
 	})
 
 	if err != nil {
 		var notFound *types.NoSuchKey
 		if errors.As(err, &notFound) {
-			return nil, errors.New("object not found")
+			return nil, simplecontent.ErrObjectNotFound
 		}
 		return nil, fmt.Errorf("failed to download from S3: %w", err)
 	}
 
+	if err := b.checkSSE(objectKey, result.ServerSideEncryption); err != nil {
+		result.Body.Close()
+		return nil, err
+	}
+
 	return result.Body, nil
 }
 
+// MoveObject copies the object to dstKey with a server-side copy and deletes the source
+func (b *Backend) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	exists, err := b.ObjectExists(ctx, dstKey)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return simplecontent.ErrObjectKeyExists
+	}
+
+	if err := b.CopyObject(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+	if err := b.Delete(ctx, srcKey); err != nil {
+		return fmt.Errorf("object copied to %s but source not deleted: %w", dstKey, err)
+	}
+	return nil
+}
+
+// CopyObject copies the object to dstKey with a server-side copy, keeping the content type
+// and user metadata. The configured server-side encryption is applied to the copy.
+func (b *Backend) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(b.bucket),
+		Key:        aws.String(b.key(dstKey)),
+		CopySource: aws.String(url.PathEscape(b.bucket + "/" + b.key(srcKey))),
+	}
+	// Encrypt the copy the same way as an upload
+	sse := &s3.PutObjectInput{}
+	b.applySSE(sse)
+	input.ServerSideEncryption = sse.ServerSideEncryption
+	input.SSEKMSKeyId = sse.SSEKMSKeyId
+	input.BucketKeyEnabled = sse.BucketKeyEnabled
+	if _, err := b.client.CopyObject(ctx, input); err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchKey" {
+			return simplecontent.ErrObjectNotFound
+		}
+		return fmt.Errorf("failed to copy object in S3: %w", err)
+	}
+	return nil
+}
+
+// AppendObject appends to the object by uploading the stored data followed by the data of
+// reader, as S3 objects cannot be extended in place. The upload is conditional on the ETag
+// of the data read, so a concurrent change fails with ErrPreconditionFailed instead of being
+// lost. The content type is kept.
+func (b *Backend) AppendObject(ctx context.Context, objectKey string, reader io.Reader) error {
+	meta, err := b.GetObjectMeta(ctx, objectKey)
+	if errors.Is(err, simplecontent.ErrObjectNotFound) {
+		return b.Upload(ctx, objectKey, reader)
+	}
+	if err != nil {
+		return err
+	}
+
+	existing, err := b.Download(ctx, objectKey)
+	if err != nil {
+		return err
+	}
+	defer existing.Close()
+	return b.UploadWithParams(ctx, io.MultiReader(existing, reader), simplecontent.UploadParams{
+		ObjectKey: objectKey,
+		MimeType:  meta.ContentType,
+		IfMatch:   meta.ETag,
+	})
+}
+
 // Delete deletes content from S3
 func (b *Backend) Delete(ctx context.Context, objectKey string) error {
 	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(b.bucket),
-		Key:    aws.String(objectKey),
+		Key:    aws.String(b.key(objectKey)),
 	})
 
 	if err != nil {
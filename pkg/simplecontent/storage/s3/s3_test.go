@@ -5,11 +5,17 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -149,6 +155,78 @@ func TestS3Backend_MinIOConfiguration(t *testing.T) {
 	})
 }
 
+// TestS3Backend_CreateBucketMinIOQuirks runs bucket creation against a fake MinIO endpoint
+func TestS3Backend_CreateBucketMinIOQuirks(t *testing.T) {
+	newServer := func(t *testing.T, createResponses ...func(w http.ResponseWriter, body string)) (*httptest.Server, *[]string) {
+		var createBodies []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodHead:
+				w.WriteHeader(http.StatusNotFound)
+			case http.MethodPut:
+				body, _ := io.ReadAll(r.Body)
+				createBodies = append(createBodies, string(body))
+				createResponses[len(createBodies)-1](w, string(body))
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &createBodies
+	}
+	s3Error := func(status int, code string) func(w http.ResponseWriter, body string) {
+		return func(w http.ResponseWriter, body string) {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(status)
+			fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+		}
+	}
+	newConfig := func(endpoint, region string) Config {
+		return Config{
+			Bucket:                 "test-bucket",
+			Region:                 region,
+			AccessKeyID:            "minioadmin",
+			SecretAccessKey:        "minioadmin",
+			Endpoint:               endpoint,
+			UsePathStyle:           true,
+			CreateBucketIfNotExist: true,
+		}
+	}
+
+	t.Run("AlreadyOwnedIsSuccess", func(t *testing.T) {
+		srv, bodies := newServer(t, s3Error(http.StatusConflict, "BucketAlreadyOwnedByYou"))
+		_, err := New(newConfig(srv.URL, "us-east-1"))
+		require.NoError(t, err)
+		require.Len(t, *bodies, 1)
+		assert.NotContains(t, (*bodies)[0], "LocationConstraint")
+	})
+
+	t.Run("AlreadyExistsIsSuccess", func(t *testing.T) {
+		srv, _ := newServer(t, s3Error(http.StatusConflict, "BucketAlreadyExists"))
+		_, err := New(newConfig(srv.URL, "us-east-1"))
+		require.NoError(t, err)
+	})
+
+	t.Run("RetriesWithoutRejectedLocationConstraint", func(t *testing.T) {
+		srv, bodies := newServer(t,
+			s3Error(http.StatusBadRequest, "InvalidRegion"),
+			func(w http.ResponseWriter, body string) { w.WriteHeader(http.StatusOK) },
+		)
+		_, err := New(newConfig(srv.URL, "eu-west-1"))
+		require.NoError(t, err)
+		require.Len(t, *bodies, 2)
+		assert.Contains(t, (*bodies)[0], "eu-west-1")
+		assert.NotContains(t, (*bodies)[1], "LocationConstraint")
+	})
+
+	t.Run("OtherErrorsFail", func(t *testing.T) {
+		srv, _ := newServer(t, s3Error(http.StatusForbidden, "AccessDenied"))
+		_, err := New(newConfig(srv.URL, "us-east-1"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create bucket")
+	})
+}
+
 // TestS3Backend_Integration tests actual S3/MinIO operations
 // This test requires a running MinIO instance or S3 credentials
 func TestS3Backend_Integration(t *testing.T) {
@@ -253,6 +331,104 @@ func TestS3Backend_Integration(t *testing.T) {
 	})
 }
 
+// TestS3Backend_SSEOptions tests how SSE settings are applied to uploads and enforced on reads
+func TestS3Backend_SSEOptions(t *testing.T) {
+	t.Run("KMSWithBucketKey", func(t *testing.T) {
+		b := &Backend{config: Config{
+			EnableSSE:           true,
+			SSEAlgorithm:        "aws:kms",
+			SSEKMSKeyID:         "key-123",
+			SSEBucketKeyEnabled: true,
+		}}
+		input := &s3.PutObjectInput{}
+		b.applySSE(input)
+		assert.Equal(t, types.ServerSideEncryptionAwsKms, input.ServerSideEncryption)
+		assert.Equal(t, "key-123", aws.ToString(input.SSEKMSKeyId))
+		assert.True(t, aws.ToBool(input.BucketKeyEnabled))
+	})
+
+	t.Run("BucketKeyIgnoredForAES256", func(t *testing.T) {
+		b := &Backend{config: Config{
+			EnableSSE:           true,
+			SSEAlgorithm:        "AES256",
+			SSEBucketKeyEnabled: true,
+		}}
+		input := &s3.PutObjectInput{}
+		b.applySSE(input)
+		assert.Equal(t, types.ServerSideEncryptionAes256, input.ServerSideEncryption)
+		assert.Nil(t, input.BucketKeyEnabled)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		b := &Backend{config: Config{SSEAlgorithm: "aws:kms", SSEBucketKeyEnabled: true}}
+		input := &s3.PutObjectInput{}
+		b.applySSE(input)
+		assert.Empty(t, input.ServerSideEncryption)
+		assert.Nil(t, input.BucketKeyEnabled)
+	})
+
+	t.Run("RequireSSE", func(t *testing.T) {
+		b := &Backend{config: Config{RequireSSE: true}}
+		assert.ErrorIs(t, b.checkSSE("plain.txt", ""), ErrObjectNotEncrypted)
+		assert.NoError(t, b.checkSSE("enc.txt", types.ServerSideEncryptionAwsKms))
+
+		b.config.RequireSSE = false
+		assert.NoError(t, b.checkSSE("plain.txt", ""))
+	})
+}
+
+// TestS3Backend_SSEIntegration verifies SSE headers round-trip through S3/MinIO.
+// Requires the same environment as TestS3Backend_Integration plus AWS_S3_SSE_ALGORITHM
+// (AES256 or aws:kms) and, optionally, AWS_S3_SSE_KMS_KEY_ID.
+func TestS3Backend_SSEIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	endpoint := os.Getenv("AWS_S3_ENDPOINT")
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	bucket := os.Getenv("AWS_S3_BUCKET")
+	algorithm := os.Getenv("AWS_S3_SSE_ALGORITHM")
+
+	if endpoint == "" || accessKey == "" || secretKey == "" || bucket == "" || algorithm == "" {
+		t.Skip("Skipping SSE integration test: S3/MinIO or SSE environment variables not set")
+	}
+
+	kmsKeyID := os.Getenv("AWS_S3_SSE_KMS_KEY_ID")
+	backend, err := New(Config{
+		Bucket:                 bucket,
+		Region:                 "us-east-1",
+		AccessKeyID:            accessKey,
+		SecretAccessKey:        secretKey,
+		Endpoint:               endpoint,
+		UsePathStyle:           true,
+		CreateBucketIfNotExist: true,
+		EnableSSE:              true,
+		SSEAlgorithm:           algorithm,
+		SSEKMSKeyID:            kmsKeyID,
+		SSEBucketKeyEnabled:    algorithm == "aws:kms",
+		RequireSSE:             true,
+	})
+	require.NoError(t, err, "Failed to create S3 backend")
+
+	ctx := context.Background()
+	objectKey := fmt.Sprintf("test/sse/%d/file.txt", time.Now().UnixNano())
+	require.NoError(t, backend.Upload(ctx, objectKey, bytes.NewReader([]byte("encrypted"))))
+	defer backend.Delete(ctx, objectKey)
+
+	meta, err := backend.GetObjectMeta(ctx, objectKey)
+	require.NoError(t, err)
+	assert.Equal(t, algorithm, meta.Metadata[MetaServerSideEncryption])
+	if kmsKeyID != "" {
+		assert.Contains(t, meta.Metadata[MetaSSEKMSKeyID], kmsKeyID)
+	}
+
+	reader, err := backend.Download(ctx, objectKey)
+	require.NoError(t, err, "Encrypted object should be readable with RequireSSE")
+	reader.Close()
+}
+
 // TestS3Backend_ErrorHandling tests error scenarios
 func TestS3Backend_ErrorHandling(t *testing.T) {
 	// Create a backend with invalid credentials to test error handling
@@ -321,6 +497,37 @@ func TestS3Backend_PresignedURLFormat(t *testing.T) {
 	})
 }
 
+func TestS3Backend_DownloadDisposition(t *testing.T) {
+	backend, err := New(Config{
+		Bucket:          "test-bucket",
+		Region:          "us-east-1",
+		AccessKeyID:     "test-key",
+		SecretAccessKey: "test-secret",
+		Endpoint:        "http://localhost:9000",
+		UsePathStyle:    true,
+	})
+	require.NoError(t, err)
+	s3Backend := backend.(*Backend)
+	ctx := context.Background()
+
+	disposition := func(t *testing.T, rawURL string) string {
+		u, err := neturl.Parse(rawURL)
+		require.NoError(t, err)
+		return u.Query().Get("response-content-disposition")
+	}
+
+	signed, err := s3Backend.GetDownloadURL(ctx, "docs/report.pdf", "report.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, `attachment; filename="report.pdf"`, disposition(t, signed))
+
+	signed, err = s3Backend.GetDownloadURLWithDisposition(ctx, "docs/report.pdf", "inline", "Q3 summary.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, `inline; filename="Q3 summary.pdf"`, disposition(t, signed))
+
+	_, err = s3Backend.GetDownloadURLWithDisposition(ctx, "docs/report.pdf", "embed", "report.pdf")
+	assert.Error(t, err)
+}
+
 // TestS3Backend_ContextCancellation tests context cancellation handling
 func TestS3Backend_ContextCancellation(t *testing.T) {
 	if testing.Short() {
@@ -350,3 +557,56 @@ func TestS3Backend_ContextCancellation(t *testing.T) {
 		}
 	})
 }
+
+func TestS3Backend_Capabilities(t *testing.T) {
+	caps := (&Backend{}).Capabilities()
+	assert.True(t, caps.PresignedUpload)
+	assert.True(t, caps.PresignedDownload)
+}
+
+func TestS3Backend_KeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	fake, srv := newFakeChecksumS3(t)
+	store, err := New(Config{
+		Bucket:          "test-bucket",
+		Region:          "us-east-1",
+		AccessKeyID:     "test-key",
+		SecretAccessKey: "test-secret",
+		Endpoint:        srv.URL,
+		UsePathStyle:    true,
+		KeyPrefix:       "app-a/",
+	})
+	require.NoError(t, err)
+	backend := store.(*Backend)
+
+	require.NoError(t, backend.Upload(ctx, "x", strings.NewReader("prefixed")))
+	assert.Equal(t, "prefixed", string(fake.objects["/test-bucket/app-a/x"]))
+	assert.NotContains(t, fake.objects, "/test-bucket/x")
+
+	meta, err := backend.GetObjectMeta(ctx, "x")
+	require.NoError(t, err)
+	assert.Equal(t, "x", meta.Key)
+	assert.Equal(t, int64(len("prefixed")), meta.Size)
+
+	reader, err := backend.Download(ctx, "x")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "prefixed", string(data))
+
+	// Presigned URLs address the prefixed key
+	for _, presign := range []func() (string, error){
+		func() (string, error) { return backend.GetUploadURL(ctx, "x") },
+		func() (string, error) { return backend.GetDownloadURL(ctx, "x", "") },
+	} {
+		rawURL, err := presign()
+		require.NoError(t, err)
+		u, err := neturl.Parse(rawURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/test-bucket/app-a/x", u.Path)
+	}
+
+	require.NoError(t, backend.Delete(ctx, "x"))
+	assert.Equal(t, []string{"/test-bucket/app-a/x"}, fake.deletes)
+}
@@ -0,0 +1,273 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// storageCleanupTimeout bounds the delete issued after a timed-out upload
+const storageCleanupTimeout = 30 * time.Second
+
+// WithStorageTimeout bounds every operation on the named storage backend. Each blob
+// operation runs with a derived context deadline and fails with ErrStorageTimeout on
+// expiry. A timed-out upload stops reading its source and the partially written object
+// is deleted. Downloads must be fully read and closed within the timeout.
+//
+// The option may be given before or after the backend is registered with WithBlobStore.
+// A zero or negative timeout disables the limit.
+func WithStorageTimeout(name string, timeout time.Duration) Option {
+	return func(s *service) {
+		if s.storageTimeouts == nil {
+			s.storageTimeouts = make(map[string]time.Duration)
+		}
+		s.storageTimeouts[name] = timeout
+	}
+}
+
+// applyStorageTimeouts wraps the registered backends that have a timeout configured
+func (s *service) applyStorageTimeouts() {
+	for name, backend := range s.blobStores {
+		s.blobStores[name] = s.withStorageTimeout(name, backend)
+	}
+}
+
+func (s *service) withStorageTimeout(name string, backend BlobStore) BlobStore {
+	timeout := s.storageTimeouts[name]
+	if timeout <= 0 {
+		return backend
+	}
+	if existing, ok := backend.(*timeoutBlobStore); ok {
+		backend = existing.BlobStore
+	}
+	return &timeoutBlobStore{BlobStore: backend, name: name, timeout: timeout}
+}
+
+// timeoutBlobStore applies a per-operation deadline to the wrapped backend
+type timeoutBlobStore struct {
+	BlobStore
+	name    string
+	timeout time.Duration
+}
+
+// timeoutErr converts a deadline expiry caused by this wrapper into ErrStorageTimeout.
+// Errors when the caller's own context was already done are returned unchanged.
+func (b *timeoutBlobStore) timeoutErr(parent, ctx context.Context, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s on backend %s exceeded %s: %w", ErrStorageTimeout, op, b.name, b.timeout, err)
+	}
+	return err
+}
+
+func (b *timeoutBlobStore) GetUploadURL(ctx context.Context, objectKey string) (string, error) {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	url, err := b.BlobStore.GetUploadURL(opCtx, objectKey)
+	return url, b.timeoutErr(ctx, opCtx, "get_upload_url", err)
+}
+
+func (b *timeoutBlobStore) Upload(ctx context.Context, objectKey string, reader io.Reader) error {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	err := b.BlobStore.Upload(opCtx, objectKey, &contextReader{ctx: opCtx, reader: reader})
+	return b.cleanupAfterUpload(ctx, opCtx, objectKey, "upload", err)
+}
+
+func (b *timeoutBlobStore) UploadWithParams(ctx context.Context, reader io.Reader, params UploadParams) error {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	err := b.BlobStore.UploadWithParams(opCtx, &contextReader{ctx: opCtx, reader: reader}, params)
+	return b.cleanupAfterUpload(ctx, opCtx, params.ObjectKey, "upload_with_params", err)
+}
+
+// cleanupAfterUpload deletes a partially written object when the upload timed out
+func (b *timeoutBlobStore) cleanupAfterUpload(parent, opCtx context.Context, objectKey, op string, err error) error {
+	err = b.timeoutErr(parent, opCtx, op, err)
+	if !errors.Is(err, ErrStorageTimeout) {
+		return err
+	}
+
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(parent), storageCleanupTimeout)
+	defer cancel()
+	if delErr := b.BlobStore.Delete(cleanupCtx, objectKey); delErr != nil {
+		slog.Warn("Failed to clean up timed-out upload", "backend", b.name, "key", objectKey, "error", delErr)
+	}
+	return err
+}
+
+func (b *timeoutBlobStore) GetDownloadURL(ctx context.Context, objectKey string, downloadFilename string) (string, error) {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	url, err := b.BlobStore.GetDownloadURL(opCtx, objectKey, downloadFilename)
+	return url, b.timeoutErr(ctx, opCtx, "get_download_url", err)
+}
+
+func (b *timeoutBlobStore) GetPreviewURL(ctx context.Context, objectKey string) (string, error) {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	url, err := b.BlobStore.GetPreviewURL(opCtx, objectKey)
+	return url, b.timeoutErr(ctx, opCtx, "get_preview_url", err)
+}
+
+// Download keeps the deadline running until the returned reader is closed, since
+// streaming backends tie the response body to the request context.
+func (b *timeoutBlobStore) Download(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	reader, err := b.BlobStore.Download(opCtx, objectKey)
+	if err != nil {
+		cancel()
+		return nil, b.timeoutErr(ctx, opCtx, "download", err)
+	}
+	return &timeoutReadCloser{store: b, parent: ctx, ctx: opCtx, cancel: cancel, reader: reader}, nil
+}
+
+func (b *timeoutBlobStore) Delete(ctx context.Context, objectKey string) error {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.timeoutErr(ctx, opCtx, "delete", b.BlobStore.Delete(opCtx, objectKey))
+}
+
+func (b *timeoutBlobStore) GetObjectMeta(ctx context.Context, objectKey string) (*ObjectMeta, error) {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	meta, err := b.BlobStore.GetObjectMeta(opCtx, objectKey)
+	return meta, b.timeoutErr(ctx, opCtx, "get_object_meta", err)
+}
+
+func (b *timeoutBlobStore) GetUploadOffset(ctx context.Context, objectKey string) (int64, error) {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	offset, err := blobUploadOffset(opCtx, b.BlobStore, objectKey)
+	return offset, b.timeoutErr(ctx, opCtx, "get_upload_offset", err)
+}
+
+func (b *timeoutBlobStore) ObjectExists(ctx context.Context, objectKey string) (bool, error) {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	exists, err := blobExists(opCtx, b.BlobStore, objectKey)
+	return exists, b.timeoutErr(ctx, opCtx, "object_exists", err)
+}
+
+func (b *timeoutBlobStore) MoveObject(ctx context.Context, srcKey, dstKey string) error {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.timeoutErr(ctx, opCtx, "move_object", blobMove(opCtx, b.BlobStore, srcKey, dstKey))
+}
+
+func (b *timeoutBlobStore) AppendObject(ctx context.Context, objectKey string, reader io.Reader) error {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	err := blobAppend(opCtx, b.BlobStore, objectKey, &contextReader{ctx: opCtx, reader: reader})
+	return b.timeoutErr(ctx, opCtx, "append_object", err)
+}
+
+func (b *timeoutBlobStore) Capabilities() BackendCapabilities {
+	return GetBackendCapabilities(b.BlobStore)
+}
+
+// Ping checks the wrapped backend with PingBackend, so its own Pinger is used when it has one
+func (b *timeoutBlobStore) Ping(ctx context.Context) error {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.timeoutErr(ctx, opCtx, "ping", PingBackend(opCtx, b.BlobStore))
+}
+
+func (b *timeoutBlobStore) GetDownloadURLWithDisposition(ctx context.Context, objectKey, disposition, downloadFilename string) (string, error) {
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	url, err := blobDownloadURLWithDisposition(opCtx, b.BlobStore, objectKey, disposition, downloadFilename)
+	return url, b.timeoutErr(ctx, opCtx, "get_download_url", err)
+}
+
+// CopyObject uses the wrapped backend's server-side copy when it has one. Other copies are
+// streamed through the wrapper, so each step gets its own deadline and a timed-out upload
+// is cleaned up.
+func (b *timeoutBlobStore) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	copier, ok := b.BlobStore.(ObjectCopier)
+	if !ok {
+		return streamCopy(ctx, b, srcKey, dstKey)
+	}
+	opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.timeoutErr(ctx, opCtx, "copy_object", copier.CopyObject(opCtx, srcKey, dstKey))
+}
+
+// signatureValidator is presigned.SignatureValidator, which this package cannot import
+type signatureValidator interface {
+	IsSignedURLEnabled() bool
+	ValidateUploadSignature(objectKey, signature string, expiresAt int64) error
+	ValidateDownloadSignature(objectKey, signature string, expiresAt int64, filename string) error
+	ValidatePreviewSignature(objectKey, signature string, expiresAt int64) error
+}
+
+// IsSignedURLEnabled reports whether the wrapped backend validates presigned URL signatures
+func (b *timeoutBlobStore) IsSignedURLEnabled() bool {
+	validator, ok := b.BlobStore.(signatureValidator)
+	return ok && validator.IsSignedURLEnabled()
+}
+
+func (b *timeoutBlobStore) ValidateUploadSignature(objectKey, signature string, expiresAt int64) error {
+	if validator, ok := b.BlobStore.(signatureValidator); ok {
+		return validator.ValidateUploadSignature(objectKey, signature, expiresAt)
+	}
+	return nil
+}
+
+func (b *timeoutBlobStore) ValidateDownloadSignature(objectKey, signature string, expiresAt int64, filename string) error {
+	if validator, ok := b.BlobStore.(signatureValidator); ok {
+		return validator.ValidateDownloadSignature(objectKey, signature, expiresAt, filename)
+	}
+	return nil
+}
+
+func (b *timeoutBlobStore) ValidatePreviewSignature(objectKey, signature string, expiresAt int64) error {
+	if validator, ok := b.BlobStore.(signatureValidator); ok {
+		return validator.ValidatePreviewSignature(objectKey, signature, expiresAt)
+	}
+	return nil
+}
+
+// contextReader fails reads once its context is done, so backends that copy from the
+// source without checking the context still stop when the deadline passes.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// timeoutReadCloser releases the download deadline on Close
+type timeoutReadCloser struct {
+	store  *timeoutBlobStore
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	reader io.ReadCloser
+}
+
+func (r *timeoutReadCloser) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, r.store.timeoutErr(r.parent, r.ctx, "download", err)
+	}
+	n, err := r.reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = r.store.timeoutErr(r.parent, r.ctx, "download", err)
+	}
+	return n, err
+}
+
+func (r *timeoutReadCloser) Close() error {
+	defer r.cancel()
+	return r.reader.Close()
+}
@@ -0,0 +1,62 @@
+package simplecontent
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// WithTempDir sets the directory for the temporary files the service spills data to, such
+// as the local copy a VideoProbe requests with VideoSource.Path. The directory is created
+// when the service is built if it does not exist. Without it temporary files go to
+// os.TempDir. A TempDir set on an enricher itself takes precedence.
+func WithTempDir(path string) Option {
+	return func(s *service) {
+		s.tempDir = path
+	}
+}
+
+// tempDirUser is implemented by components that spill data to disk and take the
+// service's temp directory unless they were given their own
+type tempDirUser interface {
+	useTempDir(dir string)
+}
+
+// prepareTempDir creates the configured temp directory and hands it to the enrichers
+func (s *service) prepareTempDir() error {
+	if s.tempDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(s.tempDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	for _, enricher := range s.enrichers {
+		if user, ok := enricher.(tempDirUser); ok {
+			user.useTempDir(s.tempDir)
+		}
+	}
+	return nil
+}
+
+// spillToTempFile copies reader into a new temporary file under dir (os.TempDir when
+// empty) and returns its path. The caller removes the file; on error nothing is left
+// behind.
+func spillToTempFile(dir, pattern string, reader io.Reader) (path string, err error) {
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write temp file: %w", closeErr)
+		}
+		if err != nil {
+			os.Remove(file.Name())
+		}
+	}()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return file.Name(), nil
+}
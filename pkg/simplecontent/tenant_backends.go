@@ -0,0 +1,62 @@
+package simplecontent
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// WithDefaultStorageBackend names the backend used when a request names none. Without it
+// the service picks any registered backend, which is only predictable with a single one.
+// New fails when the backend is not registered.
+func WithDefaultStorageBackend(name string) Option {
+	return func(s *service) {
+		s.defaultBackend = name
+	}
+}
+
+// WithTenantBackends routes each tenant's new objects to its own backend, e.g. a bucket per
+// enterprise tenant. Uploads and CreateObject use the tenant's backend when the request names
+// none; other tenants get the default backend. Objects already stored stay where they are.
+// New fails when a mapped backend is not registered.
+func WithTenantBackends(backends map[uuid.UUID]string) Option {
+	return func(s *service) {
+		if s.tenantBackends == nil {
+			s.tenantBackends = make(map[uuid.UUID]string, len(backends))
+		}
+		for tenantID, name := range backends {
+			s.tenantBackends[tenantID] = name
+		}
+	}
+}
+
+// validateBackendRouting checks that the default and tenant backends are registered
+func (s *service) validateBackendRouting() error {
+	if s.defaultBackend != "" {
+		if _, exists := s.blobStores[s.defaultBackend]; !exists {
+			return fmt.Errorf("default storage backend: %w", &BackendNotFoundError{Name: s.defaultBackend, Available: s.backendNames()})
+		}
+	}
+	tenantIDs := make([]uuid.UUID, 0, len(s.tenantBackends))
+	for tenantID := range s.tenantBackends {
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	sort.Slice(tenantIDs, func(i, j int) bool { return tenantIDs[i].String() < tenantIDs[j].String() })
+	for _, tenantID := range tenantIDs {
+		name := s.tenantBackends[tenantID]
+		if _, exists := s.blobStores[name]; !exists {
+			return fmt.Errorf("backend for tenant %s: %w", tenantID, &BackendNotFoundError{Name: name, Available: s.backendNames()})
+		}
+	}
+	return nil
+}
+
+// resolveTenantBackend is resolveBackend for new objects of a tenant: an empty name becomes
+// the tenant's backend when one is mapped
+func (s *service) resolveTenantBackend(tenantID uuid.UUID, name string) (string, BlobStore, error) {
+	if name == "" {
+		name = s.tenantBackends[tenantID]
+	}
+	return s.resolveBackend(name)
+}
@@ -0,0 +1,82 @@
+package simplecontent
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope of the default tracer
+const tracerName = "github.com/tendant/simple-content/pkg/simplecontent"
+
+// Span attributes set on the spans of service operations
+const (
+	AttrContentID      = attribute.Key("simplecontent.content_id")
+	AttrObjectID       = attribute.Key("simplecontent.object_id")
+	AttrParentID       = attribute.Key("simplecontent.parent_id")
+	AttrBackend        = attribute.Key("simplecontent.backend")
+	AttrDerivationType = attribute.Key("simplecontent.derivation_type")
+	AttrVariant        = attribute.Key("simplecontent.variant")
+	AttrBytes          = attribute.Key("simplecontent.bytes") // bytes read from the upload reader
+)
+
+// WithTracer makes the service start a span, as a child of the span in the incoming
+// context, around each upload, download and derivation. Spans are named after the
+// operation, e.g. "simplecontent.UploadContent", carry the Attr* attributes known to the
+// operation and record the error of failed operations. Without a tracer no spans are
+// created.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(s *service) {
+		s.tracer = tracer
+	}
+}
+
+// operationSpan is the span around one service operation
+type operationSpan struct {
+	span    trace.Span
+	counter *countingReader
+}
+
+// startSpan starts the span of an operation and returns the context carrying it
+func (s *service) startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, *operationSpan) {
+	tracer := s.tracer
+	if tracer == nil {
+		tracer = noop.NewTracerProvider().Tracer(tracerName)
+	}
+	ctx, span := tracer.Start(ctx, "simplecontent."+operation, trace.WithAttributes(attrs...))
+	return ctx, &operationSpan{span: span}
+}
+
+// countReader wraps the upload reader so the bytes read are recorded when the span ends.
+// Readers are left alone when the span is not recording.
+func (o *operationSpan) countReader(reader io.Reader) io.Reader {
+	if reader == nil || !o.span.IsRecording() {
+		return reader
+	}
+	counted, counter := countUpload(reader)
+	o.counter = counter
+	return counted
+}
+
+// set adds attributes learned while the operation runs
+func (o *operationSpan) set(attrs ...attribute.KeyValue) {
+	o.span.SetAttributes(attrs...)
+}
+
+// end records the outcome of the operation and ends the span
+func (o *operationSpan) end(err error) {
+	if o.counter != nil {
+		o.span.SetAttributes(AttrBytes.Int64(o.counter.count.Load()))
+	}
+	if err != nil {
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+	} else {
+		o.span.SetStatus(codes.Ok, "")
+	}
+	o.span.End()
+}
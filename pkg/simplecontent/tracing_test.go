@@ -0,0 +1,107 @@
+package simplecontent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/repo/memory"
+	memorystorage "github.com/tendant/simple-content/pkg/simplecontent/storage/memory"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanAttributes returns the attributes of a span by key
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// endedSpan returns the single ended span with the given name
+func endedSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	var found []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			found = append(found, span)
+		}
+	}
+	require.Len(t, found, 1, "expected one %s span", name)
+	return found[0]
+}
+
+func TestWithTracer(t *testing.T) {
+	ctx := context.Background()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	svc, err := simplecontent.New(
+		simplecontent.WithRepository(memory.New()),
+		simplecontent.WithBlobStore("memory", memorystorage.New()),
+		simplecontent.WithTracer(provider.Tracer("test")),
+	)
+	require.NoError(t, err)
+
+	parentCtx, parent := provider.Tracer("test").Start(ctx, "handler")
+	content, err := svc.UploadContent(parentCtx, simplecontent.UploadContentRequest{
+		OwnerID:            uuid.New(),
+		TenantID:           uuid.New(),
+		Name:               "Traced",
+		StorageBackendName: "memory",
+		Reader:             strings.NewReader("hello tracing"),
+		FileName:           "hello.txt",
+	})
+	require.NoError(t, err)
+	parent.End()
+
+	t.Run("Upload", func(t *testing.T) {
+		span := endedSpan(t, recorder, "simplecontent.UploadContent")
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), "span should be a child of the incoming span")
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		assert.Equal(t, codes.Ok, span.Status().Code)
+
+		attrs := spanAttributes(span)
+		assert.Equal(t, content.ID.String(), attrs[simplecontent.AttrContentID].AsString())
+		assert.Equal(t, "memory", attrs[simplecontent.AttrBackend].AsString())
+		assert.Equal(t, int64(len("hello tracing")), attrs[simplecontent.AttrBytes].AsInt64())
+		assert.NotEmpty(t, attrs[simplecontent.AttrObjectID].AsString())
+	})
+
+	t.Run("Derive", func(t *testing.T) {
+		_, err := svc.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:           content.ID,
+			Variant:            "thumbnail_256",
+			StorageBackendName: "memory",
+			Reader:             strings.NewReader("thumb"),
+		})
+		require.NoError(t, err)
+
+		span := endedSpan(t, recorder, "simplecontent.UploadDerivedContent")
+		attrs := spanAttributes(span)
+		assert.Equal(t, content.ID.String(), attrs[simplecontent.AttrParentID].AsString())
+		assert.Equal(t, "thumbnail", attrs[simplecontent.AttrDerivationType].AsString())
+		assert.Equal(t, "thumbnail_256", attrs[simplecontent.AttrVariant].AsString())
+		assert.Equal(t, int64(len("thumb")), attrs[simplecontent.AttrBytes].AsInt64())
+	})
+
+	t.Run("DownloadError", func(t *testing.T) {
+		missing := uuid.New()
+		_, err := svc.DownloadContent(ctx, missing)
+		require.ErrorIs(t, err, simplecontent.ErrContentNotFound)
+
+		span := endedSpan(t, recorder, "simplecontent.DownloadContent")
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Equal(t, missing.String(), spanAttributes(span)[simplecontent.AttrContentID].AsString())
+		require.NotEmpty(t, span.Events())
+		assert.Equal(t, "exception", span.Events()[0].Name)
+	})
+}
@@ -27,16 +27,9 @@ const (
     ContentStatusDeleted    ContentStatus = "deleted"
 )
 
-// IsValid checks if the ContentStatus is a valid known status.
+// IsValid checks if the ContentStatus is registered in ContentStatuses.
 func (s ContentStatus) IsValid() bool {
-    switch s {
-    case ContentStatusCreated, ContentStatusUploading, ContentStatusUploaded,
-        ContentStatusProcessing, ContentStatusProcessed,
-        ContentStatusFailed, ContentStatusArchived,
-        ContentStatusDeleted:
-        return true
-    }
-    return false
+    return ContentStatuses.IsRegistered(string(s))
 }
 
 // ParseContentStatus parses a string into a ContentStatus with validation.
@@ -67,6 +60,58 @@ const (
     VariantConversion   DerivationVariant = "conversion"
 )
 
+// DerivationStatus is the processing state of a derived content relationship.
+// It is computed from the derived content's status (content.status remains the
+// single source of truth) and collapses the lifecycle into the three states
+// callers of derivation pipelines care about.
+type DerivationStatus string
+
+// Derivation status constants (typed).
+const (
+    DerivationStatusProcessing DerivationStatus = "processing" // Derivation requested or in progress (created, uploading, uploaded, processing)
+    DerivationStatusCompleted  DerivationStatus = "completed"  // Derived content processed and ready to serve
+    DerivationStatusFailed     DerivationStatus = "failed"     // Derivation failed (see DerivedContent.ErrorMessage)
+)
+
+// IsValid checks if the DerivationStatus is a valid known status.
+func (s DerivationStatus) IsValid() bool {
+    switch s {
+    case DerivationStatusProcessing, DerivationStatusCompleted, DerivationStatusFailed:
+        return true
+    }
+    return false
+}
+
+// ContentStatuses returns the content statuses that map to this derivation status.
+func (s DerivationStatus) ContentStatuses() []string {
+    switch s {
+    case DerivationStatusProcessing:
+        return []string{
+            string(ContentStatusCreated), string(ContentStatusUploading),
+            string(ContentStatusUploaded), string(ContentStatusProcessing),
+        }
+    case DerivationStatusCompleted:
+        return []string{string(ContentStatusProcessed)}
+    case DerivationStatusFailed:
+        return []string{string(ContentStatusFailed)}
+    }
+    return nil
+}
+
+// DerivationStatusFromContentStatus maps a derived content's status to its DerivationStatus.
+// Statuses outside the derivation lifecycle (e.g., archived) return an empty DerivationStatus.
+func DerivationStatusFromContentStatus(status string) DerivationStatus {
+    switch ContentStatus(status) {
+    case ContentStatusCreated, ContentStatusUploading, ContentStatusUploaded, ContentStatusProcessing:
+        return DerivationStatusProcessing
+    case ContentStatusProcessed:
+        return DerivationStatusCompleted
+    case ContentStatusFailed:
+        return DerivationStatusFailed
+    }
+    return ""
+}
+
 // ObjectStatus is the domain type for object lifecycle states.
 type ObjectStatus string
 
@@ -87,15 +132,9 @@ const (
     ObjectStatusDeleted    ObjectStatus = "deleted"
 )
 
-// IsValid checks if the ObjectStatus is a valid known status.
+// IsValid checks if the ObjectStatus is registered in ObjectStatuses.
 func (s ObjectStatus) IsValid() bool {
-    switch s {
-    case ObjectStatusCreated, ObjectStatusUploading, ObjectStatusUploaded,
-        ObjectStatusProcessing, ObjectStatusProcessed,
-        ObjectStatusFailed, ObjectStatusDeleted:
-        return true
-    }
-    return false
+    return ObjectStatuses.IsRegistered(string(s))
 }
 
 // ParseObjectStatus parses a string into an ObjectStatus with validation.
@@ -126,6 +165,10 @@ type Content struct {
     CreatedAt      time.Time `json:"created_at"`
     UpdatedAt      time.Time `json:"updated_at"`
     DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+
+    // PrimaryObject is loaded by listings made with ListContentRequest.WithPrimaryObject;
+    // it is not persisted with the content
+    PrimaryObject *PrimaryObject `json:"primary_object,omitempty"`
 }
 
 // DerivedContent represents content derived from a parent content.
@@ -149,7 +192,9 @@ type DerivedContent struct {
 
 	// Computed fields (not persisted - populated from JOINs or service layer)
 	Status             string                 `json:"status,omitempty" db:"-"`           // Populated from content.status via JOIN
-	DownloadURL        string                 `json:"download_url,omitempty" db:"-"`
+	ProcessingStatus   DerivationStatus       `json:"processing_status,omitempty" db:"-"` // Computed from Status
+	ErrorMessage       string                 `json:"error_message,omitempty" db:"-"`     // Failure reason when ProcessingStatus is failed
+	DownloadURL       string                 `json:"download_url,omitempty" db:"-"`
 	PreviewURL         string                 `json:"preview_url,omitempty" db:"-"`
 	ThumbnailURL       string                 `json:"thumbnail_url,omitempty" db:"-"`
 
@@ -159,6 +204,43 @@ type DerivedContent struct {
 	ParentContent      *Content               `json:"parent_content,omitempty" db:"-"`
 }
 
+// DerivedContentWithDetails pairs a derived-content relationship with the full child
+// Content, so callers can render derived children without fetching each one.
+type DerivedContentWithDetails struct {
+	*DerivedContent
+	Content *Content `json:"content"`
+}
+
+// RelationType identifies the kind of relationship between two contents.
+type RelationType string
+
+const (
+	// RelationTypeDerived is the parent -> derived relationship. It is stored in the
+	// derived-content table and managed via CreateDerivedContent, not LinkContent.
+	RelationTypeDerived    RelationType = "derived"
+	RelationTypeAttachment RelationType = "attachment"
+	RelationTypeReference  RelationType = "reference"
+	RelationTypeRelated    RelationType = "related"
+)
+
+// IsValid checks if the relation type is a known value
+func (t RelationType) IsValid() bool {
+	switch t {
+	case RelationTypeDerived, RelationTypeAttachment, RelationTypeReference, RelationTypeRelated:
+		return true
+	}
+	return false
+}
+
+// ContentRelationship is a directed, typed link from one content to another
+// (e.g., a document and its attachment).
+type ContentRelationship struct {
+	FromContentID uuid.UUID    `json:"from_content_id" db:"from_content_id"`
+	ToContentID   uuid.UUID    `json:"to_content_id" db:"to_content_id"`
+	RelationType  RelationType `json:"relation_type" db:"relation_type"`
+	CreatedAt     time.Time    `json:"created_at" db:"created_at"`
+}
+
 // ContentMetadata represents metadata for a content
 type ContentMetadata struct {
 	ContentID         uuid.UUID              `json:"content_id"`
@@ -173,6 +255,15 @@ type ContentMetadata struct {
 	UpdatedAt         time.Time              `json:"updated_at"`
 }
 
+// ContentMetadataVersion is a snapshot of content metadata recorded each time it is written
+type ContentMetadataVersion struct {
+	ContentID  uuid.UUID       `json:"content_id"`
+	Version    int             `json:"version"` // 1 for the first write, increasing by one per write
+	Actor      string          `json:"actor,omitempty"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	Metadata   ContentMetadata `json:"metadata"`
+}
+
 // Object represents a physical object stored in a storage backend
 type Object struct {
     ID                 uuid.UUID `json:"id"`
@@ -243,6 +334,7 @@ type ContentDetails struct {
 
 	// Status and timing
 	Ready       bool              `json:"ready"`                     // True when parent is uploaded AND all derived content status is uploaded/processed (Object semantics)
+	MissingDerivations []string   `json:"missing_derivations,omitempty"` // Expected variants not yet completed (see SetExpectedDerivations)
 	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`      // When URLs expire (for presigned URLs)
 	CreatedAt   time.Time         `json:"created_at"`                // Content creation time
 	UpdatedAt   time.Time         `json:"updated_at"`                // Content last update time
@@ -269,6 +361,21 @@ type ContentListFilters struct {
 	SortBy          *string
 	SortOrder       *string
 	IncludeDeleted  bool
+
+	// WithoutUploadedObjects keeps only content none of whose live objects is uploaded or
+	// processed, such as content created but never uploaded
+	WithoutUploadedObjects bool
+
+	// OriginalsOnly drops content derived from other content, such as thumbnails
+	OriginalsOnly bool
+
+	// Where is a condition on the content's columns, ANDed with the fields above. Built
+	// with simplecontent.Where, it can express OR groups the fields cannot.
+	Where *ContentFilter
+
+	// IncludePrimaryObject sets Content.PrimaryObject on every listed content, loading
+	// it in the same query
+	IncludePrimaryObject bool
 }
 
 // ContentCountFilters defines filtering options for counting content
@@ -288,6 +395,28 @@ type ContentCountFilters struct {
 	UpdatedAfter    *time.Time
 	UpdatedBefore   *time.Time
 	IncludeDeleted  bool
+
+	// WithoutUploadedObjects keeps only content none of whose live objects is uploaded or
+	// processed, such as content created but never uploaded
+	WithoutUploadedObjects bool
+
+	// OriginalsOnly drops content derived from other content, such as thumbnails
+	OriginalsOnly bool
+
+	// Where is a condition on the content's columns, ANDed with the fields above. Built
+	// with simplecontent.Where, it can express OR groups the fields cannot.
+	Where *ContentFilter
+}
+
+// ObjectListFilters defines filtering options for listing objects. Results are ordered
+// by version descending.
+type ObjectListFilters struct {
+	ContentID          *uuid.UUID
+	Status             *string
+	StorageBackendName *string
+	ObjectKey          *string
+	Limit              *int
+	Offset             *int
 }
 
 // ContentStatisticsOptions defines what statistics to include
@@ -297,6 +426,7 @@ type ContentStatisticsOptions struct {
 	IncludeDerivationBreakdown   bool
 	IncludeDocumentTypeBreakdown bool
 	IncludeTimeRange             bool
+	IncludeDedupBreakdown        bool
 }
 
 // ContentStatisticsResult contains aggregated statistics about content
@@ -308,4 +438,10 @@ type ContentStatisticsResult struct {
 	ByDocumentType   map[string]int64
 	OldestContent    *time.Time
 	NewestContent    *time.Time
+
+	// Deduplication, from content metadata checksums. Content sharing a checksum stores
+	// the same bytes, so every reference after the first could point at one blob.
+	UniqueBlobs      int64 // Distinct checksums
+	TotalReferences  int64 // Content with a checksum
+	BytesSaved       int64 // Bytes of the references beyond the first of each checksum
 }
@@ -1,8 +1,10 @@
 package simplecontent
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 )
 
 // TestContentStatusIsValid tests the IsValid method for ContentStatus
@@ -383,3 +385,74 @@ func TestParseObjectStatus(t *testing.T) {
 		})
 	}
 }
+
+// TestContentMetadataAccessors tests that typed metadata values survive a JSON round trip
+func TestContentMetadataAccessors(t *testing.T) {
+	capturedAt := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+	metadata := ContentMetadata{Metadata: map[string]interface{}{
+		"page_count":  42,
+		"captured_at": capturedAt,
+		"language":    "en",
+	}}
+
+	// Store as the JSONB column would and read back
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var stored ContentMetadata
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if _, isInt := stored.Metadata["page_count"].(int); isInt {
+		t.Fatalf("expected JSON to decode page_count as float64")
+	}
+
+	for name, m := range map[string]*ContentMetadata{"in memory": &metadata, "round trip": &stored} {
+		t.Run(name, func(t *testing.T) {
+			if got, ok := m.GetInt("page_count"); !ok || got != 42 {
+				t.Errorf("GetInt() = %v, %v, want 42, true", got, ok)
+			}
+			if got, ok := m.GetTime("captured_at"); !ok || !got.Equal(capturedAt) {
+				t.Errorf("GetTime() = %v, %v, want %v, true", got, ok, capturedAt)
+			}
+			if got, ok := m.GetString("language"); !ok || got != "en" {
+				t.Errorf("GetString() = %v, %v, want en, true", got, ok)
+			}
+			if _, ok := m.GetInt("language"); ok {
+				t.Errorf("GetInt() of a string should not be ok")
+			}
+			if _, ok := m.GetTime("missing"); ok {
+				t.Errorf("GetTime() of a missing key should not be ok")
+			}
+		})
+	}
+
+	if _, ok := (&ContentMetadata{Metadata: map[string]interface{}{"ratio": 1.5}}).GetInt("ratio"); ok {
+		t.Errorf("GetInt() of a fraction should not be ok")
+	}
+
+	t.Run("Struct", func(t *testing.T) {
+		type scan struct {
+			PageCount  int       `json:"page_count"`
+			CapturedAt time.Time `json:"captured_at"`
+		}
+		var got scan
+		if err := stored.DecodeMetadata(&got); err != nil {
+			t.Fatalf("DecodeMetadata() error = %v", err)
+		}
+		if got.PageCount != 42 || !got.CapturedAt.Equal(capturedAt) {
+			t.Errorf("DecodeMetadata() = %+v", got)
+		}
+
+		if err := stored.EncodeMetadata(scan{PageCount: 7, CapturedAt: capturedAt}); err != nil {
+			t.Fatalf("EncodeMetadata() error = %v", err)
+		}
+		if n, _ := stored.GetInt("page_count"); n != 7 {
+			t.Errorf("GetInt() after EncodeMetadata = %v, want 7", n)
+		}
+		if lang, _ := stored.GetString("language"); lang != "en" {
+			t.Errorf("EncodeMetadata() dropped language")
+		}
+	})
+}
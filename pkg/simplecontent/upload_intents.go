@@ -0,0 +1,187 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadIntent is a write-ahead record that data is about to be written for an object.
+// It is recorded before the blob write and removed once the upload is committed, so an
+// intent left behind by a crash points at an upload that may not have finished.
+type UploadIntent struct {
+	ObjectID           uuid.UUID `json:"object_id"`
+	ContentID          uuid.UUID `json:"content_id"`
+	StorageBackendName string    `json:"storage_backend_name"`
+	ObjectKey          string    `json:"object_key"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// UploadRecoveryReport lists what RecoverIncompleteUploads did with each intent
+type UploadRecoveryReport struct {
+	Finalized []uuid.UUID `json:"finalized"` // data was stored; the object was marked uploaded
+	Failed    []uuid.UUID `json:"failed"`    // data was missing; the object was marked failed
+	Cleared   []uuid.UUID `json:"cleared"`   // object already committed or deleted; only the intent was removed
+}
+
+// WithUploadIntents records an upload intent before every blob write made by the service
+// and removes it once the object is marked uploaded. After a crash,
+// RecoverIncompleteUploads reconciles the intents left behind.
+//
+// Intents younger than recoveryGrace are skipped by recovery so uploads still running on
+// other instances are left alone. Pass 0 when recovering at startup of a single instance.
+func WithUploadIntents(recoveryGrace time.Duration) Option {
+	return func(s *service) {
+		s.uploadIntents = true
+		s.uploadIntentGrace = recoveryGrace
+	}
+}
+
+// beginUpload records the intent to write the object's data. The upload must not start
+// when the intent cannot be recorded.
+func (s *service) beginUpload(ctx context.Context, object *Object) error {
+	if !s.uploadIntents {
+		return nil
+	}
+	intent := &UploadIntent{
+		ObjectID:           object.ID,
+		ContentID:          object.ContentID,
+		StorageBackendName: object.StorageBackendName,
+		ObjectKey:          object.ObjectKey,
+		CreatedAt:          time.Now().UTC(),
+	}
+	if err := s.repository.CreateUploadIntent(ctx, intent); err != nil {
+		return &ObjectError{ObjectID: object.ID, Op: "upload_intent", Err: err}
+	}
+	return nil
+}
+
+// endUpload removes the object's intent once the upload was committed or failed with an
+// error the caller saw. The intent is kept when *keep is set, i.e. the data may be stored
+// but recording the upload failed, so RecoverIncompleteUploads can reconcile the object;
+// otherwise only a crash leaves an intent behind.
+func (s *service) endUpload(ctx context.Context, objectID uuid.UUID, keep *bool) {
+	if !s.uploadIntents {
+		return
+	}
+	if *keep {
+		slog.Warn("Keeping upload intent of an upload that could not be recorded", "object_id", objectID)
+		return
+	}
+	if err := s.repository.DeleteUploadIntent(context.WithoutCancel(ctx), objectID); err != nil {
+		// Log warning but don't fail - recovery clears intents of uploaded objects
+		slog.Warn("Failed to remove upload intent", "object_id", objectID, "error", err)
+	}
+}
+
+// RecoverIncompleteUploads reconciles the upload intents left behind by a crash by
+// checking whether each object's data reached storage:
+//   - data stored, object still created or uploading: the upload is finalized as by
+//     ConfirmUpload
+//   - data missing: the object is marked failed
+//   - object already uploaded or deleted: the intent is cleared, and data left behind by
+//     a deleted object is removed
+//
+// Intents that cannot be reconciled are logged and kept for the next run.
+func (s *service) RecoverIncompleteUploads(ctx context.Context) (*UploadRecoveryReport, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	intents, err := s.repository.ListUploadIntents(ctx, time.Now().UTC().Add(-s.uploadIntentGrace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload intents: %w", err)
+	}
+
+	report := &UploadRecoveryReport{Finalized: []uuid.UUID{}, Failed: []uuid.UUID{}, Cleared: []uuid.UUID{}}
+	for _, intent := range intents {
+		outcome, err := s.recoverUpload(ctx, intent)
+		if err != nil {
+			slog.Warn("Failed to recover upload", "object_id", intent.ObjectID, "key", intent.ObjectKey, "error", err)
+			continue
+		}
+		if err := s.repository.DeleteUploadIntent(ctx, intent.ObjectID); err != nil {
+			slog.Warn("Failed to remove recovered upload intent", "object_id", intent.ObjectID, "error", err)
+			continue
+		}
+		switch outcome {
+		case ObjectStatusUploaded:
+			report.Finalized = append(report.Finalized, intent.ObjectID)
+		case ObjectStatusFailed:
+			report.Failed = append(report.Failed, intent.ObjectID)
+		default:
+			report.Cleared = append(report.Cleared, intent.ObjectID)
+		}
+	}
+	return report, nil
+}
+
+// recoverUpload reconciles one intent and returns the status it gave the object, or ""
+// when the object was left as it was
+func (s *service) recoverUpload(ctx context.Context, intent *UploadIntent) (ObjectStatus, error) {
+	object, err := s.repository.GetObject(ctx, intent.ObjectID)
+	if errors.Is(err, ErrObjectNotFound) {
+		return "", s.removeAbandonedBlob(ctx, intent)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	status := ObjectStatus(object.Status)
+	if status != ObjectStatusCreated && status != ObjectStatusUploading {
+		return "", nil
+	}
+
+	backend, err := s.GetBackend(object.StorageBackendName)
+	if err != nil {
+		return "", err
+	}
+	exists, err := blobExists(ctx, backend, object.ObjectKey)
+	if err != nil {
+		s.stats.backendError(object.StorageBackendName)
+		return "", fmt.Errorf("failed to check object data: %w", err)
+	}
+
+	if exists {
+		if _, err := s.ConfirmUpload(ctx, object.ID); err != nil {
+			return "", err
+		}
+		slog.Info("Recovered incomplete upload", "object_id", object.ID, "key", object.ObjectKey)
+		return ObjectStatusUploaded, nil
+	}
+	if err := s.UpdateObjectStatus(ctx, object.ID, ObjectStatusFailed); err != nil {
+		return "", err
+	}
+	slog.Info("Marked incomplete upload failed", "object_id", object.ID, "key", object.ObjectKey)
+	return ObjectStatusFailed, nil
+}
+
+// removeAbandonedBlob deletes data written for an object that no longer exists, unless
+// another object has since taken the key
+func (s *service) removeAbandonedBlob(ctx context.Context, intent *UploadIntent) error {
+	backend, err := s.GetBackend(intent.StorageBackendName)
+	if err != nil {
+		return err
+	}
+	unlock, err := s.lockBlob(ctx, intent.StorageBackendName, intent.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("failed to lock blob: %w", err)
+	}
+	defer unlock()
+	inUse, holder, err := s.objectKeyInUse(ctx, backend, intent.StorageBackendName, intent.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("failed to check object key: %w", err)
+	}
+	if !inUse || holder != nil {
+		return nil
+	}
+	if err := backend.Delete(ctx, intent.ObjectKey); err != nil {
+		s.stats.backendError(intent.StorageBackendName)
+		return fmt.Errorf("failed to delete abandoned data: %w", err)
+	}
+	slog.Info("Removed data of deleted object", "object_id", intent.ObjectID, "key", intent.ObjectKey)
+	return nil
+}
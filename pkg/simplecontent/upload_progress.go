@@ -0,0 +1,257 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Object metadata keys written while a server-side upload is in progress
+const (
+	MetadataKeyBytesWritten     = "bytes_written"
+	MetadataKeyUploadTotalBytes = "upload_total_bytes"
+)
+
+// defaultUploadProgressInterval is how often UploadObject records progress. Uploads that
+// finish within one interval never write progress metadata.
+const defaultUploadProgressInterval = time.Second
+
+// UploadProgress reports how much of an object's data has been written to storage
+type UploadProgress struct {
+	ObjectID     uuid.UUID `json:"object_id"`
+	BytesWritten int64     `json:"bytes_written"`
+	TotalBytes   int64     `json:"total_bytes,omitempty"` // 0 when the total size is unknown
+	Completed    bool      `json:"completed"`
+}
+
+// UploadStatus is the state of one object reported by GetUploadStatuses
+type UploadStatus struct {
+	ObjectID  uuid.UUID    `json:"object_id"`
+	Status    ObjectStatus `json:"status"`
+	SizeBytes int64        `json:"size_bytes"` // Stored size; 0 until the upload has finished
+	UpdatedAt time.Time    `json:"updated_at"` // Last change to the object or its metadata
+}
+
+// WithUploadProgressInterval sets how often UploadObject records bytes_written in the
+// object metadata so GetUploadProgress can report progress of long uploads.
+// A zero or negative interval disables progress tracking.
+func WithUploadProgressInterval(interval time.Duration) Option {
+	return func(s *service) {
+		s.uploadProgressInterval = interval
+	}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	count  atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
+
+// countUpload wraps an upload source in a countingReader. Like trackUpload it keeps Seek
+// and ReadAt of the source, so wrapping does not force backends to copy what they could
+// read in place.
+func countUpload(reader io.Reader) (io.Reader, *countingReader) {
+	counter := &countingReader{reader: reader}
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return counter, counter
+	}
+	if readerAt, ok := reader.(io.ReaderAt); ok {
+		return &countingReaderAtSeeker{countingReader: counter, seeker: seeker, readerAt: readerAt}, counter
+	}
+	return &countingReadSeeker{countingReader: counter, seeker: seeker}, counter
+}
+
+type countingReadSeeker struct {
+	*countingReader
+	seeker io.Seeker
+}
+
+func (r *countingReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.seeker.Seek(offset, whence)
+}
+
+type countingReaderAtSeeker struct {
+	*countingReader
+	seeker   io.Seeker
+	readerAt io.ReaderAt
+}
+
+func (r *countingReaderAtSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.seeker.Seek(offset, whence)
+}
+
+func (r *countingReaderAtSeeker) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.readerAt.ReadAt(p, off)
+	r.count.Add(int64(n))
+	return n, err
+}
+
+// trackUploadProgress records the counter into object metadata every interval until the
+// returned stop function is called. stop waits for any in-flight write to finish so it
+// cannot overwrite metadata refreshed from storage after the upload.
+func (s *service) trackUploadProgress(ctx context.Context, objectID uuid.UUID, counter *countingReader, totalBytes int64) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(s.uploadProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.recordUploadProgress(ctx, objectID, counter.count.Load(), totalBytes)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// recordUploadProgress merges the progress fields into the object metadata
+func (s *service) recordUploadProgress(ctx context.Context, objectID uuid.UUID, bytesWritten, totalBytes int64) {
+	now := time.Now().UTC()
+	metadata := &ObjectMetadata{ObjectID: objectID, CreatedAt: now}
+	if existing, err := s.repository.GetObjectMetadata(ctx, objectID); err == nil && existing != nil {
+		metadata = existing
+	}
+
+	fields := make(map[string]interface{}, len(metadata.Metadata)+2)
+	for k, v := range metadata.Metadata {
+		fields[k] = v
+	}
+	fields[MetadataKeyBytesWritten] = bytesWritten
+	if totalBytes > 0 {
+		fields[MetadataKeyUploadTotalBytes] = totalBytes
+	}
+	metadata.Metadata = fields
+	metadata.UpdatedAt = now
+
+	if err := s.repository.SetObjectMetadata(ctx, metadata); err != nil {
+		// Progress is best-effort; the upload itself continues
+		slog.Warn("Failed to record upload progress", "object_id", objectID, "error", err)
+	}
+}
+
+// GetUploadProgress returns the bytes written so far for an object being uploaded through
+// UploadObject. Once the upload has finished, BytesWritten and TotalBytes are the stored size.
+func (s *service) GetUploadProgress(ctx context.Context, objectID uuid.UUID) (*UploadProgress, error) {
+	object, err := s.repository.GetObject(ctx, objectID)
+	if err != nil {
+		return nil, &ObjectError{ObjectID: objectID, Op: "get_upload_progress", Err: err}
+	}
+
+	progress := &UploadProgress{ObjectID: objectID}
+	switch ObjectStatus(object.Status) {
+	case ObjectStatusUploaded, ObjectStatusProcessing, ObjectStatusProcessed:
+		progress.Completed = true
+	}
+
+	metadata, err := s.repository.GetObjectMetadata(ctx, objectID)
+	if err != nil || metadata == nil {
+		// No progress recorded yet
+		return progress, nil
+	}
+
+	if progress.Completed {
+		progress.BytesWritten = metadata.SizeBytes
+		progress.TotalBytes = metadata.SizeBytes
+		return progress, nil
+	}
+
+	progress.BytesWritten = metadataInt64(metadata.Metadata, MetadataKeyBytesWritten)
+	progress.TotalBytes = metadataInt64(metadata.Metadata, MetadataKeyUploadTotalBytes)
+	return progress, nil
+}
+
+// metadataInt64 reads a numeric metadata value, which is a float64 once round-tripped through JSON
+func metadataInt64(metadata map[string]interface{}, key string) int64 {
+	switch v := metadata[key].(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// GetUploadOffset returns how many bytes of an object are already in storage, so a client
+// resuming an interrupted upload knows where to continue. Backends implementing
+// UploadOffsetReporter include data from unfinished uploads; for other backends the offset
+// is the stored object size, or 0 when nothing has been stored yet.
+func (s *service) GetUploadOffset(ctx context.Context, objectID uuid.UUID) (int64, error) {
+	object, err := s.repository.GetObject(ctx, objectID)
+	if err != nil {
+		return 0, &ObjectError{ObjectID: objectID, Op: "get_upload_offset", Err: err}
+	}
+
+	backend, err := s.GetBackend(object.StorageBackendName)
+	if err != nil {
+		return 0, &ObjectError{ObjectID: objectID, Op: "get_upload_offset", Err: err}
+	}
+
+	offset, err := blobUploadOffset(ctx, backend, object.ObjectKey)
+	if err != nil {
+		return 0, &StorageError{
+			Backend: object.StorageBackendName,
+			Key:     object.ObjectKey,
+			Op:      "get_upload_offset",
+			Err:     err,
+		}
+	}
+	return offset, nil
+}
+
+// blobUploadOffset asks the backend for its upload offset, falling back to the object size
+func blobUploadOffset(ctx context.Context, backend BlobStore, objectKey string) (int64, error) {
+	if reporter, ok := backend.(UploadOffsetReporter); ok {
+		return reporter.GetUploadOffset(ctx, objectKey)
+	}
+
+	meta, err := backend.GetObjectMeta(ctx, objectKey)
+	if errors.Is(err, ErrObjectNotFound) || errors.Is(err, ErrBlobNotFound) {
+		// Nothing stored yet, so the upload starts from the beginning
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return meta.Size, nil
+}
+
+// GetUploadStatuses returns the status of many objects in one repository round trip, for
+// dashboards that would otherwise poll each object. Objects that do not exist or have been
+// deleted are missing from the result.
+func (s *service) GetUploadStatuses(ctx context.Context, objectIDs []uuid.UUID) (map[uuid.UUID]UploadStatus, error) {
+	statuses, err := s.repository.GetUploadStatusesByObjectIDs(ctx, objectIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload statuses: %w", err)
+	}
+	result := make(map[uuid.UUID]UploadStatus, len(statuses))
+	for id, status := range statuses {
+		result[id] = *status
+	}
+	return result, nil
+}
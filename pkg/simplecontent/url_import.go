@@ -0,0 +1,251 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultURLImportMaxSize is the largest resource ImportContentFromURL accepts when the
+	// policy sets no MaxSize
+	DefaultURLImportMaxSize int64 = 100 << 20
+
+	// DefaultURLImportTimeout bounds an import, fetch and storage write together, when the
+	// policy sets no Timeout
+	DefaultURLImportTimeout = 30 * time.Second
+
+	// maxURLImportRedirects is how many redirects an import follows, as net/http does by default
+	maxURLImportRedirects = 10
+)
+
+// URLImportPolicy limits which URLs ImportContentFromURL may fetch. Host patterns are either
+// exact host names or "*.example.com", which matches any subdomain of example.com. Addresses
+// in loopback, private and link-local ranges are refused unless AllowPrivateNetworks is set;
+// the check runs on the dialed address, so DNS names resolving to internal hosts are caught too.
+type URLImportPolicy struct {
+	AllowedHosts         []string      // When set, only matching hosts may be fetched
+	DeniedHosts          []string      // Never fetched, even when allowed
+	AllowPrivateNetworks bool          // Permit loopback, private and link-local addresses
+	MaxSize              int64         // Largest accepted resource; zero uses DefaultURLImportMaxSize
+	Timeout              time.Duration // Limit for the whole import; zero uses DefaultURLImportTimeout
+}
+
+// WithURLImportPolicy sets the policy ImportContentFromURL checks URLs against. Without it any
+// public http or https URL can be imported, with the default size limit and timeout.
+func WithURLImportPolicy(policy URLImportPolicy) Option {
+	return func(s *service) {
+		s.urlImportPolicy = policy
+	}
+}
+
+// checkURL reports whether the policy lets u be fetched
+func (p URLImportPolicy) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrURLImportDenied, u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrURLImportDenied)
+	}
+	if matchHost(p.DeniedHosts, host) {
+		return fmt.Errorf("%w: host %s is denied", ErrURLImportDenied, host)
+	}
+	if len(p.AllowedHosts) > 0 && !matchHost(p.AllowedHosts, host) {
+		return fmt.Errorf("%w: host %s is not allowed", ErrURLImportDenied, host)
+	}
+	return nil
+}
+
+// checkAddress reports whether the policy lets a connection to the dialed ip:port be made
+func (p URLImportPolicy) checkAddress(address string) error {
+	if p.AllowPrivateNetworks {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrURLImportDenied, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return fmt.Errorf("%w: address %s is not public", ErrURLImportDenied, host)
+	}
+	return nil
+}
+
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// client returns an HTTP client enforcing the policy on every redirect and connection.
+// Proxies are not used, since the policy could not see past them.
+func (p URLImportPolicy) client() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return p.checkAddress(address)
+		},
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			DisableKeepAlives:   true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxURLImportRedirects {
+				return fmt.Errorf("%w: stopped after %d redirects", ErrURLImportFailed, len(via))
+			}
+			return p.checkURL(req.URL)
+		},
+	}
+}
+
+// ImportContentFromURL fetches req.URL and stores the response body as new content, the way
+// UploadContent stores an uploaded reader. The MIME type and file name come from the response
+// Content-Type and Content-Disposition headers, or the URL path, unless the request sets them,
+// and the source URL is kept under "source_url" in the content metadata.
+func (s *service) ImportContentFromURL(ctx context.Context, req ImportFromURLRequest) (*Content, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	policy := s.urlImportPolicy
+	sourceURL, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, &ContentError{Op: "import_from_url", Err: fmt.Errorf("%w: %v", ErrURLImportDenied, err)}
+	}
+	if err := policy.checkURL(sourceURL); err != nil {
+		return nil, &ContentError{Op: "import_from_url", Err: err}
+	}
+
+	timeout := policy.Timeout
+	if timeout <= 0 {
+		timeout = DefaultURLImportTimeout
+	}
+	maxSize := policy.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultURLImportMaxSize
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL.String(), nil)
+	if err != nil {
+		return nil, &ContentError{Op: "import_from_url", Err: err}
+	}
+	resp, err := policy.client().Do(httpReq)
+	if err != nil {
+		if !errors.Is(err, ErrURLImportDenied) && !errors.Is(err, ErrURLImportFailed) {
+			err = fmt.Errorf("%w: %v", ErrURLImportFailed, err)
+		}
+		return nil, &ContentError{Op: "import_from_url", Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &ContentError{Op: "import_from_url", Err: fmt.Errorf("%w: %s returned %s", ErrURLImportFailed, sourceURL.Redacted(), resp.Status)}
+	}
+	if resp.ContentLength > maxSize {
+		return nil, &ContentError{Op: "import_from_url", Err: fmt.Errorf("%w: %d bytes, limit %d", ErrURLImportTooLarge, resp.ContentLength, maxSize)}
+	}
+
+	fileName := req.FileName
+	if fileName == "" {
+		fileName = importFileName(resp)
+	}
+	documentType := req.DocumentType
+	if documentType == "" {
+		if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+			documentType = mediaType
+		}
+	}
+	name := req.Name
+	if name == "" {
+		name = fileName
+	}
+	metadata := make(map[string]interface{}, len(req.CustomMetadata)+1)
+	for k, v := range req.CustomMetadata {
+		metadata[k] = v
+	}
+	if _, ok := metadata["source_url"]; !ok {
+		metadata["source_url"] = sourceURL.Redacted()
+	}
+
+	var fileSize int64
+	if resp.ContentLength > 0 {
+		fileSize = resp.ContentLength
+	}
+	return s.UploadContent(ctx, UploadContentRequest{
+		OwnerID:            req.OwnerID,
+		TenantID:           req.TenantID,
+		Name:               name,
+		Description:        req.Description,
+		DocumentType:       documentType,
+		StorageBackendName: req.StorageBackendName,
+		Reader:             &importSizeLimiter{reader: resp.Body, remaining: maxSize},
+		FileName:           fileName,
+		FileSize:           fileSize,
+		Tags:               req.Tags,
+		CustomMetadata:     metadata,
+	})
+}
+
+// importFileName takes the file name from Content-Disposition, falling back to the last
+// segment of the final URL path
+func importFileName(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := path.Base(strings.ReplaceAll(params["filename"], `\`, "/")); name != "." && name != "/" {
+			return name
+		}
+	}
+	if resp.Request != nil {
+		if name := path.Base(resp.Request.URL.Path); name != "." && name != "/" {
+			return name
+		}
+	}
+	return ""
+}
+
+// importSizeLimiter fails the read that takes the body past the size limit, so an import
+// without a Content-Length cannot store more than the limit
+type importSizeLimiter struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (l *importSizeLimiter) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrURLImportTooLarge
+	}
+	// Read one byte past the limit to tell a body of exactly the limit from a larger one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.reader.Read(p)
+	if int64(n) > l.remaining {
+		n, l.remaining = int(l.remaining), -1
+		return n, ErrURLImportTooLarge
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
@@ -3,6 +3,7 @@ package urlstrategy
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/google/uuid"
@@ -44,8 +45,18 @@ func (s *CDNStrategy) GenerateDownloadURL(ctx context.Context, contentID uuid.UU
 
 	baseURL := fmt.Sprintf("%s/%s", s.CDNBaseURL, objectKey)
 
-	if metadata != nil && metadata.FileName != "" {
-		return fmt.Sprintf("%s?filename=%s", baseURL, metadata.FileName), nil
+	// Edge rules can turn these into a Content-Disposition header
+	if metadata != nil {
+		var params []string
+		if metadata.FileName != "" {
+			params = append(params, "filename="+url.QueryEscape(metadata.FileName))
+		}
+		if metadata.Disposition != "" {
+			params = append(params, "disposition="+url.QueryEscape(metadata.Disposition))
+		}
+		if len(params) > 0 {
+			return baseURL + "?" + strings.Join(params, "&"), nil
+		}
 	}
 
 	// Direct CDN URL pointing to the object key
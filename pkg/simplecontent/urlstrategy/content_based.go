@@ -3,6 +3,7 @@ package urlstrategy
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/google/uuid"
@@ -34,11 +35,14 @@ func (s *ContentBasedStrategy) GenerateDownloadURL(ctx context.Context, contentI
 	var params []string
 	if metadata != nil {
 		if metadata.FileName != "" {
-			params = append(params, fmt.Sprintf("filename=%s", metadata.FileName))
+			params = append(params, "filename="+url.QueryEscape(metadata.FileName))
 		}
 		if metadata.Version > 0 {
 			params = append(params, fmt.Sprintf("version=%d", metadata.Version))
 		}
+		if metadata.Disposition != "" {
+			params = append(params, "disposition="+url.QueryEscape(metadata.Disposition))
+		}
 	}
 
 	if len(params) > 0 {
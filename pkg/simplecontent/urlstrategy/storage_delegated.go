@@ -17,6 +17,12 @@ type BlobStore interface {
 	GetUploadURL(ctx context.Context, objectKey string) (string, error)
 }
 
+// DispositionURLSigner is implemented by backends that can sign a requested disposition
+// into download URLs, so the storage service itself sends the Content-Disposition header
+type DispositionURLSigner interface {
+	GetDownloadURLWithDisposition(ctx context.Context, objectKey, disposition, downloadFilename string) (string, error)
+}
+
 // StorageDelegatedStrategy delegates URL generation to the storage backends
 // This maintains backward compatibility with existing storage backend URL generation
 type StorageDelegatedStrategy struct {
@@ -57,6 +63,12 @@ func (s *StorageDelegatedStrategy) GenerateDownloadURL(ctx context.Context, cont
 		filename = generateFilename(contentID, contentType)
 	}
 
+	if metadata != nil && metadata.Disposition != "" {
+		if signer, ok := backend.(DispositionURLSigner); ok {
+			return signer.GetDownloadURLWithDisposition(ctx, objectKey, metadata.Disposition, filename)
+		}
+	}
+
 	// Delegate to storage backend (current behavior)
 	return backend.GetDownloadURL(ctx, objectKey, filename)
 }
@@ -23,6 +23,7 @@ type URLMetadata struct {
 	FileName    string
 	ContentType string
 	Version     int
+	Disposition string // "attachment" or "inline" requested by the caller; empty keeps the default
 }
 
 // EnhancedURLStrategy provides additional metadata for URL generation
@@ -0,0 +1,151 @@
+package simplecontent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Content metadata keys written by VideoMetadataEnricher
+const (
+	MetaVideoDurationSeconds = "video_duration_seconds"
+	MetaVideoWidth           = "video_width"
+	MetaVideoHeight          = "video_height"
+	MetaVideoCodec           = "video_codec"
+	MetaAudioCodec           = "audio_codec"
+)
+
+// VideoInfo holds the technical details a VideoProbe extracts from a video
+type VideoInfo struct {
+	Duration   time.Duration
+	Width      int
+	Height     int
+	VideoCodec string // e.g. "h264"
+	AudioCodec string // empty when the video has no audio track
+}
+
+// VideoProbe extracts VideoInfo from video data. Implementations typically wrap a tool
+// such as ffprobe. A probe that can parse a stream reads VideoSource.Reader; one that
+// needs a seekable file, as ffprobe does for MP4 files with the index at the end, calls
+// VideoSource.Path.
+type VideoProbe interface {
+	Probe(ctx context.Context, source *VideoSource) (*VideoInfo, error)
+}
+
+// VideoSource gives a VideoProbe the video data either as a stream or as a temporary
+// local file. Call Path before reading from the stream; the data can only be read once.
+type VideoSource struct {
+	reader   io.Reader
+	tempDir  string
+	path     string
+	opened   []*os.File
+	streamed bool
+}
+
+// Reader returns the video data as a stream
+func (v *VideoSource) Reader() (io.Reader, error) {
+	if v.path != "" {
+		file, err := os.Open(v.path)
+		if err != nil {
+			return nil, err
+		}
+		v.opened = append(v.opened, file)
+		return file, nil
+	}
+	v.streamed = true
+	return v.reader, nil
+}
+
+// Path writes the video data to a temporary file on first use and returns its path.
+// The file is removed once the probe returns.
+func (v *VideoSource) Path() (string, error) {
+	if v.path != "" {
+		return v.path, nil
+	}
+	if v.streamed {
+		return "", errors.New("video stream already consumed")
+	}
+
+	path, err := spillToTempFile(v.tempDir, "simplecontent-video-*", v.reader)
+	if err != nil {
+		return "", err
+	}
+	v.path = path
+	return v.path, nil
+}
+
+func (v *VideoSource) cleanup() {
+	for _, file := range v.opened {
+		file.Close()
+	}
+	if v.path != "" {
+		os.Remove(v.path)
+	}
+}
+
+// VideoMetadataEnricher stores the duration, resolution and codecs of video/* uploads in
+// the content metadata, using a pluggable VideoProbe to read them
+type VideoMetadataEnricher struct {
+	probe VideoProbe
+
+	// TempDir is the directory for temporary files created by VideoSource.Path
+	// (default: the service's WithTempDir, then os.TempDir)
+	TempDir string
+}
+
+// NewVideoMetadataEnricher creates a video enricher that reads details with probe
+func NewVideoMetadataEnricher(probe VideoProbe) *VideoMetadataEnricher {
+	return &VideoMetadataEnricher{probe: probe}
+}
+
+// useTempDir adopts the service's temp directory unless TempDir is set
+func (e *VideoMetadataEnricher) useTempDir(dir string) {
+	if e.TempDir == "" {
+		e.TempDir = dir
+	}
+}
+
+// SupportsContent returns true for video/* content types
+func (e *VideoMetadataEnricher) SupportsContent(mimeType string) bool {
+	return strings.HasPrefix(strings.ToLower(mimeType), "video/")
+}
+
+// Enrich probes the object and returns the video details as content metadata
+func (e *VideoMetadataEnricher) Enrich(ctx context.Context, object *Object, blobStore BlobStore) (map[string]interface{}, error) {
+	reader, err := blobStore.Download(ctx, object.ObjectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read video: %w", err)
+	}
+	defer reader.Close()
+
+	source := &VideoSource{reader: reader, tempDir: e.TempDir}
+	defer source.cleanup()
+
+	info, err := e.probe.Probe(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("video probe failed: %w", err)
+	}
+	if info == nil {
+		return nil, nil
+	}
+
+	metadata := map[string]interface{}{}
+	if info.Duration > 0 {
+		metadata[MetaVideoDurationSeconds] = info.Duration.Seconds()
+	}
+	if info.Width > 0 && info.Height > 0 {
+		metadata[MetaVideoWidth] = info.Width
+		metadata[MetaVideoHeight] = info.Height
+	}
+	if info.VideoCodec != "" {
+		metadata[MetaVideoCodec] = info.VideoCodec
+	}
+	if info.AudioCodec != "" {
+		metadata[MetaAudioCodec] = info.AudioCodec
+	}
+	return metadata, nil
+}
@@ -0,0 +1,196 @@
+package simplecontent_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+	"github.com/tendant/simple-content/pkg/simplecontent/repo/memory"
+	memorystorage "github.com/tendant/simple-content/pkg/simplecontent/storage/memory"
+)
+
+// fakeVideoProbe returns fixed details and records the data it was given
+type fakeVideoProbe struct {
+	useFile bool
+	err     error
+	data    string
+	path    string
+}
+
+func (p *fakeVideoProbe) Probe(ctx context.Context, source *simplecontent.VideoSource) (*simplecontent.VideoInfo, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.useFile {
+		path, err := source.Path()
+		if err != nil {
+			return nil, err
+		}
+		p.path = path
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		p.data = string(data)
+	} else {
+		reader, err := source.Reader()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		p.data = string(data)
+	}
+	return &simplecontent.VideoInfo{
+		Duration:   90 * time.Second,
+		Width:      1920,
+		Height:     1080,
+		VideoCodec: "h264",
+		AudioCodec: "aac",
+	}, nil
+}
+
+func TestVideoMetadataEnricher(t *testing.T) {
+	ctx := context.Background()
+
+	upload := func(t *testing.T, probe *fakeVideoProbe, documentType string) *simplecontent.ContentMetadata {
+		enricher := simplecontent.NewVideoMetadataEnricher(probe)
+		enricher.TempDir = t.TempDir()
+		svc, err := simplecontent.New(
+			simplecontent.WithRepository(memory.New()),
+			simplecontent.WithBlobStore("memory", memorystorage.New()),
+			simplecontent.WithMetadataEnricher(enricher),
+		)
+		require.NoError(t, err)
+
+		content, err := svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+			OwnerID:      uuid.New(),
+			TenantID:     uuid.New(),
+			Name:         "Clip",
+			DocumentType: documentType,
+			Reader:       strings.NewReader("fake video bytes"),
+			FileName:     "clip.mp4",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, string(simplecontent.ContentStatusUploaded), content.Status)
+
+		metadata, err := svc.GetContentMetadata(ctx, content.ID)
+		require.NoError(t, err)
+		return metadata
+	}
+
+	assertVideoMetadata := func(t *testing.T, metadata *simplecontent.ContentMetadata) {
+		assert.Equal(t, 90.0, metadata.Metadata[simplecontent.MetaVideoDurationSeconds])
+		assert.Equal(t, 1920, metadata.Metadata[simplecontent.MetaVideoWidth])
+		assert.Equal(t, 1080, metadata.Metadata[simplecontent.MetaVideoHeight])
+		assert.Equal(t, "h264", metadata.Metadata[simplecontent.MetaVideoCodec])
+		assert.Equal(t, "aac", metadata.Metadata[simplecontent.MetaAudioCodec])
+		assert.Equal(t, "clip.mp4", metadata.FileName)
+	}
+
+	t.Run("StreamProbe", func(t *testing.T) {
+		probe := &fakeVideoProbe{}
+		metadata := upload(t, probe, "video/mp4")
+		assert.Equal(t, "fake video bytes", probe.data)
+		assertVideoMetadata(t, metadata)
+	})
+
+	t.Run("TempFileProbe", func(t *testing.T) {
+		probe := &fakeVideoProbe{useFile: true}
+		metadata := upload(t, probe, "video/mp4")
+		assert.Equal(t, "fake video bytes", probe.data)
+		assertVideoMetadata(t, metadata)
+
+		// The temp file is removed after probing
+		_, err := os.Stat(probe.path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("NonVideoSkipped", func(t *testing.T) {
+		probe := &fakeVideoProbe{}
+		metadata := upload(t, probe, "image/png")
+		assert.Empty(t, probe.data)
+		assert.NotContains(t, metadata.Metadata, simplecontent.MetaVideoDurationSeconds)
+	})
+
+	t.Run("ProbeFailureKeepsUpload", func(t *testing.T) {
+		probe := &fakeVideoProbe{err: errors.New("ffprobe not installed")}
+		metadata := upload(t, probe, "video/mp4")
+		assert.NotContains(t, metadata.Metadata, simplecontent.MetaVideoDurationSeconds)
+	})
+}
+
+// spillingProbe spills the video to a temp file and fails afterwards when err is set
+type spillingProbe struct {
+	err     error
+	path    string
+	existed bool
+}
+
+func (p *spillingProbe) Probe(ctx context.Context, source *simplecontent.VideoSource) (*simplecontent.VideoInfo, error) {
+	path, err := source.Path()
+	if err != nil {
+		return nil, err
+	}
+	p.path = path
+	_, statErr := os.Stat(path)
+	p.existed = statErr == nil
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &simplecontent.VideoInfo{Duration: time.Second}, nil
+}
+
+func TestWithTempDir(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{"Success", nil},
+		{"ProbeError", errors.New("ffprobe crashed")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := filepath.Join(t.TempDir(), "spill")
+			probe := &spillingProbe{err: tc.err}
+			svc, err := simplecontent.New(
+				simplecontent.WithRepository(memory.New()),
+				simplecontent.WithBlobStore("memory", memorystorage.New()),
+				simplecontent.WithMetadataEnricher(simplecontent.NewVideoMetadataEnricher(probe)),
+				simplecontent.WithTempDir(tempDir),
+			)
+			require.NoError(t, err)
+
+			_, err = svc.UploadContent(ctx, simplecontent.UploadContentRequest{
+				OwnerID:            uuid.New(),
+				TenantID:           uuid.New(),
+				Name:               "Clip",
+				DocumentType:       "video/mp4",
+				StorageBackendName: "memory",
+				Reader:             strings.NewReader("fake mp4 data"),
+			})
+			require.NoError(t, err)
+
+			require.True(t, probe.existed, "temp file should exist while the probe runs")
+			assert.Equal(t, tempDir, filepath.Dir(probe.path))
+			_, err = os.Stat(probe.path)
+			assert.True(t, os.IsNotExist(err), "temp file should be removed, got %v", err)
+
+			entries, err := os.ReadDir(tempDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
@@ -147,12 +147,13 @@ func TestDerivedContentMaxDepth(t *testing.T) {
 
 	// Attempt to create level 6 (should fail)
 	resp := testutil.AttemptCreateDerivedContent(t, server.URL, content.ID)
-	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	// Check error message
+	// Check error code and message
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
+	assert.Contains(t, string(body), `"code":"max_derivation_depth_exceeded"`)
 	assert.Contains(t, string(body), "maximum derivation depth")
 }
@@ -127,3 +127,43 @@ func TestS3BackendWithMinIOAndSSE(t *testing.T) {
 	err = backend.Delete(ctx, objectKey)
 	assert.NoError(t, err)
 }
+
+// TestS3BackendWithMinIOCreateBucketTwice verifies bucket creation is idempotent against MinIO,
+// both when the bucket already exists and when two backends race to create it
+func TestS3BackendWithMinIOCreateBucketTwice(t *testing.T) {
+	// Skip if MINIO_INTEGRATION_TEST environment variable is not set
+	if os.Getenv("MINIO_INTEGRATION_TEST") == "" {
+		t.Skip("Skipping MinIO integration test. Set MINIO_INTEGRATION_TEST=1 to run.")
+	}
+
+	newConfig := func(bucket string) s3storage.Config {
+		return s3storage.Config{
+			Region:                 "us-east-1",
+			Bucket:                 bucket,
+			AccessKeyID:            "minioadmin",
+			SecretAccessKey:        "minioadmin",
+			Endpoint:               "http://localhost:9000",
+			UsePathStyle:           true,
+			CreateBucketIfNotExist: true,
+		}
+	}
+
+	bucket := "test-bucket-twice-" + time.Now().Format("20060102150405")
+	_, err := s3storage.New(newConfig(bucket))
+	require.NoError(t, err)
+	_, err = s3storage.New(newConfig(bucket))
+	require.NoError(t, err)
+
+	// Concurrent creators: whichever loses the race sees BucketAlreadyOwnedByYou
+	raceBucket := "test-bucket-race-" + time.Now().Format("20060102150405")
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := s3storage.New(newConfig(raceBucket))
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, <-errs)
+	}
+}
@@ -21,6 +21,12 @@ type ContentResponse struct {
 	DerivationLevel int    `json:"derivation_level"`
 }
 
+// contentListResponse is the envelope of content list endpoints
+type contentListResponse struct {
+	Items []ContentResponse `json:"items"`
+	Total int               `json:"total"`
+}
+
 // ContentMetadataResponse represents the response from metadata-related API endpoints
 type ContentMetadataResponse struct {
 	ContentID   string                 `json:"content_id"`
@@ -122,7 +128,7 @@ func GetDerivedContent(t *testing.T, serverURL, parentID string) []ContentRespon
 	require.NoError(t, err)
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var contents []ContentResponse
+	var contents contentListResponse
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(t, err)
 	defer resp.Body.Close()
@@ -130,7 +136,7 @@ func GetDerivedContent(t *testing.T, serverURL, parentID string) []ContentRespon
 	err = json.Unmarshal(body, &contents)
 	require.NoError(t, err)
 
-	return contents
+	return contents.Items
 }
 
 // GetDerivedContentTree gets the derived content tree via the API
@@ -139,7 +145,7 @@ func GetDerivedContentTree(t *testing.T, serverURL, rootID string) []ContentResp
 	require.NoError(t, err)
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var contents []ContentResponse
+	var contents contentListResponse
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(t, err)
 	defer resp.Body.Close()
@@ -147,7 +153,7 @@ func GetDerivedContentTree(t *testing.T, serverURL, rootID string) []ContentResp
 	err = json.Unmarshal(body, &contents)
 	require.NoError(t, err)
 
-	return contents
+	return contents.Items
 }
 
 // AttemptCreateDerivedContent attempts to create a derived content and returns the response